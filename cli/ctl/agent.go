@@ -136,7 +136,8 @@ func RegisterAgentUpgradeCommand() *cobra.Command {
 		Use:   "agent-upgrade",
 		Short: "agent upgrade operation commands",
 		Example: "deepflow-ctl agent-upgrade list\n" +
-			"deepflow-ctl agent-upgrade agent-name --image-name=deepflow-agent\n",
+			"deepflow-ctl agent-upgrade agent-name --image-name=deepflow-agent\n" +
+			"deepflow-ctl agent-upgrade rollout create g-xxxxxx --image-name=deepflow-agent --wave-size=10\n",
 		Run: func(cmd *cobra.Command, args []string) {
 			if len(args) == 1 {
 				if args[0] == "list" {
@@ -161,6 +162,7 @@ func RegisterAgentUpgradeCommand() *cobra.Command {
 		},
 	}
 	agentUpgrade.Flags().StringVarP(&imageName, "image-name", "I", "", "")
+	agentUpgrade.AddCommand(registerAgentUpgradeRolloutCommand())
 
 	return agentUpgrade
 }
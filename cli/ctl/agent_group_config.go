@@ -17,9 +17,11 @@
 package ctl
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/yaml"
@@ -34,7 +36,7 @@ func RegisterAgentGroupConfigCommand() *cobra.Command {
 		Use:   "agent-group-config",
 		Short: "agent-group config operation commands",
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Printf("please run with 'example | list | create | update | delete'.\n")
+			fmt.Printf("please run with 'example | list | create | update | delete | history | diff | rollback'.\n")
 		},
 	}
 
@@ -82,6 +84,42 @@ func RegisterAgentGroupConfigCommand() *cobra.Command {
 		},
 	}
 
+	history := &cobra.Command{
+		Use:     "history [agent-group ID]",
+		Short:   "list agent-group config revisions",
+		Example: "deepflow-ctl agent-group-config history g-xxxxxx",
+		Run: func(cmd *cobra.Command, args []string) {
+			historyAgentGroupConfig(cmd, args)
+		},
+	}
+
+	var diffFrom, diffTo int
+	diff := &cobra.Command{
+		Use:     "diff [agent-group ID] --from <revision> [--to <revision>]",
+		Short:   "diff two agent-group config revisions",
+		Example: "deepflow-ctl agent-group-config diff g-xxxxxx --from 1 --to 2",
+		Run: func(cmd *cobra.Command, args []string) {
+			diffAgentGroupConfig(cmd, args, diffFrom, diffTo)
+		},
+	}
+	diff.Flags().IntVarP(&diffFrom, "from", "", 0, "revision to diff from")
+	diff.Flags().IntVarP(&diffTo, "to", "", 0, "revision to diff to, the latest revision by default")
+	diff.MarkFlagRequired("from")
+
+	var rollbackRevision int
+	var rollbackComment string
+	rollback := &cobra.Command{
+		Use:     "rollback [agent-group ID] --revision <revision>",
+		Short:   "rollback agent-group config to a revision",
+		Example: "deepflow-ctl agent-group-config rollback g-xxxxxx --revision 1 --comment 'revert tap mode'",
+		Run: func(cmd *cobra.Command, args []string) {
+			rollbackAgentGroupConfig(cmd, args, rollbackRevision, rollbackComment)
+		},
+	}
+	rollback.Flags().IntVarP(&rollbackRevision, "revision", "r", 0, "revision to rollback to")
+	rollback.Flags().StringVarP(&rollbackComment, "comment", "m", "", "comment of the new revision")
+	rollback.MarkFlagRequired("revision")
+
 	example := &cobra.Command{
 		Use:   "example",
 		Short: "example agent-group config",
@@ -94,6 +132,9 @@ func RegisterAgentGroupConfigCommand() *cobra.Command {
 	agentGroupConfig.AddCommand(create)
 	agentGroupConfig.AddCommand(update)
 	agentGroupConfig.AddCommand(delete)
+	agentGroupConfig.AddCommand(history)
+	agentGroupConfig.AddCommand(diff)
+	agentGroupConfig.AddCommand(rollback)
 	return agentGroupConfig
 }
 
@@ -250,3 +291,126 @@ func deleteAgentGroupConfig(cmd *cobra.Command, args []string) {
 		return
 	}
 }
+
+func getAgentGroupLcuuid(cmd *cobra.Command, agentGroupShortUUID string) (string, error) {
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/vtap-groups/?short_uuid=%s", server.IP, server.Port, agentGroupShortUUID)
+	response, err := common.CURLPerform("GET", url, nil, "",
+		[]common.HTTPOption{common.WithTimeout(common.GetTimeout(cmd)), common.WithORGID(common.GetORGID(cmd))}...)
+	if err != nil {
+		return "", err
+	}
+	if len(response.Get("DATA").MustArray()) == 0 {
+		return "", fmt.Errorf("agent-group (%s) not exist", agentGroupShortUUID)
+	}
+	return response.Get("DATA").GetIndex(0).Get("LCUUID").MustString(), nil
+}
+
+func historyAgentGroupConfig(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify agent-group ID.\nExample: %s\n", cmd.Example)
+		return
+	}
+	lcuuid, err := getAgentGroupLcuuid(cmd, args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/agent-group-configuration/%s/revisions", server.IP, server.Port, lcuuid)
+	response, err := common.CURLPerform("GET", url, nil, "",
+		[]common.HTTPOption{common.WithTimeout(common.GetTimeout(cmd)), common.WithORGID(common.GetORGID(cmd))}...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	t := table.New()
+	t.SetHeader([]string{"REVISION", "USER_ID", "CREATED_AT", "COMMENT"})
+	tableItems := [][]string{}
+	for i := range response.Get("DATA").MustArray() {
+		revision := response.Get("DATA").GetIndex(i)
+		tableItems = append(tableItems, []string{
+			strconv.Itoa(revision.Get("REVISION").MustInt()),
+			strconv.Itoa(revision.Get("USER_ID").MustInt()),
+			revision.Get("CREATED_AT").MustString(),
+			revision.Get("COMMENT").MustString(),
+		})
+	}
+	t.AppendBulk(tableItems)
+	t.Render()
+}
+
+func diffAgentGroupConfig(cmd *cobra.Command, args []string, from, to int) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify agent-group ID.\nExample: %s\n", cmd.Example)
+		return
+	}
+	lcuuid, err := getAgentGroupLcuuid(cmd, args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/agent-group-configuration/%s/diff?from=%d&to=%d", server.IP, server.Port, lcuuid, from, to)
+	response, err := common.CURLPerform("GET", url, nil, "",
+		[]common.HTTPOption{common.WithTimeout(common.GetTimeout(cmd)), common.WithORGID(common.GetORGID(cmd))}...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	t := table.New()
+	t.SetHeader([]string{"PATH", "TYPE", "FROM", "TO"})
+	tableItems := [][]string{}
+	for i := range response.Get("DATA").MustArray() {
+		diff := response.Get("DATA").GetIndex(i)
+		tableItems = append(tableItems, []string{
+			diff.Get("PATH").MustString(),
+			diff.Get("TYPE").MustString(),
+			formatAgentGroupConfigDiffValue(diff.Get("FROM").Interface()),
+			formatAgentGroupConfigDiffValue(diff.Get("TO").Interface()),
+		})
+	}
+	t.AppendBulk(tableItems)
+	t.Render()
+}
+
+func formatAgentGroupConfigDiffValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if str, ok := value.(string); ok {
+		return str
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func rollbackAgentGroupConfig(cmd *cobra.Command, args []string, revision int, comment string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify agent-group ID.\nExample: %s\n", cmd.Example)
+		return
+	}
+	lcuuid, err := getAgentGroupLcuuid(cmd, args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/agent-group-configuration/%s/rollback", server.IP, server.Port, lcuuid)
+	body := map[string]interface{}{"REVISION": revision, "COMMENT": comment}
+	_, err = common.CURLPerform("POST", url, body, "",
+		[]common.HTTPOption{common.WithTimeout(common.GetTimeout(cmd)), common.WithORGID(common.GetORGID(cmd))}...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Printf("agent-group (%s) config is rolled back to revision %d\n", args[0], revision)
+}
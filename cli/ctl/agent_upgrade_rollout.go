@@ -0,0 +1,287 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ctl
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+	"github.com/spf13/cobra"
+
+	"github.com/khulnasoft/deepflow/cli/ctl/common"
+	"github.com/khulnasoft/deepflow/cli/ctl/common/table"
+)
+
+type agentUpgradeRolloutCreate struct {
+	name              string
+	imageName         string
+	rollbackImageName string
+	waveType          string
+	waveSize          int
+	waveInterval      int
+	healthTimeout     int
+	failureThreshold  int
+	failureAction     string
+}
+
+func registerAgentUpgradeRolloutCommand() *cobra.Command {
+	rollout := &cobra.Command{
+		Use:   "rollout",
+		Short: "upgrade agents of an agent-group in waves",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'create | list | show | pause | resume | cancel | rollback'.\n")
+		},
+	}
+
+	var rolloutCreate agentUpgradeRolloutCreate
+	create := &cobra.Command{
+		Use:   "create [agent-group ID] --image-name <image name>",
+		Short: "create an agent upgrade rollout",
+		Example: "deepflow-ctl agent-upgrade rollout create g-xxxxxx --image-name deepflow-agent --wave-size 10\n" +
+			"deepflow-ctl agent-upgrade rollout create g-xxxxxx --image-name deepflow-agent --wave-type percentage --wave-size 20 " +
+			"--failure-threshold 1 --failure-action rollback --rollback-image-name deepflow-agent-old",
+		Run: func(cmd *cobra.Command, args []string) {
+			createAgentUpgradeRollout(cmd, args, rolloutCreate)
+		},
+	}
+	create.Flags().StringVarP(&rolloutCreate.name, "name", "", "", "rollout name, default: <agent-group name>-<image name>")
+	create.Flags().StringVarP(&rolloutCreate.imageName, "image-name", "I", "", "image to upgrade to, use `deepflow-ctl repo agent list` to get image name")
+	create.Flags().StringVarP(&rolloutCreate.rollbackImageName, "rollback-image-name", "", "", "image to rollback to")
+	create.Flags().StringVarP(&rolloutCreate.waveType, "wave-type", "", "count", "wave size type, options: count, percentage")
+	create.Flags().IntVarP(&rolloutCreate.waveSize, "wave-size", "", 1, "agent count or percentage of agents upgraded in each wave")
+	create.Flags().IntVarP(&rolloutCreate.waveInterval, "wave-interval", "", 60, "seconds to wait after a wave is healthy before starting the next")
+	create.Flags().IntVarP(&rolloutCreate.healthTimeout, "health-timeout", "", 600, "seconds to wait for an upgraded agent to reconnect healthy")
+	create.Flags().IntVarP(&rolloutCreate.failureThreshold, "failure-threshold", "", 0, "failed agents allowed before failure action is taken")
+	create.Flags().StringVarP(&rolloutCreate.failureAction, "failure-action", "", "pause", "action when failed agents exceed the threshold, options: pause, rollback")
+	create.MarkFlagRequired("image-name")
+
+	var listState string
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "list agent upgrade rollouts",
+		Example: "deepflow-ctl agent-upgrade rollout list --state running",
+		Run: func(cmd *cobra.Command, args []string) {
+			listAgentUpgradeRollout(cmd, args, listState)
+		},
+	}
+	list.Flags().StringVarP(&listState, "state", "", "", "filter by state, options: running, paused, succeeded, cancelled, rolling_back, rolled_back")
+
+	show := &cobra.Command{
+		Use:     "show [rollout LCUUID]",
+		Short:   "show the upgrade state of each agent",
+		Example: "deepflow-ctl agent-upgrade rollout show xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx",
+		Run: func(cmd *cobra.Command, args []string) {
+			showAgentUpgradeRollout(cmd, args)
+		},
+	}
+
+	pause := &cobra.Command{
+		Use:     "pause [rollout LCUUID]",
+		Short:   "stop starting new waves",
+		Example: "deepflow-ctl agent-upgrade rollout pause xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx",
+		Run: func(cmd *cobra.Command, args []string) {
+			updateAgentUpgradeRollout(cmd, args, "pause", nil)
+		},
+	}
+
+	var resumeFailureThreshold int
+	resume := &cobra.Command{
+		Use:     "resume [rollout LCUUID]",
+		Short:   "resume a paused rollout",
+		Example: "deepflow-ctl agent-upgrade rollout resume xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx --failure-threshold 3",
+		Run: func(cmd *cobra.Command, args []string) {
+			var body map[string]interface{}
+			if cmd.Flags().Changed("failure-threshold") {
+				body = map[string]interface{}{"FAILURE_THRESHOLD": resumeFailureThreshold}
+			}
+			updateAgentUpgradeRollout(cmd, args, "resume", body)
+		},
+	}
+	resume.Flags().IntVarP(&resumeFailureThreshold, "failure-threshold", "", 0, "new failure threshold, required if failed agents exceed the current")
+
+	cancel := &cobra.Command{
+		Use:     "cancel [rollout LCUUID]",
+		Short:   "cancel a rollout, agents being upgraded are not affected",
+		Example: "deepflow-ctl agent-upgrade rollout cancel xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx",
+		Run: func(cmd *cobra.Command, args []string) {
+			updateAgentUpgradeRollout(cmd, args, "cancel", nil)
+		},
+	}
+
+	var rollbackImageName string
+	rollback := &cobra.Command{
+		Use:     "rollback [rollout LCUUID]",
+		Short:   "upgrade the agents of a rollout to the rollback image",
+		Example: "deepflow-ctl agent-upgrade rollout rollback xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx --rollback-image-name deepflow-agent-old",
+		Run: func(cmd *cobra.Command, args []string) {
+			var body map[string]interface{}
+			if rollbackImageName != "" {
+				body = map[string]interface{}{"ROLLBACK_IMAGE_NAME": rollbackImageName}
+			}
+			updateAgentUpgradeRollout(cmd, args, "rollback", body)
+		},
+	}
+	rollback.Flags().StringVarP(&rollbackImageName, "rollback-image-name", "", "", "image to rollback to, default: the rollback image of the rollout")
+
+	rollout.AddCommand(create)
+	rollout.AddCommand(list)
+	rollout.AddCommand(show)
+	rollout.AddCommand(pause)
+	rollout.AddCommand(resume)
+	rollout.AddCommand(cancel)
+	rollout.AddCommand(rollback)
+	return rollout
+}
+
+func createAgentUpgradeRollout(cmd *cobra.Command, args []string, rolloutCreate agentUpgradeRolloutCreate) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify agent-group ID.\nExample: %s\n", cmd.Example)
+		return
+	}
+	groupLcuuid, err := getAgentGroupLcuuid(cmd, args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/agent-upgrade-rollouts/", server.IP, server.Port)
+	body := map[string]interface{}{
+		"NAME":                rolloutCreate.name,
+		"AGENT_GROUP_LCUUID":  groupLcuuid,
+		"IMAGE_NAME":          rolloutCreate.imageName,
+		"ROLLBACK_IMAGE_NAME": rolloutCreate.rollbackImageName,
+		"WAVE_TYPE":           strings.ToUpper(rolloutCreate.waveType),
+		"WAVE_SIZE":           rolloutCreate.waveSize,
+		"WAVE_INTERVAL":       rolloutCreate.waveInterval,
+		"HEALTH_TIMEOUT":      rolloutCreate.healthTimeout,
+		"FAILURE_THRESHOLD":   rolloutCreate.failureThreshold,
+		"FAILURE_ACTION":      strings.ToUpper(rolloutCreate.failureAction),
+	}
+	response, err := common.CURLPerform("POST", url, body, "",
+		[]common.HTTPOption{common.WithTimeout(common.GetTimeout(cmd)), common.WithORGID(common.GetORGID(cmd))}...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	printAgentUpgradeRollout(response.Get("DATA"))
+}
+
+func listAgentUpgradeRollout(cmd *cobra.Command, args []string, state string) {
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/agent-upgrade-rollouts/", server.IP, server.Port)
+	if state != "" {
+		url += fmt.Sprintf("?state=%s", strings.ToUpper(state))
+	}
+	response, err := common.CURLPerform("GET", url, nil, "",
+		[]common.HTTPOption{common.WithTimeout(common.GetTimeout(cmd)), common.WithORGID(common.GetORGID(cmd))}...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	t := table.New()
+	t.SetHeader([]string{"LCUUID", "NAME", "AGENT_GROUP", "IMAGE_NAME", "STATE", "WAVE", "SUCCEEDED", "FAILED", "CREATED_AT"})
+	tableItems := [][]string{}
+	for i := range response.Get("DATA").MustArray() {
+		rollout := response.Get("DATA").GetIndex(i)
+		tableItems = append(tableItems, []string{
+			rollout.Get("LCUUID").MustString(),
+			rollout.Get("NAME").MustString(),
+			rollout.Get("AGENT_GROUP_NAME").MustString(),
+			rollout.Get("IMAGE_NAME").MustString(),
+			rollout.Get("STATE").MustString(),
+			fmt.Sprintf("%d/%d", rollout.Get("CURRENT_WAVE").MustInt(), rollout.Get("WAVE_COUNT").MustInt()),
+			fmt.Sprintf("%d/%d", rollout.Get("AGENT_STATE_COUNT").Get("SUCCEEDED").MustInt(), rollout.Get("AGENT_COUNT").MustInt()),
+			strconv.Itoa(rollout.Get("AGENT_STATE_COUNT").Get("FAILED").MustInt()),
+			rollout.Get("CREATED_AT").MustString(),
+		})
+	}
+	t.AppendBulk(tableItems)
+	t.Render()
+}
+
+func showAgentUpgradeRollout(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify rollout LCUUID.\nExample: %s\n", cmd.Example)
+		return
+	}
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/agent-upgrade-rollouts/%s/", server.IP, server.Port, args[0])
+	response, err := common.CURLPerform("GET", url, nil, "",
+		[]common.HTTPOption{common.WithTimeout(common.GetTimeout(cmd)), common.WithORGID(common.GetORGID(cmd))}...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	printAgentUpgradeRollout(response.Get("DATA"))
+}
+
+func updateAgentUpgradeRollout(cmd *cobra.Command, args []string, action string, body map[string]interface{}) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify rollout LCUUID.\nExample: %s\n", cmd.Example)
+		return
+	}
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/agent-upgrade-rollouts/%s/%s/", server.IP, server.Port, args[0], action)
+	response, err := common.CURLPerform("POST", url, body, "",
+		[]common.HTTPOption{common.WithTimeout(common.GetTimeout(cmd)), common.WithORGID(common.GetORGID(cmd))}...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	printAgentUpgradeRollout(response.Get("DATA"))
+}
+
+func printAgentUpgradeRollout(rollout *simplejson.Json) {
+	fmt.Printf("LCUUID:            %s\n", rollout.Get("LCUUID").MustString())
+	fmt.Printf("NAME:              %s\n", rollout.Get("NAME").MustString())
+	fmt.Printf("AGENT_GROUP:       %s\n", rollout.Get("AGENT_GROUP_NAME").MustString())
+	fmt.Printf("IMAGE_NAME:        %s (%s)\n", rollout.Get("IMAGE_NAME").MustString(), rollout.Get("EXPECTED_REVISION").MustString())
+	fmt.Printf("STATE:             %s\n", rollout.Get("STATE").MustString())
+	if message := rollout.Get("MESSAGE").MustString(); message != "" {
+		fmt.Printf("MESSAGE:           %s\n", message)
+	}
+	fmt.Printf("WAVE:              %d/%d\n", rollout.Get("CURRENT_WAVE").MustInt(), rollout.Get("WAVE_COUNT").MustInt())
+	fmt.Printf("FAILURE_THRESHOLD: %d (%s)\n", rollout.Get("FAILURE_THRESHOLD").MustInt(), rollout.Get("FAILURE_ACTION").MustString())
+	if rollbackImageName := rollout.Get("ROLLBACK_IMAGE_NAME").MustString(); rollbackImageName != "" {
+		fmt.Printf("ROLLBACK_IMAGE:    %s\n", rollbackImageName)
+	}
+	fmt.Println()
+
+	t := table.New()
+	t.SetHeader([]string{"AGENT_NAME", "WAVE", "STATE", "ORIGINAL_REVISION", "REVISION", "STARTED_AT", "FINISHED_AT", "MESSAGE"})
+	tableItems := [][]string{}
+	for i := range rollout.Get("AGENTS").MustArray() {
+		agent := rollout.Get("AGENTS").GetIndex(i)
+		tableItems = append(tableItems, []string{
+			agent.Get("AGENT_NAME").MustString(),
+			strconv.Itoa(agent.Get("WAVE").MustInt()),
+			agent.Get("STATE").MustString(),
+			agent.Get("ORIGINAL_REVISION").MustString(),
+			agent.Get("REVISION").MustString(),
+			agent.Get("STARTED_AT").MustString(),
+			agent.Get("FINISHED_AT").MustString(),
+			agent.Get("MESSAGE").MustString(),
+		})
+	}
+	t.AppendBulk(tableItems)
+	t.Render()
+}
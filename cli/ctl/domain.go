@@ -88,7 +88,7 @@ func RegisterDomainCommand() *cobra.Command {
 		Use:     "example domain_type",
 		Short:   "example domain create yaml",
 		Long:    "supported types: " + strings.Trim(fmt.Sprint(common.DomainTypes), "[]"),
-		Example: "deepflow-ctl domain example agent_sync \nsupport example type: aliyun | aws | baidu_bce | filereader | agent_sync | \nhuawei | kubernetes | qingcloud | tencent | volcengine",
+		Example: "deepflow-ctl domain example agent_sync \nsupport example type: aliyun | aws | baidu_bce | filereader | agent_sync | \nhuawei | kubernetes | qingcloud | tencent | volcengine | azure | gcp",
 		Run: func(cmd *cobra.Command, args []string) {
 			exampleDomainConfig(cmd, args)
 		},
@@ -313,6 +313,10 @@ func exampleDomainConfig(cmd *cobra.Command, args []string) {
 		fmt.Printf(string(example.YamlDomainFileReader))
 	case common.DOMAIN_TYPE_VOLCENGINE:
 		fmt.Printf(string(example.YamlDomainVolcengine))
+	case common.DOMAIN_TYPE_AZURE:
+		fmt.Printf(string(example.YamlDomainAzure))
+	case common.DOMAIN_TYPE_GCP:
+		fmt.Printf(string(example.YamlDomainGCP))
 	default:
 		err := fmt.Sprintf("domain_type %s not supported\n", args[0])
 		fmt.Fprintln(os.Stderr, err)
@@ -24,12 +24,18 @@ var YamlDomainAliYun []byte
 //go:embed domain_aws.yaml
 var YamlDomainAws []byte
 
+//go:embed domain_azure.yaml
+var YamlDomainAzure []byte
+
 //go:embed domain_baidubce.yaml
 var YamlDomainBaiduBce []byte
 
 //go:embed domain_filereader.yaml
 var YamlDomainFileReader []byte
 
+//go:embed domain_gcp.yaml
+var YamlDomainGCP []byte
+
 //go:embed domain_genesis.yaml
 var YamlDomainGenesis []byte
 
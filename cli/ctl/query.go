@@ -0,0 +1,359 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ctl
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	simplejson "github.com/bitly/go-simplejson"
+	"github.com/spf13/cobra"
+
+	"github.com/khulnasoft/deepflow/cli/ctl/common"
+	"github.com/khulnasoft/deepflow/cli/ctl/common/printutil"
+	"github.com/khulnasoft/deepflow/cli/ctl/common/table"
+)
+
+const (
+	QUERY_OUTPUT_TABLE = "table"
+	QUERY_OUTPUT_JSON  = "json"
+	QUERY_OUTPUT_CSV   = "csv"
+
+	// max points of a series returned by range query when --step is not set
+	PROMQL_DEFAULT_POINTS = 250
+)
+
+func RegisterQueryCommand() *cobra.Command {
+	query := &cobra.Command{
+		Use:   "query",
+		Short: "query data by DeepFlow SQL or PromQL",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println("please run with 'sql | promql'.")
+		},
+	}
+	query.PersistentFlags().Uint32P("querier-port", "", 30416, "deepflow-server querier node port")
+	query.PersistentFlags().String("since", "1h", "query since time duration like [5s,1m,5m,1h], default: 1h")
+	query.PersistentFlags().String("from", "", "query from a specific time(RFC3339), e.g.: 2000-01-01T00:00:00Z")
+	query.PersistentFlags().String("to", "", "query to a specific time(RFC3339), e.g.: 2000-01-01T00:00:00Z")
+	query.PersistentFlags().StringP("output", "o", QUERY_OUTPUT_TABLE, "output format, options: table, json, csv")
+	query.PersistentFlags().Bool("debug", false, "print debug information of the query to stderr")
+	query.PersistentFlags().Duration("watch", 0, "re-run the query periodically with the interval like [5s,1m], disabled by default")
+
+	query.AddCommand(querySQLSubCommand())
+	query.AddCommand(queryPromQLSubCommand())
+	return query
+}
+
+func querySQLSubCommand() *cobra.Command {
+	var db, dataSource string
+	sql := &cobra.Command{
+		Use:   "sql <sql>",
+		Short: "query by DeepFlow SQL",
+		Long: "query by DeepFlow SQL, $__from and $__to in sql are replaced with the unix timestamps (second) " +
+			"of the time range specified by --since or --from/--to",
+		Example: "deepflow-ctl query sql -d flow_log \"SELECT request_resource, response_code FROM l7_flow_log WHERE time>=\\$__from AND time<=\\$__to LIMIT 10\"\n" +
+			"deepflow-ctl query sql -d flow_metrics --datasource 1m -o csv \"SELECT Sum(byte) AS bytes FROM network.1m WHERE time>=\\$__from GROUP BY pod\"",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			watchQuery(cmd, func() error {
+				return querySQL(cmd, args[0], db, dataSource)
+			})
+		},
+	}
+	sql.Flags().StringVarP(&db, "db", "d", "", "database, e.g.: flow_log, flow_metrics, event, profile, prometheus, ext_metrics")
+	sql.Flags().StringVarP(&dataSource, "datasource", "", "", "data source (data precision) of flow_metrics, e.g.: 1s, 1m")
+	return sql
+}
+
+func queryPromQLSubCommand() *cobra.Command {
+	var step time.Duration
+	var instant bool
+	promql := &cobra.Command{
+		Use:   "promql <query>",
+		Short: "query by PromQL",
+		Example: "deepflow-ctl query promql --since 30m --step 1m \"sum(rate(flow_metrics__network__byte[1m])) by (pod)\"\n" +
+			"deepflow-ctl query promql --instant -o json \"up\"",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			watchQuery(cmd, func() error {
+				return queryPromQL(cmd, args[0], step, instant)
+			})
+		},
+	}
+	promql.Flags().DurationVarP(&step, "step", "", 0, fmt.Sprintf("query resolution step width of range query, default: time range / %d", PROMQL_DEFAULT_POINTS))
+	promql.Flags().BoolVarP(&instant, "instant", "", false, "instant query at the end of time range")
+	return promql
+}
+
+// watchQuery runs query once, or periodically if --watch is set
+func watchQuery(cmd *cobra.Command, query func() error) {
+	interval, _ := cmd.Flags().GetDuration("watch")
+	output, _ := cmd.Flags().GetString("output")
+	for {
+		if interval > 0 && output == QUERY_OUTPUT_TABLE {
+			// clear screen like watch(1)
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("Every %v: %s\n\n", interval, time.Now().Format(time.RFC3339))
+		}
+		if err := query(); err != nil {
+			printutil.ErrorWithColor(err.Error())
+		}
+		if interval <= 0 {
+			return
+		}
+		time.Sleep(interval)
+	}
+}
+
+func getQueryHTTPOptions(cmd *cobra.Command) []common.HTTPOption {
+	return []common.HTTPOption{common.WithTimeout(common.GetTimeout(cmd)), common.WithORGID(common.GetORGID(cmd))}
+}
+
+func getQuerierURL(cmd *cobra.Command, path string) string {
+	server := common.GetServerInfo(cmd)
+	port, _ := cmd.Flags().GetUint32("querier-port")
+	return fmt.Sprintf("http://%s:%d%s", server.IP, port, path)
+}
+
+func querySQL(cmd *cobra.Command, sql, db, dataSource string) error {
+	from, to, err := getQueryTime(cmd)
+	if err != nil {
+		return fmt.Errorf("parse time error: %v", err)
+	}
+	debug, _ := cmd.Flags().GetBool("debug")
+	values := getSQLQueryValues(sql, db, dataSource, from, to)
+	queryURL := getQuerierURL(cmd, "/v1/query/")
+	if debug {
+		queryURL += "?debug=true"
+	}
+	response, err := common.CURLPerform("POST", queryURL, nil, values.Encode(), getQueryHTTPOptions(cmd)...)
+	if debug && response != nil {
+		printQueryDebug(response.Get("debug"))
+	}
+	if err != nil {
+		return err
+	}
+
+	result := response.Get("result")
+	header, rows := parseSQLResult(result)
+	output, _ := cmd.Flags().GetString("output")
+	return printQueryResult(output, result, header, rows)
+}
+
+// getSQLQueryValues returns the form of the sql query, $__from and $__to in sql are replaced with the time range
+func getSQLQueryValues(sql, db, dataSource string, from, to int64) url.Values {
+	sql = strings.NewReplacer("$__from", strconv.FormatInt(from, 10), "$__to", strconv.FormatInt(to, 10)).Replace(sql)
+	values := url.Values{}
+	values.Set("db", db)
+	values.Set("sql", sql)
+	if dataSource != "" {
+		values.Set("data_precision", dataSource)
+	}
+	return values
+}
+
+func parseSQLResult(result *simplejson.Json) ([]string, [][]string) {
+	var header []string
+	for _, c := range result.Get("columns").MustArray() {
+		header = append(header, formatQueryValue(c))
+	}
+	var rows [][]string
+	for _, v := range result.Get("values").MustArray() {
+		values, _ := v.([]interface{})
+		row := make([]string, 0, len(values))
+		for _, value := range values {
+			row = append(row, formatQueryValue(value))
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+func queryPromQL(cmd *cobra.Command, promql string, step time.Duration, instant bool) error {
+	from, to, err := getQueryTime(cmd)
+	if err != nil {
+		return fmt.Errorf("parse time error: %v", err)
+	}
+	debug, _ := cmd.Flags().GetBool("debug")
+	path, values := getPromQLQueryValues(promql, from, to, step, instant, debug)
+	response, err := common.CURLPerform("POST", getQuerierURL(cmd, path), nil, values.Encode(), getQueryHTTPOptions(cmd)...)
+	if debug && response != nil {
+		printQueryDebug(response.Get("stats"))
+	}
+	if err != nil {
+		return err
+	}
+	if status := response.Get("status").MustString(); status != "success" {
+		return fmt.Errorf("query (%s) failed, (%s: %s)", promql, response.Get("errorType").MustString(), response.Get("error").MustString())
+	}
+
+	data := response.Get("data")
+	header, rows, err := parsePromQLResult(data)
+	if err != nil {
+		return err
+	}
+	output, _ := cmd.Flags().GetString("output")
+	return printQueryResult(output, data, header, rows)
+}
+
+// getPromQLQueryValues returns the api path and the form of the promql query, the step of range query
+// is set to return at most PROMQL_DEFAULT_POINTS points of a series if it is not specified.
+func getPromQLQueryValues(promql string, from, to int64, step time.Duration, instant, debug bool) (string, url.Values) {
+	var path string
+	values := url.Values{}
+	values.Set("query", promql)
+	if instant {
+		path = "/prom/api/v1/query"
+		values.Set("time", strconv.FormatInt(to, 10))
+	} else {
+		path = "/prom/api/v1/query_range"
+		if step <= 0 {
+			step = time.Duration(math.Max(float64(to-from)/PROMQL_DEFAULT_POINTS, 1)) * time.Second
+		}
+		values.Set("start", strconv.FormatInt(from, 10))
+		values.Set("end", strconv.FormatInt(to, 10))
+		values.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	}
+	if debug {
+		values.Set("debug", "true")
+	}
+	return path, values
+}
+
+func parsePromQLResult(data *simplejson.Json) ([]string, [][]string, error) {
+	result := data.Get("result")
+	var header []string
+	var rows [][]string
+	switch resultType := data.Get("resultType").MustString(); resultType {
+	case "matrix", "vector":
+		header = []string{"METRIC", "TIME", "VALUE"}
+		for i := range result.MustArray() {
+			series := result.GetIndex(i)
+			metric := formatPromMetric(series.Get("metric").MustMap())
+			samples := series.Get("values").MustArray()
+			if resultType == "vector" {
+				samples = []interface{}{series.Get("value").Interface()}
+			}
+			for _, s := range samples {
+				sample, _ := s.([]interface{})
+				if len(sample) != 2 {
+					continue
+				}
+				rows = append(rows, []string{metric, formatPromTime(sample[0]), formatQueryValue(sample[1])})
+			}
+		}
+	case "scalar", "string":
+		header = []string{"TIME", "VALUE"}
+		if sample := result.MustArray(); len(sample) == 2 {
+			rows = append(rows, []string{formatPromTime(sample[0]), formatQueryValue(sample[1])})
+		}
+	default:
+		return nil, nil, fmt.Errorf("result type %s is not supported", resultType)
+	}
+	return header, rows, nil
+}
+
+func printQueryResult(output string, raw *simplejson.Json, header []string, rows [][]string) error {
+	switch output {
+	case QUERY_OUTPUT_TABLE:
+		t := table.New()
+		t.SetHeader(header)
+		t.AppendBulk(rows)
+		t.Render()
+	case QUERY_OUTPUT_JSON:
+		jData, err := raw.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		str, err := common.JsonFormat(jData)
+		if err != nil {
+			return err
+		}
+		fmt.Println(str)
+	case QUERY_OUTPUT_CSV:
+		w := csv.NewWriter(os.Stdout)
+		w.Write(header)
+		w.WriteAll(rows)
+		return w.Error()
+	default:
+		return fmt.Errorf("output format %s is not supported, please use table | json | csv", output)
+	}
+	return nil
+}
+
+func printQueryDebug(debug *simplejson.Json) {
+	if debug == nil || debug.Interface() == nil {
+		return
+	}
+	jData, err := debug.MarshalJSON()
+	if err != nil {
+		return
+	}
+	if str, err := common.JsonFormat(jData); err == nil {
+		fmt.Fprintln(os.Stderr, str)
+	}
+}
+
+func formatQueryValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case []interface{}, map[string]interface{}:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatPromMetric formats labels like metric_name{label1="value1", label2="value2"}
+func formatPromMetric(labels map[string]interface{}) string {
+	name := formatQueryValue(labels["__name__"])
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		if k != "__name__" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, formatQueryValue(labels[k])))
+	}
+	return name + "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func formatPromTime(value interface{}) string {
+	ts, err := strconv.ParseFloat(formatQueryValue(value), 64)
+	if err != nil {
+		return formatQueryValue(value)
+	}
+	sec, frac := math.Modf(ts)
+	return time.Unix(int64(sec), int64(frac*1e9)).Format(time.RFC3339)
+}
@@ -0,0 +1,288 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ctl
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	simplejson "github.com/bitly/go-simplejson"
+)
+
+func newTestJson(t *testing.T, data string) *simplejson.Json {
+	j, err := simplejson.NewJson([]byte(data))
+	if err != nil {
+		t.Fatalf("parse json failed: %s", err.Error())
+	}
+	return j
+}
+
+func TestQueryCommandArgs(t *testing.T) {
+	query := RegisterQueryCommand()
+	sql, _, err := query.Find([]string{"sql"})
+	if err != nil {
+		t.Fatalf("find sql command failed: %s", err.Error())
+	}
+	promql, _, err := query.Find([]string{"promql"})
+	if err != nil {
+		t.Fatalf("find promql command failed: %s", err.Error())
+	}
+	for _, cmd := range []string{sql.Name(), promql.Name()} {
+		c, _, _ := query.Find([]string{cmd})
+		if c.Args(c, []string{}) == nil {
+			t.Errorf("%s should require the query", cmd)
+		}
+		if c.Args(c, []string{"a", "b"}) == nil {
+			t.Errorf("%s should accept only one query", cmd)
+		}
+		if err := c.Args(c, []string{"a"}); err != nil {
+			t.Errorf("%s should accept one query, got %s", cmd, err.Error())
+		}
+	}
+
+	if err := sql.ParseFlags([]string{"-d", "flow_metrics", "--datasource", "1m", "-o", "csv", "--since", "5m"}); err != nil {
+		t.Fatalf("parse sql flags failed: %s", err.Error())
+	}
+	if db, _ := sql.Flags().GetString("db"); db != "flow_metrics" {
+		t.Errorf("db = %s, want flow_metrics", db)
+	}
+	if dataSource, _ := sql.Flags().GetString("datasource"); dataSource != "1m" {
+		t.Errorf("datasource = %s, want 1m", dataSource)
+	}
+	if output, _ := sql.Flags().GetString("output"); output != QUERY_OUTPUT_CSV {
+		t.Errorf("output = %s, want csv", output)
+	}
+	from, to, err := getQueryTime(sql)
+	if err != nil {
+		t.Fatalf("get query time failed: %s", err.Error())
+	}
+	if to-from != 300 {
+		t.Errorf("time range = %d, want 300", to-from)
+	}
+
+	if err := promql.ParseFlags([]string{"--step", "30s", "--instant", "--from", "2000-01-01T00:00:00Z", "--to", "2000-01-01T01:00:00Z"}); err != nil {
+		t.Fatalf("parse promql flags failed: %s", err.Error())
+	}
+	if step, _ := promql.Flags().GetDuration("step"); step != 30*time.Second {
+		t.Errorf("step = %v, want 30s", step)
+	}
+	if instant, _ := promql.Flags().GetBool("instant"); !instant {
+		t.Error("instant should be set")
+	}
+	from, to, err = getQueryTime(promql)
+	if err != nil {
+		t.Fatalf("get query time failed: %s", err.Error())
+	}
+	if from != 946684800 || to != 946688400 {
+		t.Errorf("time range = [%d, %d], want [946684800, 946688400]", from, to)
+	}
+}
+
+func TestGetSQLQueryValues(t *testing.T) {
+	values := getSQLQueryValues("SELECT byte FROM network.1m WHERE time>=$__from AND time<=$__to", "flow_metrics", "1m", 100, 200)
+	if sql := values.Get("sql"); sql != "SELECT byte FROM network.1m WHERE time>=100 AND time<=200" {
+		t.Errorf("unexpected sql: %s", sql)
+	}
+	if values.Get("db") != "flow_metrics" || values.Get("data_precision") != "1m" {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	values = getSQLQueryValues("SELECT 1", "flow_log", "", 100, 200)
+	if _, ok := values["data_precision"]; ok {
+		t.Errorf("data_precision should not be set without datasource: %v", values)
+	}
+}
+
+func TestGetPromQLQueryValues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		step     time.Duration
+		instant  bool
+		debug    bool
+		wantPath string
+		want     map[string]string
+	}{
+		{
+			name:     "range with default step",
+			wantPath: "/prom/api/v1/query_range",
+			want:     map[string]string{"query": "up", "start": "1000", "end": "4600", "step": "14"},
+		},
+		{
+			name:     "range with step",
+			step:     1500 * time.Millisecond,
+			debug:    true,
+			wantPath: "/prom/api/v1/query_range",
+			want:     map[string]string{"query": "up", "start": "1000", "end": "4600", "step": "1.5", "debug": "true"},
+		},
+		{
+			name:     "instant",
+			instant:  true,
+			wantPath: "/prom/api/v1/query",
+			want:     map[string]string{"query": "up", "time": "4600"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, values := getPromQLQueryValues("up", 1000, 4600, tc.step, tc.instant, tc.debug)
+			if path != tc.wantPath {
+				t.Errorf("path = %s, want %s", path, tc.wantPath)
+			}
+			got := make(map[string]string, len(values))
+			for k := range values {
+				got[k] = values.Get(k)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("values = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	// the step is at least 1s for short time ranges
+	_, values := getPromQLQueryValues("up", 1000, 1010, 0, false, false)
+	if step := values.Get("step"); step != "1" {
+		t.Errorf("step = %s, want 1", step)
+	}
+}
+
+func TestParseSQLResult(t *testing.T) {
+	result := newTestJson(t, `{"columns": ["pod", "bytes", "tags"], "values": [["pod-a", 1024, {"k": "v"}], [null, 1.5, [1, 2]]]}`)
+	header, rows := parseSQLResult(result)
+	if want := []string{"pod", "bytes", "tags"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header = %v, want %v", header, want)
+	}
+	if want := [][]string{{"pod-a", "1024", `{"k":"v"}`}, {"", "1.5", "[1,2]"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
+
+func TestParsePromQLResult(t *testing.T) {
+	formatTime := func(sec int64) string {
+		return time.Unix(sec, 0).Format(time.RFC3339)
+	}
+	testCases := []struct {
+		name       string
+		data       string
+		wantHeader []string
+		wantRows   [][]string
+		wantErr    bool
+	}{
+		{
+			name:       "matrix",
+			data:       `{"resultType": "matrix", "result": [{"metric": {"__name__": "up", "pod": "a", "app": "b"}, "values": [[1000, "1"], [1060, "0"]]}]}`,
+			wantHeader: []string{"METRIC", "TIME", "VALUE"},
+			wantRows:   [][]string{{`up{app="b", pod="a"}`, formatTime(1000), "1"}, {`up{app="b", pod="a"}`, formatTime(1060), "0"}},
+		},
+		{
+			name:       "vector",
+			data:       `{"resultType": "vector", "result": [{"metric": {"pod": "a"}, "value": [1000.5, "2"]}]}`,
+			wantHeader: []string{"METRIC", "TIME", "VALUE"},
+			wantRows:   [][]string{{`{pod="a"}`, time.Unix(1000, 5e8).Format(time.RFC3339), "2"}},
+		},
+		{
+			name:       "scalar",
+			data:       `{"resultType": "scalar", "result": [1000, "3"]}`,
+			wantHeader: []string{"TIME", "VALUE"},
+			wantRows:   [][]string{{formatTime(1000), "3"}},
+		},
+		{
+			name:    "unsupported",
+			data:    `{"resultType": "histogram", "result": []}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header, rows, err := parsePromQLResult(newTestJson(t, tc.data))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(header, tc.wantHeader) {
+				t.Errorf("header = %v, want %v", header, tc.wantHeader)
+			}
+			if !reflect.DeepEqual(rows, tc.wantRows) {
+				t.Errorf("rows = %v, want %v", rows, tc.wantRows)
+			}
+		})
+	}
+}
+
+func TestPrintQueryResultTable(t *testing.T) {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("create pipe failed: %s", err.Error())
+	}
+	os.Stdout = w
+	err = printQueryResult(QUERY_OUTPUT_TABLE, simplejson.New(), exampleHeader, exampleRows)
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatalf("print table failed: %s", err.Error())
+	}
+	out, _ := io.ReadAll(r)
+
+	// the header is written to stderr, so that it is kept when the output is filtered by grep
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	want := []string{"pod-a      1024", "pod-b, c   16"}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %q, want %q", lines, want)
+	}
+	for i := range lines {
+		if strings.TrimRight(lines[i], " ") != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestPrintQueryResultUnsupportedOutput(t *testing.T) {
+	if err := printQueryResult("yaml", simplejson.New(), nil, nil); err == nil {
+		t.Error("yaml output should not be supported")
+	}
+}
+
+var (
+	exampleHeader = []string{"pod", "bytes"}
+	exampleRows   = [][]string{{"pod-a", "1024"}, {"pod-b, c", "16"}}
+)
+
+func Example_csvOutput() {
+	printQueryResult(QUERY_OUTPUT_CSV, simplejson.New(), exampleHeader, exampleRows)
+	// Output:
+	// pod,bytes
+	// pod-a,1024
+	// "pod-b, c",16
+}
+
+func Example_jsonOutput() {
+	raw, _ := simplejson.NewJson([]byte(`{"columns": ["pod"], "values": [["pod-a"]]}`))
+	printQueryResult(QUERY_OUTPUT_JSON, raw, []string{"pod"}, [][]string{{"pod-a"}})
+	// Output:
+	// {
+	//     "columns": [
+	//         "pod"
+	//     ],
+	//     "values": [
+	//         [
+	//             "pod-a"
+	//         ]
+	//     ]
+	// }
+}
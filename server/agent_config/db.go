@@ -31,6 +31,22 @@ func (MySQLAgentGroupConfiguration) TableName() string {
 	return "agent_group_configuration"
 }
 
+// MySQLAgentGroupConfigurationRevision is an immutable revision of agent group configuration,
+// which is saved when the configuration is created, updated or rolled back
+type MySQLAgentGroupConfigurationRevision struct {
+	ID               int       `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
+	AgentGroupLcuuid string    `gorm:"column:agent_group_lcuuid;type:char(64);default:not null" json:"AGENT_GROUP_LCUUID"`
+	Revision         int       `gorm:"column:revision;type:int;not null" json:"REVISION"`
+	Yaml             string    `gorm:"column:yaml;type:text;default:not null" json:"YAML,omitempty"`
+	UserID           int       `gorm:"column:user_id;type:int;default:1" json:"USER_ID"`
+	Comment          string    `gorm:"column:comment;type:varchar(512);default:''" json:"COMMENT"`
+	CreatedAt        time.Time `gorm:"column:created_at;type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"CREATED_AT"`
+}
+
+func (MySQLAgentGroupConfigurationRevision) TableName() string {
+	return "agent_group_configuration_revision"
+}
+
 type AgentGroupConfigModel struct {
 	ID                                int      `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
 	MaxCollectPps                     *int     `gorm:"column:max_collect_pps;type:int;default:null" json:"MAX_COLLECT_PPS"`
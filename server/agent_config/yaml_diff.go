@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent_config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	YAML_DIFF_ADDED    = "added"
+	YAML_DIFF_REMOVED  = "removed"
+	YAML_DIFF_MODIFIED = "modified"
+)
+
+// YAMLDiff is a difference between two yaml documents, the path consists of the keys of mappings
+// joined by '.' and the indexes of sequences, e.g.: 'inputs.proc.process_matcher[0].match_regex'
+type YAMLDiff struct {
+	Path string      `json:"PATH"`
+	Type string      `json:"TYPE"`
+	From interface{} `json:"FROM,omitempty"`
+	To   interface{} `json:"TO,omitempty"`
+}
+
+// DiffYAML compares two yaml documents by values, the comments, the key order and the format
+// of documents are ignored. The differences are sorted by path.
+func DiffYAML(from, to []byte) ([]YAMLDiff, error) {
+	var fromValue, toValue interface{}
+	if err := yaml.Unmarshal(from, &fromValue); err != nil {
+		return nil, fmt.Errorf("parse yaml failed: %v", err)
+	}
+	if err := yaml.Unmarshal(to, &toValue); err != nil {
+		return nil, fmt.Errorf("parse yaml failed: %v", err)
+	}
+	// an empty document is the same as an empty mapping
+	if fromValue == nil {
+		fromValue = map[string]interface{}{}
+	}
+	if toValue == nil {
+		toValue = map[string]interface{}{}
+	}
+	diffs := []YAMLDiff{}
+	diffYAMLValue("", fromValue, toValue, &diffs)
+	return diffs, nil
+}
+
+func toYAMLMapping(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		ret := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			ret[fmt.Sprint(k)] = v
+		}
+		return ret, true
+	}
+	return nil, false
+}
+
+func joinYAMLPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func diffYAMLValue(path string, from, to interface{}, diffs *[]YAMLDiff) {
+	fromMapping, fromIsMapping := toYAMLMapping(from)
+	toMapping, toIsMapping := toYAMLMapping(to)
+	if fromIsMapping && toIsMapping {
+		keys := make([]string, 0, len(fromMapping)+len(toMapping))
+		for k := range fromMapping {
+			keys = append(keys, k)
+		}
+		for k := range toMapping {
+			if _, ok := fromMapping[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fromV, inFrom := fromMapping[k]
+			toV, inTo := toMapping[k]
+			p := joinYAMLPath(path, k)
+			if !inFrom {
+				*diffs = append(*diffs, YAMLDiff{Path: p, Type: YAML_DIFF_ADDED, To: toV})
+			} else if !inTo {
+				*diffs = append(*diffs, YAMLDiff{Path: p, Type: YAML_DIFF_REMOVED, From: fromV})
+			} else {
+				diffYAMLValue(p, fromV, toV, diffs)
+			}
+		}
+		return
+	}
+
+	fromSequence, fromIsSequence := from.([]interface{})
+	toSequence, toIsSequence := to.([]interface{})
+	if fromIsSequence && toIsSequence {
+		for i := 0; i < len(fromSequence) || i < len(toSequence); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			if i >= len(fromSequence) {
+				*diffs = append(*diffs, YAMLDiff{Path: p, Type: YAML_DIFF_ADDED, To: toSequence[i]})
+			} else if i >= len(toSequence) {
+				*diffs = append(*diffs, YAMLDiff{Path: p, Type: YAML_DIFF_REMOVED, From: fromSequence[i]})
+			} else {
+				diffYAMLValue(p, fromSequence[i], toSequence[i], diffs)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*diffs = append(*diffs, YAMLDiff{Path: path, Type: YAML_DIFF_MODIFIED, From: from, To: to})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent_config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffYAML(t *testing.T) {
+	from := `
+global:
+  limits:
+    max_millicpus: 1000
+    max_memory: 768
+  # comments are ignored
+  tunning:
+    cpu_affinity: [1, 2]
+inputs:
+  proc:
+    enabled: false
+`
+	to := `
+inputs:
+  proc:
+    enabled: true
+global:
+  tunning:
+    cpu_affinity: [1, 3, 4]
+  limits:
+    max_memory: 768
+outputs:
+  npb:
+    max_mtu: 1500
+`
+	diffs, err := DiffYAML([]byte(from), []byte(to))
+	assert.Nil(t, err)
+	assert.Equal(t, []YAMLDiff{
+		{Path: "global.limits.max_millicpus", Type: YAML_DIFF_REMOVED, From: 1000},
+		{Path: "global.tunning.cpu_affinity[1]", Type: YAML_DIFF_MODIFIED, From: 2, To: 3},
+		{Path: "global.tunning.cpu_affinity[2]", Type: YAML_DIFF_ADDED, To: 4},
+		{Path: "inputs.proc.enabled", Type: YAML_DIFF_MODIFIED, From: false, To: true},
+		{Path: "outputs", Type: YAML_DIFF_ADDED, To: map[string]interface{}{"npb": map[string]interface{}{"max_mtu": 1500}}},
+	}, diffs)
+
+	diffs, err = DiffYAML([]byte(from), []byte(from))
+	assert.Nil(t, err)
+	assert.Empty(t, diffs)
+
+	diffs, err = DiffYAML(nil, []byte("global:\n  enabled: true\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []YAMLDiff{
+		{Path: "global", Type: YAML_DIFF_ADDED, To: map[string]interface{}{"enabled": true}},
+	}, diffs)
+
+	_, err = DiffYAML([]byte("global: [1"), nil)
+	assert.NotNil(t, err)
+}
@@ -38,6 +38,17 @@ type Config struct {
 	MaxCPUs             int                 `yaml:"max-cpus"`
 	MonitorPaths        []string            `yaml:"monitor-paths"`
 	FreeOSMemoryManager FreeOSMemoryManager `yaml:"free-os-memory-manager"`
+	DebugServer         DebugServer         `yaml:"debug-server"`
+}
+
+type DebugServer struct {
+	Enabled         bool   `yaml:"enabled"`
+	ListenPort      int    `yaml:"listen-port"`
+	Token           string `yaml:"token"`
+	TLSCertFile     string `yaml:"tls-cert-file"`
+	TLSKeyFile      string `yaml:"tls-key-file"`
+	TLSClientCAFile string `yaml:"tls-client-ca-file"`
+	UDPDisabled     bool   `yaml:"udp-disabled"`
 }
 
 type FreeOSMemoryManager struct {
@@ -68,6 +79,9 @@ func loadConfig(path string) *Config {
 		},
 		MonitorPaths:        []string{"/", "/mnt", "/var/log"},
 		FreeOSMemoryManager: FreeOSMemoryManager{false, DEFAULT_FREE_INTERVAL_SECOND},
+		DebugServer: DebugServer{
+			ListenPort: ingesterctl.DEBUG_STREAM_LISTEN_PORT,
+		},
 	}
 	configBytes, err := ioutil.ReadFile(path)
 	if err != nil {
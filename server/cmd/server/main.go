@@ -29,6 +29,7 @@ import (
 
 	"github.com/khulnasoft/deepflow/server/common"
 	"github.com/khulnasoft/deepflow/server/controller/controller"
+	"github.com/khulnasoft/deepflow/server/controller/election"
 	"github.com/khulnasoft/deepflow/server/controller/report"
 	"github.com/khulnasoft/deepflow/server/controller/trisolaris/utils"
 	"github.com/khulnasoft/deepflow/server/ingester/droplet/profiler"
@@ -36,11 +37,19 @@ import (
 	"github.com/khulnasoft/deepflow/server/ingester/ingesterctl"
 	"github.com/khulnasoft/deepflow/server/libs/debug"
 	"github.com/khulnasoft/deepflow/server/libs/logger"
+	prometheus_service "github.com/khulnasoft/deepflow/server/querier/app/prometheus/service"
 	"github.com/khulnasoft/deepflow/server/querier/querier"
 
 	logging "github.com/op/go-logging"
 )
 
+// controllerLeaderChecker tells the querier whether the controller in this deepflow-server is the leader
+type controllerLeaderChecker struct{}
+
+func (controllerLeaderChecker) IsLeader() (bool, error) {
+	return election.IsMasterController()
+}
+
 func execName() string {
 	splitted := strings.Split(os.Args[0], "/")
 	return splitted[len(splitted)-1]
@@ -81,7 +90,19 @@ func main() {
 	log.Infof("deepflow-server config: %+v", *cfg)
 
 	debug.SetIpAndPort(ingesterctl.DEBUG_LISTEN_IP, ingesterctl.DEBUG_LISTEN_PORT)
+	debug.SetUDPDisabled(cfg.DebugServer.Enabled && cfg.DebugServer.UDPDisabled)
 	debug.NewLogLevelControl()
+	if cfg.DebugServer.Enabled {
+		if err := debug.StartStreamServer(debug.StreamServerConfig{
+			Port:         cfg.DebugServer.ListenPort,
+			Token:        cfg.DebugServer.Token,
+			CertFile:     cfg.DebugServer.TLSCertFile,
+			KeyFile:      cfg.DebugServer.TLSKeyFile,
+			ClientCAFile: cfg.DebugServer.TLSClientCAFile,
+		}); err != nil {
+			log.Errorf("start debug stream server failed: %s", err)
+		}
+	}
 	profiler := profiler.NewProfiler(PROFILER_PORT)
 	if cfg.Profiler {
 		runtime.SetMutexProfileFraction(1)
@@ -103,12 +124,13 @@ func main() {
 	}()
 
 	report.SetServerInfo(Branch, RevCount, Revision)
+	prometheus_service.SetBuildInfo(Branch, Revision, CompileTime)
 
 	shared := common.NewControllerIngesterShared()
 
 	go controller.Start(ctx, *configPath, cfg.LogFile, shared)
 
-	go querier.Start(*configPath, cfg.LogFile, shared)
+	go querier.Start(ctx, *configPath, cfg.LogFile, shared, controllerLeaderChecker{})
 	closers := ingester.Start(*configPath, shared)
 
 	common.NewMonitor(cfg.MonitorPaths)
@@ -0,0 +1,296 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitly/go-simplejson"
+
+	cloudcommon "github.com/khulnasoft/deepflow/server/controller/cloud/common"
+	cloudconfig "github.com/khulnasoft/deepflow/server/controller/cloud/config"
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	mysqlmodel "github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+var log = logger.MustGetLogger("cloud.azure")
+
+const (
+	DEFAULT_LOGIN_ENDPOINT            = "https://login.microsoftonline.com"
+	DEFAULT_RESOURCE_MANAGER_ENDPOINT = "https://management.azure.com"
+
+	API_VERSION_SUBSCRIPTION = "2022-12-01"
+	API_VERSION_NETWORK      = "2023-09-01"
+	API_VERSION_COMPUTE      = "2023-09-01"
+)
+
+var vmStates = map[string]int{
+	"PowerState/running":     common.VM_STATE_RUNNING,
+	"PowerState/stopped":     common.VM_STATE_STOPPED,
+	"PowerState/deallocated": common.VM_STATE_STOPPED,
+}
+
+type Azure struct {
+	orgID                   int
+	teamID                  int
+	name                    string
+	lcuuid                  string
+	regionLcuuid            string
+	uuidGenerate            string
+	tenantID                string
+	clientID                string
+	clientSecret            string
+	subscriptionID          string
+	loginEndpoint           string
+	resourceManagerEndpoint string
+	includeRegions          map[string]bool
+	excludeRegions          map[string]bool
+	httpClient              *http.Client
+	debugger                *cloudcommon.Debugger
+
+	token         string
+	tokenExpireAt time.Time
+
+	// 以下属性为获取资源所用的关联关系
+	locationToRegionLcuuid    map[string]string
+	azLcuuidToAZ              map[string]model.AZ
+	regionLcuuidToResourceNum map[string]int
+	subnetIDToNetwork         map[string]model.Network
+	networkLcuuidToSubnets    map[string][]model.Subnet
+	publicIPIDToIP            map[string]string
+	nics                      []*simplejson.Json
+	vmIDToLcuuid              map[string]string
+	vmLcuuidToVPCLcuuid       map[string]string
+	ipConfigIDToIP            map[string]nicIP
+}
+
+// nicIP is the ip configuration of the network interface, which may be the backend of load balancers
+type nicIP struct {
+	ip       string
+	vmLcuuid string
+}
+
+func NewAzure(orgID int, domain mysqlmodel.Domain, cfg cloudconfig.CloudConfig) (*Azure, error) {
+	config, err := simplejson.NewJson([]byte(domain.Config))
+	if err != nil {
+		log.Error(err, logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+
+	tenantID, err := config.Get("tenant_id").String()
+	if err != nil {
+		log.Error("tenant_id must be specified", logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+
+	clientID, err := config.Get("client_id").String()
+	if err != nil {
+		log.Error("client_id must be specified", logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+
+	clientSecret, err := config.Get("client_secret").String()
+	if err != nil {
+		log.Error("client_secret must be specified", logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+	decryptClientSecret, err := common.DecryptSecretKey(clientSecret)
+	if err != nil {
+		log.Error("decrypt client_secret failed (%s)", err.Error(), logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+
+	subscriptionID, err := config.Get("subscription_id").String()
+	if err != nil {
+		log.Error("subscription_id must be specified", logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+
+	// the endpoints of sovereign clouds are different, e.g. 'https://login.chinacloudapi.cn'
+	loginEndpoint := config.Get("login_endpoint").MustString()
+	if loginEndpoint == "" {
+		loginEndpoint = DEFAULT_LOGIN_ENDPOINT
+	}
+	resourceManagerEndpoint := config.Get("resource_manager_endpoint").MustString()
+	if resourceManagerEndpoint == "" {
+		resourceManagerEndpoint = DEFAULT_RESOURCE_MANAGER_ENDPOINT
+	}
+
+	return &Azure{
+		orgID:                   orgID,
+		teamID:                  domain.TeamID,
+		name:                    domain.Name,
+		lcuuid:                  domain.Lcuuid,
+		uuidGenerate:            domain.DisplayName,
+		regionLcuuid:            config.Get("region_uuid").MustString(),
+		tenantID:                tenantID,
+		clientID:                clientID,
+		clientSecret:            decryptClientSecret,
+		subscriptionID:          subscriptionID,
+		loginEndpoint:           strings.TrimSuffix(loginEndpoint, "/"),
+		resourceManagerEndpoint: strings.TrimSuffix(resourceManagerEndpoint, "/"),
+		includeRegions:          cloudcommon.UniqRegions(config.Get("include_regions").MustString()),
+		excludeRegions:          cloudcommon.UniqRegions(config.Get("exclude_regions").MustString()),
+		httpClient: &http.Client{
+			Timeout: time.Duration(cfg.HTTPTimeout) * time.Second,
+		},
+		debugger: cloudcommon.NewDebugger(domain.Name),
+	}, nil
+}
+
+func (a *Azure) CheckAuth() error {
+	_, err := a.getToken()
+	return err
+}
+
+func (a *Azure) ClearDebugLog() {
+	a.debugger.Clear()
+}
+
+// the resource ids of azure are case insensitive
+func (a *Azure) idToLcuuid(id string) string {
+	return common.GetUUIDByOrgID(a.orgID, strings.ToLower(id))
+}
+
+func (a *Azure) getRegionLcuuid(location string) string {
+	if a.regionLcuuid != "" {
+		return a.regionLcuuid
+	}
+	return a.locationToRegionLcuuid[strings.ToLower(location)]
+}
+
+// checkRegion returns the region lcuuid of the location, and whether the location is collected
+func (a *Azure) checkRegion(location string) (string, bool) {
+	location = strings.ToLower(location)
+	if _, ok := a.locationToRegionLcuuid[location]; !ok {
+		return "", false
+	}
+	regionLcuuid := a.getRegionLcuuid(location)
+	a.regionLcuuidToResourceNum[regionLcuuid]++
+	return regionLcuuid, true
+}
+
+// getAZLcuuid returns the lcuuid of zone in the location, the resources without zone belong to the az named by location
+func (a *Azure) getAZLcuuid(location string, zones []interface{}) string {
+	location = strings.ToLower(location)
+	azName := location
+	if len(zones) > 0 {
+		if zone, ok := zones[0].(string); ok && zone != "" {
+			azName = location + "-" + zone
+		}
+	}
+	lcuuid := common.GetUUIDByOrgID(a.orgID, a.uuidGenerate+"_"+azName)
+	if _, ok := a.azLcuuidToAZ[lcuuid]; !ok {
+		a.azLcuuidToAZ[lcuuid] = model.AZ{
+			Lcuuid:       lcuuid,
+			Name:         azName,
+			Label:        azName,
+			RegionLcuuid: a.getRegionLcuuid(location),
+		}
+	}
+	return lcuuid
+}
+
+func (a *Azure) GetCloudData() (model.Resource, error) {
+	var resource model.Resource
+	a.locationToRegionLcuuid = map[string]string{}
+	a.azLcuuidToAZ = map[string]model.AZ{}
+	a.regionLcuuidToResourceNum = map[string]int{}
+	a.subnetIDToNetwork = map[string]model.Network{}
+	a.networkLcuuidToSubnets = map[string][]model.Subnet{}
+	a.publicIPIDToIP = map[string]string{}
+	a.nics = nil
+	a.vmIDToLcuuid = map[string]string{}
+	a.vmLcuuidToVPCLcuuid = map[string]string{}
+	a.ipConfigIDToIP = map[string]nicIP{}
+
+	regions, err := a.getRegions()
+	if err != nil {
+		return model.Resource{}, err
+	}
+
+	vpcs, networks, subnets, peerConnections, err := a.getVPCs()
+	if err != nil {
+		return model.Resource{}, err
+	}
+	resource.VPCs = append(resource.VPCs, vpcs...)
+	resource.Networks = append(resource.Networks, networks...)
+	resource.Subnets = append(resource.Subnets, subnets...)
+	resource.PeerConnections = append(resource.PeerConnections, peerConnections...)
+
+	err = a.getPublicIPs()
+	if err != nil {
+		return model.Resource{}, err
+	}
+
+	vms, err := a.getVMs()
+	if err != nil {
+		return model.Resource{}, err
+	}
+	resource.VMs = append(resource.VMs, vms...)
+
+	vinterfaces, ips, natRules, err := a.getVInterfaces()
+	if err != nil {
+		return model.Resource{}, err
+	}
+	resource.VInterfaces = append(resource.VInterfaces, vinterfaces...)
+	resource.IPs = append(resource.IPs, ips...)
+	resource.NATRules = append(resource.NATRules, natRules...)
+
+	natGateways, natVInterfaces, natIPs, err := a.getNATGateways()
+	if err != nil {
+		return model.Resource{}, err
+	}
+	resource.NATGateways = append(resource.NATGateways, natGateways...)
+	resource.VInterfaces = append(resource.VInterfaces, natVInterfaces...)
+	resource.IPs = append(resource.IPs, natIPs...)
+
+	lbs, lbListeners, lbTargetServers, lbVInterfaces, lbIPs, err := a.getLBs()
+	if err != nil {
+		return model.Resource{}, err
+	}
+	resource.LBs = append(resource.LBs, lbs...)
+	resource.LBListeners = append(resource.LBListeners, lbListeners...)
+	resource.LBTargetServers = append(resource.LBTargetServers, lbTargetServers...)
+	resource.VInterfaces = append(resource.VInterfaces, lbVInterfaces...)
+	resource.IPs = append(resource.IPs, lbIPs...)
+
+	azLcuuidToResourceNum := map[string]int{}
+	for _, vm := range resource.VMs {
+		azLcuuidToResourceNum[vm.AZLcuuid]++
+	}
+	for _, network := range resource.Networks {
+		azLcuuidToResourceNum[network.AZLcuuid]++
+	}
+	azs := []model.AZ{}
+	for _, az := range a.azLcuuidToAZ {
+		azs = append(azs, az)
+	}
+	sort.Slice(azs, func(i, j int) bool { return azs[i].Name < azs[j].Name })
+	resource.AZs = cloudcommon.EliminateEmptyAZs(azs, azLcuuidToResourceNum)
+	if a.regionLcuuid == "" {
+		resource.Regions = cloudcommon.EliminateEmptyRegions(regions, a.regionLcuuidToResourceNum)
+	}
+
+	a.debugger.Refresh()
+	return resource, nil
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	cloudcommon "github.com/khulnasoft/deepflow/server/controller/cloud/common"
+	"github.com/khulnasoft/deepflow/server/controller/cloud/config"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	mysqlcommon "github.com/khulnasoft/deepflow/server/controller/db/mysql/common"
+)
+
+// newTestServer serves the recorded responses in testdata, as a stand-in of the login and resource manager endpoints
+func newTestServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var fixture string
+		switch {
+		case r.URL.Path == "/tenant-1/oauth2/v2.0/token":
+			fixture = "token.json"
+		case r.Header.Get("Authorization") != "Bearer test-token":
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case r.URL.Query().Get("$skiptoken") == "page2":
+			fixture = "virtualMachines_page2.json"
+		default:
+			fixture = r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:] + ".json"
+		}
+		content, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(strings.ReplaceAll(string(content), "{{endpoint}}", server.URL)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestAzure(endpoint string) *Azure {
+	return &Azure{
+		orgID:                   mysqlcommon.DEFAULT_ORG_ID,
+		name:                    "test_azure",
+		uuidGenerate:            "test_azure",
+		tenantID:                "tenant-1",
+		clientID:                "client-1",
+		clientSecret:            "secret-1",
+		subscriptionID:          "sub-1",
+		loginEndpoint:           endpoint,
+		resourceManagerEndpoint: endpoint,
+		includeRegions:          map[string]bool{},
+		excludeRegions:          cloudcommon.UniqRegions("West Europe"),
+		httpClient:              &http.Client{},
+		debugger:                cloudcommon.NewDebugger("test_azure"),
+	}
+}
+
+func TestAzure(t *testing.T) {
+	Convey("TestAzure", t, func() {
+		config.CONF = &config.CloudConfig{}
+		server := newTestServer(t)
+		azure := newTestAzure(server.URL)
+
+		So(azure.CheckAuth(), ShouldBeNil)
+		data, err := azure.GetCloudData()
+		So(err, ShouldBeNil)
+
+		Convey("regions and azs should exclude empty ones", func() {
+			So(len(data.Regions), ShouldEqual, 1)
+			So(data.Regions[0].Name, ShouldEqual, "East US")
+			So(len(data.AZs), ShouldEqual, 2)
+			So(data.AZs[0].Name, ShouldEqual, "eastus")
+			So(data.AZs[1].Name, ShouldEqual, "eastus-1")
+		})
+
+		Convey("vnets and subnets should be collected", func() {
+			So(len(data.VPCs), ShouldEqual, 2)
+			So(len(data.Networks), ShouldEqual, 3)
+			So(len(data.Subnets), ShouldEqual, 4)
+			So(len(data.PeerConnections), ShouldEqual, 1)
+		})
+
+		Convey("vms should be collected across pages", func() {
+			So(len(data.VMs), ShouldEqual, 2)
+			vms := map[string]int{}
+			for _, vm := range data.VMs {
+				vms[vm.Name] = vm.State
+			}
+			So(vms["vm-web"], ShouldEqual, common.VM_STATE_RUNNING)
+			So(vms["vm-api"], ShouldEqual, common.VM_STATE_STOPPED)
+			So(data.VMs[0].IP, ShouldEqual, "10.1.0.4")
+			So(data.VMs[0].Hostname, ShouldEqual, "web")
+			So(data.VMs[0].CloudTags["env"], ShouldEqual, "prod")
+		})
+
+		Convey("vinterfaces of vms should be collected", func() {
+			vmVInterfaces := 0
+			for _, vinterface := range data.VInterfaces {
+				if vinterface.DeviceType == common.VIF_DEVICE_TYPE_VM {
+					vmVInterfaces++
+				}
+			}
+			So(vmVInterfaces, ShouldEqual, 3)
+			So(data.VInterfaces[0].Mac, ShouldEqual, "00:0d:3a:00:00:01")
+			So(data.VInterfaces[1].Mac, ShouldEqual, "ff:0d:3a:00:00:01")
+			So(len(data.NATRules), ShouldEqual, 1)
+			So(data.NATRules[0].FloatingIP, ShouldEqual, "20.1.1.1")
+			for _, ip := range data.IPs {
+				if ip.IP == "10.1.2.5" {
+					So(ip.SubnetLcuuid, ShouldNotBeEmpty)
+				}
+			}
+		})
+
+		Convey("nat gateways and lbs should be collected", func() {
+			So(len(data.NATGateways), ShouldEqual, 1)
+			So(data.NATGateways[0].FloatingIPs, ShouldEqual, "20.1.1.2")
+			So(len(data.LBs), ShouldEqual, 1)
+			So(data.LBs[0].Model, ShouldEqual, common.LB_MODEL_EXTERNAL)
+			So(data.LBs[0].VPCLcuuid, ShouldEqual, data.VMs[0].VPCLcuuid)
+			So(len(data.LBListeners), ShouldEqual, 1)
+			So(data.LBListeners[0].Protocol, ShouldEqual, "TCP")
+			So(len(data.LBTargetServers), ShouldEqual, 1)
+			So(data.LBTargetServers[0].IP, ShouldEqual, "10.1.0.4")
+			So(data.LBTargetServers[0].Port, ShouldEqual, 8080)
+		})
+	})
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bitly/go-simplejson"
+
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+const (
+	// refresh the token a little earlier than it expires
+	TOKEN_EXPIRE_MARGIN = 5 * time.Minute
+)
+
+func newErr(url, msg string) error {
+	return errors.New(fmt.Sprintf("request url: %s, %s", url, msg))
+}
+
+func (a *Azure) doRequest(req *http.Request) (*simplejson.Json, error) {
+	url := req.URL.String()
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, newErr(url, fmt.Sprintf("failed: %s", err.Error()))
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, newErr(url, fmt.Sprintf("read failed: %s", err.Error()))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newErr(url, fmt.Sprintf("failed: status (%d) body (%s)", resp.StatusCode, string(respBody)))
+	}
+	jsonResp, err := simplejson.NewJson(respBody)
+	if err != nil {
+		return nil, newErr(url, fmt.Sprintf("JSONiz failed: %s", err.Error()))
+	}
+	return jsonResp, nil
+}
+
+// getToken gets the access token of the service principal by the client credentials flow
+func (a *Azure) getToken() (string, error) {
+	if a.token != "" && time.Now().Before(a.tokenExpireAt) {
+		return a.token, nil
+	}
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", a.loginEndpoint, a.tenantID)
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", a.clientID)
+	form.Set("client_secret", a.clientSecret)
+	form.Set("scope", a.resourceManagerEndpoint+"/.default")
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := a.doRequest(req)
+	if err != nil {
+		log.Errorf("get token failed: %s", err.Error(), logger.NewORGPrefix(a.orgID))
+		return "", err
+	}
+	token := resp.Get("access_token").MustString()
+	if token == "" {
+		return "", newErr(tokenURL, "no access_token in response")
+	}
+	a.token = token
+	a.tokenExpireAt = time.Now().Add(time.Duration(resp.Get("expires_in").MustInt(3600))*time.Second - TOKEN_EXPIRE_MARGIN)
+	return token, nil
+}
+
+// getRawData lists the resources of the subscription, the pages are followed by 'nextLink'
+func (a *Azure) getRawData(resultKey, path, apiVersion string) ([]*simplejson.Json, error) {
+	startTime := time.Now()
+	var jsonList []*simplejson.Json
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	requestURL := fmt.Sprintf("%s/subscriptions/%s%s%sapi-version=%s", a.resourceManagerEndpoint, a.subscriptionID, path, separator, apiVersion)
+	for requestURL != "" {
+		token, err := a.getToken()
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+		resp, err := a.doRequest(req)
+		if err != nil {
+			log.Error(err.Error(), logger.NewORGPrefix(a.orgID))
+			return nil, err
+		}
+		values := resp.Get("value")
+		for i := range values.MustArray() {
+			jsonList = append(jsonList, values.GetIndex(i))
+		}
+		requestURL = resp.Get("nextLink").MustString()
+	}
+	log.Debugf("get %s count (%d) cost (%s)", resultKey, len(jsonList), time.Since(startTime), logger.NewORGPrefix(a.orgID))
+	a.debugger.WriteJson(resultKey, path, jsonList)
+	return jsonList, nil
+}
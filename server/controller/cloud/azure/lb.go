@@ -0,0 +1,202 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"strings"
+
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+type lbFrontend struct {
+	ip       string
+	isPublic bool
+}
+
+// getLBs gets the load balancers, the load balancing rules are the listeners, and the ip configurations in
+// the backend pools are the target servers
+func (a *Azure) getLBs() ([]model.LB, []model.LBListener, []model.LBTargetServer, []model.VInterface, []model.IP, error) {
+	log.Debug("get lbs starting", logger.NewORGPrefix(a.orgID))
+	var lbs []model.LB
+	var lbListeners []model.LBListener
+	var lbTargetServers []model.LBTargetServer
+	var lbVInterfaces []model.VInterface
+	var lbIPs []model.IP
+
+	jLBs, err := a.getRawData("loadBalancers", "/providers/Microsoft.Network/loadBalancers", API_VERSION_NETWORK)
+	if err != nil {
+		return []model.LB{}, []model.LBListener{}, []model.LBTargetServer{}, []model.VInterface{}, []model.IP{}, err
+	}
+	for _, jLB := range jLBs {
+		lbID := strings.ToLower(jLB.Get("id").MustString())
+		lbName := jLB.Get("name").MustString()
+		regionLcuuid, ok := a.checkRegion(jLB.Get("location").MustString())
+		if !ok {
+			continue
+		}
+		lbLcuuid := a.idToLcuuid(lbID)
+		properties := jLB.Get("properties")
+
+		var vpcLcuuid string
+		frontendIDToFrontend := map[string]lbFrontend{}
+		var privateNetworks []model.Network
+		var privateIPs, publicIPs []string
+		jFrontends := properties.Get("frontendIPConfigurations")
+		for i := range jFrontends.MustArray() {
+			jFrontend := jFrontends.GetIndex(i)
+			frontendID := strings.ToLower(jFrontend.Get("id").MustString())
+			jProperties := jFrontend.Get("properties")
+			if publicIP, ok := a.publicIPIDToIP[strings.ToLower(jProperties.Get("publicIPAddress").Get("id").MustString())]; ok {
+				frontendIDToFrontend[frontendID] = lbFrontend{ip: publicIP, isPublic: true}
+				publicIPs = append(publicIPs, publicIP)
+				continue
+			}
+			privateIP := jProperties.Get("privateIPAddress").MustString()
+			network, ok := a.subnetIDToNetwork[strings.ToLower(jProperties.Get("subnet").Get("id").MustString())]
+			if privateIP == "" || !ok {
+				continue
+			}
+			frontendIDToFrontend[frontendID] = lbFrontend{ip: privateIP}
+			privateNetworks = append(privateNetworks, network)
+			privateIPs = append(privateIPs, privateIP)
+			vpcLcuuid = network.VPCLcuuid
+		}
+
+		backendIDToIPs := map[string][]nicIP{}
+		jPools := properties.Get("backendAddressPools")
+		for i := range jPools.MustArray() {
+			jPool := jPools.GetIndex(i)
+			poolID := strings.ToLower(jPool.Get("id").MustString())
+			jIPConfigs := jPool.Get("properties").Get("backendIPConfigurations")
+			for j := range jIPConfigs.MustArray() {
+				if ip, ok := a.ipConfigIDToIP[strings.ToLower(jIPConfigs.GetIndex(j).Get("id").MustString())]; ok {
+					backendIDToIPs[poolID] = append(backendIDToIPs[poolID], ip)
+				}
+			}
+		}
+		// the public load balancers have no private frontend, use the vpc of backends
+		if vpcLcuuid == "" {
+			for _, ips := range backendIDToIPs {
+				for _, ip := range ips {
+					vpcLcuuid = a.vmLcuuidToVPCLcuuid[ip.vmLcuuid]
+					break
+				}
+			}
+		}
+		if vpcLcuuid == "" {
+			log.Infof("lb (%s) vpc not found", lbName, logger.NewORGPrefix(a.orgID))
+			continue
+		}
+
+		lbModel := common.LB_MODEL_INTERNAL
+		if len(publicIPs) > 0 {
+			lbModel = common.LB_MODEL_EXTERNAL
+		}
+		lbs = append(lbs, model.LB{
+			Lcuuid:       lbLcuuid,
+			Name:         lbName,
+			Label:        lbID,
+			Model:        lbModel,
+			VIP:          strings.Join(append(privateIPs, publicIPs...), ","),
+			VPCLcuuid:    vpcLcuuid,
+			RegionLcuuid: regionLcuuid,
+		})
+
+		for i, ip := range privateIPs {
+			vinterfaceLcuuid := common.GetUUIDByOrgID(a.orgID, lbLcuuid+ip)
+			lbVInterfaces = append(lbVInterfaces, model.VInterface{
+				Lcuuid:        vinterfaceLcuuid,
+				Type:          common.VIF_TYPE_LAN,
+				Mac:           common.VIF_DEFAULT_MAC,
+				DeviceLcuuid:  lbLcuuid,
+				DeviceType:    common.VIF_DEVICE_TYPE_LB,
+				NetworkLcuuid: privateNetworks[i].Lcuuid,
+				VPCLcuuid:     vpcLcuuid,
+				RegionLcuuid:  regionLcuuid,
+			})
+			lbIPs = append(lbIPs, model.IP{
+				Lcuuid:           common.GetUUIDByOrgID(a.orgID, vinterfaceLcuuid+ip),
+				VInterfaceLcuuid: vinterfaceLcuuid,
+				IP:               ip,
+				SubnetLcuuid:     a.getSubnetLcuuid(privateNetworks[i].Lcuuid, ip),
+				RegionLcuuid:     regionLcuuid,
+			})
+		}
+		if len(publicIPs) > 0 {
+			vinterfaceLcuuid := common.GetUUIDByOrgID(a.orgID, lbLcuuid)
+			lbVInterfaces = append(lbVInterfaces, model.VInterface{
+				Lcuuid:        vinterfaceLcuuid,
+				Type:          common.VIF_TYPE_WAN,
+				Mac:           common.VIF_DEFAULT_MAC,
+				DeviceLcuuid:  lbLcuuid,
+				DeviceType:    common.VIF_DEVICE_TYPE_LB,
+				NetworkLcuuid: common.NETWORK_ISP_LCUUID,
+				VPCLcuuid:     vpcLcuuid,
+				RegionLcuuid:  regionLcuuid,
+			})
+			for _, ip := range publicIPs {
+				lbIPs = append(lbIPs, model.IP{
+					Lcuuid:           common.GetUUIDByOrgID(a.orgID, vinterfaceLcuuid+ip),
+					VInterfaceLcuuid: vinterfaceLcuuid,
+					IP:               ip,
+					RegionLcuuid:     regionLcuuid,
+				})
+			}
+		}
+
+		jRules := properties.Get("loadBalancingRules")
+		for i := range jRules.MustArray() {
+			jRule := jRules.GetIndex(i)
+			ruleID := strings.ToLower(jRule.Get("id").MustString())
+			jProperties := jRule.Get("properties")
+			frontend, ok := frontendIDToFrontend[strings.ToLower(jProperties.Get("frontendIPConfiguration").Get("id").MustString())]
+			if !ok {
+				continue
+			}
+			protocol := strings.ToUpper(jProperties.Get("protocol").MustString())
+			listenerLcuuid := a.idToLcuuid(ruleID)
+			lbListeners = append(lbListeners, model.LBListener{
+				Lcuuid:   listenerLcuuid,
+				LBLcuuid: lbLcuuid,
+				Name:     jRule.Get("name").MustString(),
+				Label:    ruleID,
+				IPs:      frontend.ip,
+				Protocol: protocol,
+				Port:     jProperties.Get("frontendPort").MustInt(),
+			})
+
+			backendPort := jProperties.Get("backendPort").MustInt()
+			for _, ip := range backendIDToIPs[strings.ToLower(jProperties.Get("backendAddressPool").Get("id").MustString())] {
+				lbTargetServers = append(lbTargetServers, model.LBTargetServer{
+					Lcuuid:           common.GetUUIDByOrgID(a.orgID, listenerLcuuid+ip.ip),
+					LBLcuuid:         lbLcuuid,
+					LBListenerLcuuid: listenerLcuuid,
+					Type:             common.LB_SERVER_TYPE_VM,
+					IP:               ip.ip,
+					VMLcuuid:         ip.vmLcuuid,
+					Protocol:         protocol,
+					Port:             backendPort,
+					VPCLcuuid:        vpcLcuuid,
+				})
+			}
+		}
+	}
+	log.Debug("get lbs complete", logger.NewORGPrefix(a.orgID))
+	return lbs, lbListeners, lbTargetServers, lbVInterfaces, lbIPs, nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"strings"
+
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+// getNATGateways gets the nat gateways, whose vpc is the vnet of the associated subnets
+func (a *Azure) getNATGateways() ([]model.NATGateway, []model.VInterface, []model.IP, error) {
+	log.Debug("get nat gateways starting", logger.NewORGPrefix(a.orgID))
+	var natGateways []model.NATGateway
+	var natVInterfaces []model.VInterface
+	var natIPs []model.IP
+
+	jNATGateways, err := a.getRawData("natGateways", "/providers/Microsoft.Network/natGateways", API_VERSION_NETWORK)
+	if err != nil {
+		return []model.NATGateway{}, []model.VInterface{}, []model.IP{}, err
+	}
+	for _, jNATGateway := range jNATGateways {
+		natGatewayID := strings.ToLower(jNATGateway.Get("id").MustString())
+		natGatewayName := jNATGateway.Get("name").MustString()
+		regionLcuuid, ok := a.checkRegion(jNATGateway.Get("location").MustString())
+		if !ok {
+			continue
+		}
+		properties := jNATGateway.Get("properties")
+		var vpcLcuuid string
+		jSubnets := properties.Get("subnets")
+		for i := range jSubnets.MustArray() {
+			if network, ok := a.subnetIDToNetwork[strings.ToLower(jSubnets.GetIndex(i).Get("id").MustString())]; ok {
+				vpcLcuuid = network.VPCLcuuid
+				break
+			}
+		}
+		if vpcLcuuid == "" {
+			log.Infof("nat gateway (%s) not associated with subnet", natGatewayName, logger.NewORGPrefix(a.orgID))
+			continue
+		}
+		floatingIPs := []string{}
+		jPublicIPs := properties.Get("publicIpAddresses")
+		for i := range jPublicIPs.MustArray() {
+			if ip, ok := a.publicIPIDToIP[strings.ToLower(jPublicIPs.GetIndex(i).Get("id").MustString())]; ok {
+				floatingIPs = append(floatingIPs, ip)
+			}
+		}
+
+		natGatewayLcuuid := a.idToLcuuid(natGatewayID)
+		natGateways = append(natGateways, model.NATGateway{
+			Lcuuid:       natGatewayLcuuid,
+			Name:         natGatewayName,
+			Label:        natGatewayID,
+			FloatingIPs:  strings.Join(floatingIPs, ","),
+			VPCLcuuid:    vpcLcuuid,
+			RegionLcuuid: regionLcuuid,
+		})
+
+		vinterfaceLcuuid := common.GetUUIDByOrgID(a.orgID, natGatewayLcuuid)
+		natVInterfaces = append(natVInterfaces, model.VInterface{
+			Lcuuid:        vinterfaceLcuuid,
+			Type:          common.VIF_TYPE_WAN,
+			Mac:           common.VIF_DEFAULT_MAC,
+			DeviceLcuuid:  natGatewayLcuuid,
+			DeviceType:    common.VIF_DEVICE_TYPE_NAT_GATEWAY,
+			NetworkLcuuid: common.NETWORK_ISP_LCUUID,
+			VPCLcuuid:     vpcLcuuid,
+			RegionLcuuid:  regionLcuuid,
+		})
+		for _, ip := range floatingIPs {
+			natIPs = append(natIPs, model.IP{
+				Lcuuid:           common.GetUUIDByOrgID(a.orgID, vinterfaceLcuuid+ip),
+				VInterfaceLcuuid: vinterfaceLcuuid,
+				IP:               ip,
+				RegionLcuuid:     regionLcuuid,
+			})
+		}
+	}
+	log.Debug("get nat gateways complete", logger.NewORGPrefix(a.orgID))
+	return natGateways, natVInterfaces, natIPs, nil
+}
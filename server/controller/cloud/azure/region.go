@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"strings"
+
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+func (a *Azure) getRegions() ([]model.Region, error) {
+	log.Debug("get regions starting", logger.NewORGPrefix(a.orgID))
+	var regions []model.Region
+
+	locations, err := a.getRawData("locations", "/locations", API_VERSION_SUBSCRIPTION)
+	if err != nil {
+		return []model.Region{}, err
+	}
+	for _, location := range locations {
+		name := strings.ToLower(location.Get("name").MustString())
+		if name == "" {
+			continue
+		}
+		// the logical locations such as 'global' have no resources
+		if regionType := location.Get("metadata").Get("regionType").MustString(); regionType != "" && regionType != "Physical" {
+			continue
+		}
+		displayName := location.Get("displayName").MustString()
+		// 区域白名单，如果当前区域不在白名单中，则跳过
+		if len(a.includeRegions) > 0 {
+			_, nameIncluded := a.includeRegions[name]
+			_, displayNameIncluded := a.includeRegions[displayName]
+			if !nameIncluded && !displayNameIncluded {
+				log.Infof("region (%s) not in include_regions", name, logger.NewORGPrefix(a.orgID))
+				continue
+			}
+		}
+		// 区域黑名单，如果当前区域在黑名单中，则跳过
+		_, nameExcluded := a.excludeRegions[name]
+		_, displayNameExcluded := a.excludeRegions[displayName]
+		if nameExcluded || displayNameExcluded {
+			log.Infof("region (%s) in exclude_regions", name, logger.NewORGPrefix(a.orgID))
+			continue
+		}
+		if displayName == "" {
+			displayName = name
+		}
+		region := model.Region{
+			Lcuuid: common.GenerateUUIDByOrgID(a.orgID, name+"_"+a.uuidGenerate),
+			Label:  name,
+			Name:   displayName,
+		}
+		regions = append(regions, region)
+		a.locationToRegionLcuuid[name] = region.Lcuuid
+	}
+	log.Debug("get regions complete", logger.NewORGPrefix(a.orgID))
+	return regions, nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"strings"
+
+	cloudcommon "github.com/khulnasoft/deepflow/server/controller/cloud/common"
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+func (a *Azure) getPublicIPs() error {
+	log.Debug("get public ips starting", logger.NewORGPrefix(a.orgID))
+	publicIPs, err := a.getRawData("publicIPAddresses", "/providers/Microsoft.Network/publicIPAddresses", API_VERSION_NETWORK)
+	if err != nil {
+		return err
+	}
+	for _, publicIP := range publicIPs {
+		ip := publicIP.Get("properties").Get("ipAddress").MustString()
+		if ip == "" {
+			continue
+		}
+		a.publicIPIDToIP[strings.ToLower(publicIP.Get("id").MustString())] = ip
+	}
+	log.Debug("get public ips complete", logger.NewORGPrefix(a.orgID))
+	return nil
+}
+
+// formatMac converts the mac such as '00-0D-3A-12-34-56' to '00:0d:3a:12:34:56'
+func formatMac(mac string) string {
+	if mac == "" {
+		return common.VIF_DEFAULT_MAC
+	}
+	return strings.ToLower(strings.ReplaceAll(mac, "-", ":"))
+}
+
+// getVInterfaces gets the network interfaces of vms, the public ips are bound as wan vinterfaces and dnat rules
+func (a *Azure) getVInterfaces() ([]model.VInterface, []model.IP, []model.NATRule, error) {
+	log.Debug("get vinterfaces starting", logger.NewORGPrefix(a.orgID))
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+	var natRules []model.NATRule
+
+	for _, nic := range a.nics {
+		nicID := strings.ToLower(nic.Get("id").MustString())
+		properties := nic.Get("properties")
+		vmLcuuid, ok := a.vmIDToLcuuid[strings.ToLower(properties.Get("virtualMachine").Get("id").MustString())]
+		if !ok {
+			log.Debugf("vinterface (%s) not binding vm", nicID, logger.NewORGPrefix(a.orgID))
+			continue
+		}
+		regionLcuuid := a.getRegionLcuuid(nic.Get("location").MustString())
+		mac := formatMac(properties.Get("macAddress").MustString())
+		vinterfaceLcuuid := a.idToLcuuid(nicID)
+		wanVInterfaceLcuuid := common.GetUUIDByOrgID(a.orgID, vinterfaceLcuuid)
+		hasWANVInterface := false
+		var vpcLcuuid string
+
+		jIPConfigs := properties.Get("ipConfigurations")
+		for i := range jIPConfigs.MustArray() {
+			jIPConfig := jIPConfigs.GetIndex(i)
+			jProperties := jIPConfig.Get("properties")
+			network, ok := a.subnetIDToNetwork[strings.ToLower(jProperties.Get("subnet").Get("id").MustString())]
+			if !ok {
+				continue
+			}
+			if vpcLcuuid == "" {
+				vpcLcuuid = network.VPCLcuuid
+				vinterfaces = append(vinterfaces, model.VInterface{
+					Lcuuid:        vinterfaceLcuuid,
+					Name:          nic.Get("name").MustString(),
+					Type:          common.VIF_TYPE_LAN,
+					Mac:           mac,
+					DeviceLcuuid:  vmLcuuid,
+					DeviceType:    common.VIF_DEVICE_TYPE_VM,
+					NetworkLcuuid: network.Lcuuid,
+					VPCLcuuid:     vpcLcuuid,
+					RegionLcuuid:  regionLcuuid,
+				})
+			}
+			privateIP := jProperties.Get("privateIPAddress").MustString()
+			if privateIP == "" {
+				continue
+			}
+			ips = append(ips, model.IP{
+				Lcuuid:           common.GetUUIDByOrgID(a.orgID, vinterfaceLcuuid+privateIP),
+				VInterfaceLcuuid: vinterfaceLcuuid,
+				IP:               privateIP,
+				SubnetLcuuid:     a.getSubnetLcuuid(network.Lcuuid, privateIP),
+				RegionLcuuid:     regionLcuuid,
+			})
+			a.ipConfigIDToIP[strings.ToLower(jIPConfig.Get("id").MustString())] = nicIP{
+				ip:       privateIP,
+				vmLcuuid: vmLcuuid,
+			}
+
+			publicIP, ok := a.publicIPIDToIP[strings.ToLower(jProperties.Get("publicIPAddress").Get("id").MustString())]
+			if !ok {
+				continue
+			}
+			if !hasWANVInterface {
+				hasWANVInterface = true
+				vinterfaces = append(vinterfaces, model.VInterface{
+					Lcuuid:        wanVInterfaceLcuuid,
+					Type:          common.VIF_TYPE_WAN,
+					Mac:           "ff" + mac[2:],
+					DeviceLcuuid:  vmLcuuid,
+					DeviceType:    common.VIF_DEVICE_TYPE_VM,
+					NetworkLcuuid: common.NETWORK_ISP_LCUUID,
+					VPCLcuuid:     vpcLcuuid,
+					RegionLcuuid:  regionLcuuid,
+				})
+			}
+			ips = append(ips, model.IP{
+				Lcuuid:           common.GetUUIDByOrgID(a.orgID, vinterfaceLcuuid+publicIP),
+				VInterfaceLcuuid: wanVInterfaceLcuuid,
+				IP:               publicIP,
+				RegionLcuuid:     regionLcuuid,
+			})
+			natRules = append(natRules, model.NATRule{
+				Lcuuid:           common.GetUUIDByOrgID(a.orgID, publicIP+vinterfaceLcuuid+privateIP),
+				Type:             "DNAT",
+				Protocol:         "ALL",
+				FloatingIP:       publicIP,
+				FixedIP:          privateIP,
+				VInterfaceLcuuid: vinterfaceLcuuid,
+			})
+		}
+	}
+	log.Debug("get vinterfaces complete", logger.NewORGPrefix(a.orgID))
+	return vinterfaces, ips, natRules, nil
+}
+
+// getSubnetLcuuid returns the subnet of network which contains the ip, since a network may have several cidrs
+func (a *Azure) getSubnetLcuuid(networkLcuuid, ip string) string {
+	for _, subnet := range a.networkLcuuidToSubnets[networkLcuuid] {
+		if cloudcommon.IsIPInCIDR(ip, subnet.CIDR) {
+			return subnet.Lcuuid
+		}
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bitly/go-simplejson"
+
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+// getVMs gets the virtual machines, whose vpc and ip are got from the primary network interface
+func (a *Azure) getVMs() ([]model.VM, error) {
+	log.Debug("get vms starting", logger.NewORGPrefix(a.orgID))
+	var vms []model.VM
+
+	nics, err := a.getRawData("networkInterfaces", "/providers/Microsoft.Network/networkInterfaces", API_VERSION_NETWORK)
+	if err != nil {
+		return []model.VM{}, err
+	}
+	a.nics = nics
+	nicIDToNic := map[string]*simplejson.Json{}
+	for _, nic := range nics {
+		nicIDToNic[strings.ToLower(nic.Get("id").MustString())] = nic
+	}
+
+	// the power states are returned only when 'statusOnly' is set
+	jVMs, err := a.getRawData("virtualMachines", "/providers/Microsoft.Compute/virtualMachines?statusOnly=true", API_VERSION_COMPUTE)
+	if err != nil {
+		return []model.VM{}, err
+	}
+	for _, jVM := range jVMs {
+		vmID := strings.ToLower(jVM.Get("id").MustString())
+		vmName := jVM.Get("name").MustString()
+		location := jVM.Get("location").MustString()
+		regionLcuuid, ok := a.checkRegion(location)
+		if !ok {
+			continue
+		}
+		properties := jVM.Get("properties")
+
+		var primaryNic *simplejson.Json
+		jNics := properties.Get("networkProfile").Get("networkInterfaces")
+		for i := range jNics.MustArray() {
+			jNic := jNics.GetIndex(i)
+			nic, ok := nicIDToNic[strings.ToLower(jNic.Get("id").MustString())]
+			if !ok {
+				continue
+			}
+			if primaryNic == nil || jNic.Get("properties").Get("primary").MustBool() {
+				primaryNic = nic
+			}
+		}
+		if primaryNic == nil {
+			log.Infof("vm (%s) has no network interface", vmName, logger.NewORGPrefix(a.orgID))
+			continue
+		}
+		primaryIP, network := a.getPrimaryIPConfig(primaryNic)
+		if network.VPCLcuuid == "" {
+			log.Infof("vm (%s) vpc not found", vmName, logger.NewORGPrefix(a.orgID))
+			continue
+		}
+
+		state := common.VM_STATE_EXCEPTION
+		jStatuses := properties.Get("instanceView").Get("statuses")
+		for i := range jStatuses.MustArray() {
+			code := jStatuses.GetIndex(i).Get("code").MustString()
+			if vmState, ok := vmStates[code]; ok {
+				state = vmState
+				break
+			}
+		}
+		createdAt, _ := time.Parse(time.RFC3339Nano, properties.Get("timeCreated").MustString())
+		cloudTags := map[string]string{}
+		for k, v := range jVM.Get("tags").MustMap() {
+			if value, ok := v.(string); ok {
+				cloudTags[k] = value
+			}
+		}
+
+		vmLcuuid := a.idToLcuuid(vmID)
+		vms = append(vms, model.VM{
+			Lcuuid:       vmLcuuid,
+			Name:         vmName,
+			Label:        properties.Get("vmId").MustString(),
+			IP:           primaryIP,
+			Hostname:     properties.Get("osProfile").Get("computerName").MustString(),
+			HType:        common.VM_HTYPE_VM_C,
+			State:        state,
+			CreatedAt:    createdAt,
+			VPCLcuuid:    network.VPCLcuuid,
+			AZLcuuid:     a.getAZLcuuid(location, jVM.Get("zones").MustArray()),
+			RegionLcuuid: regionLcuuid,
+			CloudTags:    cloudTags,
+		})
+		a.vmIDToLcuuid[vmID] = vmLcuuid
+		a.vmLcuuidToVPCLcuuid[vmLcuuid] = network.VPCLcuuid
+	}
+	log.Debug("get vms complete", logger.NewORGPrefix(a.orgID))
+	return vms, nil
+}
+
+// getPrimaryIPConfig returns the private ip and the network of the primary ip configuration
+func (a *Azure) getPrimaryIPConfig(nic *simplejson.Json) (string, model.Network) {
+	var ip string
+	var network model.Network
+	jIPConfigs := nic.Get("properties").Get("ipConfigurations")
+	for i := range jIPConfigs.MustArray() {
+		jProperties := jIPConfigs.GetIndex(i).Get("properties")
+		subnetNetwork, ok := a.subnetIDToNetwork[strings.ToLower(jProperties.Get("subnet").Get("id").MustString())]
+		if !ok {
+			continue
+		}
+		if ip == "" || jProperties.Get("primary").MustBool() {
+			ip, network = jProperties.Get("privateIPAddress").MustString(), subnetNetwork
+		}
+	}
+	return ip, network
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"strings"
+
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+// getVPCs gets the virtual networks as vpcs, and their subnets and peerings
+func (a *Azure) getVPCs() ([]model.VPC, []model.Network, []model.Subnet, []model.PeerConnection, error) {
+	log.Debug("get vpcs starting", logger.NewORGPrefix(a.orgID))
+	var vpcs []model.VPC
+	var networks []model.Network
+	var subnets []model.Subnet
+	var peerConnections []model.PeerConnection
+
+	vnets, err := a.getRawData("virtualNetworks", "/providers/Microsoft.Network/virtualNetworks", API_VERSION_NETWORK)
+	if err != nil {
+		return []model.VPC{}, []model.Network{}, []model.Subnet{}, []model.PeerConnection{}, err
+	}
+
+	vnetIDToRegionLcuuid := map[string]string{}
+	for _, vnet := range vnets {
+		vnetID := strings.ToLower(vnet.Get("id").MustString())
+		regionLcuuid, ok := a.checkRegion(vnet.Get("location").MustString())
+		if !ok {
+			continue
+		}
+		vnetIDToRegionLcuuid[vnetID] = regionLcuuid
+	}
+
+	peerKeys := map[string]bool{}
+	for _, vnet := range vnets {
+		vnetID := strings.ToLower(vnet.Get("id").MustString())
+		regionLcuuid, ok := vnetIDToRegionLcuuid[vnetID]
+		if !ok {
+			continue
+		}
+		vpcLcuuid := a.idToLcuuid(vnetID)
+		vnetName := vnet.Get("name").MustString()
+		properties := vnet.Get("properties")
+		vpcs = append(vpcs, model.VPC{
+			Lcuuid:       vpcLcuuid,
+			Name:         vnetName,
+			Label:        vnetID,
+			CIDR:         strings.Join(properties.Get("addressSpace").Get("addressPrefixes").MustStringArray(), ","),
+			RegionLcuuid: regionLcuuid,
+		})
+
+		jSubnets := properties.Get("subnets")
+		for i := range jSubnets.MustArray() {
+			jSubnet := jSubnets.GetIndex(i)
+			subnetID := strings.ToLower(jSubnet.Get("id").MustString())
+			networkLcuuid := a.idToLcuuid(subnetID)
+			subnetName := jSubnet.Get("name").MustString()
+			network := model.Network{
+				Lcuuid:         networkLcuuid,
+				Name:           subnetName,
+				Label:          subnetID,
+				SegmentationID: 1,
+				NetType:        common.NETWORK_TYPE_LAN,
+				VPCLcuuid:      vpcLcuuid,
+				RegionLcuuid:   regionLcuuid,
+			}
+			networks = append(networks, network)
+			a.subnetIDToNetwork[subnetID] = network
+
+			jProperties := jSubnet.Get("properties")
+			cidrs := jProperties.Get("addressPrefixes").MustStringArray()
+			if prefix := jProperties.Get("addressPrefix").MustString(); prefix != "" {
+				cidrs = append([]string{prefix}, cidrs...)
+			}
+			for _, cidr := range cidrs {
+				subnet := model.Subnet{
+					Lcuuid:        common.GetUUIDByOrgID(a.orgID, networkLcuuid+cidr),
+					Name:          subnetName,
+					CIDR:          cidr,
+					NetworkLcuuid: networkLcuuid,
+					VPCLcuuid:     vpcLcuuid,
+				}
+				subnets = append(subnets, subnet)
+				a.networkLcuuidToSubnets[networkLcuuid] = append(a.networkLcuuidToSubnets[networkLcuuid], subnet)
+			}
+		}
+
+		// both sides of a peering have a peering resource, only one peer connection is needed
+		jPeerings := properties.Get("virtualNetworkPeerings")
+		for i := range jPeerings.MustArray() {
+			jPeering := jPeerings.GetIndex(i)
+			peeringName := jPeering.Get("name").MustString()
+			jProperties := jPeering.Get("properties")
+			if state := jProperties.Get("peeringState").MustString(); state != "Connected" {
+				log.Infof("peer connection (%s) state (%s) invalid", peeringName, state, logger.NewORGPrefix(a.orgID))
+				continue
+			}
+			remoteVNetID := strings.ToLower(jProperties.Get("remoteVirtualNetwork").Get("id").MustString())
+			remoteRegionLcuuid, ok := vnetIDToRegionLcuuid[remoteVNetID]
+			if !ok {
+				log.Infof("peer connection (%s) remote vnet (%s) not found", peeringName, remoteVNetID, logger.NewORGPrefix(a.orgID))
+				continue
+			}
+			peerKey := vnetID + "," + remoteVNetID
+			if remoteVNetID < vnetID {
+				peerKey = remoteVNetID + "," + vnetID
+			}
+			if peerKeys[peerKey] {
+				continue
+			}
+			peerKeys[peerKey] = true
+			peeringID := strings.ToLower(jPeering.Get("id").MustString())
+			peerConnections = append(peerConnections, model.PeerConnection{
+				Lcuuid:             common.GetUUIDByOrgID(a.orgID, peerKey),
+				Name:               peeringName,
+				Label:              peeringID,
+				LocalVPCLcuuid:     vpcLcuuid,
+				RemoteVPCLcuuid:    a.idToLcuuid(remoteVNetID),
+				LocalRegionLcuuid:  regionLcuuid,
+				RemoteRegionLcuuid: remoteRegionLcuuid,
+			})
+		}
+	}
+	log.Debug("get vpcs complete", logger.NewORGPrefix(a.orgID))
+	return vpcs, networks, subnets, peerConnections, nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/bitly/go-simplejson"
+
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+func newErr(url, msg string) error {
+	return errors.New(fmt.Sprintf("request url: %s, %s", url, msg))
+}
+
+func (g *GCP) doRequest(req *http.Request) (*simplejson.Json, error) {
+	url := req.URL.String()
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, newErr(url, fmt.Sprintf("failed: %s", err.Error()))
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, newErr(url, fmt.Sprintf("read failed: %s", err.Error()))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newErr(url, fmt.Sprintf("failed: status (%d) body (%s)", resp.StatusCode, string(respBody)))
+	}
+	jsonResp, err := simplejson.NewJson(respBody)
+	if err != nil {
+		return nil, newErr(url, fmt.Sprintf("JSONiz failed: %s", err.Error()))
+	}
+	return jsonResp, nil
+}
+
+// getRawData lists the resources of the project, the pages are followed by 'nextPageToken'.
+// the 'items' of list apis is an array, while the 'items' of aggregated apis is a map of scope to
+// the resources, in which case the resources are got by resultKey, such as 'instances'
+func (g *GCP) getRawData(resultKey, path string) ([]*simplejson.Json, error) {
+	startTime := time.Now()
+	var jsonList []*simplejson.Json
+	var pageToken string
+	for {
+		token, err := g.getToken()
+		if err != nil {
+			return nil, err
+		}
+		requestURL := fmt.Sprintf("%s/projects/%s%s", g.computeEndpoint, g.projectID, path)
+		if pageToken != "" {
+			requestURL += "?pageToken=" + url.QueryEscape(pageToken)
+		}
+		req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+		resp, err := g.doRequest(req)
+		if err != nil {
+			log.Error(err.Error(), logger.NewORGPrefix(g.orgID))
+			return nil, err
+		}
+
+		items := resp.Get("items")
+		if scopes, err := items.Map(); err == nil {
+			scopeNames := make([]string, 0, len(scopes))
+			for scope := range scopes {
+				scopeNames = append(scopeNames, scope)
+			}
+			sort.Strings(scopeNames)
+			for _, scope := range scopeNames {
+				scopeItems := items.Get(scope).Get(resultKey)
+				for i := range scopeItems.MustArray() {
+					jsonList = append(jsonList, scopeItems.GetIndex(i))
+				}
+			}
+		} else {
+			for i := range items.MustArray() {
+				jsonList = append(jsonList, items.GetIndex(i))
+			}
+		}
+
+		pageToken = resp.Get("nextPageToken").MustString()
+		if pageToken == "" {
+			break
+		}
+	}
+	log.Debugf("get %s count (%d) cost (%s)", resultKey, len(jsonList), time.Since(startTime), logger.NewORGPrefix(g.orgID))
+	g.debugger.WriteJson(resultKey, path, jsonList)
+	return jsonList, nil
+}
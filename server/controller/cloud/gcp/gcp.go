@@ -0,0 +1,289 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+
+	"github.com/bitly/go-simplejson"
+
+	cloudcommon "github.com/khulnasoft/deepflow/server/controller/cloud/common"
+	cloudconfig "github.com/khulnasoft/deepflow/server/controller/cloud/config"
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	mysqlmodel "github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+var log = logger.MustGetLogger("cloud.gcp")
+
+const (
+	DEFAULT_TOKEN_URI        = "https://oauth2.googleapis.com/token"
+	DEFAULT_COMPUTE_ENDPOINT = "https://compute.googleapis.com/compute/v1"
+
+	SCOPE_COMPUTE_READONLY = "https://www.googleapis.com/auth/compute.readonly"
+)
+
+var vmStates = map[string]int{
+	"RUNNING":    common.VM_STATE_RUNNING,
+	"STOPPED":    common.VM_STATE_STOPPED,
+	"SUSPENDED":  common.VM_STATE_STOPPED,
+	"TERMINATED": common.VM_STATE_STOPPED,
+}
+
+type GCP struct {
+	orgID           int
+	teamID          int
+	name            string
+	lcuuid          string
+	regionLcuuid    string
+	uuidGenerate    string
+	projectID       string
+	clientEmail     string
+	privateKey      string
+	tokenURI        string
+	computeEndpoint string
+	includeRegions  map[string]bool
+	excludeRegions  map[string]bool
+	httpClient      *http.Client
+	tokenSource     oauth2.TokenSource
+	debugger        *cloudcommon.Debugger
+
+	// 以下属性为获取资源所用的关联关系
+	regionNameToLcuuid        map[string]string
+	zoneNameToRegionName      map[string]string
+	azLcuuidToAZ              map[string]model.AZ
+	regionLcuuidToResourceNum map[string]int
+	networkURLToVPCLcuuid     map[string]string
+	subnetworkURLToNetwork    map[string]model.Network
+	networkLcuuidToSubnets    map[string][]model.Subnet
+	addressURLToIP            map[string]string
+	instanceURLToVM           map[string]model.VM
+}
+
+func NewGCP(orgID int, domain mysqlmodel.Domain, cfg cloudconfig.CloudConfig) (*GCP, error) {
+	config, err := simplejson.NewJson([]byte(domain.Config))
+	if err != nil {
+		log.Error(err, logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+
+	projectID, err := config.Get("project_id").String()
+	if err != nil {
+		log.Error("project_id must be specified", logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+
+	clientEmail, err := config.Get("client_email").String()
+	if err != nil {
+		log.Error("client_email must be specified", logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+
+	privateKey, err := config.Get("private_key").String()
+	if err != nil {
+		log.Error("private_key must be specified", logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+	decryptPrivateKey, err := common.DecryptSecretKey(privateKey)
+	if err != nil {
+		log.Error("decrypt private_key failed (%s)", err.Error(), logger.NewORGPrefix(orgID))
+		return nil, err
+	}
+
+	tokenURI := config.Get("token_uri").MustString()
+	if tokenURI == "" {
+		tokenURI = DEFAULT_TOKEN_URI
+	}
+	computeEndpoint := config.Get("compute_endpoint").MustString()
+	if computeEndpoint == "" {
+		computeEndpoint = DEFAULT_COMPUTE_ENDPOINT
+	}
+
+	return &GCP{
+		orgID:           orgID,
+		teamID:          domain.TeamID,
+		name:            domain.Name,
+		lcuuid:          domain.Lcuuid,
+		uuidGenerate:    domain.DisplayName,
+		regionLcuuid:    config.Get("region_uuid").MustString(),
+		projectID:       projectID,
+		clientEmail:     clientEmail,
+		privateKey:      decryptPrivateKey,
+		tokenURI:        tokenURI,
+		computeEndpoint: strings.TrimSuffix(computeEndpoint, "/"),
+		includeRegions:  cloudcommon.UniqRegions(config.Get("include_regions").MustString()),
+		excludeRegions:  cloudcommon.UniqRegions(config.Get("exclude_regions").MustString()),
+		httpClient: &http.Client{
+			Timeout: time.Duration(cfg.HTTPTimeout) * time.Second,
+		},
+		debugger: cloudcommon.NewDebugger(domain.Name),
+	}, nil
+}
+
+func (g *GCP) CheckAuth() error {
+	_, err := g.getToken()
+	return err
+}
+
+func (g *GCP) ClearDebugLog() {
+	g.debugger.Clear()
+}
+
+// getToken gets the access token of the service account by the jwt bearer flow, the token is cached by the token source
+func (g *GCP) getToken() (string, error) {
+	if g.tokenSource == nil {
+		jwtConfig := &jwt.Config{
+			Email:      g.clientEmail,
+			PrivateKey: []byte(g.privateKey),
+			Scopes:     []string{SCOPE_COMPUTE_READONLY},
+			TokenURL:   g.tokenURI,
+		}
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, g.httpClient)
+		g.tokenSource = jwtConfig.TokenSource(ctx)
+	}
+	token, err := g.tokenSource.Token()
+	if err != nil {
+		log.Errorf("get token failed: %s", err.Error(), logger.NewORGPrefix(g.orgID))
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+func (g *GCP) idToLcuuid(id string) string {
+	return common.GetUUIDByOrgID(g.orgID, g.projectID+"_"+id)
+}
+
+// lastSegment returns the name of resource url, such as 'us-central1' of '.../regions/us-central1'
+func lastSegment(url string) string {
+	return url[strings.LastIndex(url, "/")+1:]
+}
+
+func (g *GCP) getRegionLcuuid(regionName string) string {
+	if g.regionLcuuid != "" {
+		return g.regionLcuuid
+	}
+	return g.regionNameToLcuuid[regionName]
+}
+
+// checkRegion returns the region lcuuid of the region url or name, and whether the region is collected
+func (g *GCP) checkRegion(region string) (string, bool) {
+	regionName := lastSegment(region)
+	if _, ok := g.regionNameToLcuuid[regionName]; !ok {
+		return "", false
+	}
+	regionLcuuid := g.getRegionLcuuid(regionName)
+	g.regionLcuuidToResourceNum[regionLcuuid]++
+	return regionLcuuid, true
+}
+
+func (g *GCP) getAZLcuuid(zone string) string {
+	zoneName := lastSegment(zone)
+	lcuuid := common.GetUUIDByOrgID(g.orgID, g.uuidGenerate+"_"+zoneName)
+	if _, ok := g.azLcuuidToAZ[lcuuid]; !ok {
+		g.azLcuuidToAZ[lcuuid] = model.AZ{
+			Lcuuid:       lcuuid,
+			Name:         zoneName,
+			Label:        zoneName,
+			RegionLcuuid: g.getRegionLcuuid(g.zoneNameToRegionName[zoneName]),
+		}
+	}
+	return lcuuid
+}
+
+func (g *GCP) GetCloudData() (model.Resource, error) {
+	var resource model.Resource
+	g.regionNameToLcuuid = map[string]string{}
+	g.zoneNameToRegionName = map[string]string{}
+	g.azLcuuidToAZ = map[string]model.AZ{}
+	g.regionLcuuidToResourceNum = map[string]int{}
+	g.networkURLToVPCLcuuid = map[string]string{}
+	g.subnetworkURLToNetwork = map[string]model.Network{}
+	g.networkLcuuidToSubnets = map[string][]model.Subnet{}
+	g.addressURLToIP = map[string]string{}
+	g.instanceURLToVM = map[string]model.VM{}
+
+	regions, err := g.getRegions()
+	if err != nil {
+		return model.Resource{}, err
+	}
+
+	vpcs, networks, subnets, peerConnections, err := g.getVPCs()
+	if err != nil {
+		return model.Resource{}, err
+	}
+	resource.VPCs = append(resource.VPCs, vpcs...)
+	resource.Networks = append(resource.Networks, networks...)
+	resource.Subnets = append(resource.Subnets, subnets...)
+	resource.PeerConnections = append(resource.PeerConnections, peerConnections...)
+
+	err = g.getAddresses()
+	if err != nil {
+		return model.Resource{}, err
+	}
+
+	vms, vinterfaces, ips, natRules, err := g.getVMs()
+	if err != nil {
+		return model.Resource{}, err
+	}
+	resource.VMs = append(resource.VMs, vms...)
+	resource.VInterfaces = append(resource.VInterfaces, vinterfaces...)
+	resource.IPs = append(resource.IPs, ips...)
+	resource.NATRules = append(resource.NATRules, natRules...)
+
+	natGateways, natVInterfaces, natIPs, err := g.getNATGateways()
+	if err != nil {
+		return model.Resource{}, err
+	}
+	resource.NATGateways = append(resource.NATGateways, natGateways...)
+	resource.VInterfaces = append(resource.VInterfaces, natVInterfaces...)
+	resource.IPs = append(resource.IPs, natIPs...)
+
+	lbs, lbListeners, lbTargetServers, lbVInterfaces, lbIPs, err := g.getLBs()
+	if err != nil {
+		return model.Resource{}, err
+	}
+	resource.LBs = append(resource.LBs, lbs...)
+	resource.LBListeners = append(resource.LBListeners, lbListeners...)
+	resource.LBTargetServers = append(resource.LBTargetServers, lbTargetServers...)
+	resource.VInterfaces = append(resource.VInterfaces, lbVInterfaces...)
+	resource.IPs = append(resource.IPs, lbIPs...)
+
+	azLcuuidToResourceNum := map[string]int{}
+	for _, vm := range resource.VMs {
+		azLcuuidToResourceNum[vm.AZLcuuid]++
+	}
+	azs := []model.AZ{}
+	for _, az := range g.azLcuuidToAZ {
+		azs = append(azs, az)
+	}
+	sort.Slice(azs, func(i, j int) bool { return azs[i].Name < azs[j].Name })
+	resource.AZs = cloudcommon.EliminateEmptyAZs(azs, azLcuuidToResourceNum)
+	if g.regionLcuuid == "" {
+		resource.Regions = cloudcommon.EliminateEmptyRegions(regions, g.regionLcuuidToResourceNum)
+	}
+
+	g.debugger.Refresh()
+	return resource, nil
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	cloudcommon "github.com/khulnasoft/deepflow/server/controller/cloud/common"
+	"github.com/khulnasoft/deepflow/server/controller/cloud/config"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	mysqlcommon "github.com/khulnasoft/deepflow/server/controller/db/mysql/common"
+)
+
+// newTestServer serves the recorded responses in testdata, as a stand-in of the token and compute endpoints
+func newTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var fixture string
+		switch {
+		case r.URL.Path == "/token":
+			if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.FormValue("assertion") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token": "test-token", "token_type": "Bearer", "expires_in": 3600}`))
+			return
+		case r.Header.Get("Authorization") != "Bearer test-token":
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case r.URL.Query().Get("pageToken") != "":
+			fixture = lastSegment(r.URL.Path) + "_" + r.URL.Query().Get("pageToken") + ".json"
+		default:
+			fixture = lastSegment(r.URL.Path) + ".json"
+		}
+		content, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestGCP(t *testing.T, endpoint string) *GCP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return &GCP{
+		orgID:           mysqlcommon.DEFAULT_ORG_ID,
+		name:            "test_gcp",
+		uuidGenerate:    "test_gcp",
+		projectID:       "proj-1",
+		clientEmail:     "deepflow@proj-1.iam.gserviceaccount.com",
+		privateKey:      string(privateKey),
+		tokenURI:        endpoint + "/token",
+		computeEndpoint: endpoint + "/compute/v1",
+		includeRegions:  map[string]bool{},
+		excludeRegions:  cloudcommon.UniqRegions("asia-east1"),
+		httpClient:      &http.Client{},
+		debugger:        cloudcommon.NewDebugger("test_gcp"),
+	}
+}
+
+func TestGCP(t *testing.T) {
+	Convey("TestGCP", t, func() {
+		config.CONF = &config.CloudConfig{}
+		server := newTestServer(t)
+		gcp := newTestGCP(t, server.URL)
+
+		So(gcp.CheckAuth(), ShouldBeNil)
+		data, err := gcp.GetCloudData()
+		So(err, ShouldBeNil)
+
+		Convey("regions and azs should exclude empty ones", func() {
+			So(len(data.Regions), ShouldEqual, 2)
+			So(len(data.AZs), ShouldEqual, 2)
+			So(data.AZs[0].Name, ShouldEqual, "europe-west1-b")
+			So(data.AZs[1].Name, ShouldEqual, "us-central1-a")
+		})
+
+		Convey("vpcs and subnetworks should be collected", func() {
+			So(len(data.VPCs), ShouldEqual, 2)
+			So(len(data.Networks), ShouldEqual, 2)
+			So(len(data.Subnets), ShouldEqual, 3)
+			So(len(data.PeerConnections), ShouldEqual, 1)
+		})
+
+		Convey("instances should be collected across pages", func() {
+			So(len(data.VMs), ShouldEqual, 2)
+			So(data.VMs[0].Name, ShouldEqual, "web")
+			So(data.VMs[0].State, ShouldEqual, common.VM_STATE_RUNNING)
+			So(data.VMs[0].IP, ShouldEqual, "10.10.0.2")
+			So(data.VMs[0].CloudTags["env"], ShouldEqual, "prod")
+			So(data.VMs[1].State, ShouldEqual, common.VM_STATE_STOPPED)
+			So(data.VMs[1].Hostname, ShouldEqual, "db.internal")
+		})
+
+		Convey("vinterfaces of instances should be collected", func() {
+			vmVInterfaces := 0
+			for _, vinterface := range data.VInterfaces {
+				if vinterface.DeviceType == common.VIF_DEVICE_TYPE_VM {
+					vmVInterfaces++
+				}
+			}
+			So(vmVInterfaces, ShouldEqual, 3)
+			So(len(data.NATRules), ShouldEqual, 1)
+			So(data.NATRules[0].FloatingIP, ShouldEqual, "34.1.1.1")
+			So(data.NATRules[0].FixedIP, ShouldEqual, "10.10.0.2")
+		})
+
+		Convey("cloud nats and lbs should be collected", func() {
+			So(len(data.NATGateways), ShouldEqual, 1)
+			So(data.NATGateways[0].FloatingIPs, ShouldEqual, "34.1.1.2")
+			So(len(data.LBs), ShouldEqual, 2)
+			So(data.LBs[0].Name, ShouldEqual, "pool-web")
+			So(data.LBs[0].Model, ShouldEqual, common.LB_MODEL_EXTERNAL)
+			So(data.LBs[0].VIP, ShouldEqual, "34.1.1.3")
+			So(data.LBs[0].VPCLcuuid, ShouldEqual, data.VMs[0].VPCLcuuid)
+			So(data.LBs[1].Model, ShouldEqual, common.LB_MODEL_INTERNAL)
+			So(len(data.LBListeners), ShouldEqual, 3)
+			So(data.LBListeners[2].Port, ShouldEqual, 8080)
+			So(len(data.LBTargetServers), ShouldEqual, 2)
+		})
+	})
+}
+
+func TestGetPort(t *testing.T) {
+	Convey("TestGetPort", t, func() {
+		So(getPort("80-80", nil), ShouldEqual, 80)
+		So(getPort("", []string{"53", "54"}), ShouldEqual, 53)
+		So(getPort("", nil), ShouldEqual, 0)
+	})
+}
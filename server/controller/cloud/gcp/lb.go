@@ -0,0 +1,236 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+type lbInfo struct {
+	lcuuid          string
+	name            string
+	label           string
+	regionLcuuid    string
+	vpcLcuuid       string
+	privateIPs      []string
+	privateNetworks []model.Network
+	publicIPs       []string
+}
+
+// getPort returns the first port of forwarding rule, 0 means all ports
+func getPort(portRange string, ports []string) int {
+	if len(ports) > 0 {
+		portRange = ports[0]
+	}
+	port, _ := strconv.Atoi(strings.Split(portRange, "-")[0])
+	return port
+}
+
+// getLBs gets the regional load balancers, the forwarding rules pointing to the same target pool or backend
+// service belong to one load balancer, and are the listeners of it. the instances of target pools are the
+// target servers, while the backends of backend services are instance groups and not collected.
+func (g *GCP) getLBs() ([]model.LB, []model.LBListener, []model.LBTargetServer, []model.VInterface, []model.IP, error) {
+	log.Debug("get lbs starting", logger.NewORGPrefix(g.orgID))
+	var lbs []model.LB
+	var lbListeners []model.LBListener
+	var lbTargetServers []model.LBTargetServer
+	var lbVInterfaces []model.VInterface
+	var lbIPs []model.IP
+
+	jTargetPools, err := g.getRawData("targetPools", "/aggregated/targetPools")
+	if err != nil {
+		return []model.LB{}, []model.LBListener{}, []model.LBTargetServer{}, []model.VInterface{}, []model.IP{}, err
+	}
+	targetPoolURLToVMs := map[string][]model.VM{}
+	for _, jTargetPool := range jTargetPools {
+		targetPoolURL := jTargetPool.Get("selfLink").MustString()
+		for _, instanceURL := range jTargetPool.Get("instances").MustStringArray() {
+			if vm, ok := g.instanceURLToVM[instanceURL]; ok {
+				targetPoolURLToVMs[targetPoolURL] = append(targetPoolURLToVMs[targetPoolURL], vm)
+			}
+		}
+	}
+
+	jRules, err := g.getRawData("forwardingRules", "/aggregated/forwardingRules")
+	if err != nil {
+		return []model.LB{}, []model.LBListener{}, []model.LBTargetServer{}, []model.VInterface{}, []model.IP{}, err
+	}
+	var lbURLs []string
+	lbURLToInfo := map[string]*lbInfo{}
+	for _, jRule := range jRules {
+		ruleName := jRule.Get("name").MustString()
+		lbURL := jRule.Get("target").MustString()
+		if lbURL == "" {
+			lbURL = jRule.Get("backendService").MustString()
+		}
+		if lbURL == "" {
+			log.Infof("forwarding rule (%s) has no target", ruleName, logger.NewORGPrefix(g.orgID))
+			continue
+		}
+		// the global forwarding rules have no region, and are ignored
+		regionName := lastSegment(jRule.Get("region").MustString())
+		if _, ok := g.regionNameToLcuuid[regionName]; !ok {
+			continue
+		}
+		info, ok := lbURLToInfo[lbURL]
+		if !ok {
+			info = &lbInfo{
+				lcuuid:       common.GetUUIDByOrgID(g.orgID, lbURL),
+				name:         lastSegment(lbURL),
+				label:        lbURL,
+				regionLcuuid: g.getRegionLcuuid(regionName),
+			}
+			lbURLs = append(lbURLs, lbURL)
+			lbURLToInfo[lbURL] = info
+		}
+
+		// the forwarding rules of different ports may share the same ip
+		ip := jRule.Get("IPAddress").MustString()
+		if jRule.Get("loadBalancingScheme").MustString() == "INTERNAL" {
+			network, ok := g.subnetworkURLToNetwork[jRule.Get("subnetwork").MustString()]
+			if !ok {
+				continue
+			}
+			info.vpcLcuuid = network.VPCLcuuid
+			if !common.Contains(info.privateIPs, ip) {
+				info.privateIPs = append(info.privateIPs, ip)
+				info.privateNetworks = append(info.privateNetworks, network)
+			}
+		} else if !common.Contains(info.publicIPs, ip) {
+			info.publicIPs = append(info.publicIPs, ip)
+		}
+
+		protocol := jRule.Get("IPProtocol").MustString()
+		port := getPort(jRule.Get("portRange").MustString(), jRule.Get("ports").MustStringArray())
+		ruleURL := jRule.Get("selfLink").MustString()
+		listenerLcuuid := g.idToLcuuid(jRule.Get("id").MustString())
+		lbListeners = append(lbListeners, model.LBListener{
+			Lcuuid:   listenerLcuuid,
+			LBLcuuid: info.lcuuid,
+			Name:     ruleName,
+			Label:    ruleURL,
+			IPs:      ip,
+			Protocol: protocol,
+			Port:     port,
+		})
+		for _, vm := range targetPoolURLToVMs[lbURL] {
+			// the external load balancers have no subnetwork, use the vpc of backends
+			if info.vpcLcuuid == "" {
+				info.vpcLcuuid = vm.VPCLcuuid
+			}
+			lbTargetServers = append(lbTargetServers, model.LBTargetServer{
+				Lcuuid:           common.GetUUIDByOrgID(g.orgID, listenerLcuuid+vm.Lcuuid),
+				LBLcuuid:         info.lcuuid,
+				LBListenerLcuuid: listenerLcuuid,
+				Type:             common.LB_SERVER_TYPE_VM,
+				IP:               vm.IP,
+				VMLcuuid:         vm.Lcuuid,
+				Protocol:         protocol,
+				Port:             port,
+				VPCLcuuid:        vm.VPCLcuuid,
+			})
+		}
+	}
+
+	lbLcuuids := map[string]bool{}
+	for _, lbURL := range lbURLs {
+		info := lbURLToInfo[lbURL]
+		if info.vpcLcuuid == "" {
+			log.Infof("lb (%s) vpc not found", info.name, logger.NewORGPrefix(g.orgID))
+			continue
+		}
+		g.regionLcuuidToResourceNum[info.regionLcuuid]++
+		lbLcuuids[info.lcuuid] = true
+
+		lbModel := common.LB_MODEL_INTERNAL
+		if len(info.publicIPs) > 0 {
+			lbModel = common.LB_MODEL_EXTERNAL
+		}
+		lbs = append(lbs, model.LB{
+			Lcuuid:       info.lcuuid,
+			Name:         info.name,
+			Label:        info.label,
+			Model:        lbModel,
+			VIP:          strings.Join(append(info.privateIPs, info.publicIPs...), ","),
+			VPCLcuuid:    info.vpcLcuuid,
+			RegionLcuuid: info.regionLcuuid,
+		})
+
+		for i, ip := range info.privateIPs {
+			vinterfaceLcuuid := common.GetUUIDByOrgID(g.orgID, info.lcuuid+ip)
+			lbVInterfaces = append(lbVInterfaces, model.VInterface{
+				Lcuuid:        vinterfaceLcuuid,
+				Type:          common.VIF_TYPE_LAN,
+				Mac:           common.VIF_DEFAULT_MAC,
+				DeviceLcuuid:  info.lcuuid,
+				DeviceType:    common.VIF_DEVICE_TYPE_LB,
+				NetworkLcuuid: info.privateNetworks[i].Lcuuid,
+				VPCLcuuid:     info.vpcLcuuid,
+				RegionLcuuid:  info.regionLcuuid,
+			})
+			lbIPs = append(lbIPs, model.IP{
+				Lcuuid:           common.GetUUIDByOrgID(g.orgID, vinterfaceLcuuid+ip),
+				VInterfaceLcuuid: vinterfaceLcuuid,
+				IP:               ip,
+				SubnetLcuuid:     g.getSubnetLcuuid(info.privateNetworks[i].Lcuuid, ip),
+				RegionLcuuid:     info.regionLcuuid,
+			})
+		}
+		if len(info.publicIPs) > 0 {
+			vinterfaceLcuuid := common.GetUUIDByOrgID(g.orgID, info.lcuuid)
+			lbVInterfaces = append(lbVInterfaces, model.VInterface{
+				Lcuuid:        vinterfaceLcuuid,
+				Type:          common.VIF_TYPE_WAN,
+				Mac:           common.VIF_DEFAULT_MAC,
+				DeviceLcuuid:  info.lcuuid,
+				DeviceType:    common.VIF_DEVICE_TYPE_LB,
+				NetworkLcuuid: common.NETWORK_ISP_LCUUID,
+				VPCLcuuid:     info.vpcLcuuid,
+				RegionLcuuid:  info.regionLcuuid,
+			})
+			for _, ip := range info.publicIPs {
+				lbIPs = append(lbIPs, model.IP{
+					Lcuuid:           common.GetUUIDByOrgID(g.orgID, vinterfaceLcuuid+ip),
+					VInterfaceLcuuid: vinterfaceLcuuid,
+					IP:               ip,
+					RegionLcuuid:     info.regionLcuuid,
+				})
+			}
+		}
+	}
+
+	// the listeners and target servers of lbs without vpc are dropped as well
+	var validListeners []model.LBListener
+	for _, listener := range lbListeners {
+		if lbLcuuids[listener.LBLcuuid] {
+			validListeners = append(validListeners, listener)
+		}
+	}
+	var validTargetServers []model.LBTargetServer
+	for _, targetServer := range lbTargetServers {
+		if lbLcuuids[targetServer.LBLcuuid] {
+			validTargetServers = append(validTargetServers, targetServer)
+		}
+	}
+	log.Debug("get lbs complete", logger.NewORGPrefix(g.orgID))
+	return lbs, validListeners, validTargetServers, lbVInterfaces, lbIPs, nil
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"strings"
+
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+// getNATGateways gets the cloud nats, which are configured in the cloud routers, the floating ips are
+// the reserved addresses of manual allocated nats, the auto allocated ips are not listed by the router
+func (g *GCP) getNATGateways() ([]model.NATGateway, []model.VInterface, []model.IP, error) {
+	log.Debug("get nat gateways starting", logger.NewORGPrefix(g.orgID))
+	var natGateways []model.NATGateway
+	var natVInterfaces []model.VInterface
+	var natIPs []model.IP
+
+	jRouters, err := g.getRawData("routers", "/aggregated/routers")
+	if err != nil {
+		return []model.NATGateway{}, []model.VInterface{}, []model.IP{}, err
+	}
+	for _, jRouter := range jRouters {
+		routerID := jRouter.Get("id").MustString()
+		jNATs := jRouter.Get("nats")
+		if len(jNATs.MustArray()) == 0 {
+			continue
+		}
+		regionLcuuid, ok := g.checkRegion(jRouter.Get("region").MustString())
+		if !ok {
+			continue
+		}
+		vpcLcuuid, ok := g.networkURLToVPCLcuuid[jRouter.Get("network").MustString()]
+		if !ok {
+			log.Infof("router (%s) vpc not found", jRouter.Get("name").MustString(), logger.NewORGPrefix(g.orgID))
+			continue
+		}
+		for i := range jNATs.MustArray() {
+			jNAT := jNATs.GetIndex(i)
+			natName := jNAT.Get("name").MustString()
+			floatingIPs := []string{}
+			for _, addressURL := range jNAT.Get("natIps").MustStringArray() {
+				if ip, ok := g.addressURLToIP[addressURL]; ok {
+					floatingIPs = append(floatingIPs, ip)
+				}
+			}
+
+			natGatewayLcuuid := g.idToLcuuid(routerID + "_" + natName)
+			natGateways = append(natGateways, model.NATGateway{
+				Lcuuid:       natGatewayLcuuid,
+				Name:         natName,
+				Label:        jRouter.Get("selfLink").MustString() + "/" + natName,
+				FloatingIPs:  strings.Join(floatingIPs, ","),
+				VPCLcuuid:    vpcLcuuid,
+				RegionLcuuid: regionLcuuid,
+			})
+
+			vinterfaceLcuuid := common.GetUUIDByOrgID(g.orgID, natGatewayLcuuid)
+			natVInterfaces = append(natVInterfaces, model.VInterface{
+				Lcuuid:        vinterfaceLcuuid,
+				Type:          common.VIF_TYPE_WAN,
+				Mac:           common.VIF_DEFAULT_MAC,
+				DeviceLcuuid:  natGatewayLcuuid,
+				DeviceType:    common.VIF_DEVICE_TYPE_NAT_GATEWAY,
+				NetworkLcuuid: common.NETWORK_ISP_LCUUID,
+				VPCLcuuid:     vpcLcuuid,
+				RegionLcuuid:  regionLcuuid,
+			})
+			for _, ip := range floatingIPs {
+				natIPs = append(natIPs, model.IP{
+					Lcuuid:           common.GetUUIDByOrgID(g.orgID, vinterfaceLcuuid+ip),
+					VInterfaceLcuuid: vinterfaceLcuuid,
+					IP:               ip,
+					RegionLcuuid:     regionLcuuid,
+				})
+			}
+		}
+	}
+	log.Debug("get nat gateways complete", logger.NewORGPrefix(g.orgID))
+	return natGateways, natVInterfaces, natIPs, nil
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+func (g *GCP) getRegions() ([]model.Region, error) {
+	log.Debug("get regions starting", logger.NewORGPrefix(g.orgID))
+	var regions []model.Region
+
+	jRegions, err := g.getRawData("regions", "/regions")
+	if err != nil {
+		return []model.Region{}, err
+	}
+	for _, jRegion := range jRegions {
+		name := jRegion.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+		// 区域白名单，如果当前区域不在白名单中，则跳过
+		if len(g.includeRegions) > 0 {
+			if _, ok := g.includeRegions[name]; !ok {
+				log.Infof("region (%s) not in include_regions", name, logger.NewORGPrefix(g.orgID))
+				continue
+			}
+		}
+		// 区域黑名单，如果当前区域在黑名单中，则跳过
+		if _, ok := g.excludeRegions[name]; ok {
+			log.Infof("region (%s) in exclude_regions", name, logger.NewORGPrefix(g.orgID))
+			continue
+		}
+		region := model.Region{
+			Lcuuid: common.GenerateUUIDByOrgID(g.orgID, name+"_"+g.uuidGenerate),
+			Label:  name,
+			Name:   name,
+		}
+		regions = append(regions, region)
+		g.regionNameToLcuuid[name] = region.Lcuuid
+		for _, zone := range jRegion.Get("zones").MustStringArray() {
+			g.zoneNameToRegionName[lastSegment(zone)] = name
+		}
+	}
+	log.Debug("get regions complete", logger.NewORGPrefix(g.orgID))
+	return regions, nil
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"time"
+
+	cloudcommon "github.com/khulnasoft/deepflow/server/controller/cloud/common"
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+// getAddresses gets the reserved ip addresses, which are referred by the cloud nats
+func (g *GCP) getAddresses() error {
+	log.Debug("get addresses starting", logger.NewORGPrefix(g.orgID))
+	jAddresses, err := g.getRawData("addresses", "/aggregated/addresses")
+	if err != nil {
+		return err
+	}
+	for _, jAddress := range jAddresses {
+		address := jAddress.Get("address").MustString()
+		if address == "" {
+			continue
+		}
+		g.addressURLToIP[jAddress.Get("selfLink").MustString()] = address
+	}
+	log.Debug("get addresses complete", logger.NewORGPrefix(g.orgID))
+	return nil
+}
+
+// getVMs gets the instances and their network interfaces, the vpc and ip of instance are got from the
+// first network interface, and the external ips of access configs are bound as wan vinterfaces and dnat rules
+func (g *GCP) getVMs() ([]model.VM, []model.VInterface, []model.IP, []model.NATRule, error) {
+	log.Debug("get vms starting", logger.NewORGPrefix(g.orgID))
+	var vms []model.VM
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+	var natRules []model.NATRule
+
+	jInstances, err := g.getRawData("instances", "/aggregated/instances")
+	if err != nil {
+		return []model.VM{}, []model.VInterface{}, []model.IP{}, []model.NATRule{}, err
+	}
+	for _, jInstance := range jInstances {
+		instanceID := jInstance.Get("id").MustString()
+		instanceName := jInstance.Get("name").MustString()
+		zone := jInstance.Get("zone").MustString()
+		regionLcuuid, ok := g.checkRegion(g.zoneNameToRegionName[lastSegment(zone)])
+		if !ok {
+			continue
+		}
+		jNics := jInstance.Get("networkInterfaces")
+		if len(jNics.MustArray()) == 0 {
+			log.Infof("vm (%s) has no network interface", instanceName, logger.NewORGPrefix(g.orgID))
+			continue
+		}
+		vpcLcuuid, ok := g.networkURLToVPCLcuuid[jNics.GetIndex(0).Get("network").MustString()]
+		if !ok {
+			log.Infof("vm (%s) vpc not found", instanceName, logger.NewORGPrefix(g.orgID))
+			continue
+		}
+
+		state, ok := vmStates[jInstance.Get("status").MustString()]
+		if !ok {
+			state = common.VM_STATE_EXCEPTION
+		}
+		createdAt, _ := time.Parse(time.RFC3339Nano, jInstance.Get("creationTimestamp").MustString())
+		cloudTags := map[string]string{}
+		for k, v := range jInstance.Get("labels").MustMap() {
+			if value, ok := v.(string); ok {
+				cloudTags[k] = value
+			}
+		}
+		hostname := jInstance.Get("hostname").MustString()
+		if hostname == "" {
+			hostname = instanceName
+		}
+
+		vmLcuuid := g.idToLcuuid(instanceID)
+		vm := model.VM{
+			Lcuuid:       vmLcuuid,
+			Name:         instanceName,
+			Label:        instanceID,
+			IP:           jNics.GetIndex(0).Get("networkIP").MustString(),
+			Hostname:     hostname,
+			HType:        common.VM_HTYPE_VM_C,
+			State:        state,
+			CreatedAt:    createdAt,
+			VPCLcuuid:    vpcLcuuid,
+			AZLcuuid:     g.getAZLcuuid(zone),
+			RegionLcuuid: regionLcuuid,
+			CloudTags:    cloudTags,
+		}
+		vms = append(vms, vm)
+		g.instanceURLToVM[jInstance.Get("selfLink").MustString()] = vm
+
+		// the mac addresses of network interfaces are not provided by gcp
+		for i := range jNics.MustArray() {
+			jNic := jNics.GetIndex(i)
+			network, ok := g.subnetworkURLToNetwork[jNic.Get("subnetwork").MustString()]
+			if !ok {
+				continue
+			}
+			vinterfaceLcuuid := g.idToLcuuid(instanceID + "_" + jNic.Get("name").MustString())
+			vinterfaces = append(vinterfaces, model.VInterface{
+				Lcuuid:        vinterfaceLcuuid,
+				Name:          jNic.Get("name").MustString(),
+				Type:          common.VIF_TYPE_LAN,
+				Mac:           common.VIF_DEFAULT_MAC,
+				DeviceLcuuid:  vmLcuuid,
+				DeviceType:    common.VIF_DEVICE_TYPE_VM,
+				NetworkLcuuid: network.Lcuuid,
+				VPCLcuuid:     network.VPCLcuuid,
+				RegionLcuuid:  regionLcuuid,
+			})
+			privateIP := jNic.Get("networkIP").MustString()
+			if privateIP != "" {
+				ips = append(ips, model.IP{
+					Lcuuid:           common.GetUUIDByOrgID(g.orgID, vinterfaceLcuuid+privateIP),
+					VInterfaceLcuuid: vinterfaceLcuuid,
+					IP:               privateIP,
+					SubnetLcuuid:     g.getSubnetLcuuid(network.Lcuuid, privateIP),
+					RegionLcuuid:     regionLcuuid,
+				})
+			}
+
+			wanVInterfaceLcuuid := common.GetUUIDByOrgID(g.orgID, vinterfaceLcuuid)
+			hasWANVInterface := false
+			jAccessConfigs := jNic.Get("accessConfigs")
+			for j := range jAccessConfigs.MustArray() {
+				publicIP := jAccessConfigs.GetIndex(j).Get("natIP").MustString()
+				if publicIP == "" {
+					continue
+				}
+				if !hasWANVInterface {
+					hasWANVInterface = true
+					vinterfaces = append(vinterfaces, model.VInterface{
+						Lcuuid:        wanVInterfaceLcuuid,
+						Type:          common.VIF_TYPE_WAN,
+						Mac:           common.VIF_DEFAULT_MAC,
+						DeviceLcuuid:  vmLcuuid,
+						DeviceType:    common.VIF_DEVICE_TYPE_VM,
+						NetworkLcuuid: common.NETWORK_ISP_LCUUID,
+						VPCLcuuid:     network.VPCLcuuid,
+						RegionLcuuid:  regionLcuuid,
+					})
+				}
+				ips = append(ips, model.IP{
+					Lcuuid:           common.GetUUIDByOrgID(g.orgID, vinterfaceLcuuid+publicIP),
+					VInterfaceLcuuid: wanVInterfaceLcuuid,
+					IP:               publicIP,
+					RegionLcuuid:     regionLcuuid,
+				})
+				if privateIP == "" {
+					continue
+				}
+				natRules = append(natRules, model.NATRule{
+					Lcuuid:           common.GetUUIDByOrgID(g.orgID, publicIP+vinterfaceLcuuid+privateIP),
+					Type:             "DNAT",
+					Protocol:         "ALL",
+					FloatingIP:       publicIP,
+					FixedIP:          privateIP,
+					VInterfaceLcuuid: vinterfaceLcuuid,
+				})
+			}
+		}
+	}
+	log.Debug("get vms complete", logger.NewORGPrefix(g.orgID))
+	return vms, vinterfaces, ips, natRules, nil
+}
+
+// getSubnetLcuuid returns the subnet of network which contains the ip, since a subnetwork may have secondary ranges
+func (g *GCP) getSubnetLcuuid(networkLcuuid, ip string) string {
+	for _, subnet := range g.networkLcuuidToSubnets[networkLcuuid] {
+		if cloudcommon.IsIPInCIDR(ip, subnet.CIDR) {
+			return subnet.Lcuuid
+		}
+	}
+	return ""
+}
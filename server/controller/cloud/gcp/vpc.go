@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"github.com/khulnasoft/deepflow/server/controller/cloud/model"
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+// getVPCs gets the vpc networks and their subnetworks, the vpc networks of gcp are global resources,
+// so the region of vpc is the region of its first subnetwork, and the vpc without subnetwork is ignored
+func (g *GCP) getVPCs() ([]model.VPC, []model.Network, []model.Subnet, []model.PeerConnection, error) {
+	log.Debug("get vpcs starting", logger.NewORGPrefix(g.orgID))
+	var vpcs []model.VPC
+	var networks []model.Network
+	var subnets []model.Subnet
+	var peerConnections []model.PeerConnection
+
+	jNetworks, err := g.getRawData("networks", "/global/networks")
+	if err != nil {
+		return []model.VPC{}, []model.Network{}, []model.Subnet{}, []model.PeerConnection{}, err
+	}
+	jSubnetworks, err := g.getRawData("subnetworks", "/aggregated/subnetworks")
+	if err != nil {
+		return []model.VPC{}, []model.Network{}, []model.Subnet{}, []model.PeerConnection{}, err
+	}
+
+	networkURLToRegionLcuuid := map[string]string{}
+	for _, jSubnetwork := range jSubnetworks {
+		regionName := lastSegment(jSubnetwork.Get("region").MustString())
+		if _, ok := g.regionNameToLcuuid[regionName]; !ok {
+			continue
+		}
+		networkURL := jSubnetwork.Get("network").MustString()
+		if _, ok := networkURLToRegionLcuuid[networkURL]; !ok {
+			networkURLToRegionLcuuid[networkURL] = g.getRegionLcuuid(regionName)
+		}
+	}
+
+	for _, jNetwork := range jNetworks {
+		networkURL := jNetwork.Get("selfLink").MustString()
+		regionLcuuid, ok := networkURLToRegionLcuuid[networkURL]
+		if !ok {
+			log.Infof("vpc (%s) has no subnetwork in collected regions", networkURL, logger.NewORGPrefix(g.orgID))
+			continue
+		}
+		vpcLcuuid := g.idToLcuuid(jNetwork.Get("id").MustString())
+		vpcs = append(vpcs, model.VPC{
+			Lcuuid:       vpcLcuuid,
+			Name:         jNetwork.Get("name").MustString(),
+			Label:        networkURL,
+			CIDR:         jNetwork.Get("IPv4Range").MustString(),
+			RegionLcuuid: regionLcuuid,
+		})
+		g.networkURLToVPCLcuuid[networkURL] = vpcLcuuid
+	}
+
+	for _, jSubnetwork := range jSubnetworks {
+		regionLcuuid, ok := g.checkRegion(jSubnetwork.Get("region").MustString())
+		if !ok {
+			continue
+		}
+		vpcLcuuid, ok := g.networkURLToVPCLcuuid[jSubnetwork.Get("network").MustString()]
+		if !ok {
+			continue
+		}
+		subnetworkURL := jSubnetwork.Get("selfLink").MustString()
+		subnetworkName := jSubnetwork.Get("name").MustString()
+		networkLcuuid := g.idToLcuuid(jSubnetwork.Get("id").MustString())
+		network := model.Network{
+			Lcuuid:         networkLcuuid,
+			Name:           subnetworkName,
+			Label:          subnetworkURL,
+			SegmentationID: 1,
+			NetType:        common.NETWORK_TYPE_LAN,
+			VPCLcuuid:      vpcLcuuid,
+			RegionLcuuid:   regionLcuuid,
+		}
+		networks = append(networks, network)
+		g.subnetworkURLToNetwork[subnetworkURL] = network
+
+		// the secondary ranges are used by the alias ips, such as the pods of gke
+		cidrs := []string{jSubnetwork.Get("ipCidrRange").MustString()}
+		jRanges := jSubnetwork.Get("secondaryIpRanges")
+		for i := range jRanges.MustArray() {
+			cidrs = append(cidrs, jRanges.GetIndex(i).Get("ipCidrRange").MustString())
+		}
+		for _, cidr := range cidrs {
+			if cidr == "" {
+				continue
+			}
+			subnet := model.Subnet{
+				Lcuuid:        common.GetUUIDByOrgID(g.orgID, networkLcuuid+cidr),
+				Name:          subnetworkName,
+				CIDR:          cidr,
+				NetworkLcuuid: networkLcuuid,
+				VPCLcuuid:     vpcLcuuid,
+			}
+			subnets = append(subnets, subnet)
+			g.networkLcuuidToSubnets[networkLcuuid] = append(g.networkLcuuidToSubnets[networkLcuuid], subnet)
+		}
+	}
+
+	// both sides of a peering have a peering item, only one peer connection is needed
+	peerKeys := map[string]bool{}
+	for _, jNetwork := range jNetworks {
+		networkURL := jNetwork.Get("selfLink").MustString()
+		vpcLcuuid, ok := g.networkURLToVPCLcuuid[networkURL]
+		if !ok {
+			continue
+		}
+		jPeerings := jNetwork.Get("peerings")
+		for i := range jPeerings.MustArray() {
+			jPeering := jPeerings.GetIndex(i)
+			peeringName := jPeering.Get("name").MustString()
+			if state := jPeering.Get("state").MustString(); state != "ACTIVE" {
+				log.Infof("peer connection (%s) state (%s) invalid", peeringName, state, logger.NewORGPrefix(g.orgID))
+				continue
+			}
+			remoteNetworkURL := jPeering.Get("network").MustString()
+			remoteVPCLcuuid, ok := g.networkURLToVPCLcuuid[remoteNetworkURL]
+			if !ok {
+				log.Infof("peer connection (%s) remote vpc (%s) not found", peeringName, remoteNetworkURL, logger.NewORGPrefix(g.orgID))
+				continue
+			}
+			peerKey := networkURL + "," + remoteNetworkURL
+			if remoteNetworkURL < networkURL {
+				peerKey = remoteNetworkURL + "," + networkURL
+			}
+			if peerKeys[peerKey] {
+				continue
+			}
+			peerKeys[peerKey] = true
+			peerConnections = append(peerConnections, model.PeerConnection{
+				Lcuuid:             common.GetUUIDByOrgID(g.orgID, peerKey),
+				Name:               peeringName,
+				Label:              peerKey,
+				LocalVPCLcuuid:     vpcLcuuid,
+				RemoteVPCLcuuid:    remoteVPCLcuuid,
+				LocalRegionLcuuid:  networkURLToRegionLcuuid[networkURL],
+				RemoteRegionLcuuid: networkURLToRegionLcuuid[remoteNetworkURL],
+			})
+		}
+	}
+	log.Debug("get vpcs complete", logger.NewORGPrefix(g.orgID))
+	return vpcs, networks, subnets, peerConnections, nil
+}
@@ -22,9 +22,11 @@ import (
 
 	"github.com/khulnasoft/deepflow/server/controller/cloud/aliyun"
 	"github.com/khulnasoft/deepflow/server/controller/cloud/aws"
+	"github.com/khulnasoft/deepflow/server/controller/cloud/azure"
 	"github.com/khulnasoft/deepflow/server/controller/cloud/baidubce"
 	"github.com/khulnasoft/deepflow/server/controller/cloud/config"
 	"github.com/khulnasoft/deepflow/server/controller/cloud/filereader"
+	"github.com/khulnasoft/deepflow/server/controller/cloud/gcp"
 	"github.com/khulnasoft/deepflow/server/controller/cloud/genesis"
 	"github.com/khulnasoft/deepflow/server/controller/cloud/huawei"
 	"github.com/khulnasoft/deepflow/server/controller/cloud/kubernetes"
@@ -71,6 +73,10 @@ func NewPlatform(domain mysqlmodel.Domain, cfg config.CloudConfig, db *mysql.DB)
 		platform, err = filereader.NewFileReader(db.ORGID, domain)
 	case common.VOLCENGINE:
 		platform, err = volcengine.NewVolcEngine(db.ORGID, domain, cfg)
+	case common.AZURE:
+		platform, err = azure.NewAzure(db.ORGID, domain, cfg)
+	case common.GCP:
+		platform, err = gcp.NewGCP(db.ORGID, domain, cfg)
 	// TODO: other platform
 	default:
 		return nil, errors.New(fmt.Sprintf("domain type (%d) not supported", domain.Type))
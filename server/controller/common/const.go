@@ -124,6 +124,30 @@ const (
 	VTAP_STATE_PENDING_STR       = "PENDING"
 )
 
+// agent upgrade rollout
+const (
+	AGENT_UPGRADE_ROLLOUT_STATE_RUNNING      = "RUNNING"
+	AGENT_UPGRADE_ROLLOUT_STATE_PAUSED       = "PAUSED"
+	AGENT_UPGRADE_ROLLOUT_STATE_SUCCEEDED    = "SUCCEEDED"
+	AGENT_UPGRADE_ROLLOUT_STATE_CANCELLED    = "CANCELLED"
+	AGENT_UPGRADE_ROLLOUT_STATE_ROLLING_BACK = "ROLLING_BACK"
+	AGENT_UPGRADE_ROLLOUT_STATE_ROLLED_BACK  = "ROLLED_BACK"
+
+	AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_COUNT      = "COUNT"
+	AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_PERCENTAGE = "PERCENTAGE"
+
+	AGENT_UPGRADE_ROLLOUT_FAILURE_ACTION_PAUSE    = "PAUSE"
+	AGENT_UPGRADE_ROLLOUT_FAILURE_ACTION_ROLLBACK = "ROLLBACK"
+
+	AGENT_UPGRADE_ROLLOUT_AGENT_STATE_PENDING     = "PENDING"
+	AGENT_UPGRADE_ROLLOUT_AGENT_STATE_SKIPPED     = "SKIPPED"
+	AGENT_UPGRADE_ROLLOUT_AGENT_STATE_UPGRADING   = "UPGRADING"
+	AGENT_UPGRADE_ROLLOUT_AGENT_STATE_SUCCEEDED   = "SUCCEEDED"
+	AGENT_UPGRADE_ROLLOUT_AGENT_STATE_FAILED      = "FAILED"
+	AGENT_UPGRADE_ROLLOUT_AGENT_STATE_CANCELLED   = "CANCELLED"
+	AGENT_UPGRADE_ROLLOUT_AGENT_STATE_ROLLED_BACK = "ROLLED_BACK"
+)
+
 const (
 	VTAP_TYPE_KVM = 1 + iota
 	VTAP_TYPE_ESXI
@@ -297,6 +321,7 @@ const (
 	SUGON             = 29
 	VOLCENGINE        = 30
 	H3C               = 31
+	GCP               = 32
 
 	OPENSTACK_EN         = "openstack"
 	VSPHERE_EN           = "vsphere"
@@ -328,6 +353,7 @@ const (
 	SUGON_EN             = "sugon"
 	VOLCENGINE_EN        = "volcengine"
 	H3C_EN               = "h3c"
+	GCP_EN               = "gcp"
 
 	TENCENT_CH          = "腾讯云"
 	ALIYUN_CH           = "阿里云"
@@ -349,6 +375,7 @@ const (
 	ZSTACK_CH      = "ZStack"
 	KUBERNETES_CH  = "Kubernetes"
 	CLOUD_TOWER_CH = "CloudTower"
+	GCP_CH         = "GCP"
 )
 
 var DomainTypeToIconID = map[int]int{
@@ -370,6 +397,7 @@ var IconNameToDomainTypes = map[string][]int{
 	KINGSOFT_PRIVATE_CH: {KINGSOFT_PRIVATE},
 	BAIDU_BCE_CH:        {BAIDU_BCE},
 	VOLCENGINE_CH:       {VOLCENGINE},
+	GCP_CH:              {GCP},
 }
 
 const (
@@ -59,3 +59,12 @@ func StrToJsonAndMap(str string) (resJson string, resMap map[string]string) {
 	resJson = string(jsonStr)
 	return
 }
+
+// GetRealRevision returns the revision without branch, "<branch> <rev_count>-<commit_id>" -> "<rev_count>-<commit_id>"
+func GetRealRevision(revision string) string {
+	splitStr := strings.Split(revision, " ")
+	if len(splitStr) == 2 {
+		return splitStr[1]
+	}
+	return revision
+}
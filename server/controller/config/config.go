@@ -73,6 +73,10 @@ type ControllerConfig struct {
 	GrpcNodePort                   string `default:"30035" yaml:"grpc-node-port"`
 	Kubeconfig                     string `yaml:"kubeconfig"`
 	ElectionName                   string `default:"deepflow-server" yaml:"election-name"`
+	ElectionType                   string `default:"kubernetes" yaml:"election-type"`
+	ElectionLeaseDuration          int    `default:"15" yaml:"election-lease-duration"`
+	ElectionRenewDeadline          int    `default:"10" yaml:"election-renew-deadline"`
+	ElectionRetryPeriod            int    `default:"2" yaml:"election-retry-period"`
 	ReportingDisabled              bool   `default:"false" yaml:"reporting-disabled"`
 	BillingMethod                  string `default:"license" yaml:"billing-method"`
 	PodClusterInternalIPToIngester int    `default:"0" yaml:"pod-cluster-internal-ip-to-ingester"`
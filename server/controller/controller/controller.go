@@ -42,6 +42,7 @@ import (
 	"github.com/khulnasoft/deepflow/server/controller/monitor"
 	"github.com/khulnasoft/deepflow/server/controller/prometheus"
 	"github.com/khulnasoft/deepflow/server/controller/recorder"
+	"github.com/khulnasoft/deepflow/server/controller/recorder/notifier"
 	"github.com/khulnasoft/deepflow/server/controller/report"
 	"github.com/khulnasoft/deepflow/server/controller/statsd"
 	"github.com/khulnasoft/deepflow/server/controller/tagrecorder"
@@ -136,6 +137,14 @@ func Start(ctx context.Context, configPath, serverLogFile string, shared *server
 		os.Exit(0)
 	}
 
+	// start notifier before manager for the same reason as tagrecorder
+	router.SetInitStageForHealthChecker("Recorder notifier init")
+	if err := notifier.GetNotifier().Start(ctx, cfg.ManagerCfg.TaskCfg.RecorderCfg.Notifier); err != nil {
+		log.Errorf("start recorder notifier failed: %s", err.Error())
+		time.Sleep(time.Second)
+		os.Exit(0)
+	}
+
 	router.SetInitStageForHealthChecker("Manager init")
 	// 启动resource manager
 	// 每个云平台启动一个cloud和recorder
@@ -82,6 +82,7 @@ func checkAndStartMasterFunctions(
 	// 仅master controller才启动以下goroutine
 	// - tagrecorder
 	// - 控制器和数据节点检查
+	// - agent upgrade rollout
 	// - license分配和检查
 	// - resource id manager
 	// - clean deleted/dirty resource data
@@ -96,6 +97,7 @@ func checkAndStartMasterFunctions(
 
 	vtapCheck := vtap.NewVTapCheck(cfg.MonitorCfg, ctx)
 	vtapRebalanceCheck := vtap.NewRebalanceCheck(cfg.MonitorCfg, ctx)
+	vtapUpgradeRolloutCheck := vtap.NewUpgradeRolloutCheck(cfg.MonitorCfg, cfg.ListenPort, ctx)
 	vtapLicenseAllocation := license.NewVTapLicenseAllocation(cfg.MonitorCfg, ctx)
 	recorderResource := recorder.GetResource()
 	domainChecker := resoureservice.NewDomainCheck(ctx)
@@ -150,6 +152,9 @@ func checkAndStartMasterFunctions(
 				// rebalance vtap check
 				vtapRebalanceCheck.Start(sCtx)
 
+				// agent upgrade rollout check
+				vtapUpgradeRolloutCheck.Start(sCtx)
+
 				// license分配和检查
 				if cfg.BillingMethod == common.BILLING_METHOD_LICENSE {
 					vtapLicenseAllocation.Start(sCtx)
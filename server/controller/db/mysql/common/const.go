@@ -16,6 +16,11 @@
 
 package common
 
+const (
+	DB_TYPE_MYSQL      = "mysql"
+	DB_TYPE_POSTGRESQL = "postgresql"
+)
+
 const (
 	NON_DEFAULT_ORG_DATABASE_SUFFIX = "_deepflow"
 	DATABASE_PREFIX_ALIGNMENT       = "%04d"
@@ -25,3 +30,6 @@ const (
 	DEFAULT_ORG_ID  = 1
 	DEFAULT_TEAM_ID = 1
 )
+
+// the lease table of database election, it is created by election before migration
+const DATABASE_ELECTION_TABLE = "controller_election"
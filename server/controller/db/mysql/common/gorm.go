@@ -21,11 +21,15 @@ import (
 	"database/sql/driver"
 	"fmt"
 	l "log"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	mysql_driver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
@@ -34,11 +38,38 @@ import (
 )
 
 func GetSession(cfg config.MySqlConfig) (*gorm.DB, error) {
-	connector, err := GetConnector(cfg, true, cfg.TimeOut, false)
+	dialector, err := GetDialector(cfg, true, cfg.TimeOut, false)
 	if err != nil {
 		return nil, err
 	}
-	return InitSession(cfg, connector)
+	return InitSession(cfg, dialector)
+}
+
+// GetDialector returns the gorm dialector of the configured database type, mysql is used if type is not set.
+func GetDialector(cfg config.MySqlConfig, useDatabase bool, timeout uint32, multiStatements bool) (gorm.Dialector, error) {
+	switch cfg.Type {
+	case "", DB_TYPE_MYSQL:
+		connector, err := GetConnector(cfg, useDatabase, timeout, multiStatements)
+		if err != nil {
+			return nil, err
+		}
+		return mysql.New(mysql.Config{
+			Conn:                      sql.OpenDB(connector),
+			DefaultStringSize:         256,   // string 类型字段的默认长度
+			DisableDatetimePrecision:  true,  // 禁用 datetime 精度，MySQL 5.6 之前的数据库不支持
+			DontSupportRenameIndex:    true,  // 重命名索引时采用删除并新建的方式，MySQL 5.7 之前的数据库和 MariaDB 不支持重命名索引
+			DontSupportRenameColumn:   true,  // 用 `change` 重命名列，MySQL 8 之前的数据库和 MariaDB 不支持重命名列
+			SkipInitializeWithVersion: false, // 根据当前 MySQL 版本自动配置
+		}), nil
+	case DB_TYPE_POSTGRESQL:
+		return postgres.New(postgres.Config{
+			DSN: GetPostgreSQLDSN(cfg, useDatabase, timeout),
+			// only simple protocol supports executing multiple statements at once, which is used when migrating
+			PreferSimpleProtocol: multiStatements,
+		}), nil
+	default:
+		return nil, fmt.Errorf("database type %s is not supported", cfg.Type)
+	}
 }
 
 func GetConnector(cfg config.MySqlConfig, useDatabase bool, timeout uint32, multiStatements bool) (driver.Connector, error) {
@@ -74,15 +105,25 @@ func GetConnector(cfg config.MySqlConfig, useDatabase bool, timeout uint32, mult
 	return connector, nil
 }
 
-func InitSession(cfg config.MySqlConfig, connector driver.Connector) (*gorm.DB, error) {
-	db, err := gorm.Open(mysql.New(mysql.Config{
-		Conn:                      sql.OpenDB(connector),
-		DefaultStringSize:         256,   // string 类型字段的默认长度
-		DisableDatetimePrecision:  true,  // 禁用 datetime 精度，MySQL 5.6 之前的数据库不支持
-		DontSupportRenameIndex:    true,  // 重命名索引时采用删除并新建的方式，MySQL 5.7 之前的数据库和 MariaDB 不支持重命名索引
-		DontSupportRenameColumn:   true,  // 用 `change` 重命名列，MySQL 8 之前的数据库和 MariaDB 不支持重命名列
-		SkipInitializeWithVersion: false, // 根据当前 MySQL 版本自动配置
-	}), &gorm.Config{
+// GetPostgreSQLDSN returns the url of postgresql, connects to the maintenance database postgres
+// if useDatabase is false, because postgresql requires a database in every connection.
+func GetPostgreSQLDSN(cfg config.MySqlConfig, useDatabase bool, timeout uint32) string {
+	database := "postgres"
+	if useDatabase {
+		database = cfg.Database
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.UserName, cfg.UserPassword),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(int(cfg.Port))),
+		Path:     "/" + database,
+		RawQuery: url.Values{"connect_timeout": []string{strconv.Itoa(int(timeout))}}.Encode(),
+	}
+	return dsn.String()
+}
+
+func InitSession(cfg config.MySqlConfig, dialector gorm.Dialector) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
 		NamingStrategy: schema.NamingStrategy{SingularTable: true}, // 设置全局表名禁用复数
 		Logger: logger.New(
 			l.New(os.Stdout, "\r\n", l.LstdFlags), // io writer
@@ -97,7 +138,7 @@ func InitSession(cfg config.MySqlConfig, connector driver.Connector) (*gorm.DB,
 		log.Errorf("failed to initialize session: %v", err.Error())
 		return nil, err
 	}
-	log.Infof("%s, initialized mysql session successfully", cfg.Database)
+	log.Infof("%s, initialized %s session successfully", cfg.Database, dialector.Name())
 
 	sqlDB, _ := db.DB()
 	// 限制最大空闲连接数、最大连接数和连接的生命周期
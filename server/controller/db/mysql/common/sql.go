@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/config"
+)
+
+// the sql below differs between mysql and postgresql, the others are shared by both of them
+
+// GetSelectDatabaseSQL returns the sql querying the name of the configured database, the result is empty if it does not exist.
+func GetSelectDatabaseSQL(cfg config.MySqlConfig) string {
+	if cfg.Type == DB_TYPE_POSTGRESQL {
+		return fmt.Sprintf("SELECT datname FROM pg_database WHERE datname='%s'", cfg.Database)
+	}
+	return fmt.Sprintf("SELECT SCHEMA_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME='%s'", cfg.Database)
+}
+
+// GetSelectTableSQL returns the sql querying the name of the table in the configured database, the result is empty if it does not exist.
+func GetSelectTableSQL(cfg config.MySqlConfig, tableName string) string {
+	if cfg.Type == DB_TYPE_POSTGRESQL {
+		return fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_catalog='%s' AND table_schema=current_schema() AND table_name='%s'", cfg.Database, tableName)
+	}
+	return fmt.Sprintf("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA='%s' AND TABLE_NAME='%s'", cfg.Database, tableName)
+}
+
+// GetSelectTablesSQL returns the sql querying the names of all tables in the configured database.
+func GetSelectTablesSQL(cfg config.MySqlConfig) string {
+	if cfg.Type == DB_TYPE_POSTGRESQL {
+		return fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_catalog='%s' AND table_schema=current_schema()", cfg.Database)
+	}
+	return fmt.Sprintf("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA='%s'", cfg.Database)
+}
+
+// GetCreateDatabaseSQL returns the sql creating the configured database, the name of non-default org database
+// starts with digits, which must be quoted in postgresql.
+func GetCreateDatabaseSQL(cfg config.MySqlConfig) string {
+	if cfg.Type == DB_TYPE_POSTGRESQL {
+		return fmt.Sprintf("CREATE DATABASE \"%s\"", cfg.Database)
+	}
+	return fmt.Sprintf("CREATE DATABASE %s", cfg.Database)
+}
+
+// GetDropDatabaseSQL returns the sql dropping the configured database, postgresql refuses to drop the database
+// with connections unless forced, which requires postgresql 13 or later.
+func GetDropDatabaseSQL(cfg config.MySqlConfig) string {
+	if cfg.Type == DB_TYPE_POSTGRESQL {
+		return fmt.Sprintf("DROP DATABASE \"%s\" WITH (FORCE)", cfg.Database)
+	}
+	return fmt.Sprintf("DROP DATABASE %s", cfg.Database)
+}
+
+// GetBinaryEqualCondition returns the case sensitive equal condition of the column, the string comparison
+// of mysql is case insensitive by default, while that of postgresql is case sensitive.
+func GetBinaryEqualCondition(cfg config.MySqlConfig, column string) string {
+	if cfg.Type == DB_TYPE_POSTGRESQL {
+		return column + " = ?"
+	}
+	return "binary " + column + " = ?"
+}
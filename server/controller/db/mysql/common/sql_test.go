@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/config"
+)
+
+func TestGetSQL(t *testing.T) {
+	mysqlCfg := config.MySqlConfig{Type: DB_TYPE_MYSQL, Database: "0002_deepflow"}
+	postgresqlCfg := config.MySqlConfig{Type: DB_TYPE_POSTGRESQL, Database: "0002_deepflow"}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "mysql select database",
+			got:  GetSelectDatabaseSQL(mysqlCfg),
+			want: "SELECT SCHEMA_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME='0002_deepflow'",
+		},
+		{
+			name: "postgresql select database",
+			got:  GetSelectDatabaseSQL(postgresqlCfg),
+			want: "SELECT datname FROM pg_database WHERE datname='0002_deepflow'",
+		},
+		{
+			name: "mysql select table",
+			got:  GetSelectTableSQL(mysqlCfg, "org"),
+			want: "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA='0002_deepflow' AND TABLE_NAME='org'",
+		},
+		{
+			name: "postgresql select table",
+			got:  GetSelectTableSQL(postgresqlCfg, "org"),
+			want: "SELECT table_name FROM information_schema.tables WHERE table_catalog='0002_deepflow' AND table_schema=current_schema() AND table_name='org'",
+		},
+		{
+			name: "mysql select tables",
+			got:  GetSelectTablesSQL(mysqlCfg),
+			want: "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA='0002_deepflow'",
+		},
+		{
+			name: "postgresql select tables",
+			got:  GetSelectTablesSQL(postgresqlCfg),
+			want: "SELECT table_name FROM information_schema.tables WHERE table_catalog='0002_deepflow' AND table_schema=current_schema()",
+		},
+		{
+			name: "mysql create database",
+			got:  GetCreateDatabaseSQL(mysqlCfg),
+			want: "CREATE DATABASE 0002_deepflow",
+		},
+		{
+			name: "postgresql create database",
+			got:  GetCreateDatabaseSQL(postgresqlCfg),
+			want: `CREATE DATABASE "0002_deepflow"`,
+		},
+		{
+			name: "mysql drop database",
+			got:  GetDropDatabaseSQL(mysqlCfg),
+			want: "DROP DATABASE 0002_deepflow",
+		},
+		{
+			name: "postgresql drop database",
+			got:  GetDropDatabaseSQL(postgresqlCfg),
+			want: `DROP DATABASE "0002_deepflow" WITH (FORCE)`,
+		},
+		{
+			name: "mysql binary equal",
+			got:  GetBinaryEqualCondition(mysqlCfg, "name"),
+			want: "binary name = ?",
+		},
+		{
+			name: "postgresql binary equal",
+			got:  GetBinaryEqualCondition(postgresqlCfg, "name"),
+			want: "name = ?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPostgreSQLDSN(t *testing.T) {
+	cfg := config.MySqlConfig{
+		Type:         DB_TYPE_POSTGRESQL,
+		Database:     "deepflow",
+		Host:         "fd00::1",
+		Port:         5432,
+		UserName:     "root",
+		UserPassword: "p@ss word",
+	}
+	if got, want := GetPostgreSQLDSN(cfg, true, 30), "postgres://root:p%40ss%20word@[fd00::1]:5432/deepflow?connect_timeout=30"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := GetPostgreSQLDSN(cfg, false, 60), "postgres://root:p%40ss%20word@[fd00::1]:5432/postgres?connect_timeout=60"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestGetDialector(t *testing.T) {
+	typeToDialectorName := map[string]string{
+		"":                 "mysql",
+		DB_TYPE_MYSQL:      "mysql",
+		DB_TYPE_POSTGRESQL: "postgres",
+	}
+	for dbType, name := range typeToDialectorName {
+		dialector, err := GetDialector(config.MySqlConfig{Type: dbType, Host: "127.0.0.1", Port: 3306}, true, 30, false)
+		if err != nil {
+			t.Fatalf("type %s: %s", dbType, err.Error())
+		}
+		if dialector.Name() != name {
+			t.Errorf("type %s: got dialector %s, want %s", dbType, dialector.Name(), name)
+		}
+	}
+	if _, err := GetDialector(config.MySqlConfig{Type: "oracle"}, true, 30, false); err == nil {
+		t.Errorf("type oracle: expected error")
+	}
+}
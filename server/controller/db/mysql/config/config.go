@@ -17,6 +17,7 @@
 package config
 
 type MySqlConfig struct {
+	Type                   string `default:"mysql" yaml:"type"` // mysql or postgresql
 	Database               string `default:"deepflow" yaml:"database"`
 	Host                   string `default:"mysql" yaml:"host"`
 	Port                   uint32 `default:"30130" yaml:"port"`
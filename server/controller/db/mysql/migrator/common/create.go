@@ -17,21 +17,21 @@
 package common
 
 import (
-	"fmt"
-
 	"github.com/op/go-logging"
+
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/common"
 )
 
 var log = logging.MustGetLogger("db.mysql.migrator.common")
 
 func CreateDatabase(dc *DBConfig) error {
 	log.Infof(LogDBName(dc.Config.Database, "create database"))
-	return dc.DB.Exec(fmt.Sprintf("CREATE DATABASE %s", dc.Config.Database)).Error
+	return dc.DB.Exec(common.GetCreateDatabaseSQL(dc.Config)).Error
 }
 
 func CreateDatabaseIfNotExists(dc *DBConfig) (bool, error) {
 	var databaseName string
-	dc.DB.Raw(fmt.Sprintf("SELECT SCHEMA_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME='%s'", dc.Config.Database)).Scan(&databaseName)
+	dc.DB.Raw(common.GetSelectDatabaseSQL(dc.Config)).Scan(&databaseName)
 	if databaseName == dc.Config.Database {
 		return true, nil
 	} else {
@@ -39,3 +39,10 @@ func CreateDatabaseIfNotExists(dc *DBConfig) (bool, error) {
 		return false, err
 	}
 }
+
+// GetTables returns the names of all tables in the database
+func GetTables(dc *DBConfig) ([]string, error) {
+	var tables []string
+	err := dc.DB.Raw(common.GetSelectTablesSQL(dc.Config)).Scan(&tables).Error
+	return tables, err
+}
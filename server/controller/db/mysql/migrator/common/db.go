@@ -68,18 +68,18 @@ func LogDBName(databaseName string, format string, a ...any) string {
 }
 
 func GetSessionWithoutName(cfg config.MySqlConfig) (*gorm.DB, error) {
-	connector, err := common.GetConnector(cfg, false, cfg.TimeOut, false)
+	dialector, err := common.GetDialector(cfg, false, cfg.TimeOut, false)
 	if err != nil {
 		return nil, err
 	}
-	return common.InitSession(cfg, connector)
+	return common.InitSession(cfg, dialector)
 }
 
 func GetSessionWithName(cfg config.MySqlConfig) (*gorm.DB, error) {
 	// set multiStatements=true in dsn only when migrating MySQL
-	connector, err := common.GetConnector(cfg, true, cfg.TimeOut*2, true)
+	dialector, err := common.GetDialector(cfg, true, cfg.TimeOut*2, true)
 	if err != nil {
 		return nil, err
 	}
-	return common.InitSession(cfg, connector)
+	return common.InitSession(cfg, dialector)
 }
@@ -17,15 +17,15 @@
 package common
 
 import (
-	"fmt"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/common"
 )
 
 func DropDatabase(dc *DBConfig) error {
 	log.Infof(LogDBName(dc.Config.Database, "drop database"))
 	var databaseName string
-	dc.DB.Raw(fmt.Sprintf("SELECT SCHEMA_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME='%s'", dc.Config.Database)).Scan(&databaseName)
+	dc.DB.Raw(common.GetSelectDatabaseSQL(dc.Config)).Scan(&databaseName)
 	if databaseName == dc.Config.Database {
-		return dc.DB.Exec(fmt.Sprintf("DROP DATABASE %s", dc.Config.Database)).Error
+		return dc.DB.Exec(common.GetDropDatabaseSQL(dc.Config)).Error
 	} else {
 		log.Infof(LogDBName(dc.Config.Database, "database doesn't exist"))
 		return nil
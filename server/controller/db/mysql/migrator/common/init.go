@@ -41,13 +41,21 @@ func DropDatabaseIfInitTablesFailed(dc *DBConfig, editionFunc EditionInitTablesF
 }
 
 func InitCETables(dc *DBConfig) error {
-	err := InitTables(dc, schema.FILE_DIR)
+	err := InitTables(dc, GetCESchemaDir(dc))
 	if err != nil {
 		return err
 	}
 	return InsertDBVersion(dc, schema.DB_VERSION_TABLE, schema.DB_VERSION_EXPECTED)
 }
 
+// GetCESchemaDir returns the directory of CE sql files of the configured database type
+func GetCESchemaDir(dc *DBConfig) string {
+	if dc.Config.Type == common.DB_TYPE_POSTGRESQL {
+		return schema.POSTGRESQL_FILE_DIR
+	}
+	return schema.FILE_DIR
+}
+
 func InitTables(dc *DBConfig, schemaDir string) error {
 	log.Info(LogDBName(dc.Config.Database, "initialize %s tables", schemaDir)) // TODO
 
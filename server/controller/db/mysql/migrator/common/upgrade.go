@@ -25,12 +25,12 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/khulnasoft/deepflow/server/controller/db/mysql/migrator/schema"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/common"
 	"github.com/khulnasoft/deepflow/server/controller/db/mysql/migrator/schema/script"
 )
 
 func ExecuteCEIssues(dc *DBConfig, curVersion string) error {
-	return ExecuteIssues(dc, curVersion, schema.FILE_DIR)
+	return ExecuteIssues(dc, curVersion, GetCESchemaDir(dc))
 }
 
 func ExecuteIssues(dc *DBConfig, curVersion string, schemaDir string) error {
@@ -65,7 +65,10 @@ func executeIssue(dc *DBConfig, nextVersion string, schemaDir string) error {
 		return nil
 	}
 
-	strSQL := fmt.Sprintf("SET @defaultDatabaseName='%s';\n", "deepflow") + string(byteSQL) // TODO: remove hard code
+	strSQL := string(byteSQL)
+	if dc.Config.Type != common.DB_TYPE_POSTGRESQL {
+		strSQL = fmt.Sprintf("SET @defaultDatabaseName='%s';\n", "deepflow") + strSQL // TODO: remove hard code
+	}
 	err = dc.DB.Exec(strSQL).Error
 	if err != nil {
 		log.Error(LogDBName(dc.Config.Database, "failed to execute %s issue (version: %s): %s", schemaDir, nextVersion, err.Error()))
@@ -89,6 +92,9 @@ func executeScript(dc *DBConfig, nextVersion string) error { // TODO ce
 func getAscSortedNextVersions(files []fs.DirEntry, curVersion string) []string {
 	vs := []string{}
 	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".sql" {
+			continue
+		}
 		vs = append(vs, trimFilenameExt(f.Name()))
 	}
 	// asc sort: split version by ".", compare each number from first to end
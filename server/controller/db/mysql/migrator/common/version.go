@@ -19,6 +19,7 @@ package common
 import (
 	"fmt"
 
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/common"
 	"github.com/khulnasoft/deepflow/server/controller/db/mysql/migrator/schema"
 )
 
@@ -35,6 +36,9 @@ func CheckCEDBVersionTableExists(dc *DBConfig) (bool, error) {
 }
 
 func CreateCEDBVersionTable(dc *DBConfig) error {
+	if dc.Config.Type == common.DB_TYPE_POSTGRESQL {
+		return CreateTable(dc, schema.CREATE_TABLE_DB_VERSION_POSTGRESQL)
+	}
 	return CreateTable(dc, schema.CREATE_TABLE_DB_VERSION)
 }
 
@@ -63,7 +67,7 @@ func GetDBVersion(dc *DBConfig, tableName string) (string, error) {
 
 func CheckTableExists(dc *DBConfig, tableName string) (bool, error) {
 	var table string
-	err := dc.DB.Raw(fmt.Sprintf("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA='%s' AND TABLE_NAME='%s'", dc.Config.Database, tableName)).Scan(&table).Error
+	err := dc.DB.Raw(common.GetSelectTableSQL(dc.Config, tableName)).Scan(&table).Error
 
 	if err != nil {
 		log.Error(LogDBName(dc.Config.Database, "failed to check table %s exists: %s", tableName, err.Error()))
@@ -29,6 +29,8 @@ import (
 var log = logging.MustGetLogger("db.mysql.migrator")
 
 // if configured database does not exist, it is considered a new deployment, will create database and init tables;
+// if configured database only has the lease table of database election, it is also created before migration by
+// election, will init tables as a new database;
 // if configured database exists, but db_version table does not exist, it is also considered a new deployment,
 //
 //	maybe we do not have permission to create database or other reasons, then will init all tables.
@@ -94,12 +96,22 @@ func CreateDatabase(cfg config.MySqlConfig) (databaseExisted bool, err error) {
 		log.Error(common.LogDBName(cfg.Database, "database is not ready: %v", err))
 		return
 	}
-	if !databaseExisted {
-		db, err = common.GetSessionWithName(cfg)
-		if err != nil {
+	db, err = common.GetSessionWithName(cfg)
+	if err != nil {
+		return
+	}
+	dc.SetDB(db)
+	if databaseExisted {
+		// the database created by database election for its lease table is initialized as a new database
+		var tables []string
+		tables, err = common.GetTables(dc)
+		if err != nil || len(tables) != 1 || tables[0] != mysqlcommon.DATABASE_ELECTION_TABLE {
 			return
 		}
-		dc.SetDB(db)
+		log.Info(common.LogDBName(cfg.Database, "database only has table %s, initialize it as a new database", tables[0]))
+		databaseExisted = false
+	}
+	if !databaseExisted {
 		err = edition.DropDatabaseIfInitTablesFailed(dc)
 	}
 	return
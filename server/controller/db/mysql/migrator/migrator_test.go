@@ -0,0 +1,295 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrator
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql"
+	mysqlcommon "github.com/khulnasoft/deepflow/server/controller/db/mysql/common"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/config"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/migrator/common"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/migrator/edition"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/migrator/schema"
+	mysqlmodel "github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
+	"github.com/khulnasoft/deepflow/server/controller/trisolaris/dbmgr"
+)
+
+// The conformance tests run the same migration and queries against every supported database type,
+// they need disposable database servers and are skipped unless configured by environment variables:
+//
+//	DEEPFLOW_TEST_MYSQL_HOST, DEEPFLOW_TEST_MYSQL_PORT, DEEPFLOW_TEST_MYSQL_USER, DEEPFLOW_TEST_MYSQL_PASSWORD
+//	DEEPFLOW_TEST_POSTGRESQL_HOST, DEEPFLOW_TEST_POSTGRESQL_PORT, DEEPFLOW_TEST_POSTGRESQL_USER, DEEPFLOW_TEST_POSTGRESQL_PASSWORD
+//
+// e.g. docker run -d -p 5432:5432 -e POSTGRES_PASSWORD=deepflow postgres:16
+//
+//	DEEPFLOW_TEST_POSTGRESQL_HOST=127.0.0.1 DEEPFLOW_TEST_POSTGRESQL_PASSWORD=deepflow go test ./controller/db/mysql/migrator/
+const conformanceDatabase = "deepflow_conformance_test"
+
+func getConformanceConfig(t *testing.T, dbType string) config.MySqlConfig {
+	prefix := map[string]string{
+		mysqlcommon.DB_TYPE_MYSQL:      "DEEPFLOW_TEST_MYSQL_",
+		mysqlcommon.DB_TYPE_POSTGRESQL: "DEEPFLOW_TEST_POSTGRESQL_",
+	}[dbType]
+	defaultPort := map[string]uint32{
+		mysqlcommon.DB_TYPE_MYSQL:      3306,
+		mysqlcommon.DB_TYPE_POSTGRESQL: 5432,
+	}[dbType]
+	defaultUser := map[string]string{
+		mysqlcommon.DB_TYPE_MYSQL:      "root",
+		mysqlcommon.DB_TYPE_POSTGRESQL: "postgres",
+	}[dbType]
+
+	host := os.Getenv(prefix + "HOST")
+	if host == "" {
+		t.Skipf("%sHOST is not set", prefix)
+	}
+	port := defaultPort
+	if p := os.Getenv(prefix + "PORT"); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			t.Fatalf("invalid %sPORT: %s", prefix, p)
+		}
+		port = uint32(n)
+	}
+	user := os.Getenv(prefix + "USER")
+	if user == "" {
+		user = defaultUser
+	}
+	return config.MySqlConfig{
+		Type:                   dbType,
+		Database:               conformanceDatabase,
+		Host:                   host,
+		Port:                   port,
+		UserName:               user,
+		UserPassword:           os.Getenv(prefix + "PASSWORD"),
+		TimeOut:                30,
+		AutoIncrementIncrement: 1,
+		ResultSetMax:           100000,
+		MaxOpenConns:           10,
+		MaxIdleConns:           5,
+		ConnMaxLifeTime:        60,
+	}
+}
+
+func TestConformance(t *testing.T) {
+	schema.FILE_DIR = "schema/rawsql"
+	schema.POSTGRESQL_FILE_DIR = "schema/rawsql_postgresql"
+
+	for _, dbType := range []string{mysqlcommon.DB_TYPE_MYSQL, mysqlcommon.DB_TYPE_POSTGRESQL} {
+		t.Run(dbType, func(t *testing.T) {
+			cfg := getConformanceConfig(t, dbType)
+			if err := DropDatabase(cfg); err != nil {
+				t.Fatalf("drop database: %s", err.Error())
+			}
+			defer DropDatabase(cfg)
+
+			t.Run("migrate", func(t *testing.T) { testMigrate(t, cfg) })
+			if t.Failed() {
+				return
+			}
+			t.Run("org database", func(t *testing.T) { testORGDatabase(t, cfg) })
+			t.Run("auto increment", func(t *testing.T) { testAutoIncrement(t) })
+			t.Run("case sensitive", func(t *testing.T) { testCaseSensitive(t, cfg) })
+			t.Run("upsert", func(t *testing.T) { testUpsert(t) })
+			t.Run("updated at", func(t *testing.T) { testUpdatedAt(t) })
+			t.Run("bool and bytes", func(t *testing.T) { testBoolAndBytes(t) })
+			t.Run("dbmgr", func(t *testing.T) { testDBMgr(t) })
+		})
+	}
+}
+
+func testMigrate(t *testing.T, cfg config.MySqlConfig) {
+	// database election creates the database and its lease table before migration
+	db, err := common.GetSessionWithoutName(cfg)
+	if err != nil {
+		t.Fatalf("connect without database: %s", err.Error())
+	}
+	if err := db.Exec(mysqlcommon.GetCreateDatabaseSQL(cfg)).Error; err != nil {
+		t.Fatalf("create database: %s", err.Error())
+	}
+	db, err = common.GetSessionWithName(cfg)
+	if err != nil {
+		t.Fatalf("connect to database: %s", err.Error())
+	}
+	if err := db.Exec("CREATE TABLE " + mysqlcommon.DATABASE_ELECTION_TABLE + " (name VARCHAR(256) NOT NULL PRIMARY KEY)").Error; err != nil {
+		t.Fatalf("create election table: %s", err.Error())
+	}
+
+	if err := Migrate(cfg); err != nil {
+		t.Fatalf("migrate new database: %s", err.Error())
+	}
+	if err := edition.CheckDBVersion(mysql.DefaultDB.DB, cfg); err != nil {
+		t.Fatalf("check db version: %s", err.Error())
+	}
+	// the second migration finds the latest db version and does nothing
+	if err := Migrate(cfg); err != nil {
+		t.Fatalf("migrate existing database: %s", err.Error())
+	}
+	exists, err := mysql.CheckIfORGTableExists()
+	if err != nil || !exists {
+		t.Fatalf("org table exists: %v, err: %v", exists, err)
+	}
+	var count int64
+	if err := mysql.DefaultDB.Model(&mysqlmodel.DataSource{}).Count(&count).Error; err != nil || count == 0 {
+		t.Fatalf("data sources initialized: %d, err: %v", count, err)
+	}
+}
+
+func testORGDatabase(t *testing.T, cfg config.MySqlConfig) {
+	orgCfg := mysqlcommon.ReplaceConfigDatabaseName(cfg, 2)
+	existed, err := CreateDatabase(orgCfg)
+	if err != nil {
+		t.Fatalf("create org database %s: %s", orgCfg.Database, err.Error())
+	}
+	if existed {
+		t.Skipf("org database %s exists, which is not created by this test", orgCfg.Database)
+	}
+	defer DropDatabase(orgCfg)
+
+	db, err := mysql.NewDB(orgCfg, 2)
+	if err != nil {
+		t.Fatalf("connect org database: %s", err.Error())
+	}
+	var count int64
+	if err := db.Model(&mysqlmodel.VTapGroup{}).Count(&count).Error; err != nil {
+		t.Fatalf("query org database: %s", err.Error())
+	}
+	if count != 0 {
+		t.Errorf("default org data in org database: %d vtap groups", count)
+	}
+}
+
+func testAutoIncrement(t *testing.T) {
+	// vtap group 1 is inserted with explicit id by init sql
+	group := mysqlmodel.VTapGroup{Name: "conformance", Lcuuid: "conformance-vtap-group", TeamID: 1}
+	if err := mysql.DefaultDB.Create(&group).Error; err != nil {
+		t.Fatalf("create vtap group: %s", err.Error())
+	}
+	if group.ID <= 1 {
+		t.Errorf("vtap group id: %d", group.ID)
+	}
+
+	// auto increment of vl2 starts from 4096
+	network := mysqlmodel.Network{Name: "conformance", Domain: "conformance-domain", State: 2}
+	network.Lcuuid = "conformance-network"
+	if err := mysql.DefaultDB.Create(&network).Error; err != nil {
+		t.Fatalf("create network: %s", err.Error())
+	}
+	if network.ID < 4096 {
+		t.Errorf("network id: %d", network.ID)
+	}
+}
+
+func testCaseSensitive(t *testing.T, cfg config.MySqlConfig) {
+	domain := mysqlmodel.Domain{Name: "Conformance", ClusterID: "d-Conformance", Config: "{}"}
+	domain.Lcuuid = "conformance-domain"
+	if err := mysql.DefaultDB.Create(&domain).Error; err != nil {
+		t.Fatalf("create domain: %s", err.Error())
+	}
+	for name, want := range map[string]int64{"Conformance": 1, "conformance": 0} {
+		var count int64
+		mysql.DefaultDB.Model(&mysqlmodel.Domain{}).Where(mysqlcommon.GetBinaryEqualCondition(cfg, "name"), name).Count(&count)
+		if count != want {
+			t.Errorf("domains named %s: %d, want %d", name, count, want)
+		}
+	}
+}
+
+func testUpsert(t *testing.T) {
+	for _, value := range []string{"v1", "v2"} {
+		labels := []mysqlmodel.ChPodK8sLabel{{ID: 1, Key: "app", Value: value, TeamID: 1, DomainID: 1}}
+		if err := mysql.DefaultDB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&labels).Error; err != nil {
+			t.Fatalf("upsert label: %s", err.Error())
+		}
+	}
+	var label mysqlmodel.ChPodK8sLabel
+	if err := mysql.DefaultDB.Where(map[string]interface{}{"id": 1, "key": "app"}).First(&label).Error; err != nil {
+		t.Fatalf("get label: %s", err.Error())
+	}
+	if label.Value != "v2" {
+		t.Errorf("label value: %s", label.Value)
+	}
+}
+
+func testUpdatedAt(t *testing.T) {
+	var before, after mysqlmodel.DataSource
+	if err := mysql.DefaultDB.Where("id = ?", 1).First(&before).Error; err != nil {
+		t.Fatalf("get data source: %s", err.Error())
+	}
+	time.Sleep(1100 * time.Millisecond)
+	// UpdateColumn does not set updated_at, which is left to the database
+	if err := mysql.DefaultDB.Model(&before).UpdateColumn("retention_time", before.RetentionTime+1).Error; err != nil {
+		t.Fatalf("update data source: %s", err.Error())
+	}
+	mysql.DefaultDB.Where("id = ?", 1).First(&after)
+	if !after.UpdatedAt.After(before.UpdatedAt) {
+		t.Errorf("updated_at is not refreshed: %v -> %v", before.UpdatedAt, after.UpdatedAt)
+	}
+}
+
+func testBoolAndBytes(t *testing.T) {
+	network := mysqlmodel.Network{Name: "shared", Domain: "conformance-domain", State: 2, Shared: true}
+	network.Lcuuid = "conformance-shared-network"
+	if err := mysql.DefaultDB.Create(&network).Error; err != nil {
+		t.Fatalf("create network: %s", err.Error())
+	}
+	var shared mysqlmodel.Network
+	if err := mysql.DefaultDB.Where("lcuuid = ?", network.Lcuuid).First(&shared).Error; err != nil || !shared.Shared {
+		t.Errorf("shared network: %v, err: %v", shared.Shared, err)
+	}
+
+	resource := mysqlmodel.DomainAdditionalResource{Domain: "conformance-domain", CompressedContent: []byte(`{"az": []}`)}
+	if err := mysql.DefaultDB.Create(&resource).Error; err != nil {
+		t.Fatalf("create additional resource: %s", err.Error())
+	}
+	var got mysqlmodel.DomainAdditionalResource
+	if err := mysql.DefaultDB.Where("domain = ?", resource.Domain).First(&got).Error; err != nil {
+		t.Fatalf("get additional resource: %s", err.Error())
+	}
+	if string(got.CompressedContent) != `{"az": []}` {
+		t.Errorf("additional resource content: %s", got.CompressedContent)
+	}
+}
+
+func testDBMgr(t *testing.T) {
+	domain, err := dbmgr.DBMgr[mysqlmodel.Domain](mysql.DefaultDB.DB).GetFromLcuuid("conformance-domain")
+	if err != nil || domain.Name != "Conformance" {
+		t.Fatalf("get domain by lcuuid: %+v, err: %v", domain, err)
+	}
+	groups, err := dbmgr.DBMgr[mysqlmodel.VTapGroup](mysql.DefaultDB.DB).GetBatchFromName("default")
+	if err != nil || len(groups) != 1 {
+		t.Errorf("get vtap groups by name: %d, err: %v", len(groups), err)
+	}
+
+	// the duplicated domain is ignored and the existing one is kept
+	duplicated := mysqlmodel.Domain{Name: "Duplicated", ClusterID: "d-Duplicated", Config: "{}"}
+	duplicated.ID = domain.ID
+	duplicated.Lcuuid = domain.Lcuuid
+	if err := dbmgr.DBMgr[mysqlmodel.Domain](mysql.DefaultDB.DB).InsertIgnore(&duplicated); err != nil {
+		t.Fatalf("insert ignore domain: %s", err.Error())
+	}
+	domain, err = dbmgr.DBMgr[mysqlmodel.Domain](mysql.DefaultDB.DB).GetFromLcuuid("conformance-domain")
+	if err != nil || domain.Name != "Conformance" {
+		t.Errorf("get domain after insert ignore: %+v, err: %v", domain, err)
+	}
+}
@@ -16,4 +16,8 @@
 
 package schema
 
-const FILE_DIR = "/etc/mysql/schema/rawsql"
+// variables rather than constants, so that tests can use the sql files in source tree
+var (
+	FILE_DIR            = "/etc/mysql/schema/rawsql"
+	POSTGRESQL_FILE_DIR = "/etc/mysql/schema/rawsql_postgresql"
+)
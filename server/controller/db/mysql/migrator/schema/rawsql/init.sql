@@ -32,6 +32,47 @@ CREATE TABLE IF NOT EXISTS vtap_repo (
 )ENGINE=innodb AUTO_INCREMENT=1 DEFAULT CHARSET=utf8 COMMENT='store deepflow-agent for easy upgrade';
 TRUNCATE TABLE vtap_repo;
 
+CREATE TABLE IF NOT EXISTS agent_upgrade_rollout (
+    id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    lcuuid              CHAR(64) NOT NULL,
+    name                VARCHAR(256) DEFAULT '',
+    agent_group_lcuuid  CHAR(64) NOT NULL,
+    image_name          VARCHAR(512) NOT NULL,
+    expected_revision   VARCHAR(512) DEFAULT '',
+    rollback_image_name VARCHAR(512) DEFAULT '',
+    wave_type           VARCHAR(32) DEFAULT 'COUNT' COMMENT 'COUNT or PERCENTAGE',
+    wave_size           INTEGER DEFAULT 1,
+    wave_count          INTEGER DEFAULT 0,
+    wave_interval       INTEGER DEFAULT 60 COMMENT 'unit: s',
+    health_timeout      INTEGER DEFAULT 600 COMMENT 'unit: s',
+    failure_threshold   INTEGER DEFAULT 0,
+    failure_action      VARCHAR(32) DEFAULT 'PAUSE' COMMENT 'PAUSE or ROLLBACK',
+    state               VARCHAR(32) DEFAULT 'RUNNING',
+    current_wave        INTEGER DEFAULT 0,
+    message             VARCHAR(512) DEFAULT '',
+    user_id             INTEGER DEFAULT 1,
+    created_at          TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX lcuuid_index(lcuuid)
+)ENGINE=innodb AUTO_INCREMENT=1 DEFAULT CHARSET=utf8 COMMENT='staged agent upgrade';
+TRUNCATE TABLE agent_upgrade_rollout;
+
+CREATE TABLE IF NOT EXISTS agent_upgrade_rollout_agent (
+    id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    rollout_lcuuid      CHAR(64) NOT NULL,
+    agent_lcuuid        CHAR(64) NOT NULL,
+    agent_name          VARCHAR(256) DEFAULT '',
+    wave                INTEGER DEFAULT 0,
+    state               VARCHAR(32) DEFAULT 'PENDING',
+    original_revision   VARCHAR(256) DEFAULT '',
+    original_exceptions BIGINT DEFAULT 0,
+    started_at          DATETIME DEFAULT NULL,
+    finished_at         DATETIME DEFAULT NULL,
+    message             VARCHAR(512) DEFAULT '',
+    INDEX rollout_lcuuid_index(rollout_lcuuid)
+)ENGINE=innodb AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+TRUNCATE TABLE agent_upgrade_rollout_agent;
+
 CREATE TABLE IF NOT EXISTS resource_event (
     id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
     domain              CHAR(64) DEFAULT '',
@@ -1505,6 +1546,18 @@ CREATE TABLE IF NOT EXISTS agent_group_configuration (
 ) ENGINE=innodb DEFAULT CHARSET=utf8 AUTO_INCREMENT=1;
 TRUNCATE TABLE agent_group_configuration;
 
+CREATE TABLE IF NOT EXISTS agent_group_configuration_revision (
+    id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    agent_group_lcuuid  CHAR(64) NOT NULL,
+    revision            INTEGER NOT NULL,
+    yaml                TEXT,
+    user_id             INTEGER DEFAULT 1,
+    comment             VARCHAR(512) DEFAULT '',
+    created_at          TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX agent_group_revision_index(agent_group_lcuuid, revision)
+) ENGINE=innodb DEFAULT CHARSET=utf8 AUTO_INCREMENT=1;
+TRUNCATE TABLE agent_group_configuration_revision;
+
 CREATE TABLE IF NOT EXISTS npb_tunnel (
     id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
     user_id             INTEGER DEFAULT 1,
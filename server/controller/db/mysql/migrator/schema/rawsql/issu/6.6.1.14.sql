@@ -0,0 +1,38 @@
+-- modify start, add upgrade sql
+DROP PROCEDURE IF EXISTS CreateTableIfNotExists;
+
+CREATE PROCEDURE CreateTableIfNotExists()
+BEGIN
+    DECLARE table_count INT;
+
+    SELECT COUNT(*)
+    INTO table_count
+    FROM information_schema.tables
+    WHERE TABLE_SCHEMA = DATABASE()
+    AND TABLE_NAME = 'agent_group_configuration_revision';
+
+    IF table_count = 0 THEN
+        CREATE TABLE agent_group_configuration_revision (
+            id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+            agent_group_lcuuid  CHAR(64) NOT NULL,
+            revision            INTEGER NOT NULL,
+            yaml                TEXT,
+            user_id             INTEGER DEFAULT 1,
+            comment             VARCHAR(512) DEFAULT '',
+            created_at          TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE INDEX agent_group_revision_index(agent_group_lcuuid, revision)
+        ) ENGINE=innodb DEFAULT CHARSET=utf8 AUTO_INCREMENT=1;
+
+        -- the existing configurations are the first revisions
+        INSERT INTO agent_group_configuration_revision (agent_group_lcuuid, revision, yaml, comment)
+            SELECT agent_group_lcuuid, 1, yaml, 'initial revision' FROM agent_group_configuration;
+    END IF;
+END;
+
+CALL CreateTableIfNotExists();
+
+DROP PROCEDURE CreateTableIfNotExists;
+
+-- update db_version to latest, remember to update DB_VERSION_EXPECT in migrate/init.go
+UPDATE db_version SET version='6.6.1.14';
+-- modify end
@@ -0,0 +1,43 @@
+-- modify start, add upgrade sql
+CREATE TABLE IF NOT EXISTS agent_upgrade_rollout (
+    id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    lcuuid              CHAR(64) NOT NULL,
+    name                VARCHAR(256) DEFAULT '',
+    agent_group_lcuuid  CHAR(64) NOT NULL,
+    image_name          VARCHAR(512) NOT NULL,
+    expected_revision   VARCHAR(512) DEFAULT '',
+    rollback_image_name VARCHAR(512) DEFAULT '',
+    wave_type           VARCHAR(32) DEFAULT 'COUNT' COMMENT 'COUNT or PERCENTAGE',
+    wave_size           INTEGER DEFAULT 1,
+    wave_count          INTEGER DEFAULT 0,
+    wave_interval       INTEGER DEFAULT 60 COMMENT 'unit: s',
+    health_timeout      INTEGER DEFAULT 600 COMMENT 'unit: s',
+    failure_threshold   INTEGER DEFAULT 0,
+    failure_action      VARCHAR(32) DEFAULT 'PAUSE' COMMENT 'PAUSE or ROLLBACK',
+    state               VARCHAR(32) DEFAULT 'RUNNING',
+    current_wave        INTEGER DEFAULT 0,
+    message             VARCHAR(512) DEFAULT '',
+    user_id             INTEGER DEFAULT 1,
+    created_at          TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX lcuuid_index(lcuuid)
+)ENGINE=innodb AUTO_INCREMENT=1 DEFAULT CHARSET=utf8 COMMENT='staged agent upgrade';
+
+CREATE TABLE IF NOT EXISTS agent_upgrade_rollout_agent (
+    id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    rollout_lcuuid      CHAR(64) NOT NULL,
+    agent_lcuuid        CHAR(64) NOT NULL,
+    agent_name          VARCHAR(256) DEFAULT '',
+    wave                INTEGER DEFAULT 0,
+    state               VARCHAR(32) DEFAULT 'PENDING',
+    original_revision   VARCHAR(256) DEFAULT '',
+    original_exceptions BIGINT DEFAULT 0,
+    started_at          DATETIME DEFAULT NULL,
+    finished_at         DATETIME DEFAULT NULL,
+    message             VARCHAR(512) DEFAULT '',
+    INDEX rollout_lcuuid_index(rollout_lcuuid)
+)ENGINE=innodb AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+
+-- update db_version to latest, remember to update DB_VERSION_EXPECT in migrate/init.go
+UPDATE db_version SET version='6.6.1.15';
+-- modify end
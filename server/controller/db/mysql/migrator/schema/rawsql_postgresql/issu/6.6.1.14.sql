@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS agent_group_configuration_revision (
+    id                       SERIAL NOT NULL PRIMARY KEY,
+    agent_group_lcuuid       VARCHAR(64) NOT NULL,
+    revision                 INTEGER NOT NULL,
+    yaml                     TEXT,
+    user_id                  INTEGER DEFAULT 1,
+    comment                  VARCHAR(512) DEFAULT '',
+    created_at               TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS agent_group_revision_index ON agent_group_configuration_revision (agent_group_lcuuid, revision);
+
+-- the existing configurations are the first revisions
+INSERT INTO agent_group_configuration_revision (agent_group_lcuuid, revision, yaml, comment)
+    SELECT agent_group_lcuuid, 1, yaml, 'initial revision' FROM agent_group_configuration
+    ON CONFLICT DO NOTHING;
+
+UPDATE db_version SET version='6.6.1.14';
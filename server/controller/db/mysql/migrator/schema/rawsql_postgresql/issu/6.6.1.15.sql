@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS agent_upgrade_rollout (
+    id                       SERIAL NOT NULL PRIMARY KEY,
+    lcuuid                   VARCHAR(64) NOT NULL,
+    name                     VARCHAR(256) DEFAULT '',
+    agent_group_lcuuid       VARCHAR(64) NOT NULL,
+    image_name               VARCHAR(512) NOT NULL,
+    expected_revision        VARCHAR(512) DEFAULT '',
+    rollback_image_name      VARCHAR(512) DEFAULT '',
+    wave_type                VARCHAR(32) DEFAULT 'COUNT',
+    wave_size                INTEGER DEFAULT 1,
+    wave_count               INTEGER DEFAULT 0,
+    wave_interval            INTEGER DEFAULT 60,
+    health_timeout           INTEGER DEFAULT 600,
+    failure_threshold        INTEGER DEFAULT 0,
+    failure_action           VARCHAR(32) DEFAULT 'PAUSE',
+    state                    VARCHAR(32) DEFAULT 'RUNNING',
+    current_wave             INTEGER DEFAULT 0,
+    message                  VARCHAR(512) DEFAULT '',
+    user_id                  INTEGER DEFAULT 1,
+    created_at               TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at               TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS agent_upgrade_rollout_lcuuid_index ON agent_upgrade_rollout (lcuuid);
+DROP TRIGGER IF EXISTS agent_upgrade_rollout_updated_at ON agent_upgrade_rollout;
+CREATE TRIGGER agent_upgrade_rollout_updated_at BEFORE UPDATE ON agent_upgrade_rollout FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+COMMENT ON TABLE agent_upgrade_rollout IS 'staged agent upgrade';
+COMMENT ON COLUMN agent_upgrade_rollout.wave_type IS 'COUNT or PERCENTAGE';
+COMMENT ON COLUMN agent_upgrade_rollout.wave_interval IS 'unit: s';
+COMMENT ON COLUMN agent_upgrade_rollout.health_timeout IS 'unit: s';
+COMMENT ON COLUMN agent_upgrade_rollout.failure_action IS 'PAUSE or ROLLBACK';
+
+CREATE TABLE IF NOT EXISTS agent_upgrade_rollout_agent (
+    id                       SERIAL NOT NULL PRIMARY KEY,
+    rollout_lcuuid           VARCHAR(64) NOT NULL,
+    agent_lcuuid             VARCHAR(64) NOT NULL,
+    agent_name               VARCHAR(256) DEFAULT '',
+    wave                     INTEGER DEFAULT 0,
+    state                    VARCHAR(32) DEFAULT 'PENDING',
+    original_revision        VARCHAR(256) DEFAULT '',
+    original_exceptions      BIGINT DEFAULT 0,
+    started_at               TIMESTAMP DEFAULT NULL,
+    finished_at              TIMESTAMP DEFAULT NULL,
+    message                  VARCHAR(512) DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS agent_upgrade_rollout_agent_rollout_lcuuid_index ON agent_upgrade_rollout_agent (rollout_lcuuid);
+
+UPDATE db_version SET version='6.6.1.15';
@@ -486,6 +486,51 @@ func (VTapRepo) TableName() string {
 	return "vtap_repo"
 }
 
+type AgentUpgradeRollout struct {
+	ID                int       `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
+	Lcuuid            string    `gorm:"column:lcuuid;type:char(64);not null" json:"LCUUID"`
+	Name              string    `gorm:"column:name;type:varchar(256);default:''" json:"NAME"`
+	AgentGroupLcuuid  string    `gorm:"column:agent_group_lcuuid;type:char(64);not null" json:"AGENT_GROUP_LCUUID"`
+	ImageName         string    `gorm:"column:image_name;type:varchar(512);not null" json:"IMAGE_NAME"`
+	ExpectedRevision  string    `gorm:"column:expected_revision;type:varchar(512);default:''" json:"EXPECTED_REVISION"`
+	RollbackImageName string    `gorm:"column:rollback_image_name;type:varchar(512);default:''" json:"ROLLBACK_IMAGE_NAME"`
+	WaveType          string    `gorm:"column:wave_type;type:varchar(32);default:'COUNT'" json:"WAVE_TYPE"` // COUNT or PERCENTAGE
+	WaveSize          int       `gorm:"column:wave_size;type:int;default:1" json:"WAVE_SIZE"`
+	WaveCount         int       `gorm:"column:wave_count;type:int;default:0" json:"WAVE_COUNT"`
+	WaveInterval      int       `gorm:"column:wave_interval;type:int;default:60" json:"WAVE_INTERVAL"`   // unit: s
+	HealthTimeout     int       `gorm:"column:health_timeout;type:int;default:600" json:"HEALTH_TIMEOUT"` // unit: s
+	FailureThreshold  int       `gorm:"column:failure_threshold;type:int;default:0" json:"FAILURE_THRESHOLD"`
+	FailureAction     string    `gorm:"column:failure_action;type:varchar(32);default:'PAUSE'" json:"FAILURE_ACTION"` // PAUSE or ROLLBACK
+	State             string    `gorm:"column:state;type:varchar(32);default:'RUNNING'" json:"STATE"`
+	CurrentWave       int       `gorm:"column:current_wave;type:int;default:0" json:"CURRENT_WAVE"`
+	Message           string    `gorm:"column:message;type:varchar(512);default:''" json:"MESSAGE"`
+	UserID            int       `gorm:"column:user_id;type:int;default:1" json:"USER_ID"`
+	CreatedAt         time.Time `gorm:"column:created_at;type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"CREATED_AT"`
+	UpdatedAt         time.Time `gorm:"column:updated_at;type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"UPDATED_AT"`
+}
+
+func (AgentUpgradeRollout) TableName() string {
+	return "agent_upgrade_rollout"
+}
+
+type AgentUpgradeRolloutAgent struct {
+	ID                 int        `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
+	RolloutLcuuid      string     `gorm:"column:rollout_lcuuid;type:char(64);not null" json:"ROLLOUT_LCUUID"`
+	AgentLcuuid        string     `gorm:"column:agent_lcuuid;type:char(64);not null" json:"AGENT_LCUUID"`
+	AgentName          string     `gorm:"column:agent_name;type:varchar(256);default:''" json:"AGENT_NAME"`
+	Wave               int        `gorm:"column:wave;type:int;default:0" json:"WAVE"`
+	State              string     `gorm:"column:state;type:varchar(32);default:'PENDING'" json:"STATE"`
+	OriginalRevision   string     `gorm:"column:original_revision;type:varchar(256);default:''" json:"ORIGINAL_REVISION"`
+	OriginalExceptions int64      `gorm:"column:original_exceptions;type:bigint;default:0" json:"ORIGINAL_EXCEPTIONS"`
+	StartedAt          *time.Time `gorm:"column:started_at;type:datetime;default:null" json:"STARTED_AT"`
+	FinishedAt         *time.Time `gorm:"column:finished_at;type:datetime;default:null" json:"FINISHED_AT"`
+	Message            string     `gorm:"column:message;type:varchar(512);default:''" json:"MESSAGE"`
+}
+
+func (AgentUpgradeRolloutAgent) TableName() string {
+	return "agent_upgrade_rollout_agent"
+}
+
 type Plugin struct {
 	ID        int             `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
 	Name      string          `gorm:"column:name;type:varchar(256);not null" json:"NAME"`
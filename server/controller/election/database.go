@@ -0,0 +1,358 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package election
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/khulnasoft/deepflow/server/controller/config"
+	mysqlcommon "github.com/khulnasoft/deepflow/server/controller/db/mysql/common"
+	mysqlcfg "github.com/khulnasoft/deepflow/server/controller/db/mysql/config"
+)
+
+const DATABASE_ELECTION_TABLE = mysqlcommon.DATABASE_ELECTION_TABLE
+
+// the lease table is created by election rather than migrator, because migration is done by the leader,
+// the migrator initializes the database only having the lease table as a new one.
+// the statement works for both mysql and postgresql.
+var createDatabaseElectionTableSQL = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+    name                VARCHAR(256) NOT NULL PRIMARY KEY,
+    holder_identity     VARCHAR(256) NOT NULL DEFAULT '',
+    acquire_time        BIGINT NOT NULL DEFAULT 0,
+    renew_time          BIGINT NOT NULL DEFAULT 0,
+    lease_duration      INTEGER NOT NULL DEFAULT 0,
+    fencing_token       BIGINT NOT NULL DEFAULT 0,
+    version             BIGINT NOT NULL DEFAULT 0
+)`, DATABASE_ELECTION_TABLE)
+
+type electionLease struct {
+	Name           string `gorm:"primaryKey;column:name;type:varchar(256)"`
+	HolderIdentity string `gorm:"column:holder_identity;type:varchar(256)"`
+	AcquireTime    int64  `gorm:"column:acquire_time;type:bigint"` // unit: millisecond
+	RenewTime      int64  `gorm:"column:renew_time;type:bigint"`   // unit: millisecond
+	LeaseDuration  int    `gorm:"column:lease_duration;type:int"`  // unit: second
+	FencingToken   int64  `gorm:"column:fencing_token;type:bigint"`
+	Version        int64  `gorm:"column:version;type:bigint"` // increases on every write, used for optimistic locking
+}
+
+func (electionLease) TableName() string {
+	return DATABASE_ELECTION_TABLE
+}
+
+// databaseElector stores the lease in a row of the controller metadata database, the leader renews it as
+// heartbeat. Like client-go, whether a lease has expired is judged by the local time when its change was
+// observed, so clocks of controllers are not required to be synchronized.
+type databaseElector struct {
+	id            string
+	name          string
+	dbCfg         mysqlcfg.MySqlConfig
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+	now           func() time.Time
+
+	// db and prepared are shared by the Run goroutine and GetLease callers
+	mu            sync.Mutex
+	db            *gorm.DB
+	prepared      bool
+	observedLease *electionLease
+	observedTime  time.Time
+}
+
+func newDatabaseElector(cfg *config.ControllerConfig, id string, leaseDuration, renewDeadline, retryPeriod time.Duration) *databaseElector {
+	return &databaseElector{
+		id:            id,
+		name:          cfg.ElectionName,
+		dbCfg:         cfg.MySqlCfg,
+		leaseDuration: leaseDuration,
+		renewDeadline: renewDeadline,
+		retryPeriod:   retryPeriod,
+		now:           time.Now,
+	}
+}
+
+func (e *databaseElector) Run(ctx context.Context) {
+	for {
+		if !e.acquire(ctx) {
+			return
+		}
+		onStartedLeading(e.id, e.observedLease.FencingToken)
+		e.renew(ctx)
+		e.release()
+		onStoppedLeading(ctx, e.id, e)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (e *databaseElector) GetLease(ctx context.Context) (*LeaseRecord, error) {
+	db, err := e.getDB()
+	if err != nil {
+		return nil, err
+	}
+	var lease electionLease
+	if err := db.WithContext(ctx).Where("name = ?", e.name).Take(&lease).Error; err != nil {
+		e.unprepare()
+		return nil, fmt.Errorf("get lease %s failed: %v", e.name, err)
+	}
+	return &LeaseRecord{
+		HolderIdentity: lease.HolderIdentity,
+		AcquireTime:    time.UnixMilli(lease.AcquireTime),
+		RenewTime:      time.UnixMilli(lease.RenewTime),
+		FencingToken:   lease.FencingToken,
+	}, nil
+}
+
+// Fence locks the lease row in the transaction, so the lease can not be renewed or taken over by other
+// controllers until the transaction ends
+func (e *databaseElector) Fence(tx *gorm.DB, token int64) error {
+	var lease electionLease
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("name = ? AND holder_identity = ? AND fencing_token = ?", e.name, e.id, token).Take(&lease).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotLeader
+	}
+	return err
+}
+
+// acquire blocks until the lease is acquired or ctx is done
+func (e *databaseElector) acquire(ctx context.Context) bool {
+	for {
+		if e.tryAcquireOrRenew(ctx) {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(e.retryPeriod):
+		}
+	}
+}
+
+// renew blocks until the lease can not be renewed in renewDeadline or ctx is done
+func (e *databaseElector) renew(ctx context.Context) {
+	lastRenewTime := e.now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.retryPeriod):
+		}
+		if e.tryAcquireOrRenew(ctx) {
+			lastRenewTime = e.now()
+		} else if e.observedLease != nil && e.observedLease.HolderIdentity != e.id {
+			return
+		} else if e.now().Sub(lastRenewTime) >= e.renewDeadline {
+			log.Errorf("failed to renew lease %s in %v", e.name, e.renewDeadline)
+			return
+		}
+	}
+}
+
+// release gives up the lease so that other controllers do not have to wait for it to expire
+func (e *databaseElector) release() {
+	if e.observedLease == nil || e.observedLease.HolderIdentity != e.id {
+		return
+	}
+	db, err := e.getDB()
+	if err != nil {
+		log.Errorf("release lease %s failed: %v", e.name, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.renewDeadline)
+	defer cancel()
+	now := e.now()
+	result := db.WithContext(ctx).Model(&electionLease{}).
+		Where("name = ? AND version = ?", e.name, e.observedLease.Version).
+		Updates(map[string]interface{}{
+			"holder_identity": "",
+			"renew_time":      now.UnixMilli(),
+			"lease_duration":  1,
+			"version":         e.observedLease.Version + 1,
+		})
+	if result.Error != nil {
+		log.Errorf("release lease %s failed: %v", e.name, result.Error)
+		return
+	}
+	if result.RowsAffected == 1 {
+		lease := *e.observedLease
+		lease.HolderIdentity = ""
+		lease.RenewTime = now.UnixMilli()
+		lease.LeaseDuration = 1
+		lease.Version++
+		e.setObservedLease(&lease, now)
+	}
+}
+
+// tryAcquireOrRenew returns true if the lease is held by this controller after the try
+func (e *databaseElector) tryAcquireOrRenew(ctx context.Context) bool {
+	db, err := e.getDB()
+	if err != nil {
+		log.Error(err)
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.renewDeadline)
+	defer cancel()
+	db = db.WithContext(ctx)
+	now := e.now()
+
+	var lease electionLease
+	err = db.Where("name = ?", e.name).Take(&lease).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		lease = electionLease{
+			Name:           e.name,
+			HolderIdentity: e.id,
+			AcquireTime:    now.UnixMilli(),
+			RenewTime:      now.UnixMilli(),
+			LeaseDuration:  int(e.leaseDuration / time.Second),
+			FencingToken:   1,
+			Version:        1,
+		}
+		if err = db.Create(&lease).Error; err != nil {
+			// maybe created by another controller at the same time
+			log.Warningf("create lease %s failed: %v", e.name, err)
+			return false
+		}
+		e.setObservedLease(&lease, now)
+		return true
+	} else if err != nil {
+		log.Errorf("get lease %s failed: %v", e.name, err)
+		e.unprepare()
+		return false
+	}
+
+	if e.observedLease == nil || e.observedLease.Version != lease.Version {
+		e.setObservedLease(&lease, now)
+	}
+	if lease.HolderIdentity != "" && lease.HolderIdentity != e.id &&
+		e.observedTime.Add(time.Duration(lease.LeaseDuration)*time.Second).After(now) {
+		return false
+	}
+
+	newLease := lease
+	if lease.HolderIdentity != e.id {
+		newLease.HolderIdentity = e.id
+		newLease.AcquireTime = now.UnixMilli()
+		newLease.FencingToken++
+	}
+	newLease.RenewTime = now.UnixMilli()
+	newLease.LeaseDuration = int(e.leaseDuration / time.Second)
+	newLease.Version++
+	result := db.Model(&electionLease{}).
+		Where("name = ? AND version = ?", e.name, lease.Version).
+		Updates(map[string]interface{}{
+			"holder_identity": newLease.HolderIdentity,
+			"acquire_time":    newLease.AcquireTime,
+			"renew_time":      newLease.RenewTime,
+			"lease_duration":  newLease.LeaseDuration,
+			"fencing_token":   newLease.FencingToken,
+			"version":         newLease.Version,
+		})
+	if result.Error != nil {
+		log.Errorf("update lease %s failed: %v", e.name, result.Error)
+		return false
+	}
+	if result.RowsAffected != 1 {
+		// updated by another controller after we got it
+		return false
+	}
+	e.setObservedLease(&newLease, now)
+	return true
+}
+
+func (e *databaseElector) setObservedLease(lease *electionLease, now time.Time) {
+	if e.observedLease == nil || e.observedLease.HolderIdentity != lease.HolderIdentity {
+		if lease.HolderIdentity != "" {
+			onNewLeader(lease.HolderIdentity)
+		}
+	}
+	e.observedLease = lease
+	e.observedTime = now
+}
+
+// getDB returns the connection to the database after preparing it
+func (e *databaseElector) getDB() (*gorm.DB, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.prepare(); err != nil {
+		return nil, err
+	}
+	return e.db, nil
+}
+
+// unprepare makes the next getDB check the connection and the lease table again after a query failure
+func (e *databaseElector) unprepare() {
+	e.mu.Lock()
+	e.prepared = false
+	e.mu.Unlock()
+}
+
+// prepare connects to the database and creates the lease table if necessary, the database may not exist
+// because it is created by migration after election. It is called with mu held.
+func (e *databaseElector) prepare() error {
+	if e.prepared {
+		return nil
+	}
+	if e.db == nil {
+		db, err := e.connect()
+		if err != nil {
+			return err
+		}
+		e.db = db
+	}
+	if err := e.db.Exec(createDatabaseElectionTableSQL).Error; err != nil {
+		// the database may be recreated, reconnect next time
+		if sqlDB, err := e.db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		e.db = nil
+		return fmt.Errorf("create table %s failed: %v", DATABASE_ELECTION_TABLE, err)
+	}
+	e.prepared = true
+	return nil
+}
+
+func (e *databaseElector) connect() (*gorm.DB, error) {
+	dialector, err := mysqlcommon.GetDialector(e.dbCfg, false, e.dbCfg.TimeOut, false)
+	if err != nil {
+		return nil, err
+	}
+	db, err := mysqlcommon.InitSession(e.dbCfg, dialector)
+	if err != nil {
+		return nil, err
+	}
+	var databaseName string
+	db.Raw(mysqlcommon.GetSelectDatabaseSQL(e.dbCfg)).Scan(&databaseName)
+	if databaseName != e.dbCfg.Database {
+		log.Infof("create database %s for election", e.dbCfg.Database)
+		if err = db.Exec(mysqlcommon.GetCreateDatabaseSQL(e.dbCfg)).Error; err != nil {
+			log.Warningf("create database %s failed: %v", e.dbCfg.Database, err)
+		}
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	return mysqlcommon.GetSession(e.dbCfg)
+}
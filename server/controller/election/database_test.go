@@ -0,0 +1,258 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package election
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func newTestDatabaseElector(db *gorm.DB, id string, clock *fakeClock) *databaseElector {
+	return &databaseElector{
+		id:            id,
+		name:          "deepflow-server",
+		leaseDuration: 15 * time.Second,
+		renewDeadline: 10 * time.Second,
+		retryPeriod:   2 * time.Second,
+		now:           clock.now,
+		db:            db,
+	}
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "election.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("create sqlite database failed: %s", err.Error())
+	}
+	return db
+}
+
+func TestDatabaseElectorTryAcquireOrRenew(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	clockA := &fakeClock{t: time.Unix(1700000000, 0)}
+	clockB := &fakeClock{t: time.Unix(1700000100, 0)} // clocks of controllers are not synchronized
+	a := newTestDatabaseElector(db, "a", clockA)
+	b := newTestDatabaseElector(db, "b", clockB)
+
+	if !a.tryAcquireOrRenew(ctx) {
+		t.Fatal("a should acquire the new lease")
+	}
+	if b.tryAcquireOrRenew(ctx) {
+		t.Fatal("b should not acquire the lease held by a")
+	}
+	lease, err := b.GetLease(ctx)
+	if err != nil {
+		t.Fatalf("get lease failed: %s", err.Error())
+	}
+	if lease.HolderIdentity != "a" || lease.FencingToken != 1 || !lease.AcquireTime.Equal(clockA.t) {
+		t.Fatalf("unexpected lease: %+v", lease)
+	}
+
+	// a renews the lease, the acquire time and fencing token are kept
+	clockA.t = clockA.t.Add(2 * time.Second)
+	if !a.tryAcquireOrRenew(ctx) {
+		t.Fatal("a should renew its lease")
+	}
+	lease, _ = b.GetLease(ctx)
+	if lease.FencingToken != 1 || !lease.AcquireTime.Equal(time.Unix(1700000000, 0)) || !lease.RenewTime.Equal(clockA.t) {
+		t.Fatalf("unexpected lease after renewal: %+v", lease)
+	}
+
+	// b observes the renewal, the lease does not expire until lease duration after the observation
+	clockB.t = clockB.t.Add(14 * time.Second)
+	if b.tryAcquireOrRenew(ctx) {
+		t.Fatal("b should not acquire the lease renewed by a")
+	}
+	clockB.t = clockB.t.Add(14 * time.Second)
+	if b.tryAcquireOrRenew(ctx) {
+		t.Fatal("b should not acquire the lease before it expires")
+	}
+
+	// a stops renewing, b acquires the expired lease with a new fencing token
+	clockB.t = clockB.t.Add(2 * time.Second)
+	if !b.tryAcquireOrRenew(ctx) {
+		t.Fatal("b should acquire the expired lease")
+	}
+	lease, _ = a.GetLease(ctx)
+	if lease.HolderIdentity != "b" || lease.FencingToken != 2 || !lease.AcquireTime.Equal(clockB.t) {
+		t.Fatalf("unexpected lease after transition: %+v", lease)
+	}
+
+	// a finds that it has lost the lease
+	clockA.t = clockA.t.Add(2 * time.Second)
+	if a.tryAcquireOrRenew(ctx) {
+		t.Fatal("a should not renew the lease held by b")
+	}
+	if a.observedLease.HolderIdentity != "b" {
+		t.Fatalf("a should observe b as the holder, got %s", a.observedLease.HolderIdentity)
+	}
+}
+
+func TestDatabaseElectorRelease(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	a := newTestDatabaseElector(db, "a", clock)
+	b := newTestDatabaseElector(db, "b", clock)
+
+	if !a.tryAcquireOrRenew(ctx) {
+		t.Fatal("a should acquire the new lease")
+	}
+	if b.tryAcquireOrRenew(ctx) {
+		t.Fatal("b should not acquire the lease held by a")
+	}
+	a.release()
+
+	// the released lease can be acquired without waiting for expiration
+	if !b.tryAcquireOrRenew(ctx) {
+		t.Fatal("b should acquire the released lease")
+	}
+	lease, _ := b.GetLease(ctx)
+	if lease.HolderIdentity != "b" || lease.FencingToken != 2 {
+		t.Fatalf("unexpected lease after release: %+v", lease)
+	}
+}
+
+func TestFencedTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	a := newTestDatabaseElector(db, "a", clock)
+	b := newTestDatabaseElector(db, "b", clock)
+	if err := db.Exec("CREATE TABLE fenced_write (id INTEGER PRIMARY KEY)").Error; err != nil {
+		t.Fatalf("create table failed: %s", err.Error())
+	}
+	write := func(tx *gorm.DB) error {
+		return tx.Exec("INSERT INTO fenced_write (id) VALUES (NULL)").Error
+	}
+	countWrites := func() int64 {
+		var count int64
+		db.Table("fenced_write").Count(&count)
+		return count
+	}
+	setElector(a)
+	defer setElector(nil)
+	defer atomic.StoreInt64(&fencingToken, 0)
+
+	if err := FencedTransaction(db, write); err != ErrNotLeader {
+		t.Fatalf("write without leadership should fail with ErrNotLeader, got %v", err)
+	}
+	if !a.tryAcquireOrRenew(ctx) {
+		t.Fatal("a should acquire the new lease")
+	}
+	atomic.StoreInt64(&fencingToken, a.observedLease.FencingToken)
+	if err := FencedTransaction(db, write); err != nil {
+		t.Fatalf("write of the leader failed: %s", err.Error())
+	}
+
+	// b takes over the lease while a still thinks it is the leader
+	a.release()
+	if !b.tryAcquireOrRenew(ctx) {
+		t.Fatal("b should acquire the released lease")
+	}
+	if err := FencedTransaction(db, write); err != ErrNotLeader {
+		t.Fatalf("write of the stale leader should fail with ErrNotLeader, got %v", err)
+	}
+	if count := countWrites(); count != 1 {
+		t.Fatalf("only the write of the leader should be committed, got %d writes", count)
+	}
+}
+
+func TestDatabaseElectorConcurrentGetLease(t *testing.T) {
+	ctx := context.Background()
+	a := newTestDatabaseElector(newTestDB(t), "a", &fakeClock{t: time.Unix(1700000000, 0)})
+
+	// GetLease is called by checkLeaderValid while Run tries to acquire or renew the lease
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			a.tryAcquireOrRenew(ctx)
+			a.unprepare()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			a.GetLease(ctx)
+			a.unprepare()
+		}
+	}()
+	wg.Wait()
+
+	lease, err := a.GetLease(ctx)
+	if err != nil {
+		t.Fatalf("get lease failed: %s", err.Error())
+	}
+	if lease.HolderIdentity != "a" {
+		t.Fatalf("lease should be held by a, got %s", lease.HolderIdentity)
+	}
+}
+
+func TestDatabaseElectorRun(t *testing.T) {
+	db := newTestDB(t)
+	a := newTestDatabaseElector(db, "a", &fakeClock{})
+	a.now = time.Now
+	a.retryPeriod = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for GetFencingToken() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("a should become the leader")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if leaderData.GetLeader() != "a" {
+		t.Fatalf("leader should be a, got %s", leaderData.GetLeader())
+	}
+
+	cancel()
+	<-done
+	if GetFencingToken() != 0 {
+		t.Fatal("fencing token should be reset after leadership is lost")
+	}
+	lease, err := a.GetLease(context.Background())
+	if err != nil {
+		t.Fatalf("get lease failed: %s", err.Error())
+	}
+	if lease.HolderIdentity != "" {
+		t.Fatalf("lease should be released, got holder %s", lease.HolderIdentity)
+	}
+}
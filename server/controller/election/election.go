@@ -20,19 +20,15 @@ package election
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	logging "github.com/op/go-logging"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
-	clientset "k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/tools/leaderelection"
-	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"gorm.io/gorm"
 
 	"github.com/khulnasoft/deepflow/server/controller/common"
 	"github.com/khulnasoft/deepflow/server/controller/config"
@@ -41,8 +37,9 @@ import (
 )
 
 var (
-	acquireTime = int64(0)
-	startTime   = time.Now().Unix()
+	acquireTime  = int64(0)
+	startTime    = time.Now().Unix()
+	fencingToken = int64(0)
 )
 
 func GetAcquireTime() int64 { // 提供给 trisolairs 使用，确保各个 server 的 trisolairs 版本号是一致的
@@ -53,8 +50,59 @@ func GetAcquireTime() int64 { // 提供给 trisolairs 使用，确保各个 serv
 	return acquireTime
 }
 
+// GetFencingToken returns the fencing token of the lease held by this controller, 0 if it is not the leader.
+// The token increases every time the leadership changes, writers protected by the lease carry it by
+// FencedTransaction so that writes from a stale leader are rejected.
+func GetFencingToken() int64 {
+	if common.IsStandaloneRunningMode() {
+		return 1
+	}
+	return atomic.LoadInt64(&fencingToken)
+}
+
+var ErrNotLeader = errors.New("this controller is not the leader")
+
+var (
+	electorLock    sync.RWMutex
+	runningElector Elector
+)
+
+func setElector(e Elector) {
+	electorLock.Lock()
+	runningElector = e
+	electorLock.Unlock()
+}
+
+func getElector() Elector {
+	electorLock.RLock()
+	defer electorLock.RUnlock()
+	return runningElector
+}
+
+// FencedTransaction runs fc in a transaction of db if this controller holds the lease, the transaction is
+// rolled back with ErrNotLeader if the lease has been taken over by another controller. With database election,
+// db must be the database storing the lease, whose row is locked until the writes of fc are committed.
+func FencedTransaction(db *gorm.DB, fc func(tx *gorm.DB) error) error {
+	token := GetFencingToken()
+	if token == 0 {
+		return ErrNotLeader
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		// there is no elector in standalone mode
+		if e := getElector(); e != nil {
+			if err := e.Fence(tx, token); err != nil {
+				return err
+			}
+		}
+		return fc(tx)
+	})
+}
+
 const (
 	ID_ITEM_NUM = 4
+
+	ELECTION_TYPE_KUBERNETES = "kubernetes"
+	ELECTION_TYPE_DATABASE   = "database"
 )
 
 type LeaderData struct {
@@ -89,20 +137,45 @@ var leaderData = &LeaderData{
 	isValide: atomicbool.NewBool(false),
 }
 
-func buildConfig(kubeconfig string) (*rest.Config, error) {
-	if kubeconfig != "" {
-		cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
-		if err != nil {
-			return nil, err
-		}
-		return cfg, nil
+// LeaseRecord is the lease shared by all controllers, whoever holds it is the leader
+type LeaseRecord struct {
+	HolderIdentity string
+	AcquireTime    time.Time
+	RenewTime      time.Time
+	FencingToken   int64
+}
+
+// Elector campaigns for the lease, different backends store the lease in different places
+type Elector interface {
+	// Run campaigns for the lease until ctx is done, leadership changes are reported by
+	// onStartedLeading, onStoppedLeading and onNewLeader
+	Run(ctx context.Context)
+	// GetLease returns the lease currently stored in the backend
+	GetLease(ctx context.Context) (*LeaseRecord, error)
+	// Fence returns ErrNotLeader if the lease is not held by this controller with the fencing token,
+	// it is called in the transaction of FencedTransaction
+	Fence(tx *gorm.DB, token int64) error
+}
+
+func newElector(cfg *config.ControllerConfig, id string) (Elector, error) {
+	leaseDuration := time.Duration(cfg.ElectionLeaseDuration) * time.Second
+	renewDeadline := time.Duration(cfg.ElectionRenewDeadline) * time.Second
+	retryPeriod := time.Duration(cfg.ElectionRetryPeriod) * time.Second
+	if leaseDuration <= renewDeadline || renewDeadline <= retryPeriod || retryPeriod <= 0 {
+		return nil, fmt.Errorf(
+			"election-lease-duration(%v) must be greater than election-renew-deadline(%v), which must be greater than election-retry-period(%v)",
+			leaseDuration, renewDeadline, retryPeriod,
+		)
 	}
 
-	cfg, err := rest.InClusterConfig()
-	if err != nil {
-		return nil, err
+	switch cfg.ElectionType {
+	case ELECTION_TYPE_KUBERNETES, "":
+		return newKubernetesElector(cfg, id, leaseDuration, renewDeadline, retryPeriod)
+	case ELECTION_TYPE_DATABASE:
+		return newDatabaseElector(cfg, id, leaseDuration, renewDeadline, retryPeriod), nil
+	default:
+		return nil, fmt.Errorf("election type %s is not supported", cfg.ElectionType)
 	}
-	return cfg, nil
 }
 
 func getID() string {
@@ -121,10 +194,10 @@ func GetLeader() string {
 	return leaderData.GetLeader()
 }
 
-func getCurrentLeader(ctx context.Context, lock *resourcelock.LeaseLock) string {
+func getCurrentLeader(ctx context.Context, elector Elector) string {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	record, _, err := lock.Get(ctx)
+	record, err := elector.GetLease(ctx)
 	if err != nil {
 		log.Error(err)
 		return ""
@@ -133,15 +206,38 @@ func getCurrentLeader(ctx context.Context, lock *resourcelock.LeaseLock) string
 	return record.HolderIdentity
 }
 
-func checkLeaderValid(ctx context.Context, lock *resourcelock.LeaseLock) { // server 启动后，确保设置稳定的 leaderData
+func onStartedLeading(id string, token int64) {
+	// we're notified when we start - this is where you would
+	// usually put your code
+	log.Infof("%s is the leader, fencing token: %d", id, token)
+	atomic.StoreInt64(&fencingToken, token)
+	leaderData.SetLeader(id)
+}
+
+func onStoppedLeading(ctx context.Context, id string, elector Elector) {
+	// we can do cleanup here
+	log.Infof("leader lost: %s", id)
+	atomic.StoreInt64(&fencingToken, 0)
+	leaderData.SetLeader(getCurrentLeader(ctx, elector))
+}
+
+func onNewLeader(identity string) {
+	if leaderData.getValide() {
+		leaderData.SetLeader(identity)
+		// we're notified when new leader elected
+		log.Infof("new leader elected: %s", identity)
+	}
+}
+
+func checkLeaderValid(ctx context.Context, elector Elector) { // server 启动后，确保设置稳定的 leaderData
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	var observedTime metav1.Time
+	var observedTime time.Time
 	for {
-		record, _, err := lock.Get(ctx)
+		record, err := elector.GetLease(ctx)
 		if err == nil {
 			observedTime = record.RenewTime
 			break
@@ -153,97 +249,42 @@ func checkLeaderValid(ctx context.Context, lock *resourcelock.LeaseLock) { // se
 
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case <-ticker.C:
-			record, _, err := lock.Get(ctx)
+			record, err := elector.GetLease(ctx)
 			if err != nil {
 				log.Error(err)
 				continue
 			}
-			if !record.RenewTime.Equal(&observedTime) { // ticker 时间需要小于 leaderelection.LeaderElectionConfig.RenewDeadline 设置
+			if !record.RenewTime.Equal(observedTime) { // ticker 时间需要小于 election-renew-deadline 设置
 				acquireTime = record.AcquireTime.Unix()
 				leaderData.setValide()
 				leaderData.SetLeader(record.HolderIdentity)
 				log.Infof("check leader finish, leader is %s", record.HolderIdentity)
 				return
 			} else {
-				log.Warningf("leader(%v) validity has expired", record)
+				log.Warningf("leader(%+v) validity has expired", record)
 			}
 		}
 	}
 }
 
 func Start(ctx context.Context, cfg *config.ControllerConfig) {
-	kubeconfig := cfg.Kubeconfig
-	electionName := cfg.ElectionName
-	electionNamespace := common.GetNameSpace()
 	id := getID()
-	log.Infof("election id is %s", id)
-	// leader election uses the Kubernetes API by writing to a
-	// lock object, which can be a LeaseLock object (preferred),
-	// a ConfigMap, or an Endpoints (deprecated) object.
-	// Conflicting writes are detected and each client handles those actions
-	// independently.
-	config, err := buildConfig(kubeconfig)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	client := clientset.NewForConfigOrDie(config)
-
-	lock := &resourcelock.LeaseLock{
-		LeaseMeta: metav1.ObjectMeta{
-			Name:      electionName,
-			Namespace: electionNamespace,
-		},
-		Client: client.CoordinationV1(),
-		LockConfig: resourcelock.ResourceLockConfig{
-			Identity: id,
-		},
-	}
-
-	go checkLeaderValid(ctx, lock)
-
-	// start the leader election code loop
-	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
-		Lock: lock,
-		// IMPORTANT: you MUST ensure that any code you have that
-		// is protected by the lease must terminate **before**
-		// you call cancel. Otherwise, you could have a background
-		// loop still running and another process could
-		// get elected before your background loop finished, violating
-		// the stated goal of the lease.
-		ReleaseOnCancel: true,
-		LeaseDuration:   15 * time.Second,
-		RenewDeadline:   10 * time.Second,
-		RetryPeriod:     2 * time.Second,
-		Callbacks: leaderelection.LeaderCallbacks{
-			OnStartedLeading: func(ctx context.Context) {
-				// we're notified when we start - this is where you would
-				// usually put your code
-				log.Infof("%s is the leader", id)
-				leaderData.SetLeader(id)
-			},
-			OnStoppedLeading: func() {
-				// we can do cleanup here
-				log.Infof("leader lost: %s", id)
-				leaderData.SetLeader(getCurrentLeader(ctx, lock))
-			},
-			OnNewLeader: func(identity string) {
-				if leaderData.getValide() {
-					leaderData.SetLeader(identity)
-					// we're notified when new leader elected
-					log.Infof("new leader elected: %s", identity)
-				}
-			},
-		},
-	})
+	log.Infof("election id is %s, election type is %s", id, cfg.ElectionType)
+	elector, err := newElector(cfg, id)
 	if err != nil {
 		log.Errorf("failed to create election: %v", err)
 		time.Sleep(1 * time.Second)
 		os.Exit(1)
 	}
+
+	setElector(elector)
+	go checkLeaderValid(ctx, elector)
+
 	wg := utils.GetWaitGroupInCtx(ctx)
 	wg.Add(1)
 	defer wg.Done()
-	wait.UntilWithContext(ctx, le.Run, 0)
+	elector.Run(ctx)
 }
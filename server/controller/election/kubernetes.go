@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package election
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/controller/config"
+)
+
+// kubernetesElector stores the lease in a Kubernetes Lease object
+type kubernetesElector struct {
+	id   string
+	lock *resourcelock.LeaseLock
+	le   *leaderelection.LeaderElector
+	ctx  context.Context
+}
+
+func buildConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func newKubernetesElector(cfg *config.ControllerConfig, id string, leaseDuration, renewDeadline, retryPeriod time.Duration) (*kubernetesElector, error) {
+	// leader election uses the Kubernetes API by writing to a
+	// lock object, which can be a LeaseLock object (preferred),
+	// a ConfigMap, or an Endpoints (deprecated) object.
+	// Conflicting writes are detected and each client handles those actions
+	// independently.
+	config, err := buildConfig(cfg.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	client := clientset.NewForConfigOrDie(config)
+
+	e := &kubernetesElector{
+		id: id,
+		lock: &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Name:      cfg.ElectionName,
+				Namespace: common.GetNameSpace(),
+			},
+			Client: client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: id,
+			},
+		},
+	}
+
+	// start the leader election code loop
+	e.le, err = leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: e.lock,
+		// IMPORTANT: you MUST ensure that any code you have that
+		// is protected by the lease must terminate **before**
+		// you call cancel. Otherwise, you could have a background
+		// loop still running and another process could
+		// get elected before your background loop finished, violating
+		// the stated goal of the lease.
+		ReleaseOnCancel: true,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				var token int64
+				if record, err := e.GetLease(ctx); err == nil {
+					token = record.FencingToken
+				} else {
+					log.Error(err)
+				}
+				onStartedLeading(id, token)
+			},
+			OnStoppedLeading: func() {
+				onStoppedLeading(e.ctx, id, e)
+			},
+			OnNewLeader: onNewLeader,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *kubernetesElector) Run(ctx context.Context) {
+	e.ctx = ctx
+	wait.UntilWithContext(ctx, e.le.Run, 0)
+}
+
+func (e *kubernetesElector) GetLease(ctx context.Context) (*LeaseRecord, error) {
+	record, _, err := e.lock.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &LeaseRecord{
+		HolderIdentity: record.HolderIdentity,
+		AcquireTime:    record.AcquireTime.Time,
+		RenewTime:      record.RenewTime.Time,
+		// leader transitions starts from 0 and increases when the holder changes
+		FencingToken: int64(record.LeaderTransitions) + 1,
+	}, nil
+}
+
+// Fence compares the token with the Kubernetes lease, which can not join the transaction, so a write may
+// still be committed if the lease is taken over after the comparison
+func (e *kubernetesElector) Fence(tx *gorm.DB, token int64) error {
+	record, err := e.GetLease(tx.Statement.Context)
+	if err != nil {
+		return err
+	}
+	if record.HolderIdentity != e.id || record.FencingToken != token {
+		return ErrNotLeader
+	}
+	return nil
+}
@@ -17,7 +17,9 @@
 package router
 
 import (
+	"fmt"
 	"io"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -53,6 +55,12 @@ func (cgc *AgentGroupConfig) RegisterTo(e *gin.Engine) {
 
 	e.DELETE("/v1/agent-group-configuration/:group-lcuuid", deleteAgentGroupConfig(cgc.cfg))
 
+	e.GET("/v1/agent-group-configuration/:group-lcuuid/revisions", getAgentGroupConfigRevisions(cgc.cfg))
+	e.GET("/v1/agent-group-configuration/:group-lcuuid/revisions/:revision/json", getJsonAgentGroupConfigRevision(cgc.cfg))
+	e.GET("/v1/agent-group-configuration/:group-lcuuid/revisions/:revision/yaml", getYAMLAgentGroupConfigRevision(cgc.cfg))
+	e.GET("/v1/agent-group-configuration/:group-lcuuid/diff", diffAgentGroupConfigRevisions(cgc.cfg))
+	e.POST("/v1/agent-group-configuration/:group-lcuuid/rollback", rollbackAgentGroupConfig(cgc.cfg))
+
 }
 
 func getYAMLAgentGroupConfigTmpl(c *gin.Context) {
@@ -89,7 +97,7 @@ func postJsonAgentGroupConfig(cfg *config.ControllerConfig) gin.HandlerFunc {
 			return
 		}
 		groupLcuuid := c.Param("group-lcuuid")
-		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).CreateAgentGroupConfig(groupLcuuid, postData, service.DataTypeJSON)
+		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).CreateAgentGroupConfig(groupLcuuid, postData, service.DataTypeJSON, c.Query("comment"))
 		routercommon.JsonResponse(c, data, err)
 	}
 }
@@ -102,7 +110,7 @@ func putJsonAgentGroupConfig(cfg *config.ControllerConfig) gin.HandlerFunc {
 			return
 		}
 		groupLcuuid := c.Param("group-lcuuid")
-		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).UpdateAgentGroupConfig(groupLcuuid, postData, service.DataTypeJSON)
+		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).UpdateAgentGroupConfig(groupLcuuid, postData, service.DataTypeJSON, c.Query("comment"))
 		routercommon.JsonResponse(c, data, err)
 	}
 }
@@ -124,7 +132,7 @@ func postYAMLAgentGroupConfig(cfg *config.ControllerConfig) gin.HandlerFunc {
 		}
 		groupLcuuid := c.Param("group-lcuuid")
 		body := map[string]interface{}{"data": string(bytes)}
-		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).CreateAgentGroupConfig(groupLcuuid, body, service.DataTypeYAML)
+		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).CreateAgentGroupConfig(groupLcuuid, body, service.DataTypeYAML, c.Query("comment"))
 		routercommon.JsonResponse(c, string(data), err)
 	}
 }
@@ -138,7 +146,7 @@ func putYAMLAgentGroupConfig(cfg *config.ControllerConfig) gin.HandlerFunc {
 		}
 		groupLcuuid := c.Param("group-lcuuid")
 		body := map[string]interface{}{"data": string(bytes)}
-		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).UpdateAgentGroupConfig(groupLcuuid, body, service.DataTypeYAML)
+		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).UpdateAgentGroupConfig(groupLcuuid, body, service.DataTypeYAML, c.Query("comment"))
 		routercommon.JsonResponse(c, string(data), err)
 	}
 }
@@ -150,3 +158,76 @@ func deleteAgentGroupConfig(cfg *config.ControllerConfig) gin.HandlerFunc {
 		routercommon.JsonResponse(c, nil, err)
 	}
 }
+
+func getAgentGroupConfigRevisions(cfg *config.ControllerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupLcuuid := c.Param("group-lcuuid")
+		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).GetAgentGroupConfigRevisions(groupLcuuid)
+		routercommon.JsonResponse(c, data, err)
+	}
+}
+
+func getJsonAgentGroupConfigRevision(cfg *config.ControllerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		revision, err := strconv.Atoi(c.Param("revision"))
+		if err != nil {
+			routercommon.BadRequestResponse(c, common.INVALID_PARAMETERS, fmt.Sprintf("invalid revision (%s)", c.Param("revision")))
+			return
+		}
+		groupLcuuid := c.Param("group-lcuuid")
+		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).GetAgentGroupConfigRevision(groupLcuuid, revision, service.DataTypeJSON)
+		routercommon.JsonResponse(c, data, err)
+	}
+}
+
+func getYAMLAgentGroupConfigRevision(cfg *config.ControllerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		revision, err := strconv.Atoi(c.Param("revision"))
+		if err != nil {
+			routercommon.BadRequestResponse(c, common.INVALID_PARAMETERS, fmt.Sprintf("invalid revision (%s)", c.Param("revision")))
+			return
+		}
+		groupLcuuid := c.Param("group-lcuuid")
+		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).GetAgentGroupConfigRevision(groupLcuuid, revision, service.DataTypeYAML)
+		routercommon.JsonResponse(c, string(data), err)
+	}
+}
+
+// diffAgentGroupConfigRevisions compares the revisions specified by 'from' and 'to', the latest revision is used if 'to' is not specified
+func diffAgentGroupConfigRevisions(cfg *config.ControllerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fromRevision, err := strconv.Atoi(c.Query("from"))
+		if err != nil {
+			routercommon.BadRequestResponse(c, common.INVALID_PARAMETERS, fmt.Sprintf("invalid from revision (%s)", c.Query("from")))
+			return
+		}
+		toRevision := 0
+		if value, ok := c.GetQuery("to"); ok {
+			if toRevision, err = strconv.Atoi(value); err != nil {
+				routercommon.BadRequestResponse(c, common.INVALID_PARAMETERS, fmt.Sprintf("invalid to revision (%s)", value))
+				return
+			}
+		}
+		groupLcuuid := c.Param("group-lcuuid")
+		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).DiffAgentGroupConfigRevisions(groupLcuuid, fromRevision, toRevision)
+		routercommon.JsonResponse(c, data, err)
+	}
+}
+
+type agentGroupConfigRollback struct {
+	Revision int    `json:"REVISION" binding:"required"`
+	Comment  string `json:"COMMENT"`
+}
+
+func rollbackAgentGroupConfig(cfg *config.ControllerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var postData agentGroupConfigRollback
+		if err := c.ShouldBindJSON(&postData); err != nil {
+			routercommon.BadRequestResponse(c, common.INVALID_POST_DATA, err.Error())
+			return
+		}
+		groupLcuuid := c.Param("group-lcuuid")
+		data, err := service.NewAgentGroupConfig(common.GetUserInfo(c), cfg).RollbackAgentGroupConfig(groupLcuuid, postData.Revision, postData.Comment, service.DataTypeYAML)
+		routercommon.JsonResponse(c, string(data), err)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	httpcommon "github.com/khulnasoft/deepflow/server/controller/http/common"
+	. "github.com/khulnasoft/deepflow/server/controller/http/router/common"
+	"github.com/khulnasoft/deepflow/server/controller/http/service"
+	"github.com/khulnasoft/deepflow/server/controller/model"
+)
+
+type AgentUpgradeRollout struct{}
+
+func NewAgentUpgradeRollout() *AgentUpgradeRollout {
+	return new(AgentUpgradeRollout)
+}
+
+func (a *AgentUpgradeRollout) RegisterTo(e *gin.Engine) {
+	e.GET("/v1/agent-upgrade-rollouts/", getAgentUpgradeRollouts)
+	e.GET("/v1/agent-upgrade-rollouts/:lcuuid/", getAgentUpgradeRollout)
+	e.POST("/v1/agent-upgrade-rollouts/", createAgentUpgradeRollout)
+	e.POST("/v1/agent-upgrade-rollouts/:lcuuid/pause/", pauseAgentUpgradeRollout)
+	e.POST("/v1/agent-upgrade-rollouts/:lcuuid/resume/", resumeAgentUpgradeRollout)
+	e.POST("/v1/agent-upgrade-rollouts/:lcuuid/cancel/", cancelAgentUpgradeRollout)
+	e.POST("/v1/agent-upgrade-rollouts/:lcuuid/rollback/", rollbackAgentUpgradeRollout)
+}
+
+func getAgentUpgradeRollouts(c *gin.Context) {
+	args := make(map[string]interface{})
+	if value, ok := c.GetQuery("agent_group_lcuuid"); ok {
+		args["agent_group_lcuuid"] = value
+	}
+	if value, ok := c.GetQuery("state"); ok {
+		args["state"] = value
+	}
+	data, err := service.GetAgentUpgradeRollouts(httpcommon.GetUserInfo(c).ORGID, args)
+	JsonResponse(c, data, err)
+}
+
+func getAgentUpgradeRollout(c *gin.Context) {
+	data, err := service.GetAgentUpgradeRollout(httpcommon.GetUserInfo(c).ORGID, c.Param("lcuuid"))
+	JsonResponse(c, data, err)
+}
+
+func createAgentUpgradeRollout(c *gin.Context) {
+	var rolloutCreate model.AgentUpgradeRolloutCreate
+	if err := c.ShouldBindBodyWith(&rolloutCreate, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.CreateAgentUpgradeRollout(httpcommon.GetUserInfo(c), rolloutCreate)
+	JsonResponse(c, data, err)
+}
+
+func pauseAgentUpgradeRollout(c *gin.Context) {
+	data, err := service.PauseAgentUpgradeRollout(httpcommon.GetUserInfo(c).ORGID, c.Param("lcuuid"))
+	JsonResponse(c, data, err)
+}
+
+func resumeAgentUpgradeRollout(c *gin.Context) {
+	var rolloutUpdate model.AgentUpgradeRolloutUpdate
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindBodyWith(&rolloutUpdate, binding.JSON); err != nil {
+			BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+			return
+		}
+	}
+	data, err := service.ResumeAgentUpgradeRollout(httpcommon.GetUserInfo(c).ORGID, c.Param("lcuuid"), rolloutUpdate)
+	JsonResponse(c, data, err)
+}
+
+func cancelAgentUpgradeRollout(c *gin.Context) {
+	data, err := service.CancelAgentUpgradeRollout(httpcommon.GetUserInfo(c).ORGID, c.Param("lcuuid"))
+	JsonResponse(c, data, err)
+}
+
+func rollbackAgentUpgradeRollout(c *gin.Context) {
+	var rolloutUpdate model.AgentUpgradeRolloutUpdate
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindBodyWith(&rolloutUpdate, binding.JSON); err != nil {
+			BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+			return
+		}
+	}
+	data, err := service.RollbackAgentUpgradeRollout(httpcommon.GetUserInfo(c).ORGID, c.Param("lcuuid"), rolloutUpdate)
+	JsonResponse(c, data, err)
+}
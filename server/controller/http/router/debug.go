@@ -31,6 +31,7 @@ import (
 	"github.com/khulnasoft/deepflow/server/controller/http/service"
 	"github.com/khulnasoft/deepflow/server/controller/manager"
 	"github.com/khulnasoft/deepflow/server/controller/model"
+	"github.com/khulnasoft/deepflow/server/controller/recorder/notifier"
 )
 
 type Debug struct {
@@ -62,6 +63,13 @@ func (d *Debug) RegisterTo(e *gin.Engine) {
 	e.GET("/v1/recorders/:domainLcuuid/:subDomainLcuuid/cache/diff-bases/:resourceType/", getRecorderDiffBaseDataSetByResourceType(d.m))
 	e.GET("/v1/recorders/:domainLcuuid/:subDomainLcuuid/cache/diff-bases/:resourceType/:resourceLcuuid/", getRecorderDiffBase(d.m))
 	e.GET("/v1/recorders/:domainLcuuid/:subDomainLcuuid/cache/tool-maps/:field/", getRecorderCacheToolMap(d.m))
+	e.GET("/v1/recorder-notifier/delivery-status/", getRecorderNotifierDeliveryStatus)
+}
+
+// delivery status of the targets of this controller, filtered by target name if query param name is set
+func getRecorderNotifierDeliveryStatus(c *gin.Context) {
+	data := notifier.GetNotifier().GetDeliveryStatus(c.Query("name"))
+	JsonResponse(c, data, nil)
 }
 
 func getCloudBasicInfo(m *manager.Manager) gin.HandlerFunc {
@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	httpcommon "github.com/khulnasoft/deepflow/server/controller/http/common"
+	. "github.com/khulnasoft/deepflow/server/controller/http/router/common"
+	"github.com/khulnasoft/deepflow/server/controller/http/service"
+)
+
+type PrometheusCardinality struct{}
+
+func NewPrometheusCardinality() *PrometheusCardinality {
+	return new(PrometheusCardinality)
+}
+
+func (p *PrometheusCardinality) RegisterTo(e *gin.Engine) {
+	e.GET("/v1/prometheus-cardinality/", getPrometheusCardinality)
+}
+
+func getPrometheusCardinality(c *gin.Context) {
+	limit := 0
+	if value, ok := c.GetQuery("limit"); ok {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, "limit must be a non-negative integer")
+			return
+		}
+	}
+	data, err := service.GetPrometheusCardinality(httpcommon.GetUserInfo(c).ORGID, limit)
+	JsonResponse(c, data, err)
+}
@@ -111,8 +111,10 @@ func (s *Server) appendRegistrant() []registrant.Registrant {
 		router.NewVTapGroupConfig(s.controllerConfig),
 		router.NewVTapInterface(s.controllerConfig.FPermit),
 		router.NewVtapRepo(),
+		router.NewAgentUpgradeRollout(),
 		router.NewPlugin(),
 		router.NewMail(),
+		router.NewPrometheusCardinality(),
 		router.NewDatabase(s.controllerConfig),
 		router.NewAgentCMD(s.controllerConfig),
 		router.NewAgentGroupConfig(s.controllerConfig),
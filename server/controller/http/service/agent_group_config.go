@@ -217,7 +217,7 @@ func (a *AgentGroupConfig) GetAgentGroupConfigs() ([]byte, error) {
 	return jsonArray.Bytes(), nil
 }
 
-func (a *AgentGroupConfig) CreateAgentGroupConfig(groupLcuuid string, data map[string]interface{}, dataType int) ([]byte, error) {
+func (a *AgentGroupConfig) CreateAgentGroupConfig(groupLcuuid string, data map[string]interface{}, dataType int, comment string) ([]byte, error) {
 	dbInfo, err := mysql.GetDB(a.resourceAccess.UserInfo.ORGID)
 	if err != nil {
 		return nil, err
@@ -238,32 +238,40 @@ func (a *AgentGroupConfig) CreateAgentGroupConfig(groupLcuuid string, data map[s
 		strYaml = data["data"].(string)
 	}
 
-	var agentGroupConfig agentconf.MySQLAgentGroupConfiguration
-	if err := dbInfo.Where("agent_group_lcuuid = ?", groupLcuuid).First(&agentGroupConfig).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	err = dbInfo.Transaction(func(tx *gorm.DB) error {
+		if err := lockAgentGroupConfig(tx, groupLcuuid); err != nil {
+			return err
+		}
+		var agentGroupConfig agentconf.MySQLAgentGroupConfiguration
+		if err := tx.Where("agent_group_lcuuid = ?", groupLcuuid).First(&agentGroupConfig).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
 			newConfig := &agentconf.MySQLAgentGroupConfiguration{
 				Lcuuid:           uuid.New().String(),
 				AgentGroupLcuuid: groupLcuuid,
 				Yaml:             strYaml,
 			}
-			if err := dbInfo.Create(newConfig).Error; err != nil {
-				return nil, err
+			if err := tx.Create(newConfig).Error; err != nil {
+				return err
 			}
-
-			return a.GetAgentGroupConfig(groupLcuuid, dataType)
+			return a.createAgentGroupConfigRevision(tx, groupLcuuid, strYaml, comment)
 		}
-		return nil, err
-	}
 
-	// TODO(weiqiang): duplicate and verify
-	agentGroupConfig.Yaml = strYaml
-	if err := dbInfo.Save(&agentGroupConfig).Error; err != nil {
+		// TODO(weiqiang): duplicate and verify
+		agentGroupConfig.Yaml = strYaml
+		if err := tx.Save(&agentGroupConfig).Error; err != nil {
+			return err
+		}
+		return a.createAgentGroupConfigRevision(tx, groupLcuuid, strYaml, comment)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return a.GetAgentGroupConfig(groupLcuuid, dataType)
 }
 
-func (a *AgentGroupConfig) UpdateAgentGroupConfig(groupLcuuid string, data map[string]interface{}, dataType int) ([]byte, error) {
+func (a *AgentGroupConfig) UpdateAgentGroupConfig(groupLcuuid string, data map[string]interface{}, dataType int, comment string) ([]byte, error) {
 	dbInfo, err := mysql.GetDB(a.resourceAccess.UserInfo.ORGID)
 	if err != nil {
 		return nil, err
@@ -284,12 +292,21 @@ func (a *AgentGroupConfig) UpdateAgentGroupConfig(groupLcuuid string, data map[s
 		strYaml = data["data"].(string)
 	}
 
-	var agentGroupConfig agentconf.MySQLAgentGroupConfiguration
-	if err := dbInfo.Where("agent_group_lcuuid = ?", groupLcuuid).First(&agentGroupConfig).Error; err != nil {
-		return nil, err
-	}
-	agentGroupConfig.Yaml = strYaml
-	if err := dbInfo.Save(&agentGroupConfig).Error; err != nil {
+	err = dbInfo.Transaction(func(tx *gorm.DB) error {
+		if err := lockAgentGroupConfig(tx, groupLcuuid); err != nil {
+			return err
+		}
+		var agentGroupConfig agentconf.MySQLAgentGroupConfiguration
+		if err := tx.Where("agent_group_lcuuid = ?", groupLcuuid).First(&agentGroupConfig).Error; err != nil {
+			return err
+		}
+		agentGroupConfig.Yaml = strYaml
+		if err := tx.Save(&agentGroupConfig).Error; err != nil {
+			return err
+		}
+		return a.createAgentGroupConfigRevision(tx, groupLcuuid, strYaml, comment)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return a.GetAgentGroupConfig(groupLcuuid, dataType)
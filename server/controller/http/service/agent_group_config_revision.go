@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	agentconf "github.com/khulnasoft/deepflow/server/agent_config"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
+	httpcommon "github.com/khulnasoft/deepflow/server/controller/http/common"
+	. "github.com/khulnasoft/deepflow/server/controller/http/service/common"
+)
+
+const AGENT_GROUP_CONFIG_REVISION_COMMENT_MAX_LENGTH = 512
+
+// lockAgentGroupConfig locks the agent group until the transaction ends, so that the configuration and its revisions
+// of an agent group are saved by one transaction at a time. The agent group is locked rather than the configuration,
+// which may not exist yet. It must be the first query of the transaction, otherwise the latest revision may be hidden
+// by the snapshot of the earlier query in mysql.
+func lockAgentGroupConfig(tx *gorm.DB, groupLcuuid string) error {
+	var agentGroup model.VTapGroup
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("lcuuid = ?", groupLcuuid).First(&agentGroup).Error
+}
+
+// createAgentGroupConfigRevision saves the yaml as the next revision of the agent group configuration,
+// it should be called in the transaction which saves the configuration after lockAgentGroupConfig
+func (a *AgentGroupConfig) createAgentGroupConfigRevision(tx *gorm.DB, groupLcuuid, strYaml, comment string) error {
+	if len(comment) > AGENT_GROUP_CONFIG_REVISION_COMMENT_MAX_LENGTH {
+		return NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("comment is longer than %d", AGENT_GROUP_CONFIG_REVISION_COMMENT_MAX_LENGTH))
+	}
+	var maxRevision int
+	if err := tx.Model(&agentconf.MySQLAgentGroupConfigurationRevision{}).
+		Where("agent_group_lcuuid = ?", groupLcuuid).
+		Select("COALESCE(MAX(revision), 0)").Scan(&maxRevision).Error; err != nil {
+		return err
+	}
+	return tx.Create(&agentconf.MySQLAgentGroupConfigurationRevision{
+		AgentGroupLcuuid: groupLcuuid,
+		Revision:         maxRevision + 1,
+		Yaml:             strYaml,
+		UserID:           a.resourceAccess.UserInfo.ID,
+		Comment:          comment,
+	}).Error
+}
+
+func getAgentGroupConfigRevision(db *gorm.DB, groupLcuuid string, revision int) (*agentconf.MySQLAgentGroupConfigurationRevision, error) {
+	var data agentconf.MySQLAgentGroupConfigurationRevision
+	query := db.Where("agent_group_lcuuid = ?", groupLcuuid)
+	// 0 means the latest revision
+	if revision > 0 {
+		query = query.Where("revision = ?", revision)
+	} else {
+		query = query.Order("revision DESC")
+	}
+	if err := query.First(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("agent group (%s) config revision (%d) not found", groupLcuuid, revision))
+		}
+		return nil, err
+	}
+	return &data, nil
+}
+
+// GetAgentGroupConfigRevisions returns the revisions without yaml, the latest revision is the first
+func (a *AgentGroupConfig) GetAgentGroupConfigRevisions(groupLcuuid string) ([]agentconf.MySQLAgentGroupConfigurationRevision, error) {
+	dbInfo, err := mysql.GetDB(a.resourceAccess.UserInfo.ORGID)
+	if err != nil {
+		return nil, err
+	}
+	var data []agentconf.MySQLAgentGroupConfigurationRevision
+	if err := dbInfo.Select("id", "agent_group_lcuuid", "revision", "user_id", "comment", "created_at").
+		Where("agent_group_lcuuid = ?", groupLcuuid).Order("revision DESC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *AgentGroupConfig) GetAgentGroupConfigRevision(groupLcuuid string, revision int, dataType int) ([]byte, error) {
+	dbInfo, err := mysql.GetDB(a.resourceAccess.UserInfo.ORGID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := getAgentGroupConfigRevision(dbInfo.DB, groupLcuuid, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	if dataType == DataTypeJSON {
+		if data.Yaml == "" {
+			return []byte("{}"), nil
+		}
+		return agentconf.ParseYAMLToJson([]byte(data.Yaml), nil)
+	} else {
+		return []byte(data.Yaml), nil
+	}
+}
+
+// DiffAgentGroupConfigRevisions compares the yaml of two revisions, 0 means the latest revision
+func (a *AgentGroupConfig) DiffAgentGroupConfigRevisions(groupLcuuid string, fromRevision, toRevision int) ([]agentconf.YAMLDiff, error) {
+	dbInfo, err := mysql.GetDB(a.resourceAccess.UserInfo.ORGID)
+	if err != nil {
+		return nil, err
+	}
+	from, err := getAgentGroupConfigRevision(dbInfo.DB, groupLcuuid, fromRevision)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getAgentGroupConfigRevision(dbInfo.DB, groupLcuuid, toRevision)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := agentconf.DiffYAML([]byte(from.Yaml), []byte(to.Yaml))
+	if err != nil {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+	}
+	return diffs, nil
+}
+
+// RollbackAgentGroupConfig sets the configuration to the yaml of the revision, and saves it as a new revision
+func (a *AgentGroupConfig) RollbackAgentGroupConfig(groupLcuuid string, revision int, comment string, dataType int) ([]byte, error) {
+	if revision <= 0 {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("invalid revision (%d)", revision))
+	}
+	dbInfo, err := mysql.GetDB(a.resourceAccess.UserInfo.ORGID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := getAgentGroupConfigRevision(dbInfo.DB, groupLcuuid, revision)
+	if err != nil {
+		return nil, err
+	}
+	if comment == "" {
+		comment = fmt.Sprintf("rollback to revision %d", revision)
+	}
+
+	err = dbInfo.Transaction(func(tx *gorm.DB) error {
+		if err := lockAgentGroupConfig(tx, groupLcuuid); err != nil {
+			return err
+		}
+		var agentGroupConfig agentconf.MySQLAgentGroupConfiguration
+		if err := tx.Where("agent_group_lcuuid = ?", groupLcuuid).First(&agentGroupConfig).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			// the configuration has been deleted, recreate it
+			agentGroupConfig.Lcuuid = uuid.New().String()
+			agentGroupConfig.AgentGroupLcuuid = groupLcuuid
+		}
+		agentGroupConfig.Yaml = data.Yaml
+		if err := tx.Save(&agentGroupConfig).Error; err != nil {
+			return err
+		}
+		return a.createAgentGroupConfigRevision(tx, groupLcuuid, data.Yaml, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.GetAgentGroupConfig(groupLcuuid, dataType)
+}
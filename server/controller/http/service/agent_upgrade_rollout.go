@@ -0,0 +1,517 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql"
+	mysqlmodel "github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
+	httpcommon "github.com/khulnasoft/deepflow/server/controller/http/common"
+	. "github.com/khulnasoft/deepflow/server/controller/http/service/common"
+	"github.com/khulnasoft/deepflow/server/controller/model"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+const (
+	AGENT_UPGRADE_ROLLOUT_DEFAULT_HEALTH_TIMEOUT = 600 // unit: s
+)
+
+var activeAgentUpgradeRolloutStates = []string{
+	common.AGENT_UPGRADE_ROLLOUT_STATE_RUNNING,
+	common.AGENT_UPGRADE_ROLLOUT_STATE_PAUSED,
+	common.AGENT_UPGRADE_ROLLOUT_STATE_ROLLING_BACK,
+}
+
+// GetAgentUpgradeRolloutWaveSize returns the number of agents upgraded in each wave
+func GetAgentUpgradeRolloutWaveSize(agentCount int, waveType string, waveSize int) int {
+	if waveType == common.AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_PERCENTAGE {
+		waveSize = (agentCount*waveSize + 99) / 100
+	}
+	if waveSize < 1 {
+		waveSize = 1
+	}
+	return waveSize
+}
+
+func getAgentUpgradeRolloutExpectedRevision(db *gorm.DB, imageName string) (string, error) {
+	var vtapRepo mysqlmodel.VTapRepo
+	if err := db.Select("name", "rev_count", "commit_id").Where("name = ?", imageName).First(&vtapRepo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("agent image (%s) not found", imageName))
+		}
+		return "", err
+	}
+	if vtapRepo.RevCount == "" || vtapRepo.CommitID == "" {
+		return "", NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("agent image (%s) has no revision", imageName))
+	}
+	return vtapRepo.RevCount + "-" + vtapRepo.CommitID, nil
+}
+
+func CreateAgentUpgradeRollout(userInfo *httpcommon.UserInfo, rolloutCreate model.AgentUpgradeRolloutCreate) (*model.AgentUpgradeRollout, error) {
+	dbInfo, err := mysql.GetDB(userInfo.ORGID)
+	if err != nil {
+		return nil, err
+	}
+	db := dbInfo.DB
+
+	if rolloutCreate.WaveType == "" {
+		rolloutCreate.WaveType = common.AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_COUNT
+	}
+	if rolloutCreate.FailureAction == "" {
+		rolloutCreate.FailureAction = common.AGENT_UPGRADE_ROLLOUT_FAILURE_ACTION_PAUSE
+	}
+	if rolloutCreate.HealthTimeout == 0 {
+		rolloutCreate.HealthTimeout = AGENT_UPGRADE_ROLLOUT_DEFAULT_HEALTH_TIMEOUT
+	}
+	switch rolloutCreate.WaveType {
+	case common.AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_COUNT:
+		if rolloutCreate.WaveSize <= 0 {
+			return nil, NewError(httpcommon.INVALID_PARAMETERS, "WAVE_SIZE must be greater than 0")
+		}
+	case common.AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_PERCENTAGE:
+		if rolloutCreate.WaveSize <= 0 || rolloutCreate.WaveSize > 100 {
+			return nil, NewError(httpcommon.INVALID_PARAMETERS, "WAVE_SIZE must be in (0, 100] when WAVE_TYPE is PERCENTAGE")
+		}
+	default:
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("invalid WAVE_TYPE (%s)", rolloutCreate.WaveType))
+	}
+	if rolloutCreate.WaveInterval < 0 || rolloutCreate.HealthTimeout < 0 || rolloutCreate.FailureThreshold < 0 {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, "WAVE_INTERVAL, HEALTH_TIMEOUT and FAILURE_THRESHOLD can not be negative")
+	}
+	switch rolloutCreate.FailureAction {
+	case common.AGENT_UPGRADE_ROLLOUT_FAILURE_ACTION_PAUSE:
+	case common.AGENT_UPGRADE_ROLLOUT_FAILURE_ACTION_ROLLBACK:
+		if rolloutCreate.RollbackImageName == "" {
+			return nil, NewError(httpcommon.INVALID_PARAMETERS, "ROLLBACK_IMAGE_NAME is required when FAILURE_ACTION is ROLLBACK")
+		}
+	default:
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("invalid FAILURE_ACTION (%s)", rolloutCreate.FailureAction))
+	}
+
+	var vtapGroup mysqlmodel.VTapGroup
+	if err := db.Where("lcuuid = ?", rolloutCreate.AgentGroupLcuuid).First(&vtapGroup).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("agent group (%s) not found", rolloutCreate.AgentGroupLcuuid))
+		}
+		return nil, err
+	}
+	expectedRevision, err := getAgentUpgradeRolloutExpectedRevision(db, rolloutCreate.ImageName)
+	if err != nil {
+		return nil, err
+	}
+	if rolloutCreate.RollbackImageName != "" {
+		if _, err := getAgentUpgradeRolloutExpectedRevision(db, rolloutCreate.RollbackImageName); err != nil {
+			return nil, err
+		}
+	}
+
+	var activeCount int64
+	if err := db.Model(&mysqlmodel.AgentUpgradeRollout{}).
+		Where("agent_group_lcuuid = ? AND state IN (?)", rolloutCreate.AgentGroupLcuuid, activeAgentUpgradeRolloutStates).
+		Count(&activeCount).Error; err != nil {
+		return nil, err
+	}
+	if activeCount > 0 {
+		return nil, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("agent group (%s) already has an unfinished upgrade rollout", vtapGroup.Name))
+	}
+
+	var vtaps []mysqlmodel.VTap
+	if err := db.Where("vtap_group_lcuuid = ?", rolloutCreate.AgentGroupLcuuid).Order("id").Find(&vtaps).Error; err != nil {
+		return nil, err
+	}
+	rollout := mysqlmodel.AgentUpgradeRollout{
+		Lcuuid:            uuid.New().String(),
+		Name:              rolloutCreate.Name,
+		AgentGroupLcuuid:  rolloutCreate.AgentGroupLcuuid,
+		ImageName:         rolloutCreate.ImageName,
+		ExpectedRevision:  expectedRevision,
+		RollbackImageName: rolloutCreate.RollbackImageName,
+		WaveType:          rolloutCreate.WaveType,
+		WaveSize:          rolloutCreate.WaveSize,
+		WaveInterval:      rolloutCreate.WaveInterval,
+		HealthTimeout:     rolloutCreate.HealthTimeout,
+		FailureThreshold:  rolloutCreate.FailureThreshold,
+		FailureAction:     rolloutCreate.FailureAction,
+		State:             common.AGENT_UPGRADE_ROLLOUT_STATE_RUNNING,
+		UserID:            userInfo.ID,
+	}
+	if rollout.Name == "" {
+		rollout.Name = fmt.Sprintf("%s-%s", vtapGroup.Name, rolloutCreate.ImageName)
+	}
+
+	var rolloutAgents, upgradeAgents []*mysqlmodel.AgentUpgradeRolloutAgent
+	for _, vtap := range vtaps {
+		rolloutAgent := &mysqlmodel.AgentUpgradeRolloutAgent{
+			RolloutLcuuid:      rollout.Lcuuid,
+			AgentLcuuid:        vtap.Lcuuid,
+			AgentName:          vtap.Name,
+			State:              common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_PENDING,
+			OriginalRevision:   common.GetRealRevision(vtap.Revision),
+			OriginalExceptions: vtap.Exceptions,
+		}
+		if rolloutAgent.OriginalRevision == expectedRevision {
+			rolloutAgent.State = common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_SKIPPED
+			rolloutAgent.Message = "agent is already running the expected revision"
+		} else if vtap.Enable == common.VTAP_ENABLE_FALSE {
+			rolloutAgent.State = common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_SKIPPED
+			rolloutAgent.Message = "agent is disabled"
+		} else {
+			upgradeAgents = append(upgradeAgents, rolloutAgent)
+		}
+		rolloutAgents = append(rolloutAgents, rolloutAgent)
+	}
+	if len(upgradeAgents) == 0 {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("no agent in agent group (%s) needs to be upgraded", vtapGroup.Name))
+	}
+	waveSize := GetAgentUpgradeRolloutWaveSize(len(upgradeAgents), rollout.WaveType, rollout.WaveSize)
+	for i, rolloutAgent := range upgradeAgents {
+		rolloutAgent.Wave = i/waveSize + 1
+	}
+	rollout.WaveCount = (len(upgradeAgents) + waveSize - 1) / waveSize
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rollout).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(rolloutAgents, 100).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("create agent upgrade rollout (%s) of agent group (%s) to image (%s), %d agents in %d waves",
+		rollout.Name, vtapGroup.Name, rollout.ImageName, len(upgradeAgents), rollout.WaveCount, dbInfo.LogPrefixORGID)
+	return GetAgentUpgradeRollout(userInfo.ORGID, rollout.Lcuuid)
+}
+
+func GetAgentUpgradeRollouts(orgID int, filter map[string]interface{}) ([]model.AgentUpgradeRollout, error) {
+	dbInfo, err := mysql.GetDB(orgID)
+	if err != nil {
+		return nil, err
+	}
+	db := dbInfo.DB
+	if _, ok := filter["agent_group_lcuuid"]; ok {
+		db = db.Where("agent_group_lcuuid = ?", filter["agent_group_lcuuid"])
+	}
+	if _, ok := filter["state"]; ok {
+		db = db.Where("state = ?", filter["state"])
+	}
+	var rollouts []mysqlmodel.AgentUpgradeRollout
+	if err := db.Order("id DESC").Find(&rollouts).Error; err != nil {
+		return nil, err
+	}
+	if len(rollouts) == 0 {
+		return []model.AgentUpgradeRollout{}, nil
+	}
+
+	rolloutLcuuids := make([]string, 0, len(rollouts))
+	for _, rollout := range rollouts {
+		rolloutLcuuids = append(rolloutLcuuids, rollout.Lcuuid)
+	}
+	var rolloutAgents []mysqlmodel.AgentUpgradeRolloutAgent
+	if err := dbInfo.Select("rollout_lcuuid", "state").Where("rollout_lcuuid IN (?)", rolloutLcuuids).
+		Find(&rolloutAgents).Error; err != nil {
+		return nil, err
+	}
+	rolloutLcuuidToStateCount := make(map[string]map[string]int)
+	for _, rolloutAgent := range rolloutAgents {
+		if _, ok := rolloutLcuuidToStateCount[rolloutAgent.RolloutLcuuid]; !ok {
+			rolloutLcuuidToStateCount[rolloutAgent.RolloutLcuuid] = make(map[string]int)
+		}
+		rolloutLcuuidToStateCount[rolloutAgent.RolloutLcuuid][rolloutAgent.State]++
+	}
+	vtapGroupLcuuidToName, err := getVTapGroupLcuuidToName(dbInfo.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]model.AgentUpgradeRollout, 0, len(rollouts))
+	for _, rollout := range rollouts {
+		rolloutResp := convertAgentUpgradeRollout(rollout)
+		rolloutResp.AgentGroupName = vtapGroupLcuuidToName[rollout.AgentGroupLcuuid]
+		if stateCount, ok := rolloutLcuuidToStateCount[rollout.Lcuuid]; ok {
+			rolloutResp.AgentStateCount = stateCount
+		}
+		for _, count := range rolloutResp.AgentStateCount {
+			rolloutResp.AgentCount += count
+		}
+		resp = append(resp, rolloutResp)
+	}
+	return resp, nil
+}
+
+// GetAgentUpgradeRollout returns the rollout with the upgrade state of each agent
+func GetAgentUpgradeRollout(orgID int, lcuuid string) (*model.AgentUpgradeRollout, error) {
+	dbInfo, err := mysql.GetDB(orgID)
+	if err != nil {
+		return nil, err
+	}
+	rollout, err := getAgentUpgradeRollout(dbInfo.DB, lcuuid)
+	if err != nil {
+		return nil, err
+	}
+	var rolloutAgents []mysqlmodel.AgentUpgradeRolloutAgent
+	if err := dbInfo.Where("rollout_lcuuid = ?", lcuuid).Order("wave, id").Find(&rolloutAgents).Error; err != nil {
+		return nil, err
+	}
+	agentLcuuids := make([]string, 0, len(rolloutAgents))
+	for _, rolloutAgent := range rolloutAgents {
+		agentLcuuids = append(agentLcuuids, rolloutAgent.AgentLcuuid)
+	}
+	var vtaps []mysqlmodel.VTap
+	if err := dbInfo.Select("lcuuid", "revision").Where("lcuuid IN (?)", agentLcuuids).Find(&vtaps).Error; err != nil {
+		return nil, err
+	}
+	vtapLcuuidToRevision := make(map[string]string, len(vtaps))
+	for _, vtap := range vtaps {
+		vtapLcuuidToRevision[vtap.Lcuuid] = common.GetRealRevision(vtap.Revision)
+	}
+	vtapGroupLcuuidToName, err := getVTapGroupLcuuidToName(dbInfo.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := convertAgentUpgradeRollout(*rollout)
+	resp.AgentGroupName = vtapGroupLcuuidToName[rollout.AgentGroupLcuuid]
+	resp.AgentCount = len(rolloutAgents)
+	resp.Agents = make([]model.AgentUpgradeRolloutAgent, 0, len(rolloutAgents))
+	for _, rolloutAgent := range rolloutAgents {
+		resp.AgentStateCount[rolloutAgent.State]++
+		agentResp := model.AgentUpgradeRolloutAgent{
+			AgentLcuuid:      rolloutAgent.AgentLcuuid,
+			AgentName:        rolloutAgent.AgentName,
+			Wave:             rolloutAgent.Wave,
+			State:            rolloutAgent.State,
+			OriginalRevision: rolloutAgent.OriginalRevision,
+			Revision:         vtapLcuuidToRevision[rolloutAgent.AgentLcuuid],
+			Message:          rolloutAgent.Message,
+		}
+		if rolloutAgent.StartedAt != nil {
+			agentResp.StartedAt = rolloutAgent.StartedAt.Format(common.GO_BIRTHDAY)
+		}
+		if rolloutAgent.FinishedAt != nil {
+			agentResp.FinishedAt = rolloutAgent.FinishedAt.Format(common.GO_BIRTHDAY)
+		}
+		resp.Agents = append(resp.Agents, agentResp)
+	}
+	sort.SliceStable(resp.Agents, func(i, j int) bool {
+		// skipped agents have no wave, show them at last
+		if (resp.Agents[i].Wave == 0) != (resp.Agents[j].Wave == 0) {
+			return resp.Agents[j].Wave == 0
+		}
+		return resp.Agents[i].Wave < resp.Agents[j].Wave
+	})
+	return &resp, nil
+}
+
+// PauseAgentUpgradeRollout stops starting new waves, the agents being upgraded are still checked until they finish
+func PauseAgentUpgradeRollout(orgID int, lcuuid string) (*model.AgentUpgradeRollout, error) {
+	err := updateAgentUpgradeRolloutState(
+		orgID, lcuuid, []string{common.AGENT_UPGRADE_ROLLOUT_STATE_RUNNING},
+		map[string]interface{}{"state": common.AGENT_UPGRADE_ROLLOUT_STATE_PAUSED, "message": "paused by user"}, nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return GetAgentUpgradeRollout(orgID, lcuuid)
+}
+
+func ResumeAgentUpgradeRollout(orgID int, lcuuid string, rolloutUpdate model.AgentUpgradeRolloutUpdate) (*model.AgentUpgradeRollout, error) {
+	dbInfo, err := mysql.GetDB(orgID)
+	if err != nil {
+		return nil, err
+	}
+	rollout, err := getAgentUpgradeRollout(dbInfo.DB, lcuuid)
+	if err != nil {
+		return nil, err
+	}
+	failureThreshold := rollout.FailureThreshold
+	if rolloutUpdate.FailureThreshold != nil {
+		if *rolloutUpdate.FailureThreshold < 0 {
+			return nil, NewError(httpcommon.INVALID_PARAMETERS, "FAILURE_THRESHOLD can not be negative")
+		}
+		failureThreshold = *rolloutUpdate.FailureThreshold
+	}
+	var failedCount int64
+	if err := dbInfo.Model(&mysqlmodel.AgentUpgradeRolloutAgent{}).
+		Where("rollout_lcuuid = ? AND state = ?", lcuuid, common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_FAILED).
+		Count(&failedCount).Error; err != nil {
+		return nil, err
+	}
+	if int(failedCount) > failureThreshold {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf(
+			"failed agents (%d) exceed the failure threshold (%d), raise FAILURE_THRESHOLD to resume", failedCount, failureThreshold))
+	}
+
+	err = updateAgentUpgradeRolloutState(
+		orgID, lcuuid, []string{common.AGENT_UPGRADE_ROLLOUT_STATE_PAUSED},
+		map[string]interface{}{
+			"state":             common.AGENT_UPGRADE_ROLLOUT_STATE_RUNNING,
+			"failure_threshold": failureThreshold,
+			"message":           "",
+		}, nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return GetAgentUpgradeRollout(orgID, lcuuid)
+}
+
+// CancelAgentUpgradeRollout stops starting new waves and cancels the pending agents, the agents being upgraded are
+// still checked until they finish
+func CancelAgentUpgradeRollout(orgID int, lcuuid string) (*model.AgentUpgradeRollout, error) {
+	err := updateAgentUpgradeRolloutState(
+		orgID, lcuuid, []string{common.AGENT_UPGRADE_ROLLOUT_STATE_RUNNING, common.AGENT_UPGRADE_ROLLOUT_STATE_PAUSED},
+		map[string]interface{}{"state": common.AGENT_UPGRADE_ROLLOUT_STATE_CANCELLED, "message": "cancelled by user"},
+		cancelPendingAgentUpgradeRolloutAgents,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return GetAgentUpgradeRollout(orgID, lcuuid)
+}
+
+// RollbackAgentUpgradeRollout makes the master controller upgrade the agents of the rollout to the rollback image
+func RollbackAgentUpgradeRollout(orgID int, lcuuid string, rolloutUpdate model.AgentUpgradeRolloutUpdate) (*model.AgentUpgradeRollout, error) {
+	dbInfo, err := mysql.GetDB(orgID)
+	if err != nil {
+		return nil, err
+	}
+	rollout, err := getAgentUpgradeRollout(dbInfo.DB, lcuuid)
+	if err != nil {
+		return nil, err
+	}
+	rollbackImageName := rollout.RollbackImageName
+	if rolloutUpdate.RollbackImageName != "" {
+		rollbackImageName = rolloutUpdate.RollbackImageName
+	}
+	if rollbackImageName == "" {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, "ROLLBACK_IMAGE_NAME is required")
+	}
+	if _, err := getAgentUpgradeRolloutExpectedRevision(dbInfo.DB, rollbackImageName); err != nil {
+		return nil, err
+	}
+
+	err = updateAgentUpgradeRolloutState(
+		orgID, lcuuid,
+		[]string{
+			common.AGENT_UPGRADE_ROLLOUT_STATE_RUNNING, common.AGENT_UPGRADE_ROLLOUT_STATE_PAUSED,
+			common.AGENT_UPGRADE_ROLLOUT_STATE_SUCCEEDED, common.AGENT_UPGRADE_ROLLOUT_STATE_CANCELLED,
+		},
+		map[string]interface{}{
+			"state":               common.AGENT_UPGRADE_ROLLOUT_STATE_ROLLING_BACK,
+			"rollback_image_name": rollbackImageName,
+			"message":             "rolled back by user",
+		},
+		cancelPendingAgentUpgradeRolloutAgents,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return GetAgentUpgradeRollout(orgID, lcuuid)
+}
+
+func cancelPendingAgentUpgradeRolloutAgents(tx *gorm.DB, lcuuid string) error {
+	return tx.Model(&mysqlmodel.AgentUpgradeRolloutAgent{}).
+		Where("rollout_lcuuid = ? AND state = ?", lcuuid, common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_PENDING).
+		Update("state", common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_CANCELLED).Error
+}
+
+// updateAgentUpgradeRolloutState updates the rollout only if it is in one of the fromStates,
+// the master controller may change the state at the same time
+func updateAgentUpgradeRolloutState(
+	orgID int, lcuuid string, fromStates []string, updates map[string]interface{}, then func(tx *gorm.DB, lcuuid string) error,
+) error {
+	dbInfo, err := mysql.GetDB(orgID)
+	if err != nil {
+		return err
+	}
+	rollout, err := getAgentUpgradeRollout(dbInfo.DB, lcuuid)
+	if err != nil {
+		return err
+	}
+	return dbInfo.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&mysqlmodel.AgentUpgradeRollout{}).
+			Where("lcuuid = ? AND state IN (?)", lcuuid, fromStates).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf(
+				"agent upgrade rollout (%s) is %s, only %v is allowed", rollout.Name, rollout.State, fromStates))
+		}
+		log.Infof("update agent upgrade rollout (%s): %v", rollout.Name, updates, logger.NewORGPrefix(orgID))
+		if then != nil {
+			return then(tx, lcuuid)
+		}
+		return nil
+	})
+}
+
+func getAgentUpgradeRollout(db *gorm.DB, lcuuid string) (*mysqlmodel.AgentUpgradeRollout, error) {
+	var rollout mysqlmodel.AgentUpgradeRollout
+	if err := db.Where("lcuuid = ?", lcuuid).First(&rollout).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("agent upgrade rollout (%s) not found", lcuuid))
+		}
+		return nil, err
+	}
+	return &rollout, nil
+}
+
+func getVTapGroupLcuuidToName(db *gorm.DB) (map[string]string, error) {
+	var vtapGroups []mysqlmodel.VTapGroup
+	if err := db.Select("lcuuid", "name").Find(&vtapGroups).Error; err != nil {
+		return nil, err
+	}
+	vtapGroupLcuuidToName := make(map[string]string, len(vtapGroups))
+	for _, vtapGroup := range vtapGroups {
+		vtapGroupLcuuidToName[vtapGroup.Lcuuid] = vtapGroup.Name
+	}
+	return vtapGroupLcuuidToName, nil
+}
+
+func convertAgentUpgradeRollout(rollout mysqlmodel.AgentUpgradeRollout) model.AgentUpgradeRollout {
+	return model.AgentUpgradeRollout{
+		Lcuuid:            rollout.Lcuuid,
+		Name:              rollout.Name,
+		AgentGroupLcuuid:  rollout.AgentGroupLcuuid,
+		ImageName:         rollout.ImageName,
+		ExpectedRevision:  rollout.ExpectedRevision,
+		RollbackImageName: rollout.RollbackImageName,
+		WaveType:          rollout.WaveType,
+		WaveSize:          rollout.WaveSize,
+		WaveCount:         rollout.WaveCount,
+		WaveInterval:      rollout.WaveInterval,
+		HealthTimeout:     rollout.HealthTimeout,
+		FailureThreshold:  rollout.FailureThreshold,
+		FailureAction:     rollout.FailureAction,
+		State:             rollout.State,
+		CurrentWave:       rollout.CurrentWave,
+		Message:           rollout.Message,
+		UserID:            rollout.UserID,
+		AgentStateCount:   make(map[string]int),
+		CreatedAt:         rollout.CreatedAt.Format(common.GO_BIRTHDAY),
+		UpdatedAt:         rollout.UpdatedAt.Format(common.GO_BIRTHDAY),
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/controller/common"
+)
+
+func TestGetAgentUpgradeRolloutWaveSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		agentCount int
+		waveType   string
+		waveSize   int
+		want       int
+	}{
+		{"count", 10, common.AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_COUNT, 3, 3},
+		{"count larger than agents", 2, common.AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_COUNT, 5, 5},
+		{"percentage", 10, common.AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_PERCENTAGE, 20, 2},
+		{"percentage round up", 10, common.AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_PERCENTAGE, 25, 3},
+		{"percentage at least one", 3, common.AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_PERCENTAGE, 1, 1},
+		{"all in one wave", 7, common.AGENT_UPGRADE_ROLLOUT_WAVE_TYPE_PERCENTAGE, 100, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetAgentUpgradeRolloutWaveSize(tt.agentCount, tt.waveType, tt.waveSize); got != tt.want {
+				t.Errorf("GetAgentUpgradeRolloutWaveSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"sort"
+
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql"
+	mysqlmodel "github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
+	"github.com/khulnasoft/deepflow/server/controller/model"
+)
+
+const (
+	PROMETHEUS_CARDINALITY_DEFAULT_LIMIT = 10
+)
+
+type prometheusNameCount struct {
+	Name  string `gorm:"column:name"`
+	Count int    `gorm:"column:count"`
+}
+
+// GetPrometheusCardinality returns the top cardinality metrics and labels of an org, the cardinality is counted by
+// the metric and label IDs encoded by controller, which are limited by the ID space of each org. Controller does
+// not record the series, so the cardinality of metrics is estimated by the value counts of their label names.
+func GetPrometheusCardinality(orgID, limit int) (*model.PrometheusCardinality, error) {
+	dbInfo, err := mysql.GetDB(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = PROMETHEUS_CARDINALITY_DEFAULT_LIMIT
+	}
+
+	var labelValueCounts []prometheusNameCount
+	if err := dbInfo.Model(&mysqlmodel.PrometheusLabel{}).Select("name, COUNT(*) AS count").Group("name").
+		Scan(&labelValueCounts).Error; err != nil {
+		return nil, err
+	}
+	var metricTargetCounts []prometheusNameCount
+	if err := dbInfo.Model(&mysqlmodel.PrometheusMetricTarget{}).Select("metric_name AS name, COUNT(*) AS count").Group("metric_name").
+		Scan(&metricTargetCounts).Error; err != nil {
+		return nil, err
+	}
+	var labelNames []mysqlmodel.PrometheusLabelName
+	if err := dbInfo.Select("id", "name").Find(&labelNames).Error; err != nil {
+		return nil, err
+	}
+	var metricLabelNames []mysqlmodel.PrometheusMetricLabelName
+	if err := dbInfo.Select("metric_name", "label_name_id").Find(&metricLabelNames).Error; err != nil {
+		return nil, err
+	}
+	var metricNameCount, labelValueCount int64
+	if err := dbInfo.Model(&mysqlmodel.PrometheusMetricName{}).Count(&metricNameCount).Error; err != nil {
+		return nil, err
+	}
+	if err := dbInfo.Model(&mysqlmodel.PrometheusLabelValue{}).Count(&labelValueCount).Error; err != nil {
+		return nil, err
+	}
+
+	labelNameIDToName := make(map[int]string, len(labelNames))
+	for _, labelName := range labelNames {
+		labelNameIDToName[labelName.ID] = labelName.Name
+	}
+	metricToLabelNames := make(map[string][]string)
+	for _, metricLabelName := range metricLabelNames {
+		if name, ok := labelNameIDToName[metricLabelName.LabelNameID]; ok {
+			metricToLabelNames[metricLabelName.MetricName] = append(metricToLabelNames[metricLabelName.MetricName], name)
+		}
+	}
+
+	cardinality := getPrometheusCardinalityTop(labelValueCounts, metricTargetCounts, metricToLabelNames, limit)
+	cardinality.MetricNameCount = int(metricNameCount)
+	cardinality.LabelNameCount = len(labelNames)
+	cardinality.LabelValueCount = int(labelValueCount)
+	return cardinality, nil
+}
+
+// getPrometheusCardinalityTop sorts the metrics by the estimated labels they may use and the labels by their value
+// count, returns the top limit of each
+func getPrometheusCardinalityTop(labelValueCounts, metricTargetCounts []prometheusNameCount, metricToLabelNames map[string][]string, limit int) *model.PrometheusCardinality {
+	cardinality := &model.PrometheusCardinality{}
+	labelNameToValueCount := make(map[string]int, len(labelValueCounts))
+	for _, c := range labelValueCounts {
+		labelNameToValueCount[c.Name] = c.Count
+		cardinality.LabelCount += c.Count
+	}
+	labelNameToMetricCount := make(map[string]int)
+	metrics := make(map[string]*model.PrometheusMetricCardinality, len(metricToLabelNames))
+	for metricName, labelNames := range metricToLabelNames {
+		metric := &model.PrometheusMetricCardinality{MetricName: metricName, LabelNameCount: len(labelNames)}
+		for _, labelName := range labelNames {
+			metric.EstimatedLabelCount += labelNameToValueCount[labelName]
+			labelNameToMetricCount[labelName]++
+		}
+		metrics[metricName] = metric
+	}
+	for _, c := range metricTargetCounts {
+		if _, ok := metrics[c.Name]; !ok {
+			metrics[c.Name] = &model.PrometheusMetricCardinality{MetricName: c.Name}
+		}
+		metrics[c.Name].TargetCount = c.Count
+	}
+
+	cardinality.Metrics = make([]model.PrometheusMetricCardinality, 0, len(metrics))
+	for _, metric := range metrics {
+		cardinality.Metrics = append(cardinality.Metrics, *metric)
+	}
+	sort.Slice(cardinality.Metrics, func(i, j int) bool {
+		if cardinality.Metrics[i].EstimatedLabelCount != cardinality.Metrics[j].EstimatedLabelCount {
+			return cardinality.Metrics[i].EstimatedLabelCount > cardinality.Metrics[j].EstimatedLabelCount
+		}
+		if cardinality.Metrics[i].TargetCount != cardinality.Metrics[j].TargetCount {
+			return cardinality.Metrics[i].TargetCount > cardinality.Metrics[j].TargetCount
+		}
+		return cardinality.Metrics[i].MetricName < cardinality.Metrics[j].MetricName
+	})
+	if len(cardinality.Metrics) > limit {
+		cardinality.Metrics = cardinality.Metrics[:limit]
+	}
+
+	cardinality.Labels = make([]model.PrometheusLabelCardinality, 0, len(labelValueCounts))
+	for _, c := range labelValueCounts {
+		cardinality.Labels = append(cardinality.Labels, model.PrometheusLabelCardinality{
+			LabelName:   c.Name,
+			ValueCount:  c.Count,
+			MetricCount: labelNameToMetricCount[c.Name],
+		})
+	}
+	sort.Slice(cardinality.Labels, func(i, j int) bool {
+		if cardinality.Labels[i].ValueCount != cardinality.Labels[j].ValueCount {
+			return cardinality.Labels[i].ValueCount > cardinality.Labels[j].ValueCount
+		}
+		return cardinality.Labels[i].LabelName < cardinality.Labels[j].LabelName
+	})
+	if len(cardinality.Labels) > limit {
+		cardinality.Labels = cardinality.Labels[:limit]
+	}
+	return cardinality
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/controller/model"
+)
+
+func TestGetPrometheusCardinalityTop(t *testing.T) {
+	labelValueCounts := []prometheusNameCount{{"instance", 5}, {"job", 2}, {"request_id", 1000}}
+	metricTargetCounts := []prometheusNameCount{{"up", 5}, {"http_requests_total", 2}, {"process_cpu_seconds", 1}}
+	metricToLabelNames := map[string][]string{
+		"up":                  {"instance", "job"},
+		"http_requests_total": {"instance", "job", "request_id"},
+	}
+
+	got := getPrometheusCardinalityTop(labelValueCounts, metricTargetCounts, metricToLabelNames, 2)
+	want := &model.PrometheusCardinality{
+		LabelCount: 1007,
+		Metrics: []model.PrometheusMetricCardinality{
+			{MetricName: "http_requests_total", LabelNameCount: 3, EstimatedLabelCount: 1007, TargetCount: 2},
+			{MetricName: "up", LabelNameCount: 2, EstimatedLabelCount: 7, TargetCount: 5},
+		},
+		Labels: []model.PrometheusLabelCardinality{
+			{LabelName: "request_id", ValueCount: 1000, MetricCount: 1},
+			{LabelName: "instance", ValueCount: 5, MetricCount: 2},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getPrometheusCardinalityTop() = %+v, want %+v", got, want)
+	}
+
+	got = getPrometheusCardinalityTop(labelValueCounts, metricTargetCounts, metricToLabelNames, 10)
+	if len(got.Metrics) != 3 || got.Metrics[2].MetricName != "process_cpu_seconds" || len(got.Labels) != 3 {
+		t.Errorf("getPrometheusCardinalityTop() = %+v, want all metrics and labels", got)
+	}
+}
@@ -58,6 +58,7 @@ var DOMAIN_PASSWORD_KEYS = map[string]bool{
 	"manage_one_password": false,
 	"token":               false,
 	"app_secret":          false,
+	"private_key":         false,
 }
 
 func getGrpcServerAndPort(db *mysql.DB, controllerIP string, cfg *config.ControllerConfig) (string, string) {
@@ -111,7 +112,7 @@ func GetDomains(orgDB *mysql.DB, excludeTeamIDs []int, filter map[string]interfa
 		db = db.Where("lcuuid = ?", fLcuuid)
 	}
 	if fName, ok := filter["name"]; ok {
-		db = db.Where("binary name = ?", fName)
+		db = db.Where(mysqlcommon.GetBinaryEqualCondition(orgDB.Config, "name"), fName)
 	}
 	if fTeamID, ok := filter["team_id"]; ok {
 		db = db.Where("team_id = ?", fTeamID)
@@ -249,12 +250,12 @@ func maskDomainInfo(domainCreate model.DomainCreate) model.DomainCreate {
 func CreateDomain(domainCreate model.DomainCreate, userInfo *httpcommon.UserInfo, db *mysql.DB, cfg *config.ControllerConfig) (*model.Domain, error) {
 	var count int64
 
-	db.Model(&mysqlmodel.Domain{}).Where("binary name = ?", domainCreate.Name).Count(&count)
+	db.Model(&mysqlmodel.Domain{}).Where(mysqlcommon.GetBinaryEqualCondition(db.Config, "name"), domainCreate.Name).Count(&count)
 	if count > 0 {
 		return nil, servicecommon.NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("domain (%s) already exist", domainCreate.Name))
 	}
 
-	db.Model(&mysqlmodel.SubDomain{}).Where("binary name = ?", domainCreate.Name).Count(&count)
+	db.Model(&mysqlmodel.SubDomain{}).Where(mysqlcommon.GetBinaryEqualCondition(db.Config, "name"), domainCreate.Name).Count(&count)
 	if count > 0 {
 		return nil, servicecommon.NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("sub_domain (%s) already exist", domainCreate.Name))
 	}
@@ -266,13 +267,13 @@ func CreateDomain(domainCreate model.DomainCreate, userInfo *httpcommon.UserInfo
 		}
 
 		var domainCheck mysqlmodel.Domain
-		count = db.Where("binary cluster_id = ?", domainCreate.KubernetesClusterID).First(&domainCheck).RowsAffected
+		count = db.Where(mysqlcommon.GetBinaryEqualCondition(db.Config, "cluster_id"), domainCreate.KubernetesClusterID).First(&domainCheck).RowsAffected
 		if count > 0 {
 			return nil, servicecommon.NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("domain cluster_id (%s) already exist in domain (%s)", domainCreate.KubernetesClusterID, domainCheck.Name))
 		}
 
 		var subDomainCheck mysqlmodel.SubDomain
-		count = db.Where("binary cluster_id = ?", domainCreate.KubernetesClusterID).First(&subDomainCheck).RowsAffected
+		count = db.Where(mysqlcommon.GetBinaryEqualCondition(db.Config, "cluster_id"), domainCreate.KubernetesClusterID).First(&subDomainCheck).RowsAffected
 		if count > 0 {
 			return nil, servicecommon.NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("domain cluster_id (%s) already exist in sub_domain (%s)", domainCreate.KubernetesClusterID, subDomainCheck.Name))
 		}
@@ -397,7 +398,7 @@ func CreateDomain(domainCreate model.DomainCreate, userInfo *httpcommon.UserInfo
 
 	log.Infof("create domain (%v)", maskDomainInfo(domainCreate), db.LogPrefixORGID)
 
-	err = db.Clauses(clause.Insert{Modifier: "IGNORE"}).Create(&domain).Error
+	err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain).Error
 	if err != nil {
 		return nil, servicecommon.NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("create domain (%s) failed", domainCreate.Name))
 	}
@@ -783,7 +784,7 @@ func GetSubDomains(orgDB *mysql.DB, excludeTeamIDs []int, filter map[string]inte
 		db = db.Where("domain = ?", fDomain)
 	}
 	if fClusterID, ok := filter["cluster_id"]; ok {
-		db = db.Where("binary cluster_id = ?", fClusterID)
+		db = db.Where(mysqlcommon.GetBinaryEqualCondition(orgDB.Config, "cluster_id"), fClusterID)
 	}
 	if fTeamID, ok := filter["team_id"]; ok {
 		db = db.Where("team_id = ?", fTeamID)
@@ -863,7 +864,7 @@ func CreateSubDomain(subDomainCreate model.SubDomainCreate, db *mysql.DB, userIn
 	}
 
 	var count int64
-	db.Model(&mysqlmodel.SubDomain{}).Where("binary name = ?", subDomainCreate.Name).Count(&count)
+	db.Model(&mysqlmodel.SubDomain{}).Where(mysqlcommon.GetBinaryEqualCondition(db.Config, "name"), subDomainCreate.Name).Count(&count)
 	if count > 0 {
 		return nil, servicecommon.NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("sub_domain (%s) already exist", subDomainCreate.Name))
 	}
@@ -873,13 +874,13 @@ func CreateSubDomain(subDomainCreate model.SubDomainCreate, db *mysql.DB, userIn
 		}
 
 		var domainCheck mysqlmodel.Domain
-		count = db.Where("binary cluster_id = ?", subDomainCreate.ClusterID).First(&domainCheck).RowsAffected
+		count = db.Where(mysqlcommon.GetBinaryEqualCondition(db.Config, "cluster_id"), subDomainCreate.ClusterID).First(&domainCheck).RowsAffected
 		if count > 0 {
 			return nil, servicecommon.NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("sub_domain cluster_id (%s) already exist in domain (%s)", subDomainCreate.ClusterID, domainCheck.Name))
 		}
 
 		var subDomainCheck mysqlmodel.SubDomain
-		count = db.Where("binary cluster_id = ?", subDomainCreate.ClusterID).First(&subDomainCheck).RowsAffected
+		count = db.Where(mysqlcommon.GetBinaryEqualCondition(db.Config, "cluster_id"), subDomainCreate.ClusterID).First(&subDomainCheck).RowsAffected
 		if count > 0 {
 			return nil, servicecommon.NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("sub_domain cluster_id (%s) already exist in sub_domain (%s)", subDomainCreate.ClusterID, subDomainCheck.Name))
 		}
@@ -472,6 +472,9 @@ func (a *AgentGroup) Delete(lcuuid string) (resp map[string]string, err error) {
 		if err = db.Where("vtap_group_lcuuid = ?", lcuuid).Delete(&agentconf.AgentGroupConfigModel{}).Error; err != nil {
 			return err
 		}
+		if err = db.Where("agent_group_lcuuid = ?", lcuuid).Delete(&agentconf.MySQLAgentGroupConfigurationRevision{}).Error; err != nil {
+			return err
+		}
 		return db.Where("agent_group_lcuuid = ?", lcuuid).Delete(&agentconf.MySQLAgentGroupConfiguration{}).Error
 	})
 	if err != nil {
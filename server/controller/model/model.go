@@ -199,6 +199,86 @@ type VtapRepo struct {
 	UpdatedAt string `json:"UPDATED_AT"`
 }
 
+type AgentUpgradeRolloutCreate struct {
+	Name              string `json:"NAME"`
+	AgentGroupLcuuid  string `json:"AGENT_GROUP_LCUUID" binding:"required"`
+	ImageName         string `json:"IMAGE_NAME" binding:"required"`
+	RollbackImageName string `json:"ROLLBACK_IMAGE_NAME"`
+	WaveType          string `json:"WAVE_TYPE"` // COUNT or PERCENTAGE, default: COUNT
+	WaveSize          int    `json:"WAVE_SIZE" binding:"required"`
+	WaveInterval      int    `json:"WAVE_INTERVAL"`  // unit: s, 0 means the next wave starts as soon as the previous is healthy
+	HealthTimeout     int    `json:"HEALTH_TIMEOUT"` // unit: s, default: 600
+	FailureThreshold  int    `json:"FAILURE_THRESHOLD"`
+	FailureAction     string `json:"FAILURE_ACTION"` // PAUSE or ROLLBACK, default: PAUSE
+}
+
+type AgentUpgradeRolloutUpdate struct {
+	FailureThreshold  *int   `json:"FAILURE_THRESHOLD"`
+	RollbackImageName string `json:"ROLLBACK_IMAGE_NAME"`
+}
+
+type AgentUpgradeRollout struct {
+	Lcuuid            string                     `json:"LCUUID"`
+	Name              string                     `json:"NAME"`
+	AgentGroupLcuuid  string                     `json:"AGENT_GROUP_LCUUID"`
+	AgentGroupName    string                     `json:"AGENT_GROUP_NAME"`
+	ImageName         string                     `json:"IMAGE_NAME"`
+	ExpectedRevision  string                     `json:"EXPECTED_REVISION"`
+	RollbackImageName string                     `json:"ROLLBACK_IMAGE_NAME"`
+	WaveType          string                     `json:"WAVE_TYPE"`
+	WaveSize          int                        `json:"WAVE_SIZE"`
+	WaveCount         int                        `json:"WAVE_COUNT"`
+	WaveInterval      int                        `json:"WAVE_INTERVAL"`
+	HealthTimeout     int                        `json:"HEALTH_TIMEOUT"`
+	FailureThreshold  int                        `json:"FAILURE_THRESHOLD"`
+	FailureAction     string                     `json:"FAILURE_ACTION"`
+	State             string                     `json:"STATE"`
+	CurrentWave       int                        `json:"CURRENT_WAVE"`
+	Message           string                     `json:"MESSAGE"`
+	UserID            int                        `json:"USER_ID"`
+	AgentCount        int                        `json:"AGENT_COUNT"`
+	AgentStateCount   map[string]int             `json:"AGENT_STATE_COUNT"`
+	Agents            []AgentUpgradeRolloutAgent `json:"AGENTS,omitempty"`
+	CreatedAt         string                     `json:"CREATED_AT"`
+	UpdatedAt         string                     `json:"UPDATED_AT"`
+}
+
+type AgentUpgradeRolloutAgent struct {
+	AgentLcuuid      string `json:"AGENT_LCUUID"`
+	AgentName        string `json:"AGENT_NAME"`
+	Wave             int    `json:"WAVE"`
+	State            string `json:"STATE"`
+	OriginalRevision string `json:"ORIGINAL_REVISION"`
+	Revision         string `json:"REVISION"`
+	StartedAt        string `json:"STARTED_AT"`
+	FinishedAt       string `json:"FINISHED_AT"`
+	Message          string `json:"MESSAGE"`
+}
+
+type PrometheusCardinality struct {
+	MetricNameCount int                           `json:"METRIC_NAME_COUNT"`
+	LabelNameCount  int                           `json:"LABEL_NAME_COUNT"`
+	LabelValueCount int                           `json:"LABEL_VALUE_COUNT"`
+	LabelCount      int                           `json:"LABEL_COUNT"` // count of label name and value pairs
+	Metrics         []PrometheusMetricCardinality `json:"METRICS"`
+	Labels          []PrometheusLabelCardinality  `json:"LABELS"`
+}
+
+type PrometheusMetricCardinality struct {
+	MetricName     string `json:"METRIC_NAME"`
+	LabelNameCount int    `json:"LABEL_NAME_COUNT"`
+	// sum of the org wide value count of each label name of the metric, it is an upper bound estimate of the labels
+	// used by the metric, not the series count of the metric, which is not recorded by controller
+	EstimatedLabelCount int `json:"ESTIMATED_LABEL_COUNT"`
+	TargetCount         int `json:"TARGET_COUNT"`
+}
+
+type PrometheusLabelCardinality struct {
+	LabelName   string `json:"LABEL_NAME"`
+	ValueCount  int    `json:"VALUE_COUNT"`
+	MetricCount int    `json:"METRIC_COUNT"`
+}
+
 type HostVTapRebalanceResult struct {
 	IP                string  `json:"IP"`
 	AZ                string  `json:"AZ"`
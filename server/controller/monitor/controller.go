@@ -22,11 +22,13 @@ import (
 	"time"
 
 	mapset "github.com/deckarep/golang-set"
+	"gorm.io/gorm"
 
 	"github.com/khulnasoft/deepflow/server/controller/common"
 	"github.com/khulnasoft/deepflow/server/controller/config"
 	"github.com/khulnasoft/deepflow/server/controller/db/mysql"
 	mysqlmodel "github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
+	"github.com/khulnasoft/deepflow/server/controller/election"
 	"github.com/khulnasoft/deepflow/server/controller/model"
 	mconfig "github.com/khulnasoft/deepflow/server/controller/monitor/config"
 	"github.com/khulnasoft/deepflow/server/controller/trisolaris/refresh"
@@ -180,7 +182,10 @@ func (c *ControllerCheck) healthCheck(orgDB *mysql.DB) {
 				if _, ok := c.exceptionControllerDict[controller.IP]; ok {
 					if c.exceptionControllerDict[controller.IP].duration() >= int64(3*common.HEALTH_CHECK_INTERVAL.Seconds()) {
 						delete(c.exceptionControllerDict, controller.IP)
-						if err := mysql.DefaultDB.Model(&controller).Update("state", common.HOST_STATE_EXCEPTION).Error; err != nil {
+						// the controllers are written by the leader only, the lease is checked in the same transaction
+						if err := election.FencedTransaction(mysql.DefaultDB.DB, func(tx *gorm.DB) error {
+							return tx.Model(&controller).Update("state", common.HOST_STATE_EXCEPTION).Error
+						}); err != nil {
 							log.Errorf("update controller(name: %s, ip: %s) state error: %v", controller.Name, controller.IP, err)
 						}
 						exceptionIPs = append(exceptionIPs, controller.IP)
@@ -203,7 +208,9 @@ func (c *ControllerCheck) healthCheck(orgDB *mysql.DB) {
 				if _, ok := c.normalControllerDict[controller.IP]; ok {
 					if c.normalControllerDict[controller.IP].duration() >= int64(3*common.HEALTH_CHECK_INTERVAL.Seconds()) {
 						delete(c.normalControllerDict, controller.IP)
-						if err := mysql.DefaultDB.Model(&controller).Update("state", common.HOST_STATE_COMPLETE).Error; err != nil {
+						if err := election.FencedTransaction(mysql.DefaultDB.DB, func(tx *gorm.DB) error {
+							return tx.Model(&controller).Update("state", common.HOST_STATE_COMPLETE).Error
+						}); err != nil {
 							log.Errorf("update controller(name: %s, ip: %s) state error: %v", controller.Name, controller.IP, err)
 						}
 						log.Infof("set controller (%s) state to normal", controller.IP)
@@ -229,7 +236,9 @@ func (c *ControllerCheck) healthCheck(orgDB *mysql.DB) {
 			if err := orgDB.Delete(mysqlmodel.AZControllerConnection{}, "controller_ip = ?", ip).Error; err != nil {
 				log.Errorf("delete az_controller_connection(ip: %s) error: %s", ip, err.Error(), orgDB.LogPrefixORGID)
 			}
-			err := mysql.DefaultDB.Delete(mysqlmodel.Controller{}, "ip = ?", ip).Error
+			err := election.FencedTransaction(mysql.DefaultDB.DB, func(tx *gorm.DB) error {
+				return tx.Delete(mysqlmodel.Controller{}, "ip = ?", ip).Error
+			})
 			if err != nil {
 				log.Errorf("delete controller(%s) failed, err:%s", ip, err)
 			} else {
@@ -0,0 +1,402 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vtap
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql"
+	mysqlmodel "github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
+	"github.com/khulnasoft/deepflow/server/controller/monitor/config"
+	"github.com/khulnasoft/deepflow/server/controller/trisolaris"
+	tcommon "github.com/khulnasoft/deepflow/server/controller/trisolaris/common"
+)
+
+var vtapStateToString = map[int]string{
+	common.VTAP_STATE_NOT_CONNECTED: common.VTAP_STATE_NOT_CONNECTED_STR,
+	common.VTAP_STATE_NORMAL:        common.VTAP_STATE_NORMAL_STR,
+	common.VTAP_STATE_DISABLE:       common.VTAP_STATE_DISABLE_STR,
+	common.VTAP_STATE_PENDING:       common.VTAP_STATE_PENDING_STR,
+}
+
+// UpgradeRolloutCheck upgrades the agents of the running rollouts wave by wave, a wave is started after all agents
+// of the previous wave reconnect healthy, the rollout is paused or rolled back if failed agents exceed the threshold.
+type UpgradeRolloutCheck struct {
+	vCtx       context.Context
+	vCancel    context.CancelFunc
+	cfg        config.MonitorConfig
+	listenPort int
+}
+
+func NewUpgradeRolloutCheck(cfg config.MonitorConfig, listenPort int, ctx context.Context) *UpgradeRolloutCheck {
+	vCtx, vCancel := context.WithCancel(ctx)
+	return &UpgradeRolloutCheck{
+		vCtx:       vCtx,
+		vCancel:    vCancel,
+		cfg:        cfg,
+		listenPort: listenPort,
+	}
+}
+
+func (u *UpgradeRolloutCheck) Start(sCtx context.Context) {
+	log.Info("upgrade rollout check start")
+	go func() {
+		ticker := time.NewTicker(time.Duration(u.cfg.UpgradeRolloutCheckInterval) * time.Second)
+		defer ticker.Stop()
+	LOOP:
+		for {
+			select {
+			case <-ticker.C:
+				mysql.GetDBs().DoOnAllDBs(func(db *mysql.DB) error {
+					u.check(db)
+					return nil
+				})
+			case <-sCtx.Done():
+				break LOOP
+			case <-u.vCtx.Done():
+				break LOOP
+			}
+		}
+	}()
+}
+
+func (u *UpgradeRolloutCheck) Stop() {
+	if u.vCancel != nil {
+		u.vCancel()
+	}
+	log.Info("upgrade rollout check stopped")
+}
+
+func (u *UpgradeRolloutCheck) check(db *mysql.DB) {
+	// the agents upgrading when the rollout is paused or cancelled are still checked until they finish
+	upgradingRolloutLcuuids := db.Model(&mysqlmodel.AgentUpgradeRolloutAgent{}).Select("rollout_lcuuid").
+		Where("state = ?", common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_UPGRADING)
+	var rollouts []mysqlmodel.AgentUpgradeRollout
+	if err := db.Where(
+		"state IN (?)",
+		[]string{common.AGENT_UPGRADE_ROLLOUT_STATE_RUNNING, common.AGENT_UPGRADE_ROLLOUT_STATE_ROLLING_BACK},
+	).Or(
+		"state IN (?) AND lcuuid IN (?)",
+		[]string{common.AGENT_UPGRADE_ROLLOUT_STATE_PAUSED, common.AGENT_UPGRADE_ROLLOUT_STATE_CANCELLED}, upgradingRolloutLcuuids,
+	).Find(&rollouts).Error; err != nil {
+		log.Errorf("get agent upgrade rollouts failed: %s", err.Error(), db.LogPrefixORGID)
+		return
+	}
+	for i := range rollouts {
+		rollout := &rollouts[i]
+		var err error
+		switch rollout.State {
+		case common.AGENT_UPGRADE_ROLLOUT_STATE_RUNNING:
+			err = u.advance(db, rollout)
+		case common.AGENT_UPGRADE_ROLLOUT_STATE_ROLLING_BACK:
+			err = u.rollback(db, rollout)
+		default:
+			err = u.finishWave(db, rollout)
+		}
+		if err != nil {
+			log.Errorf("check agent upgrade rollout (%s) failed: %s", rollout.Name, err.Error(), db.LogPrefixORGID)
+		}
+	}
+}
+
+func (u *UpgradeRolloutCheck) getRolloutAgents(db *mysql.DB, rollout *mysqlmodel.AgentUpgradeRollout) (
+	[]mysqlmodel.AgentUpgradeRolloutAgent, map[string]*mysqlmodel.VTap, error,
+) {
+	var rolloutAgents []mysqlmodel.AgentUpgradeRolloutAgent
+	if err := db.Where("rollout_lcuuid = ?", rollout.Lcuuid).Find(&rolloutAgents).Error; err != nil {
+		return nil, nil, err
+	}
+	agentLcuuids := make([]string, 0, len(rolloutAgents))
+	for _, rolloutAgent := range rolloutAgents {
+		agentLcuuids = append(agentLcuuids, rolloutAgent.AgentLcuuid)
+	}
+	var vtaps []*mysqlmodel.VTap
+	if err := db.Where("lcuuid IN (?)", agentLcuuids).Find(&vtaps).Error; err != nil {
+		return nil, nil, err
+	}
+	lcuuidToVTap := make(map[string]*mysqlmodel.VTap, len(vtaps))
+	for _, vtap := range vtaps {
+		lcuuidToVTap[vtap.Lcuuid] = vtap
+	}
+	return rolloutAgents, lcuuidToVTap, nil
+}
+
+func (u *UpgradeRolloutCheck) advance(db *mysql.DB, rollout *mysqlmodel.AgentUpgradeRollout) error {
+	rolloutAgents, lcuuidToVTap, err := u.getRolloutAgents(db, rollout)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var failedCount, upgradingCount int
+	var lastFinishedAt time.Time
+	for i := range rolloutAgents {
+		rolloutAgent := &rolloutAgents[i]
+		if rolloutAgent.State == common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_UPGRADING {
+			if err := u.checkAgent(db, rollout, rolloutAgent, lcuuidToVTap[rolloutAgent.AgentLcuuid], now); err != nil {
+				return err
+			}
+		}
+		switch rolloutAgent.State {
+		case common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_UPGRADING:
+			upgradingCount++
+		case common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_FAILED:
+			failedCount++
+		}
+		if rolloutAgent.Wave == rollout.CurrentWave && rolloutAgent.FinishedAt != nil && rolloutAgent.FinishedAt.After(lastFinishedAt) {
+			lastFinishedAt = *rolloutAgent.FinishedAt
+		}
+	}
+
+	if failedCount > rollout.FailureThreshold {
+		message := fmt.Sprintf("failed agents (%d) exceed the failure threshold (%d)", failedCount, rollout.FailureThreshold)
+		state := common.AGENT_UPGRADE_ROLLOUT_STATE_PAUSED
+		if rollout.FailureAction == common.AGENT_UPGRADE_ROLLOUT_FAILURE_ACTION_ROLLBACK {
+			state = common.AGENT_UPGRADE_ROLLOUT_STATE_ROLLING_BACK
+		}
+		log.Warningf("agent upgrade rollout (%s) %s, set to %s", rollout.Name, message, state, db.LogPrefixORGID)
+		return u.updateRolloutState(db, rollout, state, message)
+	}
+	if upgradingCount > 0 {
+		return nil
+	}
+	if rollout.CurrentWave >= rollout.WaveCount {
+		log.Infof("agent upgrade rollout (%s) succeeded", rollout.Name, db.LogPrefixORGID)
+		return u.updateRolloutState(db, rollout, common.AGENT_UPGRADE_ROLLOUT_STATE_SUCCEEDED, "")
+	}
+	if rollout.CurrentWave > 0 && now.Sub(lastFinishedAt) < time.Duration(rollout.WaveInterval)*time.Second {
+		return nil
+	}
+	return u.startWave(db, rollout, rolloutAgents, lcuuidToVTap, now)
+}
+
+// finishWave checks the agents which were upgrading when the rollout was paused or cancelled, no more wave is started,
+// and the failed agents are counted against the failure threshold only if the rollout is resumed.
+func (u *UpgradeRolloutCheck) finishWave(db *mysql.DB, rollout *mysqlmodel.AgentUpgradeRollout) error {
+	rolloutAgents, lcuuidToVTap, err := u.getRolloutAgents(db, rollout)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range rolloutAgents {
+		rolloutAgent := &rolloutAgents[i]
+		if rolloutAgent.State != common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_UPGRADING {
+			continue
+		}
+		if err := u.checkAgent(db, rollout, rolloutAgent, lcuuidToVTap[rolloutAgent.AgentLcuuid], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAgent sets the agent to succeeded if it reconnects with the expected revision and without new exceptions,
+// or to failed if it is not healthy after health timeout
+func (u *UpgradeRolloutCheck) checkAgent(
+	db *mysql.DB, rollout *mysqlmodel.AgentUpgradeRollout, rolloutAgent *mysqlmodel.AgentUpgradeRolloutAgent,
+	vtap *mysqlmodel.VTap, now time.Time,
+) error {
+	var healthy bool
+	var reason string
+	if vtap == nil {
+		reason = "agent is deleted"
+	} else {
+		revision, state, exceptions := u.getAgentStatus(db.ORGID, vtap, rollout.ExpectedRevision)
+		healthy, reason = checkAgentUpgradeHealth(
+			rollout.ExpectedRevision, revision, state, exceptions, rolloutAgent.OriginalExceptions)
+	}
+
+	var state string
+	if healthy {
+		state = common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_SUCCEEDED
+		reason = ""
+	} else if vtap == nil || rolloutAgent.StartedAt == nil ||
+		now.Sub(*rolloutAgent.StartedAt) > time.Duration(rollout.HealthTimeout)*time.Second {
+		state = common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_FAILED
+		if vtap != nil {
+			reason = fmt.Sprintf("%s after %ds", reason, rollout.HealthTimeout)
+		}
+		log.Warningf("agent (%s) of upgrade rollout (%s) failed: %s", rolloutAgent.AgentName, rollout.Name, reason, db.LogPrefixORGID)
+	} else {
+		return nil
+	}
+	if err := db.Model(rolloutAgent).Updates(map[string]interface{}{
+		"state": state, "finished_at": now, "message": reason,
+	}).Error; err != nil {
+		return err
+	}
+	rolloutAgent.State = state
+	rolloutAgent.FinishedAt = &now
+	rolloutAgent.Message = reason
+	return nil
+}
+
+// getAgentStatus returns the revision, state and exceptions of the agent, the revision and exceptions in vtap cache
+// are reported by the agent if it syncs with this controller, otherwise they are the same as the database.
+func (u *UpgradeRolloutCheck) getAgentStatus(orgID int, vtap *mysqlmodel.VTap, expectedRevision string) (string, int, int64) {
+	revision, state, exceptions := common.GetRealRevision(vtap.Revision), vtap.State, vtap.Exceptions
+	vtapCache := trisolaris.GetORGVTapInfo(orgID).GetVTapCache(vtap.CtrlIP + "-" + vtap.CtrlMac)
+	if vtapCache == nil {
+		return revision, state, exceptions
+	}
+	state = vtapCache.GetVTapState()
+	if common.GetRealRevision(vtapCache.GetRevision()) == expectedRevision {
+		revision = expectedRevision
+		exceptions |= vtapCache.GetExceptions()
+	}
+	return revision, state, exceptions
+}
+
+func checkAgentUpgradeHealth(expectedRevision, revision string, state int, exceptions, originalExceptions int64) (bool, string) {
+	if revision != expectedRevision {
+		return false, fmt.Sprintf("agent revision is %s, expected %s", revision, expectedRevision)
+	}
+	if state != common.VTAP_STATE_NORMAL {
+		stateStr, ok := vtapStateToString[state]
+		if !ok {
+			stateStr = strconv.Itoa(state)
+		}
+		return false, fmt.Sprintf("agent state is %s", stateStr)
+	}
+	// only check the exceptions reported by agent, and ignore those existed before upgrade
+	newExceptions := uint64(exceptions) & uint64(tcommon.VTAP_TRIDENT_EXCEPTIONS_MASK) &^ uint64(originalExceptions)
+	if newExceptions != 0 {
+		return false, fmt.Sprintf("agent has new exceptions 0x%x", newExceptions)
+	}
+	return true, ""
+}
+
+func (u *UpgradeRolloutCheck) startWave(
+	db *mysql.DB, rollout *mysqlmodel.AgentUpgradeRollout, rolloutAgents []mysqlmodel.AgentUpgradeRolloutAgent,
+	lcuuidToVTap map[string]*mysqlmodel.VTap, now time.Time,
+) error {
+	wave := rollout.CurrentWave + 1
+	// the rollout may be paused or cancelled by user at the same time
+	result := db.Model(&mysqlmodel.AgentUpgradeRollout{}).
+		Where("id = ? AND state = ? AND current_wave = ?", rollout.ID, common.AGENT_UPGRADE_ROLLOUT_STATE_RUNNING, rollout.CurrentWave).
+		Update("current_wave", wave)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	log.Infof("agent upgrade rollout (%s) start wave %d/%d", rollout.Name, wave, rollout.WaveCount, db.LogPrefixORGID)
+
+	for i := range rolloutAgents {
+		rolloutAgent := &rolloutAgents[i]
+		if rolloutAgent.Wave != wave || rolloutAgent.State != common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_PENDING {
+			continue
+		}
+		updates := map[string]interface{}{
+			"state": common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_UPGRADING, "started_at": now, "message": "",
+		}
+		vtap, ok := lcuuidToVTap[rolloutAgent.AgentLcuuid]
+		if !ok {
+			updates["state"] = common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_FAILED
+			updates["finished_at"] = now
+			updates["message"] = "agent is deleted"
+		} else if err := u.upgradeAgent(db, vtap, rollout.ImageName); err != nil {
+			log.Warningf("upgrade agent (%s) to image (%s) failed: %s", vtap.Name, rollout.ImageName, err.Error(), db.LogPrefixORGID)
+			updates["state"] = common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_FAILED
+			updates["finished_at"] = now
+			updates["message"] = err.Error()
+		}
+		if err := db.Model(&mysqlmodel.AgentUpgradeRolloutAgent{}).
+			Where("id = ? AND state = ?", rolloutAgent.ID, common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_PENDING).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *UpgradeRolloutCheck) rollback(db *mysql.DB, rollout *mysqlmodel.AgentUpgradeRollout) error {
+	if err := db.Model(&mysqlmodel.AgentUpgradeRolloutAgent{}).
+		Where("rollout_lcuuid = ? AND state = ?", rollout.Lcuuid, common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_PENDING).
+		Update("state", common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_CANCELLED).Error; err != nil {
+		return err
+	}
+	rolloutAgents, lcuuidToVTap, err := u.getRolloutAgents(db, rollout)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var failedCount int
+	for i := range rolloutAgents {
+		rolloutAgent := &rolloutAgents[i]
+		switch rolloutAgent.State {
+		case common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_UPGRADING, common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_SUCCEEDED,
+			common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_FAILED:
+		default:
+			continue
+		}
+		message := fmt.Sprintf("rolled back to image (%s)", rollout.RollbackImageName)
+		if vtap, ok := lcuuidToVTap[rolloutAgent.AgentLcuuid]; !ok {
+			message = "agent is deleted"
+		} else if err := u.upgradeAgent(db, vtap, rollout.RollbackImageName); err != nil {
+			// retry in the next check
+			failedCount++
+			log.Warningf("rollback agent (%s) to image (%s) failed: %s", vtap.Name, rollout.RollbackImageName, err.Error(), db.LogPrefixORGID)
+			continue
+		}
+		if err := db.Model(rolloutAgent).Updates(map[string]interface{}{
+			"state": common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_ROLLED_BACK, "finished_at": now, "message": message,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	if failedCount > 0 {
+		return nil
+	}
+	log.Infof("agent upgrade rollout (%s) rolled back to image (%s)", rollout.Name, rollout.RollbackImageName, db.LogPrefixORGID)
+	return u.updateRolloutState(db, rollout, common.AGENT_UPGRADE_ROLLOUT_STATE_ROLLED_BACK, rollout.Message)
+}
+
+func (u *UpgradeRolloutCheck) updateRolloutState(db *mysql.DB, rollout *mysqlmodel.AgentUpgradeRollout, state, message string) error {
+	return db.Model(&mysqlmodel.AgentUpgradeRollout{}).
+		Where("id = ? AND state = ?", rollout.ID, rollout.State).
+		Updates(map[string]interface{}{"state": state, "message": message}).Error
+}
+
+// upgradeAgent sets the upgrade image of the agent in the vtap cache of the controllers which the agent syncs with,
+// the same as `deepflow-ctl agent-upgrade`
+func (u *UpgradeRolloutCheck) upgradeAgent(db *mysql.DB, vtap *mysqlmodel.VTap, imageName string) error {
+	var controllers []mysqlmodel.Controller
+	if err := mysql.DefaultDB.Where("ip IN (?)", []string{vtap.ControllerIP, vtap.CurControllerIP}).
+		Find(&controllers).Error; err != nil {
+		return err
+	}
+	if len(controllers) == 0 {
+		return fmt.Errorf("controller (%s) of agent not found", vtap.ControllerIP)
+	}
+
+	body := map[string]interface{}{"image_name": imageName}
+	for _, controller := range controllers {
+		ip := controller.IP
+		if controller.PodIP != "" {
+			ip = controller.PodIP
+		}
+		url := fmt.Sprintf("http://%s:%d/v1/upgrade/vtap/%s/", common.GetCURLIP(ip), u.listenPort, vtap.Lcuuid)
+		if _, err := common.CURLPerform("PATCH", url, body, common.WithORGHeader(strconv.Itoa(db.ORGID))); err != nil {
+			return fmt.Errorf("set upgrade image on controller (%s) failed: %s", controller.IP, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vtap
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/khulnasoft/deepflow/server/controller/common"
+	"github.com/khulnasoft/deepflow/server/controller/db/mysql"
+	mysqlmodel "github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+func TestCheckAgentUpgradeHealth(t *testing.T) {
+	tests := []struct {
+		name               string
+		revision           string
+		state              int
+		exceptions         int64
+		originalExceptions int64
+		want               bool
+	}{
+		{"healthy", "100-abc", common.VTAP_STATE_NORMAL, 0, 0, true},
+		{"not upgraded", "99-abb", common.VTAP_STATE_NORMAL, 0, 0, false},
+		{"lost", "100-abc", common.VTAP_STATE_NOT_CONNECTED, 0, 0, false},
+		{"new exception", "100-abc", common.VTAP_STATE_NORMAL, 0x4, 0x1, false},
+		{"existed exception", "100-abc", common.VTAP_STATE_NORMAL, 0x1, 0x1, true},
+		{"controller exception", "100-abc", common.VTAP_STATE_NORMAL, 1 << 40, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := checkAgentUpgradeHealth("100-abc", tt.revision, tt.state, tt.exceptions, tt.originalExceptions)
+			if got != tt.want {
+				t.Errorf("checkAgentUpgradeHealth() = %v (%s), want %v", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func newTestRolloutDB(t *testing.T) *mysql.DB {
+	db, err := gorm.Open(
+		sqlite.Open(filepath.Join(t.TempDir(), "rollout.db")),
+		&gorm.Config{NamingStrategy: schema.NamingStrategy{SingularTable: true}},
+	)
+	if err != nil {
+		t.Fatalf("create sqlite database failed: %s", err.Error())
+	}
+	if err := db.AutoMigrate(&mysqlmodel.AgentUpgradeRollout{}, &mysqlmodel.AgentUpgradeRolloutAgent{}, &mysqlmodel.VTap{}); err != nil {
+		t.Fatalf("migrate failed: %s", err.Error())
+	}
+	return &mysql.DB{DB: db, ORGID: common.DEFAULT_ORG_ID, LogPrefixORGID: logger.NewORGPrefix(common.DEFAULT_ORG_ID)}
+}
+
+func TestCheckStoppedRolloutFinishesWave(t *testing.T) {
+	db := newTestRolloutDB(t)
+	startedAt := time.Now().Add(-time.Minute)
+	for _, state := range []string{common.AGENT_UPGRADE_ROLLOUT_STATE_PAUSED, common.AGENT_UPGRADE_ROLLOUT_STATE_CANCELLED} {
+		db.Create(&mysqlmodel.AgentUpgradeRollout{
+			Lcuuid: state, Name: state, WaveCount: 2, HealthTimeout: 600, State: state, CurrentWave: 1, Message: "by user",
+		})
+		// the agent of the first wave is deleted during upgrading, the second wave is not started
+		db.Create(&mysqlmodel.AgentUpgradeRolloutAgent{
+			RolloutLcuuid: state, AgentLcuuid: state + "-1", Wave: 1,
+			State: common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_UPGRADING, StartedAt: &startedAt,
+		})
+		db.Create(&mysqlmodel.AgentUpgradeRolloutAgent{
+			RolloutLcuuid: state, AgentLcuuid: state + "-2", Wave: 2, State: common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_PENDING,
+		})
+	}
+
+	u := &UpgradeRolloutCheck{}
+	u.check(db)
+
+	for _, state := range []string{common.AGENT_UPGRADE_ROLLOUT_STATE_PAUSED, common.AGENT_UPGRADE_ROLLOUT_STATE_CANCELLED} {
+		var rollout mysqlmodel.AgentUpgradeRollout
+		db.Where("lcuuid = ?", state).First(&rollout)
+		if rollout.State != state || rollout.CurrentWave != 1 || rollout.Message != "by user" {
+			t.Errorf("%s rollout should not be changed, got state %s, wave %d, message %s", state, rollout.State, rollout.CurrentWave, rollout.Message)
+		}
+		var rolloutAgents []mysqlmodel.AgentUpgradeRolloutAgent
+		db.Where("rollout_lcuuid = ?", state).Order("wave").Find(&rolloutAgents)
+		if len(rolloutAgents) != 2 {
+			t.Fatalf("%s rollout should have 2 agents, got %d", state, len(rolloutAgents))
+		}
+		if rolloutAgents[0].State != common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_FAILED || rolloutAgents[0].FinishedAt == nil {
+			t.Errorf("upgrading agent of %s rollout should be checked, got %s", state, rolloutAgents[0].State)
+		}
+		if rolloutAgents[1].State != common.AGENT_UPGRADE_ROLLOUT_AGENT_STATE_PENDING {
+			t.Errorf("pending agent of %s rollout should not be upgraded, got %s", state, rolloutAgents[1].State)
+		}
+	}
+}
@@ -26,6 +26,7 @@ type RecorderConfig struct {
 	ResourceMaxID1               int    `default:"499999" yaml:"resource_max_id_1"`
 
 	LogDebug LogDebugConfig `yaml:"log_debug"`
+	Notifier NotifierConfig `yaml:"notifier"`
 }
 
 func Get() *RecorderConfig {
@@ -41,3 +42,31 @@ type LogDebugConfig struct {
 	DetailEnabled bool     `default:"false" yaml:"detail_enabled"`
 	ResourceTypes []string `default:"" yaml:"resource_type"`
 }
+
+type NotifierConfig struct {
+	Enabled       bool             `default:"false" yaml:"enabled"`
+	QueueSize     int              `default:"10000" yaml:"queue_size"`
+	Timeout       int              `default:"10" yaml:"timeout"`       // unit: second
+	RetryTimes    int              `default:"3" yaml:"retry_times"`    // retry times after the first delivery failed
+	RetryInterval int              `default:"5" yaml:"retry_interval"` // unit: second, doubled after each retry
+	Targets       []NotifierTarget `yaml:"targets"`
+}
+
+type NotifierTarget struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"` // webhook or kafka
+
+	// webhook
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+
+	// kafka
+	Brokers  []string `yaml:"brokers"`
+	Topic    string   `yaml:"topic"`
+	Username string   `yaml:"username"` // SASL/PLAIN is enabled if username is set
+	Password string   `yaml:"password"`
+
+	// filters, empty means all
+	ResourceTypes []string `yaml:"resource_types"`
+	EventTypes    []string `yaml:"event_types"` // add, update, delete or lifecycle
+}
@@ -24,6 +24,7 @@ import (
 	mysqlmodel "github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
 	"github.com/khulnasoft/deepflow/server/controller/recorder/cache/tool"
 	"github.com/khulnasoft/deepflow/server/controller/recorder/common"
+	"github.com/khulnasoft/deepflow/server/controller/recorder/notifier"
 	"github.com/khulnasoft/deepflow/server/libs/eventapi"
 	"github.com/khulnasoft/deepflow/server/libs/queue"
 )
@@ -96,6 +97,8 @@ func (e *EventManagerBase) enqueue(resourceLcuuid string, event *eventapi.Resour
 		rt = common.DEVICE_TYPE_INT_TO_STR[int(event.InstanceType)]
 	}
 	log.Infof("put %s event (lcuuid: %s): %+v into shared queue", rt, resourceLcuuid, event, e.metadata.LogPrefixes)
+	// notify before putting, because the event may be released after consumed
+	notifier.GetNotifier().NotifyLifecycleEvent(rt, resourceLcuuid, event)
 	err := e.Queue.Put(event)
 	if err != nil {
 		log.Error(putEventIntoQueueFailed(rt, err))
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifier
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/khulnasoft/deepflow/server/controller/recorder/pubsub/message"
+	"github.com/khulnasoft/deepflow/server/libs/eventapi"
+)
+
+const (
+	CLOUD_EVENT_SPEC_VERSION      = "1.0"
+	CLOUD_EVENT_CONTENT_TYPE      = "application/cloudevents+json"
+	CLOUD_EVENT_DATA_CONTENT_TYPE = "application/json"
+	CLOUD_EVENT_SOURCE            = "/deepflow/controller/recorder"
+
+	CLOUD_EVENT_TYPE_PREFIX    = "io.deepflow.resource."
+	CLOUD_EVENT_TYPE_ADDED     = CLOUD_EVENT_TYPE_PREFIX + "added"
+	CLOUD_EVENT_TYPE_UPDATED   = CLOUD_EVENT_TYPE_PREFIX + "updated"
+	CLOUD_EVENT_TYPE_DELETED   = CLOUD_EVENT_TYPE_PREFIX + "deleted"
+	CLOUD_EVENT_TYPE_LIFECYCLE = CLOUD_EVENT_TYPE_PREFIX + "lifecycle." // followed by the resource event type, such as create, migrate
+
+	EVENT_TYPE_ADD       = "add"
+	EVENT_TYPE_UPDATE    = "update"
+	EVENT_TYPE_DELETE    = "delete"
+	EVENT_TYPE_LIFECYCLE = "lifecycle"
+)
+
+var EVENT_TYPES = []string{EVENT_TYPE_ADD, EVENT_TYPE_UPDATE, EVENT_TYPE_DELETE, EVENT_TYPE_LIFECYCLE}
+
+// CloudEvent is the structured mode of CloudEvents 1.0 in JSON format,
+// all the extension attributes are lowercase alphanumeric as required by the specification.
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            string      `json:"time"`
+	DataContentType string      `json:"datacontenttype"`
+	Data            interface{} `json:"data"`
+
+	ResourceType string `json:"resourcetype"`
+	ORGID        int    `json:"orgid"`
+	TeamID       int    `json:"teamid,omitempty"`
+	DomainID     int    `json:"domainid,omitempty"`
+	SubDomainID  int    `json:"subdomainid,omitempty"`
+	SoftDelete   bool   `json:"softdelete,omitempty"`
+
+	eventType string // add, update, delete or lifecycle, used to filter events
+}
+
+// ResourceUpdatedData is the data of the resource updated event
+type ResourceUpdatedData struct {
+	ID      int                    `json:"ID"`
+	Lcuuid  string                 `json:"LCUUID"`
+	Changes map[string]FieldChange `json:"CHANGES"`
+}
+
+type FieldChange struct {
+	Old interface{} `json:"OLD"`
+	New interface{} `json:"NEW"`
+}
+
+type resourceItem interface {
+	GetID() int
+	GetLcuuid() string
+}
+
+func newCloudEvent(eventType, ceType, resourceType, subject string, md *message.Metadata, data interface{}) *CloudEvent {
+	e := &CloudEvent{
+		SpecVersion:     CLOUD_EVENT_SPEC_VERSION,
+		ID:              uuid.New().String(),
+		Source:          CLOUD_EVENT_SOURCE,
+		Type:            ceType,
+		Subject:         subject,
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		DataContentType: CLOUD_EVENT_DATA_CONTENT_TYPE,
+		Data:            data,
+		ResourceType:    resourceType,
+		eventType:       eventType,
+	}
+	if md != nil {
+		e.ORGID = md.ORGID
+		e.TeamID = md.TeamID
+		e.DomainID = md.DomainID
+		e.SubDomainID = md.SubDomainID
+		e.SoftDelete = md.SoftDelete
+	}
+	return e
+}
+
+// newItemEvents converts the MySQL items ([]*MT) of resource batch added or deleted message to events, one event per item
+func newItemEvents(eventType, ceType, resourceType string, md *message.Metadata, msg interface{}) []*CloudEvent {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Slice {
+		log.Warningf("%s %s message type %T is not supported", resourceType, eventType, msg)
+		return nil
+	}
+	events := make([]*CloudEvent, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i).Interface()
+		var subject string
+		if ri, ok := item.(resourceItem); ok {
+			subject = ri.GetLcuuid()
+		}
+		events = append(events, newCloudEvent(eventType, ceType, resourceType, subject, md, item))
+	}
+	return events
+}
+
+// newUpdatedEvent converts the FieldsUpdate of resource updated message to event,
+// returns nil if none of the fields is changed.
+func newUpdatedEvent(resourceType string, md *message.Metadata, msg interface{}) *CloudEvent {
+	key, ok := msg.(resourceItem)
+	if !ok {
+		log.Warningf("%s update message type %T is not supported", resourceType, msg)
+		return nil
+	}
+	changes := getFieldChanges(msg)
+	if len(changes) == 0 {
+		return nil
+	}
+	data := &ResourceUpdatedData{
+		ID:      key.GetID(),
+		Lcuuid:  key.GetLcuuid(),
+		Changes: changes,
+	}
+	return newCloudEvent(EVENT_TYPE_UPDATE, CLOUD_EVENT_TYPE_UPDATED, resourceType, key.GetLcuuid(), md, data)
+}
+
+func newLifecycleEvent(resourceType, resourceLcuuid string, event *eventapi.ResourceEvent) *CloudEvent {
+	// copy the event, because it will be released after consumed by the ingester
+	data := *event
+	md := message.NewMetadata(int(event.ORGID), message.MetadataTeamID(int(event.TeamID)))
+	return newCloudEvent(EVENT_TYPE_LIFECYCLE, CLOUD_EVENT_TYPE_LIFECYCLE+event.Type, resourceType, resourceLcuuid, md, &data)
+}
+
+// getFieldChanges returns the changed fields of FieldsUpdate, the key is the field name in upper snake case
+func getFieldChanges(fields interface{}) map[string]FieldChange {
+	v := reflect.ValueOf(fields)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+	t := v.Type()
+	changes := make(map[string]FieldChange)
+	for i := 0; i < v.NumField(); i++ {
+		if !t.Field(i).IsExported() || t.Field(i).Anonymous {
+			continue
+		}
+		fd, ok := v.Field(i).Addr().Interface().(message.FieldDetail)
+		if !ok || !fd.IsDifferent() {
+			continue
+		}
+		changes[toUpperSnakeCase(t.Field(i).Name)] = FieldChange{Old: fd.GetOldValue(), New: fd.GetNewValue()}
+	}
+	return changes
+}
+
+// toUpperSnakeCase converts field name to the format of json tag of MySQL model, such as VPCLcuuid to VPC_LCUUID
+func toUpperSnakeCase(name string) string {
+	rs := []rune(name)
+	var sb strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(rs[i-1]) || unicode.IsDigit(rs[i-1]) || (i+1 < len(rs) && unicode.IsLower(rs[i+1]))) {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
+
+func (e *CloudEvent) key() string {
+	if e.Subject != "" {
+		return e.Subject
+	}
+	return strconv.Itoa(e.ORGID) + "-" + e.ResourceType
+}
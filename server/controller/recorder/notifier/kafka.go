@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/khulnasoft/deepflow/server/controller/recorder/config"
+)
+
+type kafkaPublisher struct {
+	target   config.NotifierTarget
+	timeout  time.Duration
+	producer sarama.SyncProducer
+}
+
+func newKafkaPublisher(target config.NotifierTarget, timeout time.Duration) *kafkaPublisher {
+	return &kafkaPublisher{
+		target:  target,
+		timeout: timeout,
+	}
+}
+
+// the producer is created lazily, so the unavailable brokers do not block the controller starting
+func (p *kafkaPublisher) getProducer() (sarama.SyncProducer, error) {
+	if p.producer != nil {
+		return p.producer, nil
+	}
+	cfg := sarama.NewConfig()
+	cfg.Producer.RequiredAcks = sarama.WaitForAll
+	cfg.Producer.Return.Successes = true
+	// retries are done by the sender
+	cfg.Producer.Retry.Max = 0
+	cfg.Producer.Timeout = p.timeout
+	cfg.Net.DialTimeout = p.timeout
+	cfg.Net.ReadTimeout = p.timeout
+	cfg.Net.WriteTimeout = p.timeout
+	if p.target.Username != "" {
+		cfg.Net.SASL.Enable = true
+		cfg.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		cfg.Net.SASL.User = p.target.Username
+		cfg.Net.SASL.Password = p.target.Password
+	}
+	producer, err := sarama.NewSyncProducer(p.target.Brokers, cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.producer = producer
+	return producer, nil
+}
+
+// Publish sends the event in structured content mode of the CloudEvents Kafka protocol binding,
+// the subject (resource lcuuid) is used as message key to keep the events of a resource in order.
+func (p *kafkaPublisher) Publish(ctx context.Context, e *CloudEvent) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	producer, err := p.getProducer()
+	if err != nil {
+		return retryableError{err}
+	}
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: p.target.Topic,
+		Key:   sarama.StringEncoder(e.key()),
+		Value: sarama.ByteEncoder(value),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("content-type"), Value: []byte(CLOUD_EVENT_CONTENT_TYPE)},
+		},
+	})
+	if err != nil {
+		// recreate the producer at the next time, the connections may be broken
+		p.Close()
+		return retryableError{err}
+	}
+	return nil
+}
+
+func (p *kafkaPublisher) Close() {
+	if p.producer != nil {
+		p.producer.Close()
+		p.producer = nil
+	}
+}
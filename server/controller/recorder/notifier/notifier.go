@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/khulnasoft/deepflow/server/controller/recorder/config"
+	"github.com/khulnasoft/deepflow/server/controller/recorder/pubsub"
+	"github.com/khulnasoft/deepflow/server/controller/recorder/pubsub/message"
+	"github.com/khulnasoft/deepflow/server/libs/eventapi"
+	"github.com/khulnasoft/deepflow/server/libs/logger"
+)
+
+var log = logger.MustGetLogger("recorder.notifier")
+
+var (
+	notifierOnce sync.Once
+	notifier     *Notifier
+)
+
+// Notifier publishes the resource changes of recorder as CloudEvents to the external systems,
+// including resource added, updated, deleted and lifecycle events.
+type Notifier struct {
+	cfg     config.NotifierConfig
+	running bool
+	senders []*sender
+}
+
+func GetNotifier() *Notifier {
+	notifierOnce.Do(func() {
+		notifier = &Notifier{}
+	})
+	return notifier
+}
+
+// Start creates the senders of targets and subscribes the resource changes,
+// it should be called before the recorders start, because subscribing is not thread-safe.
+func (n *Notifier) Start(ctx context.Context, cfg config.NotifierConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	n.cfg = cfg
+	names := make(map[string]struct{})
+	for _, target := range cfg.Targets {
+		if _, ok := names[target.Name]; ok || target.Name == "" {
+			return fmt.Errorf("name (%s) of notifier target is empty or duplicated", target.Name)
+		}
+		names[target.Name] = struct{}{}
+		for _, et := range target.EventTypes {
+			if !slices.Contains(EVENT_TYPES, et) {
+				return fmt.Errorf("event type (%s) of notifier target (%s) is not supported, only support %v", et, target.Name, EVENT_TYPES)
+			}
+		}
+		s, err := newSender(cfg, target)
+		if err != nil {
+			return err
+		}
+		n.senders = append(n.senders, s)
+	}
+
+	for resourceType := range pubsub.GetManager().TypeToPubSub {
+		if resourceType == pubsub.PubSubTypeDomain || !n.wantResourceType(resourceType) {
+			continue
+		}
+		newResourceSubscriber(resourceType, n).subscribe()
+	}
+	for _, s := range n.senders {
+		go s.run(ctx)
+	}
+	n.running = true
+	log.Infof("recorder notifier started with %d targets", len(n.senders))
+	return nil
+}
+
+func (n *Notifier) wantResourceType(resourceType string) bool {
+	for _, s := range n.senders {
+		if s.wantResourceType(resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Notifier) notify(events ...*CloudEvent) {
+	for _, e := range events {
+		for _, s := range n.senders {
+			if s.match(e) {
+				s.put(e)
+			}
+		}
+	}
+}
+
+// NotifyLifecycleEvent publishes the resource event which is put into the shared queue of ingester,
+// the event will be copied, so it can be released after called.
+func (n *Notifier) NotifyLifecycleEvent(resourceType, resourceLcuuid string, event *eventapi.ResourceEvent) {
+	if !n.running || !n.wantResourceType(resourceType) {
+		return
+	}
+	n.notify(newLifecycleEvent(resourceType, resourceLcuuid, event))
+}
+
+// GetDeliveryStatus returns the delivery status of all targets, or the target of the name if it is not empty
+func (n *Notifier) GetDeliveryStatus(name string) []DeliveryStatus {
+	result := make([]DeliveryStatus, 0, len(n.senders))
+	for _, s := range n.senders {
+		if name != "" && s.target.Name != name {
+			continue
+		}
+		result = append(result, s.getStatus())
+	}
+	return result
+}
+
+// resourceSubscriber subscribes the resource changes of a resource type, because the resource type is not in the message
+type resourceSubscriber struct {
+	resourceType string
+	n            *Notifier
+}
+
+func newResourceSubscriber(resourceType string, n *Notifier) *resourceSubscriber {
+	return &resourceSubscriber{
+		resourceType: resourceType,
+		n:            n,
+	}
+}
+
+func (s *resourceSubscriber) subscribe() {
+	pubsub.Subscribe(s.resourceType, pubsub.TopicResourceBatchAddedMySQL, s)
+	pubsub.Subscribe(s.resourceType, pubsub.TopicResourceUpdatedFields, s)
+	pubsub.Subscribe(s.resourceType, pubsub.TopicResourceBatchDeletedMySQL, s)
+}
+
+// OnResourceBatchAdded implements interface ResourceBatchAddedSubscriber in recorder/pubsub/subscriber.go
+func (s *resourceSubscriber) OnResourceBatchAdded(md *message.Metadata, msg interface{}) {
+	s.n.notify(newItemEvents(EVENT_TYPE_ADD, CLOUD_EVENT_TYPE_ADDED, s.resourceType, md, msg)...)
+}
+
+// OnResourceUpdated implements interface ResourceUpdatedSubscriber in recorder/pubsub/subscriber.go
+func (s *resourceSubscriber) OnResourceUpdated(md *message.Metadata, msg interface{}) {
+	if e := newUpdatedEvent(s.resourceType, md, msg); e != nil {
+		s.n.notify(e)
+	}
+}
+
+// OnResourceBatchDeleted implements interface ResourceBatchDeletedSubscriber in recorder/pubsub/subscriber.go
+func (s *resourceSubscriber) OnResourceBatchDeleted(md *message.Metadata, msg interface{}) {
+	s.n.notify(newItemEvents(EVENT_TYPE_DELETE, CLOUD_EVENT_TYPE_DELETED, s.resourceType, md, msg)...)
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mysqlmodel "github.com/khulnasoft/deepflow/server/controller/db/mysql/model"
+	"github.com/khulnasoft/deepflow/server/controller/recorder/config"
+	"github.com/khulnasoft/deepflow/server/controller/recorder/pubsub/message"
+	"github.com/khulnasoft/deepflow/server/libs/eventapi"
+)
+
+func TestToUpperSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"Name":         "NAME",
+		"VPCLcuuid":    "VPC_LCUUID",
+		"LaunchServer": "LAUNCH_SERVER",
+		"IP":           "IP",
+		"HostID":       "HOST_ID",
+		"PodGroupID":   "POD_GROUP_ID",
+	} {
+		assert.Equal(t, want, toUpperSnakeCase(name))
+	}
+}
+
+func TestNewUpdatedEvent(t *testing.T) {
+	md := message.NewMetadata(1, message.MetadataDomainID(2))
+	fields := &message.VMFieldsUpdate{}
+	fields.SetID(10)
+	fields.SetLcuuid("vm-lcuuid")
+	assert.Nil(t, newUpdatedEvent("vm", md, fields), "no field changed")
+
+	fields.Name.Set("old", "new")
+	fields.HostID.Set(1, 2)
+	e := newUpdatedEvent("vm", md, fields)
+	assert.NotNil(t, e)
+	assert.Equal(t, CLOUD_EVENT_TYPE_UPDATED, e.Type)
+	assert.Equal(t, "vm-lcuuid", e.Subject)
+	assert.Equal(t, 1, e.ORGID)
+	assert.Equal(t, 2, e.DomainID)
+	data := e.Data.(*ResourceUpdatedData)
+	assert.Equal(t, 10, data.ID)
+	assert.Equal(t, map[string]FieldChange{
+		"NAME":    {Old: "old", New: "new"},
+		"HOST_ID": {Old: 1, New: 2},
+	}, data.Changes)
+}
+
+func TestNewItemEvents(t *testing.T) {
+	md := message.NewMetadata(1, message.MetadataSoftDelete(true))
+	items := []*mysqlmodel.VM{
+		{Base: mysqlmodel.Base{ID: 1, Lcuuid: "vm-1"}, Name: "vm-1"},
+		{Base: mysqlmodel.Base{ID: 2, Lcuuid: "vm-2"}, Name: "vm-2"},
+	}
+	events := newItemEvents(EVENT_TYPE_DELETE, CLOUD_EVENT_TYPE_DELETED, "vm", md, items)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "vm-2", events[1].Subject)
+	assert.True(t, events[1].SoftDelete)
+
+	b, err := json.Marshal(events[0])
+	assert.Nil(t, err)
+	var result map[string]interface{}
+	assert.Nil(t, json.Unmarshal(b, &result))
+	assert.Equal(t, CLOUD_EVENT_SPEC_VERSION, result["specversion"])
+	assert.Equal(t, CLOUD_EVENT_TYPE_DELETED, result["type"])
+	assert.Equal(t, "vm", result["resourcetype"])
+	assert.Equal(t, "vm-1", result["data"].(map[string]interface{})["NAME"])
+	assert.NotContains(t, result, "eventType")
+}
+
+func TestNewLifecycleEvent(t *testing.T) {
+	event := &eventapi.ResourceEvent{Type: eventapi.RESOURCE_EVENT_TYPE_MIGRATE, InstanceID: 1, ORGID: 1, TeamID: 3}
+	e := newLifecycleEvent("vm", "vm-1", event)
+	event.InstanceID = 2
+	assert.Equal(t, CLOUD_EVENT_TYPE_LIFECYCLE+eventapi.RESOURCE_EVENT_TYPE_MIGRATE, e.Type)
+	assert.Equal(t, EVENT_TYPE_LIFECYCLE, e.eventType)
+	assert.Equal(t, 3, e.TeamID)
+	assert.Equal(t, uint32(1), e.Data.(*eventapi.ResourceEvent).InstanceID, "event should be copied")
+}
+
+func TestSenderMatch(t *testing.T) {
+	s, err := newSender(config.NotifierConfig{QueueSize: 1}, config.NotifierTarget{
+		Name: "test", Type: TARGET_TYPE_WEBHOOK, URL: "http://127.0.0.1",
+		ResourceTypes: []string{"vm"}, EventTypes: []string{EVENT_TYPE_LIFECYCLE},
+	})
+	assert.Nil(t, err)
+	assert.True(t, s.match(&CloudEvent{ResourceType: "vm", eventType: EVENT_TYPE_LIFECYCLE}))
+	assert.False(t, s.match(&CloudEvent{ResourceType: "vm", eventType: EVENT_TYPE_ADD}))
+	assert.False(t, s.match(&CloudEvent{ResourceType: "pod", eventType: EVENT_TYPE_LIFECYCLE}))
+
+	s.put(&CloudEvent{})
+	s.put(&CloudEvent{})
+	assert.Equal(t, uint64(1), s.getStatus().Dropped)
+
+	_, err = newSender(config.NotifierConfig{}, config.NotifierTarget{Name: "test", Type: "mq"})
+	assert.NotNil(t, err)
+}
+
+func TestSenderDeliver(t *testing.T) {
+	var requests int32
+	statusCodes := []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusBadRequest}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := atomic.AddInt32(&requests, 1)
+		assert.Equal(t, CLOUD_EVENT_CONTENT_TYPE, r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+		w.WriteHeader(statusCodes[i-1])
+	}))
+	defer server.Close()
+
+	s, err := newSender(config.NotifierConfig{QueueSize: 10, Timeout: 5, RetryTimes: 3}, config.NotifierTarget{
+		Name: "test", Type: TARGET_TYPE_WEBHOOK, URL: server.URL, Headers: map[string]string{"Authorization": "Bearer abc"},
+	})
+	assert.Nil(t, err)
+
+	// 503 is retried
+	s.deliver(context.Background(), &CloudEvent{ID: "1"})
+	status := s.getStatus()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, uint64(1), status.Delivered)
+	assert.Equal(t, uint64(1), status.Retried)
+
+	// 400 is not retried
+	s.deliver(context.Background(), &CloudEvent{ID: "2", Subject: "vm-1"})
+	status = s.getStatus()
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	assert.Equal(t, uint64(1), status.Failed)
+	assert.Len(t, status.RecentFailures, 1)
+	assert.Equal(t, "2", status.RecentFailures[0].EventID)
+	assert.Equal(t, 1, status.RecentFailures[0].Attempts)
+}
+
+func TestSenderRecentFailures(t *testing.T) {
+	s, _ := newSender(config.NotifierConfig{}, config.NotifierTarget{Name: "test", Type: TARGET_TYPE_WEBHOOK, URL: "http://127.0.0.1"})
+	for i := 0; i < RECENT_FAILURES_COUNT+5; i++ {
+		s.onFailed(&CloudEvent{Subject: string(rune('a' + i))}, 1, assert.AnError)
+	}
+	status := s.getStatus()
+	assert.Len(t, status.RecentFailures, RECENT_FAILURES_COUNT)
+	assert.Equal(t, string(rune('a'+RECENT_FAILURES_COUNT+4)), status.RecentFailures[0].Subject)
+	assert.Equal(t, string(rune('a'+5)), status.RecentFailures[RECENT_FAILURES_COUNT-1].Subject)
+}
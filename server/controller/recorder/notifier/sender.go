@@ -0,0 +1,269 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/khulnasoft/deepflow/server/controller/recorder/config"
+)
+
+const (
+	TARGET_TYPE_WEBHOOK = "webhook"
+	TARGET_TYPE_KAFKA   = "kafka"
+
+	MAX_RETRY_INTERVAL    = 5 * time.Minute
+	RECENT_FAILURES_COUNT = 20
+)
+
+type publisher interface {
+	Publish(ctx context.Context, e *CloudEvent) error
+	Close()
+}
+
+// retryableError means the delivery may succeed if retried, such as network errors and 5xx responses
+type retryableError struct {
+	error
+}
+
+func (e retryableError) Unwrap() error {
+	return e.error
+}
+
+func isRetryable(err error) bool {
+	var re retryableError
+	return errors.As(err, &re)
+}
+
+// DeliveryStatus is the delivery status of a target since the controller started
+type DeliveryStatus struct {
+	Name             string            `json:"NAME"`
+	Type             string            `json:"TYPE"`
+	Endpoint         string            `json:"ENDPOINT"`
+	Pending          int               `json:"PENDING"`
+	Delivered        uint64            `json:"DELIVERED"`
+	Failed           uint64            `json:"FAILED"`
+	Dropped          uint64            `json:"DROPPED"` // dropped because the queue is full
+	Retried          uint64            `json:"RETRIED"`
+	LastDeliveredAt  string            `json:"LAST_DELIVERED_AT"`
+	LastFailedAt     string            `json:"LAST_FAILED_AT"`
+	LastError        string            `json:"LAST_ERROR"`
+	RecentFailures   []DeliveryFailure `json:"RECENT_FAILURES"`
+	ResourceTypes    []string          `json:"RESOURCE_TYPES"`
+	EventTypes       []string          `json:"EVENT_TYPES"`
+	recentFailureIdx int
+}
+
+type DeliveryFailure struct {
+	EventID      string `json:"EVENT_ID"`
+	EventType    string `json:"EVENT_TYPE"`
+	ResourceType string `json:"RESOURCE_TYPE"`
+	Subject      string `json:"SUBJECT"`
+	Attempts     int    `json:"ATTEMPTS"`
+	Error        string `json:"ERROR"`
+	FailedAt     string `json:"FAILED_AT"`
+}
+
+// sender delivers the events matching the filters to a target in order, with retries
+type sender struct {
+	cfg           config.NotifierConfig
+	target        config.NotifierTarget
+	resourceTypes map[string]struct{}
+	eventTypes    map[string]struct{}
+	publisher     publisher
+	queue         chan *CloudEvent
+
+	mutex  sync.Mutex
+	status DeliveryStatus
+}
+
+func newSender(cfg config.NotifierConfig, target config.NotifierTarget) (*sender, error) {
+	var p publisher
+	var endpoint string
+	switch target.Type {
+	case TARGET_TYPE_WEBHOOK:
+		if target.URL == "" {
+			return nil, fmt.Errorf("url of webhook target (%s) is empty", target.Name)
+		}
+		p = newWebhookPublisher(target, time.Duration(cfg.Timeout)*time.Second)
+		endpoint = target.URL
+	case TARGET_TYPE_KAFKA:
+		if len(target.Brokers) == 0 || target.Topic == "" {
+			return nil, fmt.Errorf("brokers or topic of kafka target (%s) is empty", target.Name)
+		}
+		p = newKafkaPublisher(target, time.Duration(cfg.Timeout)*time.Second)
+		endpoint = fmt.Sprintf("%v/%s", target.Brokers, target.Topic)
+	default:
+		return nil, fmt.Errorf("type (%s) of target (%s) is not supported, only support %s and %s",
+			target.Type, target.Name, TARGET_TYPE_WEBHOOK, TARGET_TYPE_KAFKA)
+	}
+
+	s := &sender{
+		cfg:           cfg,
+		target:        target,
+		resourceTypes: make(map[string]struct{}),
+		eventTypes:    make(map[string]struct{}),
+		publisher:     p,
+		queue:         make(chan *CloudEvent, cfg.QueueSize),
+		status: DeliveryStatus{
+			Name:          target.Name,
+			Type:          target.Type,
+			Endpoint:      endpoint,
+			ResourceTypes: target.ResourceTypes,
+			EventTypes:    target.EventTypes,
+		},
+	}
+	for _, rt := range target.ResourceTypes {
+		s.resourceTypes[rt] = struct{}{}
+	}
+	for _, et := range target.EventTypes {
+		s.eventTypes[et] = struct{}{}
+	}
+	return s, nil
+}
+
+func (s *sender) wantResourceType(resourceType string) bool {
+	if len(s.resourceTypes) == 0 {
+		return true
+	}
+	_, ok := s.resourceTypes[resourceType]
+	return ok
+}
+
+func (s *sender) match(e *CloudEvent) bool {
+	if !s.wantResourceType(e.ResourceType) {
+		return false
+	}
+	if len(s.eventTypes) == 0 {
+		return true
+	}
+	_, ok := s.eventTypes[e.eventType]
+	return ok
+}
+
+// put never blocks the recorder, the event is dropped if the queue is full
+func (s *sender) put(e *CloudEvent) {
+	select {
+	case s.queue <- e:
+	default:
+		s.mutex.Lock()
+		s.status.Dropped++
+		dropped := s.status.Dropped
+		s.mutex.Unlock()
+		if dropped%1000 == 1 {
+			log.Warningf("queue of target (%s) is full, %d events dropped", s.target.Name, dropped)
+		}
+	}
+}
+
+func (s *sender) run(ctx context.Context) {
+	defer s.publisher.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-s.queue:
+			s.deliver(ctx, e)
+		}
+	}
+}
+
+func (s *sender) deliver(ctx context.Context, e *CloudEvent) {
+	interval := time.Duration(s.cfg.RetryInterval) * time.Second
+	var err error
+	attempts := 0
+	for {
+		attempts++
+		err = s.publisher.Publish(ctx, e)
+		if err == nil {
+			s.onDelivered()
+			return
+		}
+		if !isRetryable(err) || attempts > s.cfg.RetryTimes {
+			break
+		}
+		log.Infof("deliver event (id: %s) to target (%s) failed: %s, retry after %s", e.ID, s.target.Name, err.Error(), interval)
+		s.onRetried()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > MAX_RETRY_INTERVAL {
+			interval = MAX_RETRY_INTERVAL
+		}
+	}
+	log.Errorf("deliver event (id: %s, type: %s, subject: %s) to target (%s) failed after %d attempts: %s",
+		e.ID, e.Type, e.Subject, s.target.Name, attempts, err.Error())
+	s.onFailed(e, attempts, err)
+}
+
+func (s *sender) onDelivered() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status.Delivered++
+	s.status.LastDeliveredAt = time.Now().Format(time.RFC3339)
+}
+
+func (s *sender) onRetried() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status.Retried++
+}
+
+func (s *sender) onFailed(e *CloudEvent, attempts int, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().Format(time.RFC3339)
+	s.status.Failed++
+	s.status.LastFailedAt = now
+	s.status.LastError = err.Error()
+	failure := DeliveryFailure{
+		EventID:      e.ID,
+		EventType:    e.Type,
+		ResourceType: e.ResourceType,
+		Subject:      e.Subject,
+		Attempts:     attempts,
+		Error:        err.Error(),
+		FailedAt:     now,
+	}
+	// keep the recent failures in a ring buffer
+	if len(s.status.RecentFailures) < RECENT_FAILURES_COUNT {
+		s.status.RecentFailures = append(s.status.RecentFailures, failure)
+	} else {
+		s.status.RecentFailures[s.status.recentFailureIdx] = failure
+	}
+	s.status.recentFailureIdx = (s.status.recentFailureIdx + 1) % RECENT_FAILURES_COUNT
+}
+
+// getStatus returns a copy of the status, the recent failures are sorted from the latest to the oldest
+func (s *sender) getStatus() DeliveryStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	status := s.status
+	status.Pending = len(s.queue)
+	n := len(s.status.RecentFailures)
+	status.RecentFailures = make([]DeliveryFailure, 0, n)
+	for i := 1; i <= n; i++ {
+		status.RecentFailures = append(status.RecentFailures, s.status.RecentFailures[(s.status.recentFailureIdx-i+n)%n])
+	}
+	return status
+}
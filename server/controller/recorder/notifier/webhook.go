@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/khulnasoft/deepflow/server/controller/recorder/config"
+)
+
+type webhookPublisher struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newWebhookPublisher(target config.NotifierTarget, timeout time.Duration) *webhookPublisher {
+	return &webhookPublisher{
+		url:     target.URL,
+		headers: target.Headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Publish posts the event in structured content mode of the CloudEvents HTTP protocol binding
+func (p *webhookPublisher) Publish(ctx context.Context, e *CloudEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", CLOUD_EVENT_CONTENT_TYPE)
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return retryableError{err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		err = fmt.Errorf("%s returned HTTP status %s: %s", p.url, resp.Status, respBody)
+		// the other 4xx errors are caused by the request itself, retrying is useless
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout {
+			return retryableError{err}
+		}
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (p *webhookPublisher) Close() {
+	p.client.CloseIdleConnections()
+}
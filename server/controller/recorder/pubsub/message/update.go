@@ -58,6 +58,14 @@ func (f *Fields[T]) GetFields() interface{} {
 	return f.data
 }
 
+// FieldDetail is implemented by all the fields of FieldsUpdate except Key,
+// used when the type of the field value is unknown, such as serializing the changes.
+type FieldDetail interface {
+	IsDifferent() bool
+	GetOldValue() interface{}
+	GetNewValue() interface{}
+}
+
 type fieldDetail[T any] struct {
 	different bool
 	new       T
@@ -97,6 +105,14 @@ func (d *fieldDetail[T]) SetOld(old T) {
 	d.old = old
 }
 
+func (d *fieldDetail[T]) GetOldValue() interface{} {
+	return d.old
+}
+
+func (d *fieldDetail[T]) GetNewValue() interface{} {
+	return d.new
+}
+
 type MySQLData[MT constraint.MySQLModel] struct {
 	new *MT
 	old *MT
@@ -64,7 +64,7 @@ func (c *ChPodK8sLabel) onResourceUpdated(sourceID int, fieldsUpdate *message.Po
 				if oldV != v {
 					updateKey = K8sLabelKey{ID: sourceID, Key: k}
 					updateInfo["value"] = v
-					db.Where("id = ? and `key` = ?", sourceID, k).First(&chItem)
+					db.Where(map[string]interface{}{"id": sourceID, "key": k}).First(&chItem)
 					if chItem.ID == 0 {
 						keysToAdd = append(keysToAdd, K8sLabelKey{ID: sourceID, Key: k})
 						targetsToAdd = append(targetsToAdd, mysqlmodel.ChPodK8sLabel{
@@ -62,7 +62,7 @@ func (c *ChPodNSCloudTag) onResourceUpdated(sourceID int, fieldsUpdate *message.
 				if oldV != v {
 					key := CloudTagKey{ID: sourceID, Key: k}
 					updateInfo["value"] = v
-					db.Where("id = ? and `key` = ?", sourceID, k).First(&chItem)
+					db.Where(map[string]interface{}{"id": sourceID, "key": k}).First(&chItem)
 					if chItem.ID == 0 {
 						keysToAdd = append(keysToAdd, key)
 						targetsToAdd = append(targetsToAdd, mysqlmodel.ChPodNSCloudTag{
@@ -63,7 +63,7 @@ func (c *ChPodServiceK8sAnnotation) onResourceUpdated(sourceID int, fieldsUpdate
 				if oldV != v {
 					key := K8sAnnotationKey{ID: sourceID, Key: k}
 					var chItem mysqlmodel.ChPodServiceK8sAnnotation
-					db.Where("id = ? and `key` = ?", sourceID, k).First(&chItem)
+					db.Where(map[string]interface{}{"id": sourceID, "key": k}).First(&chItem)
 					if chItem.ID == 0 {
 						keysToAdd = append(keysToAdd, key)
 						targetsToAdd = append(targetsToAdd, mysqlmodel.ChPodServiceK8sAnnotation{
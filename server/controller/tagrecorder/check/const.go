@@ -207,6 +207,10 @@ var CH_IP_RESOURCE_TAGS = []string{
 }
 
 const (
+	SQL_SOURCE            = "SOURCE(%s)\n"
+	SQL_SOURCE_MYSQL      = "MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at')"
+	SQL_SOURCE_POSTGRESQL = "POSTGRESQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at')"
+
 	CREATE_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
 		"(\n" +
 		"    `id` UInt64,\n" +
@@ -214,7 +218,7 @@ const (
 		"    `icon_id` Int64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_VPC_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -225,7 +229,7 @@ const (
 		"    `uid` String\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_TAP_TYPE_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -234,7 +238,7 @@ const (
 		"    `name` String\n" +
 		")\n" +
 		"PRIMARY KEY value\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_VTAP_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -245,7 +249,7 @@ const (
 		"    `icon_id` Int64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_DEVICE_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -257,7 +261,7 @@ const (
 		"    `uid` String\n" +
 		")\n" +
 		"PRIMARY KEY devicetype, deviceid\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_VTAP_PORT_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -273,7 +277,7 @@ const (
 		"    `icon_id` Int64\n" +
 		")\n" +
 		"PRIMARY KEY vtap_id, tap_port\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_PORT_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -289,7 +293,7 @@ const (
 		"    `port_pod_service_name` String\n" +
 		")\n" +
 		"PRIMARY KEY id, protocol, port\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_IP_PORT_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -306,7 +310,7 @@ const (
 		"    `port_pod_service_name` String\n" +
 		")\n" +
 		"PRIMARY KEY ip, subnet_id, protocol, port\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_DEVICE_PORT_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -323,7 +327,7 @@ const (
 		"    `port_pod_service_name` String\n" +
 		")\n" +
 		"PRIMARY KEY devicetype, deviceid, protocol, port\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_SERVER_PORT_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -332,7 +336,7 @@ const (
 		"    `server_port_name` String\n" +
 		")\n" +
 		"PRIMARY KEY server_port\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_IP_RELATION_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -351,7 +355,7 @@ const (
 		"    `pod_service_name` String\n" +
 		")\n" +
 		"PRIMARY KEY l3_epc_id, ip\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_ID_NAME_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -360,7 +364,7 @@ const (
 		"    `name` String\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_K8S_LABEL_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -372,7 +376,7 @@ const (
 		"    `pod_ns_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id, key\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_K8S_LABELS_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -383,7 +387,7 @@ const (
 		"    `pod_ns_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_IP_RESOURCE_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -432,7 +436,7 @@ const (
 		"    `uid` String\n" +
 		")\n" +
 		"PRIMARY KEY ip, subnet_id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_STRING_ENUM_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -443,7 +447,7 @@ const (
 		"    `description` String\n" +
 		")\n" +
 		"PRIMARY KEY tag_name, value\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_INT_ENUM_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -454,7 +458,7 @@ const (
 		"    `description` String\n" +
 		")\n" +
 		"PRIMARY KEY tag_name, value\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_NODE_TYPE_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -463,7 +467,7 @@ const (
 		"    `node_type` String\n" +
 		")\n" +
 		"PRIMARY KEY resource_type\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_CLOUD_TAG_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -473,7 +477,7 @@ const (
 		"    `value` String\n" +
 		")\n" +
 		"PRIMARY KEY id, key\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_CLOUD_TAGS_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -482,7 +486,7 @@ const (
 		"    `cloud_tags` String\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_OS_APP_TAG_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -492,7 +496,7 @@ const (
 		"    `value` String\n" +
 		")\n" +
 		"PRIMARY KEY pid, key\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_OS_APP_TAGS_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -501,7 +505,7 @@ const (
 		"    `os_app_tags` String\n" +
 		")\n" +
 		"PRIMARY KEY pid\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 
@@ -514,7 +518,7 @@ const (
 		"    `pod_ns_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id, key\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_K8S_ANNOTATIONS_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -525,7 +529,7 @@ const (
 		"    `pod_ns_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_K8S_ENV_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -537,7 +541,7 @@ const (
 		"    `pod_ns_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id, key\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_K8S_ENVS_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -548,7 +552,7 @@ const (
 		"    `pod_ns_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_PROMETHEUS_LABEL_NAME_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -557,7 +561,7 @@ const (
 		"    `name` String\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_PROMETHEUS_METRIC_APP_LABEL_LAYOUT_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -568,7 +572,7 @@ const (
 		"    `app_label_column_index` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_APP_LABEL_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -578,7 +582,7 @@ const (
 		"    `label_value_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY label_name_id, label_value_id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_TARGET_LABEL_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -589,7 +593,7 @@ const (
 		"    `target_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY metric_id, label_name_id, target_id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_PROMETHEUS_TARGET_LABEL_LAYOUT_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -599,7 +603,7 @@ const (
 		"    `target_label_values` String\n" +
 		")\n" +
 		"PRIMARY KEY target_id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 	CREATE_POD_NS_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -610,7 +614,7 @@ const (
 		"    `pod_cluster_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_POD_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -625,7 +629,7 @@ const (
 		"    `pod_group_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_POD_SERVICE_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -636,7 +640,7 @@ const (
 		"    `pod_ns_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_CHOST_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -647,7 +651,7 @@ const (
 		"    `l3_epc_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_CH_POD_GROUP_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -660,7 +664,7 @@ const (
 		"    `pod_ns_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_CH_GPROCESS_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -672,7 +676,7 @@ const (
 		"    `l3_epc_id` Int64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(FLAT())"
 	CREATE_POLICY_DICTIONARY_SQL = "CREATE DICTIONARY %s.%s\n" +
@@ -683,7 +687,7 @@ const (
 		"    `name` String\n" +
 		")\n" +
 		"PRIMARY KEY tunnel_type, acl_gid\n" +
-		"SOURCE(MYSQL(PORT %s USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n" +
+		SQL_SOURCE +
 		"LIFETIME(MIN 30 MAX %d)\n" +
 		"LAYOUT(COMPLEX_KEY_HASHED())"
 )
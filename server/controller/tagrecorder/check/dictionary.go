@@ -34,8 +34,19 @@ import (
 
 	"github.com/khulnasoft/deepflow/server/controller/common"
 	"github.com/khulnasoft/deepflow/server/controller/db/clickhouse"
+	mysqlcommon "github.com/khulnasoft/deepflow/server/controller/db/mysql/common"
 )
 
+// getSourceSQL returns the source of the dictionary filled into SQL_SOURCE, which reads the table from
+// the metadata database through the clickhouse table engine matching the database type.
+func getSourceSQL(dbType, port, userName, userPassword, replicaSQL, database, table string) string {
+	source := SQL_SOURCE_MYSQL
+	if dbType == mysqlcommon.DB_TYPE_POSTGRESQL {
+		source = SQL_SOURCE_POSTGRESQL
+	}
+	return fmt.Sprintf(source, port, userName, userPassword, replicaSQL, database, table, table)
+}
+
 func (c *TagRecorder) UpdateChDictionary() {
 	log.Info("tagrecorder update ch dictionary")
 	kubeconfig := c.cfg.Kubeconfig
@@ -228,7 +239,8 @@ func (c *TagRecorder) UpdateChDictionary() {
 							chTable := "ch_" + strings.TrimSuffix(dictName, "_map")
 							createSQL := CREATE_SQL_MAP[dictName]
 							mysqlPortStr := strconv.Itoa(int(c.cfg.MySqlCfg.Port))
-							createSQL = fmt.Sprintf(createSQL, c.cfg.ClickHouseCfg.Database, dictName, mysqlPortStr, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, c.cfg.MySqlCfg.Database, chTable, chTable, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
+							sourceSQL := getSourceSQL(c.cfg.MySqlCfg.Type, mysqlPortStr, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, c.cfg.MySqlCfg.Database, chTable)
+							createSQL = fmt.Sprintf(createSQL, c.cfg.ClickHouseCfg.Database, dictName, sourceSQL, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
 							log.Infof("create dictionary %s", dictName)
 							log.Info(createSQL)
 							_, err = connect.Exec(createSQL)
@@ -262,7 +274,8 @@ func (c *TagRecorder) UpdateChDictionary() {
 							}
 							createSQL := CREATE_SQL_MAP[dictName]
 							mysqlPortStr := strconv.Itoa(int(c.cfg.MySqlCfg.Port))
-							createSQL = fmt.Sprintf(createSQL, c.cfg.ClickHouseCfg.Database, dictName, mysqlPortStr, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, c.cfg.MySqlCfg.Database, chTable, chTable, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
+							sourceSQL := getSourceSQL(c.cfg.MySqlCfg.Type, mysqlPortStr, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, c.cfg.MySqlCfg.Database, chTable)
+							createSQL = fmt.Sprintf(createSQL, c.cfg.ClickHouseCfg.Database, dictName, sourceSQL, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
 							// In the new version of CK (version after 23.8), when ‘SHOW CREATE DICTIONARY’ does not display plain text password information, the password is fixedly displayed as ‘[HIDDEN]’, and password comparison needs to be repair.
 							checkDictSQL := strings.Replace(dictSQL[0], "[HIDDEN]", c.cfg.MySqlCfg.UserPassword, 1)
 							if createSQL == checkDictSQL {
@@ -214,7 +214,9 @@ var CH_IP_RESOURCE_TAGS = []string{
 const (
 	SQL_CREATE_DICT               = "CREATE DICTIONARY %s.%s\n"
 	SQL_REPLICA                   = "REPLICA (HOST '%s' PRIORITY 1)"
-	SQL_SOURCE_MYSQL              = "SOURCE(MYSQL(PORT %d USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at'))\n"
+	SQL_SOURCE                    = "SOURCE(%s)\n"
+	SQL_SOURCE_MYSQL              = "MYSQL(PORT %d USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at')"
+	SQL_SOURCE_POSTGRESQL         = "POSTGRESQL(PORT %d USER '%s' PASSWORD '%s' %s DB %s TABLE %s INVALIDATE_QUERY 'select(select updated_at from %s order by updated_at desc limit 1) as updated_at')"
 	SQL_LIFETIME                  = "LIFETIME(MIN 30 MAX %d)\n"
 	SQL_LAYOUT_FLAT               = "LAYOUT(FLAT())"
 	SQL_LAYOUT_COMPLEX_KEY_HASHED = "LAYOUT(COMPLEX_KEY_HASHED())"
@@ -229,7 +231,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_AZ_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -241,7 +243,7 @@ const (
 		"    `domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_REGION_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -251,7 +253,7 @@ const (
 		"    `icon_id` Int64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_VPC_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -264,7 +266,7 @@ const (
 		"    `domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_TAP_TYPE_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -273,7 +275,7 @@ const (
 		"    `name` String\n" +
 		")\n" +
 		"PRIMARY KEY value\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_VTAP_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -290,7 +292,7 @@ const (
 		"    `pod_node_name` String\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_DEVICE_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -307,7 +309,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY devicetype, deviceid\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_VTAP_PORT_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -328,7 +330,7 @@ const (
 		"    `team_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY vtap_id, tap_port\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_PORT_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -344,7 +346,7 @@ const (
 		"    `port_pod_service_name` String\n" +
 		")\n" +
 		"PRIMARY KEY id, protocol, port\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_IP_PORT_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -361,7 +363,7 @@ const (
 		"    `port_pod_service_name` String\n" +
 		")\n" +
 		"PRIMARY KEY ip, subnet_id, protocol, port\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_DEVICE_PORT_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -378,7 +380,7 @@ const (
 		"    `port_pod_service_name` String\n" +
 		")\n" +
 		"PRIMARY KEY devicetype, deviceid, protocol, port\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_SERVER_PORT_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -387,7 +389,7 @@ const (
 		"    `server_port_name` String\n" +
 		")\n" +
 		"PRIMARY KEY server_port\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_IP_RELATION_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -407,7 +409,7 @@ const (
 		"    `team_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY l3_epc_id, ip\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_ID_NAME_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -416,7 +418,7 @@ const (
 		"    `name` String\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_NPB_TUNNEL_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -426,7 +428,7 @@ const (
 		"    `team_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_POD_NODE_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -440,7 +442,7 @@ const (
 		"    `pod_cluster_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_POD_INGRESS_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -454,7 +456,7 @@ const (
 		"    `pod_cluster_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_LB_LISTENER_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -464,7 +466,7 @@ const (
 		"    `team_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_K8S_LABEL_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -479,7 +481,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id, key\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_K8S_LABELS_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -493,7 +495,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_IP_RESOURCE_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -542,7 +544,7 @@ const (
 		"    `uid` String\n" +
 		")\n" +
 		"PRIMARY KEY ip, subnet_id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_STRING_ENUM_SQL = SQL_CREATE_DICT +
@@ -553,7 +555,7 @@ const (
 		"    `description` String\n" +
 		")\n" +
 		"PRIMARY KEY tag_name, value\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_INT_ENUM_SQL = SQL_CREATE_DICT +
@@ -564,7 +566,7 @@ const (
 		"    `description` String\n" +
 		")\n" +
 		"PRIMARY KEY tag_name, value\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_NODE_TYPE_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -573,7 +575,7 @@ const (
 		"    `node_type` String\n" +
 		")\n" +
 		"PRIMARY KEY resource_type\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_CLOUD_TAG_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -585,7 +587,7 @@ const (
 		"    `domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id, key\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_POD_NS_CLOUD_TAG_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -598,7 +600,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id, key\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_CLOUD_TAGS_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -609,7 +611,7 @@ const (
 		"    `domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_POD_NS_CLOUD_TAGS_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -621,7 +623,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_OS_APP_TAG_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -634,7 +636,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY pid, key\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_OS_APP_TAGS_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -646,7 +648,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY pid\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 
@@ -662,7 +664,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id, key\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_K8S_ANNOTATIONS_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -676,7 +678,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_K8S_ENV_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -691,7 +693,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id, key\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_K8S_ENVS_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -705,7 +707,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_PROMETHEUS_LABEL_NAME_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -714,7 +716,7 @@ const (
 		"    `name` String\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_PROMETHEUS_METRIC_APP_LABEL_LAYOUT_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -725,7 +727,7 @@ const (
 		"    `app_label_column_index` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_APP_LABEL_SQL = SQL_CREATE_DICT +
@@ -735,7 +737,7 @@ const (
 		"    `label_value_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY label_name_id, label_value_id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_TARGET_LABEL_SQL = SQL_CREATE_DICT +
@@ -746,7 +748,7 @@ const (
 		"    `target_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY metric_id, label_name_id, target_id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_PROMETHEUS_TARGET_LABEL_LAYOUT_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -756,7 +758,7 @@ const (
 		"    `target_label_values` String\n" +
 		")\n" +
 		"PRIMARY KEY target_id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_POD_NS_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -770,7 +772,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_POD_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -788,7 +790,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_POD_SERVICE_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -802,7 +804,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_CHOST_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -817,7 +819,7 @@ const (
 		"    `domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_POD_GROUP_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -833,7 +835,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_GPROCESS_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -848,7 +850,7 @@ const (
 		"    `sub_domain_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 	CREATE_POLICY_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -860,7 +862,7 @@ const (
 		"    `team_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY tunnel_type, acl_gid\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_COMPLEX_KEY_HASHED
 	CREATE_AlARM_POLICY_DICTIONARY_SQL = SQL_CREATE_DICT +
@@ -871,7 +873,7 @@ const (
 		"    `team_id` UInt64\n" +
 		")\n" +
 		"PRIMARY KEY id\n" +
-		SQL_SOURCE_MYSQL +
+		SQL_SOURCE +
 		SQL_LIFETIME +
 		SQL_LAYOUT_FLAT
 )
@@ -147,6 +147,16 @@ func (c *Dictionary) Update() {
 	return
 }
 
+// getSourceSQL returns the source of the dictionary filled into SQL_SOURCE, which reads the table from
+// the metadata database through the clickhouse table engine matching the database type.
+func getSourceSQL(dbType string, port uint32, userName, userPassword, replicaSQL, database, table string) string {
+	source := SQL_SOURCE_MYSQL
+	if dbType == mysqlCommon.DB_TYPE_POSTGRESQL {
+		source = SQL_SOURCE_POSTGRESQL
+	}
+	return fmt.Sprintf(source, port, userName, userPassword, replicaSQL, database, table, table)
+}
+
 func (c *Dictionary) update(clickHouseCfg *clickhouse.ClickHouseConfig) {
 	var mysqlDatabaseName string
 	var ckDatabaseName string
@@ -313,7 +323,8 @@ func (c *Dictionary) update(clickHouseCfg *clickhouse.ClickHouseConfig) {
 			dictName := dict.(string)
 			chTable := "ch_" + strings.TrimSuffix(dictName, "_map")
 			createSQL := CREATE_SQL_MAP[dictName]
-			createSQL = fmt.Sprintf(createSQL, ckDatabaseName, dictName, mysqlPort, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, mysqlDatabaseName, chTable, chTable, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
+			sourceSQL := getSourceSQL(c.cfg.MySqlCfg.Type, mysqlPort, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, mysqlDatabaseName, chTable)
+			createSQL = fmt.Sprintf(createSQL, ckDatabaseName, dictName, sourceSQL, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
 			log.Infof("create dictionary %s", dictName, logger.NewORGPrefix(orgID))
 			log.Info(createSQL, logger.NewORGPrefix(orgID))
 			_, err = ckDb.Exec(createSQL)
@@ -344,7 +355,8 @@ func (c *Dictionary) update(clickHouseCfg *clickhouse.ClickHouseConfig) {
 				break
 			}
 			createSQL := CREATE_SQL_MAP[dictName]
-			createSQL = fmt.Sprintf(createSQL, ckDatabaseName, dictName, mysqlPort, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, mysqlDatabaseName, chTable, chTable, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
+			sourceSQL := getSourceSQL(c.cfg.MySqlCfg.Type, mysqlPort, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, mysqlDatabaseName, chTable)
+			createSQL = fmt.Sprintf(createSQL, ckDatabaseName, dictName, sourceSQL, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
 			// In the new version of CK (version after 23.8), when ‘SHOW CREATE DICTIONARY’ does not display plain text password information, the password is fixedly displayed as ‘[HIDDEN]’, and password comparison needs to be repair.
 			checkDictSQL := strings.Replace(dictSQL[0], "[HIDDEN]", c.cfg.MySqlCfg.UserPassword, 1)
 			if createSQL == checkDictSQL {
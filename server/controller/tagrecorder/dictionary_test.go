@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tagrecorder
+
+import (
+	"fmt"
+	"testing"
+
+	mysqlcommon "github.com/khulnasoft/deepflow/server/controller/db/mysql/common"
+)
+
+func TestGetSourceSQL(t *testing.T) {
+	replicaSQL := fmt.Sprintf(SQL_REPLICA, "127.0.0.1")
+	invalidateQuery := "INVALIDATE_QUERY 'select(select updated_at from ch_region order by updated_at desc limit 1) as updated_at'"
+	testCases := []struct {
+		name   string
+		dbType string
+		want   string
+	}{
+		{
+			name:   "mysql",
+			dbType: mysqlcommon.DB_TYPE_MYSQL,
+			want:   "MYSQL(PORT 30130 USER 'root' PASSWORD 'deepflow' REPLICA (HOST '127.0.0.1' PRIORITY 1) DB deepflow TABLE ch_region " + invalidateQuery + ")",
+		},
+		{
+			name:   "postgresql",
+			dbType: mysqlcommon.DB_TYPE_POSTGRESQL,
+			want:   "POSTGRESQL(PORT 30130 USER 'root' PASSWORD 'deepflow' REPLICA (HOST '127.0.0.1' PRIORITY 1) DB deepflow TABLE ch_region " + invalidateQuery + ")",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := getSourceSQL(tc.dbType, 30130, "root", "deepflow", replicaSQL, "deepflow", "ch_region")
+			if got != tc.want {
+				t.Errorf("getSourceSQL() = %q, want %q", got, tc.want)
+			}
+			createSQL := fmt.Sprintf(CREATE_SQL_MAP[CH_DICTIONARY_REGION], "flow_tag", CH_DICTIONARY_REGION, got, 60)
+			wantCreateSQL := "CREATE DICTIONARY flow_tag.region_map\n" +
+				"(\n" +
+				"    `id` UInt64,\n" +
+				"    `name` String,\n" +
+				"    `icon_id` Int64\n" +
+				")\n" +
+				"PRIMARY KEY id\n" +
+				"SOURCE(" + tc.want + ")\n" +
+				"LIFETIME(MIN 30 MAX 60)\n" +
+				"LAYOUT(FLAT())"
+			if createSQL != wantCreateSQL {
+				t.Errorf("create dictionary sql = %q, want %q", createSQL, wantCreateSQL)
+			}
+		})
+	}
+}
@@ -284,18 +284,6 @@ func isPodVTap(vtapType int) bool {
 	}
 }
 
-func getRealRevision(revision string) string {
-	var realRevision string
-	splitStr := strings.Split(revision, " ")
-	if len(splitStr) == 2 {
-		realRevision = splitStr[1]
-	} else {
-		realRevision = revision
-	}
-
-	return realRevision
-}
-
 func (e *VTapEvent) GetFailedResponse(in *api.SyncRequest, gVTapInfo *vtap.VTapInfo) *api.SyncResponse {
 	return &api.SyncResponse{
 		Status:        &STATUS_FAILED,
@@ -386,7 +374,7 @@ func (e *VTapEvent) Sync(ctx context.Context, in *api.SyncRequest) (*api.SyncRes
 	}
 
 	// trident上报的revision与升级trident_revision一致后，则取消预期的`expected_revision`
-	if vtapCache.GetExpectedRevision() == getRealRevision(in.GetRevision()) {
+	if vtapCache.GetExpectedRevision() == GetRealRevision(in.GetRevision()) {
 		vtapCache.UpdateUpgradeInfo("", "")
 	}
 	if uint32(vtapCache.GetBootTime()) != in.GetBootTime() {
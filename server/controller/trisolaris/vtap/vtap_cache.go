@@ -583,6 +583,10 @@ func (c *VTapCache) GetVTapType() int {
 	return c.vTapType
 }
 
+func (c *VTapCache) GetVTapState() int {
+	return c.state
+}
+
 func (c *VTapCache) GetVTapEnabled() int {
 	return c.enable
 }
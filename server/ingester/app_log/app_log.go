@@ -20,10 +20,13 @@ import (
 	"strconv"
 	"time"
 
+	logging "github.com/op/go-logging"
+
 	"github.com/khulnasoft/deepflow/server/ingester/app_log/config"
 	"github.com/khulnasoft/deepflow/server/ingester/app_log/dbwriter"
 	"github.com/khulnasoft/deepflow/server/ingester/app_log/decoder"
 	dropletqueue "github.com/khulnasoft/deepflow/server/ingester/droplet/queue"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters"
 	"github.com/khulnasoft/deepflow/server/ingester/ingesterctl"
 	"github.com/khulnasoft/deepflow/server/ingester/pkg/ckwriter"
 	"github.com/khulnasoft/deepflow/server/libs/datatype"
@@ -33,6 +36,8 @@ import (
 	"github.com/khulnasoft/deepflow/server/libs/receiver"
 )
 
+var log = logging.MustGetLogger("app_log")
+
 type ApplicationLogger struct {
 	Config      *config.Config
 	Ckwriter    *ckwriter.CKWriter
@@ -51,6 +56,7 @@ func NewApplicationLogger(
 	config *config.Config,
 	recv *receiver.Receiver,
 	platformDataManager *grpc.PlatformDataManager,
+	exporters *exporters.Exporters,
 ) (*ApplicationLogger, error) {
 	manager := dropletqueue.NewManager(ingesterctl.INGESTERCTL_APPLICATION_LOG_QUEUE)
 
@@ -58,15 +64,16 @@ func NewApplicationLogger(
 	if err != nil {
 		return nil, err
 	}
-	sysLogger, err := NewLogger(datatype.MESSAGE_TYPE_SYSLOG, config, manager, recv, platformDataManager, ckwriter)
+	// the loggers export to the same datasource, so they should use different exporter indexes
+	sysLogger, err := NewLogger(datatype.MESSAGE_TYPE_SYSLOG, config, manager, recv, platformDataManager, ckwriter, exporters, 0)
 	if err != nil {
 		return nil, err
 	}
-	agentLogger, err := NewLogger(datatype.MESSAGE_TYPE_AGENT_LOG, config, manager, recv, platformDataManager, ckwriter)
+	agentLogger, err := NewLogger(datatype.MESSAGE_TYPE_AGENT_LOG, config, manager, recv, platformDataManager, ckwriter, exporters, config.DecoderQueueCount)
 	if err != nil {
 		return nil, err
 	}
-	appLogger, err := NewLogger(datatype.MESSAGE_TYPE_APPLICATION_LOG, config, manager, recv, platformDataManager, ckwriter)
+	appLogger, err := NewLogger(datatype.MESSAGE_TYPE_APPLICATION_LOG, config, manager, recv, platformDataManager, ckwriter, exporters, 2*config.DecoderQueueCount)
 	if err != nil {
 		return nil, err
 	}
@@ -102,9 +109,15 @@ func NewLogger(
 	recv *receiver.Receiver,
 	platformDataManager *grpc.PlatformDataManager,
 	ckwriter *ckwriter.CKWriter,
+	exporters *exporters.Exporters,
+	exporterIndexBase int,
 ) (*Logger, error) {
 
 	queueCount := config.DecoderQueueCount
+	if exporters != nil && exporterIndexBase+queueCount > queue.MAX_QUEUE_COUNT {
+		log.Warningf("the exporter index of %s exceeds %d, disable exporting", msgType, queue.MAX_QUEUE_COUNT)
+		exporters = nil
+	}
 	decodeQueues := manager.NewQueues(
 		"1-receive-to-decode-"+msgType.String(),
 		config.DecoderQueueSize,
@@ -131,6 +144,8 @@ func NewLogger(
 			queue.QueueReader(decodeQueues.FixedMultiQueue[i]),
 			logWriter,
 			platformDatas[i],
+			exporters,
+			exporterIndexBase+i,
 			config,
 		)
 	}
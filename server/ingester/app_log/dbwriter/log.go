@@ -17,16 +17,25 @@
 package dbwriter
 
 import (
+	"encoding/hex"
 	"fmt"
 	"net"
+	"reflect"
 	"strings"
 	"sync/atomic"
+	"unsafe"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
 
 	basecommon "github.com/khulnasoft/deepflow/server/ingester/common"
+	exportercommon "github.com/khulnasoft/deepflow/server/ingester/exporters/common"
 	"github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	utag "github.com/khulnasoft/deepflow/server/ingester/exporters/universal_tag"
 	"github.com/khulnasoft/deepflow/server/ingester/flow_tag"
 	"github.com/khulnasoft/deepflow/server/libs/ckdb"
 	"github.com/khulnasoft/deepflow/server/libs/pool"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
 )
 
 const (
@@ -65,15 +74,15 @@ type ApplicationLogStore struct {
 	Time      uint32 `json:"time" category:"$tag" sub:"flow_info"` // s
 	Timestamp int64  `json:"timestamp" category:"$tag" sub:"flow_info"`
 	_id       uint64 `json:"_id" category:"$tag" sub:"flow_info"`
-	Type      string
+	Type      string `json:"_type" category:"$tag" sub:"flow_info"`
 
-	TraceID    string
-	SpanID     string
-	TraceFlags uint32
+	TraceID    string `json:"trace_id" category:"$tag" sub:"tracing_info"`
+	SpanID     string `json:"span_id" category:"$tag" sub:"tracing_info"`
+	TraceFlags uint32 `json:"trace_flags" category:"$tag" sub:"tracing_info"`
 
-	SeverityNumber uint8 // numerical value of the severity(also known as log level id)
+	SeverityNumber uint8 `json:"severity_number" category:"$tag" sub:"tracing_info" enumfile:"severity_number"` // numerical value of the severity(also known as log level id)
 
-	Body string
+	Body string `json:"body" category:"$tag" sub:"tracing_info"`
 
 	AppService string `json:"app_service" category:"$tag" sub:"service_info"` // service name
 
@@ -84,7 +93,7 @@ type ApplicationLogStore struct {
 	L3EpcID      int32  `json:"l3_epc_id" category:"$tag" sub:"universal_tag"`
 	HostID       uint16 `json:"host_id" category:"$tag" sub:"universal_tag"`
 	PodID        uint32 `json:"pod_id" category:"$tag" sub:"universal_tag"`
-	PodNodeID    uint32 `json:"host_node_id" category:"$tag" sub:"universal_tag"`
+	PodNodeID    uint32 `json:"pod_node_id" category:"$tag" sub:"universal_tag"`
 	PodNSID      uint16 `json:"pod_ns_id" category:"$tag" sub:"universal_tag"`
 	PodClusterID uint16 `json:"pod_cluster_id" category:"$tag" sub:"universal_tag"`
 	PodGroupID   uint32 `json:"pod_group_id" category:"$tag" sub:"universal_tag"`
@@ -94,7 +103,7 @@ type ApplicationLogStore struct {
 	SubnetID     uint16 `json:"subnet_id" category:"$tag" sub:"universal_tag"`
 	IsIPv4       bool   `json:"is_ipv4" category:"$tag" sub:"universal_tag"`
 	IP4          uint32 `json:"ip4" category:"$tag" sub:"network_layer" to_string:"IPv4String"`
-	IP6          net.IP `json:"ip6" category:"$tag" sub:"network_layer"  to_string:"IPv6String"`
+	IP6          net.IP `json:"ip6" category:"$tag" sub:"network_layer" to_string:"IPv6String" data_type:"net.IP"`
 
 	// Not stored, only determines which database to store in.
 	// When Orgid is 0 or 1, it is stored in database 'event', otherwise stored in '<OrgId>_event'.
@@ -174,7 +183,91 @@ func (l *ApplicationLogStore) Release() {
 }
 
 func (l *ApplicationLogStore) DataSource() uint32 {
-	return uint32(config.MAX_DATASOURCE_ID)
+	return uint32(config.APPLICATION_LOG)
+}
+
+func (l *ApplicationLogStore) EncodeTo(protocol config.ExportProtocol, utags *utag.UniversalTagsManager, cfg *config.ExporterCfg) (interface{}, error) {
+	tags := l.QueryUniversalTags(utags)
+	k8sLabels := utags.QueryCustomK8sLabels(l.OrgId, l.PodID)
+	switch protocol {
+	case config.PROTOCOL_OTLP:
+		return l.EncodeToOtlp(cfg, tags, k8sLabels), nil
+	case config.PROTOCOL_KAFKA, config.PROTOCOL_HTTP, config.PROTOCOL_FILE:
+		return exportercommon.EncodeToJson(l, int(l.DataSource()), cfg, tags, tags, k8sLabels, k8sLabels), nil
+	default:
+		return nil, fmt.Errorf("application log unsupport export to %s", protocol)
+	}
+}
+
+func (l *ApplicationLogStore) EncodeToOtlp(cfg *config.ExporterCfg, tags *utag.UniversalTags, k8sLabels utag.Labels) plog.ResourceLogsSlice {
+	logsSlice, logRecord := exportercommon.EncodeToOtlpLogs(l, int(l.DataSource()), cfg, tags, tags, k8sLabels, k8sLabels)
+	// the body and trace context are the fields of otlp log record, no need to export as attributes
+	logAttrs := logRecord.Attributes()
+	logAttrs.Remove("df.tracing_info.body")
+	logAttrs.Remove("df.tracing_info.trace_id")
+	logAttrs.Remove("df.tracing_info.span_id")
+	logAttrs.Remove("df.tracing_info.trace_flags")
+
+	logRecord.Body().SetStr(l.Body)
+	logRecord.SetSeverityNumber(SeverityToOtlp(l.SeverityNumber))
+	logRecord.SetSeverityText(logRecord.SeverityNumber().String())
+	var traceID pcommon.TraceID
+	if hexToBytes(l.TraceID, traceID[:]) {
+		logRecord.SetTraceID(traceID)
+	}
+	var spanID pcommon.SpanID
+	if hexToBytes(l.SpanID, spanID[:]) {
+		logRecord.SetSpanID(spanID)
+	}
+	logRecord.SetFlags(plog.LogRecordFlags(l.TraceFlags))
+	return logsSlice
+}
+
+// SeverityToOtlp converts the severity number which is the same as the value of log/syslog
+// (defined in app_log/decoder) to the otlp severity number
+func SeverityToOtlp(severity uint8) plog.SeverityNumber {
+	switch severity {
+	case 2: // FATAL
+		return plog.SeverityNumberFatal
+	case 3: // ERROR
+		return plog.SeverityNumberError
+	case 4: // WARN
+		return plog.SeverityNumberWarn
+	case 5: // INFO
+		return plog.SeverityNumberInfo
+	case 6: // DEBUG
+		return plog.SeverityNumberDebug
+	case 7: // TRACE
+		return plog.SeverityNumberTrace
+	default:
+		return plog.SeverityNumberUnspecified
+	}
+}
+
+// hexToBytes decodes the hex string to dst, returns false if the length of string does not match
+func hexToBytes(s string, dst []byte) bool {
+	if len(s) != hex.EncodedLen(len(dst)) {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+func (l *ApplicationLogStore) QueryUniversalTags(utags *utag.UniversalTagsManager) *utag.UniversalTags {
+	return utags.QueryUniversalTags(l.OrgId,
+		l.RegionID, l.AZID, l.HostID, l.PodNSID, l.PodClusterID, l.SubnetID, l.AgentID,
+		l.L3DeviceType, l.AutoServiceType, l.AutoInstanceType,
+		l.L3DeviceID, l.AutoServiceID, l.AutoInstanceID, l.PodNodeID, l.PodGroupID, l.PodID, uint32(l.L3EpcID), l.GProcessID, l.ServiceID,
+		l.IsIPv4, l.IP4, l.IP6,
+	)
+}
+
+func (l *ApplicationLogStore) GetFieldValueByOffsetAndKind(offset uintptr, kind reflect.Kind, dataType utils.DataType) interface{} {
+	return utils.GetValueByOffsetAndKind(uintptr(unsafe.Pointer(l)), offset, kind, dataType)
+}
+
+func (l *ApplicationLogStore) TimestampUs() int64 {
+	return l.Timestamp
 }
 
 var LogCounter uint32
@@ -30,6 +30,9 @@ import (
 	"github.com/khulnasoft/deepflow/server/ingester/app_log/config"
 	"github.com/khulnasoft/deepflow/server/ingester/app_log/dbwriter"
 	ingestercommon "github.com/khulnasoft/deepflow/server/ingester/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters"
+	exporterscommon "github.com/khulnasoft/deepflow/server/ingester/exporters/common"
+	exportersconfig "github.com/khulnasoft/deepflow/server/ingester/exporters/config"
 	"github.com/khulnasoft/deepflow/server/libs/ckdb"
 	"github.com/khulnasoft/deepflow/server/libs/codec"
 	"github.com/khulnasoft/deepflow/server/libs/datatype"
@@ -115,6 +118,8 @@ type Decoder struct {
 	platformData      *grpc.PlatformInfoTable
 	inQueue           queue.QueueReader
 	logWriter         *dbwriter.AppLogWriter
+	exporters         *exporters.Exporters
+	exporterIndex     int
 	debugEnabled      bool
 	config            *config.Config
 	appLogEntrysCache []AppLogEntry
@@ -130,6 +135,8 @@ func NewDecoder(
 	inQueue queue.QueueReader,
 	logWriter *dbwriter.AppLogWriter,
 	platformData *grpc.PlatformInfoTable,
+	exporters *exporters.Exporters,
+	exporterIndex int,
 	config *config.Config,
 ) *Decoder {
 	return &Decoder{
@@ -139,6 +146,8 @@ func NewDecoder(
 		inQueue:           inQueue,
 		debugEnabled:      log.IsEnabledFor(logging.DEBUG),
 		logWriter:         logWriter,
+		exporters:         exporters,
+		exporterIndex:     exporterIndex,
 		appLogEntrysCache: make([]AppLogEntry, 0),
 		config:            config,
 		counter:           &Counter{},
@@ -162,6 +171,7 @@ func (d *Decoder) Run() {
 		n := d.inQueue.Gets(buffer)
 		for i := 0; i < n; i++ {
 			if buffer[i] == nil {
+				d.export(nil)
 				continue
 			}
 			d.counter.InCount++
@@ -183,6 +193,13 @@ func (d *Decoder) Run() {
 	}
 }
 
+func (d *Decoder) export(item exporterscommon.ExportItem) {
+	if d.exporters == nil {
+		return
+	}
+	d.exporters.Put(uint32(exportersconfig.APPLICATION_LOG), d.exporterIndex, item)
+}
+
 func (d *Decoder) handleAgentLog(agentId uint16, decoder *codec.SimpleDecoder) {
 	for !decoder.IsEnd() {
 		bytes := decoder.ReadBytes()
@@ -300,6 +317,7 @@ func (d *Decoder) WriteAgentLog(agentId uint16, bs []byte) error {
 	s.AttributeNames = append(s.AttributeNames, "module")
 	s.AttributeValues = append(s.AttributeValues, string(columns[4]))
 
+	d.export(s)
 	d.logWriter.Write(s)
 	return nil
 }
@@ -447,6 +465,7 @@ func (d *Decoder) WriteAppLog(agentId uint16, l *AppLogEntry) error {
 	s.AutoInstanceID, s.AutoInstanceType = ingestercommon.GetAutoInstance(s.PodID, 0, s.PodNodeID, s.L3DeviceID, uint32(s.SubnetID), uint8(s.L3DeviceType), s.L3EpcID)
 	s.AutoServiceID, s.AutoServiceType = ingestercommon.GetAutoService(s.ServiceID, s.PodGroupID, 0, s.PodNodeID, s.L3DeviceID, uint32(s.SubnetID), uint8(s.L3DeviceType), podGroupType, s.L3EpcID)
 
+	d.export(s)
 	d.logWriter.Write(s)
 	return nil
 }
@@ -375,4 +375,16 @@ var ColumnAdd66 = []*ColumnAdds{
 		ColumnNames: []string{"auto_instance_type", "auto_service_type"},
 		ColumnType:  ckdb.UInt8,
 	},
+	{
+		Dbs:         []string{"flow_log"},
+		Tables:      []string{"l4_flow_log", "l4_flow_log_local", "l7_flow_log", "l7_flow_log_local"},
+		ColumnNames: []string{"country_0", "country_1", "city_0", "city_1", "asn_org_0", "asn_org_1"},
+		ColumnType:  ckdb.LowCardinalityString,
+	},
+	{
+		Dbs:         []string{"flow_log"},
+		Tables:      []string{"l4_flow_log", "l4_flow_log_local", "l7_flow_log", "l7_flow_log_local"},
+		ColumnNames: []string{"asn_0", "asn_1"},
+		ColumnType:  ckdb.UInt32,
+	},
 }
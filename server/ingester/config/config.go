@@ -65,6 +65,8 @@ const (
 	EnvRunningMode                  = "DEEPFLOW_SERVER_RUNNING_MODE"
 	RunningModeStandalone           = "STANDALONE"
 	DefaultByconityStoragePolicy    = "cnch_default_s3"
+	DefaultCKWriterSpillDir         = "/var/lib/deepflow/ckwriter-spill"
+	DefaultCKWriterSpillMaxSize     = 1024 // MB
 	// the maximum number of endpoints for a server corresponding to ClickHouse;
 	//   any endpoints beyond this limit will be ignored
 	MaxClickHouseEndpointsPerServer = 128
@@ -138,6 +140,39 @@ type CKWriterConfig struct {
 	FlushTimeout int `yaml:"flush-timeout"`
 }
 
+// batches failed to write to ClickHouse are saved in the directory and written again when ClickHouse recovers
+type CKWriterSpill struct {
+	Enabled bool     `yaml:"enabled"`
+	Dir     string   `yaml:"dir"`
+	MaxSize int      `yaml:"max-size"`    // MB, for each table
+	Tables  []string `yaml:"tables,flow"` // <database>.<table>, empty means all tables
+}
+
+func (s *CKWriterSpill) Validate() {
+	if s.Dir == "" {
+		s.Dir = DefaultCKWriterSpillDir
+	}
+	if s.MaxSize <= 0 {
+		s.MaxSize = DefaultCKWriterSpillMaxSize
+	}
+}
+
+// TableEnabled checks whether the table of database should spill failed batches
+func (s *CKWriterSpill) TableEnabled(database, table string) bool {
+	if !s.Enabled {
+		return false
+	}
+	if len(s.Tables) == 0 {
+		return true
+	}
+	for _, t := range s.Tables {
+		if t == database+"."+table || t == database {
+			return true
+		}
+	}
+	return false
+}
+
 type CKDB struct {
 	External            bool     `yaml:"external"`
 	Type                string   `yaml:"type"`
@@ -177,6 +212,7 @@ type Config struct {
 	TCPReaderBuffer          int             `yaml:"tcp-reader-buffer"`
 	CKDiskMonitor            CKDiskMonitor   `yaml:"ck-disk-monitor"`
 	ColdStorage              CKDBColdStorage `yaml:"ckdb-cold-storage"`
+	CKWriterSpill            CKWriterSpill   `yaml:"ckwriter-spill"`
 	ckdbColdStorages         map[string]*ckdb.ColdStorage
 	NodeIP                   string `yaml:"node-ip"`
 	GrpcBufferSize           int    `yaml:"grpc-buffer-size"`
@@ -337,6 +373,7 @@ func (c *Config) Validate() error {
 		return nil
 	}
 	c.CKDiskMonitor.Validate()
+	c.CKWriterSpill.Validate()
 
 	if c.CKDB.Type == "" {
 		c.CKDB.Type = ckdb.CKDBTypeClickhouse
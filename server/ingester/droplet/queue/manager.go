@@ -136,7 +136,7 @@ func (m *Manager) NewQueuesUnmarshal(name string, size, count, userCount int, un
 	return q
 }
 
-func sendCmdOnly(moduleId debug.ModuleId, operate int, arg *bytes.Buffer) (*net.UDPConn, *bytes.Buffer, error) {
+func sendCmdOnly(moduleId debug.ModuleId, operate int, arg *bytes.Buffer) (debug.ClientConn, *bytes.Buffer, error) {
 	conn, result, err := debug.SendToServer(moduleId, debug.ModuleOperate(operate), arg)
 	if err != nil {
 		return conn, nil, err
@@ -158,7 +158,7 @@ func sendCmd(moduleId debug.ModuleId, operate int, arg *bytes.Buffer, out interf
 	return true
 }
 
-func queueOperate(moduleId debug.ModuleId, name string, operate int) *net.UDPConn {
+func queueOperate(moduleId debug.ModuleId, name string, operate int) debug.ClientConn {
 	buffer := bytes.Buffer{}
 	encoder := gob.NewEncoder(&buffer)
 	if err := encoder.Encode(name); err != nil {
@@ -177,7 +177,7 @@ func isNotTimeout(err error) bool {
 	return !strings.Contains(err.Error(), "timeout")
 }
 
-func recvDebugMsg(moduleId debug.ModuleId, conn *net.UDPConn, name string) {
+func recvDebugMsg(moduleId debug.ModuleId, conn debug.ClientConn, name string) {
 	sigs := make(chan os.Signal, 10)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGKILL, syscall.SIGQUIT, syscall.SIGSTOP)
 	var message string
@@ -63,7 +63,7 @@ type EventStore struct {
 
 	SignalSource     uint8  `json:"signal_source" category:"$tag" sub:"capture_info" enumfile:"perf_event_signal_source"` // Resource / File IO
 	EventType        string `json:"event_type" category:"$tag" sub:"event_info" enumfile:"perf_event_type"`
-	EventDescription string
+	EventDescription string `json:"event_description" category:"$tag" sub:"event_info"`
 	ProcessKName     string `json:"process_kname" category:"$tag" sub:"service_info"` // us
 
 	GProcessID uint32 `json:"gprocess_id" category:"$tag" sub:"universal_tag"`
@@ -73,7 +73,7 @@ type EventStore struct {
 	L3EpcID      int32  `json:"l3_epc_id" category:"$tag" sub:"universal_tag"`
 	HostID       uint16 `json:"host_id" category:"$tag" sub:"universal_tag"`
 	PodID        uint32 `json:"pod_id" category:"$tag" sub:"universal_tag"`
-	PodNodeID    uint32 `json:"host_node_id" category:"$tag" sub:"universal_tag"`
+	PodNodeID    uint32 `json:"pod_node_id" category:"$tag" sub:"universal_tag"`
 	PodNSID      uint16 `json:"pod_ns_id" category:"$tag" sub:"universal_tag"`
 	PodClusterID uint16 `json:"pod_cluster_id" category:"$tag" sub:"universal_tag"`
 	PodGroupID   uint32 `json:"pod_group_id" category:"$tag" sub:"universal_tag"`
@@ -84,7 +84,7 @@ type EventStore struct {
 	SubnetID     uint16 `json:"subnet_id" category:"$tag" sub:"universal_tag"`
 	IsIPv4       bool   `json:"is_ipv4" category:"$tag" sub:"network_layer"`
 	IP4          uint32 `json:"ip4" category:"$tag" sub:"network_layer" to_string:"IPv4String"`
-	IP6          net.IP `json:"ip6" category:"$tag" sub:"network_layer"  to_string:"IPv6String"`
+	IP6          net.IP `json:"ip6" category:"$tag" sub:"network_layer" to_string:"IPv6String" data_type:"net.IP"`
 
 	// Not stored, only determines which database to store in.
 	// When Orgid is 0 or 1, it is stored in database 'event', otherwise stored in '<OrgId>_event'.
@@ -179,15 +179,23 @@ func (e *EventStore) DataSource() uint32 {
 	if e.HasMetrics {
 		return uint32(config.PERF_EVENT)
 	}
-	return uint32(config.MAX_DATASOURCE_ID)
+	return uint32(config.EVENT)
 }
 
 func (e *EventStore) EncodeTo(protocol config.ExportProtocol, utags *utag.UniversalTagsManager, cfg *config.ExporterCfg) (interface{}, error) {
 	switch protocol {
-	case config.PROTOCOL_KAFKA:
+	case config.PROTOCOL_KAFKA, config.PROTOCOL_HTTP, config.PROTOCOL_FILE:
 		tags := e.QueryUniversalTags(utags)
 		k8sLabels := utags.QueryCustomK8sLabels(e.OrgId, e.PodID)
 		return exportercommon.EncodeToJson(e, int(e.DataSource()), cfg, tags, tags, k8sLabels, k8sLabels), nil
+	case config.PROTOCOL_OTLP:
+		tags := e.QueryUniversalTags(utags)
+		k8sLabels := utags.QueryCustomK8sLabels(e.OrgId, e.PodID)
+		logs, logRecord := exportercommon.EncodeToOtlpLogs(e, int(e.DataSource()), cfg, tags, tags, k8sLabels, k8sLabels)
+		// the event description is the body of otlp log record, no need to export as attribute
+		logRecord.Attributes().Remove("df.event_info.event_description")
+		logRecord.Body().SetStr(e.EventDescription)
+		return logs, nil
 	default:
 		return nil, fmt.Errorf("event unsupport export to %s", protocol)
 	}
@@ -89,6 +89,7 @@ func NewDecoder(
 		}
 	}
 	return &Decoder{
+		index:        index,
 		eventType:    eventType,
 		platformData: platformData,
 		inQueue:      inQueue,
@@ -414,6 +415,7 @@ func (d *Decoder) handleResourceEvent(event *eventapi.ResourceEvent) {
 		)
 
 	d.counter.OutCount++
+	d.export(s)
 	d.eventWriter.Write(s)
 }
 
@@ -463,5 +465,6 @@ func (d *Decoder) writeAlertEvent(event *alert_event.AlertEvent) {
 	s.TeamID = uint16(event.GetTeamId())
 	s.UserId = event.GetUserId()
 
+	d.export(s)
 	d.eventWriter.WriteAlertEvent(s)
 }
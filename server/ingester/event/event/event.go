@@ -24,6 +24,8 @@ import (
 	_ "golang.org/x/net/context"
 	_ "google.golang.org/grpc"
 
+	logging "github.com/op/go-logging"
+
 	dropletqueue "github.com/khulnasoft/deepflow/server/ingester/droplet/queue"
 	"github.com/khulnasoft/deepflow/server/ingester/event/common"
 	"github.com/khulnasoft/deepflow/server/ingester/event/config"
@@ -38,6 +40,8 @@ import (
 	"github.com/khulnasoft/deepflow/server/libs/receiver"
 )
 
+var log = logging.MustGetLogger("event")
+
 type Event struct {
 	Config          *config.Config
 	ResourceEventor *Eventor
@@ -54,7 +58,7 @@ type Eventor struct {
 
 func NewEvent(config *config.Config, resourceEventQueue *queue.OverwriteQueue, recv *receiver.Receiver, platformDataManager *grpc.PlatformDataManager, exporters *exporters.Exporters) (*Event, error) {
 	manager := dropletqueue.NewManager(ingesterctl.INGESTERCTL_EVENT_QUEUE)
-	resourceEventor, err := NewResouceEventor(resourceEventQueue, config, platformDataManager.GetMasterPlatformInfoTable())
+	resourceEventor, err := NewResouceEventor(resourceEventQueue, config, platformDataManager.GetMasterPlatformInfoTable(), exporters)
 	if err != nil {
 		return nil, err
 	}
@@ -64,12 +68,12 @@ func NewEvent(config *config.Config, resourceEventQueue *queue.OverwriteQueue, r
 		return nil, err
 	}
 
-	alertEventor, err := NewAlertEventor(config, recv, manager, platformDataManager.GetMasterPlatformInfoTable())
+	alertEventor, err := NewAlertEventor(config, recv, manager, platformDataManager.GetMasterPlatformInfoTable(), exporters)
 	if err != nil {
 		return nil, err
 	}
 
-	k8sEventor, err := NewEventor(common.K8S_EVENT, config, recv, manager, platformDataManager, nil)
+	k8sEventor, err := NewEventor(common.K8S_EVENT, config, recv, manager, platformDataManager, exporters)
 	if err != nil {
 		return nil, err
 	}
@@ -83,18 +87,24 @@ func NewEvent(config *config.Config, resourceEventQueue *queue.OverwriteQueue, r
 	}, nil
 }
 
-func NewResouceEventor(eventQueue *queue.OverwriteQueue, config *config.Config, platformTable *grpc.PlatformInfoTable) (*Eventor, error) {
+func NewResouceEventor(eventQueue *queue.OverwriteQueue, config *config.Config, platformTable *grpc.PlatformInfoTable, exporters *exporters.Exporters) (*Eventor, error) {
 	eventWriter, err := dbwriter.NewEventWriter(common.RESOURCE_EVENT, 0, config)
 	if err != nil {
 		return nil, err
 	}
+	// resource events are exported as the same datasource as k8s events, so the index follows the k8s event decoders
+	index := config.K8sDecoderQueueCount
+	if exporters != nil && index >= libqueue.MAX_QUEUE_COUNT {
+		log.Warningf("the exporter index of resource event exceeds %d, disable exporting", libqueue.MAX_QUEUE_COUNT)
+		exporters = nil
+	}
 	d := decoder.NewDecoder(
-		0,
+		index,
 		common.RESOURCE_EVENT,
 		queue.QueueReader(eventQueue),
 		eventWriter,
 		platformTable,
-		nil,
+		exporters,
 		config,
 	)
 	return &Eventor{
@@ -103,7 +113,7 @@ func NewResouceEventor(eventQueue *queue.OverwriteQueue, config *config.Config,
 	}, nil
 }
 
-func NewAlertEventor(config *config.Config, recv *receiver.Receiver, manager *dropletqueue.Manager, platformTable *grpc.PlatformInfoTable) (*Eventor, error) {
+func NewAlertEventor(config *config.Config, recv *receiver.Receiver, manager *dropletqueue.Manager, platformTable *grpc.PlatformInfoTable, exporters *exporters.Exporters) (*Eventor, error) {
 	eventMsg := datatype.MESSAGE_TYPE_ALERT_EVENT
 	decodeQueues := manager.NewQueues(
 		"1-receive-to-decode-"+eventMsg.String(),
@@ -124,7 +134,7 @@ func NewAlertEventor(config *config.Config, recv *receiver.Receiver, manager *dr
 		queue.QueueReader(decodeQueues.FixedMultiQueue[0]),
 		eventWriter,
 		platformTable,
-		nil,
+		exporters,
 		config,
 	)
 	return &Eventor{
@@ -112,6 +112,98 @@ func writeK8sLabels(sb *strings.Builder, keyName, valueName string, k8sLabels ut
 	sb.WriteString(valuesBuilder.String())
 }
 
+type fieldValue struct {
+	key            string
+	isString       bool
+	isFloat64      bool
+	isStringSlice  bool
+	isFloat64Slice bool
+	str            string
+	float64        float64
+	strs           []string
+	float64s       []float64
+}
+
+// getFieldValue gets the value of the field and translates it by 'to_string', universal tag and enum,
+// returns false if the field is nil or should not be exported
+func getFieldValue(item EncodeItem, structTags *config.StructTags, isMapItem bool, exporterCfg *config.ExporterCfg, uTags0, uTags1 *utag.UniversalTags, v *fieldValue) bool {
+	*v = fieldValue{}
+	value := item.GetFieldValueByOffsetAndKind(structTags.Offset, structTags.DataKind, structTags.DataType)
+	if utils.IsNil(value) {
+		log.Debugf("%s value is nil", structTags.FieldName)
+		return false
+	}
+	if isMapItem && structTags.MapName != "" {
+		v.key = structTags.MapName
+	} else {
+		v.key = structTags.Name
+	}
+	if s, ok := value.(string); ok {
+		v.isString = true
+		v.str = s
+	} else if s, ok := value.([]string); ok {
+		v.isStringSlice = true
+		v.strs = s
+	} else if f, ok := value.([]float64); ok {
+		v.isFloat64Slice = true
+		v.float64s = f
+	} else if ints, ok := value.([]int64); ok {
+		v.isFloat64Slice = true
+		v.float64s = make([]float64, len(ints))
+		for i := range ints {
+			v.float64s[i] = float64(ints[i])
+		}
+	} else if f, fStr, ok := utils.ConvertToFloat64(value); ok {
+		v.isFloat64 = true
+		v.float64 = f
+		v.str = fStr
+	} else {
+		v.isString = true
+		v.str = fmt.Sprintf("%v", value)
+	}
+
+	if structTags.ToStringFuncName != "" {
+		ret := structTags.ToStringFunc.Call([]reflect.Value{reflect.ValueOf(value)})
+		v.str = ret[0].String()
+		v.isString = true
+	} else if structTags.UniversalTagMapID > 0 && !exporterCfg.UniversalTagTranslateToNameDisabled {
+		// skip '_id'
+		if pos := strings.Index(v.key, "_id"); pos != -1 {
+			v.key = (v.key[:pos]) + v.key[pos+3:] // 3 is  length of '_id'
+		}
+		if strings.HasSuffix(structTags.Name, "_1") {
+			v.str = uTags1.GetTagValue(structTags.UniversalTagMapID)
+		} else {
+			v.str = uTags0.GetTagValue(structTags.UniversalTagMapID)
+		}
+		v.isString = true
+	} else if structTags.EnumFile != "" && !exporterCfg.EnumTranslateToNameDisabled {
+		if v.isString {
+			v.str = structTags.EnumStringMap[v.str]
+		} else if v.isFloat64 {
+			v.str = structTags.EnumIntMap[int(v.float64)]
+		}
+		v.isString = true
+	}
+
+	// not export empty tags
+	if !exporterCfg.ExportEmptyTag &&
+		(structTags.CategoryBit&config.TAG) != 0 &&
+		((v.isString && v.str == "") ||
+			(v.isStringSlice && len(v.strs) == 0)) {
+		return false
+	}
+
+	// not export empty metrics
+	if exporterCfg.ExportEmptyMetricsDisabled &&
+		(structTags.CategoryBit&config.METRICS) != 0 &&
+		((v.isString && v.str == "") || (v.isFloat64 && v.float64 == 0) ||
+			(v.isFloat64Slice && len(v.float64s) == 0)) {
+		return false
+	}
+	return true
+}
+
 func EncodeToJson(item EncodeItem, dataSourceId int, exporterCfg *config.ExporterCfg, uTags0, uTags1 *utag.UniversalTags, k8sLabels0, k8sLabels1 utag.Labels) string {
 	var sb = &strings.Builder{}
 	sb.WriteString("{\"datasource\":\"")
@@ -124,110 +216,40 @@ func EncodeToJson(item EncodeItem, dataSourceId int, exporterCfg *config.Exporte
 	}
 
 	isMapItem := config.DataSourceID(dataSourceId).IsMap()
-	var isString, isFloat64, isStringSlice, isFloat64Slice bool
-	var keyStr, valueStr string
-	var valueFloat64 float64
-	var stringSlice []string
-	var float64Slice []float64
-	for _, structTags := range exporterCfg.ExportFieldStructTags[dataSourceId] {
-		isString, isFloat64, isStringSlice, isFloat64Slice = false, false, false, false
-		value := item.GetFieldValueByOffsetAndKind(structTags.Offset, structTags.DataKind, structTags.DataType)
-		if utils.IsNil(value) {
-			log.Debugf("%s value is nil", structTags.FieldName)
-			continue
-		}
-		if isMapItem && structTags.MapName != "" {
-			keyStr = structTags.MapName
-		} else {
-			keyStr = structTags.Name
-		}
-		if v, ok := value.(string); ok {
-			isString = true
-			valueStr = v
-		} else if v, ok := value.([]string); ok {
-			isStringSlice = true
-			stringSlice = v
-		} else if v, ok := value.([]float64); ok {
-			isFloat64Slice = true
-			float64Slice = v
-		} else if v, vStr, ok := utils.ConvertToFloat64(value); ok {
-			isFloat64 = true
-			valueFloat64 = v
-			valueStr = vStr
-		} else {
-			isString = true
-			valueStr = fmt.Sprintf("%v", value)
-		}
-
-		if structTags.ToStringFuncName != "" {
-			ret := structTags.ToStringFunc.Call([]reflect.Value{reflect.ValueOf(value)})
-			valueStr = ret[0].String()
-			isString = true
-		} else if structTags.UniversalTagMapID > 0 && !exporterCfg.UniversalTagTranslateToNameDisabled {
-			// skip '_id'
-			if pos := strings.Index(keyStr, "_id"); pos != -1 {
-				keyStr = (keyStr[:pos]) + keyStr[pos+3:] // 3 is  length of '_id'
-			}
-			if strings.HasSuffix(structTags.Name, "_1") {
-				valueStr = uTags1.GetTagValue(structTags.UniversalTagMapID)
-			} else {
-				valueStr = uTags0.GetTagValue(structTags.UniversalTagMapID)
-			}
-			isString = true
-		} else if structTags.EnumFile != "" && !exporterCfg.EnumTranslateToNameDisabled {
-			if isString {
-				valueStr = structTags.EnumStringMap[valueStr]
-			} else if isFloat64 {
-				valueStr = structTags.EnumIntMap[int(valueFloat64)]
-			}
-			isString = true
-		}
-
-		// not export empty tags
-		if !exporterCfg.ExportEmptyTag &&
-			(structTags.CategoryBit&config.TAG) != 0 &&
-			((isString && valueStr == "") ||
-				(isStringSlice && len(stringSlice) == 0)) {
+	v := &fieldValue{}
+	for i := range exporterCfg.ExportFieldStructTags[dataSourceId] {
+		if !getFieldValue(item, &exporterCfg.ExportFieldStructTags[dataSourceId][i], isMapItem, exporterCfg, uTags0, uTags1, v) {
 			continue
 		}
-
-		// not export empty metrics
-		if exporterCfg.ExportEmptyMetricsDisabled &&
-			(structTags.CategoryBit&config.METRICS) != 0 &&
-			((isString && valueStr == "") || (isFloat64 && valueFloat64 == 0) ||
-				(isFloat64Slice && len(float64Slice) == 0)) {
-			continue
-		}
-
 		sb.WriteString(`,"`)
-		sb.WriteString(keyStr)
+		sb.WriteString(v.key)
 		sb.WriteString(`":`)
-		if isString {
+		if v.isString {
 			sb.WriteString(`"`)
-			sb.WriteString(utils.EscapeJSONString(valueStr))
+			sb.WriteString(utils.EscapeJSONString(v.str))
 			sb.WriteString(`"`)
-		} else if isStringSlice {
+		} else if v.isStringSlice {
 			sb.WriteString("[")
-			for i, v := range stringSlice {
+			for i, str := range v.strs {
 				if i != 0 {
 					sb.WriteString(`,`)
 				}
 				sb.WriteString(`"`)
-				sb.WriteString(v)
+				sb.WriteString(str)
 				sb.WriteString(`"`)
 			}
 			sb.WriteString("]")
-		} else if isFloat64Slice {
+		} else if v.isFloat64Slice {
 			sb.WriteString("[")
-			for i, v := range float64Slice {
+			for i, f := range v.float64s {
 				if i != 0 {
 					sb.WriteString(`,`)
 				}
-				sb.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+				sb.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
 			}
 			sb.WriteString("]")
-		} else if isFloat64 {
-			sb.WriteString(valueStr)
+		} else if v.isFloat64 {
+			sb.WriteString(v.str)
 		} else {
 			log.Warningf("unreachable")
 		}
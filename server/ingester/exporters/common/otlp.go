@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	utag "github.com/khulnasoft/deepflow/server/ingester/exporters/universal_tag"
+)
+
+const (
+	OTLP_ATTRIBUTE_PREFIX           = "df."
+	OTLP_METRICS_ATTRIBUTE_PREFIX   = "df.metrics."
+	OTLP_K8S_LABEL_ATTRIBUTE_PREFIX = "df.custom_tag.k8s.labels."
+)
+
+// otlpAttributeName gets the attribute name of the field, the format is 'df.<sub_category>.<name>',
+// e.g.: 'df.universal_tag.region', 'df.flow_info.time', and 'df.metrics.<name>' for the metrics
+func otlpAttributeName(structTags *config.StructTags, key string) string {
+	if structTags.CategoryBit&config.METRICS != 0 {
+		return OTLP_METRICS_ATTRIBUTE_PREFIX + key
+	}
+	var sb strings.Builder
+	sb.WriteString(OTLP_ATTRIBUTE_PREFIX)
+	if _, sub, _ := strings.Cut(structTags.Category, "."); sub != "" {
+		sb.WriteString(sub)
+		sb.WriteString(".")
+	}
+	sb.WriteString(key)
+	return sb.String()
+}
+
+func putOtlpAttribute(attrs pcommon.Map, name string, structTags *config.StructTags, v *fieldValue) {
+	if v.isString {
+		attrs.PutStr(name, v.str)
+	} else if v.isStringSlice {
+		slice := attrs.PutEmptySlice(name)
+		slice.EnsureCapacity(len(v.strs))
+		for _, str := range v.strs {
+			slice.AppendEmpty().SetStr(str)
+		}
+	} else if v.isFloat64Slice {
+		slice := attrs.PutEmptySlice(name)
+		slice.EnsureCapacity(len(v.float64s))
+		for _, f := range v.float64s {
+			slice.AppendEmpty().SetDouble(f)
+		}
+	} else if v.isFloat64 {
+		if structTags.DataKind == reflect.Float32 || structTags.DataKind == reflect.Float64 {
+			attrs.PutDouble(name, v.float64)
+		} else if i, err := strconv.ParseInt(v.str, 10, 64); err == nil {
+			attrs.PutInt(name, i)
+		} else {
+			// uint64 values which overflow int64
+			attrs.PutStr(name, v.str)
+		}
+	}
+}
+
+func putOtlpK8sLabels(attrs pcommon.Map, k8sLabels utag.Labels, suffix string) {
+	for name, value := range k8sLabels {
+		if value == "" {
+			continue
+		}
+		attrs.PutStr(OTLP_K8S_LABEL_ATTRIBUTE_PREFIX+name+suffix, value)
+	}
+}
+
+// EncodeToOtlpLogs encodes the exported fields of item to an otlp log record, the fields of universal tags and
+// k8s labels are put into the resource attributes, and the others are put into the log record attributes.
+// The values are translated the same as 'EncodeToJson', the caller could fill the body, severity and
+// trace context of the returned log record.
+func EncodeToOtlpLogs(item EncodeItem, dataSourceId int, exporterCfg *config.ExporterCfg, uTags0, uTags1 *utag.UniversalTags, k8sLabels0, k8sLabels1 utag.Labels) (plog.ResourceLogsSlice, plog.LogRecord) {
+	logsSlice := plog.NewResourceLogsSlice()
+	resLogs := logsSlice.AppendEmpty()
+	resAttrs := resLogs.Resource().Attributes()
+	logRecord := resLogs.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	logAttrs := logRecord.Attributes()
+
+	timestamp := pcommon.Timestamp(item.TimestampUs() * 1000)
+	logRecord.SetTimestamp(timestamp)
+	logRecord.SetObservedTimestamp(timestamp)
+
+	if dataSourceId >= int(config.MAX_DATASOURCE_ID) {
+		log.Errorf("export datasource wrong: datasourceid %d ", dataSourceId)
+		return logsSlice, logRecord
+	}
+	resAttrs.PutStr(OTLP_ATTRIBUTE_PREFIX+"datasource", config.DataSourceID(dataSourceId).String())
+
+	isMapItem := config.DataSourceID(dataSourceId).IsMap()
+	v := &fieldValue{}
+	for i := range exporterCfg.ExportFieldStructTags[dataSourceId] {
+		structTags := &exporterCfg.ExportFieldStructTags[dataSourceId][i]
+		if !getFieldValue(item, structTags, isMapItem, exporterCfg, uTags0, uTags1, v) {
+			continue
+		}
+		name := otlpAttributeName(structTags, v.key)
+		if structTags.SubCategoryBit&config.UNIVERSAL_TAG != 0 {
+			putOtlpAttribute(resAttrs, name, structTags, v)
+		} else {
+			putOtlpAttribute(logAttrs, name, structTags, v)
+		}
+	}
+
+	if isMapItem {
+		putOtlpK8sLabels(resAttrs, k8sLabels0, "_0")
+		putOtlpK8sLabels(resAttrs, k8sLabels1, "_1")
+	} else {
+		putOtlpK8sLabels(resAttrs, k8sLabels0, "")
+	}
+	return logsSlice, logRecord
+}
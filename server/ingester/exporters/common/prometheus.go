@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	utag "github.com/khulnasoft/deepflow/server/ingester/exporters/universal_tag"
+)
+
+// AppendPrometheusLabels appends the exported tags of item to labels, the values are translated the same as 'EncodeToJson',
+// the metrics and the tags of slice are ignored
+func AppendPrometheusLabels(labels []prompb.Label, item EncodeItem, dataSourceId int, exporterCfg *config.ExporterCfg, uTags0, uTags1 *utag.UniversalTags) []prompb.Label {
+	if dataSourceId >= int(config.MAX_DATASOURCE_ID) {
+		log.Errorf("export datasource wrong: datasourceid %d ", dataSourceId)
+		return labels
+	}
+	isMapItem := config.DataSourceID(dataSourceId).IsMap()
+	v := &fieldValue{}
+	for i := range exporterCfg.ExportFieldStructTags[dataSourceId] {
+		structTags := &exporterCfg.ExportFieldStructTags[dataSourceId][i]
+		if structTags.CategoryBit&config.TAG == 0 {
+			continue
+		}
+		//  TimeSeries.Samples have exported `time`, no need to export anymore
+		if structTags.Name == "time" {
+			continue
+		}
+		if !getFieldValue(item, structTags, isMapItem, exporterCfg, uTags0, uTags1, v) {
+			continue
+		}
+		if !v.isString && !v.isFloat64 {
+			continue
+		}
+		labels = append(labels, prompb.Label{
+			Name:  v.key,
+			Value: v.str,
+		})
+	}
+	return labels
+}
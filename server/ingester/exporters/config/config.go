@@ -45,6 +45,14 @@ const (
 	DefaultExportOtherBatchSize = 1024
 	SecurityProtocol            = "SASL_SSL"
 
+	DefaultExportHttpTimeout      = 10 // s
+	DefaultExportHttpMaxRetries   = 3
+	DefaultExportHttpRetryBackoff = 500 // ms
+
+	DefaultExportFileDir            = "/var/lib/deepflow/export"
+	DefaultExportFileMaxSize        = 256  // MB
+	DefaultExportFileRotateInterval = 3600 // s
+
 	CATEGORY_K8S_LABEL = "$k8s.label"
 	CATEGORY_TAG       = "$tag"
 	CATEGORY_METRICS   = "$metrics"
@@ -70,6 +78,12 @@ const (
 	PERF_EVENT = DataSourceID(flow_metrics.METRICS_TABLE_ID_MAX) + 1 + iota
 	L4_FLOW_LOG
 	L7_FLOW_LOG
+	EVENT
+	ALERT_EVENT
+	APPLICATION_LOG
+	PROMETHEUS
+	EXT_METRICS
+	PROFILE
 
 	MAX_DATASOURCE_ID
 )
@@ -86,6 +100,12 @@ var dataSourceStrings = []string{
 	PERF_EVENT:         "event.perf_event",
 	L4_FLOW_LOG:        "flow_log.l4_flow_log",
 	L7_FLOW_LOG:        "flow_log.l7_flow_log",
+	EVENT:              "event.event",
+	ALERT_EVENT:        "event.alert_event",
+	APPLICATION_LOG:    "application_log.log",
+	PROMETHEUS:         "prometheus.samples",
+	EXT_METRICS:        "ext_metrics.metrics",
+	PROFILE:            "profile.in_process",
 	MAX_DATASOURCE_ID:  "invalid_datasource",
 }
 
@@ -101,6 +121,12 @@ var dataSourceTopicStrings = []string{
 	PERF_EVENT:         TOPIC_PREFIX + dataSourceStrings[PERF_EVENT],
 	L4_FLOW_LOG:        TOPIC_PREFIX + dataSourceStrings[L4_FLOW_LOG],
 	L7_FLOW_LOG:        TOPIC_PREFIX + dataSourceStrings[L7_FLOW_LOG],
+	EVENT:              TOPIC_PREFIX + dataSourceStrings[EVENT],
+	ALERT_EVENT:        TOPIC_PREFIX + dataSourceStrings[ALERT_EVENT],
+	APPLICATION_LOG:    TOPIC_PREFIX + dataSourceStrings[APPLICATION_LOG],
+	PROMETHEUS:         TOPIC_PREFIX + dataSourceStrings[PROMETHEUS],
+	EXT_METRICS:        TOPIC_PREFIX + dataSourceStrings[EXT_METRICS],
+	PROFILE:            TOPIC_PREFIX + dataSourceStrings[PROFILE],
 	MAX_DATASOURCE_ID:  TOPIC_PREFIX + dataSourceStrings[MAX_DATASOURCE_ID],
 }
 
@@ -146,7 +172,8 @@ func (d DataSourceID) TopicString() string {
 
 func (d DataSourceID) IsMap() bool {
 	switch d {
-	case NETWORK_1M, APPLICATION_1M, NETWORK_1S, APPLICATION_1S, PERF_EVENT:
+	case NETWORK_1M, APPLICATION_1M, NETWORK_1S, APPLICATION_1S, PERF_EVENT,
+		EVENT, ALERT_EVENT, APPLICATION_LOG, PROMETHEUS, EXT_METRICS, PROFILE:
 		return false
 	default:
 		return true
@@ -461,6 +488,94 @@ type ExporterCfg struct {
 	// kafka private configuration
 	Sasl  Sasl   `yaml:"sasl"`
 	Topic string `yaml:"topic"`
+
+	// http private configuration
+	HttpFormat       string `yaml:"http-format"`        // 'json' (default) or 'ndjson'
+	HttpGzip         bool   `yaml:"http-gzip"`          // compress the request body with gzip
+	HttpTimeout      int    `yaml:"http-timeout"`       // unit: s
+	HttpMaxRetries   int    `yaml:"http-max-retries"`   // a negative value disables retrying
+	HttpRetryBackoff int    `yaml:"http-retry-backoff"` // unit: ms, doubled after each retry
+
+	// file private configuration
+	FileDir            string `yaml:"file-dir"`
+	FileFormat         string `yaml:"file-format"`          // 'ndjson' (default) or 'parquet'
+	FileCompression    string `yaml:"file-compression"`     // ndjson: 'none' (default) or 'gzip', parquet: 'snappy' (default), 'gzip', 'zstd' or 'none'
+	FileMaxSize        int    `yaml:"file-max-size"`        // unit: MB
+	FileRotateInterval int    `yaml:"file-rotate-interval"` // unit: s
+	FileRetentionCount int    `yaml:"file-retention-count"` // the max count of files kept for each data source and queue, 0 means no limit
+}
+
+const (
+	HTTP_FORMAT_JSON   = "json"
+	HTTP_FORMAT_NDJSON = "ndjson"
+)
+
+const (
+	FILE_FORMAT_NDJSON  = "ndjson"
+	FILE_FORMAT_PARQUET = "parquet"
+
+	FILE_COMPRESSION_NONE   = "none"
+	FILE_COMPRESSION_GZIP   = "gzip"
+	FILE_COMPRESSION_SNAPPY = "snappy"
+	FILE_COMPRESSION_ZSTD   = "zstd"
+)
+
+func (cfg *ExporterCfg) validateFile() {
+	if cfg.FileDir == "" {
+		cfg.FileDir = DefaultExportFileDir
+	}
+	if cfg.FileFormat != FILE_FORMAT_NDJSON && cfg.FileFormat != FILE_FORMAT_PARQUET {
+		if cfg.FileFormat != "" {
+			log.Warningf("'file-format' only support value %s or %s, use %s", FILE_FORMAT_NDJSON, FILE_FORMAT_PARQUET, FILE_FORMAT_NDJSON)
+		}
+		cfg.FileFormat = FILE_FORMAT_NDJSON
+	}
+	supported := []string{FILE_COMPRESSION_NONE, FILE_COMPRESSION_GZIP}
+	if cfg.FileFormat == FILE_FORMAT_PARQUET {
+		supported = []string{FILE_COMPRESSION_SNAPPY, FILE_COMPRESSION_GZIP, FILE_COMPRESSION_ZSTD, FILE_COMPRESSION_NONE}
+	}
+	valid := false
+	for _, c := range supported {
+		if cfg.FileCompression == c {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		if cfg.FileCompression != "" {
+			log.Warningf("'file-compression' of %s only support value %v, use %s", cfg.FileFormat, supported, supported[0])
+		}
+		cfg.FileCompression = supported[0]
+	}
+	if cfg.FileMaxSize <= 0 {
+		cfg.FileMaxSize = DefaultExportFileMaxSize
+	}
+	if cfg.FileRotateInterval <= 0 {
+		cfg.FileRotateInterval = DefaultExportFileRotateInterval
+	}
+	if cfg.FileRetentionCount < 0 {
+		cfg.FileRetentionCount = 0
+	}
+}
+
+func (cfg *ExporterCfg) validateHttp() {
+	if cfg.HttpFormat != HTTP_FORMAT_JSON && cfg.HttpFormat != HTTP_FORMAT_NDJSON {
+		if cfg.HttpFormat != "" {
+			log.Warningf("'http-format' only support value %s or %s, use %s", HTTP_FORMAT_JSON, HTTP_FORMAT_NDJSON, HTTP_FORMAT_JSON)
+		}
+		cfg.HttpFormat = HTTP_FORMAT_JSON
+	}
+	if cfg.HttpTimeout <= 0 {
+		cfg.HttpTimeout = DefaultExportHttpTimeout
+	}
+	if cfg.HttpMaxRetries == 0 {
+		cfg.HttpMaxRetries = DefaultExportHttpMaxRetries
+	} else if cfg.HttpMaxRetries < 0 {
+		cfg.HttpMaxRetries = 0
+	}
+	if cfg.HttpRetryBackoff <= 0 {
+		cfg.HttpRetryBackoff = DefaultExportHttpRetryBackoff
+	}
 }
 
 type Sasl struct {
@@ -490,6 +605,8 @@ const (
 	PROTOCOL_OTLP ExportProtocol = iota
 	PROTOCOL_PROMETHEUS
 	PROTOCOL_KAFKA
+	PROTOCOL_HTTP
+	PROTOCOL_FILE
 
 	MAX_PROTOCOL_ID
 )
@@ -498,6 +615,8 @@ var protocolToStrings = []string{
 	PROTOCOL_OTLP:       "opentelemetry",
 	PROTOCOL_PROMETHEUS: "prometheus",
 	PROTOCOL_KAFKA:      "kafka",
+	PROTOCOL_HTTP:       "http",
+	PROTOCOL_FILE:       "file",
 	MAX_PROTOCOL_ID:     "unknown",
 }
 
@@ -550,6 +669,12 @@ func (cfg *ExporterCfg) Validate() error {
 
 	cfg.TagFilterCondition.Validate()
 	cfg.Sasl.Validate()
+	switch cfg.ExportProtocol {
+	case PROTOCOL_HTTP:
+		cfg.validateHttp()
+	case PROTOCOL_FILE:
+		cfg.validateFile()
+	}
 
 	return nil
 }
@@ -601,7 +726,7 @@ const (
 	SERVICE_INFO
 	TRACING_INFO
 	CAPTURE_INFO
-	EVENT_INFO // perf_event only
+	EVENT_INFO // perf_event/event/alert_event only
 	DATA_LINK_LAYER
 
 	// metrics
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exporters
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/enum_translation"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+)
+
+type encodeTestItem struct {
+	Time       uint32   `json:"time" category:"$tag" sub:"flow_info"`
+	AppService string   `json:"app_service" category:"$tag" sub:"service_info"`
+	TagNames   []string `json:"tag_names" category:"$tag" sub:"native_tag" data_type:"[]string"`
+	Timestamps []int64  `json:"timestamps" category:"$tag" sub:"flow_info" data_type:"[]int64"`
+	Value      float64  `json:"value" category:"$metrics"`
+}
+
+func (i *encodeTestItem) GetFieldValueByOffsetAndKind(offset uintptr, kind reflect.Kind, dataType utils.DataType) interface{} {
+	return utils.GetValueByOffsetAndKind(uintptr(unsafe.Pointer(i)), offset, kind, dataType)
+}
+
+func (i *encodeTestItem) TimestampUs() int64 {
+	return int64(i.Time) * 1000000
+}
+
+func newEncodeTestItem() (*encodeTestItem, *config.ExporterCfg) {
+	item := &encodeTestItem{
+		Time:       1700000000,
+		AppService: "svc",
+		TagNames:   []string{"a", "b"},
+		Timestamps: []int64{1, 2},
+		Value:      1.5,
+	}
+	es := &Exporters{translation: enum_translation.NewEnumTranslation()}
+	cfg := newTailExporterCfg()
+	es.initStructTags(item, uint32(config.PROFILE), cfg)
+	return item, cfg
+}
+
+func TestEncodeInt64Slice(t *testing.T) {
+	item, cfg := newEncodeTestItem()
+	json := common.EncodeToJson(item, int(config.PROFILE), cfg, nil, nil, nil, nil)
+	for _, expected := range []string{`"datasource":"profile.in_process"`, `"tag_names":["a","b"]`, `"timestamps":[1,2]`, `"value":1.5`} {
+		if !strings.Contains(json, expected) {
+			t.Errorf("json %s does not contain %s", json, expected)
+		}
+	}
+}
+
+func TestEncodeToOtlpLogs(t *testing.T) {
+	item, cfg := newEncodeTestItem()
+	logsSlice, logRecord := common.EncodeToOtlpLogs(item, int(config.PROFILE), cfg, nil, nil, nil, nil)
+	if logsSlice.Len() != 1 {
+		t.Fatalf("expected 1 resource logs, got %d", logsSlice.Len())
+	}
+	if v, ok := logsSlice.At(0).Resource().Attributes().Get("df.datasource"); !ok || v.Str() != "profile.in_process" {
+		t.Errorf("unexpected datasource attribute %v", v.AsString())
+	}
+	if logRecord.Timestamp().AsTime().Unix() != int64(item.Time) {
+		t.Errorf("unexpected timestamp %v", logRecord.Timestamp())
+	}
+
+	attrs := logRecord.Attributes()
+	if v, ok := attrs.Get("df.flow_info.time"); !ok || v.Int() != int64(item.Time) {
+		t.Errorf("unexpected time attribute %s", v.AsString())
+	}
+	if v, ok := attrs.Get("df.service_info.app_service"); !ok || v.Str() != "svc" {
+		t.Errorf("unexpected app_service attribute %s", v.AsString())
+	}
+	if v, ok := attrs.Get("df.native_tag.tag_names"); !ok || v.Slice().Len() != 2 || v.Slice().At(1).Str() != "b" {
+		t.Errorf("unexpected tag_names attribute %s", v.AsString())
+	}
+	if v, ok := attrs.Get("df.flow_info.timestamps"); !ok || v.Slice().Len() != 2 || v.Slice().At(1).Double() != 2 {
+		t.Errorf("unexpected timestamps attribute %s", v.AsString())
+	}
+	if v, ok := attrs.Get("df.metrics.value"); !ok || v.Double() != 1.5 {
+		t.Errorf("unexpected value attribute %s", v.AsString())
+	}
+}
+
+func TestAppendPrometheusLabels(t *testing.T) {
+	item, cfg := newEncodeTestItem()
+	labels := common.AppendPrometheusLabels(nil, item, int(config.PROFILE), cfg, nil, nil)
+	// time, slices and metrics are not exported as labels
+	expected := []prompb.Label{{Name: "app_service", Value: "svc"}}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, labels)
+	}
+}
@@ -19,16 +19,20 @@ package exporters
 import (
 	"reflect"
 	"strings"
+	"sync/atomic"
 
 	logging "github.com/op/go-logging"
 
 	"github.com/khulnasoft/deepflow/server/ingester/exporters/common"
 	"github.com/khulnasoft/deepflow/server/ingester/exporters/config"
 	"github.com/khulnasoft/deepflow/server/ingester/exporters/enum_translation"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/file_exporter"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/http_exporter"
 	"github.com/khulnasoft/deepflow/server/ingester/exporters/kafka_exporter"
 	"github.com/khulnasoft/deepflow/server/ingester/exporters/otlp_exporter"
 	"github.com/khulnasoft/deepflow/server/ingester/exporters/prometheus_exporter"
 	"github.com/khulnasoft/deepflow/server/ingester/exporters/universal_tag"
+	"github.com/khulnasoft/deepflow/server/ingester/ingesterctl"
 	"github.com/khulnasoft/deepflow/server/libs/queue"
 	"github.com/khulnasoft/deepflow/server/libs/utils"
 )
@@ -62,13 +66,11 @@ type Exporters struct {
 	dataSourceExporters     [config.MAX_DATASOURCE_ID][]Exporter
 	dataSourceExporterCfgs  [config.MAX_DATASOURCE_ID][]*config.ExporterCfg
 	putCaches               []ExportersCache // cache for batch put to exporter, has multi decoders call Put(), and put to multi exporters
+	tail                    *Tail
 }
 
+// NewExporters always returns non-nil, even if no exporter is enabled, the records could be sent to deepflow-ctl by the tail
 func NewExporters(cfg *config.Config) *Exporters {
-	if len(cfg.Exporters) == 0 {
-		log.Infof("exporters is empty")
-		return nil
-	}
 	log.Infof("init exporters: %+v", cfg.Exporters)
 
 	translation := enum_translation.NewEnumTranslation()
@@ -97,6 +99,10 @@ func NewExporters(cfg *config.Config) *Exporters {
 			exporter = prometheus_exporter.NewPrometheusExporter(i, &cfg.Exporters[i], universalTagManager)
 		case config.PROTOCOL_KAFKA:
 			exporter = kafka_exporter.NewKafkaExporter(i, &cfg.Exporters[i], universalTagManager)
+		case config.PROTOCOL_HTTP:
+			exporter = http_exporter.NewHttpExporter(i, &cfg.Exporters[i], universalTagManager)
+		case config.PROTOCOL_FILE:
+			exporter = file_exporter.NewFileExporter(i, &cfg.Exporters[i], universalTagManager)
 		default:
 			exporter = nil
 			log.Warningf("unsupport export protocol %s", exporterCfg.Protocol)
@@ -118,10 +124,9 @@ func NewExporters(cfg *config.Config) *Exporters {
 
 	if len(exporters) == 0 {
 		log.Infof("exporters is disabled")
-		return nil
 	}
 
-	return &Exporters{
+	es := &Exporters{
 		config:                  cfg,
 		universalTagsManagerMap: uTagManagerMap,
 		exporters:               exporters,
@@ -130,6 +135,8 @@ func NewExporters(cfg *config.Config) *Exporters {
 		dataSourceExporterCfgs:  dataSourceExporterCfgs,
 		translation:             translation,
 	}
+	es.tail = NewTail(es, ingesterctl.INGESTERCTL_TAIL)
+	return es
 }
 
 func (es *Exporters) Start() {
@@ -142,6 +149,7 @@ func (es *Exporters) Start() {
 }
 
 func (es *Exporters) Close() error {
+	es.tail.Close()
 	for _, v := range es.universalTagsManagerMap {
 		v.Close()
 	}
@@ -322,6 +330,15 @@ func (es *Exporters) getPutCache(dataSourceId, decoderId, exporterId int) *Expor
 	return &es.putCaches[(dataSourceId*queue.MAX_QUEUE_COUNT+decoderId)*MAX_EXPORTERS_PER_DATASOURCE+exporterId]
 }
 
+// IsExportDataSource returns whether the items of the datasource would be exported or tailed, the callers
+// which build the items only for exporting could skip building them if it returns false
+func (es *Exporters) IsExportDataSource(dataSourceId uint32) bool {
+	if dataSourceId >= uint32(config.MAX_DATASOURCE_ID) {
+		return false
+	}
+	return len(es.dataSourceExporters[dataSourceId]) > 0 || atomic.LoadInt32(&es.tail.active) != 0
+}
+
 func (es *Exporters) Put(dataSourceId uint32, decoderIndex int, item common.ExportItem) {
 	if utils.IsNil(item) {
 		es.Flush(int(dataSourceId), decoderIndex)
@@ -332,6 +349,8 @@ func (es *Exporters) Put(dataSourceId uint32, decoderIndex int, item common.Expo
 		log.Warningf("datasourceId %d != itemDatasoure %d", dataSourceId, item.DataSource())
 		return
 	}
+	es.tail.Put(dataSourceId, item)
+
 	if es.dataSourceExporters[dataSourceId] == nil {
 		return
 	}
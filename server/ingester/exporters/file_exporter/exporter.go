@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file_exporter
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	logging "github.com/op/go-logging"
+
+	ingester_common "github.com/khulnasoft/deepflow/server/ingester/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/common"
+	exporters_cfg "github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	utag "github.com/khulnasoft/deepflow/server/ingester/exporters/universal_tag"
+	"github.com/khulnasoft/deepflow/server/ingester/ingesterctl"
+	"github.com/khulnasoft/deepflow/server/libs/debug"
+	"github.com/khulnasoft/deepflow/server/libs/queue"
+	"github.com/khulnasoft/deepflow/server/libs/stats"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+)
+
+var log = logging.MustGetLogger("file_exporter")
+
+const (
+	QUEUE_BATCH_COUNT = 1024
+)
+
+type FileExporter struct {
+	index                int
+	dataQueues           queue.FixedMultiQueue
+	queueCount           int
+	universalTagsManager *utag.UniversalTagsManager
+	config               *exporters_cfg.ExporterCfg
+	counter              *Counter
+	lastCounter          Counter
+	running              bool
+	wg                   sync.WaitGroup
+
+	utils.Closable
+}
+
+type Counter struct {
+	RecvCounter      int64 `statsd:"recv-count"`
+	WriteCounter     int64 `statsd:"write-count"`
+	DropCounter      int64 `statsd:"drop-count"`
+	RotateCounter    int64 `statsd:"rotate-count"`
+	RemoveCounter    int64 `statsd:"remove-count"`
+	ExportUsedTimeNs int64 `statsd:"export-used-time-ns"`
+}
+
+func (e *FileExporter) GetCounter() interface{} {
+	var counter Counter
+	counter, *e.counter = *e.counter, Counter{}
+	e.lastCounter = counter
+	return &counter
+}
+
+func NewFileExporter(index int, config *exporters_cfg.ExporterCfg, universalTagsManager *utag.UniversalTagsManager) *FileExporter {
+	dataQueues := queue.NewOverwriteQueues(
+		fmt.Sprintf("file_exporter_%d", index), queue.HashKey(config.QueueCount), config.QueueSize,
+		queue.OptionFlushIndicator(time.Second),
+		queue.OptionRelease(func(p interface{}) { p.(common.ExportItem).Release() }),
+		ingester_common.QUEUE_STATS_MODULE_INGESTER)
+
+	exporter := &FileExporter{
+		index:                index,
+		dataQueues:           dataQueues,
+		queueCount:           config.QueueCount,
+		universalTagsManager: universalTagsManager,
+		config:               config,
+		counter:              &Counter{},
+	}
+	debug.ServerRegisterSimple(ingesterctl.CMD_FILE_EXPORTER, exporter)
+	ingester_common.RegisterCountableForIngester("exporter", exporter, stats.OptionStatTags{
+		"type": "file", "index": strconv.Itoa(index)})
+	log.Infof("file exporter %d created, dir %s, format %s", index, config.FileDir, config.FileFormat)
+	return exporter
+}
+
+func (e *FileExporter) Put(items ...interface{}) {
+	e.counter.RecvCounter++
+	e.dataQueues.Put(queue.HashKey(int(e.counter.RecvCounter)%e.queueCount), items...)
+}
+
+func (e *FileExporter) Start() {
+	if e.running {
+		log.Warningf("file exporter %d already running", e.index)
+		return
+	}
+	e.running = true
+	for i := 0; i < e.queueCount; i++ {
+		e.wg.Add(1)
+		go e.queueProcess(int(i))
+	}
+	log.Infof("file exporter %d started %d queue", e.index, e.queueCount)
+}
+
+// Close waits for the writing files to be finished, or the parquet files will be broken
+func (e *FileExporter) Close() {
+	e.Closable.Close()
+	e.running = false
+	e.wg.Wait()
+	log.Infof("file exporter %d stopped", e.index)
+}
+
+func (e *FileExporter) queueProcess(queueID int) {
+	defer e.wg.Done()
+	items := make([]interface{}, QUEUE_BATCH_COUNT)
+	// each data source is written to its own files, since the fields are different
+	writers := [exporters_cfg.MAX_DATASOURCE_ID]*rotatingWriter{}
+	defer func() {
+		for _, w := range writers {
+			if w != nil {
+				w.Close()
+			}
+		}
+	}()
+
+	for e.running {
+		n := e.dataQueues.Gets(queue.HashKey(queueID), items)
+		now := time.Now()
+		for _, item := range items[:n] {
+			if item == nil {
+				for _, w := range writers {
+					if w != nil {
+						w.Tick(now)
+					}
+				}
+				continue
+			}
+			exportItem, ok := item.(common.ExportItem)
+			if !ok {
+				e.counter.DropCounter++
+				continue
+			}
+			e.write(queueID, &writers, exportItem)
+			exportItem.Release()
+		}
+		e.counter.ExportUsedTimeNs += int64(time.Since(now))
+	}
+}
+
+func (e *FileExporter) write(queueID int, writers *[exporters_cfg.MAX_DATASOURCE_ID]*rotatingWriter, exportItem common.ExportItem) {
+	dataSourceId := exportItem.DataSource()
+	if dataSourceId >= uint32(exporters_cfg.MAX_DATASOURCE_ID) {
+		e.counter.DropCounter++
+		return
+	}
+
+	json, err := exportItem.EncodeTo(exporters_cfg.PROTOCOL_FILE, e.universalTagsManager, e.config)
+	if err != nil {
+		if e.counter.DropCounter == 0 {
+			log.Warningf("file encode failed, err: %s", err)
+		}
+		e.counter.DropCounter++
+		return
+	}
+	jsonStr := json.(string)
+	if jsonStr == "" {
+		e.counter.DropCounter++
+		return
+	}
+
+	w := writers[dataSourceId]
+	if w == nil {
+		w, err = newRotatingWriter(e.config, dataSourceId, e.index, queueID, e.counter)
+		if err != nil {
+			if e.counter.DropCounter == 0 {
+				log.Warningf("exporter %d queue %d create file writer failed, err: %s", e.index, queueID, err)
+			}
+			e.counter.DropCounter++
+			return
+		}
+		writers[dataSourceId] = w
+	}
+	if err := w.Write(jsonStr); err != nil {
+		if e.counter.DropCounter == 0 {
+			log.Warningf("exporter %d queue %d write file failed, err: %s", e.index, queueID, err)
+		}
+		e.counter.DropCounter++
+		return
+	}
+	e.counter.WriteCounter++
+}
+
+func (e *FileExporter) HandleSimpleCommand(op uint16, arg string) string {
+	return fmt.Sprintf("file exporter %d last 10s counter: %+v", e.index, e.lastCounter)
+}
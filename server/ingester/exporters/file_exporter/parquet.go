@@ -0,0 +1,270 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file_exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+
+	exporters_cfg "github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+)
+
+const (
+	// the rows are buffered in memory until the row group is full, so it should not be too large
+	PARQUET_ROW_GROUP_SIZE = 32 << 20
+)
+
+type columnType uint8
+
+const (
+	COLUMN_STRING columnType = iota
+	COLUMN_INT64
+	COLUMN_UINT64
+	COLUMN_DOUBLE
+)
+
+var columnTypeSchemas = []string{
+	COLUMN_STRING: "type=BYTE_ARRAY, convertedtype=UTF8",
+	COLUMN_INT64:  "type=INT64",
+	COLUMN_UINT64: "type=INT64, convertedtype=UINT_64",
+	COLUMN_DOUBLE: "type=DOUBLE",
+}
+
+type parquetColumn struct {
+	name string
+	typ  columnType
+}
+
+func kindToColumnType(kind reflect.Kind, dataType utils.DataType) columnType {
+	switch kind {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return COLUMN_INT64
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return COLUMN_UINT64
+	case reflect.Float32, reflect.Float64:
+		return COLUMN_DOUBLE
+	case reflect.Pointer:
+		if dataType == utils.DATATYPE_UintPtr || dataType == utils.DATATYPE_Uint64Ptr {
+			return COLUMN_UINT64
+		}
+		return COLUMN_INT64
+	default:
+		// string, IP, and the slices which are saved as json arrays
+		return COLUMN_STRING
+	}
+}
+
+// parquetColumns gets the columns of data source from 'ExportFieldStructTags', the names and types
+// of columns are the same as the keys and values generated by 'common.EncodeToJson'
+func parquetColumns(cfg *exporters_cfg.ExporterCfg, dataSourceId uint32) []parquetColumn {
+	isMapItem := exporters_cfg.DataSourceID(dataSourceId).IsMap()
+	columns := []parquetColumn{{name: "datasource", typ: COLUMN_STRING}}
+	for _, structTags := range cfg.ExportFieldStructTags[dataSourceId] {
+		name := structTags.Name
+		if isMapItem && structTags.MapName != "" {
+			name = structTags.MapName
+		}
+		typ := kindToColumnType(structTags.DataKind, structTags.DataType)
+		if structTags.ToStringFuncName != "" {
+			typ = COLUMN_STRING
+		} else if structTags.UniversalTagMapID > 0 && !cfg.UniversalTagTranslateToNameDisabled {
+			if pos := strings.Index(name, "_id"); pos != -1 {
+				name = name[:pos] + name[pos+3:]
+			}
+			typ = COLUMN_STRING
+		} else if structTags.EnumFile != "" && !cfg.EnumTranslateToNameDisabled {
+			typ = COLUMN_STRING
+		}
+		columns = append(columns, parquetColumn{name: name, typ: typ})
+	}
+	if isMapItem {
+		columns = append(columns,
+			parquetColumn{name: "k8s_label_names_0"}, parquetColumn{name: "k8s_label_values_0"},
+			parquetColumn{name: "k8s_label_names_1"}, parquetColumn{name: "k8s_label_values_1"})
+	} else {
+		columns = append(columns, parquetColumn{name: "k8s_label_names"}, parquetColumn{name: "k8s_label_values"})
+	}
+	columns = append(columns, parquetColumn{name: "time_str"})
+
+	// the names should be unique in the schema
+	names := make(map[string]bool, len(columns))
+	uniqueColumns := columns[:0]
+	for _, c := range columns {
+		if names[c.name] {
+			continue
+		}
+		names[c.name] = true
+		uniqueColumns = append(uniqueColumns, c)
+	}
+	return uniqueColumns
+}
+
+func parquetCompressionCodec(compression string) parquet.CompressionCodec {
+	switch compression {
+	case exporters_cfg.FILE_COMPRESSION_GZIP:
+		return parquet.CompressionCodec_GZIP
+	case exporters_cfg.FILE_COMPRESSION_ZSTD:
+		return parquet.CompressionCodec_ZSTD
+	case exporters_cfg.FILE_COMPRESSION_NONE:
+		return parquet.CompressionCodec_UNCOMPRESSED
+	default:
+		return parquet.CompressionCodec_SNAPPY
+	}
+}
+
+type parquetWriter struct {
+	columns []parquetColumn
+	index   map[string]int
+	counter *countingWriter
+	buffer  *bufio.Writer
+	writer  *writer.CSVWriter
+
+	// parquet-go estimates each value of the interface rows as 4 bytes, so the size of rows is counted here
+	rowCount int64
+	rowBytes int64
+}
+
+func newParquetWriter(w io.Writer, cfg *exporters_cfg.ExporterCfg, dataSourceId uint32) (*parquetWriter, error) {
+	columns := parquetColumns(cfg, dataSourceId)
+	metadata := make([]string, 0, len(columns))
+	index := make(map[string]int, len(columns))
+	for i, c := range columns {
+		metadata = append(metadata, fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", c.name, columnTypeSchemas[c.typ]))
+		index[c.name] = i
+	}
+
+	counter := &countingWriter{w: w}
+	buffer := bufio.NewWriterSize(counter, WRITE_BUFFER_SIZE)
+	pw, err := writer.NewCSVWriterFromWriter(metadata, buffer, 1)
+	if err != nil {
+		return nil, err
+	}
+	pw.RowGroupSize = PARQUET_ROW_GROUP_SIZE
+	pw.CompressionType = parquetCompressionCodec(cfg.FileCompression)
+	return &parquetWriter{
+		columns: columns,
+		index:   index,
+		counter: counter,
+		buffer:  buffer,
+		writer:  pw,
+	}, nil
+}
+
+func (w *parquetWriter) Write(item string) error {
+	fields := make(map[string]interface{}, len(w.columns))
+	decoder := json.NewDecoder(strings.NewReader(item))
+	// keep the precision of uint64
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return err
+	}
+	row := make([]interface{}, len(w.columns))
+	for key, value := range fields {
+		i, ok := w.index[key]
+		if !ok {
+			continue
+		}
+		row[i] = toColumnValue(value, w.columns[i].typ)
+		w.rowBytes += valueSize(row[i])
+	}
+	w.rowCount++
+	return w.writer.Write(row)
+}
+
+func valueSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case string:
+		return int64(len(v))
+	case nil:
+		return 0
+	default:
+		return 8
+	}
+}
+
+// toColumnValue converts the json value to the type of column, returns nil if failed
+func toColumnValue(value interface{}, typ columnType) interface{} {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case json.Number:
+		str = v.String()
+	case nil:
+		return nil
+	default:
+		if typ != COLUMN_STRING {
+			return nil
+		}
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		return string(bytes)
+	}
+
+	switch typ {
+	case COLUMN_INT64:
+		if v, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return v
+		}
+		if v, err := strconv.ParseFloat(str, 64); err == nil {
+			return int64(v)
+		}
+	case COLUMN_UINT64:
+		if v, err := strconv.ParseUint(str, 10, 64); err == nil {
+			return int64(v)
+		}
+		if v, err := strconv.ParseFloat(str, 64); err == nil {
+			return int64(uint64(v))
+		}
+	case COLUMN_DOUBLE:
+		if v, err := strconv.ParseFloat(str, 64); err == nil {
+			return v
+		}
+	default:
+		return str
+	}
+	return nil
+}
+
+func (w *parquetWriter) Size() int64 {
+	// the pages and rows are buffered in memory before a row group is written, the rows not encoded
+	// into pages yet are estimated by the average row size
+	size := w.counter.n + int64(w.buffer.Buffered()) + w.writer.Size
+	if w.rowCount > 0 {
+		size += int64(len(w.writer.Objs)) * w.rowBytes / w.rowCount
+	}
+	return size
+}
+
+func (w *parquetWriter) Close() error {
+	if err := w.writer.WriteStop(); err != nil {
+		return err
+	}
+	return w.buffer.Flush()
+}
@@ -0,0 +1,280 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file_exporter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	exporters_cfg "github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+)
+
+const (
+	// the suffix of the file being written, it is removed when the file is finished
+	WRITING_FILE_SUFFIX = ".writing"
+	FILE_TIME_FORMAT    = "20060102150405.000000"
+	WRITE_BUFFER_SIZE   = 256 << 10
+)
+
+// itemWriter encodes the json items to a file
+type itemWriter interface {
+	Write(item string) error
+	// the size of file after closing, may be estimated
+	Size() int64
+	Close() error
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type ndjsonWriter struct {
+	counter *countingWriter
+	gzip    *gzip.Writer
+	writer  *bufio.Writer
+}
+
+func newNdjsonWriter(w io.Writer, compression string) *ndjsonWriter {
+	counter := &countingWriter{w: w}
+	writer := &ndjsonWriter{counter: counter}
+	if compression == exporters_cfg.FILE_COMPRESSION_GZIP {
+		writer.gzip = gzip.NewWriter(counter)
+		writer.writer = bufio.NewWriterSize(writer.gzip, WRITE_BUFFER_SIZE)
+	} else {
+		writer.writer = bufio.NewWriterSize(counter, WRITE_BUFFER_SIZE)
+	}
+	return writer
+}
+
+func (w *ndjsonWriter) Write(item string) error {
+	if _, err := w.writer.WriteString(item); err != nil {
+		return err
+	}
+	return w.writer.WriteByte('\n')
+}
+
+func (w *ndjsonWriter) Size() int64 {
+	if w.gzip != nil {
+		return w.counter.n
+	}
+	return w.counter.n + int64(w.writer.Buffered())
+}
+
+func (w *ndjsonWriter) Close() error {
+	if err := w.writer.Flush(); err != nil {
+		return err
+	}
+	if w.gzip != nil {
+		return w.gzip.Close()
+	}
+	return nil
+}
+
+func fileExtension(cfg *exporters_cfg.ExporterCfg) string {
+	if cfg.FileFormat == exporters_cfg.FILE_FORMAT_PARQUET {
+		// the compression of parquet is inside the file
+		return ".parquet"
+	}
+	if cfg.FileCompression == exporters_cfg.FILE_COMPRESSION_GZIP {
+		return ".ndjson.gz"
+	}
+	return ".ndjson"
+}
+
+// rotatingWriter writes the items of a data source to the files in '$file-dir/$data-source/', the file is
+// rotated when its size exceeds 'file-max-size' or it has been written for 'file-rotate-interval', and the
+// oldest files are removed when the count of files exceeds 'file-retention-count'.
+// It is used by one queue goroutine only, no lock is needed.
+type rotatingWriter struct {
+	cfg            *exporters_cfg.ExporterCfg
+	dataSourceId   uint32
+	dir            string
+	prefix         string
+	ext            string
+	maxSize        int64
+	rotateInterval time.Duration
+	counter        *Counter
+
+	path     string // the file being written, without WRITING_FILE_SUFFIX
+	file     *os.File
+	writer   itemWriter
+	openTime time.Time
+	files    []string // the finished files, from old to new
+}
+
+func newRotatingWriter(cfg *exporters_cfg.ExporterCfg, dataSourceId uint32, index, queueID int, counter *Counter) (*rotatingWriter, error) {
+	dataSource := exporters_cfg.DataSourceID(dataSourceId).String()
+	w := &rotatingWriter{
+		cfg:            cfg,
+		dataSourceId:   dataSourceId,
+		dir:            filepath.Join(cfg.FileDir, dataSource),
+		prefix:         fmt.Sprintf("%s.%d.%d.", dataSource, index, queueID),
+		ext:            fileExtension(cfg),
+		maxSize:        int64(cfg.FileMaxSize) << 20,
+		rotateInterval: time.Duration(cfg.FileRotateInterval) * time.Second,
+		counter:        counter,
+	}
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := w.loadFiles(); err != nil {
+		return nil, err
+	}
+	w.removeExpiredFiles()
+	return w, nil
+}
+
+// loadFiles finds the files written before restarting
+func (w *rotatingWriter) loadFiles() error {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, w.prefix) {
+			continue
+		}
+		if strings.HasSuffix(name, WRITING_FILE_SUFFIX) {
+			path := filepath.Join(w.dir, name)
+			finishedPath := strings.TrimSuffix(path, WRITING_FILE_SUFFIX)
+			// the unfinished ndjson files are still readable except the last line, but the parquet files are broken without the footer
+			if strings.HasSuffix(finishedPath, ".parquet") {
+				log.Infof("remove unfinished file %s", path)
+				os.Remove(path)
+				continue
+			}
+			if err := os.Rename(path, finishedPath); err != nil {
+				log.Warningf("rename unfinished file %s failed: %s", path, err)
+				continue
+			}
+			name = filepath.Base(finishedPath)
+		}
+		w.files = append(w.files, name)
+	}
+	sort.Strings(w.files)
+	return nil
+}
+
+func (w *rotatingWriter) removeExpiredFiles() {
+	retention := w.cfg.FileRetentionCount
+	if retention <= 0 {
+		return
+	}
+	for len(w.files) > retention {
+		path := filepath.Join(w.dir, w.files[0])
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Warningf("remove file %s failed: %s", path, err)
+		}
+		w.counter.RemoveCounter++
+		w.files = w.files[1:]
+	}
+}
+
+func (w *rotatingWriter) open(now time.Time) error {
+	path := filepath.Join(w.dir, w.prefix+now.Format(FILE_TIME_FORMAT)+w.ext)
+	file, err := os.OpenFile(path+WRITING_FILE_SUFFIX, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	var writer itemWriter
+	if w.cfg.FileFormat == exporters_cfg.FILE_FORMAT_PARQUET {
+		writer, err = newParquetWriter(file, w.cfg, w.dataSourceId)
+		if err != nil {
+			file.Close()
+			os.Remove(path + WRITING_FILE_SUFFIX)
+			return err
+		}
+	} else {
+		writer = newNdjsonWriter(file, w.cfg.FileCompression)
+	}
+	w.path, w.file, w.writer, w.openTime = path, file, writer, now
+	return nil
+}
+
+func (w *rotatingWriter) Write(item string) error {
+	if w.writer == nil {
+		if err := w.open(time.Now()); err != nil {
+			return err
+		}
+	}
+	if err := w.writer.Write(item); err != nil {
+		return err
+	}
+	if w.writer.Size() >= w.maxSize {
+		w.rotate()
+	}
+	return nil
+}
+
+// Tick rotates the file which has been written for 'file-rotate-interval'
+func (w *rotatingWriter) Tick(now time.Time) {
+	if w.writer != nil && now.Sub(w.openTime) >= w.rotateInterval {
+		w.rotate()
+	}
+}
+
+func (w *rotatingWriter) rotate() {
+	if err := w.finish(); err != nil {
+		log.Warning(err)
+	}
+	w.counter.RotateCounter++
+	w.removeExpiredFiles()
+}
+
+// finish closes the file being written and removes WRITING_FILE_SUFFIX
+func (w *rotatingWriter) finish() error {
+	if w.writer == nil {
+		return nil
+	}
+	writer, file, path := w.writer, w.file, w.path
+	w.writer, w.file, w.path = nil, nil, ""
+
+	err := writer.Close()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + WRITING_FILE_SUFFIX)
+		return fmt.Errorf("finish file %s failed: %s", path, err)
+	}
+	if err := os.Rename(path+WRITING_FILE_SUFFIX, path); err != nil {
+		return fmt.Errorf("finish file %s failed: %s", path, err)
+	}
+	w.files = append(w.files, filepath.Base(path))
+	return nil
+}
+
+func (w *rotatingWriter) Close() {
+	if err := w.finish(); err != nil {
+		log.Warning(err)
+	}
+}
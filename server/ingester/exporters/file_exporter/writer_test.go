@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file_exporter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+
+	exporters_cfg "github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+)
+
+const testItem = `{"datasource":"flow_log.l7_flow_log","response_code":200,"flow_id":18446744073709551615,"l7_protocol":"HTTP",` +
+	`"region_0":"r1","response_duration":1.5,"attribute_names":["a","b"],"time_str":"2024-01-01 00:00:00 +0000 UTC"}`
+
+func newTestConfig(format, compression string) *exporters_cfg.ExporterCfg {
+	cfg := &exporters_cfg.ExporterCfg{
+		Protocol:           "file",
+		FileFormat:         format,
+		FileCompression:    compression,
+		FileRetentionCount: 2,
+	}
+	cfg.Validate()
+	cfg.ExportFieldStructTags[exporters_cfg.L7_FLOW_LOG] = []exporters_cfg.StructTags{
+		{Name: "response_code", DataKind: reflect.Int32},
+		{Name: "flow_id", DataKind: reflect.Uint64},
+		{Name: "l7_protocol", DataKind: reflect.Uint8, EnumFile: "l7_protocol"},
+		{Name: "region_id_0", DataKind: reflect.Uint16, UniversalTagMapID: 1},
+		{Name: "response_duration", DataKind: reflect.Float64},
+		{Name: "attribute_names", DataKind: reflect.Slice, DataType: utils.DATATYPE_StringSlice},
+	}
+	return cfg
+}
+
+func finishedFiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []string{}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), WRITING_FILE_SUFFIX) {
+			t.Fatalf("unexpected unfinished file %s", entry.Name())
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	return files
+}
+
+func TestParquetColumns(t *testing.T) {
+	columns := parquetColumns(newTestConfig(exporters_cfg.FILE_FORMAT_PARQUET, ""), uint32(exporters_cfg.L7_FLOW_LOG))
+	expected := []parquetColumn{
+		{"datasource", COLUMN_STRING},
+		{"response_code", COLUMN_INT64},
+		{"flow_id", COLUMN_UINT64},
+		{"l7_protocol", COLUMN_STRING},
+		{"region_0", COLUMN_STRING},
+		{"response_duration", COLUMN_DOUBLE},
+		{"attribute_names", COLUMN_STRING},
+		{"k8s_label_names_0", COLUMN_STRING},
+		{"k8s_label_values_0", COLUMN_STRING},
+		{"k8s_label_names_1", COLUMN_STRING},
+		{"k8s_label_values_1", COLUMN_STRING},
+		{"time_str", COLUMN_STRING},
+	}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("expected columns %v, actual %v", expected, columns)
+	}
+}
+
+func TestNdjsonRotateAndRetention(t *testing.T) {
+	cfg := newTestConfig(exporters_cfg.FILE_FORMAT_NDJSON, exporters_cfg.FILE_COMPRESSION_GZIP)
+	cfg.FileDir = t.TempDir()
+	counter := &Counter{}
+	w, err := newRotatingWriter(cfg, uint32(exporters_cfg.L7_FLOW_LOG), 0, 0, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 10; j++ {
+			if err := w.Write(testItem); err != nil {
+				t.Fatal(err)
+			}
+		}
+		now = now.Add(time.Duration(cfg.FileRotateInterval+1) * time.Second)
+		w.Tick(now)
+	}
+	if counter.RotateCounter != 4 || counter.RemoveCounter != 2 {
+		t.Errorf("expected 4 rotations and 2 removals, actual %d and %d", counter.RotateCounter, counter.RemoveCounter)
+	}
+
+	files := finishedFiles(t, filepath.Join(cfg.FileDir, "flow_log.l7_flow_log"))
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files kept, actual %v", files)
+	}
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".ndjson.gz") {
+			t.Errorf("unexpected file name %s", file)
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := 0
+		scanner := bufio.NewScanner(gz)
+		for scanner.Scan() {
+			if scanner.Text() != testItem {
+				t.Errorf("unexpected line %s", scanner.Text())
+			}
+			lines++
+		}
+		f.Close()
+		if lines != 10 {
+			t.Errorf("expected 10 lines in %s, actual %d", file, lines)
+		}
+	}
+}
+
+func TestParquetWrite(t *testing.T) {
+	cfg := newTestConfig(exporters_cfg.FILE_FORMAT_PARQUET, "")
+	cfg.FileDir = t.TempDir()
+	w, err := newRotatingWriter(cfg, uint32(exporters_cfg.L7_FLOW_LOG), 0, 0, &Counter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := w.Write(testItem); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+
+	files := finishedFiles(t, filepath.Join(cfg.FileDir, "flow_log.l7_flow_log"))
+	if len(files) != 1 || !strings.HasSuffix(files[0], ".parquet") {
+		t.Fatalf("expected 1 parquet file, actual %v", files)
+	}
+	pf, err := local.NewLocalFileReader(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pf.Close()
+	pr, err := reader.NewParquetColumnReader(pf, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+	if pr.GetNumRows() != 3 {
+		t.Fatalf("expected 3 rows, actual %d", pr.GetNumRows())
+	}
+
+	expected := map[int64]interface{}{
+		1: int64(200),
+		2: int64(-1), // uint64 max saved as INT64
+		3: "HTTP",
+		4: "r1",
+		5: 1.5,
+		6: `["a","b"]`,
+		7: nil,
+	}
+	for index, value := range expected {
+		values, _, _, err := pr.ReadColumnByIndex(index, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(values) != 3 || !reflect.DeepEqual(values[0], value) {
+			t.Errorf("column %d expected %v, actual %v", index, value, values)
+		}
+	}
+}
+
+func TestParquetSize(t *testing.T) {
+	cfg := newTestConfig(exporters_cfg.FILE_FORMAT_PARQUET, "")
+	w, err := newParquetWriter(io.Discard, cfg, uint32(exporters_cfg.L7_FLOW_LOG))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := w.Write(testItem); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the rows are buffered in memory, each row has 15 bytes of strings and 3 numbers
+	if size := w.Size(); size < 100*(15+3*8) {
+		t.Errorf("expected the buffered rows to be counted, actual size %d", size)
+	}
+}
@@ -0,0 +1,328 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http_exporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	logging "github.com/op/go-logging"
+
+	ingester_common "github.com/khulnasoft/deepflow/server/ingester/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/common"
+	exporters_cfg "github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	utag "github.com/khulnasoft/deepflow/server/ingester/exporters/universal_tag"
+	"github.com/khulnasoft/deepflow/server/ingester/ingesterctl"
+	"github.com/khulnasoft/deepflow/server/libs/debug"
+	"github.com/khulnasoft/deepflow/server/libs/queue"
+	"github.com/khulnasoft/deepflow/server/libs/stats"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+)
+
+var log = logging.MustGetLogger("http_exporter")
+
+const (
+	QUEUE_BATCH_COUNT = 1024
+	// the max backoff between retries
+	MAX_RETRY_BACKOFF = 30 * time.Second
+)
+
+type HttpExporter struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	index                 int
+	dataQueues            queue.FixedMultiQueue
+	queueCount            int
+	requestFailedCounters []int
+	client                *http.Client
+
+	universalTagsManager *utag.UniversalTagsManager
+	config               *exporters_cfg.ExporterCfg
+	counter              *Counter
+	lastCounter          Counter
+	running              bool
+
+	utils.Closable
+}
+
+type Counter struct {
+	RecvCounter      int64 `statsd:"recv-count"`
+	SendCounter      int64 `statsd:"send-count"`
+	SendBatchCounter int64 `statsd:"send-batch-count"`
+	RetryCounter     int64 `statsd:"retry-count"`
+	DropCounter      int64 `statsd:"drop-count"`
+	DropBatchCounter int64 `statsd:"drop-batch-count"`
+	ExportUsedTimeNs int64 `statsd:"export-used-time-ns"`
+}
+
+func (e *HttpExporter) GetCounter() interface{} {
+	var counter Counter
+	counter, *e.counter = *e.counter, Counter{}
+	e.lastCounter = counter
+	return &counter
+}
+
+func NewHttpExporter(index int, config *exporters_cfg.ExporterCfg, universalTagsManager *utag.UniversalTagsManager) *HttpExporter {
+	dataQueues := queue.NewOverwriteQueues(
+		fmt.Sprintf("http_exporter_%d", index), queue.HashKey(config.QueueCount), config.QueueSize,
+		queue.OptionFlushIndicator(time.Second),
+		queue.OptionRelease(func(p interface{}) { p.(common.ExportItem).Release() }),
+		ingester_common.QUEUE_STATS_MODULE_INGESTER)
+
+	exporter := newHttpExporter(index, config, universalTagsManager)
+	exporter.dataQueues = dataQueues
+	debug.ServerRegisterSimple(ingesterctl.CMD_HTTP_EXPORTER, exporter)
+	ingester_common.RegisterCountableForIngester("exporter", exporter, stats.OptionStatTags{
+		"type": "http", "index": strconv.Itoa(index)})
+	log.Infof("http exporter %d created", index)
+	return exporter
+}
+
+func newHttpExporter(index int, config *exporters_cfg.ExporterCfg, universalTagsManager *utag.UniversalTagsManager) *HttpExporter {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &HttpExporter{
+		index:                 index,
+		queueCount:            config.QueueCount,
+		requestFailedCounters: make([]int, config.QueueCount),
+		client:                &http.Client{Timeout: time.Duration(config.HttpTimeout) * time.Second},
+		universalTagsManager:  universalTagsManager,
+		config:                config,
+		counter:               &Counter{},
+		ctx:                   ctx,
+		cancel:                cancel,
+	}
+}
+
+func (e *HttpExporter) Put(items ...interface{}) {
+	e.counter.RecvCounter++
+	e.dataQueues.Put(queue.HashKey(int(e.counter.RecvCounter)%e.queueCount), items...)
+}
+
+func (e *HttpExporter) Start() {
+	if e.running {
+		log.Warningf("http exporter %d already running", e.index)
+		return
+	}
+	e.running = true
+	for i := 0; i < e.queueCount; i++ {
+		go e.queueProcess(int(i))
+	}
+	log.Infof("http exporter %d started %d queue", e.index, e.queueCount)
+}
+
+func (e *HttpExporter) Close() {
+	e.Closable.Close()
+	e.running = false
+	e.cancel()
+	log.Infof("http exporter %d stopping", e.index)
+}
+
+func (e *HttpExporter) queueProcess(queueID int) {
+	items := make([]interface{}, QUEUE_BATCH_COUNT)
+	batch := make([]string, 0, e.config.BatchSize)
+
+	for e.running {
+		n := e.dataQueues.Gets(queue.HashKey(queueID), items)
+		for _, item := range items[:n] {
+			if item == nil {
+				e.exportBatch(queueID, batch)
+				batch = batch[:0]
+				continue
+			}
+			exportItem, ok := item.(common.ExportItem)
+			if !ok {
+				e.counter.DropCounter++
+				continue
+			}
+
+			json, err := exportItem.EncodeTo(exporters_cfg.PROTOCOL_HTTP, e.universalTagsManager, e.config)
+			if err != nil {
+				if e.counter.DropCounter == 0 {
+					log.Warningf("http encode failed, err: %s", err)
+				}
+				e.counter.DropCounter++
+				exportItem.Release()
+				continue
+			}
+			// items encoded to "" are invalid, see common.EncodeToJson
+			if jsonStr := json.(string); jsonStr != "" {
+				batch = append(batch, jsonStr)
+			}
+			if len(batch) >= e.config.BatchSize {
+				e.exportBatch(queueID, batch)
+				batch = batch[:0]
+			}
+			exportItem.Release()
+		}
+	}
+}
+
+func (e *HttpExporter) exportBatch(queueID int, batch []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Warningf("http export error: %s", r)
+		}
+	}()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	now := time.Now()
+	if err := e.sendWithRetry(queueID, batch); err != nil {
+		if e.counter.DropCounter == 0 {
+			log.Warningf("exporter %d send http request failed, requestFailedCounter=%d, err: %s", e.index, e.requestFailedCounters[queueID], err)
+		}
+		e.counter.DropCounter += int64(len(batch))
+		e.counter.DropBatchCounter++
+	} else {
+		e.counter.SendCounter += int64(len(batch))
+		e.counter.SendBatchCounter++
+	}
+	e.counter.ExportUsedTimeNs += int64(time.Since(now))
+}
+
+// encodeBody joins the json items to a json array or newline delimited json, and compresses it if gzip is enabled
+func encodeBody(batch []string, format string, gzipEnabled bool) ([]byte, error) {
+	size := len(batch) + 1
+	for _, item := range batch {
+		size += len(item)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	if format == exporters_cfg.HTTP_FORMAT_NDJSON {
+		for _, item := range batch {
+			buf.WriteString(item)
+			buf.WriteByte('\n')
+		}
+	} else {
+		buf.WriteByte('[')
+		for i, item := range batch {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(item)
+		}
+		buf.WriteByte(']')
+	}
+	if !gzipEnabled {
+		return buf.Bytes(), nil
+	}
+
+	compressed := &bytes.Buffer{}
+	writer := gzip.NewWriter(compressed)
+	if _, err := writer.Write(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// retryableError means the request may succeed by sending again
+type retryableError struct {
+	error
+}
+
+func (e *HttpExporter) sendWithRetry(queueID int, batch []string) error {
+	if len(e.config.RandomEndpoints) == 0 {
+		return fmt.Errorf("no endpoints configured")
+	}
+	body, err := encodeBody(batch, e.config.HttpFormat, e.config.HttpGzip)
+	if err != nil {
+		return err
+	}
+
+	backoff := time.Duration(e.config.HttpRetryBackoff) * time.Millisecond
+	for retry := 0; ; retry++ {
+		err = e.sendRequest(queueID, body)
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(retryableError); !ok || retry >= e.config.HttpMaxRetries {
+			return err
+		}
+		e.counter.RetryCounter++
+		select {
+		case <-e.ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > MAX_RETRY_BACKOFF {
+			backoff = MAX_RETRY_BACKOFF
+		}
+	}
+}
+
+func (e *HttpExporter) getEndpoint(queueID int) string {
+	l := len(e.config.RandomEndpoints)
+	return e.config.RandomEndpoints[e.requestFailedCounters[queueID]%l]
+}
+
+func (e *HttpExporter) sendRequest(queueID int, body []byte) error {
+	// switch to the next endpoint after a failure
+	endpoint := e.getEndpoint(queueID)
+	req, err := http.NewRequestWithContext(e.ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		e.requestFailedCounters[queueID]++
+		return err
+	}
+
+	if e.config.HttpFormat == exporters_cfg.HTTP_FORMAT_NDJSON {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if e.config.HttpGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	// inject extra headers
+	for k, v := range e.config.ExtraHeaders {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		e.requestFailedCounters[queueID]++
+		return retryableError{err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		e.requestFailedCounters[queueID]++
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		err = fmt.Errorf("%s returned HTTP status %s: %s", endpoint, resp.Status, respBody)
+		// the other 4xx errors are caused by the request itself, retrying is useless
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout {
+			return retryableError{err}
+		}
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (e *HttpExporter) HandleSimpleCommand(op uint16, arg string) string {
+	return fmt.Sprintf("http exporter %d last 10s counter: %+v", e.index, e.lastCounter)
+}
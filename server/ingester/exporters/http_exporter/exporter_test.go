@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http_exporter
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	exporters_cfg "github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+)
+
+func TestEncodeBody(t *testing.T) {
+	batch := []string{`{"a":1}`, `{"b":2}`}
+	testCases := []struct {
+		format string
+		output string
+	}{
+		{exporters_cfg.HTTP_FORMAT_JSON, `[{"a":1},{"b":2}]`},
+		{exporters_cfg.HTTP_FORMAT_NDJSON, "{\"a\":1}\n{\"b\":2}\n"},
+	}
+	for _, tc := range testCases {
+		body, err := encodeBody(batch, tc.format, false)
+		if err != nil || string(body) != tc.output {
+			t.Errorf("encode %s, expected %s, actual %s, err %v", tc.format, tc.output, body, err)
+		}
+	}
+}
+
+func newTestExporter(endpoints []string, gzipEnabled bool) *HttpExporter {
+	cfg := &exporters_cfg.ExporterCfg{
+		Protocol:         "http",
+		Endpoints:        endpoints,
+		QueueCount:       1,
+		HttpGzip:         gzipEnabled,
+		HttpRetryBackoff: 1,
+		ExtraHeaders:     map[string]string{"Authorization": "Bearer abc"},
+	}
+	cfg.Validate()
+	return newHttpExporter(0, cfg, nil)
+}
+
+func TestSendWithRetry(t *testing.T) {
+	requests := 0
+	var body, contentEncoding, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		contentEncoding, auth = r.Header.Get("Content-Encoding"), r.Header.Get("Authorization")
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(reader)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	e := newTestExporter([]string{server.URL}, true)
+	if err := e.sendWithRetry(0, []string{`{"a":1}`}); err != nil {
+		t.Fatalf("send failed: %s", err)
+	}
+	if requests != 3 || e.counter.RetryCounter != 2 {
+		t.Errorf("expected 3 requests with 2 retries, actual %d requests with %d retries", requests, e.counter.RetryCounter)
+	}
+	if body != `[{"a":1}]` || contentEncoding != "gzip" || auth != "Bearer abc" {
+		t.Errorf("unexpected request, body %s, content-encoding %s, authorization %s", body, contentEncoding, auth)
+	}
+}
+
+func TestSendNotRetryClientError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	e := newTestExporter([]string{server.URL}, false)
+	if err := e.sendWithRetry(0, []string{`{"a":1}`}); err == nil {
+		t.Fatal("send should fail")
+	}
+	if requests != 1 {
+		t.Errorf("4xx errors should not be retried, actual %d requests", requests)
+	}
+}
@@ -24,6 +24,8 @@ import (
 
 	logging "github.com/op/go-logging"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"golang.org/x/net/context"
@@ -53,6 +55,7 @@ type OtlpExporter struct {
 	dataQueues           queue.FixedMultiQueue
 	queueCount           int
 	grpcExporters        []ptraceotlp.GRPCClient
+	grpcLogExporters     []plogotlp.GRPCClient
 	grpcConns            []*grpc.ClientConn
 	grpcFailedCounters   []int
 	universalTagsManager *utag.UniversalTagsManager
@@ -95,6 +98,7 @@ func NewOtlpExporter(index int, config *exporters_cfg.ExporterCfg, universalTags
 		grpcConns:            make([]*grpc.ClientConn, config.QueueCount),
 		grpcFailedCounters:   make([]int, config.QueueCount),
 		grpcExporters:        make([]ptraceotlp.GRPCClient, config.QueueCount),
+		grpcLogExporters:     make([]plogotlp.GRPCClient, config.QueueCount),
 		config:               config,
 		counter:              &Counter{},
 	}
@@ -130,6 +134,7 @@ func (e *OtlpExporter) Close() {
 func (e *OtlpExporter) queueProcess(queueID int) {
 	var batchCount int
 	traces := ptrace.NewTraces()
+	logs := plog.NewLogs()
 	items := make([]interface{}, QUEUE_BATCH_COUNT)
 
 	ctx := context.Background()
@@ -142,12 +147,22 @@ func (e *OtlpExporter) queueProcess(queueID int) {
 			return
 		}
 
-		if err := e.grpcExport(ctx, queueID, ptraceotlp.NewExportRequestFromTraces(traces)); err == nil {
+		var err error
+		if traces.ResourceSpans().Len() > 0 {
+			err = e.grpcExport(ctx, queueID, ptraceotlp.NewExportRequestFromTraces(traces))
+			log.Debugf(tracesToString(traces))
+		}
+		if logs.ResourceLogs().Len() > 0 {
+			if logsErr := e.grpcExport(ctx, queueID, plogotlp.NewExportRequestFromLogs(logs)); logsErr != nil {
+				err = logsErr
+			}
+		}
+		if err == nil {
 			e.counter.SendCounter += int64(batchCount)
 		}
 		batchCount = 0
-		log.Debugf(tracesToString(traces))
 		traces = ptrace.NewTraces()
+		logs = plog.NewLogs()
 	}
 
 	for e.running {
@@ -173,8 +188,19 @@ func (e *OtlpExporter) queueProcess(queueID int) {
 				exportItem.Release()
 				continue
 			}
-			rsSlice := dst.(ptrace.ResourceSpansSlice)
-			rsSlice.MoveAndAppendTo(traces.ResourceSpans())
+			switch dst := dst.(type) {
+			case ptrace.ResourceSpansSlice:
+				dst.MoveAndAppendTo(traces.ResourceSpans())
+			case plog.ResourceLogsSlice:
+				dst.MoveAndAppendTo(logs.ResourceLogs())
+			default:
+				if e.counter.DropCounter == 0 {
+					log.Warningf("otlp exporter unsupport encoded data type %T", dst)
+				}
+				e.counter.DropCounter++
+				exportItem.Release()
+				continue
+			}
 
 			batchCount++
 			if batchCount >= e.config.BatchSize {
@@ -185,7 +211,12 @@ func (e *OtlpExporter) queueProcess(queueID int) {
 	}
 }
 
-func (e *OtlpExporter) grpcExport(ctx context.Context, queueID int, req ptraceotlp.ExportRequest) error {
+// the request is ptraceotlp.ExportRequest or plogotlp.ExportRequest
+type exportRequest interface {
+	MarshalJSON() ([]byte, error)
+}
+
+func (e *OtlpExporter) grpcExport(ctx context.Context, queueID int, req exportRequest) error {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Warningf("grpc otlp export error: %s", r)
@@ -206,13 +237,22 @@ func (e *OtlpExporter) grpcExport(ctx context.Context, queueID int, req ptraceot
 			return err
 		}
 	}
-	_, err := e.grpcExporters[queueID].Export(ctx, req)
+	var err error
+	switch r := req.(type) {
+	case ptraceotlp.ExportRequest:
+		_, err = e.grpcExporters[queueID].Export(ctx, r)
+	case plogotlp.ExportRequest:
+		_, err = e.grpcLogExporters[queueID].Export(ctx, r)
+	default:
+		err = fmt.Errorf("unsupport otlp request type %T", req)
+	}
 	if err != nil {
 		if e.counter.DropCounter == 0 {
-			log.Warningf("otlp exporter %d send grpc traces failed. faildCounter=%d, err: %s", e.index, e.grpcFailedCounters[queueID], err)
+			log.Warningf("otlp exporter %d send grpc data failed. faildCounter=%d, err: %s", e.index, e.grpcFailedCounters[queueID], err)
 		}
 		e.counter.DropCounter++
 		e.grpcExporters[queueID] = nil
+		e.grpcLogExporters[queueID] = nil
 		return err
 	} else {
 		e.counter.SendBatchCounter++
@@ -249,6 +289,7 @@ func (e *OtlpExporter) newGrpcExporter(queueID int) error {
 
 	e.grpcConns[queueID] = conn
 	e.grpcExporters[queueID] = ptraceotlp.NewGRPCClient(conn)
+	e.grpcLogExporters[queueID] = plogotlp.NewGRPCClient(conn)
 	return nil
 }
 
@@ -128,7 +128,7 @@ func (e *PrometheusExporter) Start() {
 
 func (e *PrometheusExporter) Close() {
 	e.running = false
-	e.Close()
+	e.Closable.Close()
 	e.cancel()
 	log.Infof("promethues exporter %d stopping", e.index)
 }
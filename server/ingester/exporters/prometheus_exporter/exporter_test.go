@@ -0,0 +1,36 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus_exporter
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &PrometheusExporter{ctx: ctx, cancel: cancel, running: true}
+	// Close must close the embedded Closable rather than call itself again
+	e.Close()
+	if e.running || !e.Closed() {
+		t.Errorf("exporter should be stopped and closed, running %v, closed %v", e.running, e.Closed())
+	}
+	if ctx.Err() == nil {
+		t.Error("context of the exporter should be cancelled")
+	}
+}
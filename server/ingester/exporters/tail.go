@@ -0,0 +1,516 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exporters
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/tail"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/universal_tag"
+	"github.com/khulnasoft/deepflow/server/libs/debug"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+)
+
+const (
+	TAIL_QUEUE_SIZE = 1024
+
+	TAIL_KEY_DATASOURCE  = "datasource"
+	TAIL_KEY_AGENT_ID    = "agent_id"
+	TAIL_KEY_ORG_ID      = "org_id"
+	TAIL_KEY_L7_PROTOCOL = "l7_protocol"
+	TAIL_KEY_IP          = "ip"
+	TAIL_KEY_PORT        = "port"
+)
+
+// the fields of the records matched by each filter key
+var tailKeyFields = map[string][]string{
+	TAIL_KEY_DATASOURCE:  nil,
+	TAIL_KEY_AGENT_ID:    {"agent_id"},
+	TAIL_KEY_ORG_ID:      {"org_id"},
+	TAIL_KEY_L7_PROTOCOL: {"l7_protocol"},
+	TAIL_KEY_IP:          {"ip4", "ip4_0", "ip4_1", "ip6", "ip6_0", "ip6_1"},
+	TAIL_KEY_PORT:        {"client_port", "server_port"},
+}
+
+var tailAndRegexp = regexp.MustCompile(`(?i)\s+and\s+`)
+
+type tailCondition struct {
+	key         string
+	not         bool
+	values      []string
+	numbers     []float64
+	ipNets      []*net.IPNet
+	dataSources [config.MAX_DATASOURCE_ID]bool
+}
+
+type tailFilter struct {
+	conditions []tailCondition
+}
+
+func parseTailIPNet(str string) (*net.IPNet, error) {
+	if strings.Contains(str, "/") {
+		_, ipNet, err := net.ParseCIDR(str)
+		return ipNet, err
+	}
+	ip := net.ParseIP(str)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip %s", str)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// the datasource could be the full name 'flow_metrics.network.1m' or a part of it, such as 'network.1m' and 'flow_metrics'
+func matchTailDataSource(name, value string) bool {
+	return name == value || strings.HasSuffix(name, "."+value) || strings.HasPrefix(name, value+".")
+}
+
+func parseTailCondition(str string) (*tailCondition, error) {
+	c := &tailCondition{}
+	var key, values string
+	if pos := strings.Index(str, "!="); pos != -1 {
+		c.not = true
+		key, values = str[:pos], str[pos+2:]
+	} else if pos := strings.Index(str, "="); pos != -1 {
+		key, values = str[:pos], str[pos+1:]
+	} else {
+		return nil, fmt.Errorf("invalid condition '%s', should be 'key=values' or 'key!=values'", str)
+	}
+	c.key = strings.ToLower(strings.TrimSpace(key))
+	if _, ok := tailKeyFields[c.key]; !ok {
+		return nil, fmt.Errorf("unsupported key '%s' in condition '%s'", c.key, str)
+	}
+	for _, v := range strings.Split(values, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			c.values = append(c.values, v)
+		}
+	}
+	if len(c.values) == 0 {
+		return nil, fmt.Errorf("no value in condition '%s'", str)
+	}
+
+	for _, v := range c.values {
+		switch c.key {
+		case TAIL_KEY_DATASOURCE:
+			found := false
+			for i := config.DataSourceID(0); i < config.MAX_DATASOURCE_ID; i++ {
+				if name := i.String(); name != "" && matchTailDataSource(name, v) {
+					c.dataSources[i] = true
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unknown datasource '%s'", v)
+			}
+		case TAIL_KEY_IP:
+			ipNet, err := parseTailIPNet(v)
+			if err != nil {
+				return nil, err
+			}
+			c.ipNets = append(c.ipNets, ipNet)
+		case TAIL_KEY_L7_PROTOCOL:
+			// the l7_protocol could be a name, it is matched by the enum translation of the field
+			if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+				c.numbers = append(c.numbers, float64(n))
+			}
+		default:
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s '%s'", c.key, v)
+			}
+			c.numbers = append(c.numbers, float64(n))
+		}
+	}
+	return c, nil
+}
+
+func parseTailFilter(str string) (*tailFilter, error) {
+	f := &tailFilter{}
+	if str = strings.TrimSpace(str); str == "" {
+		return f, nil
+	}
+	for _, s := range tailAndRegexp.Split(str, -1) {
+		c, err := parseTailCondition(s)
+		if err != nil {
+			return nil, err
+		}
+		f.conditions = append(f.conditions, *c)
+	}
+	return f, nil
+}
+
+func (c *tailCondition) matchValue(tag *config.StructTags, value interface{}) bool {
+	switch c.key {
+	case TAIL_KEY_IP:
+		var ip net.IP
+		switch v := value.(type) {
+		case uint32:
+			ip = utils.IpFromUint32(v)
+		case net.IP:
+			ip = v
+		}
+		if len(ip) == 0 {
+			return false
+		}
+		for _, ipNet := range c.ipNets {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	number, _, ok := utils.ConvertToFloat64(value)
+	if !ok {
+		return false
+	}
+	for _, n := range c.numbers {
+		if n == number {
+			return true
+		}
+	}
+	if c.key == TAIL_KEY_L7_PROTOCOL && tag.EnumIntMap != nil {
+		name := tag.EnumIntMap[int(number)]
+		for _, v := range c.values {
+			if strings.EqualFold(v, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *tailCondition) match(item common.ExportItem, dataSourceId uint32, tags map[string]*config.StructTags) bool {
+	matched := false
+	if c.key == TAIL_KEY_DATASOURCE {
+		matched = c.dataSources[dataSourceId]
+	} else {
+		for _, field := range tailKeyFields[c.key] {
+			tag := tags[field]
+			if tag == nil {
+				continue
+			}
+			value := item.GetFieldValueByOffsetAndKind(tag.Offset, tag.DataKind, tag.DataType)
+			if utils.IsNil(value) {
+				continue
+			}
+			if c.matchValue(tag, value) {
+				matched = true
+				break
+			}
+		}
+	}
+	return matched != c.not
+}
+
+func (f *tailFilter) match(item common.ExportItem, dataSourceId uint32, tags map[string]*config.StructTags) bool {
+	for i := range f.conditions {
+		if !f.conditions[i].match(item, dataSourceId, tags) {
+			return false
+		}
+	}
+	return true
+}
+
+type tailSession struct {
+	conn     *net.UDPConn
+	remote   *net.UDPAddr
+	request  tail.Request
+	filter   *tailFilter
+	utags    *universal_tag.UniversalTagsManager
+	ch       chan string
+	stop     chan struct{}
+	stopOnce sync.Once
+	reason   string
+
+	heartbeatInterval time.Duration
+
+	windowLock   sync.Mutex
+	windowSecond int64
+	windowCount  int
+
+	sent        uint64
+	rateLimited uint64
+	queueFull   uint64
+	tooLarge    uint64
+}
+
+func sendTailMessage(conn *net.UDPConn, remote *net.UDPAddr, msgType uint8, data string) {
+	buffer, err := tail.EncodeMessage(msgType, data)
+	if err != nil {
+		log.Warningf("tail encode message failed: %s", err)
+		return
+	}
+	debug.SendToClient(conn, remote, 0, buffer)
+}
+
+// rate limit by a fixed window of one second
+func (s *tailSession) allow() bool {
+	now := time.Now().Unix()
+	s.windowLock.Lock()
+	defer s.windowLock.Unlock()
+	if now != s.windowSecond {
+		s.windowSecond = now
+		s.windowCount = 0
+	}
+	if s.windowCount >= s.request.Rate {
+		return false
+	}
+	s.windowCount++
+	return true
+}
+
+func (s *tailSession) close(reason string) {
+	s.stopOnce.Do(func() {
+		s.reason = reason
+		close(s.stop)
+	})
+}
+
+func (s *tailSession) run() {
+	timer := time.NewTimer(s.request.Duration)
+	defer timer.Stop()
+	heartbeat := time.NewTicker(s.heartbeatInterval)
+	defer heartbeat.Stop()
+	idle := true
+	for {
+		select {
+		case record := <-s.ch:
+			sendTailMessage(s.conn, s.remote, tail.TAIL_MSG_RECORD, record)
+			s.sent++
+			idle = false
+		case <-heartbeat.C:
+			if idle {
+				sendTailMessage(s.conn, s.remote, tail.TAIL_MSG_HEARTBEAT, "")
+			}
+			idle = true
+		case <-timer.C:
+			s.close("timeout")
+		case <-s.stop:
+			msg := fmt.Sprintf("tail stopped for %s, sent %d, dropped by rate limit %d, dropped by queue full %d, dropped by too large %d",
+				s.reason, s.sent, atomic.LoadUint64(&s.rateLimited), atomic.LoadUint64(&s.queueFull), atomic.LoadUint64(&s.tooLarge))
+			log.Info(msg)
+			sendTailMessage(s.conn, s.remote, tail.TAIL_MSG_END, msg)
+			return
+		}
+	}
+}
+
+// Tail sends the records which are put to the exporters to deepflow-ctl, only one session is running at the same time
+type Tail struct {
+	exporters *Exporters
+	config    *config.ExporterCfg
+
+	initOnces [config.MAX_DATASOURCE_ID]sync.Once
+	tags      [config.MAX_DATASOURCE_ID]map[string]*config.StructTags
+
+	utagsLock sync.Mutex
+	utags     *universal_tag.UniversalTagsManager
+	ownUtags  bool
+
+	active  int32
+	lock    sync.RWMutex
+	session *tailSession
+}
+
+// the tail exports all fields, and encodes them as the file exporter does
+func newTailExporterCfg() *config.ExporterCfg {
+	exportFields := []string{config.CATEGORY_TAG, config.CATEGORY_TAG + ".event_info", config.CATEGORY_METRICS, config.CATEGORY_K8S_LABEL}
+	return &config.ExporterCfg{
+		Protocol:                "tail",
+		Enabled:                 true,
+		ExportProtocol:          config.PROTOCOL_FILE,
+		ExportFields:            exportFields,
+		ExportFieldCategoryBits: config.StringsToCategoryBits(exportFields),
+		ExportFieldNames:        exportFields,
+		ExportFieldK8s:          config.GetK8sLabelConfigs(exportFields),
+	}
+}
+
+func NewTail(exporters *Exporters, module debug.ModuleId) *Tail {
+	t := &Tail{
+		exporters: exporters,
+		config:    newTailExporterCfg(),
+	}
+	debug.Register(module, t)
+	return t
+}
+
+func (t *Tail) initStructTags(item common.ExportItem, dataSourceId uint32) map[string]*config.StructTags {
+	t.initOnces[dataSourceId].Do(func() {
+		t.exporters.initStructTags(item, dataSourceId, t.config)
+		structTags := t.config.ExportFieldStructTags[dataSourceId]
+		tags := make(map[string]*config.StructTags, len(structTags))
+		for i := range structTags {
+			tags[structTags[i].Name] = &structTags[i]
+		}
+		t.tags[dataSourceId] = tags
+	})
+	return t.tags[dataSourceId]
+}
+
+func (t *Tail) getUniversalTagsManager() *universal_tag.UniversalTagsManager {
+	t.utagsLock.Lock()
+	defer t.utagsLock.Unlock()
+	if t.utags != nil {
+		return t.utags
+	}
+	for _, v := range t.exporters.universalTagsManagerMap {
+		t.utags = v
+		return t.utags
+	}
+	t.utags = universal_tag.NewUniversalTagsManager(t.config.ExportFieldK8s, t.exporters.config.Base)
+	t.utags.Start()
+	t.ownUtags = true
+	return t.utags
+}
+
+func (t *Tail) Put(dataSourceId uint32, item common.ExportItem) {
+	if atomic.LoadInt32(&t.active) == 0 {
+		return
+	}
+	t.lock.RLock()
+	s := t.session
+	t.lock.RUnlock()
+	if s == nil {
+		return
+	}
+
+	tags := t.initStructTags(item, dataSourceId)
+	if !s.filter.match(item, dataSourceId, tags) {
+		return
+	}
+	if !s.allow() {
+		atomic.AddUint64(&s.rateLimited, 1)
+		return
+	}
+	data, err := item.EncodeTo(config.PROTOCOL_FILE, s.utags, t.config)
+	if err != nil {
+		log.Debugf("tail encode failed: %s", err)
+		return
+	}
+	record, ok := data.(string)
+	if !ok || record == "" {
+		return
+	}
+	if len(record) > tail.MAX_RECORD_LEN {
+		atomic.AddUint64(&s.tooLarge, 1)
+		return
+	}
+	select {
+	case s.ch <- record:
+	default:
+		atomic.AddUint64(&s.queueFull, 1)
+	}
+}
+
+func (t *Tail) newSession(conn *net.UDPConn, remote *net.UDPAddr, request *tail.Request) (*tailSession, error) {
+	request.Validate()
+	filter, err := parseTailFilter(request.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return &tailSession{
+		conn:    conn,
+		remote:  remote,
+		request: *request,
+		filter:  filter,
+		utags:   t.getUniversalTagsManager(),
+		ch:      make(chan string, TAIL_QUEUE_SIZE),
+		stop:    make(chan struct{}),
+
+		heartbeatInterval: tail.HEARTBEAT_INTERVAL,
+	}, nil
+}
+
+func (t *Tail) start(s *tailSession) {
+	t.lock.Lock()
+	if t.session != nil {
+		t.session.close(fmt.Sprintf("replaced by the session of %s", s.remote))
+	}
+	t.session = s
+	atomic.StoreInt32(&t.active, 1)
+	t.lock.Unlock()
+
+	go func() {
+		s.run()
+		t.lock.Lock()
+		if t.session == s {
+			t.session = nil
+			atomic.StoreInt32(&t.active, 0)
+		}
+		t.lock.Unlock()
+	}()
+}
+
+func (t *Tail) stop(reason string) {
+	t.lock.Lock()
+	if t.session != nil {
+		t.session.close(reason)
+	}
+	t.lock.Unlock()
+}
+
+func (t *Tail) RecvCommand(conn *net.UDPConn, remote *net.UDPAddr, operate uint16, arg *bytes.Buffer) {
+	switch operate {
+	case tail.TAIL_CMD_START:
+		request := tail.Request{}
+		decoder := gob.NewDecoder(arg)
+		if err := decoder.Decode(&request); err != nil {
+			log.Warningf("tail decode request failed: %s", err)
+			sendTailMessage(conn, remote, tail.TAIL_MSG_ERROR, err.Error())
+			return
+		}
+		s, err := t.newSession(conn, remote, &request)
+		if err != nil {
+			sendTailMessage(conn, remote, tail.TAIL_MSG_ERROR, err.Error())
+			return
+		}
+		msg := fmt.Sprintf("tail started for %s, filter: '%s', rate: %d/s, duration: %s", remote, request.Filter, request.Rate, request.Duration)
+		log.Info(msg)
+		// reply before starting, so that the reply is the first message received by the client
+		sendTailMessage(conn, remote, tail.TAIL_MSG_INFO, msg)
+		t.start(s)
+	case tail.TAIL_CMD_STOP:
+		t.stop(fmt.Sprintf("stopped by %s", remote))
+		debug.SendToClient(conn, remote, 0, nil)
+	default:
+		log.Warningf("tail recv unknown command (%v).", operate)
+	}
+}
+
+func (t *Tail) Close() {
+	t.stop("ingester closed")
+	t.utagsLock.Lock()
+	if t.ownUtags {
+		t.utags.Close()
+	}
+	t.utagsLock.Unlock()
+}
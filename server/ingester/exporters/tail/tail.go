@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tail
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/khulnasoft/deepflow/server/libs/debug"
+)
+
+const (
+	TAIL_CMD_START = iota
+	TAIL_CMD_STOP
+)
+
+const (
+	TAIL_MSG_RECORD = iota
+	TAIL_MSG_INFO
+	TAIL_MSG_ERROR
+	TAIL_MSG_END
+	TAIL_MSG_HEARTBEAT
+)
+
+const (
+	DEFAULT_RATE     = 100
+	MAX_RATE         = 10000
+	DEFAULT_DURATION = time.Minute
+	MAX_DURATION     = 10 * time.Minute
+
+	// sent when no record is sent in the interval, so that the debug stream, which ends after
+	// debug.STREAM_IDLE_TIMEOUT without any message, is kept open while no record matches
+	HEARTBEAT_INTERVAL = 10 * time.Second
+
+	// the record is gob encoded in Message and then in debug.DebugMessage, reserve some bytes for them
+	MAX_RECORD_LEN = debug.MAX_PAYLOAD_LEN - 128
+)
+
+// Request starts a tail session on the ingester
+type Request struct {
+	Filter   string        // e.g. 'datasource=l7_flow_log and l7_protocol=HTTP,DNS and ip=10.1.0.0/16'
+	Rate     int           // max records sent per second
+	Duration time.Duration // the session stops automatically after it
+}
+
+// Message is sent from the ingester to the client for each record and state change
+type Message struct {
+	Type uint8
+	Data string
+}
+
+func (r *Request) Validate() {
+	if r.Rate <= 0 {
+		r.Rate = DEFAULT_RATE
+	} else if r.Rate > MAX_RATE {
+		r.Rate = MAX_RATE
+	}
+	if r.Duration <= 0 {
+		r.Duration = DEFAULT_DURATION
+	} else if r.Duration > MAX_DURATION {
+		r.Duration = MAX_DURATION
+	}
+}
+
+func EncodeMessage(msgType uint8, data string) (*bytes.Buffer, error) {
+	buffer := &bytes.Buffer{}
+	encoder := gob.NewEncoder(buffer)
+	if err := encoder.Encode(Message{Type: msgType, Data: data}); err != nil {
+		return nil, err
+	}
+	return buffer, nil
+}
+
+func decodeMessage(buffer *bytes.Buffer) (*Message, error) {
+	msg := &Message{}
+	decoder := gob.NewDecoder(buffer)
+	if err := decoder.Decode(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func stopTail(moduleId debug.ModuleId) {
+	conn, _, _ := debug.SendToServer(moduleId, TAIL_CMD_STOP, nil)
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+func startTail(moduleId debug.ModuleId, request *Request) (debug.ClientConn, error) {
+	buffer := bytes.Buffer{}
+	encoder := gob.NewEncoder(&buffer)
+	if err := encoder.Encode(request); err != nil {
+		return nil, err
+	}
+	conn, result, err := debug.SendToServer(moduleId, TAIL_CMD_START, &buffer)
+	if err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, err
+	}
+	msg, err := decodeMessage(result)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if msg.Type == TAIL_MSG_ERROR {
+		conn.Close()
+		return nil, fmt.Errorf("%s", msg.Data)
+	}
+	fmt.Fprintln(os.Stderr, msg.Data)
+	return conn, nil
+}
+
+func recvRecords(moduleId debug.ModuleId, conn debug.ClientConn, duration time.Duration) {
+	defer conn.Close()
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(sigs)
+
+	// if the end message is lost, stop waiting a while after the session expires
+	deadline := time.Now().Add(duration + 5*time.Second)
+	for {
+		select {
+		case sig := <-sigs:
+			stopTail(moduleId)
+			fmt.Fprintf(os.Stderr, "signal %v\n", sig)
+			return
+		default:
+		}
+		if time.Now().After(deadline) {
+			fmt.Fprintln(os.Stderr, "tail session expired")
+			return
+		}
+
+		conn.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		buffer, err := debug.RecvFromServer(conn)
+		if err != nil {
+			if strings.Contains(err.Error(), "timeout") {
+				continue
+			}
+			stopTail(moduleId)
+			fmt.Fprintf(os.Stderr, "receive from ingester failed: %v\n", err)
+			return
+		}
+		msg, err := decodeMessage(buffer)
+		if err != nil {
+			stopTail(moduleId)
+			fmt.Fprintf(os.Stderr, "decode message failed: %v\n", err)
+			return
+		}
+		switch msg.Type {
+		case TAIL_MSG_RECORD:
+			fmt.Println(msg.Data)
+		case TAIL_MSG_INFO:
+			fmt.Fprintln(os.Stderr, msg.Data)
+		case TAIL_MSG_ERROR, TAIL_MSG_END:
+			fmt.Fprintln(os.Stderr, msg.Data)
+			return
+		case TAIL_MSG_HEARTBEAT:
+			// only keeps the stream open while no record matches
+		}
+	}
+}
+
+func RegisterCommand(moduleId debug.ModuleId) *cobra.Command {
+	request := &Request{}
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "print decoded records as JSON lines",
+		Long: `print the records decoded by ingester as JSON lines, the fields are encoded in the same way as the kafka/http/file exporters.
+
+filter expression is conditions joined by 'and', each condition is 'key=v1,v2' or 'key!=v1,v2', supported keys:
+  datasource   e.g. flow_log.l7_flow_log, l7_flow_log, flow_metrics.network.1m, event.perf_event
+  agent_id     agent ID
+  org_id       organization ID
+  l7_protocol  number or name, e.g. 20 or HTTP
+  ip           IP or CIDR, matches either side
+  port         matches client_port or server_port
+example:
+  deepflow-ctl ingester flow tail -f 'datasource=l7_flow_log and l7_protocol=HTTP and ip=10.1.0.0/16' --rate 10`,
+		Run: func(cmd *cobra.Command, args []string) {
+			request.Validate()
+			conn, err := startTail(moduleId, request)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "start tail failed: %v\n", err)
+				return
+			}
+			recvRecords(moduleId, conn, request.Duration)
+		},
+	}
+	cmd.Flags().StringVarP(&request.Filter, "filter", "f", "", "filter expression, match all records if empty")
+	cmd.Flags().IntVar(&request.Rate, "rate", DEFAULT_RATE, fmt.Sprintf("max records per second, up to %d", MAX_RATE))
+	cmd.Flags().DurationVar(&request.Duration, "duration", DEFAULT_DURATION, fmt.Sprintf("stop after the duration, up to %s", MAX_DURATION))
+	return cmd
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exporters
+
+import (
+	"encoding/gob"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/enum_translation"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/tail"
+	utag "github.com/khulnasoft/deepflow/server/ingester/exporters/universal_tag"
+	"github.com/khulnasoft/deepflow/server/libs/debug"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+)
+
+type tailTestItem struct {
+	AgentID    uint16 `json:"agent_id" category:"$tag" sub:"capture_info"`
+	OrgId      uint16 `json:"org_id" category:"$tag"`
+	IP40       uint32 `json:"ip4_0" category:"$tag" sub:"network_layer" to_string:"IPv4String"`
+	IP41       uint32 `json:"ip4_1" category:"$tag" sub:"network_layer" to_string:"IPv4String"`
+	ClientPort uint16 `json:"client_port" category:"$tag" sub:"transport_layer"`
+	ServerPort uint16 `json:"server_port" category:"$tag" sub:"transport_layer"`
+	L7Protocol uint8  `json:"l7_protocol" category:"$tag" sub:"application_layer" enumfile:"l7_protocol"`
+}
+
+func (i *tailTestItem) DataSource() uint32 {
+	return uint32(config.L7_FLOW_LOG)
+}
+
+func (i *tailTestItem) GetFieldValueByOffsetAndKind(offset uintptr, kind reflect.Kind, dataType utils.DataType) interface{} {
+	return utils.GetValueByOffsetAndKind(uintptr(unsafe.Pointer(i)), offset, kind, dataType)
+}
+
+func (i *tailTestItem) EncodeTo(p config.ExportProtocol, utags *utag.UniversalTagsManager, cfg *config.ExporterCfg) (interface{}, error) {
+	return common.EncodeToJson(i, int(i.DataSource()), cfg, nil, nil, nil, nil), nil
+}
+
+func (i *tailTestItem) TimestampUs() int64 {
+	return 0
+}
+
+func (i *tailTestItem) Release() {}
+
+func (i *tailTestItem) AddReferenceCount() {}
+
+func newTestTail() *Tail {
+	es := &Exporters{translation: enum_translation.NewEnumTranslation()}
+	return &Tail{exporters: es, config: newTailExporterCfg()}
+}
+
+func TestParseTailFilter(t *testing.T) {
+	testCases := []struct {
+		filter     string
+		conditions int
+		valid      bool
+	}{
+		{"", 0, true},
+		{"agent_id=1", 1, true},
+		{"datasource=l7_flow_log and l7_protocol=HTTP,20 AND ip!=10.0.0.0/8", 3, true},
+		{"datasource=network.1m and port=80,443 and org_id=1", 3, true},
+		{"ip=fe80::1", 1, true},
+		{"agent_id", 0, false},
+		{"pod=1", 0, false},
+		{"agent_id=abc", 0, false},
+		{"port=", 0, false},
+		{"ip=10.0.0.300", 0, false},
+		{"datasource=unknown", 0, false},
+	}
+	for _, tc := range testCases {
+		f, err := parseTailFilter(tc.filter)
+		if tc.valid != (err == nil) {
+			t.Errorf("filter '%s': expected valid %v, got error %v", tc.filter, tc.valid, err)
+			continue
+		}
+		if err == nil && len(f.conditions) != tc.conditions {
+			t.Errorf("filter '%s': expected %d conditions, got %d", tc.filter, tc.conditions, len(f.conditions))
+		}
+	}
+}
+
+func TestTailFilterMatch(t *testing.T) {
+	tl := newTestTail()
+	item := &tailTestItem{
+		AgentID:    3,
+		OrgId:      1,
+		IP40:       utils.IpToUint32([]byte{10, 1, 2, 3}),
+		IP41:       utils.IpToUint32([]byte{192, 168, 0, 1}),
+		ClientPort: 34567,
+		ServerPort: 80,
+		L7Protocol: 20,
+	}
+	tags := tl.initStructTags(item, item.DataSource())
+
+	testCases := []struct {
+		filter string
+		match  bool
+	}{
+		{"", true},
+		{"agent_id=3", true},
+		{"agent_id=1,2", false},
+		{"agent_id!=1,2", true},
+		{"org_id=1", true},
+		{"datasource=flow_log", true},
+		{"datasource=flow_log.l4_flow_log", false},
+		{"l7_protocol=http", true},
+		{"l7_protocol=20", true},
+		{"l7_protocol=DNS", false},
+		{"ip=192.168.0.0/16", true},
+		{"ip=10.1.2.3", true},
+		{"ip!=10.0.0.0/8", false},
+		{"port=80", true},
+		{"port=8080", false},
+		{"datasource=l7_flow_log and l7_protocol=HTTP and ip=10.1.0.0/16 and port=80", true},
+		{"datasource=l7_flow_log and agent_id=4", false},
+	}
+	for _, tc := range testCases {
+		f, err := parseTailFilter(tc.filter)
+		if err != nil {
+			t.Fatalf("filter '%s': %s", tc.filter, err)
+		}
+		if got := f.match(item, item.DataSource(), tags); got != tc.match {
+			t.Errorf("filter '%s': expected %v, got %v", tc.filter, tc.match, got)
+		}
+	}
+}
+
+func TestTailPut(t *testing.T) {
+	tl := newTestTail()
+	filter, err := parseTailFilter("l7_protocol=HTTP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &tailSession{
+		request: tail.Request{Rate: 2},
+		filter:  filter,
+		ch:      make(chan string, TAIL_QUEUE_SIZE),
+		stop:    make(chan struct{}),
+	}
+	tl.session = s
+	tl.active = 1
+
+	for i := 0; i < 5; i++ {
+		tl.Put(uint32(config.L7_FLOW_LOG), &tailTestItem{AgentID: 3, IP40: utils.IpToUint32([]byte{10, 1, 2, 3}), L7Protocol: 20})
+	}
+	tl.Put(uint32(config.L7_FLOW_LOG), &tailTestItem{AgentID: 3, L7Protocol: 40})
+
+	// the rate limit window may be reset when crossing a second
+	if len(s.ch) < 2 || len(s.ch) > 4 {
+		t.Fatalf("expected 2 records sent, got %d, rate limited %d", len(s.ch), s.rateLimited)
+	}
+	record := <-s.ch
+	for _, expected := range []string{`"datasource":"flow_log.l7_flow_log"`, `"ip4_0":"10.1.2.3"`, `"l7_protocol":"HTTP"`} {
+		if !strings.Contains(record, expected) {
+			t.Errorf("record %s does not contain %s", record, expected)
+		}
+	}
+}
+
+func TestTailSessionHeartbeat(t *testing.T) {
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverConn.Close()
+	clientConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientConn.Close()
+
+	s := &tailSession{
+		conn:              serverConn,
+		remote:            clientConn.LocalAddr().(*net.UDPAddr),
+		request:           tail.Request{Rate: 10, Duration: 500 * time.Millisecond},
+		ch:                make(chan string, TAIL_QUEUE_SIZE),
+		stop:              make(chan struct{}),
+		heartbeatInterval: 50 * time.Millisecond,
+	}
+	go s.run()
+
+	// no record matches, heartbeats are sent until the session expires
+	heartbeats := 0
+	clientConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		buffer, err := debug.RecvFromServer(clientConn)
+		if err != nil {
+			t.Fatalf("receive failed: %s", err)
+		}
+		msg := tail.Message{}
+		if err := gob.NewDecoder(buffer).Decode(&msg); err != nil {
+			t.Fatalf("decode failed: %s", err)
+		}
+		if msg.Type == tail.TAIL_MSG_END {
+			break
+		}
+		if msg.Type != tail.TAIL_MSG_HEARTBEAT {
+			t.Fatalf("unexpected message %+v", msg)
+		}
+		heartbeats++
+	}
+	if heartbeats < 3 {
+		t.Fatalf("expected heartbeats while idle, got %d", heartbeats)
+	}
+}
@@ -113,7 +113,10 @@ var idStrings = []string{
 
 type UniversalTags [MAX_TAG_ID]string
 
-func (u UniversalTags) GetTagValue(id uint8) string {
+func (u *UniversalTags) GetTagValue(id uint8) string {
+	if u == nil {
+		return ""
+	}
 	return u[id]
 }
 
@@ -264,7 +267,11 @@ func (u *UniversalTagsManager) getAuto(orgId uint16, autoType DeviceType, autoID
 }
 
 func (u *UniversalTagsManager) QueryCustomK8sLabels(orgId uint16, podID uint32) Labels {
-	return u.universalTagMaps[orgId].podK8SLabelMap[podID]
+	tagMaps := u.universalTagMaps[orgId]
+	if tagMaps == nil {
+		return nil
+	}
+	return tagMaps.podK8SLabelMap[podID]
 }
 
 type UniversalTagsManager struct {
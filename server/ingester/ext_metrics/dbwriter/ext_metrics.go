@@ -17,12 +17,25 @@
 package dbwriter
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+
 	"github.com/khulnasoft/deepflow/server/ingester/common"
+	exportercommon "github.com/khulnasoft/deepflow/server/ingester/exporters/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	utag "github.com/khulnasoft/deepflow/server/ingester/exporters/universal_tag"
 	"github.com/khulnasoft/deepflow/server/ingester/flow_tag"
 	"github.com/khulnasoft/deepflow/server/libs/ckdb"
 	"github.com/khulnasoft/deepflow/server/libs/datatype"
 	flow_metrics "github.com/khulnasoft/deepflow/server/libs/flow-metrics"
 	"github.com/khulnasoft/deepflow/server/libs/pool"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
 )
 
 const (
@@ -30,25 +43,28 @@ const (
 )
 
 type ExtMetrics struct {
-	Timestamp uint32 // s
+	pool.ReferenceCount
+
+	Timestamp uint32 `json:"time" category:"$tag" sub:"flow_info"` // s
 	MsgType   datatype.MessageType
 
 	UniversalTag flow_metrics.UniversalTag
 
-	VTableName string
+	VTableName string `json:"virtual_table_name" category:"$tag" sub:"flow_info"`
 
 	AgentID uint16
 
 	// Not stored, only determines which database to store in.
 	// When Orgid is 0 or 1, it is stored in database '<DatabaseName()>', otherwise stored in '<OrgId>_<DatabaseName()>'.
-	OrgId, RawOrgId uint16 // RawOrgId is read from server-stats message, only used to distinguish which database data is written to
-	TeamID          uint16
+	OrgId    uint16 `json:"org_id" category:"$tag"`
+	RawOrgId uint16 // RawOrgId is read from server-stats message, only used to distinguish which database data is written to
+	TeamID   uint16 `json:"team_id" category:"$tag"`
 
-	TagNames  []string
-	TagValues []string
+	TagNames  []string `json:"tag_names" category:"$tag" sub:"native_tag" data_type:"[]string"`
+	TagValues []string `json:"tag_values" category:"$tag" sub:"native_tag" data_type:"[]string"`
 
-	MetricsFloatNames  []string
-	MetricsFloatValues []float64
+	MetricsFloatNames  []string  `json:"metrics_float_names" category:"$metrics" data_type:"[]string"`
+	MetricsFloatValues []float64 `json:"metrics_float_values" category:"$metrics" data_type:"[]float64"`
 }
 
 func (m *ExtMetrics) IsValid() bool {
@@ -133,6 +149,106 @@ func (m *ExtMetrics) Release() {
 	ReleaseExtMetrics(m)
 }
 
+func (m *ExtMetrics) DataSource() uint32 {
+	return uint32(config.EXT_METRICS)
+}
+
+func (m *ExtMetrics) EncodeTo(protocol config.ExportProtocol, utags *utag.UniversalTagsManager, cfg *config.ExporterCfg) (interface{}, error) {
+	tags := m.QueryUniversalTags(utags)
+	switch protocol {
+	case config.PROTOCOL_PROMETHEUS:
+		return m.EncodeToPrometheus(cfg, tags), nil
+	case config.PROTOCOL_KAFKA, config.PROTOCOL_HTTP, config.PROTOCOL_FILE:
+		k8sLabels := utags.QueryCustomK8sLabels(m.OrgId, m.UniversalTag.PodID)
+		return exportercommon.EncodeToJson(m, int(m.DataSource()), cfg, tags, tags, k8sLabels, k8sLabels), nil
+	default:
+		return nil, fmt.Errorf("ext metrics unsupport export to %s", protocol)
+	}
+}
+
+// EncodeToPrometheus encodes each float metrics to a time series named '<measurement>_<field>' like the
+// prometheus output of telegraf, e.g.: 'cpu_usage_idle' of the virtual table 'influxdb.cpu'
+func (m *ExtMetrics) EncodeToPrometheus(cfg *config.ExporterCfg, tags *utag.UniversalTags) []prompb.TimeSeries {
+	labels := make([]prompb.Label, 0, len(m.TagNames)+16)
+	for i := range m.TagNames {
+		labels = append(labels, prompb.Label{
+			Name:  toPrometheusName(m.TagNames[i]),
+			Value: m.TagValues[i],
+		})
+	}
+	originLen := len(labels)
+	labels = exportercommon.AppendPrometheusLabels(labels, m, int(m.DataSource()), cfg, tags, tags)
+	exportLabels := labels[:originLen]
+	for _, l := range labels[originLen:] {
+		if hasPrometheusLabel(labels[:originLen], l.Name) {
+			continue
+		}
+		exportLabels = append(exportLabels, l)
+	}
+
+	measurement := m.VTableName
+	if i := strings.LastIndexByte(measurement, '.'); i >= 0 {
+		measurement = measurement[i+1:]
+	}
+	timeSeries := make([]prompb.TimeSeries, 0, len(m.MetricsFloatNames))
+	for i, name := range m.MetricsFloatNames {
+		value := m.MetricsFloatValues[i]
+		if cfg.ExportEmptyMetricsDisabled && value == 0 {
+			continue
+		}
+		ts := prompb.TimeSeries{}
+		ts.Labels = make([]prompb.Label, 0, len(exportLabels)+1)
+		ts.Labels = append(ts.Labels, prompb.Label{
+			Name:  model.MetricNameLabel,
+			Value: toPrometheusName(measurement + "_" + name),
+		})
+		ts.Labels = append(ts.Labels, exportLabels...)
+		sort.Slice(ts.Labels, func(i, j int) bool {
+			return ts.Labels[i].Name < ts.Labels[j].Name
+		})
+		ts.Samples = []prompb.Sample{{Value: value, Timestamp: int64(m.Timestamp) * 1000}} // convert to ms
+		timeSeries = append(timeSeries, ts)
+	}
+	return timeSeries
+}
+
+func hasPrometheusLabel(labels []prompb.Label, name string) bool {
+	for i := range labels {
+		if labels[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// toPrometheusName replaces the characters which are invalid in prometheus metric and label names with '_'
+func toPrometheusName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func (m *ExtMetrics) QueryUniversalTags(utags *utag.UniversalTagsManager) *utag.UniversalTags {
+	t := &m.UniversalTag
+	return utags.QueryUniversalTags(m.OrgId,
+		t.RegionID, t.AZID, t.HostID, t.PodNSID, t.PodClusterID, t.SubnetID, t.VTAPID,
+		uint8(t.L3DeviceType), t.AutoServiceType, t.AutoInstanceType,
+		t.L3DeviceID, t.AutoServiceID, t.AutoInstanceID, t.PodNodeID, t.PodGroupID, t.PodID, uint32(t.L3EpcID), t.GPID, t.ServiceID,
+		t.IsIPv6 == 0, t.IP, t.IP6,
+	)
+}
+
+func (m *ExtMetrics) GetFieldValueByOffsetAndKind(offset uintptr, kind reflect.Kind, dataType utils.DataType) interface{} {
+	return utils.GetValueByOffsetAndKind(uintptr(unsafe.Pointer(m)), offset, kind, dataType)
+}
+
+func (m *ExtMetrics) TimestampUs() int64 {
+	return int64(m.Timestamp) * 1000000
+}
+
 func (m *ExtMetrics) GenCKTable(cluster, storagePolicy, ckdbType string, ttl int, coldStorage *ckdb.ColdStorage) *ckdb.Table {
 	timeKey := "time"
 	engine := ckdb.MergeTree
@@ -249,12 +365,17 @@ var extMetricsPool = pool.NewLockFreePool(func() interface{} {
 })
 
 func AcquireExtMetrics() *ExtMetrics {
-	return extMetricsPool.Get().(*ExtMetrics)
+	m := extMetricsPool.Get().(*ExtMetrics)
+	m.Reset()
+	return m
 }
 
 var emptyUniversalTag = flow_metrics.UniversalTag{}
 
 func ReleaseExtMetrics(m *ExtMetrics) {
+	if m == nil || m.SubReferenceCount() {
+		return
+	}
 	m.UniversalTag = emptyUniversalTag
 	m.TagNames = m.TagNames[:0]
 	m.TagValues = m.TagValues[:0]
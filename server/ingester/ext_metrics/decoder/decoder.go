@@ -25,6 +25,9 @@ import (
 	logging "github.com/op/go-logging"
 
 	"github.com/khulnasoft/deepflow/server/ingester/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters"
+	exporterscommon "github.com/khulnasoft/deepflow/server/ingester/exporters/common"
+	exportersconfig "github.com/khulnasoft/deepflow/server/ingester/exporters/config"
 	"github.com/khulnasoft/deepflow/server/ingester/ext_metrics/config"
 	"github.com/khulnasoft/deepflow/server/ingester/ext_metrics/dbwriter"
 	"github.com/khulnasoft/deepflow/server/libs/ckdb"
@@ -61,6 +64,7 @@ type Decoder struct {
 	platformData      *grpc.PlatformInfoTable
 	inQueue           queue.QueueReader
 	extMetricsWriters [dbwriter.MAX_DB_ID]*dbwriter.ExtMetricsWriter
+	exporters         *exporters.Exporters
 	debugEnabled      bool
 	config            *config.Config
 
@@ -81,6 +85,7 @@ func NewDecoder(
 	platformData *grpc.PlatformInfoTable,
 	inQueue queue.QueueReader,
 	extMetricsWriters [dbwriter.MAX_DB_ID]*dbwriter.ExtMetricsWriter,
+	exporters *exporters.Exporters,
 	config *config.Config,
 ) *Decoder {
 	d := &Decoder{
@@ -90,6 +95,7 @@ func NewDecoder(
 		inQueue:           inQueue,
 		debugEnabled:      log.IsEnabledFor(logging.DEBUG),
 		extMetricsWriters: extMetricsWriters,
+		exporters:         exporters,
 		config:            config,
 		counter:           &Counter{},
 	}
@@ -119,6 +125,7 @@ func (d *Decoder) Run() {
 		n := d.inQueue.Gets(buffer)
 		for i := 0; i < n; i++ {
 			if buffer[i] == nil {
+				d.export(nil)
 				continue
 			}
 			d.counter.InCount++
@@ -175,10 +182,18 @@ func (d *Decoder) sendTelegraf(vtapID uint16, point models.Point) {
 		d.counter.ErrMetrics++
 		return
 	}
+	d.export(extMetrics)
 	d.extMetricsWriters[int(dbwriter.EXT_METRICS_DB_ID)].Write(extMetrics)
 	d.counter.OutCount++
 }
 
+func (d *Decoder) export(item exporterscommon.ExportItem) {
+	if d.exporters == nil {
+		return
+	}
+	d.exporters.Put(uint32(exportersconfig.EXT_METRICS), d.index, item)
+}
+
 func (d *Decoder) handleDeepflowStats(vtapID uint16, decoder *codec.SimpleDecoder) {
 	for !decoder.IsEnd() {
 		pbStats := &pb.Stats{}
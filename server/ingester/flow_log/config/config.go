@@ -25,6 +25,7 @@ import (
 
 	"github.com/khulnasoft/deepflow/server/ingester/config"
 	"github.com/khulnasoft/deepflow/server/ingester/config/configdefaults"
+	"github.com/khulnasoft/deepflow/server/libs/ckdb"
 )
 
 var log = logging.MustGetLogger("flow_log.config")
@@ -36,6 +37,13 @@ const (
 	DefaultDecoderQueueSize  = 4096
 	DefaultBrokerQueueSize   = 1 << 14
 	DefaultFlowLogTTL        = 72 // hour
+
+	DefaultOtlpHttpPort       = 4318
+	DefaultOtlpGrpcPort       = 4317
+	DefaultOtlpMaxRequestSize = 8 << 20 // byte
+
+	DefaultGeoIPLanguage       = "en"
+	DefaultGeoIPReloadInterval = 60 // second
 )
 
 type FlowLogTTL struct {
@@ -44,6 +52,32 @@ type FlowLogTTL struct {
 	L4Packet  int `yaml:"l4-packet"`
 }
 
+// OtlpReceiverConfig receives the OTLP traces sent directly by the applications, which are processed as
+// the OpenTelemetry data sent by the agent 'AgentId'
+type OtlpReceiverConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	HttpPort       int    `yaml:"http-port"`
+	GrpcPort       int    `yaml:"grpc-port"`
+	AgentId        uint16 `yaml:"agent-id"`
+	OrgId          uint16 `yaml:"org-id"`
+	TeamId         uint32 `yaml:"team-id"`
+	MaxRequestSize int    `yaml:"max-request-size"`
+}
+
+// GeoIPConfig loads MaxMind compatible databases (GeoIP2/GeoLite2/DB-IP mmdb files) to fill the
+// country, city and ASN of the client and server IPs of flow logs. the files are reloaded when modified
+type GeoIPConfig struct {
+	CountryMMDBFile string `yaml:"country-mmdb-file"`
+	CityMMDBFile    string `yaml:"city-mmdb-file"`
+	ASNMMDBFile     string `yaml:"asn-mmdb-file"`
+	Language        string `yaml:"language"`
+	ReloadInterval  int    `yaml:"reload-interval"` // second
+}
+
+func (c *GeoIPConfig) Enabled() bool {
+	return c.CountryMMDBFile != "" || c.CityMMDBFile != "" || c.ASNMMDBFile != ""
+}
+
 type Config struct {
 	Base              *config.Config
 	CKWriterConfig    config.CKWriterConfig `yaml:"flowlog-ck-writer"`
@@ -55,6 +89,8 @@ type Config struct {
 	DecoderQueueCount int                   `yaml:"flow-log-decoder-queue-count"`
 	DecoderQueueSize  int                   `yaml:"flow-log-decoder-queue-size"`
 	TraceTreeEnabled  *bool                 `yaml:"flow-log-trace-tree-enabled"`
+	OtlpReceiver      OtlpReceiverConfig    `yaml:"flow-log-otlp-receiver"`
+	GeoIP             GeoIPConfig           `yaml:"flow-log-geoip"`
 }
 
 type FlowLogConfig struct {
@@ -84,6 +120,25 @@ func (c *Config) Validate() error {
 		c.TraceTreeEnabled = &value
 	}
 
+	if c.OtlpReceiver.HttpPort == 0 {
+		c.OtlpReceiver.HttpPort = DefaultOtlpHttpPort
+	}
+	if c.OtlpReceiver.GrpcPort == 0 {
+		c.OtlpReceiver.GrpcPort = DefaultOtlpGrpcPort
+	}
+	if c.OtlpReceiver.OrgId == 0 {
+		c.OtlpReceiver.OrgId = ckdb.DEFAULT_ORG_ID
+	}
+	if c.OtlpReceiver.MaxRequestSize <= 0 {
+		c.OtlpReceiver.MaxRequestSize = DefaultOtlpMaxRequestSize
+	}
+	if c.GeoIP.Language == "" {
+		c.GeoIP.Language = DefaultGeoIPLanguage
+	}
+	if c.GeoIP.ReloadInterval <= 0 {
+		c.GeoIP.ReloadInterval = DefaultGeoIPReloadInterval
+	}
+
 	return nil
 }
 
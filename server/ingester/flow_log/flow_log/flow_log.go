@@ -33,6 +33,7 @@ import (
 	"github.com/khulnasoft/deepflow/server/ingester/flow_log/dbwriter"
 	"github.com/khulnasoft/deepflow/server/ingester/flow_log/decoder"
 	"github.com/khulnasoft/deepflow/server/ingester/flow_log/geo"
+	"github.com/khulnasoft/deepflow/server/ingester/flow_log/otlp_receiver"
 	"github.com/khulnasoft/deepflow/server/ingester/flow_log/throttler"
 	"github.com/khulnasoft/deepflow/server/ingester/flow_tag"
 	"github.com/khulnasoft/deepflow/server/ingester/ingesterctl"
@@ -55,6 +56,7 @@ type FlowLog struct {
 	OtelCompressedLogger *Logger
 	L4PacketLogger       *Logger
 	SkyWalkingLogger     *Logger
+	OtlpReceiver         *otlp_receiver.OtlpReceiver
 	Exporters            *exporters.Exporters
 	SpanWriter           *dbwriter.SpanWriter
 	TraceTreeWriter      *dbwriter.TraceTreeWriter
@@ -62,6 +64,7 @@ type FlowLog struct {
 
 type Logger struct {
 	Config        *config.Config
+	DecodeQueues  *dropletqueue.MultiQueue
 	Decoders      []*decoder.Decoder
 	PlatformDatas []*grpc.PlatformInfoTable
 	FlowLogWriter *dbwriter.FlowLogWriter
@@ -70,6 +73,14 @@ type Logger struct {
 func NewFlowLog(config *config.Config, traceTreeQueue *queue.OverwriteQueue, recv *receiver.Receiver, platformDataManager *grpc.PlatformDataManager, exporters *exporters.Exporters) (*FlowLog, error) {
 	manager := dropletqueue.NewManager(ingesterctl.INGESTERCTL_FLOW_LOG_QUEUE)
 
+	if config.GeoIP.Enabled() {
+		geoIP := &config.GeoIP
+		if err := geo.NewMMDB(geoIP.CountryMMDBFile, geoIP.CityMMDBFile, geoIP.ASNMMDBFile, geoIP.Language,
+			time.Duration(geoIP.ReloadInterval)*time.Second); err != nil {
+			return nil, err
+		}
+	}
+
 	if config.Base.StorageDisabled {
 		l7FlowLogger, err := NewL7FlowLogger(config, platformDataManager, manager, recv, nil, exporters, nil)
 		if err != nil {
@@ -123,6 +134,10 @@ func NewFlowLog(config *config.Config, traceTreeQueue *queue.OverwriteQueue, rec
 	if err != nil {
 		return nil, err
 	}
+	var otlpReceiver *otlp_receiver.OtlpReceiver
+	if config.OtlpReceiver.Enabled {
+		otlpReceiver = otlp_receiver.NewOtlpReceiver(&config.OtlpReceiver, otelLogger.DecodeQueues, config.DecoderQueueCount)
+	}
 	return &FlowLog{
 		FlowLogConfig:        config,
 		L4FlowLogger:         l4FlowLogger,
@@ -131,6 +146,7 @@ func NewFlowLog(config *config.Config, traceTreeQueue *queue.OverwriteQueue, rec
 		OtelCompressedLogger: otelCompressedLogger,
 		L4PacketLogger:       l4PacketLogger,
 		SkyWalkingLogger:     skywalkingLogger,
+		OtlpReceiver:         otlpReceiver,
 		Exporters:            exporters,
 		SpanWriter:           spanWriter,
 		TraceTreeWriter:      traceTreeWriter,
@@ -188,6 +204,7 @@ func NewLogger(msgType datatype.MessageType, config *config.Config, platformData
 	}
 	return &Logger{
 		Config:        config,
+		DecodeQueues:  decodeQueues,
 		Decoders:      decoders,
 		PlatformDatas: platformDatas,
 		FlowLogWriter: flowLogWriter,
@@ -365,6 +382,9 @@ func (s *FlowLog) Start() {
 	if s.SkyWalkingLogger != nil {
 		s.SkyWalkingLogger.Start()
 	}
+	if s.OtlpReceiver != nil {
+		s.OtlpReceiver.Start()
+	}
 	if s.SpanWriter != nil {
 		s.SpanWriter.Start()
 	}
@@ -389,6 +409,9 @@ func (s *FlowLog) Close() error {
 	if s.OtelCompressedLogger != nil {
 		s.OtelCompressedLogger.Close()
 	}
+	if s.OtlpReceiver != nil {
+		s.OtlpReceiver.Close()
+	}
 	if s.SkyWalkingLogger != nil {
 		s.SkyWalkingLogger.Close()
 	}
@@ -398,5 +421,6 @@ func (s *FlowLog) Close() error {
 	if s.TraceTreeWriter != nil {
 		s.TraceTreeWriter.Close()
 	}
+	geo.CloseMMDB()
 	return nil
 }
@@ -17,10 +17,14 @@
 package geo
 
 import (
+	"net"
+	"time"
+
 	"github.com/khulnasoft/deepflow/server/libs/geo"
 )
 
 var geoTree geo.GeoTree
+var mmdb *geo.MMDB
 
 func NewGeoTree() {
 	geoTree = geo.NewNetmaskGeoTree()
@@ -30,3 +34,34 @@ func QueryProvince(ip uint32) string {
 	region, _ := geoTree.Query(ip)
 	return geo.DecodeRegion(region)
 }
+
+// NewMMDB loads the MaxMind compatible databases and reloads them when the files are changed
+func NewMMDB(countryFile, cityFile, asnFile, language string, reloadInterval time.Duration) error {
+	m, err := geo.NewMMDB(countryFile, cityFile, asnFile, language, reloadInterval)
+	if err != nil {
+		return err
+	}
+	m.Start()
+	mmdb = m
+	return nil
+}
+
+func CloseMMDB() {
+	if mmdb != nil {
+		mmdb.Close()
+	}
+}
+
+func MMDBEnabled() bool {
+	return mmdb != nil
+}
+
+func QueryIPGeo(isIPv4 bool, ip4 uint32, ip6 net.IP) geo.IPGeoInfo {
+	var info geo.IPGeoInfo
+	if isIPv4 {
+		mmdb.QueryIPv4(ip4, &info)
+	} else {
+		mmdb.Query(ip6, &info)
+	}
+	return info
+}
@@ -220,16 +220,55 @@ func (a *ApplicationLayer) WriteBlock(block *ckdb.Block) {
 type Internet struct {
 	Province0 string `json:"province_0" category:"$tag" sub:"network_layer"`
 	Province1 string `json:"province_1" category:"$tag" sub:"network_layer"`
+
+	IPGeo
 }
 
-var InternetColumns = []*ckdb.Column{
+var InternetColumns = append([]*ckdb.Column{
 	// 广域网
 	ckdb.NewColumn("province_0", ckdb.LowCardinalityString),
 	ckdb.NewColumn("province_1", ckdb.LowCardinalityString),
-}
+}, IPGeoColumns...)
 
 func (i *Internet) WriteBlock(block *ckdb.Block) {
 	block.Write(i.Province0, i.Province1)
+	i.IPGeo.WriteBlock(block)
+}
+
+// IPGeo is looked up from the MaxMind compatible databases configured by 'flow-log-geoip'
+type IPGeo struct {
+	Country0 string `json:"country_0" category:"$tag" sub:"network_layer"`
+	Country1 string `json:"country_1" category:"$tag" sub:"network_layer"`
+	City0    string `json:"city_0" category:"$tag" sub:"network_layer"`
+	City1    string `json:"city_1" category:"$tag" sub:"network_layer"`
+	ASN0     uint32 `json:"asn_0" category:"$tag" sub:"network_layer"`
+	ASN1     uint32 `json:"asn_1" category:"$tag" sub:"network_layer"`
+	ASNOrg0  string `json:"asn_org_0" category:"$tag" sub:"network_layer"`
+	ASNOrg1  string `json:"asn_org_1" category:"$tag" sub:"network_layer"`
+}
+
+var IPGeoColumns = []*ckdb.Column{
+	ckdb.NewColumn("country_0", ckdb.LowCardinalityString).SetComment("客户端IP所属国家"),
+	ckdb.NewColumn("country_1", ckdb.LowCardinalityString).SetComment("服务端IP所属国家"),
+	ckdb.NewColumn("city_0", ckdb.LowCardinalityString).SetComment("客户端IP所属城市"),
+	ckdb.NewColumn("city_1", ckdb.LowCardinalityString).SetComment("服务端IP所属城市"),
+	ckdb.NewColumn("asn_0", ckdb.UInt32).SetComment("客户端IP所属自治系统号"),
+	ckdb.NewColumn("asn_1", ckdb.UInt32).SetComment("服务端IP所属自治系统号"),
+	ckdb.NewColumn("asn_org_0", ckdb.LowCardinalityString).SetComment("客户端IP所属自治系统组织"),
+	ckdb.NewColumn("asn_org_1", ckdb.LowCardinalityString).SetComment("服务端IP所属自治系统组织"),
+}
+
+func (g *IPGeo) WriteBlock(block *ckdb.Block) {
+	block.Write(g.Country0, g.Country1, g.City0, g.City1, g.ASN0, g.ASN1, g.ASNOrg0, g.ASNOrg1)
+}
+
+func (g *IPGeo) Fill(isIPv4 bool, ip40, ip41 uint32, ip60, ip61 net.IP) {
+	if !geo.MMDBEnabled() {
+		return
+	}
+	info0, info1 := geo.QueryIPGeo(isIPv4, ip40, ip60), geo.QueryIPGeo(isIPv4, ip41, ip61)
+	g.Country0, g.City0, g.ASN0, g.ASNOrg0 = info0.Country, info0.City, info0.ASN, info0.ASNOrg
+	g.Country1, g.City1, g.ASN1, g.ASNOrg1 = info1.Country, info1.City, info1.ASN, info1.ASNOrg
 }
 
 type KnowledgeGraph struct {
@@ -702,9 +741,10 @@ func (a *ApplicationLayer) Fill(f *pb.Flow) {
 	}
 }
 
-func (i *Internet) Fill(f *pb.Flow) {
+func (i *Internet) Fill(f *pb.Flow, isIPV6 bool) {
 	i.Province0 = geo.QueryProvince(f.FlowKey.IpSrc)
 	i.Province1 = geo.QueryProvince(f.FlowKey.IpDst)
+	i.IPGeo.Fill(!isIPV6, f.FlowKey.IpSrc, f.FlowKey.IpDst, f.FlowKey.Ip6Src, f.FlowKey.Ip6Dst)
 }
 
 func isLocalIP(isIPv6 bool, ip4 uint32, ip6 net.IP) bool {
@@ -1105,7 +1145,7 @@ func TaggedFlowToL4FlowLog(orgId, teamId uint16, f *pb.TaggedFlow, platformData
 	s.NetworkLayer.Fill(f.Flow, isIPV6)
 	s.TransportLayer.Fill(f.Flow)
 	s.ApplicationLayer.Fill(f.Flow)
-	s.Internet.Fill(f.Flow)
+	s.Internet.Fill(f.Flow, isIPV6)
 	s.KnowledgeGraph.FillL4(f.Flow, isIPV6, platformData)
 	s.FlowInfo.Fill(f.Flow)
 	s.Metrics.Fill(f.Flow)
@@ -53,6 +53,7 @@ type L7Base struct {
 	IP61     net.IP `json:"ip6_1" category:"$tag" sub:"network_layer" to_string:"IPv6String"`
 	IsIPv4   bool   `json:"is_ipv4" category:"$tag" sub:"network_layer"`
 	Protocol uint8  `json:"protocol" category:"$tag" sub:"network_layer" enumfile:"l7_ip_protocol"`
+	IPGeo
 
 	// 传输层
 	ClientPort uint16 `json:"client_port" category:"$tag" sub:"transport_layer" `
@@ -142,6 +143,7 @@ func L7BaseColumns() []*ckdb.Column {
 		ckdb.NewColumn("syscall_cap_seq_0", ckdb.UInt32).SetComment("Syscall序列号-请求"),
 		ckdb.NewColumn("syscall_cap_seq_1", ckdb.UInt32).SetComment("Syscall序列号-响应"),
 	)
+	columns = append(columns, IPGeoColumns...)
 
 	return columns
 }
@@ -189,6 +191,7 @@ func (f *L7Base) WriteBlock(block *ckdb.Block) {
 		f.SyscallCoroutine1,
 		f.SyscallCapSeq0,
 		f.SyscallCapSeq1)
+	f.IPGeo.WriteBlock(block)
 }
 
 type L7FlowLog struct {
@@ -620,6 +623,7 @@ func (b *L7Base) Fill(log *pb.AppProtoLogsData, platformData *grpc.PlatformInfoT
 	b.Protocol = uint8(log.Base.Protocol)
 
 	b.KnowledgeGraph.FillL7(l, platformData, layers.IPProtocol(b.Protocol))
+	b.IPGeo.Fill(b.IsIPv4, b.IP40, b.IP41, b.IP60, b.IP61)
 }
 
 func (k *KnowledgeGraph) FillL7(l *pb.AppProtoLogsBaseInfo, platformData *grpc.PlatformInfoTable, protocol layers.IPProtocol) {
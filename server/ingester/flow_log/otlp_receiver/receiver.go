@@ -0,0 +1,257 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp_receiver
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	logging "github.com/op/go-logging"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/khulnasoft/deepflow/server/ingester/common"
+	"github.com/khulnasoft/deepflow/server/ingester/flow_log/config"
+	"github.com/khulnasoft/deepflow/server/libs/codec"
+	"github.com/khulnasoft/deepflow/server/libs/queue"
+	"github.com/khulnasoft/deepflow/server/libs/receiver"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+)
+
+var log = logging.MustGetLogger("flow_log.otlp_receiver")
+
+const (
+	TRACES_PATH = "/v1/traces"
+
+	CONTENT_TYPE_PROTOBUF = "application/x-protobuf"
+	CONTENT_TYPE_JSON     = "application/json"
+)
+
+var errRequestTooLarge = errors.New("request body too large")
+
+type Counter struct {
+	HttpRequestCount int64 `statsd:"http-request-count"`
+	GrpcRequestCount int64 `statsd:"grpc-request-count"`
+	SpanCount        int64 `statsd:"span-count"`
+	ErrorCount       int64 `statsd:"err-count"`
+}
+
+// OtlpReceiver receives the OTLP/HTTP and OTLP/gRPC trace export requests, and puts them to the decode
+// queues of 'MESSAGE_TYPE_OPENTELEMETRY', so they are decoded and throttled as the data sent by agents
+type OtlpReceiver struct {
+	cfg        *config.OtlpReceiverConfig
+	queues     queue.MultiQueueWriter
+	queueCount int
+	index      uint64
+
+	httpServer *http.Server
+	grpcServer *grpc.Server
+	counter    *Counter
+
+	ptraceotlp.UnimplementedGRPCServer
+	utils.Closable
+}
+
+func NewOtlpReceiver(cfg *config.OtlpReceiverConfig, queues queue.MultiQueueWriter, queueCount int) *OtlpReceiver {
+	r := &OtlpReceiver{
+		cfg:        cfg,
+		queues:     queues,
+		queueCount: queueCount,
+		counter:    &Counter{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(TRACES_PATH, r.handleHttp)
+	r.httpServer = &http.Server{
+		Addr:    ":" + strconv.Itoa(cfg.HttpPort),
+		Handler: mux,
+	}
+	r.grpcServer = grpc.NewServer(grpc.MaxRecvMsgSize(cfg.MaxRequestSize))
+	ptraceotlp.RegisterGRPCServer(r.grpcServer, r)
+	common.RegisterCountableForIngester("otlp_receiver", r)
+	return r
+}
+
+func (r *OtlpReceiver) GetCounter() interface{} {
+	counter := &Counter{
+		HttpRequestCount: atomic.SwapInt64(&r.counter.HttpRequestCount, 0),
+		GrpcRequestCount: atomic.SwapInt64(&r.counter.GrpcRequestCount, 0),
+		SpanCount:        atomic.SwapInt64(&r.counter.SpanCount, 0),
+		ErrorCount:       atomic.SwapInt64(&r.counter.ErrorCount, 0),
+	}
+	return counter
+}
+
+func (r *OtlpReceiver) Start() {
+	go func() {
+		log.Infof("otlp http receiver listening on %s", r.httpServer.Addr)
+		if err := r.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Errorf("otlp http receiver failed: %s", err)
+		}
+	}()
+	go func() {
+		listener, err := net.Listen("tcp", ":"+strconv.Itoa(r.cfg.GrpcPort))
+		if err != nil {
+			log.Errorf("otlp grpc receiver listen failed: %s", err)
+			return
+		}
+		log.Infof("otlp grpc receiver listening on %s", listener.Addr())
+		if err := r.grpcServer.Serve(listener); err != nil {
+			log.Errorf("otlp grpc receiver failed: %s", err)
+		}
+	}()
+}
+
+func (r *OtlpReceiver) Close() {
+	r.Closable.Close()
+	r.httpServer.Close()
+	r.grpcServer.Stop()
+}
+
+// Export implements the OTLP/gRPC trace service
+func (r *OtlpReceiver) Export(ctx context.Context, request ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	atomic.AddInt64(&r.counter.GrpcRequestCount, 1)
+	if err := r.put(request); err != nil {
+		atomic.AddInt64(&r.counter.ErrorCount, 1)
+		return ptraceotlp.NewExportResponse(), status.Error(codes.Unavailable, err.Error())
+	}
+	return ptraceotlp.NewExportResponse(), nil
+}
+
+func (r *OtlpReceiver) handleHttp(w http.ResponseWriter, req *http.Request) {
+	atomic.AddInt64(&r.counter.HttpRequestCount, 1)
+	if req.Method != http.MethodPost {
+		r.httpError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if contentType != CONTENT_TYPE_PROTOBUF && contentType != CONTENT_TYPE_JSON {
+		r.httpError(w, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type %s", contentType))
+		return
+	}
+
+	body, err := r.readBody(req)
+	if err == errRequestTooLarge {
+		r.httpError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	} else if err != nil {
+		r.httpError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	request := ptraceotlp.NewExportRequest()
+	if contentType == CONTENT_TYPE_JSON {
+		err = request.UnmarshalJSON(body)
+	} else {
+		err = request.UnmarshalProto(body)
+	}
+	if err != nil {
+		r.httpError(w, http.StatusBadRequest, fmt.Errorf("decode request failed: %s", err))
+		return
+	}
+	if err := r.put(request); err != nil {
+		r.httpError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// the response is encoded in the same content type as the request
+	response := ptraceotlp.NewExportResponse()
+	var resp []byte
+	if contentType == CONTENT_TYPE_JSON {
+		resp, err = response.MarshalJSON()
+	} else {
+		resp, err = response.MarshalProto()
+	}
+	if err != nil {
+		r.httpError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(resp)
+}
+
+func (r *OtlpReceiver) readBody(req *http.Request) ([]byte, error) {
+	var reader io.Reader = req.Body
+	switch req.Header.Get("Content-Encoding") {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case "", "identity":
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %s", req.Header.Get("Content-Encoding"))
+	}
+	// read one more byte to find out whether the body exceeds the limit, the size after decompressing is limited too
+	body, err := io.ReadAll(io.LimitReader(reader, int64(r.cfg.MaxRequestSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > r.cfg.MaxRequestSize {
+		return nil, errRequestTooLarge
+	}
+	return body, nil
+}
+
+func (r *OtlpReceiver) httpError(w http.ResponseWriter, code int, err error) {
+	if atomic.AddInt64(&r.counter.ErrorCount, 1) == 1 {
+		log.Warningf("otlp http request failed: %s", err)
+	}
+	http.Error(w, err.Error(), code)
+}
+
+// put encodes the request as a frame body of 'MESSAGE_TYPE_OPENTELEMETRY', since the 'ExportTraceServiceRequest'
+// and the 'TracesData' are compatible in the wire format
+func (r *OtlpReceiver) put(request ptraceotlp.ExportRequest) error {
+	spanCount := request.Traces().SpanCount()
+	if spanCount == 0 {
+		return nil
+	}
+	data, err := request.MarshalProto()
+	if err != nil {
+		return err
+	}
+
+	encoder := codec.AcquireSimpleEncoder()
+	encoder.WriteBytes(data)
+	frame := encoder.Bytes()
+	recvBuffer, _ := receiver.AcquireRecvBuffer(len(frame), receiver.TCP)
+	recvBuffer.Begin, recvBuffer.End = 0, copy(recvBuffer.Buffer, frame)
+	codec.ReleaseSimpleEncoder(encoder)
+
+	recvBuffer.VtapID = r.cfg.AgentId
+	recvBuffer.OrgID = r.cfg.OrgId
+	recvBuffer.TeamID = r.cfg.TeamId
+	index := atomic.AddUint64(&r.index, 1)
+	if err := r.queues.Put(queue.HashKey(index%uint64(r.queueCount)), recvBuffer); err != nil {
+		receiver.ReleaseRecvBuffer(recvBuffer)
+		return err
+	}
+	atomic.AddInt64(&r.counter.SpanCount, int64(spanCount))
+	return nil
+}
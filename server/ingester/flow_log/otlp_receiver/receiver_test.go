@@ -0,0 +1,151 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp_receiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/khulnasoft/deepflow/server/ingester/flow_log/config"
+	"github.com/khulnasoft/deepflow/server/libs/codec"
+	"github.com/khulnasoft/deepflow/server/libs/queue"
+	"github.com/khulnasoft/deepflow/server/libs/receiver"
+)
+
+type testQueues struct {
+	items []interface{}
+}
+
+func (q *testQueues) Put(key queue.HashKey, items ...interface{}) error {
+	q.items = append(q.items, items...)
+	return nil
+}
+
+func (q *testQueues) Puts(keys []queue.HashKey, items []interface{}) error {
+	q.items = append(q.items, items...)
+	return nil
+}
+
+func (q *testQueues) Len(key queue.HashKey) int { return len(q.items) }
+
+func (q *testQueues) Close() error { return nil }
+
+func newTestReceiver(queues *testQueues) *OtlpReceiver {
+	cfg := &config.OtlpReceiverConfig{AgentId: 100, OrgId: 2, TeamId: 3, MaxRequestSize: 1 << 20}
+	return &OtlpReceiver{cfg: cfg, queues: queues, queueCount: 2, counter: &Counter{}}
+}
+
+func newTestRequest() ptraceotlp.ExportRequest {
+	traces := ptrace.NewTraces()
+	span := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("GET /api")
+	span.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	span.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	return ptraceotlp.NewExportRequestFromTraces(traces)
+}
+
+func decodeFrame(t *testing.T, item interface{}) (*receiver.RecvBuffer, *v1.TracesData) {
+	recvBuffer, ok := item.(*receiver.RecvBuffer)
+	if !ok {
+		t.Fatalf("unexpected queue item %v", item)
+	}
+	decoder := &codec.SimpleDecoder{}
+	decoder.Init(recvBuffer.Buffer[recvBuffer.Begin:recvBuffer.End])
+	tracesData := &v1.TracesData{}
+	if err := proto.Unmarshal(decoder.ReadBytes(), tracesData); err != nil || decoder.Failed() || !decoder.IsEnd() {
+		t.Fatalf("decode frame failed: %v", err)
+	}
+	return recvBuffer, tracesData
+}
+
+func TestHttpReceive(t *testing.T) {
+	request := newTestRequest()
+	protoBody, _ := request.MarshalProto()
+	jsonBody, _ := request.MarshalJSON()
+	gzipBody := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(gzipBody)
+	gzipWriter.Write(protoBody)
+	gzipWriter.Close()
+
+	testCases := []struct {
+		contentType     string
+		contentEncoding string
+		body            []byte
+	}{
+		{CONTENT_TYPE_PROTOBUF, "", protoBody},
+		{CONTENT_TYPE_JSON + "; charset=utf-8", "", jsonBody},
+		{CONTENT_TYPE_PROTOBUF, "gzip", gzipBody.Bytes()},
+	}
+	for _, tc := range testCases {
+		queues := &testQueues{}
+		r := newTestReceiver(queues)
+		req := httptest.NewRequest(http.MethodPost, TRACES_PATH, bytes.NewReader(tc.body))
+		req.Header.Set("Content-Type", tc.contentType)
+		req.Header.Set("Content-Encoding", tc.contentEncoding)
+		w := httptest.NewRecorder()
+		r.handleHttp(w, req)
+		if w.Code != http.StatusOK || len(queues.items) != 1 {
+			t.Fatalf("%s %s: expected 1 frame with status 200, actual %d frames with status %d: %s",
+				tc.contentType, tc.contentEncoding, len(queues.items), w.Code, w.Body.String())
+		}
+		recvBuffer, tracesData := decodeFrame(t, queues.items[0])
+		if recvBuffer.VtapID != 100 || recvBuffer.OrgID != 2 || recvBuffer.TeamID != 3 {
+			t.Errorf("unexpected ids agent %d org %d team %d", recvBuffer.VtapID, recvBuffer.OrgID, recvBuffer.TeamID)
+		}
+		span := tracesData.ResourceSpans[0].ScopeSpans[0].Spans[0]
+		if span.Name != "GET /api" || span.TraceId[15] != 16 {
+			t.Errorf("unexpected span %v", span)
+		}
+	}
+}
+
+func TestHttpReceiveInvalid(t *testing.T) {
+	queues := &testQueues{}
+	r := newTestReceiver(queues)
+	r.cfg.MaxRequestSize = 16
+	testCases := []struct {
+		method      string
+		contentType string
+		body        string
+		code        int
+	}{
+		{http.MethodGet, CONTENT_TYPE_PROTOBUF, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "text/plain", "", http.StatusUnsupportedMediaType},
+		{http.MethodPost, CONTENT_TYPE_JSON, "{", http.StatusBadRequest},
+		{http.MethodPost, CONTENT_TYPE_JSON, `{"resourceSpans":[],"padding":"abcdefgh"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, TRACES_PATH, bytes.NewReader([]byte(tc.body)))
+		req.Header.Set("Content-Type", tc.contentType)
+		w := httptest.NewRecorder()
+		r.handleHttp(w, req)
+		if w.Code != tc.code {
+			t.Errorf("%s %s %s: expected status %d, actual %d", tc.method, tc.contentType, tc.body, tc.code, w.Code)
+		}
+	}
+	if len(queues.items) != 0 {
+		t.Errorf("invalid requests should not be queued, actual %d frames", len(queues.items))
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/khulnasoft/deepflow/server/ingester/ckmonitor"
 	"github.com/khulnasoft/deepflow/server/ingester/datasource"
 	"github.com/khulnasoft/deepflow/server/ingester/exporters"
+	"github.com/khulnasoft/deepflow/server/ingester/pkg/ckwriter"
 	"github.com/khulnasoft/deepflow/server/libs/grpc"
 	"github.com/khulnasoft/deepflow/server/libs/logger"
 	"github.com/khulnasoft/deepflow/server/libs/pool"
@@ -98,6 +99,8 @@ func Start(configPath string, shared *servercommon.ControllerIngesterShared) []i
 	ingesterOrgHandler := NewOrgHandler(cfg)
 	closers := []io.Closer{}
 
+	ckwriter.SetSpillConfig(&cfg.CKWriterSpill)
+
 	if cfg.IngesterEnabled {
 		flowLogConfig := flowlogcfg.Load(cfg, configPath)
 		bytes, _ = yaml.Marshal(flowLogConfig)
@@ -168,10 +171,8 @@ func Start(configPath string, shared *servercommon.ControllerIngesterShared) []i
 			receiver)
 
 		exporters := exporters.NewExporters(exportersConfig)
-		if exporters != nil {
-			exporters.Start()
-			closers = append(closers, exporters)
-		}
+		exporters.Start()
+		closers = append(closers, exporters)
 
 		// 写流日志数据
 		flowLog, err := flowlog.NewFlowLog(flowLogConfig, shared.TraceTreeQueue, receiver, platformDataManager, exporters)
@@ -181,7 +182,7 @@ func Start(configPath string, shared *servercommon.ControllerIngesterShared) []i
 
 		if !cfg.StorageDisabled {
 			// 写ext_metrics数据
-			extMetrics, err := ext_metrics.NewExtMetrics(extMetricsConfig, receiver, platformDataManager)
+			extMetrics, err := ext_metrics.NewExtMetrics(extMetricsConfig, receiver, platformDataManager, exporters)
 			checkError(err)
 			extMetrics.Start()
 			closers = append(closers, extMetrics)
@@ -205,20 +206,20 @@ func Start(configPath string, shared *servercommon.ControllerIngesterShared) []i
 			closers = append(closers, pcaper)
 
 			// write profile data
-			profile, err := profile.NewProfile(profileConfig, receiver, platformDataManager)
+			profile, err := profile.NewProfile(profileConfig, receiver, platformDataManager, exporters)
 			checkError(err)
 			profile.Start()
 			closers = append(closers, profile)
 
 			// write prometheus data
-			prometheus, err := prometheus.NewPrometheusHandler(prometheusConfig, receiver, platformDataManager)
+			prometheus, err := prometheus.NewPrometheusHandler(prometheusConfig, receiver, platformDataManager, exporters)
 			checkError(err)
 			prometheus.Start()
 			closers = append(closers, prometheus)
 			ingesterOrgHandler.SetPromHandler(prometheus)
 
 			// write application log data
-			applicationLog, err := app_log.NewApplicationLogger(applicationLogConfig, receiver, platformDataManager)
+			applicationLog, err := app_log.NewApplicationLogger(applicationLogConfig, receiver, platformDataManager, exporters)
 			checkError(err)
 			applicationLog.Start()
 			closers = append(closers, applicationLog)
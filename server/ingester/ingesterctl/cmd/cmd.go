@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"time"
 	"unicode"
@@ -27,6 +28,7 @@ import (
 	"github.com/khulnasoft/deepflow/server/ingester/common"
 	"github.com/khulnasoft/deepflow/server/ingester/droplet/profiler"
 	"github.com/khulnasoft/deepflow/server/ingester/droplet/queue"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/tail"
 	"github.com/khulnasoft/deepflow/server/ingester/ingesterctl"
 	"github.com/khulnasoft/deepflow/server/ingester/prometheus/decoder"
 	"github.com/khulnasoft/deepflow/server/libs/ckdb"
@@ -41,10 +43,27 @@ func RegisterIngesterCommand(root *cobra.Command) {
 	ip, _ := root.Flags().GetString("ip")
 	orgId, _ := root.Flags().GetUint32("org-id")
 	debug.SetIpAndPort(ip, ingesterctl.DEBUG_LISTEN_PORT)
+	streamCfg := debug.StreamClientConfig{}
 	ingesterCmd := &cobra.Command{
 		Use:   "ingester",
 		Short: "Ingester debug commands",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if streamCfg.Port == 0 {
+				return nil
+			}
+			if streamCfg.Token == "" {
+				streamCfg.Token = os.Getenv("DEEPFLOW_DEBUG_TOKEN")
+			}
+			return debug.SetStreamClient(streamCfg)
+		},
 	}
+	ingesterCmd.PersistentFlags().IntVar(&streamCfg.Port, "debug-port", 0, fmt.Sprintf("send commands to the debug server (e.g. %d) by HTTP(S) instead of UDP", ingesterctl.DEBUG_STREAM_LISTEN_PORT))
+	ingesterCmd.PersistentFlags().StringVar(&streamCfg.Token, "debug-token", "", "token of the debug server, default to env DEEPFLOW_DEBUG_TOKEN")
+	ingesterCmd.PersistentFlags().BoolVar(&streamCfg.TLS, "debug-tls", false, "use HTTPS for the debug server")
+	ingesterCmd.PersistentFlags().StringVar(&streamCfg.CAFile, "debug-ca", "", "CA file to verify the debug server certificate, implies --debug-tls")
+	ingesterCmd.PersistentFlags().StringVar(&streamCfg.CertFile, "debug-cert", "", "client certificate file for mTLS, implies --debug-tls")
+	ingesterCmd.PersistentFlags().StringVar(&streamCfg.KeyFile, "debug-key", "", "client key file for mTLS")
+	ingesterCmd.PersistentFlags().BoolVar(&streamCfg.InsecureSkipVerify, "debug-insecure-skip-verify", false, "skip verifying the debug server certificate")
 
 	dropletCmd := &cobra.Command{
 		Use:   "droplet",
@@ -120,6 +139,7 @@ func RegisterIngesterCommand(root *cobra.Command) {
 	}))
 	flowLogCmd.AddCommand(debug.ClientRegisterSimple(ingesterctl.CMD_PLATFORMDATA_FLOW_LOG, debug.CmdHelper{"platformData [filter]", "show flow log platform data statistics"}, nil))
 	flowLogCmd.AddCommand(debug.ClientRegisterSimple(ingesterctl.CMD_L7_FLOW_LOG, debug.CmdHelper{"l7", "show l7 flow log counter"}, nil))
+	flowLogCmd.AddCommand(tail.RegisterCommand(ingesterctl.INGESTERCTL_TAIL))
 
 	prometheusCmd.AddCommand(debug.ClientRegisterSimple(ingesterctl.CMD_PLATFORMDATA_PROMETHEUS, debug.CmdHelper{"platformData [filter]", "show prometheus platform data statistics"}, nil))
 	prometheusCmd.AddCommand(decoder.RegisterClientPrometheusLabelCommand())
@@ -132,6 +152,8 @@ func RegisterIngesterCommand(root *cobra.Command) {
 	exportersCmd.AddCommand(debug.ClientRegisterSimple(ingesterctl.CMD_EXPORTER_PLATFORMDATA, debug.CmdHelper{"platformData", "show otlp platformData"}, nil))
 	exportersCmd.AddCommand(debug.ClientRegisterSimple(ingesterctl.CMD_KAFKA_EXPORTER, debug.CmdHelper{Cmd: "kafka", Helper: "show kafka exporter stats"}, nil))
 	exportersCmd.AddCommand(debug.ClientRegisterSimple(ingesterctl.CMD_PROMETHEUS_EXPORTER, debug.CmdHelper{Cmd: "prometheus", Helper: "show prometheus exporter stats"}, nil))
+	exportersCmd.AddCommand(debug.ClientRegisterSimple(ingesterctl.CMD_HTTP_EXPORTER, debug.CmdHelper{Cmd: "http", Helper: "show http exporter stats"}, nil))
+	exportersCmd.AddCommand(debug.ClientRegisterSimple(ingesterctl.CMD_FILE_EXPORTER, debug.CmdHelper{Cmd: "file", Helper: "show file exporter stats"}, nil))
 
 	profileCmd.AddCommand(debug.ClientRegisterSimple(ingesterctl.CMD_PLATFORMDATA_PROFILE, debug.CmdHelper{"platformData [filter]", "show profile platform data statistics"}, nil))
 
@@ -19,8 +19,9 @@ package ingesterctl
 import "github.com/khulnasoft/deepflow/server/libs/debug"
 
 const (
-	DEBUG_LISTEN_IP   = "::"
-	DEBUG_LISTEN_PORT = 39527
+	DEBUG_LISTEN_IP          = "::"
+	DEBUG_LISTEN_PORT        = 39527
+	DEBUG_STREAM_LISTEN_PORT = 39528
 )
 
 const (
@@ -38,6 +39,7 @@ const (
 	INGESTERCTL_PROMETHEUS_QUEUE
 	INGESTERCTL_PROFILE_QUEUE
 	INGESTERCTL_APPLICATION_LOG_QUEUE
+	INGESTERCTL_TAIL
 
 	INGESTERCTL_MAX
 )
@@ -59,6 +61,8 @@ const (
 	CMD_CONTINUOUS_PROFILER
 	CMD_ORG_SWITCH
 	CMD_FREE_OS_MEMORY
+	CMD_HTTP_EXPORTER
+	CMD_FILE_EXPORTER
 )
 
 const (
@@ -19,6 +19,7 @@ package ckwriter
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"sync"
@@ -118,6 +119,8 @@ type QueueContext struct {
 	batchs          []driver.Batch
 	writeCounter    uint64
 	counter         Counter
+	spill           *spill // nil if spill is disabled
+	lastReplayTime  time.Time
 }
 
 func (qc *QueueContext) EndpointsChange(addrs []string) {
@@ -318,6 +321,19 @@ func NewCKWriter(addrs []string, user, password, counterName, timeZone string, t
 	}
 
 	name := fmt.Sprintf("%s-%s-%s", table.Database, table.LocalName, counterName)
+	if spillConfig != nil && spillConfig.TableEnabled(table.Database, table.GlobalName) {
+		// the max size is shared by all queues of the table
+		maxSize := (int64(spillConfig.MaxSize) << 20) / int64(queueCount)
+		for i, qc := range queueContexts {
+			dir := filepath.Join(spillConfig.Dir, name, strconv.Itoa(i))
+			if qc.spill, err = newSpill(dir, maxSize); err != nil {
+				return nil, fmt.Errorf("init spill dir %s failed: %s", dir, err)
+			}
+			if qc.spill.Len() > 0 {
+				log.Infof("ckwriter %s queue %d has %d spilled items to replay", name, i, qc.spill.ItemCount())
+			}
+		}
+	}
 	dataQueues := queue.NewOverwriteQueues(
 		name, queue.HashKey(queueCount), queueSize,
 		queue.OptionFlushIndicator(time.Second),
@@ -373,6 +389,10 @@ type Counter struct {
 	RetryCount        int64 `statsd:"retry-count"`
 	RetryFailedCount  int64 `statsd:"retry-failed-count"`
 	OrgInvalidCount   int64 `statsd:"org-invalid-count"`
+	SpilledCount      int64 `statsd:"spilled-count"`
+	SpillFailedCount  int64 `statsd:"spill-failed-count"`
+	ReplayedCount     int64 `statsd:"replayed-count"`
+	EvictedCount      int64 `statsd:"evicted-count"`
 	utils.Closable
 }
 
@@ -462,6 +482,9 @@ func (w *CKWriter) queueProcess(queueID int) {
 						cache.lastWriteTime = now
 					}
 				}
+				if qc.spill != nil && qc.spill.Len() > 0 && now.Sub(qc.lastReplayTime) > SPILL_REPLAY_INTERVAL {
+					w.replaySpill(queueID)
+				}
 			} else {
 				log.Warningf("get writer queue data type wrong %T", item)
 			}
@@ -506,10 +529,13 @@ func (w *CKWriter) Write(queueID int, cache *Cache) {
 		err := w.InitTable(queueID, cache.orgID)
 		if err != nil {
 			if logEnabled {
-				log.Warningf("create table (%s.%s) failed, drop (%d) items: %s", w.table.OrgDatabase(cache.orgID), w.table.LocalName, itemsLen, err)
+				action := "drop"
+				if qc.spill != nil {
+					action = "spill"
+				}
+				log.Warningf("create table (%s.%s) failed, %s (%d) items: %s", w.table.OrgDatabase(cache.orgID), w.table.LocalName, action, itemsLen, err)
 			}
-			qc.counter.WriteFailedCount += int64(itemsLen)
-			cache.Release()
+			w.spillOrDrop(queueID, cache)
 			return
 		}
 		cache.tableCreated = true
@@ -533,21 +559,105 @@ func (w *CKWriter) Write(queueID int, cache *Cache) {
 		if logEnabled {
 			if err != nil {
 				qc.counter.RetryFailedCount++
-				log.Warningf("retry write table (%s.%s) failed, drop (%d) items: %s", w.table.OrgDatabase(cache.orgID), w.table.LocalName, itemsLen, err)
+				action := "drop"
+				if qc.spill != nil {
+					action = "spill"
+				}
+				log.Warningf("retry write table (%s.%s) failed, %s (%d) items: %s", w.table.OrgDatabase(cache.orgID), w.table.LocalName, action, itemsLen, err)
 			} else {
 				log.Infof("retry write table (%s.%s) success, write (%d) items", w.table.OrgDatabase(cache.orgID), w.table.LocalName, itemsLen)
 			}
 		}
 		if err != nil {
-			qc.counter.WriteFailedCount += int64(itemsLen)
-		} else {
-			qc.counter.WriteSuccessCount += int64(itemsLen)
+			w.spillOrDrop(queueID, cache)
+			return
 		}
+		qc.counter.WriteSuccessCount += int64(itemsLen)
 	} else {
 		qc.counter.WriteSuccessCount += int64(itemsLen)
 	}
 
 	cache.Release()
+	// the connection is recovered, replay the spilled items as soon as possible
+	if qc.spill != nil && qc.spill.Len() > 0 && time.Since(qc.lastReplayTime) > time.Second {
+		w.replaySpill(queueID)
+	}
+}
+
+// spillOrDrop saves the items of cache to the spill directory if enabled, otherwise drops them
+func (w *CKWriter) spillOrDrop(queueID int, cache *Cache) {
+	qc := w.queueContexts[queueID]
+	itemsLen := len(cache.items)
+	defer cache.Release()
+	if qc.spill == nil {
+		qc.counter.WriteFailedCount += int64(itemsLen)
+		return
+	}
+
+	block := ckdb.NewRowBlock()
+	for _, item := range cache.items {
+		item.WriteBlock(block)
+		block.WriteAll()
+	}
+	evicted, err := qc.spill.Put(cache.orgID, block.Rows())
+	if err != nil {
+		if qc.counter.SpillFailedCount == 0 {
+			log.Warningf("spill table (%s.%s) failed, drop (%d) items: %s", w.table.OrgDatabase(cache.orgID), w.table.LocalName, itemsLen, err)
+		}
+		qc.counter.SpillFailedCount += int64(itemsLen)
+		qc.counter.WriteFailedCount += int64(itemsLen)
+		return
+	}
+	qc.counter.SpilledCount += int64(itemsLen)
+	if evicted > 0 {
+		if qc.counter.EvictedCount == 0 {
+			log.Warningf("spill of table (%s.%s) is full, evict (%d) oldest items", w.table.OrgDatabase(cache.orgID), w.table.LocalName, evicted)
+		}
+		qc.counter.EvictedCount += int64(evicted)
+		qc.counter.WriteFailedCount += int64(evicted)
+	}
+}
+
+// replaySpill writes the spilled batches in order, stops at the first failure and retries after SPILL_REPLAY_INTERVAL
+func (w *CKWriter) replaySpill(queueID int) {
+	qc := w.queueContexts[queueID]
+	qc.lastReplayTime = time.Now()
+	for i := 0; i < SPILL_REPLAY_BATCH_COUNT && qc.spill.Len() > 0; i++ {
+		f := qc.spill.Front()
+		batch, err := qc.spill.Load(f)
+		if err != nil {
+			log.Warningf("load spill file of table (%s) failed, evict (%d) items: %s", w.name, f.itemCount, err)
+			qc.counter.EvictedCount += int64(f.itemCount)
+			qc.spill.Remove(f)
+			continue
+		}
+		if batch.OrgID > ckdb.MAX_ORG_ID {
+			qc.counter.OrgInvalidCount += int64(f.itemCount)
+			qc.spill.Remove(f)
+			continue
+		}
+		cache := qc.orgCaches[batch.OrgID]
+		if !cache.OrgIdExists() {
+			log.Warningf("table (%s.%s) orgId is not exist, drop (%d) spilled items", w.table.OrgDatabase(cache.orgID), w.table.LocalName, f.itemCount)
+			qc.counter.OrgInvalidCount += int64(f.itemCount)
+			qc.spill.Remove(f)
+			continue
+		}
+		if !cache.tableCreated {
+			if err := w.InitTable(queueID, cache.orgID); err != nil {
+				return
+			}
+			cache.tableCreated = true
+		}
+		connID := int(atomic.AddUint64(&qc.writeCounter, 1)) % qc.connCount
+		if err := w.writeRows(queueID, connID, cache.prepare, batch.Rows); err != nil {
+			log.Warningf("replay spilled items of table (%s.%s) failed, will retry later: %s", w.table.OrgDatabase(cache.orgID), w.table.LocalName, err)
+			return
+		}
+		log.Infof("replay spilled items of table (%s.%s) success, write (%d) items", w.table.OrgDatabase(cache.orgID), w.table.LocalName, f.itemCount)
+		qc.counter.ReplayedCount += int64(f.itemCount)
+		qc.spill.Remove(f)
+	}
 }
 
 func IsNil(i interface{}) bool {
@@ -561,16 +671,13 @@ func IsNil(i interface{}) bool {
 	return false
 }
 
-func (w *CKWriter) writeItems(queueID, connID int, cache *Cache) error {
+func (w *CKWriter) prepareBatch(queueID, connID int, prepare string) (driver.Batch, error) {
 	qc := w.queueContexts[queueID]
-	if len(cache.items) == 0 {
-		return nil
-	}
 	ck := qc.conns[connID]
 	if IsNil(ck) {
 		if err := w.ResetConnection(queueID, connID); err != nil {
 			time.Sleep(time.Second * 10)
-			return fmt.Errorf("write block failed, can not connect to clickhouse: %s", err)
+			return nil, fmt.Errorf("write block failed, can not connect to clickhouse: %s", err)
 		}
 		ck = qc.conns[connID]
 	}
@@ -578,18 +685,29 @@ func (w *CKWriter) writeItems(queueID, connID int, cache *Cache) error {
 	batchID := connID
 	batch := qc.batchs[batchID]
 	if IsNil(batch) {
-		qc.batchs[batchID], err = ck.PrepareBatch(context.Background(), cache.prepare)
+		qc.batchs[batchID], err = ck.PrepareBatch(context.Background(), prepare)
 		if err != nil {
-			return fmt.Errorf("prepare batch item write block failed: %s", err)
+			return nil, fmt.Errorf("prepare batch item write block failed: %s", err)
 		}
 		batch = qc.batchs[batchID]
 	} else {
-		batch, err = ck.PrepareReuseBatch(context.Background(), cache.prepare, batch)
+		batch, err = ck.PrepareReuseBatch(context.Background(), prepare, batch)
 		if err != nil {
-			return fmt.Errorf("prepare reuse batch item write block failed: %s", err)
+			return nil, fmt.Errorf("prepare reuse batch item write block failed: %s", err)
 		}
 		qc.batchs[batchID] = batch
 	}
+	return batch, nil
+}
+
+func (w *CKWriter) writeItems(queueID, connID int, cache *Cache) error {
+	if len(cache.items) == 0 {
+		return nil
+	}
+	batch, err := w.prepareBatch(queueID, connID, cache.prepare)
+	if err != nil {
+		return err
+	}
 
 	ckdbBlock := ckdb.NewBlock(batch)
 	for _, item := range cache.items {
@@ -606,6 +724,28 @@ func (w *CKWriter) writeItems(queueID, connID int, cache *Cache) error {
 	return nil
 }
 
+func (w *CKWriter) writeRows(queueID, connID int, prepare string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	batch, err := w.prepareBatch(queueID, connID, prepare)
+	if err != nil {
+		return err
+	}
+
+	ckdbBlock := ckdb.NewBlock(batch)
+	for _, row := range rows {
+		ckdbBlock.Write(row...)
+		if err := ckdbBlock.WriteAll(); err != nil {
+			return fmt.Errorf("row write block failed: %s", err)
+		}
+	}
+	if err = ckdbBlock.Send(); err != nil {
+		return fmt.Errorf("send write block failed: %s", err)
+	}
+	return nil
+}
+
 func (w *CKWriter) Close() {
 	w.exit = true
 	w.wg.Wait()
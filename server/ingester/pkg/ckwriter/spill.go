@@ -0,0 +1,299 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ckwriter
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft/deepflow/server/ingester/config"
+)
+
+const (
+	SPILL_FILE_SUFFIX     = ".spill"
+	SPILL_TMP_FILE_SUFFIX = ".tmp"
+	// replay the spilled batches at most every SPILL_REPLAY_INTERVAL when writing fails
+	SPILL_REPLAY_INTERVAL = 10 * time.Second
+	// the max count of spilled batches replayed at a time, avoid blocking the queue for too long
+	SPILL_REPLAY_BATCH_COUNT = 16
+)
+
+func init() {
+	// the types of column values which are not registered by gob
+	gob.Register(net.IP{})
+	gob.Register(time.Time{})
+	gob.Register(spillNilPointer{})
+}
+
+// spillNilPointer replaces the nil pointers of Nullable columns, which can not be encoded by gob inside interface
+type spillNilPointer struct {
+	Type string
+}
+
+// the nil pointers restored by type name, the unknown types are restored as untyped nil
+var spillNilPointers = map[string]interface{}{}
+
+func init() {
+	for _, v := range []interface{}{
+		(*int8)(nil), (*int16)(nil), (*int32)(nil), (*int64)(nil), (*int)(nil),
+		(*uint8)(nil), (*uint16)(nil), (*uint32)(nil), (*uint64)(nil), (*uint)(nil),
+		(*float32)(nil), (*float64)(nil), (*bool)(nil), (*string)(nil), (*time.Time)(nil),
+	} {
+		spillNilPointers[reflect.TypeOf(v).String()] = v
+	}
+}
+
+func isNilPointer(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// encodeNilPointers returns the rows whose nil pointers are replaced by spillNilPointer, the rows containing
+// nil pointers are copied, so the rows passed in are not modified
+func encodeNilPointers(rows [][]interface{}) [][]interface{} {
+	var encoded [][]interface{}
+	for i, row := range rows {
+		var encodedRow []interface{}
+		for j, v := range row {
+			if !isNilPointer(v) {
+				continue
+			}
+			if encodedRow == nil {
+				encodedRow = append([]interface{}{}, row...)
+			}
+			encodedRow[j] = spillNilPointer{Type: reflect.TypeOf(v).String()}
+		}
+		if encodedRow != nil {
+			if encoded == nil {
+				encoded = append([][]interface{}{}, rows...)
+			}
+			encoded[i] = encodedRow
+		}
+	}
+	if encoded == nil {
+		return rows
+	}
+	return encoded
+}
+
+// decodeNilPointers restores the nil pointers in place
+func decodeNilPointers(rows [][]interface{}) {
+	for _, row := range rows {
+		for j, v := range row {
+			if p, ok := v.(spillNilPointer); ok {
+				row[j] = spillNilPointers[p.Type]
+			}
+		}
+	}
+}
+
+var spillConfig *config.CKWriterSpill
+
+// SetSpillConfig should be called before creating the CKWriters
+func SetSpillConfig(cfg *config.CKWriterSpill) {
+	spillConfig = cfg
+}
+
+// the batch saved in a spill file
+type spillBatch struct {
+	OrgID uint16
+	Rows  [][]interface{}
+}
+
+type spillFile struct {
+	seq       uint64
+	orgID     uint16
+	itemCount int
+	size      int64
+}
+
+func (f *spillFile) name() string {
+	// the sequence keeps the order of files, the org id and item count are used without decoding the file
+	return fmt.Sprintf("%020d-%d-%d%s", f.seq, f.orgID, f.itemCount, SPILL_FILE_SUFFIX)
+}
+
+func parseSpillFileName(name string) (*spillFile, bool) {
+	if !strings.HasSuffix(name, SPILL_FILE_SUFFIX) {
+		return nil, false
+	}
+	f := &spillFile{}
+	if n, err := fmt.Sscanf(strings.TrimSuffix(name, SPILL_FILE_SUFFIX), "%d-%d-%d", &f.seq, &f.orgID, &f.itemCount); err != nil || n != 3 {
+		return nil, false
+	}
+	return f, true
+}
+
+// spill is a write-ahead directory of the batches failed to write, the batches are saved one file each
+// and replayed in the order they are saved. The oldest files are evicted when the size exceeds maxSize.
+// It is used by one queue goroutine only, no lock is needed.
+type spill struct {
+	dir     string
+	maxSize int64
+	size    int64
+	files   []*spillFile
+	nextSeq uint64
+}
+
+func newSpill(dir string, maxSize int64) (*spill, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := &spill{dir: dir, maxSize: maxSize, nextSeq: 1}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		// remove the files not finished writing
+		if strings.HasSuffix(entry.Name(), SPILL_TMP_FILE_SUFFIX) {
+			os.Remove(filepath.Join(dir, entry.Name()))
+			continue
+		}
+		f, ok := parseSpillFileName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		f.size = info.Size()
+		s.files = append(s.files, f)
+		s.size += f.size
+		if f.seq >= s.nextSeq {
+			s.nextSeq = f.seq + 1
+		}
+	}
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i].seq < s.files[j].seq })
+	return s, nil
+}
+
+func (s *spill) Len() int {
+	return len(s.files)
+}
+
+func (s *spill) ItemCount() int {
+	count := 0
+	for _, f := range s.files {
+		count += f.itemCount
+	}
+	return count
+}
+
+// Put saves the rows to a new file and returns the count of items evicted to keep the size under maxSize
+func (s *spill) Put(orgID uint16, rows [][]interface{}) (int, error) {
+	f := &spillFile{seq: s.nextSeq, orgID: orgID, itemCount: len(rows)}
+	path := filepath.Join(s.dir, f.name())
+	tmpPath := path + SPILL_TMP_FILE_SUFFIX
+	if err := writeSpillFile(tmpPath, &spillBatch{OrgID: orgID, Rows: encodeNilPointers(rows)}); err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	f.size = info.Size()
+	if f.size > s.maxSize {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("spill size %d of %d items exceeds the max size %d", f.size, f.itemCount, s.maxSize)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	s.nextSeq++
+	s.files = append(s.files, f)
+	s.size += f.size
+
+	evicted := 0
+	for s.size > s.maxSize && len(s.files) > 1 {
+		evicted += s.Front().itemCount
+		s.Remove(s.Front())
+	}
+	return evicted, nil
+}
+
+func writeSpillFile(path string, batch *spillBatch) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	if err := gob.NewEncoder(writer).Encode(batch); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (s *spill) Front() *spillFile {
+	if len(s.files) == 0 {
+		return nil
+	}
+	return s.files[0]
+}
+
+func (s *spill) Load(f *spillFile) (*spillBatch, error) {
+	file, err := os.Open(filepath.Join(s.dir, f.name()))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	batch := &spillBatch{}
+	if err := gob.NewDecoder(bufio.NewReader(file)).Decode(batch); err != nil {
+		return nil, err
+	}
+	decodeNilPointers(batch.Rows)
+	return batch, nil
+}
+
+// Remove deletes the front file
+func (s *spill) Remove(f *spillFile) {
+	if len(s.files) == 0 || s.files[0] != f {
+		return
+	}
+	if err := os.Remove(filepath.Join(s.dir, f.name())); err != nil && !os.IsNotExist(err) {
+		log.Warningf("remove spill file %s failed: %s", filepath.Join(s.dir, f.name()), err)
+	}
+	s.files[0] = nil
+	s.files = s.files[1:]
+	s.size -= f.size
+}
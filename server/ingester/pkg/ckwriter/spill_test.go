@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ckwriter
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/libs/ckdb"
+)
+
+type testItem struct {
+	time  uint32
+	ip    uint32
+	names []string
+}
+
+func (i *testItem) WriteBlock(block *ckdb.Block) {
+	block.WriteDateTime(i.time)
+	block.WriteIPv4(i.ip)
+	block.WriteIPv6(nil)
+	block.WriteBool(true)
+	block.Write(i.names, uint64(i.time), "test")
+}
+
+func (i *testItem) OrgID() uint16 { return 1 }
+
+func (i *testItem) Release() {}
+
+func testRows(start, count int) [][]interface{} {
+	block := ckdb.NewRowBlock()
+	for i := start; i < start+count; i++ {
+		item := &testItem{time: uint32(i), ip: 0x0a000001, names: []string{"a", "b"}}
+		item.WriteBlock(block)
+		block.WriteAll()
+	}
+	return block.Rows()
+}
+
+func TestSpillPutLoad(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpill(dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if evicted, err := s.Put(uint16(i), testRows(i*10, 10)); err != nil || evicted != 0 {
+			t.Fatalf("put failed, evicted %d, err %v", evicted, err)
+		}
+	}
+	if s.Len() != 3 || s.ItemCount() != 30 {
+		t.Fatalf("expected 3 files with 30 items, actual %d files with %d items", s.Len(), s.ItemCount())
+	}
+
+	// reopen the directory, files are replayed in the order they are saved
+	s, err = newSpill(dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		f := s.Front()
+		batch, err := s.Load(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if batch.OrgID != uint16(i) || len(batch.Rows) != 10 {
+			t.Fatalf("expected org %d with 10 rows, actual org %d with %d rows", i, batch.OrgID, len(batch.Rows))
+		}
+		if !reflect.DeepEqual(batch.Rows, testRows(i*10, 10)) {
+			t.Fatalf("rows changed after load: %v", batch.Rows[0])
+		}
+		if ip, ok := batch.Rows[0][1].(net.IP); !ok || !ip.Equal(net.ParseIP("10.0.0.1")) {
+			t.Fatalf("ip changed after load: %v", batch.Rows[0][1])
+		}
+		s.Remove(f)
+	}
+	if s.Len() != 0 || s.size != 0 {
+		t.Fatalf("expected empty spill, actual %d files of %d bytes", s.Len(), s.size)
+	}
+	if _, err := s.Put(0, testRows(0, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if s.Front().seq != 4 {
+		t.Fatalf("expected sequence 4 after reopen, actual %d", s.Front().seq)
+	}
+}
+
+func TestSpillEvict(t *testing.T) {
+	s, err := newSpill(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Put(0, testRows(0, 10)); err != nil {
+		t.Fatal(err)
+	}
+	// keep room for about two files
+	s.maxSize = s.size*2 + s.size/2
+
+	evictedTotal := 0
+	for i := 1; i < 5; i++ {
+		evicted, err := s.Put(0, testRows(i*10, 10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		evictedTotal += evicted
+	}
+	if s.Len() != 2 || evictedTotal != 30 {
+		t.Fatalf("expected 2 files left and 30 items evicted, actual %d files and %d items", s.Len(), evictedTotal)
+	}
+	batch, err := s.Load(s.Front())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.Rows[0][0] != uint32(30) {
+		t.Fatalf("the oldest files should be evicted, actual front row %v", batch.Rows[0])
+	}
+
+	// a batch larger than the max size is refused
+	if _, err := s.Put(0, testRows(0, 1000)); err == nil {
+		t.Fatal("put a batch larger than max size should fail")
+	}
+	if s.Len() != 2 {
+		t.Fatalf("expected 2 files left, actual %d", s.Len())
+	}
+}
+
+type testNullableItem struct {
+	requestId    *uint64
+	responseCode *int32
+}
+
+func (i *testNullableItem) WriteBlock(block *ckdb.Block) {
+	block.Write(i.requestId, i.responseCode)
+}
+
+func TestSpillNilPointers(t *testing.T) {
+	requestId, responseCode := uint64(100), int32(200)
+	block := ckdb.NewRowBlock()
+	for _, item := range []*testNullableItem{{nil, nil}, {&requestId, &responseCode}} {
+		item.WriteBlock(block)
+		block.WriteAll()
+	}
+	rows := block.Rows()
+
+	s, err := newSpill(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Put(1, rows); err != nil {
+		t.Fatal(err)
+	}
+	if rows[0][0] != (*uint64)(nil) {
+		t.Fatalf("the rows put should not be modified: %v", rows[0])
+	}
+	batch, err := s.Load(s.Front())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the nil pointers are restored with their types, so that they are written as NULL
+	if v, ok := batch.Rows[0][0].(*uint64); !ok || v != nil {
+		t.Errorf("expected nil *uint64, actual %#v", batch.Rows[0][0])
+	}
+	if v, ok := batch.Rows[0][1].(*int32); !ok || v != nil {
+		t.Errorf("expected nil *int32, actual %#v", batch.Rows[0][1])
+	}
+	if batch.Rows[1][0] != requestId || batch.Rows[1][1] != responseCode {
+		t.Errorf("expected (%d, %d), actual %v", requestId, responseCode, batch.Rows[1])
+	}
+}
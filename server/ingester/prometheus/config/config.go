@@ -17,12 +17,15 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 
 	"github.com/khulnasoft/deepflow/server/ingester/config"
+	"github.com/khulnasoft/deepflow/server/libs/ckdb"
 
 	logging "github.com/op/go-logging"
+	"github.com/prometheus/prometheus/model/relabel"
 	yaml "gopkg.in/yaml.v2"
 )
 
@@ -37,8 +40,27 @@ const (
 	DefaultAppLabelColumnIncrement      = 8
 	DefaultAppLabelColumnMinCount       = 8
 	DefaultLabelCacheExpiration         = 86400 // 1 day
+	DefaultActiveSeriesTimeout          = 3600  // 1 hour
 )
 
+// SeriesLimit limits the active series of each org and of each metric in an org, 0 means unlimited.
+// The limits are applied by each ingester instance, not cluster wide.
+type SeriesLimit struct {
+	MaxSeriesPerOrg    int `yaml:"max-series-per-org"`
+	MaxSeriesPerMetric int `yaml:"max-series-per-metric"`
+}
+
+type OrgSeriesLimit struct {
+	OrgID       int `yaml:"org-id"`
+	SeriesLimit `yaml:",inline"`
+}
+
+type SeriesLimitConfig struct {
+	SeriesLimit         `yaml:",inline"`
+	ActiveSeriesTimeout int              `yaml:"active-series-timeout"` // series not received within the timeout are no longer active, unit: s
+	OrgOverrides        []OrgSeriesLimit `yaml:"org-overrides"`
+}
+
 type Config struct {
 	Base                         *config.Config
 	CKWriterConfig               config.CKWriterConfig `yaml:"prometheus-ck-writer"`
@@ -51,6 +73,8 @@ type Config struct {
 	AppLabelColumnMinCount       int                   `yaml:"prometheus-app-label-column-min-count"`
 	IgnoreUniversalTag           bool                  `yaml:"prometheus-sample-ignore-universal-tag"`
 	LabelCacheExpiration         int                   `yaml:"prometheus-label-cache-expiration"`
+	RelabelConfigs               []*relabel.Config     `yaml:"prometheus-relabel-configs"`
+	SeriesLimit                  SeriesLimitConfig     `yaml:"prometheus-series-limit"`
 }
 
 type PrometheusConfig struct {
@@ -79,6 +103,14 @@ func (c *Config) Validate() error {
 	if c.LabelCacheExpiration <= 0 {
 		c.LabelCacheExpiration = DefaultLabelCacheExpiration
 	}
+	if c.SeriesLimit.ActiveSeriesTimeout <= 0 {
+		c.SeriesLimit.ActiveSeriesTimeout = DefaultActiveSeriesTimeout
+	}
+	for _, o := range c.SeriesLimit.OrgOverrides {
+		if o.OrgID <= ckdb.INVALID_ORG_ID || o.OrgID > ckdb.MAX_ORG_ID {
+			return fmt.Errorf("invalid org-id %d of prometheus-series-limit org-overrides", o.OrgID)
+		}
+	}
 
 	return nil
 }
@@ -96,6 +128,7 @@ func Load(base *config.Config, path string) *Config {
 			AppLabelColumnIncrement:      DefaultAppLabelColumnIncrement,
 			AppLabelColumnMinCount:       DefaultAppLabelColumnMinCount,
 			LabelCacheExpiration:         DefaultLabelCacheExpiration,
+			SeriesLimit:                  SeriesLimitConfig{ActiveSeriesTimeout: DefaultActiveSeriesTimeout},
 		},
 	}
 	if _, err := os.Stat(path); os.IsNotExist(err) {
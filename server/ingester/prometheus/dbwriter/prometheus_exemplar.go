@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dbwriter
+
+import (
+	"strings"
+
+	"github.com/khulnasoft/deepflow/server/libs/ckdb"
+	"github.com/khulnasoft/deepflow/server/libs/datatype/prompb"
+	"github.com/khulnasoft/deepflow/server/libs/pool"
+)
+
+// PrometheusExemplar stores an exemplar of a sample or histogram, the trace/span id are extracted from its labels.
+type PrometheusExemplar struct {
+	PrometheusSampleMini
+
+	TraceId     string
+	SpanId      string
+	LabelNames  []string
+	LabelValues []string
+}
+
+func (m *PrometheusExemplar) TableID() int {
+	return PROMETHEUS_EXEMPLAR_TABLE_ID
+}
+
+func (m *PrometheusExemplar) TableName() string {
+	return PROMETHEUS_EXEMPLAR_TABLE
+}
+
+// Note: The order of Write() must be consistent with the order of append() in Columns.
+func (m *PrometheusExemplar) WriteBlock(block *ckdb.Block) {
+	m.PrometheusSampleMini.writeLabels(block)
+	block.Write(
+		m.Value,
+		m.TraceId,
+		m.SpanId,
+		m.LabelNames,
+		m.LabelValues,
+	)
+}
+
+// Note: The order of append() must be consistent with the order of Write() in WriteBlock.
+func (m *PrometheusExemplar) Columns(appLabelColumnCount int) []*ckdb.Column {
+	columns := m.PrometheusSampleMini.labelColumns(appLabelColumnCount)
+	columns = append(columns,
+		ckdb.NewColumn("value", ckdb.Float64),
+		ckdb.NewColumn("trace_id", ckdb.String).SetIndex(ckdb.IndexBloomfilter),
+		ckdb.NewColumn("span_id", ckdb.String),
+		ckdb.NewColumn("label_names", ckdb.ArrayLowCardinalityString).SetComment("the exemplar labels except trace_id and span_id"),
+		ckdb.NewColumn("label_values", ckdb.ArrayString),
+	)
+	return columns
+}
+
+func (m *PrometheusExemplar) GenCKTable(cluster, storagePolicy, ckdbType string, ttl int, coldStorage *ckdb.ColdStorage, appLabelColumnCount int) *ckdb.Table {
+	table := m.PrometheusSampleMini.GenCKTable(cluster, storagePolicy, ckdbType, ttl, coldStorage, appLabelColumnCount)
+	table.LocalName = m.TableName() + ckdb.LOCAL_SUBFFIX
+	table.GlobalName = m.TableName()
+	table.Columns = m.Columns(appLabelColumnCount)
+	return table
+}
+
+// Fill copies the exemplar labels, they are from temporary memory, so need to be cloned
+func (m *PrometheusExemplar) Fill(e *prompb.Exemplar) {
+	m.Value = e.Value
+	for _, l := range e.Labels {
+		switch l.Name {
+		case "trace_id", "traceID", "traceId", "trace-id":
+			m.TraceId = strings.Clone(l.Value)
+		case "span_id", "spanID", "spanId", "span-id":
+			m.SpanId = strings.Clone(l.Value)
+		default:
+			m.LabelNames = append(m.LabelNames, strings.Clone(l.Name))
+			m.LabelValues = append(m.LabelValues, strings.Clone(l.Value))
+		}
+	}
+}
+
+func (m *PrometheusExemplar) Release() {
+	ReleasePrometheusExemplar(m)
+}
+
+var prometheusExemplarPool = pool.NewLockFreePool(func() interface{} {
+	return &PrometheusExemplar{}
+})
+
+func AcquirePrometheusExemplar() *PrometheusExemplar {
+	return prometheusExemplarPool.Get().(*PrometheusExemplar)
+}
+
+func ReleasePrometheusExemplar(p *PrometheusExemplar) {
+	p.AppLabelValueIDs = p.AppLabelValueIDs[:0]
+	p.TraceId, p.SpanId = "", ""
+	p.LabelNames = p.LabelNames[:0]
+	p.LabelValues = p.LabelValues[:0]
+	prometheusExemplarPool.Put(p)
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dbwriter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/prometheus/common/model"
+	exportprompb "github.com/prometheus/prometheus/prompb"
+
+	exportercommon "github.com/khulnasoft/deepflow/server/ingester/exporters/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters/config"
+	utag "github.com/khulnasoft/deepflow/server/ingester/exporters/universal_tag"
+	"github.com/khulnasoft/deepflow/server/libs/datatype/prompb"
+	flow_metrics "github.com/khulnasoft/deepflow/server/libs/flow-metrics"
+	"github.com/khulnasoft/deepflow/server/libs/pool"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+)
+
+// PrometheusExportItem is a time series which is exported by the exporters, it is not stored in clickhouse
+type PrometheusExportItem struct {
+	pool.ReferenceCount
+
+	Time        uint32    `json:"time" category:"$tag" sub:"flow_info"` // s, the time of the first sample
+	MetricName  string    `json:"metric_name" category:"$tag" sub:"native_tag"`
+	LabelNames  []string  `json:"label_names" category:"$tag" sub:"native_tag" data_type:"[]string"`
+	LabelValues []string  `json:"label_values" category:"$tag" sub:"native_tag" data_type:"[]string"`
+	Timestamps  []int64   `json:"timestamps" category:"$tag" sub:"flow_info" data_type:"[]int64"` // ms
+	Values      []float64 `json:"values" category:"$metrics" data_type:"[]float64"`
+
+	OrgId  uint16 `json:"org_id" category:"$tag"`
+	TeamID uint16 `json:"team_id" category:"$tag"`
+
+	UniversalTag flow_metrics.UniversalTag
+}
+
+var prometheusExportItemPool = pool.NewLockFreePool(func() interface{} {
+	return &PrometheusExportItem{}
+})
+
+// AcquirePrometheusExportItem clones the labels and the valid samples of the time series, since the time series
+// is decoded into the temporary memory
+func AcquirePrometheusExportItem(ts *prompb.TimeSeries, extraLabels []prompb.Label, orgId, teamId uint16, universalTag *flow_metrics.UniversalTag) *PrometheusExportItem {
+	e := prometheusExportItemPool.Get().(*PrometheusExportItem)
+	e.Reset()
+	e.OrgId, e.TeamID = orgId, teamId
+	e.UniversalTag = *universalTag
+
+	tsLen := len(ts.Labels)
+	var l *prompb.Label
+	for i := 0; i < tsLen+len(extraLabels); i++ {
+		if i < tsLen {
+			l = &ts.Labels[i]
+		} else {
+			l = &extraLabels[i-tsLen]
+		}
+		if e.MetricName == "" && l.Name == model.MetricNameLabel {
+			e.MetricName = strings.Clone(l.Value)
+			continue
+		}
+		e.LabelNames = append(e.LabelNames, strings.Clone(l.Name))
+		e.LabelValues = append(e.LabelValues, strings.Clone(l.Value))
+	}
+
+	for _, s := range ts.Samples {
+		if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+			continue
+		}
+		e.Timestamps = append(e.Timestamps, s.Timestamp)
+		e.Values = append(e.Values, s.Value)
+	}
+	if len(e.Timestamps) > 0 {
+		e.Time = uint32(model.Time(e.Timestamps[0]).Unix())
+	}
+	return e
+}
+
+func ReleasePrometheusExportItem(e *PrometheusExportItem) {
+	if e == nil || e.SubReferenceCount() {
+		return
+	}
+	labelNames, labelValues := e.LabelNames[:0], e.LabelValues[:0]
+	timestamps, values := e.Timestamps[:0], e.Values[:0]
+	*e = PrometheusExportItem{}
+	e.LabelNames, e.LabelValues = labelNames, labelValues
+	e.Timestamps, e.Values = timestamps, values
+	prometheusExportItemPool.Put(e)
+}
+
+func (e *PrometheusExportItem) Release() {
+	ReleasePrometheusExportItem(e)
+}
+
+func (e *PrometheusExportItem) DataSource() uint32 {
+	return uint32(config.PROMETHEUS)
+}
+
+func (e *PrometheusExportItem) EncodeTo(protocol config.ExportProtocol, utags *utag.UniversalTagsManager, cfg *config.ExporterCfg) (interface{}, error) {
+	tags := e.QueryUniversalTags(utags)
+	switch protocol {
+	case config.PROTOCOL_PROMETHEUS:
+		return e.EncodeToPrometheus(cfg, tags), nil
+	case config.PROTOCOL_KAFKA, config.PROTOCOL_HTTP, config.PROTOCOL_FILE:
+		k8sLabels := utags.QueryCustomK8sLabels(e.OrgId, e.UniversalTag.PodID)
+		return exportercommon.EncodeToJson(e, int(e.DataSource()), cfg, tags, tags, k8sLabels, k8sLabels), nil
+	default:
+		return nil, fmt.Errorf("prometheus sample unsupport export to %s", protocol)
+	}
+}
+
+// EncodeToPrometheus encodes the original labels and the exported tags to a time series, the exported tags
+// which have the same names as the original labels are ignored
+func (e *PrometheusExportItem) EncodeToPrometheus(cfg *config.ExporterCfg, tags *utag.UniversalTags) []exportprompb.TimeSeries {
+	ts := exportprompb.TimeSeries{}
+	ts.Labels = make([]exportprompb.Label, 0, len(e.LabelNames)+16)
+	ts.Labels = append(ts.Labels, exportprompb.Label{
+		Name:  model.MetricNameLabel,
+		Value: e.MetricName,
+	})
+	for i := range e.LabelNames {
+		ts.Labels = append(ts.Labels, exportprompb.Label{
+			Name:  e.LabelNames[i],
+			Value: e.LabelValues[i],
+		})
+	}
+	originLen := len(ts.Labels)
+	ts.Labels = exportercommon.AppendPrometheusLabels(ts.Labels, e, int(e.DataSource()), cfg, tags, tags)
+	labels := ts.Labels[:originLen]
+	for _, l := range ts.Labels[originLen:] {
+		if l.Name == "metric_name" || hasPrometheusLabel(ts.Labels[:originLen], l.Name) {
+			continue
+		}
+		labels = append(labels, l)
+	}
+	ts.Labels = labels
+	sort.Slice(ts.Labels, func(i, j int) bool {
+		return ts.Labels[i].Name < ts.Labels[j].Name
+	})
+
+	ts.Samples = make([]exportprompb.Sample, len(e.Timestamps))
+	for i := range e.Timestamps {
+		ts.Samples[i].Timestamp = e.Timestamps[i]
+		ts.Samples[i].Value = e.Values[i]
+	}
+	return []exportprompb.TimeSeries{ts}
+}
+
+func hasPrometheusLabel(labels []exportprompb.Label, name string) bool {
+	for i := range labels {
+		if labels[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *PrometheusExportItem) QueryUniversalTags(utags *utag.UniversalTagsManager) *utag.UniversalTags {
+	t := &e.UniversalTag
+	return utags.QueryUniversalTags(e.OrgId,
+		t.RegionID, t.AZID, t.HostID, t.PodNSID, t.PodClusterID, t.SubnetID, t.VTAPID,
+		uint8(t.L3DeviceType), t.AutoServiceType, t.AutoInstanceType,
+		t.L3DeviceID, t.AutoServiceID, t.AutoInstanceID, t.PodNodeID, t.PodGroupID, t.PodID, uint32(t.L3EpcID), t.GPID, t.ServiceID,
+		t.IsIPv6 == 0, t.IP, t.IP6,
+	)
+}
+
+func (e *PrometheusExportItem) GetFieldValueByOffsetAndKind(offset uintptr, kind reflect.Kind, dataType utils.DataType) interface{} {
+	return utils.GetValueByOffsetAndKind(uintptr(unsafe.Pointer(e)), offset, kind, dataType)
+}
+
+func (e *PrometheusExportItem) TimestampUs() int64 {
+	if len(e.Timestamps) == 0 {
+		return int64(e.Time) * 1000000
+	}
+	return e.Timestamps[0] * 1000
+}
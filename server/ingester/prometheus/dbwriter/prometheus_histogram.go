@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dbwriter
+
+import (
+	"github.com/khulnasoft/deepflow/server/libs/ckdb"
+	"github.com/khulnasoft/deepflow/server/libs/datatype/prompb"
+	"github.com/khulnasoft/deepflow/server/libs/pool"
+)
+
+// PrometheusHistogram stores a native (sparse) histogram, the bucket counts are decoded to absolute values.
+type PrometheusHistogram struct {
+	PrometheusSampleMini
+
+	Count                 float64
+	Sum                   float64
+	Schema                int8
+	ZeroThreshold         float64
+	ZeroCount             float64
+	PositiveBucketIndexes []int64
+	PositiveBucketCounts  []float64
+	NegativeBucketIndexes []int64
+	NegativeBucketCounts  []float64
+	ResetHint             uint8
+}
+
+func (m *PrometheusHistogram) TableID() int {
+	return PROMETHEUS_HISTOGRAM_TABLE_ID
+}
+
+func (m *PrometheusHistogram) TableName() string {
+	return PROMETHEUS_HISTOGRAM_TABLE
+}
+
+// Note: The order of Write() must be consistent with the order of append() in Columns.
+func (m *PrometheusHistogram) WriteBlock(block *ckdb.Block) {
+	m.PrometheusSampleMini.writeLabels(block)
+	block.Write(
+		m.Count,
+		m.Sum,
+		m.Schema,
+		m.ZeroThreshold,
+		m.ZeroCount,
+		m.PositiveBucketIndexes,
+		m.PositiveBucketCounts,
+		m.NegativeBucketIndexes,
+		m.NegativeBucketCounts,
+		m.ResetHint,
+	)
+}
+
+// Note: The order of append() must be consistent with the order of Write() in WriteBlock.
+func (m *PrometheusHistogram) Columns(appLabelColumnCount int) []*ckdb.Column {
+	columns := m.PrometheusSampleMini.labelColumns(appLabelColumnCount)
+	columns = append(columns,
+		ckdb.NewColumn("count", ckdb.Float64).SetComment("the total number of observations"),
+		ckdb.NewColumn("sum", ckdb.Float64).SetComment("the sum of observations"),
+		ckdb.NewColumn("schema", ckdb.Int8).SetComment("the bucket schema, each power of two is divided into 2^schema buckets"),
+		ckdb.NewColumn("zero_threshold", ckdb.Float64),
+		ckdb.NewColumn("zero_count", ckdb.Float64).SetComment("the number of observations in the zero bucket"),
+		ckdb.NewColumn("positive_bucket_indexes", ckdb.ArrayInt64),
+		ckdb.NewColumn("positive_bucket_counts", ckdb.ArrayFloat64).SetComment("the absolute count of each positive bucket"),
+		ckdb.NewColumn("negative_bucket_indexes", ckdb.ArrayInt64),
+		ckdb.NewColumn("negative_bucket_counts", ckdb.ArrayFloat64).SetComment("the absolute count of each negative bucket"),
+		ckdb.NewColumn("reset_hint", ckdb.UInt8).SetComment("0: unknown, 1: yes, 2: no, 3: gauge"),
+	)
+	return columns
+}
+
+func (m *PrometheusHistogram) GenCKTable(cluster, storagePolicy, ckdbType string, ttl int, coldStorage *ckdb.ColdStorage, appLabelColumnCount int) *ckdb.Table {
+	table := m.PrometheusSampleMini.GenCKTable(cluster, storagePolicy, ckdbType, ttl, coldStorage, appLabelColumnCount)
+	table.LocalName = m.TableName() + ckdb.LOCAL_SUBFFIX
+	table.GlobalName = m.TableName()
+	table.Columns = m.Columns(appLabelColumnCount)
+	return table
+}
+
+// Fill decodes the spans and deltas (or float counts) of h to absolute bucket indexes and counts.
+func (m *PrometheusHistogram) Fill(h *prompb.Histogram) {
+	if _, ok := h.GetCount().(*prompb.Histogram_CountFloat); ok {
+		m.Count = h.GetCountFloat()
+	} else {
+		m.Count = float64(h.GetCountInt())
+	}
+	if _, ok := h.GetZeroCount().(*prompb.Histogram_ZeroCountFloat); ok {
+		m.ZeroCount = h.GetZeroCountFloat()
+	} else {
+		m.ZeroCount = float64(h.GetZeroCountInt())
+	}
+	m.Sum = h.Sum
+	m.Schema = int8(h.Schema)
+	m.ZeroThreshold = h.ZeroThreshold
+	m.ResetHint = uint8(h.ResetHint)
+	m.PositiveBucketIndexes, m.PositiveBucketCounts = decodeBuckets(m.PositiveBucketIndexes, m.PositiveBucketCounts, h.PositiveSpans, h.PositiveDeltas, h.PositiveCounts)
+	m.NegativeBucketIndexes, m.NegativeBucketCounts = decodeBuckets(m.NegativeBucketIndexes, m.NegativeBucketCounts, h.NegativeSpans, h.NegativeDeltas, h.NegativeCounts)
+}
+
+// integer histograms carry deltas between consecutive buckets, float histograms carry absolute counts
+func decodeBuckets(indexes []int64, counts []float64, spans []prompb.BucketSpan, deltas []int64, floatCounts []float64) ([]int64, []float64) {
+	var index, count int64
+	n := 0
+	for _, span := range spans {
+		index += int64(span.Offset)
+		for i := uint32(0); i < span.Length; i++ {
+			if len(floatCounts) > 0 {
+				if n >= len(floatCounts) {
+					return indexes, counts
+				}
+				counts = append(counts, floatCounts[n])
+			} else {
+				if n >= len(deltas) {
+					return indexes, counts
+				}
+				count += deltas[n]
+				counts = append(counts, float64(count))
+			}
+			indexes = append(indexes, index)
+			index++
+			n++
+		}
+	}
+	return indexes, counts
+}
+
+func (m *PrometheusHistogram) Release() {
+	ReleasePrometheusHistogram(m)
+}
+
+var prometheusHistogramPool = pool.NewLockFreePool(func() interface{} {
+	return &PrometheusHistogram{}
+})
+
+func AcquirePrometheusHistogram() *PrometheusHistogram {
+	return prometheusHistogramPool.Get().(*PrometheusHistogram)
+}
+
+func ReleasePrometheusHistogram(p *PrometheusHistogram) {
+	p.AppLabelValueIDs = p.AppLabelValueIDs[:0]
+	p.PositiveBucketIndexes = p.PositiveBucketIndexes[:0]
+	p.PositiveBucketCounts = p.PositiveBucketCounts[:0]
+	p.NegativeBucketIndexes = p.NegativeBucketIndexes[:0]
+	p.NegativeBucketCounts = p.NegativeBucketCounts[:0]
+	prometheusHistogramPool.Put(p)
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dbwriter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/libs/datatype/prompb"
+)
+
+func TestPrometheusHistogramFill(t *testing.T) {
+	h := &prompb.Histogram{
+		Count:          &prompb.Histogram_CountInt{CountInt: 10},
+		Sum:            12.5,
+		Schema:         1,
+		ZeroThreshold:  0.001,
+		ZeroCount:      &prompb.Histogram_ZeroCountInt{ZeroCountInt: 1},
+		PositiveSpans:  []prompb.BucketSpan{{Offset: -1, Length: 2}, {Offset: 2, Length: 1}},
+		PositiveDeltas: []int64{2, 1, -2},
+		NegativeSpans:  []prompb.BucketSpan{{Offset: 0, Length: 1}},
+		NegativeCounts: []float64{3},
+	}
+	m := AcquirePrometheusHistogram()
+	m.Fill(h)
+	if m.Count != 10 || m.Sum != 12.5 || m.Schema != 1 || m.ZeroCount != 1 {
+		t.Errorf("unexpected histogram %+v", m)
+	}
+	if expected := []int64{-1, 0, 3}; !reflect.DeepEqual(m.PositiveBucketIndexes, expected) {
+		t.Errorf("positive indexes expected %v, actual %v", expected, m.PositiveBucketIndexes)
+	}
+	if expected := []float64{2, 3, 1}; !reflect.DeepEqual(m.PositiveBucketCounts, expected) {
+		t.Errorf("positive counts expected %v, actual %v", expected, m.PositiveBucketCounts)
+	}
+	if !reflect.DeepEqual(m.NegativeBucketIndexes, []int64{0}) || !reflect.DeepEqual(m.NegativeBucketCounts, []float64{3}) {
+		t.Errorf("unexpected negative buckets %v %v", m.NegativeBucketIndexes, m.NegativeBucketCounts)
+	}
+	m.Release()
+}
+
+func TestPrometheusExemplarFill(t *testing.T) {
+	e := &prompb.Exemplar{
+		Labels: []prompb.Label{{Name: "traceID", Value: "abc"}, {Name: "span_id", Value: "def"}, {Name: "user", Value: "u1"}},
+		Value:  0.5,
+	}
+	m := AcquirePrometheusExemplar()
+	m.Fill(e)
+	if m.TraceId != "abc" || m.SpanId != "def" || m.Value != 0.5 {
+		t.Errorf("unexpected exemplar %+v", m)
+	}
+	if !reflect.DeepEqual(m.LabelNames, []string{"user"}) || !reflect.DeepEqual(m.LabelValues, []string{"u1"}) {
+		t.Errorf("unexpected exemplar labels %v %v", m.LabelNames, m.LabelValues)
+	}
+	m.Release()
+}
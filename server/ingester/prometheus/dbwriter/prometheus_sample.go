@@ -36,6 +36,7 @@ const (
 
 type PrometheusSampleInterface interface {
 	DatabaseName() string
+	TableID() int
 	TableName() string
 	WriteBlock(*ckdb.Block)
 	OrgID() uint16
@@ -72,6 +73,10 @@ func (m *PrometheusSampleMini) DatabaseName() string {
 	return PROMETHEUS_DB
 }
 
+func (m *PrometheusSampleMini) TableID() int {
+	return PROMETHEUS_SAMPLE_TABLE_ID
+}
+
 func (m *PrometheusSampleMini) TableName() string {
 	return PROMETHEUS_TABLE
 }
@@ -90,6 +95,12 @@ func (m *PrometheusSampleMini) PodNsId() uint16 {
 
 // Note: The order of Write() must be consistent with the order of append() in Columns.
 func (m *PrometheusSampleMini) WriteBlock(block *ckdb.Block) {
+	m.writeLabels(block)
+	block.Write(m.Value)
+}
+
+// writeLabels writes the columns shared by samples, histograms and exemplars
+func (m *PrometheusSampleMini) writeLabels(block *ckdb.Block) {
 	block.WriteDateTime(m.Timestamp)
 	block.Write(
 		m.MetricID,
@@ -99,7 +110,6 @@ func (m *PrometheusSampleMini) WriteBlock(block *ckdb.Block) {
 	for _, v := range m.AppLabelValueIDs[1:] {
 		block.Write(v)
 	}
-	block.Write(m.Value)
 }
 
 func (m *PrometheusSampleMini) OrgID() uint16 {
@@ -108,6 +118,13 @@ func (m *PrometheusSampleMini) OrgID() uint16 {
 
 // Note: The order of append() must be consistent with the order of Write() in WriteBlock.
 func (m *PrometheusSampleMini) Columns(appLabelColumnCount int) []*ckdb.Column {
+	columns := m.labelColumns(appLabelColumnCount)
+	columns = append(columns, ckdb.NewColumn("value", ckdb.Float64))
+	return columns
+}
+
+// labelColumns returns the columns shared by samples, histograms and exemplars
+func (m *PrometheusSampleMini) labelColumns(appLabelColumnCount int) []*ckdb.Column {
 	columns := []*ckdb.Column{}
 
 	columns = append(columns, ckdb.NewColumnWithGroupBy("time", ckdb.DateTime))
@@ -119,8 +136,6 @@ func (m *PrometheusSampleMini) Columns(appLabelColumnCount int) []*ckdb.Column {
 	for i := 1; i <= appLabelColumnCount; i++ {
 		columns = append(columns, ckdb.NewColumn(fmt.Sprintf("app_label_value_id_%d", i), ckdb.UInt32))
 	}
-	columns = append(columns, ckdb.NewColumn("value", ckdb.Float64))
-
 	return columns
 }
 
@@ -237,7 +252,7 @@ func (m *PrometheusSample) DatabaseName() string {
 }
 
 func (m *PrometheusSample) TableName() string {
-	return m.PrometheusSampleMini.DatabaseName()
+	return m.PrometheusSampleMini.TableName()
 }
 
 // Note: The order of Write() must be consistent with the order of append() in Columns.
@@ -22,12 +22,16 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/OneOfOne/xxhash"
 	"github.com/golang/snappy"
 	logging "github.com/op/go-logging"
 	"github.com/prometheus/common/model"
 
 	"github.com/khulnasoft/deepflow/server/ingester/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters"
+	exportersconfig "github.com/khulnasoft/deepflow/server/ingester/exporters/config"
 	"github.com/khulnasoft/deepflow/server/ingester/prometheus/config"
 	"github.com/khulnasoft/deepflow/server/ingester/prometheus/dbwriter"
 	"github.com/khulnasoft/deepflow/server/libs/codec"
@@ -59,6 +63,10 @@ type Counter struct {
 	TimeSeriesErr  int64 `statsd:"time-series-err"`
 	TimeSeriesSlow int64 `statsd:"time-series-slow"`
 	TimeSeriesOut  int64 `statsd:"time-series-out"` // count the number of TimeSeries (not Samples)
+
+	TimeSeriesRelabelDrop     int64 `statsd:"time-series-relabel-drop"`
+	TimeSeriesOrgLimitDrop    int64 `statsd:"time-series-org-limit-drop"`
+	TimeSeriesMetricLimitDrop int64 `statsd:"time-series-metric-limit-drop"`
 }
 
 type BuilderCounter struct {
@@ -74,6 +82,8 @@ type BuilderCounter struct {
 	TargetMiss        int64 `statsd:"target-miss"`
 	MetricTargetMiss  int64 `statsd:"metric-target-miss"`
 	Sample            int64 `statsd:"sample-out"`
+	Histogram         int64 `statsd:"histogram-out"`
+	Exemplar          int64 `statsd:"exemplar-out"`
 }
 
 type PrometheusSamplesBuilder struct {
@@ -87,6 +97,8 @@ type PrometheusSamplesBuilder struct {
 	// temporary buffers
 	metricName              string
 	samplesBuffer           []interface{} // store all Samples in a TimeSeries.
+	histogramsBuffer        []interface{} // store all native Histograms in a TimeSeries.
+	exemplarsBuffer         []interface{} // store all Exemplars in a TimeSeries.
 	timeSeriesBuffer        *prompb.TimeSeries
 	tsLabelNameIDsBuffer    []uint32 // store timeSeries labelNameIDs without metricName
 	tsLabelValueIDsBuffer   []uint32 // store timeSeries labelValueIDs without metricID
@@ -135,12 +147,17 @@ type Decoder struct {
 	inQueue          queue.QueueReader
 	slowDecodeQueue  queue.QueueWriter
 	prometheusWriter *dbwriter.PrometheusWriter
+	exporters        *exporters.Exporters
+	exporterIndex    int
 	debugEnabled     bool
 	config           *config.Config
 
 	orgId, teamId uint16
 
 	samplesBuilder *PrometheusSamplesBuilder
+	relabeler      *Relabeler
+	seriesLimiter  *SeriesLimiter
+	seriesHasher   *xxhash.XXHash64
 
 	counter *Counter
 	utils.Closable
@@ -153,11 +170,19 @@ func NewDecoder(
 	inQueue queue.QueueReader,
 	slowDecodeQueue queue.QueueWriter,
 	prometheusWriter *dbwriter.PrometheusWriter,
+	exporters *exporters.Exporters,
+	exporterIndex int,
+	seriesLimiter *SeriesLimiter,
 	config *config.Config,
 ) *Decoder {
 	return &Decoder{
 		index:            index,
+		exporters:        exporters,
+		exporterIndex:    exporterIndex,
 		samplesBuilder:   NewPrometheusSamplesBuilder("prometheus-builder", index, platformData, prometheusLabelTable, config.AppLabelColumnIncrement, config.IgnoreUniversalTag),
+		relabeler:        NewRelabeler(config.RelabelConfigs),
+		seriesLimiter:    seriesLimiter,
+		seriesHasher:     xxhash.New64(),
 		inQueue:          inQueue,
 		slowDecodeQueue:  slowDecodeQueue,
 		debugEnabled:     log.IsEnabledFor(logging.DEBUG),
@@ -187,6 +212,7 @@ func (d *Decoder) Run() {
 		n := d.inQueue.Gets(buffer)
 		for i := 0; i < n; i++ {
 			if buffer[i] == nil {
+				flushExporters(d.exporters, d.exporterIndex)
 				continue
 			}
 			d.counter.InCount++
@@ -276,6 +302,32 @@ func (d *Decoder) sendPrometheus(vtapID uint16, ts *prompb.TimeSeries, extraLabe
 		log.Debugf("decoder %d vtap %d recv promtheus timeseries: %v", d.index, vtapID, ts)
 	}
 
+	if d.relabeler.Enabled() {
+		if !d.relabeler.Relabel(ts, extraLabels) {
+			d.counter.TimeSeriesRelabelDrop++
+			return
+		}
+		// the extra labels are relabeled and written into ts
+		extraLabels = nil
+	}
+	if d.seriesLimiter != nil {
+		metricName, hash := seriesHash(d.seriesHasher, ts, extraLabels)
+		switch d.seriesLimiter.Check(d.orgId, metricName, hash, uint32(time.Now().Unix())) {
+		case SERIES_ORG_LIMITED:
+			if d.counter.TimeSeriesOrgLimitDrop == 0 {
+				log.Warningf("org %d exceeds the active series limit, drop new series of metric %s", d.orgId, metricName)
+			}
+			d.counter.TimeSeriesOrgLimitDrop++
+			return
+		case SERIES_METRIC_LIMITED:
+			if d.counter.TimeSeriesMetricLimitDrop == 0 {
+				log.Warningf("metric %s of org %d exceeds the active series limit, drop its new series", metricName, d.orgId)
+			}
+			d.counter.TimeSeriesMetricLimitDrop++
+			return
+		}
+	}
+
 	epcId, podClusterId, err := d.samplesBuilder.GetEpcPodClusterId(d.orgId, vtapID)
 	if err != nil {
 		if d.counter.TimeSeriesErr == 0 {
@@ -300,11 +352,42 @@ func (d *Decoder) sendPrometheus(vtapID uint16, ts *prompb.TimeSeries, extraLabe
 		d.slowDecodeQueue.Put(AcquireSlowItem(vtapID, epcId, podClusterId, orgId, teamId, ts, extraLabels))
 		return
 	}
-	d.prometheusWriter.WriteBatch(builder.samplesBuffer, builder.metricName, builder.timeSeriesBuffer, extraLabels, builder.tsLabelNameIDsBuffer, builder.tsLabelValueIDsBuffer)
-	d.counter.OutCount += int64(len(builder.samplesBuffer))
+	exportTimeSeries(d.exporters, d.exporterIndex, builder, vtapID, d.orgId, d.teamId, ts, extraLabels)
+	d.counter.OutCount += int64(builder.writeBatches(d.prometheusWriter, extraLabels))
 	d.counter.TimeSeriesOut++
 }
 
+// writeBatches writes the samples, histograms and exemplars built from a TimeSeries, returns the number of items written
+func (b *PrometheusSamplesBuilder) writeBatches(writer *dbwriter.PrometheusWriter, extraLabels []prompb.Label) int {
+	for _, batch := range [][]interface{}{b.samplesBuffer, b.histogramsBuffer, b.exemplarsBuffer} {
+		writer.WriteBatch(batch, b.metricName, b.timeSeriesBuffer, extraLabels, b.tsLabelNameIDsBuffer, b.tsLabelValueIDsBuffer)
+	}
+	return len(b.samplesBuffer) + len(b.histogramsBuffer) + len(b.exemplarsBuffer)
+}
+
+func flushExporters(es *exporters.Exporters, index int) {
+	if es == nil {
+		return
+	}
+	es.Put(uint32(exportersconfig.PROMETHEUS), index, nil)
+}
+
+// exportTimeSeries exports the time series whose samples have been built by the builder, it must be called
+// before the samples are written, since the samples are released after written
+func exportTimeSeries(es *exporters.Exporters, index int, builder *PrometheusSamplesBuilder, vtapID, orgId, teamId uint16, ts *prompb.TimeSeries, extraLabels []prompb.Label) {
+	dataSourceId := uint32(exportersconfig.PROMETHEUS)
+	if es == nil || len(builder.samplesBuffer) == 0 || !es.IsExportDataSource(dataSourceId) {
+		return
+	}
+	universalTag := flow_metrics.UniversalTag{VTAPID: vtapID}
+	if s, ok := builder.samplesBuffer[0].(*dbwriter.PrometheusSample); ok {
+		universalTag = s.UniversalTag
+	}
+	item := dbwriter.AcquirePrometheusExportItem(ts, extraLabels, orgId, teamId, &universalTag)
+	es.Put(dataSourceId, index, item)
+	item.Release()
+}
+
 func (b *PrometheusSamplesBuilder) GetEpcPodClusterId(orgId, vtapID uint16) (uint16, uint16, error) {
 	epcId, podClusterId := int32(0), uint16(0)
 	if vtapInfo := b.platformData.QueryVtapInfo(orgId, vtapID); vtapInfo != nil {
@@ -321,13 +404,15 @@ func (b *PrometheusSamplesBuilder) GetEpcPodClusterId(orgId, vtapID uint16) (uin
 // if failed, return false,err
 // if isSlow, return true,slowReason
 func (b *PrometheusSamplesBuilder) TimeSeriesToStore(vtapID, epcId, podClusterId, orgId, teamID uint16, ts *prompb.TimeSeries, extraLabels []prompb.Label) (bool, error) {
-	if len(ts.Samples) == 0 {
+	if len(ts.Samples) == 0 && len(ts.Histograms) == 0 && len(ts.Exemplars) == 0 {
 		b.counter.TimeSeriesInvaild++
 		return false, fmt.Errorf("prometheum samples of time serries(%s) is empty.", ts)
 	}
 	b.counter.TimeSeriesIn++
 
 	b.samplesBuffer = b.samplesBuffer[:0]
+	b.histogramsBuffer = b.histogramsBuffer[:0]
+	b.exemplarsBuffer = b.exemplarsBuffer[:0]
 	b.timeSeriesBuffer = ts
 	b.tsLabelNameIDsBuffer = b.tsLabelNameIDsBuffer[:0]
 	b.tsLabelValueIDsBuffer = b.tsLabelValueIDsBuffer[:0]
@@ -463,6 +548,36 @@ func (b *PrometheusSamplesBuilder) TimeSeriesToStore(vtapID, epcId, podClusterId
 
 		b.counter.Sample++
 	}
+
+	// native histograms and exemplars are stored without universal tags
+	for i := range ts.Histograms {
+		h := &ts.Histograms[i]
+		m := dbwriter.AcquirePrometheusHistogram()
+		m.Timestamp = uint32(model.Time(h.Timestamp).Unix())
+		m.MetricID = metricID
+		m.AppLabelValueIDs = append(m.AppLabelValueIDs, b.appLabelValueIDsBuffer...)
+		m.VtapId = vtapID
+		m.OrgId, m.TeamID = orgId, teamID
+		m.Fill(h)
+		b.histogramsBuffer = append(b.histogramsBuffer, m)
+		b.counter.Histogram++
+	}
+
+	for i := range ts.Exemplars {
+		e := &ts.Exemplars[i]
+		if math.IsNaN(e.Value) || math.IsInf(e.Value, 0) {
+			continue
+		}
+		m := dbwriter.AcquirePrometheusExemplar()
+		m.Timestamp = uint32(model.Time(e.Timestamp).Unix())
+		m.MetricID = metricID
+		m.AppLabelValueIDs = append(m.AppLabelValueIDs, b.appLabelValueIDsBuffer...)
+		m.VtapId = vtapID
+		m.OrgId, m.TeamID = orgId, teamID
+		m.Fill(e)
+		b.exemplarsBuffer = append(b.exemplarsBuffer, m)
+		b.counter.Exemplar++
+	}
 	return false, nil
 }
 
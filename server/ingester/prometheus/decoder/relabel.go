@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package decoder
+
+import (
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/khulnasoft/deepflow/server/libs/datatype/prompb"
+)
+
+// Relabeler applies the prometheus relabel_configs to the received TimeSeries before their label IDs are requested,
+// so the series dropped by relabeling do not occupy the metric and label IDs.
+// The extra labels added by the agent are relabeled together with the labels of the TimeSeries, as they are
+// a part of the stored series.
+type Relabeler struct {
+	configs []*relabel.Config
+	buffer  labels.Labels
+}
+
+func NewRelabeler(configs []*relabel.Config) *Relabeler {
+	return &Relabeler{configs: configs}
+}
+
+func (r *Relabeler) Enabled() bool {
+	return len(r.configs) > 0
+}
+
+// Relabel relabels the labels of ts and extraLabels, the result is written into the labels of ts in place,
+// so the caller should not add extraLabels to ts again. Returns false if ts should be dropped
+func (r *Relabeler) Relabel(ts *prompb.TimeSeries, extraLabels []prompb.Label) bool {
+	if len(r.configs) == 0 {
+		return true
+	}
+	r.buffer = r.buffer[:0]
+	for _, l := range ts.Labels {
+		r.buffer = append(r.buffer, labels.Label{Name: l.Name, Value: l.Value})
+	}
+	for _, l := range extraLabels {
+		r.buffer = append(r.buffer, labels.Label{Name: l.Name, Value: l.Value})
+	}
+	result := relabel.Process(r.buffer, r.configs...)
+	if len(result) == 0 {
+		return false
+	}
+	ts.Labels = ts.Labels[:0]
+	for _, l := range result {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: l.Name, Value: l.Value})
+	}
+	return true
+}
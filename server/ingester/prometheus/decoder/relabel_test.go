@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package decoder
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/relabel"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/khulnasoft/deepflow/server/libs/datatype/prompb"
+)
+
+func newTimeSeries(labels ...string) *prompb.TimeSeries {
+	ts := &prompb.TimeSeries{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: labels[i], Value: labels[i+1]})
+	}
+	return ts
+}
+
+func TestRelabel(t *testing.T) {
+	configYaml := `
+- source_labels: [__name__]
+  regex: go_.*
+  action: drop
+- regex: pod_uid
+  action: labeldrop
+- source_labels: [instance]
+  target_label: host
+  regex: (.*):\d+
+  replacement: $1
+`
+	var configs []*relabel.Config
+	if err := yaml.Unmarshal([]byte(configYaml), &configs); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRelabeler(configs)
+
+	if r.Relabel(newTimeSeries("__name__", "go_goroutines", "instance", "1.2.3.4:80"), nil) {
+		t.Error("go_goroutines should be dropped")
+	}
+	ts := newTimeSeries("__name__", "up", "instance", "1.2.3.4:80", "pod_uid", "abc")
+	if !r.Relabel(ts, nil) {
+		t.Fatal("up should not be dropped")
+	}
+	checkLabels(t, ts, newTimeSeries("__name__", "up", "host", "1.2.3.4", "instance", "1.2.3.4:80"))
+
+	// the extra labels are relabeled together with the labels of the series
+	extraLabels := newTimeSeries("env", "test", "pod_uid", "abc").Labels
+	if r.Relabel(newTimeSeries("__name__", "go_goroutines"), extraLabels) {
+		t.Error("go_goroutines should be dropped")
+	}
+	ts = newTimeSeries("__name__", "up", "instance", "1.2.3.4:80")
+	if !r.Relabel(ts, extraLabels) {
+		t.Fatal("up should not be dropped")
+	}
+	checkLabels(t, ts, newTimeSeries("__name__", "up", "env", "test", "host", "1.2.3.4", "instance", "1.2.3.4:80"))
+}
+
+func TestRelabelExtraLabels(t *testing.T) {
+	configYaml := `
+- source_labels: [cluster]
+  regex: dev
+  action: drop
+`
+	var configs []*relabel.Config
+	if err := yaml.Unmarshal([]byte(configYaml), &configs); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRelabeler(configs)
+	// the series is dropped by the extra label
+	if r.Relabel(newTimeSeries("__name__", "up"), newTimeSeries("cluster", "dev").Labels) {
+		t.Error("the series of cluster dev should be dropped")
+	}
+	ts := newTimeSeries("__name__", "up")
+	if !r.Relabel(ts, newTimeSeries("cluster", "prod").Labels) {
+		t.Fatal("the series of cluster prod should not be dropped")
+	}
+	checkLabels(t, ts, newTimeSeries("__name__", "up", "cluster", "prod"))
+}
+
+func checkLabels(t *testing.T, ts, want *prompb.TimeSeries) {
+	t.Helper()
+	if len(ts.Labels) != len(want.Labels) {
+		t.Fatalf("Relabel() = %v, want %v", ts.Labels, want.Labels)
+	}
+	for i := range want.Labels {
+		if ts.Labels[i].Name != want.Labels[i].Name || ts.Labels[i].Value != want.Labels[i].Value {
+			t.Errorf("Relabel() = %v, want %v", ts.Labels, want.Labels)
+		}
+	}
+}
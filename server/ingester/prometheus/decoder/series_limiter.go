@@ -0,0 +1,199 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package decoder
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/OneOfOne/xxhash"
+	"github.com/prometheus/common/model"
+
+	"github.com/khulnasoft/deepflow/server/ingester/common"
+	"github.com/khulnasoft/deepflow/server/ingester/prometheus/config"
+	"github.com/khulnasoft/deepflow/server/libs/datatype/prompb"
+	"github.com/khulnasoft/deepflow/server/libs/grpc"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+)
+
+const (
+	SERIES_ACCEPTED = iota
+	SERIES_ORG_LIMITED
+	SERIES_METRIC_LIMITED
+)
+
+var labelSeparator = []byte{0xff}
+
+type SeriesLimiterCounter struct {
+	ActiveSeries  int64 `statsd:"active-series"`
+	ExpiredSeries int64 `statsd:"expired-series"`
+}
+
+// orgSeries keeps the active series of an org in two generations, the current generation becomes the previous one
+// every timeout and the previous one is dropped, so that expiring is O(1) instead of walking all the series, and a
+// series expires between timeout and 2 * timeout after it is received last time
+type orgSeries struct {
+	sync.Mutex
+	current       map[uint64]string // the key is the hash of series labels, the value is the metric name
+	previous      map[uint64]string
+	currentCount  map[string]int // the series count of each metric
+	previousCount map[string]int
+	rotateTime    uint32
+}
+
+func (o *orgSeries) reset(now uint32) {
+	o.current, o.currentCount = make(map[uint64]string), make(map[string]int)
+	o.previous, o.previousCount = make(map[uint64]string), make(map[string]int)
+	o.rotateTime = now
+}
+
+// rotate drops the previous generation, returns the count of the expired series
+func (o *orgSeries) rotate(now, timeout uint32) int {
+	if now >= o.rotateTime+2*timeout {
+		// the current generation is expired too
+		expired := len(o.current) + len(o.previous)
+		o.reset(now)
+		return expired
+	}
+	expired := len(o.previous)
+	o.previous, o.previousCount = o.current, o.currentCount
+	o.current, o.currentCount = make(map[uint64]string), make(map[string]int)
+	o.rotateTime = now
+	return expired
+}
+
+func (o *orgSeries) seriesCount() int {
+	return len(o.current) + len(o.previous)
+}
+
+func (o *orgSeries) metricSeriesCount(metricName string) int {
+	return o.currentCount[metricName] + o.previousCount[metricName]
+}
+
+// SeriesLimiter limits the active series of each org and each metric of an org, it is shared by all the decoders of
+// an ingester, so the limits are per ingester instead of cluster wide. Each org has its own lock.
+// A series is active if it has been received within the ActiveSeriesTimeout, new series exceeding the limits are dropped,
+// the series already active are always accepted.
+type SeriesLimiter struct {
+	limits        [grpc.MAX_ORG_COUNT]config.SeriesLimit
+	orgs          [grpc.MAX_ORG_COUNT]orgSeries
+	timeout       uint32
+	expiredSeries int64
+
+	utils.Closable
+}
+
+// NewSeriesLimiter returns nil if no limit is configured
+func NewSeriesLimiter(cfg *config.SeriesLimitConfig) *SeriesLimiter {
+	l := &SeriesLimiter{
+		timeout: uint32(cfg.ActiveSeriesTimeout),
+	}
+	enabled := false
+	for i := range l.limits {
+		l.limits[i] = cfg.SeriesLimit
+	}
+	for _, o := range cfg.OrgOverrides {
+		l.limits[o.OrgID] = o.SeriesLimit
+	}
+	for _, limit := range l.limits {
+		if limit.MaxSeriesPerOrg > 0 || limit.MaxSeriesPerMetric > 0 {
+			enabled = true
+			break
+		}
+	}
+	if !enabled {
+		return nil
+	}
+	common.RegisterCountableForIngester("prometheus_series_limiter", l)
+	return l
+}
+
+func (l *SeriesLimiter) GetCounter() interface{} {
+	counter := &SeriesLimiterCounter{
+		ExpiredSeries: atomic.SwapInt64(&l.expiredSeries, 0),
+	}
+	for i := range l.orgs {
+		org := &l.orgs[i]
+		org.Lock()
+		counter.ActiveSeries += int64(org.seriesCount())
+		org.Unlock()
+	}
+	return counter
+}
+
+// Check records the series as active if it is accepted, returns SERIES_ACCEPTED or the limit that rejects it
+func (l *SeriesLimiter) Check(orgId uint16, metricName string, seriesHash uint64, now uint32) int {
+	limit := l.limits[orgId]
+	if limit.MaxSeriesPerOrg <= 0 && limit.MaxSeriesPerMetric <= 0 {
+		return SERIES_ACCEPTED
+	}
+
+	org := &l.orgs[orgId]
+	org.Lock()
+	defer org.Unlock()
+	if org.current == nil {
+		org.reset(now)
+	} else if now >= org.rotateTime+l.timeout {
+		atomic.AddInt64(&l.expiredSeries, int64(org.rotate(now, l.timeout)))
+	}
+
+	if _, ok := org.current[seriesHash]; ok {
+		return SERIES_ACCEPTED
+	}
+	if name, ok := org.previous[seriesHash]; ok {
+		// move the series to the current generation
+		delete(org.previous, seriesHash)
+		if org.previousCount[name] <= 1 {
+			delete(org.previousCount, name)
+		} else {
+			org.previousCount[name]--
+		}
+		org.current[seriesHash] = name
+		org.currentCount[name]++
+		return SERIES_ACCEPTED
+	}
+	if limit.MaxSeriesPerOrg > 0 && org.seriesCount() >= limit.MaxSeriesPerOrg {
+		return SERIES_ORG_LIMITED
+	}
+	if limit.MaxSeriesPerMetric > 0 && org.metricSeriesCount(metricName) >= limit.MaxSeriesPerMetric {
+		return SERIES_METRIC_LIMITED
+	}
+	// metricName is from temporary memory, so needs to be cloned
+	metricName = strings.Clone(metricName)
+	org.current[seriesHash] = metricName
+	org.currentCount[metricName]++
+	return SERIES_ACCEPTED
+}
+
+// seriesHash returns the metric name and the hash of all labels of ts, the hasher is reused by the caller
+func seriesHash(hasher *xxhash.XXHash64, ts *prompb.TimeSeries, extraLabels []prompb.Label) (string, uint64) {
+	hasher.Reset()
+	metricName := ""
+	for _, labels := range [][]prompb.Label{ts.Labels, extraLabels} {
+		for _, l := range labels {
+			if l.Name == model.MetricNameLabel {
+				metricName = l.Value
+			}
+			hasher.WriteString(l.Name)
+			hasher.Write(labelSeparator)
+			hasher.WriteString(l.Value)
+			hasher.Write(labelSeparator)
+		}
+	}
+	return metricName, hasher.Sum64()
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package decoder
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/OneOfOne/xxhash"
+
+	"github.com/khulnasoft/deepflow/server/ingester/prometheus/config"
+)
+
+func TestSeriesLimiter(t *testing.T) {
+	if NewSeriesLimiter(&config.SeriesLimitConfig{ActiveSeriesTimeout: 60}) != nil {
+		t.Error("limiter should be disabled without limits")
+	}
+
+	l := NewSeriesLimiter(&config.SeriesLimitConfig{
+		SeriesLimit:         config.SeriesLimit{MaxSeriesPerOrg: 3, MaxSeriesPerMetric: 2},
+		ActiveSeriesTimeout: 60,
+		OrgOverrides:        []config.OrgSeriesLimit{{OrgID: 2}},
+	})
+	hasher := xxhash.New64()
+	check := func(orgId uint16, now uint32, labels ...string) int {
+		metricName, hash := seriesHash(hasher, newTimeSeries(labels...), nil)
+		return l.Check(orgId, metricName, hash, now)
+	}
+
+	tests := []struct {
+		name   string
+		orgId  uint16
+		now    uint32
+		labels []string
+		want   int
+	}{
+		{"new series", 1, 100, []string{"__name__", "a", "id", "1"}, SERIES_ACCEPTED},
+		{"second series of metric", 1, 100, []string{"__name__", "a", "id", "2"}, SERIES_ACCEPTED},
+		{"metric limited", 1, 100, []string{"__name__", "a", "id", "3"}, SERIES_METRIC_LIMITED},
+		{"active series", 1, 120, []string{"__name__", "a", "id", "1"}, SERIES_ACCEPTED},
+		{"other metric", 1, 110, []string{"__name__", "b", "id", "1"}, SERIES_ACCEPTED},
+		{"org limited", 1, 110, []string{"__name__", "c", "id", "1"}, SERIES_ORG_LIMITED},
+		{"org without limit", 2, 110, []string{"__name__", "a", "id", "3"}, SERIES_ACCEPTED},
+		// the series received before 160 are moved to the previous generation, and are still active
+		{"previous generation", 1, 170, []string{"__name__", "a", "id", "1"}, SERIES_ACCEPTED},
+		{"org limited by previous generation", 1, 170, []string{"__name__", "a", "id", "3"}, SERIES_ORG_LIMITED},
+		// the series a{id="2"} and b{id="1"} are expired, a{id="1"} is received at 170 and still active
+		{"after expiration", 1, 230, []string{"__name__", "a", "id", "3"}, SERIES_ACCEPTED},
+		{"after expiration other metric", 1, 230, []string{"__name__", "c", "id", "1"}, SERIES_ACCEPTED},
+		{"org limited again", 1, 230, []string{"__name__", "d", "id", "1"}, SERIES_ORG_LIMITED},
+		// all the series are expired after 2 * timeout
+		{"all expired", 1, 400, []string{"__name__", "d", "id", "1"}, SERIES_ACCEPTED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := check(tt.orgId, tt.now, tt.labels...); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	counter := l.GetCounter().(*SeriesLimiterCounter)
+	// a{id="2"}, b{id="1"} expired at 230, a{id="1"}, a{id="3"}, c{id="1"} expired at 400
+	if counter.ActiveSeries != 1 || counter.ExpiredSeries != 5 {
+		t.Errorf("GetCounter() = %+v, want 1 active and 5 expired", counter)
+	}
+}
+
+func TestSeriesLimiterConcurrentOrgs(t *testing.T) {
+	l := NewSeriesLimiter(&config.SeriesLimitConfig{
+		SeriesLimit:         config.SeriesLimit{MaxSeriesPerOrg: 100},
+		ActiveSeriesTimeout: 60,
+	})
+	var wg sync.WaitGroup
+	for orgId := uint16(1); orgId <= 4; orgId++ {
+		wg.Add(1)
+		go func(orgId uint16) {
+			defer wg.Done()
+			for i := uint64(0); i < 1000; i++ {
+				l.Check(orgId, "a", i%200, uint32(100+i/10))
+			}
+		}(orgId)
+	}
+	wg.Wait()
+	// each org keeps at most 100 series
+	if counter := l.GetCounter().(*SeriesLimiterCounter); counter.ActiveSeries > 400 {
+		t.Errorf("active series %d exceeds the limits", counter.ActiveSeries)
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/khulnasoft/deepflow/message/trident"
 	"github.com/khulnasoft/deepflow/server/ingester/common"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters"
 	"github.com/khulnasoft/deepflow/server/ingester/prometheus/config"
 	"github.com/khulnasoft/deepflow/server/ingester/prometheus/dbwriter"
 	"github.com/khulnasoft/deepflow/server/libs/datatype/prompb"
@@ -77,9 +78,41 @@ func AcquireSlowItem(vtapId, epcId, podClusterId, orgId, teamId uint16, ts *prom
 	}
 
 	s.ts.Samples = append(s.ts.Samples, ts.Samples...)
+	for i := range ts.Histograms {
+		s.ts.Histograms = append(s.ts.Histograms, cloneHistogram(&ts.Histograms[i]))
+	}
+	for _, e := range ts.Exemplars {
+		exemplar := prompb.Exemplar{Value: e.Value, Timestamp: e.Timestamp}
+		for _, l := range e.Labels {
+			exemplar.Labels = append(exemplar.Labels, prompb.Label{
+				Name:  strings.Clone(l.Name),
+				Value: strings.Clone(l.Value),
+			})
+		}
+		s.ts.Exemplars = append(s.ts.Exemplars, exemplar)
+	}
 	return s
 }
 
+// the buckets of the histogram may reuse the memory of the request, so they need to be cloned
+func cloneHistogram(h *prompb.Histogram) prompb.Histogram {
+	return prompb.Histogram{
+		Count:          h.Count,
+		Sum:            h.Sum,
+		Schema:         h.Schema,
+		ZeroThreshold:  h.ZeroThreshold,
+		ZeroCount:      h.ZeroCount,
+		NegativeSpans:  append([]prompb.BucketSpan{}, h.NegativeSpans...),
+		NegativeDeltas: append([]int64{}, h.NegativeDeltas...),
+		NegativeCounts: append([]float64{}, h.NegativeCounts...),
+		PositiveSpans:  append([]prompb.BucketSpan{}, h.PositiveSpans...),
+		PositiveDeltas: append([]int64{}, h.PositiveDeltas...),
+		PositiveCounts: append([]float64{}, h.PositiveCounts...),
+		ResetHint:      h.ResetHint,
+		Timestamp:      h.Timestamp,
+	}
+}
+
 func ReleaseSlowItem(s *SlowItem) {
 	if s.ts.Labels != nil {
 		s.ts.Labels = s.ts.Labels[:0]
@@ -87,6 +120,12 @@ func ReleaseSlowItem(s *SlowItem) {
 	if s.ts.Samples != nil {
 		s.ts.Samples = s.ts.Samples[:0]
 	}
+	if s.ts.Histograms != nil {
+		s.ts.Histograms = s.ts.Histograms[:0]
+	}
+	if s.ts.Exemplars != nil {
+		s.ts.Exemplars = s.ts.Exemplars[:0]
+	}
 	slowItemPool.Put(s)
 }
 
@@ -96,6 +135,8 @@ type SlowDecoder struct {
 	debugEnabled     bool
 	config           *config.Config
 	prometheusWriter *dbwriter.PrometheusWriter
+	exporters        *exporters.Exporters
+	exporterIndex    int
 
 	samplesBuilder *PrometheusSamplesBuilder
 	labelTable     *PrometheusLabelTable
@@ -110,10 +151,14 @@ func NewSlowDecoder(
 	prometheusLabelTable *PrometheusLabelTable,
 	inQueue queue.QueueReader,
 	prometheusWriter *dbwriter.PrometheusWriter,
+	exporters *exporters.Exporters,
+	exporterIndex int,
 	config *config.Config,
 ) *SlowDecoder {
 	return &SlowDecoder{
 		index:            index,
+		exporters:        exporters,
+		exporterIndex:    exporterIndex,
 		samplesBuilder:   NewPrometheusSamplesBuilder("slow-prometheus-builder", index, platformData, prometheusLabelTable, config.AppLabelColumnIncrement, config.IgnoreUniversalTag),
 		labelTable:       prometheusLabelTable,
 		inQueue:          inQueue,
@@ -144,6 +189,7 @@ func (d *SlowDecoder) Run() {
 		for i := 0; i < n; i++ {
 			if buffer[i] == nil {
 				queueTicker++
+				flushExporters(d.exporters, d.exporterIndex)
 				continue
 			}
 			d.counter.TimeSeriesIn++
@@ -288,11 +334,7 @@ func (d *SlowDecoder) sendPrometheusSamples(vtapID, epcId, podClusterId, orgId,
 		d.counter.TimeSeriesDrop++
 		return
 	}
-	d.prometheusWriter.WriteBatch(d.samplesBuilder.samplesBuffer,
-		d.samplesBuilder.metricName,
-		d.samplesBuilder.timeSeriesBuffer,
-		nil,
-		d.samplesBuilder.tsLabelNameIDsBuffer, d.samplesBuilder.tsLabelValueIDsBuffer)
-	d.counter.SampleOut += int64(len(d.samplesBuilder.samplesBuffer))
+	exportTimeSeries(d.exporters, d.exporterIndex, d.samplesBuilder, vtapID, orgId, teamId, ts, nil)
+	d.counter.SampleOut += int64(d.samplesBuilder.writeBatches(d.prometheusWriter, nil))
 	d.counter.TimeSeriesOut++
 }
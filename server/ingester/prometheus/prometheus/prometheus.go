@@ -23,7 +23,10 @@ import (
 	_ "golang.org/x/net/context"
 	_ "google.golang.org/grpc"
 
+	logging "github.com/op/go-logging"
+
 	dropletqueue "github.com/khulnasoft/deepflow/server/ingester/droplet/queue"
+	"github.com/khulnasoft/deepflow/server/ingester/exporters"
 	"github.com/khulnasoft/deepflow/server/ingester/ingesterctl"
 	"github.com/khulnasoft/deepflow/server/ingester/prometheus/config"
 	"github.com/khulnasoft/deepflow/server/ingester/prometheus/dbwriter"
@@ -36,6 +39,8 @@ import (
 	"github.com/khulnasoft/deepflow/server/libs/receiver"
 )
 
+var log = logging.MustGetLogger("prometheus")
+
 type PrometheusHandler struct {
 	Config               *config.Config
 	LabelTable           *decoder.PrometheusLabelTable
@@ -46,9 +51,14 @@ type PrometheusHandler struct {
 	prometheusLabelTable *decoder.PrometheusLabelTable
 }
 
-func NewPrometheusHandler(config *config.Config, recv *receiver.Receiver, platformDataManager *grpc.PlatformDataManager) (*PrometheusHandler, error) {
+func NewPrometheusHandler(config *config.Config, recv *receiver.Receiver, platformDataManager *grpc.PlatformDataManager, exporters *exporters.Exporters) (*PrometheusHandler, error) {
 	manager := dropletqueue.NewManager(ingesterctl.INGESTERCTL_PROMETHEUS_QUEUE)
 	queueCount := config.DecoderQueueCount
+	// the decoders and the slow decoders export to the same datasource, so they use different exporter indexes
+	if exporters != nil && 2*queueCount > libqueue.MAX_QUEUE_COUNT {
+		log.Warningf("the exporter index of prometheus exceeds %d, disable exporting", libqueue.MAX_QUEUE_COUNT)
+		exporters = nil
+	}
 	msgType := datatype.MESSAGE_TYPE_PROMETHEUS
 	decodeQueues := manager.NewQueues(
 		"1-receive-to-decode-"+msgType.String(),
@@ -76,6 +86,7 @@ func NewPrometheusHandler(config *config.Config, recv *receiver.Receiver, platfo
 		initAppLabelColumnCount = currentColumnIndexMax
 	}
 
+	seriesLimiter := decoder.NewSeriesLimiter(&config.SeriesLimit)
 	decoders := make([]*decoder.Decoder, queueCount)
 	platformDatas := make([]*grpc.PlatformInfoTable, queueCount)
 	slowDecoders := make([]*decoder.SlowDecoder, queueCount)
@@ -100,6 +111,9 @@ func NewPrometheusHandler(config *config.Config, recv *receiver.Receiver, platfo
 			queue.QueueReader(decodeQueues.FixedMultiQueue[i]),
 			queue.QueueWriter(slowDecodeQueues.FixedMultiQueue[i]),
 			metricsWriter,
+			exporters,
+			i,
+			seriesLimiter,
 			config,
 		)
 		slowMetricsWriter, err := dbwriter.NewPrometheusWriter(i, initAppLabelColumnCount, "slow-prometheus", dbwriter.PROMETHEUS_DB, config)
@@ -113,6 +127,8 @@ func NewPrometheusHandler(config *config.Config, recv *receiver.Receiver, platfo
 			prometheusLabelTable,
 			queue.QueueReader(slowDecodeQueues.FixedMultiQueue[i]),
 			slowMetricsWriter,
+			exporters,
+			queueCount+i,
 			config,
 		)
 	}
@@ -34,6 +34,7 @@ const DEFAULT_COLUMN_COUNT = 256
 type Block struct {
 	batch driver.Batch
 	items []interface{}
+	rows  [][]interface{}
 }
 
 func NewBlock(batch driver.Batch) *Block {
@@ -43,12 +44,30 @@ func NewBlock(batch driver.Batch) *Block {
 	}
 }
 
+// NewRowBlock returns a block without batch, the written rows are kept in memory and can be got by Rows()
+func NewRowBlock() *Block {
+	return &Block{
+		items: make([]interface{}, 0, DEFAULT_COLUMN_COUNT),
+	}
+}
+
 func (b *Block) WriteAll() error {
+	if b.batch == nil {
+		row := make([]interface{}, len(b.items))
+		copy(row, b.items)
+		b.rows = append(b.rows, row)
+		b.items = b.items[:0]
+		return nil
+	}
 	err := b.batch.Append(b.items...)
 	b.items = b.items[:0]
 	return err
 }
 
+func (b *Block) Rows() [][]interface{} {
+	return b.rows
+}
+
 func (b *Block) Send() error {
 	return b.batch.Send()
 }
@@ -19,7 +19,6 @@ package debug
 import (
 	"bytes"
 	"fmt"
-	"net"
 
 	"github.com/spf13/cobra"
 )
@@ -97,7 +96,10 @@ func ClientRegisterSimple(moduleId ModuleId, module CmdHelper, operates []CmdHel
 	return command
 }
 
-func RecvFromServerMulti(conn *net.UDPConn) (*bytes.Buffer, error) {
+func RecvFromServerMulti(conn ClientConn) (*bytes.Buffer, error) {
+	if c, ok := conn.(*streamConn); ok {
+		return c.recvAll()
+	}
 	ret := bytes.NewBuffer(make([]byte, 0))
 	for {
 		data, err := RecvFromServer(conn)
@@ -0,0 +1,447 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package debug
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the stream server serves debug commands by HTTP(S) with token or mTLS authentication,
+// responses are streamed as DebugMessages and are not limited by UDP_MAXLEN
+const (
+	STREAM_PATH         = "/v1/debug"
+	STREAM_CHUNK_LEN    = 1 << 20
+	STREAM_MAX_REQUEST  = 1 << 20
+	STREAM_IDLE_TIMEOUT = time.Minute
+)
+
+type StreamServerConfig struct {
+	Port         int
+	Token        string // clients send it in the header 'Authorization: Bearer <token>'
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string // if set, client certificates signed by it are required
+}
+
+type StreamClientConfig struct {
+	Port               int
+	Token              string
+	TLS                bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+var (
+	streamSinks  sync.Map // *net.UDPAddr -> *streamSink
+	streamClient *streamClientState
+)
+
+func getStreamSink(remote *net.UDPAddr) *streamSink {
+	if remote == nil {
+		return nil
+	}
+	if sink, ok := streamSinks.Load(remote); ok {
+		return sink.(*streamSink)
+	}
+	return nil
+}
+
+type streamSink struct {
+	sync.Mutex
+	writer   http.ResponseWriter
+	flusher  http.Flusher
+	encoder  *gob.Encoder
+	closed   bool
+	activity chan struct{}
+}
+
+func newStreamSink(w http.ResponseWriter) *streamSink {
+	s := &streamSink{
+		writer:   w,
+		encoder:  gob.NewEncoder(w),
+		activity: make(chan struct{}, 1),
+	}
+	s.flusher, _ = w.(http.Flusher)
+	return s
+}
+
+func (s *streamSink) send(msg *DebugMessage) {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return
+	}
+	if err := s.encoder.Encode(msg); err != nil {
+		log.Debugf("debug stream send failed: %s", err)
+		s.closed = true
+		return
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	select {
+	case s.activity <- struct{}{}:
+	default:
+	}
+}
+
+func (s *streamSink) close() {
+	s.Lock()
+	s.closed = true
+	s.Unlock()
+}
+
+// modules may send messages asynchronously, the stream ends when the client disconnects or it is idle for a while
+func (s *streamSink) wait(done <-chan struct{}, idleTimeout time.Duration) {
+	timer := time.NewTimer(idleTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+			return
+		case <-s.activity:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(idleTimeout)
+		}
+	}
+}
+
+type streamServer struct {
+	token string
+}
+
+func (s *streamServer) authenticate(r *http.Request) bool {
+	if s.token == "" {
+		// authenticated by the client certificate
+		return r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(s.token)) == 1
+}
+
+func (s *streamServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != STREAM_PATH {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authenticate(r) {
+		log.Warningf("debug stream from %s unauthorized", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	msg := DebugMessage{}
+	if err := gob.NewDecoder(http.MaxBytesReader(w, r.Body, STREAM_MAX_REQUEST)).Decode(&msg); err != nil {
+		http.Error(w, fmt.Sprintf("decode request failed: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	sink := newStreamSink(w)
+	defer sink.close()
+
+	if msg.Module < MODULE_MAX && recvHandlers[msg.Module] != nil {
+		remote := &net.UDPAddr{}
+		if addr, err := net.ResolveUDPAddr("udp", r.RemoteAddr); err == nil {
+			remote = addr
+		}
+		streamSinks.Store(remote, sink)
+		defer streamSinks.Delete(remote)
+		recvHandlers[msg.Module].RecvCommand(nil, remote, msg.Operate, bytes.NewBuffer(msg.Args))
+		sink.wait(r.Context().Done(), STREAM_IDLE_TIMEOUT)
+		return
+	}
+	if int(msg.Module) < len(simpleHandlers) && simpleHandlers[msg.Module] != nil {
+		result := simpleHandlers[msg.Module].HandleSimpleCommand(msg.Operate, string(msg.Args))
+		for i := 0; i < len(result); i += STREAM_CHUNK_LEN {
+			end := i + STREAM_CHUNK_LEN
+			if end > len(result) {
+				end = len(result)
+			}
+			sink.send(&DebugMessage{Operate: 11, Args: []byte(result[i:end])})
+		}
+		return
+	}
+	sink.send(&DebugMessage{Operate: 11, Result: 1})
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+	return pool, nil
+}
+
+// StartStreamServer serves the registered modules by HTTP(S), one of token and client CA is required
+func StartStreamServer(cfg StreamServerConfig) error {
+	if cfg.Token == "" && cfg.ClientCAFile == "" {
+		return errors.New("debug stream server requires a token or a client CA")
+	}
+	if cfg.ClientCAFile != "" && (cfg.CertFile == "" || cfg.KeyFile == "") {
+		return errors.New("debug stream server requires the certificate and key to verify client certificates")
+	}
+	server := &http.Server{
+		Addr:    net.JoinHostPort(hostIp, strconv.Itoa(cfg.Port)),
+		Handler: &streamServer{token: cfg.Token},
+	}
+	if cfg.ClientCAFile != "" {
+		pool, err := loadCertPool(cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = &tls.Config{
+			ClientCAs:  pool,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+
+	useTLS := cfg.CertFile != "" && cfg.KeyFile != ""
+	if !useTLS {
+		log.Warning("debug stream server is not using TLS, the token is sent in plain text")
+	}
+	go func() {
+		log.Infof("DebugStreamServer <%s> tls: %v", server.Addr, useTLS)
+		var err error
+		if useTLS {
+			err = server.ServeTLS(listener, cfg.CertFile, cfg.KeyFile)
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			log.Error(err)
+		}
+	}()
+	return nil
+}
+
+type streamClientState struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+// SetStreamClient makes the client send commands to the stream server instead of UDP
+func SetStreamClient(cfg StreamClientConfig) error {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+		}).DialContext,
+	}
+	scheme := "http"
+	if cfg.TLS || cfg.CAFile != "" || cfg.CertFile != "" {
+		scheme = "https"
+		tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+		if cfg.CAFile != "" {
+			pool, err := loadCertPool(cfg.CAFile)
+			if err != nil {
+				return err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		if cfg.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+			if err != nil {
+				return err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	streamClient = &streamClientState{
+		client: &http.Client{Transport: transport},
+		url:    fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(hostIp, strconv.Itoa(cfg.Port)), STREAM_PATH),
+		token:  cfg.Token,
+	}
+	return nil
+}
+
+type streamTimeoutError struct{}
+
+func (e streamTimeoutError) Error() string   { return "i/o timeout" }
+func (e streamTimeoutError) Timeout() bool   { return true }
+func (e streamTimeoutError) Temporary() bool { return true }
+
+type streamConn struct {
+	body      io.ReadCloser
+	messages  chan *DebugMessage
+	err       error // valid after messages is closed
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	deadlineLock sync.Mutex
+	deadline     time.Time
+}
+
+func newStreamConn(body io.ReadCloser) *streamConn {
+	c := &streamConn{
+		body:     body,
+		messages: make(chan *DebugMessage, 16),
+		closed:   make(chan struct{}),
+	}
+	go func() {
+		decoder := gob.NewDecoder(body)
+		for {
+			msg := &DebugMessage{}
+			if err := decoder.Decode(msg); err != nil {
+				c.err = err
+				close(c.messages)
+				return
+			}
+			select {
+			case c.messages <- msg:
+			case <-c.closed:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+func (c *streamConn) SetReadDeadline(t time.Time) error {
+	c.deadlineLock.Lock()
+	c.deadline = t
+	c.deadlineLock.Unlock()
+	return nil
+}
+
+func (c *streamConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return c.body.Close()
+}
+
+func (c *streamConn) recv() (*DebugMessage, error) {
+	c.deadlineLock.Lock()
+	deadline := c.deadline
+	c.deadlineLock.Unlock()
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			return nil, c.err
+		}
+		return msg, nil
+	case <-timeout:
+		return nil, streamTimeoutError{}
+	}
+}
+
+func (c *streamConn) recvArgs() (*bytes.Buffer, error) {
+	msg, err := c.recv()
+	if err != nil {
+		return nil, err
+	}
+	if msg.Result != 0 {
+		return nil, errors.New("msg.Result != 0")
+	}
+	return bytes.NewBuffer(msg.Args), nil
+}
+
+// the result of a simple command is sent in chunks, and the server ends the stream after it
+func (c *streamConn) recvAll() (*bytes.Buffer, error) {
+	ret := &bytes.Buffer{}
+	for {
+		data, err := c.recvArgs()
+		if err == io.EOF {
+			return ret, nil
+		} else if err != nil {
+			return ret, err
+		}
+		ret.Write(data.Bytes())
+	}
+}
+
+func sendToStreamServer(module ModuleId, operate ModuleOperate, args *bytes.Buffer) (ClientConn, *bytes.Buffer, error) {
+	msg := DebugMessage{Module: uint16(module), Operate: uint16(operate), Result: 0}
+	if args != nil {
+		msg.Args = args.Bytes()
+	}
+	body := &bytes.Buffer{}
+	if err := gob.NewEncoder(body).Encode(msg); err != nil {
+		return nil, nil, err
+	}
+	request, err := http.NewRequest(http.MethodPost, streamClient.url, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+	if streamClient.token != "" {
+		request.Header.Set("Authorization", "Bearer "+streamClient.token)
+	}
+	response, err := streamClient.client.Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		text, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+		response.Body.Close()
+		return nil, nil, fmt.Errorf("debug stream server returns %s: %s", response.Status, strings.TrimSpace(string(text)))
+	}
+
+	conn := newStreamConn(response.Body)
+	conn.SetReadDeadline(time.Now().Add(time.Minute))
+	var recv *bytes.Buffer
+	if module > MODULE_MAX {
+		recv, err = RecvFromServerMulti(conn)
+	} else {
+		recv, err = RecvFromServer(conn)
+	}
+	return conn, recv, err
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package debug
+
+import (
+	"bytes"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testStreamModule       ModuleId = 5
+	testStreamSimpleModule ModuleId = MODULE_MAX + 30
+)
+
+type testSimpleProcess struct {
+	result string
+}
+
+func (p *testSimpleProcess) HandleSimpleCommand(operate uint16, arg string) string {
+	return p.result
+}
+
+type testStreamProcess struct{}
+
+func (p *testStreamProcess) RecvCommand(conn *net.UDPConn, remote *net.UDPAddr, operate uint16, arg *bytes.Buffer) {
+	SendToClient(conn, remote, 0, bytes.NewBufferString("first "+arg.String()))
+	go func() {
+		// larger than UDP_MAXLEN
+		SendToClient(conn, remote, 0, bytes.NewBufferString(strings.Repeat("x", UDP_MAXLEN*2)))
+	}()
+}
+
+func newTestStreamServer(t *testing.T, token string) *httptest.Server {
+	server := httptest.NewServer(&streamServer{token: token})
+	t.Cleanup(func() {
+		server.Close()
+		streamClient = nil
+	})
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	SetIpAndPort(host, DEFAULT_LISTEN_PORT)
+	if err := SetStreamClient(StreamClientConfig{Port: portNum, Token: token}); err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func TestStreamUnauthorized(t *testing.T) {
+	newTestStreamServer(t, "secret")
+	streamClient.token = "wrong"
+	simpleHandlers[testStreamSimpleModule] = &testSimpleProcess{result: "ok"}
+	defer func() { simpleHandlers[testStreamSimpleModule] = nil }()
+
+	_, _, err := SendToServer(testStreamSimpleModule, 0, nil)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestStreamSimpleCommand(t *testing.T) {
+	newTestStreamServer(t, "secret")
+	expected := strings.Repeat("0123456789", STREAM_CHUNK_LEN/4)
+	simpleHandlers[testStreamSimpleModule] = &testSimpleProcess{result: expected}
+	defer func() { simpleHandlers[testStreamSimpleModule] = nil }()
+
+	result, err := CommmandGetResult(testStreamSimpleModule, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != expected {
+		t.Fatalf("expected result of length %d, got %d", len(expected), len(result))
+	}
+}
+
+func TestStreamCommand(t *testing.T) {
+	newTestStreamServer(t, "secret")
+	recvHandlers[testStreamModule] = &testStreamProcess{}
+	defer func() { recvHandlers[testStreamModule] = nil }()
+
+	conn, result, err := SendToServer(testStreamModule, 0, bytes.NewBufferString("arg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if result.String() != "first arg" {
+		t.Fatalf("expected 'first arg', got '%s'", result.String())
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	result, err = RecvFromServer(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Len() != UDP_MAXLEN*2 {
+		t.Fatalf("expected message of length %d, got %d", UDP_MAXLEN*2, result.Len())
+	}
+
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err = RecvFromServer(conn); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
@@ -39,6 +39,12 @@ type CommandLineProcess interface {
 	RecvCommand(conn *net.UDPConn, remote *net.UDPAddr, operate uint16, arg *bytes.Buffer)
 }
 
+// client端连接, 可以是UDP连接, 也可以是stream连接
+type ClientConn interface {
+	SetReadDeadline(t time.Time) error
+	Close() error
+}
+
 const (
 	DEFAULT_LISTEN_PORT = 9528
 	UDP_MAXLEN          = 9710
@@ -53,6 +59,7 @@ var (
 	log          = logging.MustGetLogger(os.Args[0])
 	orgId        = 0
 
+	udpDisabled      = false
 	recvHandlers     = [MODULE_MAX]CommandLineProcess{}
 	registerHandlers = [MODULE_MAX]RegisterCommmandLine{}
 )
@@ -68,6 +75,11 @@ func SetIpAndPort(ip string, port int) {
 	hostPort = port
 }
 
+// 关闭UDP监听, 只通过stream server处理命令
+func SetUDPDisabled(disabled bool) {
+	udpDisabled = disabled
+}
+
 func SetOrgId(id int) {
 	orgId = id
 }
@@ -79,7 +91,11 @@ func GetOrgId() uint16 {
 	return uint16(orgId)
 }
 
-func RecvFromServer(conn *net.UDPConn) (*bytes.Buffer, error) {
+func RecvFromServer(clientConn ClientConn) (*bytes.Buffer, error) {
+	if c, ok := clientConn.(*streamConn); ok {
+		return c.recvArgs()
+	}
+	conn := clientConn.(*net.UDPConn)
 	data := make([]byte, UDP_MAXLEN)
 	msg := DebugMessage{}
 
@@ -97,7 +113,10 @@ func RecvFromServer(conn *net.UDPConn) (*bytes.Buffer, error) {
 	return bytes.NewBuffer(msg.Args[:]), nil
 }
 
-func SendToServer(module ModuleId, operate ModuleOperate, args *bytes.Buffer) (*net.UDPConn, *bytes.Buffer, error) {
+func SendToServer(module ModuleId, operate ModuleOperate, args *bytes.Buffer) (ClientConn, *bytes.Buffer, error) {
+	if streamClient != nil {
+		return sendToStreamServer(module, operate, args)
+	}
 	ip := hostIp
 	if strings.Contains(ip, ":") {
 		ip = "[" + ip + "]"
@@ -135,6 +154,15 @@ func SendToClient(conn *net.UDPConn, remote *net.UDPAddr, result uint32, args *b
 	if args != nil {
 		msg.Args = args.Bytes()
 	}
+	// 命令来自stream server时, remote对应一个stream, 数据不受UDP_MAXLEN限制
+	if sink := getStreamSink(remote); sink != nil {
+		sink.send(&msg)
+		return
+	}
+	if conn == nil {
+		// stream已关闭
+		return
+	}
 	encoder := gob.NewEncoder(&buffer)
 	if err := encoder.Encode(msg); err != nil {
 		log.Error(err)
@@ -167,7 +195,7 @@ func process(conn *net.UDPConn) {
 		recvHandlers[msg.Module].RecvCommand(conn, remote, msg.Operate, bytes.NewBuffer(msg.Args[:]))
 		return
 	}
-	if simpleHandlers[msg.Module] != nil {
+	if int(msg.Module) < len(simpleHandlers) && simpleHandlers[msg.Module] != nil {
 		result := simpleHandlers[msg.Module].HandleSimpleCommand(msg.Operate, string(msg.Args))
 		for i := 0; i < len(result); i += MAX_PAYLOAD_LEN {
 			if i+MAX_PAYLOAD_LEN > len(result) {
@@ -187,6 +215,10 @@ func process(conn *net.UDPConn) {
 }
 
 func debugListener() {
+	if udpDisabled {
+		log.Info("DebugListener of UDP is disabled")
+		return
+	}
 	go func() {
 		addr := &net.UDPAddr{IP: net.ParseIP(hostIp), Port: hostPort}
 		listener, err := net.ListenUDP("udp", addr)
@@ -0,0 +1,224 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package geo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+const (
+	DEFAULT_MMDB_LANGUAGE        = "en"
+	DEFAULT_MMDB_RELOAD_INTERVAL = time.Minute
+)
+
+// IPGeoInfo is the enrichment looked up from MaxMind compatible databases
+type IPGeoInfo struct {
+	Country string
+	City    string
+	ASN     uint32
+	ASNOrg  string
+}
+
+type mmdbNames struct {
+	IsoCode string            `maxminddb:"iso_code"`
+	Names   map[string]string `maxminddb:"names"`
+}
+
+type mmdbCountryRecord struct {
+	Country mmdbNames `maxminddb:"country"`
+}
+
+type mmdbCityRecord struct {
+	City    mmdbNames `maxminddb:"city"`
+	Country mmdbNames `maxminddb:"country"`
+}
+
+type mmdbASNRecord struct {
+	ASN    uint32 `maxminddb:"autonomous_system_number"`
+	ASNOrg string `maxminddb:"autonomous_system_organization"`
+}
+
+type mmdbFile struct {
+	path    string
+	reader  atomic.Value // *maxminddb.Reader
+	modTime time.Time
+	size    int64
+}
+
+// load reads the file again if it was modified since the last load. the
+// previous reader stays in use when the new file can not be parsed.
+func (f *mmdbFile) load() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, err
+	}
+	if f.get() != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return false, nil
+	}
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return false, err
+	}
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return false, fmt.Errorf("parse mmdb file %s failed: %s", f.path, err)
+	}
+	f.reader.Store(reader)
+	f.modTime, f.size = info.ModTime(), info.Size()
+	return true, nil
+}
+
+func (f *mmdbFile) get() *maxminddb.Reader {
+	if f == nil {
+		return nil
+	}
+	reader, _ := f.reader.Load().(*maxminddb.Reader)
+	return reader
+}
+
+// MMDB looks up country, city and ASN of IPv4 and IPv6 addresses from
+// user supplied MaxMind DB files, and reloads the files when they are changed
+type MMDB struct {
+	country, city, asn *mmdbFile
+	language           string
+	reloadInterval     time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewMMDB(countryFile, cityFile, asnFile, language string, reloadInterval time.Duration) (*MMDB, error) {
+	if countryFile == "" && cityFile == "" && asnFile == "" {
+		return nil, fmt.Errorf("no mmdb file is configured")
+	}
+	if language == "" {
+		language = DEFAULT_MMDB_LANGUAGE
+	}
+	if reloadInterval <= 0 {
+		reloadInterval = DEFAULT_MMDB_RELOAD_INTERVAL
+	}
+	m := &MMDB{
+		language:       language,
+		reloadInterval: reloadInterval,
+		stop:           make(chan struct{}),
+	}
+	for _, f := range []struct {
+		path string
+		file **mmdbFile
+	}{{countryFile, &m.country}, {cityFile, &m.city}, {asnFile, &m.asn}} {
+		if f.path == "" {
+			continue
+		}
+		*f.file = &mmdbFile{path: f.path}
+		if _, err := (*f.file).load(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *MMDB) reload() {
+	for _, f := range []*mmdbFile{m.country, m.city, m.asn} {
+		if f == nil {
+			continue
+		}
+		if reloaded, err := f.load(); err != nil {
+			log.Warningf("reload mmdb failed: %s", err)
+		} else if reloaded {
+			log.Infof("mmdb file %s reloaded", f.path)
+		}
+	}
+}
+
+func (m *MMDB) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.reloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.reload()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (m *MMDB) Close() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+func (m *MMDB) name(n *mmdbNames) string {
+	if name, ok := n.Names[m.language]; ok && name != "" {
+		return name
+	}
+	if name, ok := n.Names[DEFAULT_MMDB_LANGUAGE]; ok && name != "" {
+		return name
+	}
+	return n.IsoCode
+}
+
+// Query fills info with the geo information of ip, the fields not found are left empty
+func (m *MMDB) Query(ip net.IP, info *IPGeoInfo) {
+	*info = IPGeoInfo{}
+	if m == nil || len(ip) == 0 || ip.IsUnspecified() {
+		return
+	}
+	if reader := m.city.get(); reader != nil {
+		var record mmdbCityRecord
+		if err := reader.Lookup(ip, &record); err == nil {
+			info.City = m.name(&record.City)
+			info.Country = m.name(&record.Country)
+		}
+	}
+	if reader := m.country.get(); reader != nil && info.Country == "" {
+		var record mmdbCountryRecord
+		if err := reader.Lookup(ip, &record); err == nil {
+			info.Country = m.name(&record.Country)
+		}
+	}
+	if reader := m.asn.get(); reader != nil {
+		var record mmdbASNRecord
+		if err := reader.Lookup(ip, &record); err == nil {
+			info.ASN = record.ASN
+			info.ASNOrg = record.ASNOrg
+		}
+	}
+}
+
+func (m *MMDB) QueryIPv4(ip uint32, info *IPGeoInfo) {
+	if m == nil || ip == 0 {
+		*info = IPGeoInfo{}
+		return
+	}
+	var buf [net.IPv4len]byte
+	binary.BigEndian.PutUint32(buf[:], ip)
+	m.Query(net.IP(buf[:]), info)
+}
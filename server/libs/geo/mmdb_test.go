@@ -0,0 +1,252 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package geo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+type testNetwork struct {
+	cidr string
+	data map[string]interface{}
+}
+
+type testNode struct {
+	children [2]*testNode
+	data     int
+}
+
+func testControl(typ, size int) []byte {
+	var b []byte
+	if typ <= 7 {
+		b = []byte{byte(typ << 5)}
+	} else {
+		b = []byte{0, byte(typ - 7)}
+	}
+	if size < 29 {
+		b[0] |= byte(size)
+	} else {
+		b[0] |= 29
+		b = append(b, byte(size-29))
+	}
+	return b
+}
+
+func testEncode(v interface{}) []byte {
+	switch v := v.(type) {
+	case string:
+		return append(testControl(2, len(v)), v...)
+	case uint16:
+		return append(testControl(5, 2), byte(v>>8), byte(v))
+	case uint32:
+		buf := make([]byte, 4)
+		binary.BigEndian.PutUint32(buf, v)
+		return append(testControl(6, 4), buf...)
+	case []string:
+		b := testControl(11, len(v))
+		for _, s := range v {
+			b = append(b, testEncode(s)...)
+		}
+		return b
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for k, s := range v {
+			m[k] = s
+		}
+		return testEncode(m)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b := testControl(7, len(v))
+		for _, k := range keys {
+			b = append(b, testEncode(k)...)
+			b = append(b, testEncode(v[k])...)
+		}
+		return b
+	}
+	panic("unsupported type")
+}
+
+// buildTestMMDB writes a minimal MaxMind DB with 24 bits records
+func buildTestMMDB(ipVersion int, networks []testNetwork) []byte {
+	root := &testNode{data: -1}
+	var dataSection []byte
+	var dataOffsets []int
+	for i, n := range networks {
+		_, ipNet, err := net.ParseCIDR(n.cidr)
+		if err != nil {
+			panic(err)
+		}
+		ip, prefix := ipNet.IP, 0
+		ones, _ := ipNet.Mask.Size()
+		if ip4 := ip.To4(); ip4 != nil && ipVersion == 6 {
+			ip, prefix = net.IP(append(make([]byte, 12), ip4...)), 96
+		} else if ip4 != nil {
+			ip = ip4
+		}
+		node := root
+		for bit := 0; bit < prefix+ones; bit++ {
+			b := (ip[bit/8] >> (7 - uint(bit%8))) & 1
+			if node.children[b] == nil {
+				node.children[b] = &testNode{data: -1}
+			}
+			node = node.children[b]
+		}
+		node.data = i
+		dataOffsets = append(dataOffsets, len(dataSection))
+		dataSection = append(dataSection, testEncode(n.data)...)
+	}
+
+	var nodes []*testNode
+	index := map[*testNode]int{}
+	for queue := []*testNode{root}; len(queue) > 0; queue = queue[1:] {
+		index[queue[0]] = len(nodes)
+		nodes = append(nodes, queue[0])
+		for _, child := range queue[0].children {
+			if child != nil && child.data < 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+	nodeCount := len(nodes)
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		for _, child := range node.children {
+			record := nodeCount
+			if child != nil && child.data >= 0 {
+				record = nodeCount + 16 + dataOffsets[child.data]
+			} else if child != nil {
+				record = index[child]
+			}
+			buf.Write([]byte{byte(record >> 16), byte(record >> 8), byte(record)})
+		}
+	}
+	buf.Write(make([]byte, 16))
+	buf.Write(dataSection)
+	buf.WriteString("\xab\xcd\xefMaxMind.com")
+	buf.Write(testEncode(map[string]interface{}{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(ipVersion),
+		"database_type":               "test",
+		"languages":                   []string{"en"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+	}))
+	return buf.Bytes()
+}
+
+func writeTestMMDB(t *testing.T, path string, ipVersion int, networks []testNetwork) {
+	if err := os.WriteFile(path, buildTestMMDB(ipVersion, networks), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testASN(asn uint32, org string) map[string]interface{} {
+	return map[string]interface{}{"autonomous_system_number": asn, "autonomous_system_organization": org}
+}
+
+func TestMMDBQuery(t *testing.T) {
+	dir := t.TempDir()
+	countryFile, cityFile, asnFile := filepath.Join(dir, "country.mmdb"), filepath.Join(dir, "city.mmdb"), filepath.Join(dir, "asn.mmdb")
+	writeTestMMDB(t, countryFile, 4, []testNetwork{
+		{"8.8.8.0/24", map[string]interface{}{"country": map[string]interface{}{"iso_code": "US", "names": map[string]string{"en": "United States"}}}},
+	})
+	writeTestMMDB(t, cityFile, 6, []testNetwork{
+		{"1.0.0.0/24", map[string]interface{}{
+			"city":    map[string]interface{}{"names": map[string]string{"en": "Brisbane", "zh-CN": "布里斯班"}},
+			"country": map[string]interface{}{"iso_code": "AU", "names": map[string]string{"en": "Australia"}},
+		}},
+		{"2001:db8::/32", map[string]interface{}{
+			"city":    map[string]interface{}{"names": map[string]string{"en": "Test City"}},
+			"country": map[string]interface{}{"iso_code": "ZZ"},
+		}},
+	})
+	writeTestMMDB(t, asnFile, 6, []testNetwork{
+		{"1.0.0.0/24", testASN(13335, "Cloudflare")},
+		{"2001:db8::/32", testASN(64496, "Example")},
+	})
+
+	m, err := NewMMDB(countryFile, cityFile, asnFile, "zh-CN", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info IPGeoInfo
+	m.QueryIPv4(0x01000001, &info)
+	if info != (IPGeoInfo{"Australia", "布里斯班", 13335, "Cloudflare"}) {
+		t.Errorf("query 1.0.0.1 got %+v", info)
+	}
+	m.Query(net.ParseIP("2001:db8::1"), &info)
+	if info != (IPGeoInfo{"ZZ", "Test City", 64496, "Example"}) {
+		t.Errorf("query 2001:db8::1 got %+v", info)
+	}
+	m.Query(net.ParseIP("8.8.8.8"), &info)
+	if info != (IPGeoInfo{Country: "United States"}) {
+		t.Errorf("query 8.8.8.8 got %+v", info)
+	}
+	m.Query(net.ParseIP("2001:db9::1"), &info)
+	if info != (IPGeoInfo{}) {
+		t.Errorf("query 2001:db9::1 got %+v", info)
+	}
+
+	var nilMMDB *MMDB
+	nilMMDB.QueryIPv4(0x01000001, &info)
+	if info != (IPGeoInfo{}) {
+		t.Errorf("query nil mmdb got %+v", info)
+	}
+}
+
+func TestMMDBReload(t *testing.T) {
+	asnFile := filepath.Join(t.TempDir(), "asn.mmdb")
+	writeTestMMDB(t, asnFile, 6, []testNetwork{{"1.0.0.0/24", testASN(13335, "Cloudflare")}})
+	m, err := NewMMDB("", "", asnFile, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestMMDB(t, asnFile, 6, []testNetwork{{"1.0.0.0/24", testASN(64496, "Example")}})
+	modTime := time.Now().Add(time.Minute)
+	os.Chtimes(asnFile, modTime, modTime)
+	m.reload()
+	var info IPGeoInfo
+	m.QueryIPv4(0x01000001, &info)
+	if info.ASN != 64496 || info.ASNOrg != "Example" {
+		t.Errorf("query after reload got %+v", info)
+	}
+
+	// a broken file must not replace the loaded database
+	os.WriteFile(asnFile, []byte("broken"), 0644)
+	m.reload()
+	m.QueryIPv4(0x01000001, &info)
+	if info.ASN != 64496 {
+		t.Errorf("query after broken reload got %+v", info)
+	}
+
+	if _, err := NewMMDB("", "", "", "", 0); err == nil {
+		t.Error("expect error without mmdb files")
+	}
+}
@@ -303,6 +303,7 @@ const (
 	DATATYPE_StringSlice
 	DATATYPE_Float64Slice
 	DATATYPE_IP
+	DATATYPE_Int64Slice
 )
 
 func ToDataType(str string) DataType {
@@ -331,6 +332,8 @@ func ToDataType(str string) DataType {
 		return DATATYPE_StringSlice
 	case "[]float64":
 		return DATATYPE_Float64Slice
+	case "[]int64":
+		return DATATYPE_Int64Slice
 	case "net.IP":
 		return DATATYPE_IP
 	default:
@@ -400,6 +403,8 @@ func GetValueByOffsetAndKind(ptr, offset uintptr, kind reflect.Kind, dataType Da
 			return *(*[]string)(fieldAddr)
 		case DATATYPE_Float64Slice:
 			return *(*[]float64)(fieldAddr)
+		case DATATYPE_Int64Slice:
+			return *(*[]int64)(fieldAddr)
 		default:
 			return nil
 		}
@@ -27,9 +27,15 @@ const (
 const (
 	DATABASE_FLOW_LOG = "flow_log"
 	TABLE_L7_FLOW_LOG = "l7_flow_log"
+	TABLE_TRACE_TREE  = "trace_tree"
 	TAG_TRACE_ID      = "trace_id"
 )
 
+const (
+	NODE_TYPE_IP       = "ip"
+	NODE_TYPE_INTERNET = "internet"
+)
+
 const (
 	HEADER_KEY_X_ORG_ID = "X-Org-Id"
 )
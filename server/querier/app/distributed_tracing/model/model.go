@@ -27,3 +27,8 @@ type TraceMap struct {
 	Context        context.Context
 	OrgID          string
 }
+
+type TraceMapResult struct {
+	TraceCount int           `json:"trace_count"`
+	Edges      []RawTraceMap `json:"edges"`
+}
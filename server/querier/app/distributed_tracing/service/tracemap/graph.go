@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package tracemap
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/khulnasoft/deepflow/server/libs/tracetree"
+	"github.com/khulnasoft/deepflow/server/libs/utils"
+	"github.com/khulnasoft/deepflow/server/querier/app/distributed_tracing/model"
+)
+
+const (
+	AUTO_SERVICE_TYPE_INTERNET = 0
+	AUTO_SERVICE_TYPE_IP       = 255
+)
+
+// serviceNode identifies one vertex of the trace map
+type serviceNode struct {
+	AutoServiceType uint8
+	AutoServiceID   uint32
+	AppService      string
+	// only used when AutoServiceType is internet or ip, otherwise the service id is enough
+	IP string
+}
+
+func (n serviceNode) isIP() bool {
+	return n.AutoServiceType == AUTO_SERVICE_TYPE_INTERNET || n.AutoServiceType == AUTO_SERVICE_TYPE_IP
+}
+
+type serviceEdgeKey struct {
+	Client serviceNode
+	Server serviceNode
+}
+
+type serviceEdge struct {
+	ResponseTotal                  uint64
+	ResponseStatusServerErrorCount uint64
+	ResponseDurationSum            uint64
+}
+
+// serviceGraph merges the nodes of many trace trees into service-to-service edges
+type serviceGraph struct {
+	edges      map[serviceEdgeKey]*serviceEdge
+	traceCount int
+}
+
+func newServiceGraph() *serviceGraph {
+	return &serviceGraph{edges: make(map[serviceEdgeKey]*serviceEdge)}
+}
+
+func ipString(isIPv4 bool, ip4 uint32, ip6 net.IP) string {
+	if isIPv4 {
+		return utils.IpFromUint32(ip4).String()
+	}
+	return ip6.String()
+}
+
+func nodeFromNodeInfo(info *tracetree.NodeInfo) serviceNode {
+	n := serviceNode{
+		AutoServiceType: info.AutoServiceType,
+		AutoServiceID:   info.AutoServiceID,
+		AppService:      info.AppService,
+	}
+	if n.isIP() {
+		n.IP = ipString(info.IsIPv4, info.IP4, info.IP6)
+	}
+	return n
+}
+
+func clientNodeFromSpanInfo(info *tracetree.SpanInfo) serviceNode {
+	n := serviceNode{
+		AutoServiceType: info.AutoServiceType0,
+		AutoServiceID:   info.AutoServiceID0,
+		AppService:      info.AppService0,
+	}
+	if n.isIP() {
+		n.IP = ipString(info.IsIPv4, info.IP40, info.IP60)
+	}
+	return n
+}
+
+// AddTraceTree adds all calls of a trace tree to the graph, each node holds the calls of one client and server pair.
+// A node with a parent is called by the service of its parent node. A root node is called by the client side
+// of its own spans, which usually is an ip or internet node outside the trace.
+func (g *serviceGraph) AddTraceTree(t *tracetree.TraceTree) {
+	g.traceCount++
+	for i := range t.TreeNodes {
+		node := &t.TreeNodes[i]
+		server := nodeFromNodeInfo(&node.NodeInfo)
+		if node.ParentNodeIndex >= 0 && int(node.ParentNodeIndex) < len(t.TreeNodes) {
+			client := nodeFromNodeInfo(&t.TreeNodes[node.ParentNodeIndex].NodeInfo)
+			g.addEdge(client, server, node)
+			continue
+		}
+		if len(node.UniqParentSpanInfos) == 0 {
+			continue
+		}
+		if client := clientNodeFromSpanInfo(&node.UniqParentSpanInfos[0]); client != server {
+			g.addEdge(client, server, node)
+		}
+	}
+}
+
+func (g *serviceGraph) addEdge(client, server serviceNode, node *tracetree.TreeNode) {
+	key := serviceEdgeKey{Client: client, Server: server}
+	edge, ok := g.edges[key]
+	if !ok {
+		edge = &serviceEdge{}
+		g.edges[key] = edge
+	}
+	edge.ResponseTotal += uint64(node.ResponseTotal)
+	edge.ResponseStatusServerErrorCount += uint64(node.ResponseStatusServerErrorCount)
+	edge.ResponseDurationSum += node.ResponseDurationSum
+}
+
+// Nodes returns all non-ip nodes which need their names to be resolved
+func (g *serviceGraph) Nodes() []serviceNode {
+	seen := make(map[serviceNode]struct{})
+	nodes := []serviceNode{}
+	for key := range g.edges {
+		for _, n := range []serviceNode{key.Client, key.Server} {
+			if n.isIP() {
+				continue
+			}
+			n.AppService = ""
+			if _, ok := seen[n]; ok {
+				continue
+			}
+			seen[n] = struct{}{}
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+type nodeDetail struct {
+	Name     string
+	IconID   int
+	NodeType string
+}
+
+func nodeUID(n serviceNode, name string) string {
+	// encoding: auto_service_type+auto_service_id+auto_service+app_service+layer
+	return fmt.Sprintf("%d-%d-%s-%s-%d", n.AutoServiceType, n.AutoServiceID, name, n.AppService, 0)
+}
+
+// Result converts the graph to the response, details are indexed by nodes with an empty app_service
+func (g *serviceGraph) Result(details map[serviceNode]*nodeDetail, ipNodeType, internetNodeType string) *model.TraceMapResult {
+	describe := func(n serviceNode) (name string, iconID int, nodeType string) {
+		switch n.AutoServiceType {
+		case AUTO_SERVICE_TYPE_INTERNET:
+			return n.IP, 0, internetNodeType
+		case AUTO_SERVICE_TYPE_IP:
+			return n.IP, 0, ipNodeType
+		}
+		n.AppService = ""
+		if d, ok := details[n]; ok {
+			return d.Name, d.IconID, d.NodeType
+		}
+		return "", 0, ""
+	}
+
+	result := &model.TraceMapResult{
+		TraceCount: g.traceCount,
+		Edges:      make([]model.RawTraceMap, 0, len(g.edges)),
+	}
+	for key, edge := range g.edges {
+		clientName, clientIconID, clientNodeType := describe(key.Client)
+		serverName, serverIconID, serverNodeType := describe(key.Server)
+		result.Edges = append(result.Edges, model.RawTraceMap{
+			AutoServiceId0:                 uint(key.Client.AutoServiceID),
+			AutoServiceId1:                 uint(key.Server.AutoServiceID),
+			AutoServiceType0:               uint(key.Client.AutoServiceType),
+			AutoServiceType1:               uint(key.Server.AutoServiceType),
+			ResponseTotal:                  uint(edge.ResponseTotal),
+			ResponseStatusServerErrorCount: uint(edge.ResponseStatusServerErrorCount),
+			ClientIconId:                   clientIconID,
+			ServerIconId:                   serverIconID,
+			ResponseDurationSum:            edge.ResponseDurationSum,
+			AutoService0:                   clientName,
+			AutoService1:                   serverName,
+			Uid0:                           nodeUID(key.Client, clientName),
+			Uid1:                           nodeUID(key.Server, serverName),
+			IP0:                            key.Client.IP,
+			IP1:                            key.Server.IP,
+			AppService0:                    key.Client.AppService,
+			AppService1:                    key.Server.AppService,
+			ClientNodeType:                 clientNodeType,
+			ServerNodeType:                 serverNodeType,
+		})
+	}
+	// keep the output stable, the busiest edges first
+	sort.Slice(result.Edges, func(i, j int) bool {
+		if result.Edges[i].ResponseTotal != result.Edges[j].ResponseTotal {
+			return result.Edges[i].ResponseTotal > result.Edges[j].ResponseTotal
+		}
+		if result.Edges[i].Uid0 != result.Edges[j].Uid0 {
+			return result.Edges[i].Uid0 < result.Edges[j].Uid0
+		}
+		return result.Edges[i].Uid1 < result.Edges[j].Uid1
+	})
+	return result
+}
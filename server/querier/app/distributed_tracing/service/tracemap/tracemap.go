@@ -20,9 +20,16 @@ import (
 
 	"github.com/khulnasoft/deepflow/server/querier/app/distributed_tracing/model"
 	"github.com/khulnasoft/deepflow/server/querier/config"
+	"github.com/khulnasoft/deepflow/server/querier/router"
 )
 
 func TraceMap(args model.TraceMap, cfg *config.QuerierConfig, c *gin.Context, done chan bool, generator *TraceMapGenerator) {
-	done <- true
-	return
+	defer func() {
+		done <- true
+	}()
+	result, debug, err := generator.Generate(&args)
+	if err == nil && !args.Debug {
+		debug = nil
+	}
+	router.JsonResponse(c, result, debug, err)
 }
@@ -16,8 +16,32 @@
 package tracemap
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	logging "github.com/op/go-logging"
+
+	"github.com/khulnasoft/deepflow/server/libs/ckdb"
+	"github.com/khulnasoft/deepflow/server/libs/codec"
+	"github.com/khulnasoft/deepflow/server/libs/grpc"
 	"github.com/khulnasoft/deepflow/server/libs/queue"
+	"github.com/khulnasoft/deepflow/server/libs/tracetree"
+	"github.com/khulnasoft/deepflow/server/querier/app/distributed_tracing/common"
+	"github.com/khulnasoft/deepflow/server/querier/app/distributed_tracing/model"
+	querier_common "github.com/khulnasoft/deepflow/server/querier/common"
 	"github.com/khulnasoft/deepflow/server/querier/config"
+	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse"
+	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/client"
+)
+
+var log = logging.MustGetLogger("tracemap")
+
+const (
+	TABLE_SPAN_WITH_TRACE_ID = "span_with_trace_id"
+	DATABASE_FLOW_TAG        = "flow_tag"
 )
 
 type TraceMapGenerator struct {
@@ -29,6 +53,293 @@ func NewTraceMapGenerator(sharedQueue *queue.OverwriteQueue, cfg *config.Querier
 	return &TraceMapGenerator{sharedQueue: sharedQueue, cfg: cfg}
 }
 
+// Start periodically builds trace trees from `flow_log.span_with_trace_id` and hands them to the
+// ingester through the shared queue, where they are written to `flow_log.trace_tree`
 func (g *TraceMapGenerator) Start() {
+	if g.sharedQueue == nil || g.cfg.Tracemap.WriteInterval <= 0 {
+		log.Info("trace tree generator is disabled")
+		return
+	}
+	go g.run()
+}
+
+func (g *TraceMapGenerator) run() {
+	interval := time.Duration(g.cfg.Tracemap.WriteInterval) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		// spans arrive late, only generate trees for traces which are old enough
+		end := time.Now().Add(-time.Duration(g.cfg.Tracemap.TraceQueryDelta) * time.Second).Unix()
+		start := end - int64(g.cfg.Tracemap.WriteInterval)
+		for _, orgID := range grpc.QueryAllOrgIDs() {
+			if err := g.generateTraceTrees(orgID, start, end); err != nil {
+				log.Warningf("org(%d) generate trace trees failed: %s", orgID, err)
+			}
+		}
+	}
+}
+
+func orgDatabase(orgID uint16, db string) string {
+	return ckdb.OrgDatabasePrefix(orgID) + db
+}
+
+func newClient(ctx context.Context) *client.Client {
+	return &client.Client{
+		Host:     config.Cfg.Clickhouse.Host,
+		Port:     config.Cfg.Clickhouse.Port,
+		UserName: config.Cfg.Clickhouse.User,
+		Password: config.Cfg.Clickhouse.Password,
+		DB:       common.DATABASE_FLOW_LOG,
+		Context:  ctx,
+	}
+}
+
+func queryStrings(values []interface{}, index int) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		row, ok := value.([]interface{})
+		if !ok || len(row) <= index {
+			continue
+		}
+		if s, ok := row[index].(string); ok && s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func searchIndexFilter(traceIDs []string) string {
+	indices := make([]string, 0, len(traceIDs))
+	quoted := make([]string, 0, len(traceIDs))
+	for _, traceID := range traceIDs {
+		indices = append(indices, strconv.FormatUint(tracetree.HashSearchIndex(traceID), 10))
+		quoted = append(quoted, "'"+strings.ReplaceAll(traceID, "'", "\\'")+"'")
+	}
+	return fmt.Sprintf("search_index IN (%s) AND trace_id IN (%s)", strings.Join(indices, ","), strings.Join(quoted, ","))
+}
+
+func batches(traceIDs []string, size int) [][]string {
+	if size <= 0 {
+		size = len(traceIDs)
+	}
+	result := [][]string{}
+	for start := 0; start < len(traceIDs); start += size {
+		end := start + size
+		if end > len(traceIDs) {
+			end = len(traceIDs)
+		}
+		result = append(result, traceIDs[start:end])
+	}
+	return result
+}
+
+func (g *TraceMapGenerator) generateTraceTrees(orgID uint16, start, end int64) error {
+	db := orgDatabase(orgID, common.DATABASE_FLOW_LOG)
+	chClient := newClient(context.Background())
+	sql := fmt.Sprintf("SELECT trace_id FROM %s.`%s` WHERE time>=%d AND time<%d GROUP BY trace_id LIMIT %d",
+		db, TABLE_SPAN_WITH_TRACE_ID, start, end, g.cfg.Tracemap.MaxTracePerIteration)
+	result, err := chClient.DoQuery(&client.QueryParams{Sql: sql, SimpleSql: true})
+	if err != nil {
+		return err
+	}
+	traceIDs := queryStrings(result.Values, 0)
+
+	delta := int64(g.cfg.Tracemap.TraceQueryDelta)
+	items := make([]interface{}, 0, g.cfg.Tracemap.WriteBatchSize)
+	for _, batch := range batches(traceIDs, int(g.cfg.Tracemap.BatchTracesCountMax)) {
+		sql := fmt.Sprintf("SELECT toUnixTimestamp(time), trace_id, encoded_span FROM %s.`%s` WHERE time>=%d AND time<=%d AND %s",
+			db, TABLE_SPAN_WITH_TRACE_ID, start-delta, end+delta, searchIndexFilter(batch))
+		result, err := chClient.DoQuery(&client.QueryParams{Sql: sql, SimpleSql: true})
+		if err != nil {
+			return err
+		}
+		spans := make(map[string][]*tracetree.SpanTrace, len(batch))
+		decoder := &codec.SimpleDecoder{}
+		for _, value := range result.Values {
+			row := value.([]interface{})
+			traceID, _ := row[1].(string)
+			encoded, _ := row[2].(string)
+			span := tracetree.AcquireSpanTrace()
+			decoder.Init([]byte(encoded))
+			if err := span.Decode(decoder); err != nil {
+				log.Debugf("trace(%s) decode span failed: %s", traceID, err)
+				tracetree.ReleaseSpanTrace(span)
+				continue
+			}
+			span.Time = toUint32(row[0])
+			spans[traceID] = append(spans[traceID], span)
+		}
+		for traceID, traceSpans := range spans {
+			if tree := buildTraceTree(traceID, orgID, traceSpans); tree != nil {
+				items = append(items, tree)
+			}
+			for _, span := range traceSpans {
+				tracetree.ReleaseSpanTrace(span)
+			}
+			if len(items) >= g.cfg.Tracemap.WriteBatchSize {
+				g.sharedQueue.Put(items...)
+				items = items[:0]
+			}
+		}
+	}
+	if len(items) > 0 {
+		g.sharedQueue.Put(items...)
+	}
+	log.Debugf("org(%d) generated %d trace trees in [%d, %d)", orgID, len(traceIDs), start, end)
+	return nil
+}
+
+func toUint32(value interface{}) uint32 {
+	switch v := value.(type) {
+	case uint8:
+		return uint32(v)
+	case uint16:
+		return uint32(v)
+	case uint32:
+		return v
+	case uint64:
+		return uint32(v)
+	case int8:
+		return uint32(v)
+	case int16:
+		return uint32(v)
+	case int32:
+		return uint32(v)
+	case int64:
+		return uint32(v)
+	case int:
+		return uint32(v)
+	}
+	return 0
+}
+
+// queryTraceIDs returns the trace ids of the l7_flow_log matching the query condition
+func (g *TraceMapGenerator) queryTraceIDs(args *model.TraceMap, debugs *[]interface{}) ([]string, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE time>=%d AND time<=%d AND %s!='' AND (%s) GROUP BY %s LIMIT %d",
+		common.TAG_TRACE_ID, common.TABLE_L7_FLOW_LOG, args.TimeStart, args.TimeEnd, common.TAG_TRACE_ID,
+		args.QueryCondition, common.TAG_TRACE_ID, g.cfg.Tracemap.MaxTracePerIteration)
+	ckEngine := &clickhouse.CHEngine{DB: common.DATABASE_FLOW_LOG}
+	ckEngine.Init()
+	result, debug, err := ckEngine.ExecuteQuery(&querier_common.QuerierParams{
+		DB:      common.DATABASE_FLOW_LOG,
+		Sql:     sql,
+		Debug:   strconv.FormatBool(args.Debug),
+		Context: args.Context,
+		ORGID:   args.OrgID,
+	})
+	*debugs = append(*debugs, debug)
+	if err != nil {
+		return nil, err
+	}
+	return queryStrings(result.Values, 0), nil
+}
+
+// queryTraceTrees reads the newest trace tree of each trace, the trees are filtered by trace ids when given
+func (g *TraceMapGenerator) queryTraceTrees(args *model.TraceMap, db string, traceIDs []string, graph *serviceGraph, debugs *[]interface{}) error {
+	delta := int(g.cfg.Tracemap.TraceQueryDelta)
+	conditions := []string{fmt.Sprintf("time>=%d AND time<=%d", args.TimeStart-delta, args.TimeEnd+delta)}
+	if traceIDs != nil {
+		if len(traceIDs) == 0 {
+			return nil
+		}
+		conditions = append(conditions, searchIndexFilter(traceIDs))
+	}
+	sql := fmt.Sprintf("SELECT trace_id, encoded_span_list FROM %s.`%s` WHERE %s ORDER BY time DESC LIMIT 1 BY trace_id LIMIT %d",
+		db, common.TABLE_TRACE_TREE, strings.Join(conditions, " AND "), g.cfg.Tracemap.MaxTracePerIteration)
+	chClient := newClient(args.Context)
+	chClient.Debug = client.NewDebug(sql)
+	result, err := chClient.DoQuery(&client.QueryParams{Sql: sql, SimpleSql: true})
+	*debugs = append(*debugs, *chClient.Debug)
+	if err != nil {
+		return err
+	}
+	decoder := &codec.SimpleDecoder{}
+	tree := &tracetree.TraceTree{}
+	for _, value := range result.Values {
+		row := value.([]interface{})
+		encoded, _ := row[1].(string)
+		decoder.Init([]byte(encoded))
+		if err := tree.Decode(decoder); err != nil {
+			log.Debugf("trace(%v) decode trace tree failed: %s", row[0], err)
+			continue
+		}
+		graph.AddTraceTree(tree)
+	}
+	return nil
+}
+
+// queryNodeDetails resolves the names, icons and node types of the services in the graph
+func (g *TraceMapGenerator) queryNodeDetails(args *model.TraceMap, nodes []serviceNode, debugs *[]interface{}) (map[serviceNode]*nodeDetail, error) {
+	details := make(map[serviceNode]*nodeDetail, len(nodes))
+	if len(nodes) == 0 {
+		return details, nil
+	}
+	keys := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		keys = append(keys, fmt.Sprintf("(%d,%d)", n.AutoServiceType, n.AutoServiceID))
+	}
+	sql := fmt.Sprintf("SELECT devicetype, deviceid, name, icon_id, dictGet('%s.node_type_map', 'node_type', toUInt64(devicetype)) AS node_type FROM %s.device_map WHERE (devicetype, deviceid) IN (%s)",
+		DATABASE_FLOW_TAG, DATABASE_FLOW_TAG, strings.Join(keys, ","))
+	chClient := newClient(args.Context)
+	chClient.DB = DATABASE_FLOW_TAG
+	chClient.Debug = client.NewDebug(sql)
+	// the client replaces `flow_tag` with the database of the organization
+	result, err := chClient.DoQuery(&client.QueryParams{Sql: sql, ORGID: args.OrgID})
+	*debugs = append(*debugs, *chClient.Debug)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range result.Values {
+		row := value.([]interface{})
+		n := serviceNode{AutoServiceType: uint8(toUint32(row[0])), AutoServiceID: toUint32(row[1])}
+		d := &nodeDetail{}
+		d.Name, _ = row[2].(string)
+		d.IconID = int(toUint32(row[3]))
+		d.NodeType, _ = row[4].(string)
+		details[n] = d
+	}
+	return details, nil
+}
+
+// Generate merges the trace trees in the time range into a service-to-service call graph
+func (g *TraceMapGenerator) Generate(args *model.TraceMap) (*model.TraceMapResult, interface{}, error) {
+	debugs := []interface{}{}
+	orgID := uint16(ckdb.DEFAULT_ORG_ID)
+	if args.OrgID != "" {
+		id, err := strconv.Atoi(args.OrgID)
+		if err != nil {
+			return nil, debugs, querier_common.NewError(querier_common.INVALID_POST_DATA, fmt.Sprintf("invalid org id %s", args.OrgID))
+		}
+		orgID = uint16(id)
+	}
+	if args.TimeStart > args.TimeEnd {
+		return nil, debugs, querier_common.NewError(querier_common.INVALID_POST_DATA, "time_start is greater than time_end")
+	}
+
+	var traceIDs []string
+	if args.QueryCondition != "" {
+		var err error
+		if traceIDs, err = g.queryTraceIDs(args, &debugs); err != nil {
+			return nil, debugs, err
+		}
+	}
+
+	graph := newServiceGraph()
+	db := orgDatabase(orgID, common.DATABASE_FLOW_LOG)
+	for _, batch := range batches(traceIDs, int(g.cfg.Tracemap.BatchTracesCountMax)) {
+		if err := g.queryTraceTrees(args, db, batch, graph, &debugs); err != nil {
+			return nil, debugs, err
+		}
+	}
+	if traceIDs == nil {
+		if err := g.queryTraceTrees(args, db, nil, graph, &debugs); err != nil {
+			return nil, debugs, err
+		}
+	}
 
+	details, err := g.queryNodeDetails(args, graph.Nodes(), &debugs)
+	if err != nil {
+		return nil, debugs, err
+	}
+	return graph.Result(details, common.NODE_TYPE_IP, common.NODE_TYPE_INTERNET), debugs, nil
 }
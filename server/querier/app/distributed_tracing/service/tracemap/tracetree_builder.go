@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package tracemap
+
+import (
+	"sort"
+
+	"github.com/khulnasoft/deepflow/server/libs/datatype"
+	"github.com/khulnasoft/deepflow/server/libs/tracetree"
+)
+
+type spanSideKey struct {
+	AutoServiceType uint8
+	AutoServiceID   uint32
+	IP              string
+}
+
+// serviceKey identifies the service on the server side of spans
+type serviceKey struct {
+	spanSideKey
+	AppService string
+}
+
+// edgeKey identifies a tree node, each node holds the calls from one client service to one server service
+type edgeKey struct {
+	Client serviceKey
+	Server serviceKey
+}
+
+type spanInfoKey struct {
+	Client     spanSideKey
+	Server     spanSideKey
+	AppService string
+}
+
+type callKey struct {
+	Client     spanSideKey
+	Server     spanSideKey
+	ReqTcpSeq  uint32
+	RespTcpSeq uint32
+}
+
+func serverSideKey(s *tracetree.SpanTrace) spanSideKey {
+	k := spanSideKey{AutoServiceType: s.AutoServiceType1, AutoServiceID: s.AutoServiceID1}
+	if k.AutoServiceType == AUTO_SERVICE_TYPE_INTERNET || k.AutoServiceType == AUTO_SERVICE_TYPE_IP {
+		k.IP = ipString(s.IsIPv4, s.IP41, s.IP61)
+	}
+	return k
+}
+
+func clientSideKey(s *tracetree.SpanTrace) spanSideKey {
+	k := spanSideKey{AutoServiceType: s.AutoServiceType0, AutoServiceID: s.AutoServiceID0}
+	if k.AutoServiceType == AUTO_SERVICE_TYPE_INTERNET || k.AutoServiceType == AUTO_SERVICE_TYPE_IP {
+		k.IP = ipString(s.IsIPv4, s.IP40, s.IP60)
+	}
+	return k
+}
+
+// buildTraceTree merges the spans of one trace into nodes of client and server service pairs.
+// Every span is a call from its client side to its server side. The client service is the service of
+// the parent span, otherwise the service whose server side is the client side of the span, otherwise
+// the client side itself. The same call captured at several observation points shares tcp sequences
+// and is counted only once.
+func buildTraceTree(traceID string, orgID uint16, spans []*tracetree.SpanTrace) *tracetree.TraceTree {
+	if len(spans) == 0 {
+		return nil
+	}
+	// process spans in time order so that callers are seen before callees as far as possible
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].Time != spans[j].Time {
+			return spans[i].Time < spans[j].Time
+		}
+		return spans[i].EndTimeUsPart < spans[j].EndTimeUsPart
+	})
+
+	t := tracetree.AcquireTraceTree()
+	t.Time = spans[len(spans)-1].Time
+	t.OrgId = orgID
+	t.TraceId = traceID
+	t.SearchIndex = tracetree.HashSearchIndex(traceID)
+	t.TreeNodes = t.TreeNodes[:0]
+
+	// the services of all spans should be known before looking up the client services
+	spanServices := make(map[string]serviceKey)
+	sideServices := make(map[spanSideKey]serviceKey)
+	for _, s := range spans {
+		server := serviceKey{spanSideKey: serverSideKey(s), AppService: s.AppService}
+		if s.SpanId != "" {
+			spanServices[s.SpanId] = server
+		}
+		if _, ok := sideServices[server.spanSideKey]; !ok {
+			sideServices[server.spanSideKey] = server
+		}
+	}
+
+	nodeIndices := make(map[edgeKey]int)
+	nodeClients := make([]serviceKey, 0)
+	serviceNodes := make(map[serviceKey]int) // the first node of each server service
+	countedCalls := make(map[callKey]struct{})
+	spanInfos := make([]map[spanInfoKey]struct{}, 0)
+
+	for _, s := range spans {
+		server := serviceKey{spanSideKey: serverSideKey(s), AppService: s.AppService}
+		key := edgeKey{Client: clientService(s, server, spanServices, sideServices), Server: server}
+		index, ok := nodeIndices[key]
+		if !ok {
+			index = len(t.TreeNodes)
+			t.TreeNodes = append(t.TreeNodes, tracetree.TreeNode{
+				ParentNodeIndex: -1,
+				NodeInfo: tracetree.NodeInfo{
+					AutoServiceType: s.AutoServiceType1,
+					AutoServiceID:   s.AutoServiceID1,
+					AppService:      s.AppService,
+					IsIPv4:          s.IsIPv4,
+					IP4:             s.IP41,
+					IP6:             s.IP61,
+				},
+				QuerierRegion: s.QuerierRegion,
+			})
+			spanInfos = append(spanInfos, make(map[spanInfoKey]struct{}))
+			nodeIndices[key] = index
+			nodeClients = append(nodeClients, key.Client)
+			if _, ok := serviceNodes[server]; !ok {
+				serviceNodes[server] = index
+			}
+		}
+		node := &t.TreeNodes[index]
+		if s.Topic != "" {
+			node.Topic = s.Topic
+		}
+
+		infoKey := spanInfoKey{Client: clientSideKey(s), Server: server.spanSideKey, AppService: s.AppService}
+		if _, ok := spanInfos[index][infoKey]; !ok {
+			spanInfos[index][infoKey] = struct{}{}
+			node.UniqParentSpanInfos = append(node.UniqParentSpanInfos, tracetree.SpanInfo{
+				AutoServiceType0: s.AutoServiceType0,
+				AutoServiceType1: s.AutoServiceType1,
+				AutoServiceID0:   s.AutoServiceID0,
+				AutoServiceID1:   s.AutoServiceID1,
+				AppService0:      key.Client.AppService,
+				AppService1:      s.AppService,
+				IsIPv4:           s.IsIPv4,
+				IP40:             s.IP40,
+				IP60:             s.IP60,
+				IP41:             s.IP41,
+				IP61:             s.IP61,
+			})
+		}
+
+		call := callKey{Client: clientSideKey(s), Server: server.spanSideKey, ReqTcpSeq: s.ReqTcpSeq, RespTcpSeq: s.RespTcpSeq}
+		if s.ReqTcpSeq != 0 || s.RespTcpSeq != 0 {
+			if _, ok := countedCalls[call]; ok {
+				continue
+			}
+			countedCalls[call] = struct{}{}
+		}
+		node.ResponseTotal++
+		node.ResponseDurationSum += s.ResponseDuration
+		if s.ResponseStatus == uint8(datatype.STATUS_SERVER_ERROR) {
+			node.ResponseStatusServerErrorCount++
+		}
+	}
+
+	// link each node to a node of its client service
+	for index, client := range nodeClients {
+		parent, ok := serviceNodes[client]
+		if ok && parent != index && !isAncestor(t.TreeNodes, index, parent) {
+			t.TreeNodes[index].ParentNodeIndex = int32(parent)
+		}
+	}
+	return t
+}
+
+// clientService returns the service calling the server of span s
+func clientService(s *tracetree.SpanTrace, server serviceKey, spanServices map[string]serviceKey, sideServices map[spanSideKey]serviceKey) serviceKey {
+	if s.ParentSpanId != "" {
+		if parent, ok := spanServices[s.ParentSpanId]; ok && parent != server {
+			return parent
+		}
+	}
+	client := clientSideKey(s)
+	if service, ok := sideServices[client]; ok && service != server {
+		return service
+	}
+	return serviceKey{spanSideKey: client}
+}
+
+// isAncestor checks whether node is an ancestor of target, used to avoid linking a loop
+func isAncestor(nodes []tracetree.TreeNode, node, target int) bool {
+	for i := 0; target >= 0 && i < len(nodes); i++ {
+		if target == node {
+			return true
+		}
+		target = int(nodes[target].ParentNodeIndex)
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracemap
+
+import (
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/libs/datatype"
+	"github.com/khulnasoft/deepflow/server/libs/tracetree"
+)
+
+const testServiceType = 11
+
+// newSpan returns a span from client service to server service, client 0 means the client is an ip
+func newSpan(client, server uint32, spanID, parentSpanID string, reqTcpSeq uint32, status datatype.LogMessageStatus) *tracetree.SpanTrace {
+	s := &tracetree.SpanTrace{
+		AutoServiceType0: testServiceType,
+		AutoServiceID0:   client,
+		AutoServiceType1: testServiceType,
+		AutoServiceID1:   server,
+		IsIPv4:           true,
+		SpanId:           spanID,
+		ParentSpanId:     parentSpanID,
+		ReqTcpSeq:        reqTcpSeq,
+		ResponseDuration: 10,
+		ResponseStatus:   uint8(status),
+	}
+	if client == 0 {
+		s.AutoServiceType0 = AUTO_SERVICE_TYPE_IP
+		s.IP40 = 0x0a000001
+	}
+	return s
+}
+
+func serviceOf(id uint32) serviceNode {
+	return serviceNode{AutoServiceType: testServiceType, AutoServiceID: id}
+}
+
+func testGraph(spans []*tracetree.SpanTrace) map[serviceEdgeKey]serviceEdge {
+	g := newServiceGraph()
+	g.AddTraceTree(buildTraceTree("trace", 1, spans))
+	edges := make(map[serviceEdgeKey]serviceEdge, len(g.edges))
+	for k, v := range g.edges {
+		edges[k] = *v
+	}
+	return edges
+}
+
+func TestTraceTreeFanIn(t *testing.T) {
+	ip := serviceNode{AutoServiceType: AUTO_SERVICE_TYPE_IP, IP: "10.0.0.1"}
+	spans := []*tracetree.SpanTrace{
+		newSpan(0, 1, "a", "", 1, datatype.STATUS_OK),
+		newSpan(0, 2, "b", "", 2, datatype.STATUS_OK),
+		// service 3 is called by service 1 twice and by service 2 once
+		newSpan(1, 3, "c1", "a", 3, datatype.STATUS_OK),
+		newSpan(1, 3, "c2", "a", 4, datatype.STATUS_SERVER_ERROR),
+		newSpan(2, 3, "c3", "b", 5, datatype.STATUS_OK),
+		// the same call captured at another observation point
+		newSpan(2, 3, "c4", "b", 5, datatype.STATUS_OK),
+	}
+	want := map[serviceEdgeKey]serviceEdge{
+		{Client: ip, Server: serviceOf(1)}:           {ResponseTotal: 1, ResponseDurationSum: 10},
+		{Client: ip, Server: serviceOf(2)}:           {ResponseTotal: 1, ResponseDurationSum: 10},
+		{Client: serviceOf(1), Server: serviceOf(3)}: {ResponseTotal: 2, ResponseStatusServerErrorCount: 1, ResponseDurationSum: 20},
+		{Client: serviceOf(2), Server: serviceOf(3)}: {ResponseTotal: 1, ResponseDurationSum: 10},
+	}
+	checkEdges(t, testGraph(spans), want)
+}
+
+func TestTraceTreeFanOut(t *testing.T) {
+	ip := serviceNode{AutoServiceType: AUTO_SERVICE_TYPE_IP, IP: "10.0.0.1"}
+	spans := []*tracetree.SpanTrace{
+		newSpan(0, 1, "a", "", 1, datatype.STATUS_OK),
+		// service 1 calls service 2 and service 3, service 2 calls service 3
+		newSpan(1, 2, "b", "a", 2, datatype.STATUS_OK),
+		newSpan(1, 3, "c1", "a", 3, datatype.STATUS_SERVER_ERROR),
+		newSpan(2, 3, "c2", "b", 4, datatype.STATUS_OK),
+		// no parent span, the client is found by the client side
+		newSpan(2, 3, "c3", "", 5, datatype.STATUS_OK),
+	}
+	want := map[serviceEdgeKey]serviceEdge{
+		{Client: ip, Server: serviceOf(1)}:           {ResponseTotal: 1, ResponseDurationSum: 10},
+		{Client: serviceOf(1), Server: serviceOf(2)}: {ResponseTotal: 1, ResponseDurationSum: 10},
+		{Client: serviceOf(1), Server: serviceOf(3)}: {ResponseTotal: 1, ResponseStatusServerErrorCount: 1, ResponseDurationSum: 10},
+		{Client: serviceOf(2), Server: serviceOf(3)}: {ResponseTotal: 2, ResponseDurationSum: 20},
+	}
+	checkEdges(t, testGraph(spans), want)
+}
+
+func checkEdges(t *testing.T, got, want map[serviceEdgeKey]serviceEdge) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("got %d edges %v, want %d edges", len(got), got, len(want))
+	}
+	for k, w := range want {
+		if g, ok := got[k]; !ok || g != w {
+			t.Errorf("edge %+v -> %+v = %+v, want %+v", k.Client, k.Server, g, w)
+		}
+	}
+}
@@ -28,6 +28,7 @@ type Prometheus struct {
 	ThanosReplicaLabels     []string        `yaml:"thanos-replica-labels"`
 	OperatorOffloading      bool            `default:"false" yaml:"operator-offloading"`
 	Cache                   PrometheusCache `yaml:"cache"`
+	Rules                   PrometheusRules `yaml:"rules"`
 }
 
 type PrometheusCache struct {
@@ -39,3 +40,13 @@ type PrometheusCache struct {
 	CacheCleanInterval int    `default:"3600" yaml:"cache-clean-interval"` // clean interval for cache, unit: s, default: 1h
 	CacheAllowTimeGap  int    `default:"1" yaml:"cache-allow-time-gap"`    // when query end time - cache end time <= allow gap: not update cache, unit: s, default: 1s
 }
+
+type PrometheusRules struct {
+	Enabled            bool     `default:"false" yaml:"enabled"`
+	Files              []string `yaml:"files"`                                      // rule files in prometheus format, glob patterns are supported
+	EvaluationInterval int      `default:"60" yaml:"evaluation-interval"`           // interval for the groups without `interval`, unit: s, default: 60s
+	ReloadInterval     int      `default:"60" yaml:"reload-interval"`               // interval for reloading rule files, unit: s, 0 means never reload
+	OrgID              int      `default:"1" yaml:"org-id"`                         // rules are evaluated with the data of this organization
+	IngesterAddress    string   `default:"127.0.0.1:20033" yaml:"ingester-address"` // alert events and recording results are sent to ingester
+	LeaderOnly         bool     `default:"true" yaml:"leader-only"`                 // rules are evaluated only in the deepflow-server of the leader controller
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql/parser"
 )
 
@@ -55,11 +56,40 @@ type PromMetaParams struct {
 	StartTime   string
 	EndTime     string
 	LabelName   string
+	Matchers    []string
+	Metric      string
+	Limit       int
 	OrgID       string
 	BlockTeamID []string
 	Context     context.Context
 }
 
+type PromMetadata struct {
+	Type string `json:"type"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
+}
+
+type PromBuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	Branch    string `json:"branch"`
+	BuildUser string `json:"buildUser"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+type PromExemplar struct {
+	Labels    labels.Labels `json:"labels"`
+	Value     string        `json:"value"`
+	Timestamp float64       `json:"timestamp"`
+}
+
+type PromExemplarResult struct {
+	SeriesLabels labels.Labels  `json:"seriesLabels"`
+	Exemplars    []PromExemplar `json:"exemplars"`
+}
+
 type PromQueryStats struct {
 	Duration   float64 `json:"duration,omitempty"`
 	SQL        string  `json:"sql,omitempty"`
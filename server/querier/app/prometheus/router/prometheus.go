@@ -162,6 +162,7 @@ func promTagValuesReader(svc *service.PrometheusService) gin.HandlerFunc {
 			LabelName:   c.Param("labelName"),
 			StartTime:   c.Request.FormValue("start"),
 			EndTime:     c.Request.FormValue("end"),
+			Matchers:    c.Request.Form["match[]"],
 			Context:     c.Request.Context(),
 			BlockTeamID: block_team_ids,
 			OrgID:       c.Request.Header.Get(common.HEADER_KEY_X_ORG_ID),
@@ -175,6 +176,99 @@ func promTagValuesReader(svc *service.PrometheusService) gin.HandlerFunc {
 	})
 }
 
+func promTagNamesReader(svc *service.PrometheusService) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		block_team_ids, err := splitStrings(c.Request.FormValue("block-team-id"))
+		if err != nil {
+			code, obj := handleError(err)
+			c.JSON(code, obj)
+			return
+		}
+		args := model.PromMetaParams{
+			StartTime:   c.Request.FormValue("start"),
+			EndTime:     c.Request.FormValue("end"),
+			Matchers:    c.Request.Form["match[]"],
+			Context:     c.Request.Context(),
+			BlockTeamID: block_team_ids,
+			OrgID:       c.Request.Header.Get(common.HEADER_KEY_X_ORG_ID),
+		}
+		result, err := svc.PromLabelNamesService(&args, c.Request.Context())
+		if err != nil {
+			code, obj := handleError(err)
+			c.JSON(code, obj)
+			return
+		}
+		c.JSON(200, result)
+	})
+}
+
+func promMetadataReader(svc *service.PrometheusService) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		args := model.PromMetaParams{
+			Metric:  c.Request.FormValue("metric"),
+			Context: c.Request.Context(),
+			OrgID:   c.Request.Header.Get(common.HEADER_KEY_X_ORG_ID),
+		}
+		// negative limit means no limit
+		err := setRouterArgs(c.Request.FormValue("limit"), &args.Limit, -1, strconv.Atoi)
+		if err != nil {
+			code, obj := handleError(err)
+			c.JSON(code, obj)
+			return
+		}
+		result, err := svc.PromMetadataService(&args, c.Request.Context())
+		if err != nil {
+			code, obj := handleError(err)
+			c.JSON(code, obj)
+			return
+		}
+		c.JSON(200, result)
+	})
+}
+
+func promExemplarsReader(svc *service.PrometheusService) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		args := model.PromQueryParams{
+			Promql:    c.Request.FormValue("query"),
+			StartTime: c.Request.FormValue("start"),
+			EndTime:   c.Request.FormValue("end"),
+			Context:   c.Request.Context(),
+			OrgID:     c.Request.Header.Get(common.HEADER_KEY_X_ORG_ID),
+		}
+		setRouterArgs(c.Request.FormValue("debug"), &args.Debug, config.Cfg.Prometheus.RequestQueryWithDebug, strconv.ParseBool)
+		err := setRouterArgs(c.Request.FormValue("block-team-id"), &args.BlockTeamID, nil, splitStrings)
+		if err != nil {
+			code, obj := handleError(err)
+			c.JSON(code, obj)
+			return
+		}
+		result, err := svc.PromExemplarsQueryService(&args, c.Request.Context())
+		if err != nil {
+			code, obj := handleError(err)
+			c.JSON(code, obj)
+			return
+		}
+		c.JSON(200, result)
+	})
+}
+
+func promBuildInfo(svc *service.PrometheusService) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		c.JSON(200, svc.PromBuildInfoService())
+	})
+}
+
+func promFormatQuery(svc *service.PrometheusService) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		result, err := svc.PromFormatQueryService(c.Request.FormValue("query"))
+		if err != nil {
+			c.JSON(400, &model.PromQueryResponse{Error: err.Error(), Status: _STATUS_FAIL})
+			return
+		}
+		c.JSON(200, result)
+	})
+}
+
 func promSeriesReader(svc *service.PrometheusService) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		args := model.PromQueryParams{
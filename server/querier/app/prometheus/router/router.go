@@ -24,7 +24,8 @@ import (
 	"github.com/khulnasoft/deepflow/server/querier/config"
 )
 
-func PrometheusRouter(e *gin.Engine) {
+// PrometheusRouter registers the prometheus apis, the returned service is shared with the rule evaluator
+func PrometheusRouter(e *gin.Engine) *service.PrometheusService {
 	// only one instance during server lifetime
 	prometheusService := service.NewPrometheusService()
 	// Both SetRate and Acquire are expanded by 1000 times, making it suitable for small QPS scenarios.
@@ -44,6 +45,11 @@ func PrometheusRouter(e *gin.Engine) {
 		promGroup.GET("/api/v1/series", promSeriesReader(prometheusService))
 		promGroup.POST("/api/v1/series", promSeriesReader(prometheusService))
 		promGroup.GET("/api/v1/label/:labelName/values", promTagValuesReader(prometheusService))
+		promGroup.GET("/api/v1/labels", promTagNamesReader(prometheusService))
+		promGroup.POST("/api/v1/labels", promTagNamesReader(prometheusService))
+		promGroup.GET("/api/v1/metadata", promMetadataReader(prometheusService))
+		promGroup.GET("/api/v1/query_exemplars", promExemplarsReader(prometheusService))
+		promGroup.POST("/api/v1/query_exemplars", promExemplarsReader(prometheusService))
 
 		// not use "/prom/api/v1/adapter/:name", suitable for map[rouer key]counter in statsd
 		for _, v := range []string{"label", "query_range", "query", "series"} {
@@ -56,4 +62,8 @@ func PrometheusRouter(e *gin.Engine) {
 	e.GET("/prom/api/v1/analysis", promQLAnalysis(prometheusService))
 	e.GET("/prom/api/v1/parse", promQLParse(prometheusService))
 	e.GET("/prom/api/v1/addfilter", promQLAddFilters(prometheusService))
+	e.GET("/prom/api/v1/status/buildinfo", promBuildInfo(prometheusService))
+	e.GET("/prom/api/v1/format_query", promFormatQuery(prometheusService))
+	e.POST("/prom/api/v1/format_query", promFormatQuery(prometheusService))
+	return prometheusService
 }
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/khulnasoft/deepflow/server/libs/datatype"
+)
+
+const (
+	// the series of alerting rules, the transitions of alerts are saved as alert events instead
+	ALERTS_METRIC_NAME           = "ALERTS"
+	ALERTS_FOR_STATE_METRIC_NAME = "ALERTS_FOR_STATE"
+
+	RECORD_FIELD_NAME = "value"
+	MAX_RECORD_SIZE   = 64 << 10
+)
+
+// recordAppendable saves the results of recording rules to `ext_metrics` as telegraf metrics, the
+// table of a result is `influxdb.${record}`, queried by PromQL as `ext_metrics__metrics__influxdb_${record}__value`
+type recordAppendable struct {
+	sender messageSender
+}
+
+func (a *recordAppendable) Appender(ctx context.Context) storage.Appender {
+	return &recordAppender{sender: a.sender}
+}
+
+type recordAppender struct {
+	sender messageSender
+	lines  []byte
+}
+
+func (a *recordAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	name := l.Get(labels.MetricName)
+	// the stale markers and NaN are not supported by line protocol
+	if name == "" || name == ALERTS_METRIC_NAME || name == ALERTS_FOR_STATE_METRIC_NAME || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, nil
+	}
+	tags := make(models.Tags, 0, len(l)-1)
+	for _, label := range l {
+		if label.Name != labels.MetricName {
+			tags = append(tags, models.NewTag([]byte(label.Name), []byte(label.Value)))
+		}
+	}
+	point, err := models.NewPoint(name, tags, models.Fields{RECORD_FIELD_NAME: v}, time.UnixMilli(t))
+	if err != nil {
+		return 0, err
+	}
+	a.lines = point.AppendString(a.lines)
+	a.lines = append(a.lines, '\n')
+	return 0, nil
+}
+
+func (a *recordAppender) AppendExemplar(ref storage.SeriesRef, l labels.Labels, e exemplar.Exemplar) (storage.SeriesRef, error) {
+	return 0, nil
+}
+
+func (a *recordAppender) Commit() error {
+	if len(a.lines) == 0 {
+		return nil
+	}
+	records := [][]byte{}
+	lines := a.lines
+	for len(lines) > MAX_RECORD_SIZE {
+		// split at the end of a line
+		end := MAX_RECORD_SIZE
+		for end > 0 && lines[end-1] != '\n' {
+			end--
+		}
+		if end == 0 {
+			end = bytes.IndexByte(lines, '\n') + 1
+		}
+		records = append(records, lines[:end])
+		lines = lines[end:]
+	}
+	records = append(records, lines)
+	a.lines = nil
+	return a.sender.Send(datatype.MESSAGE_TYPE_TELEGRAF, records)
+}
+
+func (a *recordAppender) Rollback() error {
+	a.lines = nil
+	return nil
+}
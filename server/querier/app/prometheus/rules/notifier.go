@@ -0,0 +1,151 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/rules"
+
+	"github.com/khulnasoft/deepflow/message/alert_event"
+	"github.com/khulnasoft/deepflow/server/libs/datatype"
+)
+
+// values of enum `policy_app_type` and `event_level`
+const (
+	POLICY_TYPE_CUSTOM = 3
+
+	EVENT_LEVEL_CRITICAL  = 1
+	EVENT_LEVEL_ERROR     = 2
+	EVENT_LEVEL_WARN      = 3
+	EVENT_LEVEL_RECOVERED = 5
+	EVENT_LEVEL_INFO      = 6
+)
+
+const (
+	SEVERITY_LABEL       = "severity"
+	ANNOTATION_PREFIX    = "annotation_"
+	TAG_KEY_ALERT_STATE  = "alert_state"
+	TAG_KEY_RULE_EXPR    = "rule_expr"
+	ALERT_STATE_FIRING   = "firing"
+	ALERT_STATE_RESOLVED = "resolved"
+)
+
+var severityEventLevels = map[string]uint32{
+	"critical": EVENT_LEVEL_CRITICAL,
+	"error":    EVENT_LEVEL_ERROR,
+	"warning":  EVENT_LEVEL_WARN,
+	"warn":     EVENT_LEVEL_WARN,
+	"info":     EVENT_LEVEL_INFO,
+}
+
+// alertNotifier converts the firing and resolved transitions of alerts to alert events,
+// the alerts resent periodically by the rule manager are ignored
+type alertNotifier struct {
+	orgID  uint32
+	sender messageSender
+
+	firing map[string]time.Time // rule expr and alert labels -> ActiveAt of the firing alert
+	lock   sync.Mutex
+}
+
+func newAlertNotifier(orgID uint32, sender messageSender) *alertNotifier {
+	return &alertNotifier{
+		orgID:  orgID,
+		sender: sender,
+		firing: make(map[string]time.Time),
+	}
+}
+
+// Notify is the `rules.NotifyFunc`, it is called by the groups concurrently
+func (n *alertNotifier) Notify(ctx context.Context, expr string, alerts ...*rules.Alert) {
+	records := make([][]byte, 0, len(alerts))
+	n.lock.Lock()
+	for _, alert := range alerts {
+		key := expr + alert.Labels.String()
+		activeAt, ok := n.firing[key]
+		if alert.ResolvedAt.IsZero() {
+			if ok && activeAt.Equal(alert.ActiveAt) {
+				continue
+			}
+			n.firing[key] = alert.ActiveAt
+		} else {
+			// the alerts firing before restarting are not known, ignore their resolving
+			if !ok {
+				continue
+			}
+			delete(n.firing, key)
+		}
+		bytes, err := n.alertEvent(expr, alert).Marshal()
+		if err != nil {
+			log.Warningf("marshal alert event of %s failed: %s", alert.Labels, err)
+			continue
+		}
+		records = append(records, bytes)
+	}
+	n.lock.Unlock()
+
+	if len(records) == 0 {
+		return
+	}
+	if err := n.sender.Send(datatype.MESSAGE_TYPE_ALERT_EVENT, records); err != nil {
+		log.Warningf("send %d alert events failed: %s", len(records), err)
+	}
+}
+
+func (n *alertNotifier) alertEvent(expr string, alert *rules.Alert) *alert_event.AlertEvent {
+	event := &alert_event.AlertEvent{
+		PolicyId:    proto.Uint32(0),
+		PolicyType:  proto.Uint32(POLICY_TYPE_CUSTOM),
+		AlertPolicy: proto.String(alert.Labels.Get(labels.AlertName)),
+		MetricValue: proto.Float64(alert.Value),
+		OrgId:       proto.Uint32(n.orgID),
+	}
+
+	state := ALERT_STATE_FIRING
+	if alert.ResolvedAt.IsZero() {
+		event.Time = proto.Uint32(uint32(alert.FiredAt.Unix()))
+		level, ok := severityEventLevels[strings.ToLower(alert.Labels.Get(SEVERITY_LABEL))]
+		if !ok {
+			level = EVENT_LEVEL_WARN
+		}
+		event.EventLevel = proto.Uint32(level)
+	} else {
+		state = ALERT_STATE_RESOLVED
+		event.Time = proto.Uint32(uint32(alert.ResolvedAt.Unix()))
+		event.EventLevel = proto.Uint32(EVENT_LEVEL_RECOVERED)
+	}
+
+	targetLabels := labels.NewBuilder(alert.Labels).Del(labels.AlertName).Labels()
+	event.TargetTags = proto.String(targetLabels.String())
+	for _, l := range alert.Labels {
+		event.TagStrKeys = append(event.TagStrKeys, l.Name)
+		event.TagStrValues = append(event.TagStrValues, l.Value)
+	}
+	for _, a := range alert.Annotations {
+		event.TagStrKeys = append(event.TagStrKeys, ANNOTATION_PREFIX+a.Name)
+		event.TagStrValues = append(event.TagStrValues, a.Value)
+	}
+	event.TagStrKeys = append(event.TagStrKeys, TAG_KEY_ALERT_STATE, TAG_KEY_RULE_EXPR)
+	event.TagStrValues = append(event.TagStrValues, state, expr)
+	return event
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	logging "github.com/op/go-logging"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/rules"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/khulnasoft/deepflow/server/querier/app/prometheus/config"
+	"github.com/khulnasoft/deepflow/server/querier/app/prometheus/model"
+)
+
+var log = logging.MustGetLogger("prometheus.rules")
+
+const (
+	// the alerts are resent by the rule manager after the delay, they are deduplicated by alertNotifier
+	RESEND_DELAY = time.Minute
+	// the leadership of controller is checked periodically, only the leader evaluates the rules
+	LEADER_CHECK_INTERVAL = 10 * time.Second
+)
+
+// LeaderChecker tells whether this querier runs with the leader controller, it is provided by deepflow-server
+// which runs the controller election
+type LeaderChecker interface {
+	IsLeader() (bool, error)
+}
+
+type QueryService interface {
+	PromInstantQueryService(args *model.PromQueryParams, ctx context.Context) (*model.PromQueryResponse, error)
+}
+
+// RuleEvaluator loads the alerting and recording rules in prometheus format, and evaluates them periodically
+// by the PromQL engine of querier. The transitions of alerts are saved as alert events, and the results of
+// recording rules are saved to ext_metrics, both are sent to ingester.
+// With leader-only, the rules are evaluated only in the deepflow-server whose controller is the leader, so
+// the records and alerts are not duplicated by the replicas of querier. Without a LeaderChecker, e.g. in the
+// standalone querier, leader-only is ignored and the rules are evaluated.
+type RuleEvaluator struct {
+	config       *config.Prometheus
+	queryService QueryService
+	sender       *ingesterSender
+	notifier     *alertNotifier
+	leaderOnly   bool
+	isLeader     func() bool
+
+	// manager is only accessed in the run goroutine, it is nil when the rules are not evaluated
+	manager *rules.Manager
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRuleEvaluator(cfg *config.Prometheus, queryService QueryService, leaderChecker LeaderChecker) *RuleEvaluator {
+	ctx, cancel := context.WithCancel(context.Background())
+	sender := newIngesterSender(cfg.Rules.IngesterAddress, uint16(cfg.Rules.OrgID))
+	leaderOnly := cfg.Rules.LeaderOnly
+	if leaderOnly && leaderChecker == nil {
+		log.Warning("prometheus rules leader-only is ignored without controller election, the rules are evaluated by this querier")
+		leaderOnly = false
+	}
+	return &RuleEvaluator{
+		config:       cfg,
+		queryService: queryService,
+		sender:       sender,
+		notifier:     newAlertNotifier(uint32(cfg.Rules.OrgID), sender),
+		leaderOnly:   leaderOnly,
+		isLeader:     func() bool { return isLeader(leaderOnly, leaderChecker) },
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+}
+
+func isLeader(leaderOnly bool, leaderChecker LeaderChecker) bool {
+	if !leaderOnly {
+		return true
+	}
+	leader, err := leaderChecker.IsLeader()
+	if err != nil {
+		log.Debugf("check leader controller failed: %s", err)
+		return false
+	}
+	return leader
+}
+
+func (e *RuleEvaluator) newManager() *rules.Manager {
+	return rules.NewManager(&rules.ManagerOptions{
+		QueryFunc:  e.query,
+		NotifyFunc: e.notifier.Notify,
+		Context:    e.ctx,
+		Appendable: &recordAppendable{sender: e.sender},
+		// the `for` state of alerts is not saved, there is nothing to restore after restarting
+		Queryable: storage.QueryableFunc(func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
+			return storage.NoopQuerier(), nil
+		}),
+		Logger:      &rulesLogger{},
+		ResendDelay: RESEND_DELAY,
+	})
+}
+
+func (e *RuleEvaluator) Start() {
+	go e.run()
+	log.Infof("prometheus rule evaluator started, leader only: %v, rule files: %v", e.leaderOnly, e.config.Rules.Files)
+}
+
+// Close stops the evaluation and waits for the running rule groups
+func (e *RuleEvaluator) Close() error {
+	e.cancel()
+	<-e.done
+	e.sender.Close()
+	return nil
+}
+
+func (e *RuleEvaluator) run() {
+	defer close(e.done)
+	leaderTicker := time.NewTicker(LEADER_CHECK_INTERVAL)
+	defer leaderTicker.Stop()
+	var reloadC <-chan time.Time
+	if e.config.Rules.ReloadInterval > 0 {
+		reloadTicker := time.NewTicker(time.Duration(e.config.Rules.ReloadInterval) * time.Second)
+		defer reloadTicker.Stop()
+		reloadC = reloadTicker.C
+	}
+
+	e.updateLeadership()
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.stopManager()
+			return
+		case <-leaderTicker.C:
+			e.updateLeadership()
+		case <-reloadC:
+			if e.manager == nil {
+				continue
+			}
+			if err := e.reload(); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+}
+
+// updateLeadership starts the evaluation when becoming the leader, and stops it when losing the leadership
+func (e *RuleEvaluator) updateLeadership() {
+	leader := e.isLeader()
+	if leader && e.manager == nil {
+		e.manager = e.newManager()
+		if err := e.reload(); err != nil {
+			log.Error(err)
+		}
+		go e.manager.Run()
+		log.Info("start evaluating prometheus rules")
+	} else if !leader && e.manager != nil {
+		e.stopManager()
+		log.Info("stop evaluating prometheus rules, the controller is no longer the leader")
+	}
+}
+
+func (e *RuleEvaluator) stopManager() {
+	if e.manager != nil {
+		e.manager.Stop()
+		e.manager = nil
+	}
+}
+
+// reload updates the rule groups, the unchanged groups keep running with their states
+func (e *RuleEvaluator) reload() error {
+	files := []string{}
+	for _, pattern := range e.config.Rules.Files {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid rule file pattern %s: %s", pattern, err)
+		}
+		files = append(files, matches...)
+	}
+	interval := time.Duration(e.config.Rules.EvaluationInterval) * time.Second
+	if err := e.manager.Update(interval, files, nil, "", nil); err != nil {
+		return fmt.Errorf("load rule files %v failed: %s", files, err)
+	}
+	return nil
+}
+
+// query is the `rules.QueryFunc`, it executes instant queries in the same way as the `/prom/api/v1/query` api
+func (e *RuleEvaluator) query(ctx context.Context, q string, t time.Time) (promql.Vector, error) {
+	args := &model.PromQueryParams{
+		Promql:     q,
+		StartTime:  t.Format(time.RFC3339Nano),
+		EndTime:    t.Format(time.RFC3339Nano),
+		OrgID:      strconv.Itoa(e.config.Rules.OrgID),
+		Slimit:     e.config.SeriesLimit,
+		Offloading: e.config.OperatorOffloading,
+		Context:    ctx,
+	}
+	result, err := e.queryService.PromInstantQueryService(args, ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := result.Data.(*model.PromQueryData)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result of %s", q)
+	}
+	switch v := data.Result.(type) {
+	case promql.Vector:
+		return v, nil
+	case promql.Scalar:
+		return promql.Vector{promql.Sample{
+			Point:  promql.Point(v),
+			Metric: labels.Labels{},
+		}}, nil
+	default:
+		return nil, fmt.Errorf("rule result of %s is not a vector or scalar", q)
+	}
+}
+
+// rulesLogger prints the logs of rule manager, the errors of evaluation are warned
+type rulesLogger struct{}
+
+func (l *rulesLogger) Log(keyvals ...interface{}) error {
+	level := ""
+	var buf strings.Builder
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if fmt.Sprint(keyvals[i]) == "level" {
+			level = fmt.Sprint(keyvals[i+1])
+			continue
+		}
+		fmt.Fprintf(&buf, "[%s=%v]", keyvals[i], keyvals[i+1])
+	}
+	switch level {
+	case "error", "warn":
+		log.Warning(buf.String())
+	case "debug":
+		log.Debug(buf.String())
+	default:
+		log.Info(buf.String())
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/rules"
+
+	"github.com/khulnasoft/deepflow/message/alert_event"
+	"github.com/khulnasoft/deepflow/server/libs/codec"
+	"github.com/khulnasoft/deepflow/server/libs/datatype"
+	"github.com/khulnasoft/deepflow/server/querier/app/prometheus/config"
+)
+
+type fakeSender struct {
+	msgTypes []datatype.MessageType
+	records  [][]byte
+}
+
+func (s *fakeSender) Send(msgType datatype.MessageType, records [][]byte) error {
+	for _, record := range records {
+		s.msgTypes = append(s.msgTypes, msgType)
+		s.records = append(s.records, append([]byte{}, record...))
+	}
+	return nil
+}
+
+func TestAlertNotifierTransitions(t *testing.T) {
+	sender := &fakeSender{}
+	notifier := newAlertNotifier(1, sender)
+	activeAt := time.Unix(1700000000, 0)
+	alert := &rules.Alert{
+		State:       rules.StateFiring,
+		Labels:      labels.FromStrings(labels.AlertName, "HighErrorRate", "severity", "critical", "service", "web"),
+		Annotations: labels.FromStrings("summary", "too many errors"),
+		Value:       0.5,
+		ActiveAt:    activeAt,
+		FiredAt:     activeAt.Add(time.Minute),
+	}
+	expr := `rate(errors[1m]) > 0.1`
+
+	notifier.Notify(context.Background(), expr, alert)
+	// resent by the rule manager
+	notifier.Notify(context.Background(), expr, alert)
+	resolved := *alert
+	resolved.State = rules.StateInactive
+	resolved.ResolvedAt = activeAt.Add(5 * time.Minute)
+	notifier.Notify(context.Background(), expr, &resolved)
+	notifier.Notify(context.Background(), expr, &resolved)
+
+	if len(sender.records) != 2 {
+		t.Fatalf("expected 2 alert events, actual %d", len(sender.records))
+	}
+	expected := []struct {
+		time  uint32
+		level uint32
+		state string
+	}{
+		{uint32(alert.FiredAt.Unix()), EVENT_LEVEL_CRITICAL, ALERT_STATE_FIRING},
+		{uint32(resolved.ResolvedAt.Unix()), EVENT_LEVEL_RECOVERED, ALERT_STATE_RESOLVED},
+	}
+	for i, record := range sender.records {
+		if sender.msgTypes[i] != datatype.MESSAGE_TYPE_ALERT_EVENT {
+			t.Errorf("unexpected message type %s", sender.msgTypes[i])
+		}
+		event := &alert_event.AlertEvent{}
+		if err := event.Unmarshal(record); err != nil {
+			t.Fatal(err)
+		}
+		if event.GetTime() != expected[i].time || event.GetEventLevel() != expected[i].level ||
+			event.GetAlertPolicy() != "HighErrorRate" || event.GetMetricValue() != 0.5 || event.GetOrgId() != 1 {
+			t.Errorf("unexpected alert event %+v", event)
+		}
+		if event.GetTargetTags() != `{service="web", severity="critical"}` {
+			t.Errorf("unexpected target tags %s", event.GetTargetTags())
+		}
+		tags := map[string]string{}
+		for j, key := range event.GetTagStrKeys() {
+			tags[key] = event.GetTagStrValues()[j]
+		}
+		if tags["service"] != "web" || tags[ANNOTATION_PREFIX+"summary"] != "too many errors" ||
+			tags[TAG_KEY_ALERT_STATE] != expected[i].state || tags[TAG_KEY_RULE_EXPR] != expr {
+			t.Errorf("unexpected tags %v", tags)
+		}
+	}
+}
+
+func TestRecordAppender(t *testing.T) {
+	sender := &fakeSender{}
+	appender := (&recordAppendable{sender: sender}).Appender(context.Background())
+	ts := time.Unix(1700000000, 0).UnixMilli()
+	appender.Append(0, labels.FromStrings(labels.MetricName, "job:errors:rate1m", "job", "web"), ts, 1.5)
+	appender.Append(0, labels.FromStrings(labels.MetricName, ALERTS_METRIC_NAME, labels.AlertName, "a"), ts, 1)
+	appender.Append(0, labels.FromStrings(labels.MetricName, "job:errors:rate1m", "job", "db"), ts, 0)
+	appender.Append(0, labels.FromStrings(labels.MetricName, "job:errors:rate1m", "job", "api"), ts, math.Float64frombits(value.StaleNaN))
+	if err := appender.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sender.records) != 1 || sender.msgTypes[0] != datatype.MESSAGE_TYPE_TELEGRAF {
+		t.Fatalf("expected 1 telegraf record, actual %d", len(sender.records))
+	}
+	expected := "job:errors:rate1m,job=web value=1.5 1700000000000000000\n" +
+		"job:errors:rate1m,job=db value=0 1700000000000000000\n"
+	if string(sender.records[0]) != expected {
+		t.Errorf("expected lines %q, actual %q", expected, sender.records[0])
+	}
+}
+
+func TestIngesterSender(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	records := []string{"a", strings.Repeat("b", MAX_FRAME_SIZE/2), strings.Repeat("c", MAX_FRAME_SIZE/2)}
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		result := []string{}
+		for len(result) < len(records) {
+			header := make([]byte, datatype.MESSAGE_HEADER_LEN+datatype.FLOW_HEADER_LEN)
+			if _, err := io.ReadFull(reader, header); err != nil {
+				break
+			}
+			baseHeader, flowHeader := datatype.BaseHeader{}, datatype.FlowHeader{}
+			if baseHeader.Decode(header) != nil || baseHeader.Type != datatype.MESSAGE_TYPE_TELEGRAF {
+				break
+			}
+			flowHeader.Decode(header[datatype.MESSAGE_HEADER_LEN:])
+			if flowHeader.OrgID != 2 {
+				break
+			}
+			payload := make([]byte, int(baseHeader.FrameSize)-len(header))
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				break
+			}
+			decoder := &codec.SimpleDecoder{}
+			decoder.Init(payload)
+			for !decoder.IsEnd() && !decoder.Failed() {
+				result = append(result, string(decoder.ReadBytes()))
+			}
+		}
+		received <- result
+	}()
+
+	sender := newIngesterSender(listener.Addr().String(), 2)
+	defer sender.Close()
+	data := [][]byte{}
+	for _, record := range records {
+		data = append(data, []byte(record))
+	}
+	if err := sender.Send(datatype.MESSAGE_TYPE_TELEGRAF, data); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case result := <-received:
+		if strings.Join(result, ",") != strings.Join(records, ",") {
+			t.Errorf("received records are different from the sent ones, count %d", len(result))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive timeout")
+	}
+}
+
+func TestRuleEvaluatorLeadership(t *testing.T) {
+	cfg := &config.Prometheus{Rules: config.PrometheusRules{IngesterAddress: "127.0.0.1:0", LeaderOnly: true}}
+	e := NewRuleEvaluator(cfg, nil, testLeaderChecker{})
+	leader := false
+	e.isLeader = func() bool { return leader }
+
+	e.updateLeadership()
+	if e.manager != nil {
+		t.Fatal("rules should not be evaluated without the leadership")
+	}
+	leader = true
+	e.updateLeadership()
+	if e.manager == nil {
+		t.Fatal("rules should be evaluated by the leader")
+	}
+	leader = false
+	e.updateLeadership()
+	if e.manager != nil {
+		t.Fatal("rules should be stopped after losing the leadership")
+	}
+
+	leader = true
+	e.Start()
+	closed := make(chan struct{})
+	go func() {
+		e.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() should stop the evaluator")
+	}
+}
+
+type testLeaderChecker struct {
+	leader bool
+}
+
+func (c testLeaderChecker) IsLeader() (bool, error) {
+	return c.leader, nil
+}
+
+func TestRuleEvaluatorWithoutLeaderChecker(t *testing.T) {
+	cfg := &config.Prometheus{Rules: config.PrometheusRules{IngesterAddress: "127.0.0.1:0", LeaderOnly: true}}
+	// the standalone querier runs without controller election
+	e := NewRuleEvaluator(cfg, nil, nil)
+	if e.leaderOnly || !e.isLeader() {
+		t.Fatal("leader-only should be ignored without leader checker")
+	}
+	e = NewRuleEvaluator(cfg, nil, testLeaderChecker{leader: false})
+	if !e.leaderOnly || e.isLeader() {
+		t.Fatal("rules should not be evaluated by the follower")
+	}
+}
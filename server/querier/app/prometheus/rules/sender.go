@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/khulnasoft/deepflow/server/libs/codec"
+	"github.com/khulnasoft/deepflow/server/libs/datatype"
+)
+
+const (
+	DIAL_TIMEOUT  = 5 * time.Second
+	WRITE_TIMEOUT = 10 * time.Second
+	// keep the frames much smaller than the limit of receiver
+	MAX_FRAME_SIZE = datatype.MESSAGE_FRAME_SIZE_MAX / 2
+)
+
+type messageSender interface {
+	Send(msgType datatype.MessageType, records [][]byte) error
+}
+
+// ingesterSender sends the records to the receiver of ingester through tcp, in the same frame
+// format as the agents, each record is encoded by `codec.SimpleEncoder.WriteBytes`
+type ingesterSender struct {
+	address string
+	orgID   uint16
+
+	conn    net.Conn
+	encoder codec.SimpleEncoder
+	lock    sync.Mutex
+}
+
+func newIngesterSender(address string, orgID uint16) *ingesterSender {
+	return &ingesterSender{
+		address: address,
+		orgID:   orgID,
+	}
+}
+
+func (s *ingesterSender) Send(msgType datatype.MessageType, records [][]byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	headerLen := datatype.MESSAGE_HEADER_LEN + datatype.FLOW_HEADER_LEN
+	for len(records) > 0 {
+		s.encoder.Reset()
+		n := 0
+		for _, record := range records {
+			if n > 0 && headerLen+len(s.encoder.Bytes())+4+len(record) > MAX_FRAME_SIZE {
+				break
+			}
+			s.encoder.WriteBytes(record)
+			n++
+		}
+		records = records[n:]
+
+		frame := make([]byte, headerLen, headerLen+len(s.encoder.Bytes()))
+		frame = append(frame, s.encoder.Bytes()...)
+		baseHeader := datatype.BaseHeader{
+			FrameSize: uint32(len(frame)),
+			Type:      msgType,
+		}
+		flowHeader := datatype.FlowHeader{
+			Version: datatype.LATEST_VERSION,
+			OrgID:   s.orgID,
+		}
+		baseHeader.Encode(frame)
+		flowHeader.Encode(frame[datatype.MESSAGE_HEADER_LEN:])
+		if err := s.write(frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ingesterSender) write(frame []byte) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout("tcp", s.address, DIAL_TIMEOUT)
+		if err != nil {
+			return fmt.Errorf("connect to ingester %s failed: %s", s.address, err)
+		}
+		s.conn = conn
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
+	if _, err := s.conn.Write(frame); err != nil {
+		// reconnect on the next sending
+		s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("send to ingester %s failed: %s", s.address, err)
+	}
+	return nil
+}
+
+func (s *ingesterSender) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
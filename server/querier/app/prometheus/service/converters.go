@@ -141,6 +141,9 @@ func (p *prometheusReader) promReaderTransToSQL(ctx context.Context, req *prompb
 	if err != nil {
 		return ctx, "", "", "", "", err
 	}
+	if nativeHistogram, ok := ctx.Value(ctxKeyNativeHistogram{}).(bool); ok && nativeHistogram {
+		metricAlias = strings.Join(nativeHistogramColumns, ", ")
+	}
 
 	metricsArray := []string{fmt.Sprintf("toUnixTimestamp(time) AS %s", PROMETHEUS_TIME_COLUMNS)}
 	orderBy := []string{fmt.Sprintf("%s desc", PROMETHEUS_TIME_COLUMNS)}
@@ -0,0 +1,252 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/khulnasoft/deepflow/server/querier/app/prometheus/model"
+	"github.com/khulnasoft/deepflow/server/querier/common"
+	chCommon "github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/common"
+	tagdescription "github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/tag"
+)
+
+const (
+	TABLE_NAME_APPLICATION     = "application"
+	TABLE_NAME_APPLICATION_MAP = "application_map"
+
+	// the slowest requests are the exemplars of each selector
+	EXEMPLAR_LIMIT = 100
+	// the value of exemplars for application metrics
+	EXEMPLAR_DEFAULT_VALUE = "response_duration"
+	SERVER_SIDE_TAG_SUFFIX = "_1"
+)
+
+// API Spec: https://prometheus.io/docs/prometheus/latest/querying/api/#querying-exemplars
+// The exemplars are the samples of l7_flow_log with trace_id, they are found for the selectors of
+// flow_log.l7_flow_log and flow_metrics.application(_map) metrics, so we can jump from them to traces.
+// The exemplars of Prometheus metrics are remote written by the apps and stored in prometheus.exemplars.
+func (p *prometheusExecutor) queryExemplars(ctx context.Context, args *model.PromQueryParams) (*model.PromQueryResponse, error) {
+	start, err := parseTime(args.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseTime(args.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := parser.ParseExpr(args.Promql)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &prometheusReader{
+		orgID:                   args.OrgID,
+		blockTeamID:             args.BlockTeamID,
+		getExternalTagFromCache: p.convertExternalTagToQuerierAllowTag,
+		addExternalTagToCache:   p.addExtraLabelsToCache,
+	}
+	results := []model.PromExemplarResult{}
+	for _, matchers := range parser.ExtractSelectors(expr) {
+		sql, db, seriesLabels := reader.exemplarsTransToSQL(matchers, start.Unix(), end.Unix())
+		if sql == "" {
+			continue
+		}
+		result, _, _, err := queryDataExecute(ctx, sql, db, "", args.OrgID, args.Debug)
+		if err != nil {
+			return nil, err
+		}
+		if exemplars := parseExemplars(result); len(exemplars) > 0 {
+			results = append(results, model.PromExemplarResult{SeriesLabels: seriesLabels, Exemplars: exemplars})
+		}
+	}
+	return &model.PromQueryResponse{Data: results, Status: _SUCCESS}, nil
+}
+
+// exemplarsTransToSQL returns empty sql if the metric has no exemplar
+func (p *prometheusReader) exemplarsTransToSQL(matchers []*labels.Matcher, start, end int64) (string, string, labels.Labels) {
+	pbMatchers := make([]*prompb.LabelMatcher, 0, len(matchers))
+	seriesLabels := make(labels.Labels, 0, len(matchers))
+	for _, m := range matchers {
+		pbMatchers = append(pbMatchers, &prompb.LabelMatcher{Type: parseMatcherType(m.Type), Name: m.Name, Value: m.Value})
+		if m.Type == labels.MatchEqual {
+			seriesLabels = append(seriesLabels, labels.Label{Name: m.Name, Value: m.Value})
+		}
+	}
+	sort.Sort(seriesLabels)
+
+	prefixType, metricName, db, table, _, _, _, err := parseMetric(pbMatchers)
+	if err != nil {
+		return "", "", nil
+	}
+	if db == "" || db == chCommon.DB_NAME_PROMETHEUS {
+		return p.prometheusExemplarsTransToSQL(pbMatchers, prefixType, table, start, end), chCommon.DB_NAME_PROMETHEUS, seriesLabels
+	}
+	valueField := ""
+	if db == chCommon.DB_NAME_FLOW_LOG && table == TABLE_NAME_L7_FLOW_LOG {
+		valueField = metricName
+	} else if db == chCommon.DB_NAME_FLOW_METRICS && (table == TABLE_NAME_APPLICATION || table == TABLE_NAME_APPLICATION_MAP) {
+		valueField = EXEMPLAR_DEFAULT_VALUE
+	} else {
+		return "", "", nil
+	}
+
+	filters := []string{fmt.Sprintf("(time >= %d AND time <= %d)", start, end), "trace_id != ''"}
+	for _, matcher := range pbMatchers {
+		if table == TABLE_NAME_APPLICATION {
+			// the tags of application metrics are the server side tags of l7_flow_log
+			serverSideMatcher := *matcher
+			serverSideMatcher.Name = serverSideTagName(matcher.Name)
+			matcher = &serverSideMatcher
+		}
+		if _, _, _, filter := p.parseMatchers(matcher, prefixType, db); filter != "" {
+			filters = append(filters, filter)
+		}
+	}
+	if len(p.blockTeamID) > 0 {
+		filters = append(filters, fmt.Sprintf("team_id not in (%s)", strings.Join(p.blockTeamID, ",")))
+	}
+	sql := fmt.Sprintf("SELECT toUnixTimestamp(time) AS %s, trace_id, span_id, `%s` AS %s FROM `%s` WHERE %s ORDER BY %s DESC LIMIT %d",
+		PROMETHEUS_TIME_COLUMNS, valueField, PROMETHEUS_METRIC_VALUE, TABLE_NAME_L7_FLOW_LOG,
+		strings.Join(filters, " AND "), PROMETHEUS_METRIC_VALUE, EXEMPLAR_LIMIT)
+	return sql, chCommon.DB_NAME_FLOW_LOG, seriesLabels
+}
+
+// the exemplars of native histogram series are stored with the histogram metric, and have no `le` label
+func (p *prometheusReader) prometheusExemplarsTransToSQL(matchers []*prompb.LabelMatcher, prefixType prefix, metricName string, start, end int64) string {
+	histogramMetric, _ := getNativeHistogramMetric(p.orgID, matchers)
+	if histogramMetric != "" {
+		metricName = histogramMetric
+	}
+	filters := []string{fmt.Sprintf("(time >= %d AND time <= %d)", start, end)}
+	for _, matcher := range matchers {
+		if histogramMetric != "" && matcher.Name == HISTOGRAM_LE_LABEL {
+			continue
+		}
+		if _, _, _, filter := p.parseMatchers(matcher, prefixType, chCommon.DB_NAME_PROMETHEUS); filter != "" {
+			filters = append(filters, filter)
+		}
+	}
+	if len(p.blockTeamID) > 0 {
+		filters = append(filters, fmt.Sprintf("team_id not in (%s)", strings.Join(p.blockTeamID, ",")))
+	}
+	return fmt.Sprintf("SELECT toUnixTimestamp(time) AS %s, trace_id, span_id, %s, label_names, label_values FROM `%s.%s` WHERE %s ORDER BY %s DESC LIMIT %d",
+		PROMETHEUS_TIME_COLUMNS, PROMETHEUS_METRIC_VALUE, chCommon.TABLE_NAME_PROMETHEUS_EXEMPLARS, metricName,
+		strings.Join(filters, " AND "), PROMETHEUS_TIME_COLUMNS, EXEMPLAR_LIMIT)
+}
+
+func serverSideTagName(tag string) string {
+	key := tagdescription.TagDescriptionKey{DB: chCommon.DB_NAME_FLOW_LOG, Table: TABLE_NAME_L7_FLOW_LOG, TagName: tag}
+	if _, ok := tagdescription.TAG_DESCRIPTIONS[key]; ok {
+		return tag
+	}
+	key.TagName = tag + SERVER_SIDE_TAG_SUFFIX
+	if _, ok := tagdescription.TAG_DESCRIPTIONS[key]; ok {
+		return key.TagName
+	}
+	return tag
+}
+
+func parseExemplars(result *common.Result) []model.PromExemplar {
+	if result == nil {
+		return nil
+	}
+	columns := make(map[string]int, len(result.Columns))
+	for i, column := range result.Columns {
+		if name, ok := column.(string); ok {
+			columns[name] = i
+		}
+	}
+	timeIndex, ok1 := columns[PROMETHEUS_TIME_COLUMNS]
+	valueIndex, ok2 := columns[PROMETHEUS_METRIC_VALUE]
+	traceIndex, ok3 := columns["trace_id"]
+	spanIndex, ok4 := columns["span_id"]
+	if !(ok1 && ok2 && ok3 && ok4) {
+		return nil
+	}
+	// the other labels of prometheus exemplars
+	labelNamesIndex, hasLabelNames := columns["label_names"]
+	labelValuesIndex, hasLabelValues := columns["label_values"]
+
+	exemplars := make([]model.PromExemplar, 0, len(result.Values))
+	for _, v := range result.Values {
+		row, ok := v.([]interface{})
+		if !ok || len(row) != len(result.Columns) {
+			continue
+		}
+		timestamp, ok := toFloat64(row[timeIndex])
+		if !ok {
+			continue
+		}
+		value, ok := toFloat64(row[valueIndex])
+		if !ok {
+			continue
+		}
+		exemplarLabels := labels.Labels{}
+		if traceID := fmt.Sprint(row[traceIndex]); traceID != "" {
+			exemplarLabels = append(exemplarLabels, labels.Label{Name: "trace_id", Value: traceID})
+		}
+		if spanID := fmt.Sprint(row[spanIndex]); spanID != "" {
+			exemplarLabels = append(exemplarLabels, labels.Label{Name: "span_id", Value: spanID})
+		}
+		if hasLabelNames && hasLabelValues {
+			names, _ := row[labelNamesIndex].([]string)
+			values, _ := row[labelValuesIndex].([]string)
+			for j := 0; j < len(names) && j < len(values); j++ {
+				exemplarLabels = append(exemplarLabels, labels.Label{Name: names[j], Value: values[j]})
+			}
+			sort.Sort(exemplarLabels)
+		}
+		exemplars = append(exemplars, model.PromExemplar{
+			Labels:    exemplarLabels,
+			Value:     strconv.FormatFloat(value, 'f', -1, 64),
+			Timestamp: timestamp,
+		})
+	}
+	// exemplars are sorted by time in prometheus
+	sort.SliceStable(exemplars, func(i, j int) bool { return exemplars[i].Timestamp < exemplars[j].Timestamp })
+	return exemplars
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
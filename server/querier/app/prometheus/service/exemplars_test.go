@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+)
+
+func TestParseExemplars(t *testing.T) {
+	result := &common.Result{
+		Columns: []interface{}{PROMETHEUS_TIME_COLUMNS, "trace_id", "span_id", PROMETHEUS_METRIC_VALUE},
+		Values: []interface{}{
+			[]interface{}{uint32(20), "t2", "", float64(3)},
+			[]interface{}{uint32(10), "t1", "s1", float64(1.5)},
+			[]interface{}{"bad", "t3", "s3", float64(1)},
+		},
+	}
+	exemplars := parseExemplars(result)
+	if len(exemplars) != 2 {
+		t.Fatalf("expected 2 exemplars, actual %v", exemplars)
+	}
+	first, second := exemplars[0], exemplars[1]
+	if first.Timestamp != 10 || first.Value != "1.5" || first.Labels.Get("trace_id") != "t1" || first.Labels.Get("span_id") != "s1" {
+		t.Errorf("unexpected exemplar %+v", first)
+	}
+	if second.Timestamp != 20 || second.Value != "3" || len(second.Labels) != 1 {
+		t.Errorf("unexpected exemplar %+v", second)
+	}
+}
+
+func TestParsePrometheusExemplars(t *testing.T) {
+	result := &common.Result{
+		Columns: []interface{}{PROMETHEUS_TIME_COLUMNS, "trace_id", "span_id", PROMETHEUS_METRIC_VALUE, "label_names", "label_values"},
+		Values: []interface{}{
+			[]interface{}{uint32(10), "t1", "", float64(0.2), []string{"user"}, []string{"u1"}},
+		},
+	}
+	exemplars := parseExemplars(result)
+	if len(exemplars) != 1 {
+		t.Fatalf("expected 1 exemplar, actual %v", exemplars)
+	}
+	if l := exemplars[0].Labels; len(l) != 2 || l.Get("trace_id") != "t1" || l.Get("user") != "u1" {
+		t.Errorf("unexpected exemplar labels %v", l)
+	}
+}
+
+func TestFormatQuery(t *testing.T) {
+	p := &prometheusExecutor{}
+	resp, err := p.formatQuery(`sum(rate(foo{bar="baz"}[5m]))by(job)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `sum by(job) (rate(foo{bar="baz"}[5m]))`; resp.Data != expected {
+		t.Errorf("expected %s, actual %v", expected, resp.Data)
+	}
+	if _, err := p.formatQuery("foo{"); err == nil {
+		t.Error("expected parse error")
+	}
+}
@@ -19,12 +19,21 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/khulnasoft/deepflow/server/querier/app/prometheus/model"
+	"github.com/khulnasoft/deepflow/server/querier/common"
+	"github.com/khulnasoft/deepflow/server/querier/config"
 	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse"
+	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/client"
 	chCommon "github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/common"
 	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/metrics"
+	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/trans_prometheus"
 )
 
 const (
@@ -33,9 +42,26 @@ const (
 	TABLE_NAME_L7_FLOW_LOG = "l7_flow_log"
 	TABLE_NAME_SAMPLES     = "samples"
 	METRICS_CATEGORY_TAG   = "Tag"
+
+	// the time range of label queries without `start` or `end`
+	defaultMetaQueryRange = time.Hour
 )
 
 func (p *prometheusExecutor) getTagValues(ctx context.Context, args *model.PromMetaParams) (result *model.PromQueryResponse, err error) {
+	if len(args.Matchers) > 0 {
+		// get values from the matched series
+		series, err := p.matchedSeries(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		values := map[string]struct{}{}
+		for _, s := range series {
+			if v := s.Get(args.LabelName); v != "" {
+				values[v] = struct{}{}
+			}
+		}
+		return &model.PromQueryResponse{Data: sortedKeys(values), Status: _SUCCESS}, nil
+	}
 	if args.LabelName == LABEL_NAME_METRICS {
 		return &model.PromQueryResponse{
 			Data: getMetrics(ctx, args),
@@ -44,7 +70,127 @@ func (p *prometheusExecutor) getTagValues(ctx context.Context, args *model.PromM
 	return result, err
 }
 
+// API Spec: https://prometheus.io/docs/prometheus/latest/querying/api/#getting-label-names
+func (p *prometheusExecutor) getTagNames(ctx context.Context, args *model.PromMetaParams) (*model.PromQueryResponse, error) {
+	names := map[string]struct{}{}
+	if len(args.Matchers) > 0 {
+		series, err := p.matchedSeries(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range series {
+			for _, l := range s {
+				names[l.Name] = struct{}{}
+			}
+		}
+	} else if args.StartTime != "" || args.EndTime != "" {
+		// without matchers, get the names of the prometheus labels written in the time range
+		sql, err := labelNamesSQL(args.StartTime, args.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		chClient := client.Client{
+			Host:     config.Cfg.Clickhouse.Host,
+			Port:     config.Cfg.Clickhouse.Port,
+			UserName: config.Cfg.Clickhouse.User,
+			Password: config.Cfg.Clickhouse.Password,
+			DB:       "flow_tag",
+			Context:  ctx,
+		}
+		result, err := chClient.DoQuery(&client.QueryParams{Sql: sql, ORGID: args.OrgID})
+		if err != nil {
+			return nil, err
+		}
+		names[LABEL_NAME_METRICS] = struct{}{}
+		for _, value := range result.Values {
+			if name, ok := value.([]interface{})[0].(string); ok {
+				names[name] = struct{}{}
+			}
+		}
+	} else {
+		// without matchers and time range, return the names of all prometheus labels in cache
+		orgID := args.OrgID
+		if orgID == "" {
+			orgID = common.DEFAULT_ORG_ID
+		}
+		names[LABEL_NAME_METRICS] = struct{}{}
+		for name := range trans_prometheus.ORGPrometheus[orgID].LabelNameToID {
+			names[name] = struct{}{}
+		}
+	}
+	return &model.PromQueryResponse{Data: sortedKeys(names), Status: _SUCCESS}, nil
+}
+
+// labelNamesSQL gets the names of the prometheus labels in `flow_tag.prometheus_custom_field` between start and end,
+// the time of the field is refreshed when the label is written again, so a label is kept if it is written in the range
+func labelNamesSQL(startTime, endTime string) (string, error) {
+	filters := []string{"field_type='tag'"}
+	if startTime != "" {
+		start, err := parseTime(startTime)
+		if err != nil {
+			return "", err
+		}
+		filters = append(filters, fmt.Sprintf("time>=%d", start.Unix()))
+	}
+	if endTime != "" {
+		end, err := parseTime(endTime)
+		if err != nil {
+			return "", err
+		}
+		filters = append(filters, fmt.Sprintf("time<=%d", end.Unix()))
+	}
+	return fmt.Sprintf("SELECT field_name FROM flow_tag.prometheus_custom_field WHERE %s GROUP BY field_name", strings.Join(filters, " AND ")), nil
+}
+
+// matchedSeries gets the series matched by `match[]`, the time range is the last hour if not specified
+func (p *prometheusExecutor) matchedSeries(ctx context.Context, args *model.PromMetaParams) ([]labels.Labels, error) {
+	endTime, startTime := args.EndTime, args.StartTime
+	if endTime == "" {
+		endTime = strconv.FormatInt(time.Now().Unix(), 10)
+	}
+	if startTime == "" {
+		end, err := parseTime(endTime)
+		if err != nil {
+			return nil, err
+		}
+		startTime = strconv.FormatInt(end.Add(-defaultMetaQueryRange).Unix(), 10)
+	}
+	queryArgs := &model.PromQueryParams{
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Matchers:    args.Matchers,
+		OrgID:       args.OrgID,
+		BlockTeamID: args.BlockTeamID,
+		Context:     ctx,
+	}
+	// should show tags when get `Series`
+	result, err := p.series(context.WithValue(ctx, CtxKeyShowTag{}, true), queryArgs)
+	if err != nil {
+		return nil, err
+	}
+	series, _ := result.Data.([]labels.Labels)
+	return series, nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func getMetrics(ctx context.Context, args *model.PromMetaParams) (resp []string) {
+	resp = []string{}
+	rangeMetrics(ctx, args, func(name string, _ *metrics.Metrics) {
+		resp = append(resp, name)
+	})
+	return resp
+}
+
+// rangeMetrics calls handle for all metrics, the description of prometheus metrics is nil
+func rangeMetrics(ctx context.Context, args *model.PromMetaParams, handle func(name string, m *metrics.Metrics)) {
 	// We speed up the return of the metrics list by querying the aggregation information in
 	// `flow_tag.ext_metrics_custom_field_value`. Since we do not query the original time series
 	// data, filtering metrics by time is currently not supported.
@@ -60,14 +206,13 @@ func getMetrics(ctx context.Context, args *model.PromMetaParams) (resp []string)
 	//	where = fmt.Sprintf("time<=%s", args.EndTime)
 	//}
 
-	resp = []string{}
 	for db, tables := range chCommon.DB_TABLE_MAP {
 		if db == chCommon.DB_NAME_EXT_METRICS {
 			extMetrics, _ := metrics.GetExtMetrics(chCommon.DB_NAME_EXT_METRICS, "", where, "", args.OrgID, false, args.Context)
 			for _, v := range extMetrics {
 				// append telegraf metrics, e.g.: influxdb_internal_statsd__tcp_current_connections[influxdb_target__metric]
 				metricName := fmt.Sprintf("%s__%s__%s__%s", db, "metrics", strings.Replace(v.Table, ".", "_", 1), strings.TrimPrefix(v.DisplayName, "metrics."))
-				resp = append(resp, metricName)
+				handle(metricName, v)
 			}
 		} else if db == chCommon.DB_NAME_PROMETHEUS {
 			// prometheus samples should get all metrcis from `table`
@@ -75,16 +220,16 @@ func getMetrics(ctx context.Context, args *model.PromMetaParams) (resp []string)
 			for _, v := range samples.Values {
 				tableName := v.([]interface{})[0].(string)
 				// append ${metrics_name}
-				resp = append(resp, tableName)
+				handle(tableName, nil)
 				// append prometheus__samples__${metrics_name}
 				metricsName := fmt.Sprintf("%s__%s__%s", db, TABLE_NAME_SAMPLES, tableName)
-				resp = append(resp, metricsName)
+				handle(metricsName, nil)
 			}
 		} else if db == chCommon.DB_NAME_DEEPFLOW_ADMIN || db == chCommon.DB_NAME_DEEPFLOW_TENANT {
 			deepflowSystem, _ := metrics.GetExtMetrics(db, "", where, "", args.OrgID, false, args.Context)
 			for _, v := range deepflowSystem {
 				metricName := fmt.Sprintf("%s__%s__%s", db, strings.ReplaceAll(v.Table, ".", "_"), strings.TrimPrefix(v.DisplayName, "metrics."))
-				resp = append(resp, metricName)
+				handle(metricName, v)
 			}
 		} else {
 			for _, table := range tables {
@@ -99,16 +244,15 @@ func getMetrics(ctx context.Context, args *model.PromMetaParams) (resp []string)
 					metricsName := ""
 					if db == chCommon.DB_NAME_FLOW_METRICS {
 						metricsName = fmt.Sprintf("%s__%s__%s__%s", db, table, field, "1m")
-						resp = append(resp, metricsName)
+						handle(metricsName, v)
 						metricsName = fmt.Sprintf("%s__%s__%s__%s", db, table, field, "1s")
-						resp = append(resp, metricsName)
+						handle(metricsName, v)
 					} else {
 						metricsName = fmt.Sprintf("%s__%s__%s", db, table, field)
-						resp = append(resp, metricsName)
+						handle(metricsName, v)
 					}
 				}
 			}
 		}
 	}
-	return resp
 }
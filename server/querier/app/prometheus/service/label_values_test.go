@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+)
+
+func TestLabelNamesSQL(t *testing.T) {
+	sql, err := labelNamesSQL("1700000000", "2023-11-14T23:13:20Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "SELECT field_name FROM flow_tag.prometheus_custom_field WHERE field_type='tag' AND time>=1700000000 AND time<=1700003600 GROUP BY field_name"
+	if sql != expected {
+		t.Errorf("expected %s, actual %s", expected, sql)
+	}
+	sql, err = labelNamesSQL("", "1700000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = "SELECT field_name FROM flow_tag.prometheus_custom_field WHERE field_type='tag' AND time<=1700000000 GROUP BY field_name"
+	if sql != expected {
+		t.Errorf("expected %s, actual %s", expected, sql)
+	}
+	if _, err := labelNamesSQL("bad", ""); err == nil {
+		t.Error("expected parse error")
+	}
+}
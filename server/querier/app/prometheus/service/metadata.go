@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/khulnasoft/deepflow/server/querier/app/prometheus/model"
+	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/metrics"
+)
+
+const (
+	// the prometheus version which the PromQL engine and apis are compatible with, some clients (i.e.: grafana)
+	// detect the features by it
+	PROMETHEUS_COMPATIBLE_VERSION = "2.36.2"
+
+	METADATA_TYPE_GAUGE   = "gauge"
+	METADATA_TYPE_UNKNOWN = "unknown"
+)
+
+var buildInfo = model.PromBuildInfo{
+	Version:   PROMETHEUS_COMPATIBLE_VERSION,
+	GoVersion: runtime.Version(),
+}
+
+func SetBuildInfo(branch, revision, compileTime string) {
+	buildInfo.Branch = branch
+	buildInfo.Revision = revision
+	buildInfo.BuildDate = compileTime
+}
+
+// API Spec: https://prometheus.io/docs/prometheus/latest/querying/api/#build-information
+func (p *prometheusExecutor) getBuildInfo() *model.PromQueryResponse {
+	return &model.PromQueryResponse{Data: buildInfo, Status: _SUCCESS}
+}
+
+// API Spec: https://prometheus.io/docs/prometheus/latest/querying/api/#formatting-query-expressions
+func (p *prometheusExecutor) formatQuery(query string) (*model.PromQueryResponse, error) {
+	expr, err := parser.ParseExpr(query)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PromQueryResponse{Data: expr.String(), Status: _SUCCESS}, nil
+}
+
+// API Spec: https://prometheus.io/docs/prometheus/latest/querying/api/#querying-metric-metadata
+func (p *prometheusExecutor) metricMetadata(ctx context.Context, args *model.PromMetaParams) (*model.PromQueryResponse, error) {
+	result := map[string][]model.PromMetadata{}
+	rangeMetrics(ctx, args, func(name string, m *metrics.Metrics) {
+		if args.Metric != "" && name != args.Metric {
+			return
+		}
+		if args.Limit >= 0 && len(result) >= args.Limit {
+			return
+		}
+		result[name] = []model.PromMetadata{metricToMetadata(m)}
+	})
+	return &model.PromQueryResponse{Data: result, Status: _SUCCESS}, nil
+}
+
+// the metadata of prometheus metrics is not stored, and DeepFlow metrics are aggregated in each
+// interval, so all of them are gauges
+func metricToMetadata(m *metrics.Metrics) model.PromMetadata {
+	if m == nil {
+		return model.PromMetadata{Type: METADATA_TYPE_UNKNOWN}
+	}
+	metadata := model.PromMetadata{
+		Type: METADATA_TYPE_GAUGE,
+		Help: m.Description,
+		Unit: m.Unit,
+	}
+	if metadata.Help == "" {
+		metadata.Help = m.DisplayName
+	}
+	return metadata
+}
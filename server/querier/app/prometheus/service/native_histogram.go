@@ -0,0 +1,323 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+	chCommon "github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/common"
+	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/trans_prometheus"
+)
+
+// native histograms are stored in `prometheus`.`histograms`, when they are queried as
+// `<metric>_bucket`/`<metric>_count`/`<metric>_sum`, they are expanded to classic histogram series,
+// so that histogram_quantile and other functions work with them
+const (
+	HISTOGRAM_BUCKET_SUFFIX = "_bucket"
+	HISTOGRAM_COUNT_SUFFIX  = "_count"
+	HISTOGRAM_SUM_SUFFIX    = "_sum"
+	HISTOGRAM_LE_LABEL      = "le"
+)
+
+var nativeHistogramColumns = []string{
+	"count", "sum", "schema", "zero_threshold", "zero_count",
+	"positive_bucket_indexes", "positive_bucket_counts", "negative_bucket_indexes", "negative_bucket_counts",
+}
+
+// define `native histogram` flag, query histogram columns instead of `value`
+type ctxKeyNativeHistogram struct{}
+
+// getNativeHistogramMetric returns the native histogram metric and the suffix of the queried classic series,
+// only when the queried metric itself does not exist
+func getNativeHistogramMetric(orgID string, matchers []*prompb.LabelMatcher) (metric string, suffix string) {
+	if orgID == "" {
+		orgID = common.DEFAULT_ORG_ID
+	}
+	for _, matcher := range matchers {
+		if matcher.Name != PROMETHEUS_METRICS_NAME {
+			continue
+		}
+		if matcher.Type != prompb.LabelMatcher_EQ || strings.Contains(matcher.Value, "__") {
+			return "", ""
+		}
+		metricNameToID := trans_prometheus.ORGPrometheus[orgID].MetricNameToID
+		if _, ok := metricNameToID[matcher.Value]; ok {
+			return "", ""
+		}
+		for _, s := range []string{HISTOGRAM_BUCKET_SUFFIX, HISTOGRAM_COUNT_SUFFIX, HISTOGRAM_SUM_SUFFIX} {
+			if !strings.HasSuffix(matcher.Value, s) {
+				continue
+			}
+			metric = strings.TrimSuffix(matcher.Value, s)
+			if _, ok := metricNameToID[metric]; ok {
+				return metric, s
+			}
+		}
+		return "", ""
+	}
+	return "", ""
+}
+
+func (p *prometheusReader) nativeHistogramTransToSQL(ctx context.Context, req *prompb.ReadRequest, histogramMetric string, startTime int64, endTime int64, debug bool) (context.Context, string, string, string, string, error) {
+	q := req.Queries[0]
+	metricName := ""
+	matchers := make([]*prompb.LabelMatcher, 0, len(q.Matchers))
+	for _, matcher := range q.Matchers {
+		if matcher.Name == PROMETHEUS_METRICS_NAME {
+			metricName = matcher.Value
+			matchers = append(matchers, &prompb.LabelMatcher{Type: prompb.LabelMatcher_EQ, Name: PROMETHEUS_METRICS_NAME, Value: fmt.Sprintf("%s.%s", chCommon.TABLE_NAME_PROMETHEUS_HISTOGRAMS, histogramMetric)})
+		} else if matcher.Name != HISTOGRAM_LE_LABEL {
+			// `le` is generated by expansion, filter it after query
+			matchers = append(matchers, matcher)
+		}
+	}
+	// aggregations are calculated by prometheus engine after expansion, so hints are not used for query
+	histogramQuery := &prompb.Query{StartTimestampMs: q.StartTimestampMs, EndTimestampMs: q.EndTimestampMs, Matchers: matchers}
+	if q.Hints != nil {
+		histogramQuery.Hints = &prompb.ReadHints{StepMs: q.Hints.StepMs, StartMs: q.Hints.StartMs, EndMs: q.Hints.EndMs, RangeMs: q.Hints.RangeMs}
+	}
+	ctx = context.WithValue(ctx, ctxKeyNativeHistogram{}, true)
+	ctx, sql, db, datasource, _, err := p.promReaderTransToSQL(ctx, &prompb.ReadRequest{Queries: []*prompb.Query{histogramQuery}}, startTime, endTime, debug)
+	return ctx, sql, db, datasource, metricName, err
+}
+
+type histogramBucket struct {
+	upperBound float64
+	count      float64 // cumulative count
+}
+
+// the upper bound of positive bucket `index` is (2^(2^-schema))^index
+func bucketUpperBound(schema int8, index int64) float64 {
+	return math.Exp2(float64(index) * math.Exp2(-float64(schema)))
+}
+
+// nativeHistogramToBuckets converts a native histogram to classic cumulative buckets ordered by upper bound
+func nativeHistogramToBuckets(schema int8, count, zeroThreshold, zeroCount float64, positiveIndexes []int64, positiveCounts []float64, negativeIndexes []int64, negativeCounts []float64) []histogramBucket {
+	buckets := make([]histogramBucket, 0, len(positiveIndexes)+len(negativeIndexes)+2)
+	cumulative := 0.0
+	// negative bucket `index` covers [-upper(index), -upper(index-1)), the larger index the smaller bound
+	for i := len(negativeIndexes) - 1; i >= 0; i-- {
+		if i >= len(negativeCounts) {
+			continue
+		}
+		cumulative += negativeCounts[i]
+		buckets = append(buckets, histogramBucket{upperBound: -bucketUpperBound(schema, negativeIndexes[i]-1), count: cumulative})
+	}
+	if zeroThreshold > 0 || zeroCount > 0 {
+		cumulative += zeroCount
+		buckets = append(buckets, histogramBucket{upperBound: zeroThreshold, count: cumulative})
+	}
+	for i := range positiveIndexes {
+		if i >= len(positiveCounts) {
+			break
+		}
+		cumulative += positiveCounts[i]
+		buckets = append(buckets, histogramBucket{upperBound: bucketUpperBound(schema, positiveIndexes[i]), count: cumulative})
+	}
+	return append(buckets, histogramBucket{upperBound: math.Inf(1), count: count})
+}
+
+// cumulativeCount returns the count of the observations not greater than le, the buckets are ordered by upper bound.
+// le may fall inside a bucket after the schema of the series changed, then the count of the lower bound is used,
+// so that the counts are still monotonic in le
+func cumulativeCount(buckets []histogramBucket, le float64) float64 {
+	i := sort.Search(len(buckets), func(i int) bool { return buckets[i].upperBound > le })
+	if i == 0 {
+		return 0
+	}
+	return buckets[i-1].count
+}
+
+// expandNativeHistogram converts the queried histograms to the `value` of classic `_bucket`/`_count`/`_sum` series,
+// the `le` label of buckets is added into `tag`. The buckets of each sample are expanded to the union of the bounds of
+// all samples in the series, because the populated buckets of native histograms are sparse and change over time,
+// while rate and histogram_quantile need the same `le` series at every sample
+func expandNativeHistogram(result *common.Result, suffix string, matchers []*prompb.LabelMatcher) (*common.Result, error) {
+	if result == nil {
+		return result, nil
+	}
+	histogramIndexes := make(map[string]int, len(nativeHistogramColumns))
+	otherIndexes := make([]int, 0, len(result.Columns))
+	tagIndex := -1
+	for i, column := range result.Columns {
+		name, _ := column.(string)
+		if common.IsValueInSliceString(name, nativeHistogramColumns) {
+			histogramIndexes[name] = i
+			continue
+		}
+		if name == PROMETHEUS_NATIVE_TAG_NAME {
+			tagIndex = i
+		}
+		otherIndexes = append(otherIndexes, i)
+	}
+	if len(histogramIndexes) != len(nativeHistogramColumns) {
+		return nil, fmt.Errorf("native histogram columns %v get failed", nativeHistogramColumns)
+	}
+	if suffix == HISTOGRAM_BUCKET_SUFFIX && tagIndex < 0 {
+		return nil, fmt.Errorf("native histogram buckets need column %s", PROMETHEUS_NATIVE_TAG_NAME)
+	}
+
+	leMatchers := make([]*labels.Matcher, 0, 1)
+	for _, m := range matchers {
+		if m.Name != HISTOGRAM_LE_LABEL {
+			continue
+		}
+		matcher, err := labels.NewMatcher(labels.MatchType(m.Type), m.Name, m.Value)
+		if err != nil {
+			return nil, err
+		}
+		leMatchers = append(leMatchers, matcher)
+	}
+
+	expanded := &common.Result{
+		Columns: make([]interface{}, 0, len(otherIndexes)+1),
+		Schemas: make(common.ColumnSchemas, 0, len(otherIndexes)+1),
+		Values:  make([]interface{}, 0, len(result.Values)),
+	}
+	for _, i := range otherIndexes {
+		expanded.Columns = append(expanded.Columns, result.Columns[i])
+		if i < len(result.Schemas) {
+			expanded.Schemas = append(expanded.Schemas, result.Schemas[i])
+		} else {
+			expanded.Schemas = append(expanded.Schemas, common.NewColumnSchema(result.Columns[i].(string), "", ""))
+		}
+	}
+	valueSchema := common.NewColumnSchema(PROMETHEUS_METRIC_VALUE, "", "")
+	valueSchema.ValueType = "Float64"
+	expanded.Columns = append(expanded.Columns, PROMETHEUS_METRIC_VALUE)
+	expanded.Schemas = append(expanded.Schemas, valueSchema)
+
+	newRow := func(values []interface{}, value float64) []interface{} {
+		row := make([]interface{}, 0, len(otherIndexes)+1)
+		for _, i := range otherIndexes {
+			row = append(row, values[i])
+		}
+		return append(row, value)
+	}
+	getFloat := func(values []interface{}, column string) float64 {
+		v, _ := values[histogramIndexes[column]].(float64)
+		return v
+	}
+	type sampleBuckets struct {
+		values  []interface{}
+		series  string
+		buckets []histogramBucket
+	}
+	samples := make([]sampleBuckets, 0, len(result.Values))
+	seriesBounds := make(map[string]map[float64]struct{})
+	for _, v := range result.Values {
+		values := v.([]interface{})
+		switch suffix {
+		case HISTOGRAM_COUNT_SUFFIX:
+			expanded.Values = append(expanded.Values, newRow(values, getFloat(values, "count")))
+		case HISTOGRAM_SUM_SUFFIX:
+			expanded.Values = append(expanded.Values, newRow(values, getFloat(values, "sum")))
+		case HISTOGRAM_BUCKET_SUFFIX:
+			schema, _ := values[histogramIndexes["schema"]].(int8)
+			positiveIndexes, _ := values[histogramIndexes["positive_bucket_indexes"]].([]int64)
+			positiveCounts, _ := values[histogramIndexes["positive_bucket_counts"]].([]float64)
+			negativeIndexes, _ := values[histogramIndexes["negative_bucket_indexes"]].([]int64)
+			negativeCounts, _ := values[histogramIndexes["negative_bucket_counts"]].([]float64)
+			buckets := nativeHistogramToBuckets(schema, getFloat(values, "count"), getFloat(values, "zero_threshold"), getFloat(values, "zero_count"),
+				positiveIndexes, positiveCounts, negativeIndexes, negativeCounts)
+			series := nativeHistogramSeriesKey(result.Columns, values, otherIndexes)
+			bounds, ok := seriesBounds[series]
+			if !ok {
+				bounds = make(map[float64]struct{})
+				seriesBounds[series] = bounds
+			}
+			for _, bucket := range buckets {
+				bounds[bucket.upperBound] = struct{}{}
+			}
+			samples = append(samples, sampleBuckets{values: values, series: series, buckets: buckets})
+		}
+	}
+	if len(samples) == 0 {
+		return expanded, nil
+	}
+
+	sortedBounds := make(map[string][]float64, len(seriesBounds))
+	for series, bounds := range seriesBounds {
+		sorted := make([]float64, 0, len(bounds))
+		for bound := range bounds {
+			sorted = append(sorted, bound)
+		}
+		sort.Float64s(sorted)
+		sortedBounds[series] = sorted
+	}
+	bucketTags := make(map[string]map[string]string)
+	for _, sample := range samples {
+		tagJson, _ := sample.values[tagIndex].(string)
+		tags, ok := bucketTags[tagJson]
+		if !ok {
+			tags = make(map[string]string)
+			json.Unmarshal([]byte(tagJson), &tags)
+			bucketTags[tagJson] = tags
+		}
+		for _, bound := range sortedBounds[sample.series] {
+			le := strconv.FormatFloat(bound, 'f', -1, 64)
+			if !matchesAll(leMatchers, le) {
+				continue
+			}
+			tags[HISTOGRAM_LE_LABEL] = le
+			bucketTagJson, err := json.Marshal(tags)
+			if err != nil {
+				return nil, err
+			}
+			row := newRow(sample.values, cumulativeCount(sample.buckets, bound))
+			for j, i := range otherIndexes {
+				if i == tagIndex {
+					row[j] = string(bucketTagJson)
+				}
+			}
+			expanded.Values = append(expanded.Values, row)
+		}
+		delete(tags, HISTOGRAM_LE_LABEL)
+	}
+	return expanded, nil
+}
+
+// nativeHistogramSeriesKey returns the key of the series of the sample, which is made of all columns except time
+func nativeHistogramSeriesKey(columns []interface{}, values []interface{}, otherIndexes []int) string {
+	var key strings.Builder
+	for _, i := range otherIndexes {
+		if columns[i] == PROMETHEUS_TIME_COLUMNS {
+			continue
+		}
+		fmt.Fprintf(&key, "%v\x00", values[i])
+	}
+	return key.String()
+}
+
+func matchesAll(matchers []*labels.Matcher, value string) bool {
+	for _, m := range matchers {
+		if !m.Matches(value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/trans_prometheus"
+)
+
+func TestGetNativeHistogramMetric(t *testing.T) {
+	origin, ok := trans_prometheus.ORGPrometheus["1"]
+	defer func() {
+		if ok {
+			trans_prometheus.ORGPrometheus["1"] = origin
+		} else {
+			delete(trans_prometheus.ORGPrometheus, "1")
+		}
+	}()
+	trans_prometheus.ORGPrometheus["1"] = trans_prometheus.PrometheusMap{
+		MetricNameToID: map[string]uint64{"http_duration": 1, "rpc_duration": 2, "rpc_duration_bucket": 3},
+	}
+	cases := []struct {
+		metric, histogram, suffix string
+	}{
+		{"http_duration_bucket", "http_duration", HISTOGRAM_BUCKET_SUFFIX},
+		{"http_duration_count", "http_duration", HISTOGRAM_COUNT_SUFFIX},
+		{"http_duration_sum", "http_duration", HISTOGRAM_SUM_SUFFIX},
+		{"http_duration", "", ""},
+		// classic histogram
+		{"rpc_duration_bucket", "", ""},
+		{"unknown_bucket", "", ""},
+	}
+	for _, c := range cases {
+		matchers := []*prompb.LabelMatcher{{Type: prompb.LabelMatcher_EQ, Name: PROMETHEUS_METRICS_NAME, Value: c.metric}}
+		histogram, suffix := getNativeHistogramMetric("1", matchers)
+		if histogram != c.histogram || suffix != c.suffix {
+			t.Errorf("%s: expected (%s, %s), actual (%s, %s)", c.metric, c.histogram, c.suffix, histogram, suffix)
+		}
+	}
+}
+
+func TestNativeHistogramToBuckets(t *testing.T) {
+	// schema 0: the upper bound of bucket i is 2^i
+	buckets := nativeHistogramToBuckets(0, 12, 0.5, 1, []int64{0, 1, 3}, []float64{2, 3, 4}, []int64{1}, []float64{2})
+	expected := []histogramBucket{{-1, 2}, {0.5, 3}, {1, 5}, {2, 8}, {8, 12}, {math.Inf(1), 12}}
+	if len(buckets) != len(expected) {
+		t.Fatalf("expected %v, actual %v", expected, buckets)
+	}
+	for i := range expected {
+		if buckets[i] != expected[i] {
+			t.Errorf("bucket %d expected %v, actual %v", i, expected[i], buckets[i])
+		}
+	}
+}
+
+func TestExpandNativeHistogram(t *testing.T) {
+	result := &common.Result{
+		Columns: []interface{}{PROMETHEUS_TIME_COLUMNS, "count", "sum", "schema", "zero_threshold", "zero_count",
+			"positive_bucket_indexes", "positive_bucket_counts", "negative_bucket_indexes", "negative_bucket_counts", PROMETHEUS_NATIVE_TAG_NAME},
+		Values: []interface{}{
+			[]interface{}{uint32(20), float64(5), float64(7.5), int8(0), float64(0), float64(0), []int64{0, 1}, []float64{2, 3}, []int64{}, []float64{}, `{"job":"a"}`},
+		},
+	}
+	sum, err := expandNativeHistogram(result, HISTOGRAM_SUM_SUFFIX, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sum.Columns) != 3 || len(sum.Values) != 1 || sum.Values[0].([]interface{})[2] != float64(7.5) {
+		t.Errorf("unexpected sum result %+v", sum)
+	}
+
+	matchers := []*prompb.LabelMatcher{{Type: prompb.LabelMatcher_NEQ, Name: HISTOGRAM_LE_LABEL, Value: "1"}}
+	buckets, err := expandNativeHistogram(result, HISTOGRAM_BUCKET_SUFFIX, matchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]interface{}{
+		{uint32(20), `{"job":"a","le":"2"}`, float64(5)},
+		{uint32(20), `{"job":"a","le":"+Inf"}`, float64(5)},
+	}
+	if len(buckets.Values) != len(expected) {
+		t.Fatalf("expected %v, actual %v", expected, buckets.Values)
+	}
+	for i, row := range buckets.Values {
+		for j, v := range row.([]interface{}) {
+			if v != expected[i][j] {
+				t.Errorf("row %d column %d expected %v, actual %v", i, j, expected[i][j], v)
+			}
+		}
+	}
+}
+
+func testExpandedBuckets(t *testing.T, result *common.Result, expected [][]interface{}) {
+	buckets, err := expandNativeHistogram(result, HISTOGRAM_BUCKET_SUFFIX, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets.Values) != len(expected) {
+		t.Fatalf("expected %v, actual %v", expected, buckets.Values)
+	}
+	for i, row := range buckets.Values {
+		for j, v := range row.([]interface{}) {
+			if v != expected[i][j] {
+				t.Errorf("row %d column %d expected %v, actual %v", i, j, expected[i][j], v)
+			}
+		}
+	}
+}
+
+func TestExpandNativeHistogramSparseBuckets(t *testing.T) {
+	result := &common.Result{
+		Columns: []interface{}{PROMETHEUS_TIME_COLUMNS, "count", "sum", "schema", "zero_threshold", "zero_count",
+			"positive_bucket_indexes", "positive_bucket_counts", "negative_bucket_indexes", "negative_bucket_counts", PROMETHEUS_NATIVE_TAG_NAME},
+		Values: []interface{}{
+			[]interface{}{uint32(20), float64(4), float64(9), int8(0), float64(0), float64(0), []int64{0, 2}, []float64{3, 1}, []int64{}, []float64{}, `{"job":"a"}`},
+			[]interface{}{uint32(20), float64(1), float64(2), int8(0), float64(0), float64(0), []int64{1}, []float64{1}, []int64{}, []float64{}, `{"job":"b"}`},
+			// bucket 2 is not populated yet
+			[]interface{}{uint32(10), float64(2), float64(2), int8(0), float64(0), float64(0), []int64{0}, []float64{2}, []int64{}, []float64{}, `{"job":"a"}`},
+		},
+	}
+	// every sample of a series has the same buckets, the buckets of other series are not added
+	testExpandedBuckets(t, result, [][]interface{}{
+		{uint32(20), `{"job":"a","le":"1"}`, float64(3)},
+		{uint32(20), `{"job":"a","le":"4"}`, float64(4)},
+		{uint32(20), `{"job":"a","le":"+Inf"}`, float64(4)},
+		{uint32(20), `{"job":"b","le":"2"}`, float64(1)},
+		{uint32(20), `{"job":"b","le":"+Inf"}`, float64(1)},
+		{uint32(10), `{"job":"a","le":"1"}`, float64(2)},
+		{uint32(10), `{"job":"a","le":"4"}`, float64(2)},
+		{uint32(10), `{"job":"a","le":"+Inf"}`, float64(2)},
+	})
+}
+
+func TestExpandNativeHistogramSchemaChange(t *testing.T) {
+	result := &common.Result{
+		Columns: []interface{}{PROMETHEUS_TIME_COLUMNS, "count", "sum", "schema", "zero_threshold", "zero_count",
+			"positive_bucket_indexes", "positive_bucket_counts", "negative_bucket_indexes", "negative_bucket_counts", PROMETHEUS_NATIVE_TAG_NAME},
+		Values: []interface{}{
+			// schema 0: bucket 1 is (1, 2]
+			[]interface{}{uint32(20), float64(3), float64(5), int8(0), float64(0), float64(0), []int64{1}, []float64{3}, []int64{}, []float64{}, `{"job":"a"}`},
+			// schema 1: bucket 1 is (1, √2], bucket 2 is (√2, 2]
+			[]interface{}{uint32(10), float64(2), float64(3), int8(1), float64(0), float64(0), []int64{1, 2}, []float64{1, 1}, []int64{}, []float64{}, `{"job":"a"}`},
+		},
+	}
+	// the bound √2 falls inside the bucket (1, 2] of schema 0, the count of the lower bound is used
+	sqrt2 := strconv.FormatFloat(bucketUpperBound(1, 1), 'f', -1, 64)
+	testExpandedBuckets(t, result, [][]interface{}{
+		{uint32(20), `{"job":"a","le":"` + sqrt2 + `"}`, float64(0)},
+		{uint32(20), `{"job":"a","le":"2"}`, float64(3)},
+		{uint32(20), `{"job":"a","le":"+Inf"}`, float64(3)},
+		{uint32(10), `{"job":"a","le":"` + sqrt2 + `"}`, float64(1)},
+		{uint32(10), `{"job":"a","le":"2"}`, float64(2)},
+		{uint32(10), `{"job":"a","le":"+Inf"}`, float64(2)},
+	})
+}
@@ -105,7 +105,12 @@ func (p *prometheusReader) promReaderExecute(ctx context.Context, req *prompb.Re
 	var db, datasource string
 	var debugInfo map[string]interface{}
 	log.Debugf("metric: [%s] data query range: [%d-%d]", metricName, start, end)
-	ctx, querierSql, db, datasource, metricName, err = p.promReaderTransToSQL(ctx, req, start, end, debug)
+	histogramMetric, histogramSuffix := getNativeHistogramMetric(p.orgID, req.Queries[0].Matchers)
+	if histogramMetric != "" {
+		ctx, querierSql, db, datasource, metricName, err = p.nativeHistogramTransToSQL(ctx, req, histogramMetric, start, end, debug)
+	} else {
+		ctx, querierSql, db, datasource, metricName, err = p.promReaderTransToSQL(ctx, req, start, end, debug)
+	}
 	// fmt.Println(sql, db)
 	if err != nil {
 		return nil, "", "", 0, err
@@ -150,6 +155,12 @@ func (p *prometheusReader) promReaderExecute(ctx context.Context, req *prompb.Re
 		log.Errorf("ExecuteQuery failed, debug info = %v, err info = %v", debugInfo, err)
 		return nil, "", "", 0, err
 	}
+	if histogramMetric != "" {
+		result, err = expandNativeHistogram(result, histogramSuffix, req.Queries[0].Matchers)
+		if err != nil {
+			return nil, "", "", 0, err
+		}
+	}
 
 	if debug {
 		duration = extractQueryTimeFromQueryResponse(debugInfo)
@@ -96,6 +96,26 @@ func (s *PrometheusService) PromLabelValuesService(args *model.PromMetaParams, c
 	return s.executor.getTagValues(ctx, args)
 }
 
+func (s *PrometheusService) PromLabelNamesService(args *model.PromMetaParams, ctx context.Context) (*model.PromQueryResponse, error) {
+	return s.executor.getTagNames(ctx, args)
+}
+
+func (s *PrometheusService) PromMetadataService(args *model.PromMetaParams, ctx context.Context) (*model.PromQueryResponse, error) {
+	return s.executor.metricMetadata(ctx, args)
+}
+
+func (s *PrometheusService) PromExemplarsQueryService(args *model.PromQueryParams, ctx context.Context) (*model.PromQueryResponse, error) {
+	return s.executor.queryExemplars(ctx, args)
+}
+
+func (s *PrometheusService) PromBuildInfoService() *model.PromQueryResponse {
+	return s.executor.getBuildInfo()
+}
+
+func (s *PrometheusService) PromFormatQueryService(query string) (*model.PromQueryResponse, error) {
+	return s.executor.formatQuery(query)
+}
+
 func (s *PrometheusService) PromSeriesQueryService(args *model.PromQueryParams, ctx context.Context) (*model.PromQueryResponse, error) {
 	return s.executor.series(ctx, args)
 }
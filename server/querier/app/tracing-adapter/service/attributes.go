@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/khulnasoft/deepflow/server/libs/datatype"
+	"github.com/khulnasoft/deepflow/server/querier/app/tracing-adapter/model"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// opentelemetry semantic conventions, used by jaeger and zipkin spans
+// ref: https://opentelemetry.io/docs/specs/semconv/
+const (
+	AttributeHTTPRequestMethod    = "http.request.method"
+	AttributeHTTPResponseStatus   = "http.response.status_code"
+	AttributeHTTPURL              = "http.url"
+	AttributeHTTPTarget           = "http.target"
+	AttributeHTTPRoute            = "http.route"
+	AttributeHTTPPath             = "http.path"
+	AttributeDbSystem             = "db.system"
+	AttributeDbOperation          = "db.operation"
+	AttributeRPCSystem            = "rpc.system"
+	AttributeRPCService           = "rpc.service"
+	AttributeRPCMethod            = "rpc.method"
+	AttributeRPCGRPCStatusCode    = "rpc.grpc.status_code"
+	AttributeMessagingSystem      = "messaging.system"
+	AttributeMessagingDestination = "messaging.destination"
+)
+
+// span kind names of opentracing (jaeger) and zipkin, compared in lower case
+const (
+	spanKindClient   = "client"
+	spanKindServer   = "server"
+	spanKindProducer = "producer"
+	spanKindConsumer = "consumer"
+	spanKindInternal = "internal"
+)
+
+func spanKindToOTelSpanKind(kind string) int {
+	switch strings.ToLower(kind) {
+	case spanKindClient:
+		return int(v1.Span_SPAN_KIND_CLIENT)
+	case spanKindServer:
+		return int(v1.Span_SPAN_KIND_SERVER)
+	case spanKindProducer:
+		return int(v1.Span_SPAN_KIND_PRODUCER)
+	case spanKindConsumer:
+		return int(v1.Span_SPAN_KIND_CONSUMER)
+	case spanKindInternal:
+		return int(v1.Span_SPAN_KIND_INTERNAL)
+	default:
+		return int(v1.Span_SPAN_KIND_UNSPECIFIED)
+	}
+}
+
+func spanKindToTapSide(kind string) string {
+	switch strings.ToLower(kind) {
+	case spanKindClient, spanKindProducer:
+		// client-side span
+		return "c-app"
+	case spanKindServer, spanKindConsumer:
+		// server-side span
+		return "s-app"
+	default:
+		return "app"
+	}
+}
+
+// generateSpanUniqueID converts a hex span id to the DeepFlow unique id, falls back to a hash when
+// the id is not a 64 bits hex string
+func generateSpanUniqueID(spanID string, startTimeUs int64, index int) uint64 {
+	if id, err := strconv.ParseUint(spanID, 16, 64); err == nil && id != 0 {
+		return id
+	}
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%s-%d-%d", spanID, startTimeUs, index)))
+	return h.Sum64()
+}
+
+func firstAttribute(attributes map[string]string, keys ...string) (string, bool) {
+	for _, k := range keys {
+		if v, ok := attributes[k]; ok && v != "" {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func dbSystemToL7Protocol(system string) (int, string) {
+	switch strings.ToLower(system) {
+	case "mysql", "mariadb":
+		return int(datatype.L7_PROTOCOL_MYSQL), "MySQL"
+	case "postgresql":
+		return int(datatype.L7_PROTOCOL_POSTGRE), "PostgreSQL"
+	case "redis":
+		return int(datatype.L7_PROTOCOL_REDIS), "Redis"
+	case "mongodb":
+		return int(datatype.L7_PROTOCOL_MONGODB), "MongoDB"
+	default:
+		return 0, ""
+	}
+}
+
+// attributesToSpanRequestInfo fills the request/response fields of the span from the HTTP, RPC, DB and
+// messaging attributes, both the current and the legacy opentelemetry attribute names are supported
+func attributesToSpanRequestInfo(attributes map[string]string, span *model.ExSpan) {
+	if method, ok := firstAttribute(attributes, AttributeHTTPRequestMethod, AttributeHTTPMethod); ok {
+		span.L7Protocol, span.L7ProtocolStr = int(datatype.L7_PROTOCOL_HTTP_1), "HTTP"
+		span.RequestType = method
+		if resource, ok := firstAttribute(attributes, AttributeHTTPRoute, AttributeHTTPTarget, AttributeHTTPPath, AttributeHTTPURL); ok {
+			span.RequestResource = resource
+		}
+		if status, ok := firstAttribute(attributes, AttributeHTTPResponseStatus, AttributeHTTPStatus_Code, AttributeHTTPStatusCode); ok {
+			if code, err := strconv.Atoi(status); err == nil {
+				span.ResponseStatus = code
+			}
+		}
+		return
+	}
+	if system, ok := attributes[AttributeRPCSystem]; ok {
+		if system == "grpc" {
+			span.L7Protocol, span.L7ProtocolStr = int(datatype.L7_PROTOCOL_GRPC), "gRPC"
+		}
+		span.RequestType = attributes[AttributeRPCMethod]
+		span.RequestResource = attributes[AttributeRPCService]
+		if status, ok := attributes[AttributeRPCGRPCStatusCode]; ok {
+			if code, err := strconv.Atoi(status); err == nil {
+				span.ResponseStatus = code
+			}
+		}
+		return
+	}
+	if system, ok := attributes[AttributeDbSystem]; ok {
+		span.L7Protocol, span.L7ProtocolStr = dbSystemToL7Protocol(system)
+		if operation, ok := attributes[AttributeDbOperation]; ok {
+			span.RequestType = operation
+		}
+		if statement, ok := attributes[AttributeDbStatement]; ok {
+			span.RequestResource = statement
+		}
+		return
+	}
+	if system, ok := attributes[AttributeMessagingSystem]; ok {
+		if system == "kafka" {
+			span.L7Protocol, span.L7ProtocolStr = int(datatype.L7_PROTOCOL_KAFKA), "Kafka"
+		}
+		span.RequestResource = attributes[AttributeMessagingDestination]
+	}
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/khulnasoft/deepflow/server/querier/app/tracing-adapter/common"
+	"github.com/khulnasoft/deepflow/server/querier/app/tracing-adapter/config"
+	"github.com/khulnasoft/deepflow/server/querier/app/tracing-adapter/model"
+	"github.com/mitchellh/mapstructure"
+	"github.com/op/go-logging"
+)
+
+const (
+	// jaeger-query http api, the same api used by jaeger-ui
+	// ref: https://www.jaegertracing.io/docs/latest/apis/#http-json-internal
+	jaeger_trace_url = "api/traces"
+
+	JaegerTagSpanKind = "span.kind"
+	JaegerRefChildOf  = "CHILD_OF"
+)
+
+type jaegerTraceResponse struct {
+	Data   []jaegerTrace `json:"data"`
+	Errors []struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"errors"`
+}
+
+type jaegerTrace struct {
+	TraceID   string                   `json:"traceID"`
+	Spans     []jaegerSpan             `json:"spans"`
+	Processes map[string]jaegerProcess `json:"processes"`
+}
+
+type jaegerSpan struct {
+	TraceID       string            `json:"traceID"`
+	SpanID        string            `json:"spanID"`
+	OperationName string            `json:"operationName"`
+	References    []jaegerReference `json:"references"`
+	StartTime     int64             `json:"startTime"` // microseconds
+	Duration      int64             `json:"duration"`  // microseconds
+	Tags          []jaegerKeyValue  `json:"tags"`
+	ProcessID     string            `json:"processID"`
+}
+
+type jaegerReference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type jaegerKeyValue struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type jaegerProcess struct {
+	ServiceName string           `json:"serviceName"`
+	Tags        []jaegerKeyValue `json:"tags"`
+}
+
+type jaegerConfig struct {
+	Auth        string `mapstructure:"auth"`         // basic auth
+	BearerToken string `mapstructure:"bearer_token"` // token auth, used when basic auth is not set
+	PathPrefix  string `mapstructure:"path_prefix"`  // jaeger-query `--query.base-path`
+}
+
+type JaegerAdapter struct {
+}
+
+var log_jaeger = logging.MustGetLogger("tracing-adapter.jaeger")
+
+func (j *JaegerAdapter) GetTrace(traceID string, c *config.ExternalAPM) (*model.ExTrace, error) {
+	jaegerConfig := &jaegerConfig{}
+	err := mapstructure.Decode(c.ExtraConfig, jaegerConfig)
+	if err != nil {
+		log_jaeger.Errorf("cannot decode jaeger extra config %v, err: %s", c.ExtraConfig, err)
+		return nil, err
+	}
+	traces, err := j.getTrace(traceID, c, jaegerConfig)
+	if err != nil || traces == nil {
+		return nil, err
+	}
+	return j.jaegerTracesToExTraces(traces), nil
+}
+
+func (j *JaegerAdapter) getTrace(traceID string, c *config.ExternalAPM, jaegerConfig *jaegerConfig) (*jaegerTraceResponse, error) {
+	scheme := "http"
+	if c.TLS != nil {
+		scheme = "https"
+	}
+	path := jaeger_trace_url
+	if prefix := strings.Trim(jaegerConfig.PathPrefix, "/"); prefix != "" {
+		path = prefix + "/" + path
+	}
+	result, err := common.DoRequest(http.MethodGet, fmt.Sprintf("%s://%s/%s/%s", scheme, c.Addr, path, url.PathEscape(traceID)), nil, appendAuthHeader(jaegerConfig.Auth, jaegerConfig.BearerToken), c.Timeout, c.TLS)
+	if err != nil || result == nil {
+		log_jaeger.Errorf("query jaeger trace %s at %s failed! err: %s", traceID, c.Addr, err)
+		return nil, err
+	}
+	traces, err := common.Deserialize[jaegerTraceResponse](result)
+	if err != nil || traces == nil {
+		log_jaeger.Errorf("deserialize failed! err: %s", err)
+		return nil, err
+	}
+	if len(traces.Errors) > 0 {
+		return nil, fmt.Errorf("query jaeger trace %s failed: %s", traceID, traces.Errors[0].Msg)
+	}
+	return traces, nil
+}
+
+func (j *JaegerAdapter) jaegerTracesToExTraces(traces *jaegerTraceResponse) *model.ExTrace {
+	exTrace := &model.ExTrace{}
+	exTrace.Spans = make([]model.ExSpan, 0)
+	index := 0
+	for _, trace := range traces.Data {
+		for i := range trace.Spans {
+			jaegerSpan := &trace.Spans[i]
+			process := trace.Processes[jaegerSpan.ProcessID]
+			attributes := j.jaegerTagsToAttributes(jaegerSpan.Tags)
+			spanKind := attributes[JaegerTagSpanKind]
+			span := model.ExSpan{
+				Name:            jaegerSpan.OperationName,
+				ID:              generateSpanUniqueID(jaegerSpan.SpanID, jaegerSpan.StartTime, index),
+				StartTimeUs:     jaegerSpan.StartTime,
+				EndTimeUs:       jaegerSpan.StartTime + jaegerSpan.Duration,
+				TapSide:         spanKindToTapSide(spanKind),
+				TraceID:         jaegerSpan.TraceID,
+				SpanID:          jaegerSpan.SpanID,
+				ParentSpanID:    j.jaegerReferencesToParentSpanID(jaegerSpan.References),
+				SpanKind:        spanKindToOTelSpanKind(spanKind),
+				Endpoint:        jaegerSpan.OperationName,
+				AppService:      process.ServiceName, // service name
+				AppInstance:     j.jaegerProcessToInstance(&process),
+				ServiceUname:    process.ServiceName,
+				RequestResource: jaegerSpan.OperationName, // maybe overwrite by tags
+				SignalSource:    model.L7_FLOW_SIGNAL_SOURCE_OTEL,
+				Attribute:       attributes,
+			}
+			attributesToSpanRequestInfo(attributes, &span)
+			exTrace.Spans = append(exTrace.Spans, span)
+			index++
+		}
+	}
+	return exTrace
+}
+
+func (j *JaegerAdapter) jaegerReferencesToParentSpanID(refs []jaegerReference) string {
+	// prefer CHILD_OF, a span only have ONE parent in DeepFlow
+	for _, ref := range refs {
+		if ref.RefType == JaegerRefChildOf {
+			return ref.SpanID
+		}
+	}
+	if len(refs) > 0 {
+		return refs[0].SpanID
+	}
+	return ""
+}
+
+func (j *JaegerAdapter) jaegerProcessToInstance(process *jaegerProcess) string {
+	for _, tag := range process.Tags {
+		switch tag.Key {
+		case "hostname", "ip", "host.name":
+			return fmt.Sprint(tag.Value)
+		}
+	}
+	return ""
+}
+
+func (j *JaegerAdapter) jaegerTagsToAttributes(tags []jaegerKeyValue) map[string]string {
+	attr := make(map[string]string, len(tags))
+	for _, v := range tags {
+		switch value := v.Value.(type) {
+		case float64:
+			// json numbers are decoded as float64, keep integers without decimals
+			if value == float64(int64(value)) {
+				attr[v.Key] = fmt.Sprintf("%d", int64(value))
+			} else {
+				attr[v.Key] = fmt.Sprint(value)
+			}
+		default:
+			attr[v.Key] = fmt.Sprint(value)
+		}
+	}
+	return attr
+}
+
+func appendAuthHeader(auth, bearerToken string) map[string]string {
+	header := common.DefaultContentTypeHeader()
+	if auth != "" {
+		header["Authorization"] = fmt.Sprintf("Basic %s", auth)
+	} else if bearerToken != "" {
+		header["Authorization"] = fmt.Sprintf("Bearer %s", bearerToken)
+	}
+	return header
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/querier/app/tracing-adapter/common"
+	. "github.com/smartystreets/goconvey/convey"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+var jaeger_mock_data = `{
+"data": [
+    {
+        "traceID": "5b8aa5a2d2c872e8321cf37308d69df2",
+        "spans": [
+            {
+                "traceID": "5b8aa5a2d2c872e8321cf37308d69df2",
+                "spanID": "051581bf3cb55c13",
+                "operationName": "HTTP GET /dispatch",
+                "references": [],
+                "startTime": 1694428678774000,
+                "duration": 53000,
+                "tags": [
+                    {"key": "span.kind", "type": "string", "value": "server"},
+                    {"key": "http.method", "type": "string", "value": "GET"},
+                    {"key": "http.url", "type": "string", "value": "/dispatch?customer=123"},
+                    {"key": "http.status_code", "type": "int64", "value": 200}
+                ],
+                "processID": "p1"
+            },
+            {
+                "traceID": "5b8aa5a2d2c872e8321cf37308d69df2",
+                "spanID": "5ff4a8a0d9a9c3b2",
+                "operationName": "SQL SELECT",
+                "references": [
+                    {"refType": "CHILD_OF", "traceID": "5b8aa5a2d2c872e8321cf37308d69df2", "spanID": "051581bf3cb55c13"}
+                ],
+                "startTime": 1694428678780000,
+                "duration": 30000,
+                "tags": [
+                    {"key": "span.kind", "type": "string", "value": "client"},
+                    {"key": "db.system", "type": "string", "value": "mysql"},
+                    {"key": "db.statement", "type": "string", "value": "SELECT * FROM customer WHERE customer_id=123"}
+                ],
+                "processID": "p2"
+            }
+        ],
+        "processes": {
+            "p1": {
+                "serviceName": "frontend",
+                "tags": [{"key": "hostname", "type": "string", "value": "frontend-0"}]
+            },
+            "p2": {
+                "serviceName": "mysql",
+                "tags": [{"key": "ip", "type": "string", "value": "10.1.2.3"}]
+            }
+        }
+    }
+],
+"errors": null
+}`
+
+var jaeger_mock_not_found = `{
+"data": null,
+"errors": [{"code": 404, "msg": "trace not found"}]
+}`
+
+func TestGetJaegerTrace(t *testing.T) {
+	jaegerAdapter := &JaegerAdapter{}
+	Convey("TestGetJaegerTrace_Success", t, func() {
+		traces, err := common.Deserialize[jaegerTraceResponse]([]byte(jaeger_mock_data))
+		So(err, ShouldBeNil)
+		So(len(traces.Data), ShouldEqual, 1)
+		result := jaegerAdapter.jaegerTracesToExTraces(traces)
+		So(result, ShouldNotBeNil)
+		So(len(result.Spans), ShouldEqual, 2)
+
+		server := result.Spans[0]
+		So(server.ID, ShouldEqual, 0x051581bf3cb55c13)
+		So(server.TraceID, ShouldEqual, "5b8aa5a2d2c872e8321cf37308d69df2")
+		So(server.ParentSpanID, ShouldEqual, "")
+		So(server.EndTimeUs-server.StartTimeUs, ShouldEqual, 53000)
+		So(server.TapSide, ShouldEqual, "s-app")
+		So(server.SpanKind, ShouldEqual, int(v1.Span_SPAN_KIND_SERVER))
+		So(server.AppService, ShouldEqual, "frontend")
+		So(server.AppInstance, ShouldEqual, "frontend-0")
+		So(server.L7ProtocolStr, ShouldEqual, "HTTP")
+		So(server.RequestType, ShouldEqual, "GET")
+		So(server.RequestResource, ShouldEqual, "/dispatch?customer=123")
+		So(server.ResponseStatus, ShouldEqual, 200)
+		So(server.Attribute["http.status_code"], ShouldEqual, "200")
+
+		client := result.Spans[1]
+		So(client.ParentSpanID, ShouldEqual, server.SpanID)
+		So(client.TapSide, ShouldEqual, "c-app")
+		So(client.AppInstance, ShouldEqual, "10.1.2.3")
+		So(client.L7ProtocolStr, ShouldEqual, "MySQL")
+		So(client.RequestResource, ShouldEqual, "SELECT * FROM customer WHERE customer_id=123")
+	})
+
+	Convey("TestGetJaegerTrace_NotFound", t, func() {
+		traces, err := common.Deserialize[jaegerTraceResponse]([]byte(jaeger_mock_not_found))
+		So(err, ShouldBeNil)
+		So(len(traces.Errors), ShouldEqual, 1)
+		result := jaegerAdapter.jaegerTracesToExTraces(traces)
+		So(result, ShouldNotBeNil)
+		So(len(result.Spans), ShouldEqual, 0)
+	})
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/khulnasoft/deepflow/server/querier/app/tracing-adapter/common"
+	"github.com/khulnasoft/deepflow/server/querier/app/tracing-adapter/config"
+	"github.com/khulnasoft/deepflow/server/querier/app/tracing-adapter/model"
+	"github.com/mitchellh/mapstructure"
+	"github.com/op/go-logging"
+)
+
+const (
+	// zipkin v2 api
+	// ref: https://zipkin.io/zipkin-api/#/default/get_trace__traceId_
+	zipkin_trace_url = "api/v2/trace"
+)
+
+type zipkinSpan struct {
+	TraceID        string            `json:"traceId"`
+	ID             string            `json:"id"`
+	ParentID       string            `json:"parentId"`
+	Name           string            `json:"name"`
+	Timestamp      int64             `json:"timestamp"` // microseconds
+	Duration       int64             `json:"duration"`  // microseconds
+	Kind           string            `json:"kind"`      // CLIENT/SERVER/PRODUCER/CONSUMER, empty for local spans
+	LocalEndpoint  *zipkinEndpoint   `json:"localEndpoint"`
+	RemoteEndpoint *zipkinEndpoint   `json:"remoteEndpoint"`
+	Tags           map[string]string `json:"tags"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int    `json:"port"`
+}
+
+type zipkinConfig struct {
+	Auth        string `mapstructure:"auth"`         // basic auth
+	BearerToken string `mapstructure:"bearer_token"` // token auth, used when basic auth is not set
+	PathPrefix  string `mapstructure:"path_prefix"`  // zipkin server behind a reverse proxy
+}
+
+type ZipkinAdapter struct {
+}
+
+var log_zipkin = logging.MustGetLogger("tracing-adapter.zipkin")
+
+func (z *ZipkinAdapter) GetTrace(traceID string, c *config.ExternalAPM) (*model.ExTrace, error) {
+	zipkinConfig := &zipkinConfig{}
+	err := mapstructure.Decode(c.ExtraConfig, zipkinConfig)
+	if err != nil {
+		log_zipkin.Errorf("cannot decode zipkin extra config %v, err: %s", c.ExtraConfig, err)
+		return nil, err
+	}
+	spans, err := z.getTrace(traceID, c, zipkinConfig)
+	if err != nil || spans == nil {
+		return nil, err
+	}
+	return z.zipkinSpansToExTraces(*spans), nil
+}
+
+func (z *ZipkinAdapter) getTrace(traceID string, c *config.ExternalAPM, zipkinConfig *zipkinConfig) (*[]zipkinSpan, error) {
+	scheme := "http"
+	if c.TLS != nil {
+		scheme = "https"
+	}
+	path := zipkin_trace_url
+	if prefix := strings.Trim(zipkinConfig.PathPrefix, "/"); prefix != "" {
+		path = prefix + "/" + path
+	}
+	result, err := common.DoRequest(http.MethodGet, fmt.Sprintf("%s://%s/%s/%s", scheme, c.Addr, path, url.PathEscape(traceID)), nil, appendAuthHeader(zipkinConfig.Auth, zipkinConfig.BearerToken), c.Timeout, c.TLS)
+	if err != nil || result == nil {
+		log_zipkin.Errorf("query zipkin trace %s at %s failed! err: %s", traceID, c.Addr, err)
+		return nil, err
+	}
+	spans, err := common.Deserialize[[]zipkinSpan](result)
+	if err != nil || spans == nil {
+		log_zipkin.Errorf("deserialize failed! err: %s", err)
+		return nil, err
+	}
+	return spans, nil
+}
+
+func (z *ZipkinAdapter) zipkinSpansToExTraces(spans []zipkinSpan) *model.ExTrace {
+	exTrace := &model.ExTrace{}
+	exTrace.Spans = make([]model.ExSpan, 0, len(spans))
+	spanIDCount := make(map[string]int, len(spans))
+	for i := range spans {
+		spanIDCount[spans[i].ID]++
+	}
+	for i := range spans {
+		zipkinSpan := &spans[i]
+		serviceName, instance := z.zipkinEndpointToService(zipkinSpan.LocalEndpoint)
+		attributes := zipkinSpan.Tags
+		if attributes == nil {
+			attributes = make(map[string]string)
+		}
+		span := model.ExSpan{
+			Name:            zipkinSpan.Name,
+			ID:              z.zipkinSpanUniqueID(zipkinSpan, spanIDCount[zipkinSpan.ID] > 1, i),
+			StartTimeUs:     zipkinSpan.Timestamp,
+			EndTimeUs:       zipkinSpan.Timestamp + zipkinSpan.Duration,
+			TapSide:         spanKindToTapSide(zipkinSpan.Kind),
+			TraceID:         zipkinSpan.TraceID,
+			SpanID:          zipkinSpan.ID,
+			ParentSpanID:    zipkinSpan.ParentID,
+			SpanKind:        spanKindToOTelSpanKind(zipkinSpan.Kind),
+			Endpoint:        zipkinSpan.Name,
+			AppService:      serviceName, // service name
+			AppInstance:     instance,
+			ServiceUname:    serviceName,
+			RequestResource: zipkinSpan.Name, // maybe overwrite by tags
+			SignalSource:    model.L7_FLOW_SIGNAL_SOURCE_OTEL,
+			Attribute:       attributes,
+		}
+		attributesToSpanRequestInfo(attributes, &span)
+		exTrace.Spans = append(exTrace.Spans, span)
+	}
+	return exTrace
+}
+
+// zipkinSpanUniqueID returns the unique id of the span, the client and server sides of a shared span have the same
+// span id and may have the same timestamp, so the kind and local endpoint are added to tell them apart
+func (z *ZipkinAdapter) zipkinSpanUniqueID(span *zipkinSpan, shared bool, index int) uint64 {
+	if !shared {
+		return generateSpanUniqueID(span.ID, span.Timestamp, index)
+	}
+	serviceName, instance := z.zipkinEndpointToService(span.LocalEndpoint)
+	return generateSpanUniqueID(fmt.Sprintf("%s-%s-%s-%s", span.ID, span.Kind, serviceName, instance), span.Timestamp, index)
+}
+
+func (z *ZipkinAdapter) zipkinEndpointToService(endpoint *zipkinEndpoint) (string, string) {
+	if endpoint == nil {
+		return "", ""
+	}
+	instance := endpoint.IPv4
+	if instance == "" {
+		instance = endpoint.IPv6
+	}
+	return endpoint.ServiceName, instance
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/querier/app/tracing-adapter/common"
+	. "github.com/smartystreets/goconvey/convey"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+var zipkin_mock_data = `[
+    {
+        "traceId": "5af7183fb1d4cf5f",
+        "id": "5af7183fb1d4cf5f",
+        "name": "get /api",
+        "timestamp": 1694428678774000,
+        "duration": 53000,
+        "kind": "SERVER",
+        "localEndpoint": {"serviceName": "frontend", "ipv4": "172.17.0.13"},
+        "remoteEndpoint": {"ipv4": "10.0.0.1", "port": 58648},
+        "tags": {
+            "http.method": "GET",
+            "http.path": "/api",
+            "http.status_code": "500"
+        }
+    },
+    {
+        "traceId": "5af7183fb1d4cf5f",
+        "parentId": "5af7183fb1d4cf5f",
+        "id": "352bff9a74ca9ad2",
+        "name": "/grpc.health.v1.Health/Check",
+        "timestamp": 1694428678780000,
+        "duration": 20000,
+        "kind": "CLIENT",
+        "localEndpoint": {"serviceName": "frontend", "ipv6": "fe80::1"},
+        "tags": {
+            "rpc.system": "grpc",
+            "rpc.service": "grpc.health.v1.Health",
+            "rpc.method": "Check"
+        }
+    },
+    {
+        "traceId": "5af7183fb1d4cf5f",
+        "parentId": "5af7183fb1d4cf5f",
+        "id": "not-a-hex-id",
+        "name": "render",
+        "timestamp": 1694428678800000,
+        "duration": 1000
+    }
+]`
+
+func TestGetZipkinTrace(t *testing.T) {
+	zipkinAdapter := &ZipkinAdapter{}
+	Convey("TestGetZipkinTrace_Success", t, func() {
+		spans, err := common.Deserialize[[]zipkinSpan]([]byte(zipkin_mock_data))
+		So(err, ShouldBeNil)
+		So(len(*spans), ShouldEqual, 3)
+		result := zipkinAdapter.zipkinSpansToExTraces(*spans)
+		So(result, ShouldNotBeNil)
+		So(len(result.Spans), ShouldEqual, 3)
+
+		server := result.Spans[0]
+		So(server.ID, ShouldEqual, 0x5af7183fb1d4cf5f)
+		So(server.ParentSpanID, ShouldEqual, "")
+		So(server.TapSide, ShouldEqual, "s-app")
+		So(server.SpanKind, ShouldEqual, int(v1.Span_SPAN_KIND_SERVER))
+		So(server.AppService, ShouldEqual, "frontend")
+		So(server.AppInstance, ShouldEqual, "172.17.0.13")
+		So(server.RequestType, ShouldEqual, "GET")
+		So(server.RequestResource, ShouldEqual, "/api")
+		So(server.ResponseStatus, ShouldEqual, 500)
+
+		client := result.Spans[1]
+		So(client.ParentSpanID, ShouldEqual, server.SpanID)
+		So(client.TapSide, ShouldEqual, "c-app")
+		So(client.AppInstance, ShouldEqual, "fe80::1")
+		So(client.L7ProtocolStr, ShouldEqual, "gRPC")
+		So(client.RequestType, ShouldEqual, "Check")
+		So(client.RequestResource, ShouldEqual, "grpc.health.v1.Health")
+
+		local := result.Spans[2]
+		So(local.ID, ShouldBeGreaterThan, 0)
+		So(local.TapSide, ShouldEqual, "app")
+		So(local.SpanKind, ShouldEqual, int(v1.Span_SPAN_KIND_UNSPECIFIED))
+		So(local.AppService, ShouldEqual, "")
+		So(local.RequestResource, ShouldEqual, "render")
+		So(local.Attribute, ShouldNotBeNil)
+	})
+}
+
+const zipkin_shared_span_mock_data = `
+[
+    {
+        "traceId": "5af7183fb1d4cf5f",
+        "id": "6b221d5bc9e6496c",
+        "name": "get /api",
+        "timestamp": 1694428678750000,
+        "duration": 53000,
+        "kind": "CLIENT",
+        "localEndpoint": {"serviceName": "frontend", "ipv4": "172.17.0.13"}
+    },
+    {
+        "traceId": "5af7183fb1d4cf5f",
+        "id": "6b221d5bc9e6496c",
+        "name": "get /api",
+        "timestamp": 1694428678750000,
+        "duration": 50000,
+        "kind": "SERVER",
+        "shared": true,
+        "localEndpoint": {"serviceName": "backend", "ipv4": "172.17.0.14"}
+    }
+]`
+
+func TestGetZipkinTraceSharedSpan(t *testing.T) {
+	zipkinAdapter := &ZipkinAdapter{}
+	Convey("TestGetZipkinTraceSharedSpan_Success", t, func() {
+		spans, err := common.Deserialize[[]zipkinSpan]([]byte(zipkin_shared_span_mock_data))
+		So(err, ShouldBeNil)
+		result := zipkinAdapter.zipkinSpansToExTraces(*spans)
+		So(len(result.Spans), ShouldEqual, 2)
+
+		client, server := result.Spans[0], result.Spans[1]
+		So(client.SpanID, ShouldEqual, server.SpanID)
+		So(client.ID, ShouldNotEqual, server.ID)
+		So(client.TapSide, ShouldEqual, "c-app")
+		So(server.TapSide, ShouldEqual, "s-app")
+		So(server.AppService, ShouldEqual, "backend")
+	})
+}
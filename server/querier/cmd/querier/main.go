@@ -17,6 +17,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"strings"
@@ -42,5 +43,6 @@ func main() {
 		logger.EnableStdoutLog()
 	}
 	shared := common.NewControllerIngesterShared()
-	querier.Start(*configPath, "", shared)
+	// the standalone querier runs without controller election
+	querier.Start(context.Background(), *configPath, "", shared, nil)
 }
@@ -34,6 +34,7 @@ type QuerierParams struct {
 	NoPreWhere    bool
 	ORGID         string
 	SimpleSql     bool
+	ResultWriter  ResultWriter // stream the result to the writer if not nil
 }
 
 type TempoParams struct {
@@ -45,8 +46,10 @@ type TempoParams struct {
 	MaxDuration string
 	Limit       string
 	Debug       string
+	Query       string // TraceQL
 	Filters     []*KeyValue
 	Context     context.Context
+	ORGID       string
 }
 
 func (p *TempoParams) SetFilters(filterStr string) {
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+type queryProgressKey struct{}
+
+// QueryProgress accumulates the progress of all the ClickHouse queries executed for one querier query,
+// each ClickHouse query gets the query id '<QueryUUID>-<index>' so that they can be killed by the QueryUUID
+type QueryProgress struct {
+	QueryUUID string
+
+	queryIndex      uint64
+	readRows        uint64
+	readBytes       uint64
+	totalRowsToRead uint64
+}
+
+func NewQueryProgress(queryUUID string) *QueryProgress {
+	return &QueryProgress{QueryUUID: queryUUID}
+}
+
+func (p *QueryProgress) NextQueryID() string {
+	return fmt.Sprintf("%s-%d", p.QueryUUID, atomic.AddUint64(&p.queryIndex, 1))
+}
+
+// Add accumulates a ClickHouse progress packet, the values in the packet are increments
+func (p *QueryProgress) Add(readRows, readBytes, totalRowsToRead uint64) {
+	atomic.AddUint64(&p.readRows, readRows)
+	atomic.AddUint64(&p.readBytes, readBytes)
+	atomic.AddUint64(&p.totalRowsToRead, totalRowsToRead)
+}
+
+func (p *QueryProgress) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"query_count":        atomic.LoadUint64(&p.queryIndex),
+		"read_rows":          atomic.LoadUint64(&p.readRows),
+		"read_bytes":         atomic.LoadUint64(&p.readBytes),
+		"total_rows_to_read": atomic.LoadUint64(&p.totalRowsToRead),
+	}
+}
+
+func ContextWithQueryProgress(ctx context.Context, p *QueryProgress) context.Context {
+	return context.WithValue(ctx, queryProgressKey{}, p)
+}
+
+func GetQueryProgress(ctx context.Context) *QueryProgress {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(queryProgressKey{}).(*QueryProgress)
+	return p
+}
@@ -62,6 +62,7 @@ type QuerierConfig struct {
 	MaxPrometheusIdSubqueryLruEntry int                           `default:"8000" yaml:"max-prometheus-id-subquery-lru-entry"`
 	PrometheusIdSubqueryLruTimeout  int                           `default:"60" yaml:"prometheus-id-subquery-lru-timeout"`
 	AutoCustomTags                  []AutoCustomTags              `yaml:"auto-custom-tags" binding:"omitempty,dive"`
+	AsyncQuery                      AsyncQuery                    `yaml:"async-query"`
 }
 
 type DeepflowApp struct {
@@ -89,7 +90,17 @@ type Clickhouse struct {
 	Timeout        int    `default:"60" yaml:"timeout"`
 	ConnectTimeout int    `default:"2" yaml:"connect-timeout"`
 	MaxConnection  int    `default:"20" yaml:"max-connection"`
+	ClusterName    string `default:"df_cluster" yaml:"cluster-name"`
 }
+
+type AsyncQuery struct {
+	MaxConcurrencyPerOrg int    `default:"4" yaml:"max-concurrency-per-org"`
+	MaxQueries           int    `default:"1000" yaml:"max-queries"`
+	ResultTTL            int    `default:"600" yaml:"result-ttl"`
+	MaxPageSize          int    `default:"10000" yaml:"max-page-size"`
+	AdvertiseIP          string `default:"" yaml:"advertise-ip"`
+}
+
 type AutoCustomTags struct {
 	TagName     string   `default:"" yaml:"tag-name"`
 	TagFields   []string `yaml:"tag-fields" binding:"omitempty,dive"`
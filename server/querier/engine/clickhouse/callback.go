@@ -34,6 +34,9 @@ import (
 
 var TIME_FILL_LIMIT_DEFAULT = 20
 
+// TimeFill needs the whole result, so the results with this callback can not be streamed
+const TIME_FILL_CALLBACK_COLUMN = "time"
+
 type Callback struct {
 	Args     []interface{}
 	Function func([]interface{}) func(*common.Result) error
@@ -216,6 +219,10 @@ func MacTranslate(args []interface{}) func(result *common.Result) error {
 			}
 		}
 		copy(newValues, result.Values)
+		// the translated values are strings, e.g. for the arrow output which decides the field types by the schemas
+		if macIndex < len(result.Schemas) && result.Schemas[macIndex] != nil {
+			result.Schemas[macIndex].ValueType = "String"
+		}
 		for i, newValue := range newValues {
 			newValueSlice := newValue.([]interface{})
 			switch newValueSlice[macIndex].(type) {
@@ -232,6 +232,9 @@ func (e *CHEngine) ExecuteQuery(args *common.QuerierParams) (*common.Result, map
 		}
 		if !isShow {
 			params.Callbacks = callbacks
+			if _, ok := callbacks[TIME_FILL_CALLBACK_COLUMN]; !ok {
+				params.ResultWriter = args.ResultWriter
+			}
 		}
 		result, err := chClient.DoQuery(params)
 		if err != nil {
@@ -1165,7 +1168,11 @@ func (e *CHEngine) TransFrom(froms sqlparser.TableExprs) error {
 			} else if strings.Contains(table, "vtap_acl") {
 				table = strings.ReplaceAll(table, "vtap_acl", "traffic_policy")
 			}
-			e.Table = table
+			if e.DB == chCommon.DB_NAME_PROMETHEUS {
+				table, e.Table = GetPrometheusTable(table)
+			} else {
+				e.Table = table
+			}
 			// ext_metrics只有metrics表，使用virtual_table_name做过滤区分
 			if e.DB == "ext_metrics" {
 				table = "metrics"
@@ -1182,7 +1189,6 @@ func (e *CHEngine) TransFrom(froms sqlparser.TableExprs) error {
 				filter := view.Filters{Expr: metricIDFilter}
 				whereStmt.filter = &filter
 				e.Statements = append(e.Statements, &whereStmt)
-				table = "samples"
 			}
 			interval, err := chCommon.GetDatasourceInterval(e.DB, e.Table, e.DataSource, e.ORGID)
 			if err != nil {
@@ -33,7 +33,9 @@ import (
 	"github.com/khulnasoft/deepflow/server/querier/common"
 	"github.com/khulnasoft/deepflow/server/querier/config"
 	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/client"
+	chCommon "github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/common"
 	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/metrics"
+	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/trans_prometheus"
 	"github.com/khulnasoft/deepflow/server/querier/parse"
 )
 
@@ -696,6 +698,44 @@ func Load() error {
 	return nil
 }
 
+func TestPrometheusTable(t *testing.T) {
+	Load()
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	mockDatasources()
+	origin, ok := trans_prometheus.ORGPrometheus["1"]
+	defer func() {
+		if ok {
+			trans_prometheus.ORGPrometheus["1"] = origin
+		} else {
+			delete(trans_prometheus.ORGPrometheus, "1")
+		}
+	}()
+	trans_prometheus.ORGPrometheus["1"] = trans_prometheus.PrometheusMap{
+		MetricNameToID: map[string]uint64{"http_duration": 5},
+	}
+
+	cases := []struct {
+		input, output string
+	}{
+		{"SELECT value FROM http_duration", "FROM prometheus.`samples` PREWHERE (metric_id=5)"},
+		{"SELECT count, sum FROM `histograms.http_duration`", "FROM prometheus.`histograms` PREWHERE (metric_id=5)"},
+		{"SELECT value, trace_id FROM `exemplars.http_duration`", "FROM prometheus.`exemplars` PREWHERE (metric_id=5)"},
+	}
+	for _, c := range cases {
+		e := CHEngine{DB: chCommon.DB_NAME_PROMETHEUS, ORGID: "1"}
+		e.Context = context.Background()
+		e.Init()
+		parser := parse.Parser{Engine: &e}
+		if err := parser.ParseSQL(c.input); err != nil {
+			t.Fatal(err)
+		}
+		if out := parser.Engine.ToSQLString(); !strings.Contains(out, c.output) {
+			t.Errorf("%s: expected %s, actual %s", c.input, c.output, out)
+		}
+	}
+}
+
 func mockDatasources() {
 	httpmock.RegisterResponder(
 		"GET", "http://localhost:20417/v1/data-sources/",
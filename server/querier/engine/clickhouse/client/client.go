@@ -46,6 +46,7 @@ type QueryParams struct {
 	ColumnSchemaMap map[string]*common.ColumnSchema
 	ORGID           string
 	SimpleSql       bool
+	ResultWriter    common.ResultWriter // stream the rows in batches instead of returning them in the result
 }
 
 // All ClickHouse Client share one connection
@@ -106,6 +107,27 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// KillQuery kills all the ClickHouse queries whose query id starts with the query_uuid, the queries may run on
+// any node of the cluster, e.g. the local queries of a distributed table
+func (c *Client) KillQuery(query_uuid, cluster string) error {
+	err := c.init(query_uuid)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	ctx := c.Context
+	if c.Context == nil {
+		ctx = context.Background()
+	}
+	sqlstr := getKillQuerySQL(query_uuid, cluster)
+	if err = c.connection.Exec(ctx, sqlstr); err != nil {
+		log.Errorf("kill clickhouse query Error: %s, sql: %s, query_uuid: %s", err, sqlstr, query_uuid)
+		return err
+	}
+	log.Infof("query_uuid: %s. killed clickhouse queries", query_uuid)
+	return nil
+}
+
 func (c *Client) DoQuery(params *QueryParams) (result *common.Result, err error) {
 	sqlstr, callbacks, query_uuid, columnSchemaMap, simpleSql := params.Sql, params.Callbacks, params.QueryUUID, params.ColumnSchemaMap, params.SimpleSql
 	queryCacheStr := ""
@@ -135,6 +157,15 @@ func (c *Client) DoQuery(params *QueryParams) (result *common.Result, err error)
 	if c.Context == nil {
 		ctx = context.Background()
 	}
+	// async queries track the progress and kill the queries by the query id
+	if progress := common.GetQueryProgress(ctx); progress != nil {
+		ctx = clickhouse.Context(ctx,
+			clickhouse.WithQueryID(progress.NextQueryID()),
+			clickhouse.WithProgress(func(p *clickhouse.Progress) {
+				progress.Add(p.Rows, p.Bytes, p.TotalRows)
+			}),
+		)
+	}
 	rows, err := c.connection.Query(ctx, sqlstr)
 	c.Debug.Sql = sqlstr
 	if err != nil {
@@ -162,7 +193,12 @@ func (c *Client) DoQuery(params *QueryParams) (result *common.Result, err error)
 		columnValues[i] = reflect.New(columns[i].ScanType()).Interface()
 		columnSchemas[i].ValueType = columns[i].DatabaseTypeName()
 	}
+	var stream *resultStream
+	if params.ResultWriter != nil {
+		stream = newResultStream(params.ResultWriter, columnNames, columnSchemas, callbacks)
+	}
 	resSize := 0
+	resRows := 0
 	for rows.Next() {
 		if err := rows.Scan(columnValues...); err != nil {
 			c.Debug.Error = fmt.Sprintf("%s", err)
@@ -175,6 +211,15 @@ func (c *Client) DoQuery(params *QueryParams) (result *common.Result, err error)
 			record = append(record, value)
 		}
 		values = append(values, record)
+		resRows++
+		if stream != nil && len(values) >= STREAM_BATCH_SIZE {
+			if err := stream.write(values); err != nil {
+				log.Errorf("write result Error: %s, sql: %s, query_uuid: %s", err, sqlstr, c.Debug.QueryUUID)
+				c.Debug.Error = fmt.Sprintf("%s", err)
+				return nil, err
+			}
+			values = nil
+		}
 	}
 	// Even if the query operation produces an error, it does not necessarily return an error in the'err 'parameter,
 	// so the return value of the'rows. Err () ' method must be checked to ensure that the query operation is successful
@@ -183,8 +228,14 @@ func (c *Client) DoQuery(params *QueryParams) (result *common.Result, err error)
 		c.Debug.Error = fmt.Sprintf("%s", err)
 		return nil, err
 	}
+	if stream != nil {
+		if err := stream.write(values); err != nil {
+			log.Errorf("write result Error: %s, sql: %s, query_uuid: %s", err, sqlstr, c.Debug.QueryUUID)
+			c.Debug.Error = fmt.Sprintf("%s", err)
+			return nil, err
+		}
+	}
 	queryTime := time.Since(start)
-	resRows := len(values)
 	statsd.QuerierCounter.WriteCk(
 		&statsd.ClickhouseCounter{
 			ResponseSize: uint64(resSize),
@@ -194,6 +245,11 @@ func (c *Client) DoQuery(params *QueryParams) (result *common.Result, err error)
 		},
 	)
 	c.Debug.QueryTime = fmt.Sprintf("%.9fs", float64(queryTime)/1e9)
+	if stream != nil {
+		// the callbacks have been applied to each batch
+		log.Infof("query_uuid: %s. query api statistics: %d rows, %d columns, %d bytes streamed, cost %f ms", c.Debug.QueryUUID, resRows, resColumns, resSize, float64(queryTime.Milliseconds()))
+		return &common.Result{Columns: stream.columns, Schemas: columnSchemas}, nil
+	}
 	result = &common.Result{
 		Columns: columnNames,
 		Values:  values,
@@ -209,3 +265,11 @@ func (c *Client) DoQuery(params *QueryParams) (result *common.Result, err error)
 	log.Infof("query_uuid: %s. query api statistics: %d rows, %d columns, %d bytes, cost %f ms", c.Debug.QueryUUID, resRows, resColumns, resSize, float64(queryTime.Milliseconds()))
 	return result, nil
 }
+
+func getKillQuerySQL(queryUUID, cluster string) string {
+	onCluster := ""
+	if cluster != "" {
+		onCluster = fmt.Sprintf(" ON CLUSTER `%s`", strings.ReplaceAll(cluster, "`", ""))
+	}
+	return fmt.Sprintf("KILL QUERY%s WHERE startsWith(query_id, '%s') ASYNC", onCluster, strings.NewReplacer("\\", "\\\\", "'", "\\'").Replace(queryUUID))
+}
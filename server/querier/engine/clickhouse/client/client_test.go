@@ -0,0 +1,36 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import "testing"
+
+func TestGetKillQuerySQL(t *testing.T) {
+	cases := []struct {
+		queryUUID string
+		cluster   string
+		expected  string
+	}{
+		{"q1", "", "KILL QUERY WHERE startsWith(query_id, 'q1') ASYNC"},
+		{"q1", "df_cluster", "KILL QUERY ON CLUSTER `df_cluster` WHERE startsWith(query_id, 'q1') ASYNC"},
+		{"q'1", "df`cluster", "KILL QUERY ON CLUSTER `dfcluster` WHERE startsWith(query_id, 'q\\'1') ASYNC"},
+	}
+	for _, c := range cases {
+		if sql := getKillQuerySQL(c.queryUUID, c.cluster); sql != c.expected {
+			t.Errorf("getKillQuerySQL(%s, %s) = %s, expected %s", c.queryUUID, c.cluster, sql, c.expected)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"github.com/khulnasoft/deepflow/server/querier/common"
+)
+
+const STREAM_BATCH_SIZE = 1024
+
+type resultStream struct {
+	writer        common.ResultWriter
+	rawColumns    []interface{}
+	columns       []interface{}
+	schemas       common.ColumnSchemas
+	callbacks     map[string]func(*common.Result) error
+	headerWritten bool
+}
+
+func newResultStream(writer common.ResultWriter, columns []interface{}, schemas common.ColumnSchemas, callbacks map[string]func(*common.Result) error) *resultStream {
+	return &resultStream{
+		writer:     writer,
+		rawColumns: columns,
+		columns:    columns,
+		schemas:    schemas,
+		callbacks:  callbacks,
+	}
+}
+
+// write applies the callbacks to the batch and writes it, the callbacks must only depend on the rows of the batch,
+// the header is written with the columns returned by the callbacks of the first batch
+func (s *resultStream) write(values []interface{}) error {
+	if len(values) == 0 && s.headerWritten {
+		return nil
+	}
+	batch := &common.Result{
+		Columns: s.rawColumns,
+		Values:  values,
+		Schemas: s.schemas,
+	}
+	for _, callback := range s.callbacks {
+		if err := callback(batch); err != nil {
+			log.Errorf("Execute Callback %v Error: %v", callback, err)
+		}
+	}
+	if !s.headerWritten {
+		s.columns = batch.Columns
+		if err := s.writer.WriteHeader(batch.Columns, batch.Schemas); err != nil {
+			return err
+		}
+		s.headerWritten = true
+	}
+	if len(batch.Values) == 0 {
+		return nil
+	}
+	return s.writer.WriteRows(batch.Values)
+}
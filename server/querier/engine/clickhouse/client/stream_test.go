@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+)
+
+type testResultWriter struct {
+	headers [][]interface{}
+	batches [][]interface{}
+}
+
+func (w *testResultWriter) WriteHeader(columns []interface{}, schemas common.ColumnSchemas) error {
+	w.headers = append(w.headers, columns)
+	return nil
+}
+
+func (w *testResultWriter) WriteRows(values []interface{}) error {
+	w.batches = append(w.batches, values)
+	return nil
+}
+
+func (w *testResultWriter) Close() error {
+	return nil
+}
+
+func TestResultStream(t *testing.T) {
+	w := &testResultWriter{}
+	callbacks := map[string]func(*common.Result) error{
+		"tag": func(result *common.Result) error {
+			columns := make([]interface{}, len(result.Columns))
+			for i, column := range result.Columns {
+				columns[i] = strings.TrimPrefix(column.(string), "tag_")
+			}
+			result.Columns = columns
+			for _, value := range result.Values {
+				row := value.([]interface{})
+				row[0] = row[0].(int) * 10
+			}
+			return nil
+		},
+	}
+	stream := newResultStream(w, []interface{}{"tag_a", "b"}, common.ColumnSchemas{{Name: "tag_a"}, {Name: "b"}}, callbacks)
+	if err := stream.write([]interface{}{[]interface{}{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.write([]interface{}{[]interface{}{3, 4}}); err != nil {
+		t.Fatal(err)
+	}
+	// the last empty batch writes nothing
+	if err := stream.write(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.headers) != 1 || w.headers[0][0] != "a" || stream.columns[0] != "a" {
+		t.Errorf("unexpected headers: %v", w.headers)
+	}
+	if len(w.batches) != 2 || w.batches[0][0].([]interface{})[0] != 10 || w.batches[1][0].([]interface{})[0] != 30 {
+		t.Errorf("unexpected batches: %v", w.batches)
+	}
+
+	// the header is written even if there is no row
+	w = &testResultWriter{}
+	stream = newResultStream(w, []interface{}{"tag_a"}, common.ColumnSchemas{{Name: "tag_a"}}, callbacks)
+	if err := stream.write(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.headers) != 1 || w.headers[0][0] != "a" || len(w.batches) != 0 {
+		t.Errorf("unexpected result of empty stream: %v %v", w.headers, w.batches)
+	}
+}
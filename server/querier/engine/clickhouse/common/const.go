@@ -31,6 +31,9 @@ const DB_NAME_APPLICATION_LOG = "application_log"
 const TABLE_NAME_VTAP_ACL = "traffic_policy"
 const TABLE_NAME_TRACE_TREE = "trace_tree"
 const TABLE_NAME_SPAN_WITH_TRACE_ID = "span_with_trace_id"
+const TABLE_NAME_PROMETHEUS_SAMPLES = "samples"
+const TABLE_NAME_PROMETHEUS_HISTOGRAMS = "histograms"
+const TABLE_NAME_PROMETHEUS_EXEMPLARS = "exemplars"
 const IndexTypeIncremetalId = "incremental-id"
 const FormatHex = "hex"
 const TagServerChPrefix = "服务端"
@@ -18,6 +18,7 @@ package clickhouse
 
 import (
 	"fmt"
+	"strings"
 
 	"golang.org/x/exp/slices"
 
@@ -43,6 +44,17 @@ func GetVirtualTableFilter(db, table string) (view.Node, bool) {
 	return nil, false
 }
 
+// GetPrometheusTable returns the physical table and metric name of a prometheus table,
+// native histograms and exemplars are queried by `histograms.<metric>` and `exemplars.<metric>`
+func GetPrometheusTable(table string) (string, string) {
+	for _, t := range []string{chCommon.TABLE_NAME_PROMETHEUS_HISTOGRAMS, chCommon.TABLE_NAME_PROMETHEUS_EXEMPLARS} {
+		if strings.HasPrefix(table, t+".") {
+			return t, strings.TrimPrefix(table, t+".")
+		}
+	}
+	return chCommon.TABLE_NAME_PROMETHEUS_SAMPLES, table
+}
+
 func GetMetricIDFilter(e *CHEngine) (view.Node, error) {
 	table := e.Table
 	metricID, ok := trans_prometheus.ORGPrometheus[e.ORGID].MetricNameToID[table]
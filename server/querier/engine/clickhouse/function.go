@@ -764,7 +764,7 @@ func (t *Time) Format(m *view.Model) {
 	}
 	m.AddGroup(&view.Group{Value: t.Alias, Flag: view.GROUP_FLAG_METRICS_OUTER})
 	if (m.Time.Fill == "0" || m.Time.Fill == "none" || m.Time.Fill == "null") && m.Time.Interval > 0 {
-		m.AddCallback("time", TimeFill([]interface{}{m}))
+		m.AddCallback(TIME_FILL_CALLBACK_COLUMN, TimeFill([]interface{}{m}))
 	}
 }
 
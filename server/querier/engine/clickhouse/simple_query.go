@@ -38,7 +38,7 @@ func SimpleExecute(args *common.QuerierParams) (result *common.Result, debug map
 		QueryUUID: query_uuid,
 	}
 	chClient.Debug = queryDebug
-	result, err = chClient.DoQuery(&client.QueryParams{Sql: args.Sql, UseQueryCache: args.UseQueryCache, QueryCacheTTL: args.QueryCacheTTL})
+	result, err = chClient.DoQuery(&client.QueryParams{Sql: args.Sql, UseQueryCache: args.UseQueryCache, QueryCacheTTL: args.QueryCacheTTL, ResultWriter: args.ResultWriter})
 	debugInfo.Debug = append(debugInfo.Debug, *queryDebug)
 	debug = debugInfo.Get()
 	return
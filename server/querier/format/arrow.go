@@ -0,0 +1,363 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+)
+
+type arrowKind int
+
+const (
+	arrowKindNull arrowKind = iota
+	arrowKindInt
+	arrowKindUint
+	arrowKindFloat
+	arrowKindBool
+	arrowKindTime
+	arrowKindString
+)
+
+// arrowColumn is the type of a field, unit is only used by arrowKindTime
+type arrowColumn struct {
+	kind arrowKind
+	unit arrow.TimeUnit
+}
+
+// arrowWriter writes the result as an Arrow IPC stream, the column schemas are kept in the field metadata.
+// The types of the fields are decided by the ClickHouse types in the column schemas, the values which can not be
+// converted to the field type fail the writing, instead of being written as null.
+type arrowWriter struct {
+	out       *flushWriter
+	mem       memory.Allocator
+	columns   []interface{}
+	schemas   common.ColumnSchemas
+	arrowCols []arrowColumn
+	schema    *arrow.Schema
+	writer    *ipc.Writer
+}
+
+func newArrowWriter(w io.Writer) *arrowWriter {
+	return &arrowWriter{out: newFlushWriter(w), mem: memory.NewGoAllocator()}
+}
+
+func (w *arrowWriter) WriteHeader(columns []interface{}, schemas common.ColumnSchemas) error {
+	w.columns = columns
+	w.schemas = schemas
+	return nil
+}
+
+// unwrapValueType removes Nullable and LowCardinality from the ClickHouse type
+func unwrapValueType(valueType string) string {
+	for {
+		trimmed := valueType
+		for _, wrapper := range []string{"Nullable(", "LowCardinality("} {
+			if strings.HasPrefix(trimmed, wrapper) && strings.HasSuffix(trimmed, ")") {
+				trimmed = trimmed[len(wrapper) : len(trimmed)-1]
+			}
+		}
+		if trimmed == valueType {
+			return valueType
+		}
+		valueType = trimmed
+	}
+}
+
+// valueTypeColumn returns the field type of the ClickHouse type, the types without a matching arrow type,
+// e.g. arrays, maps, ips and big integers, are written as string
+func valueTypeColumn(valueType string) arrowColumn {
+	valueType = unwrapValueType(valueType)
+	switch valueType {
+	case "Int8", "Int16", "Int32", "Int64":
+		return arrowColumn{kind: arrowKindInt}
+	case "UInt8", "UInt16", "UInt32", "UInt64":
+		return arrowColumn{kind: arrowKindUint}
+	case "Float32", "Float64":
+		return arrowColumn{kind: arrowKindFloat}
+	case "Bool":
+		return arrowColumn{kind: arrowKindBool}
+	case "Nothing":
+		return arrowColumn{kind: arrowKindNull}
+	case "Date", "Date32":
+		return arrowColumn{kind: arrowKindTime, unit: arrow.Millisecond}
+	}
+	switch {
+	case strings.HasPrefix(valueType, "Decimal"):
+		return arrowColumn{kind: arrowKindFloat}
+	case strings.HasPrefix(valueType, "DateTime64("):
+		// DateTime64(precision[, timezone])
+		precision, _ := strconv.Atoi(strings.TrimSpace(strings.Split(strings.TrimSuffix(valueType[len("DateTime64("):], ")"), ",")[0]))
+		switch {
+		case precision <= 3:
+			return arrowColumn{kind: arrowKindTime, unit: arrow.Millisecond}
+		case precision <= 6:
+			return arrowColumn{kind: arrowKindTime, unit: arrow.Microsecond}
+		default:
+			return arrowColumn{kind: arrowKindTime, unit: arrow.Nanosecond}
+		}
+	case strings.HasPrefix(valueType, "DateTime"):
+		return arrowColumn{kind: arrowKindTime, unit: arrow.Millisecond}
+	}
+	return arrowColumn{kind: arrowKindString}
+}
+
+func (c arrowColumn) dataType() arrow.DataType {
+	switch c.kind {
+	case arrowKindInt:
+		return arrow.PrimitiveTypes.Int64
+	case arrowKindUint:
+		return arrow.PrimitiveTypes.Uint64
+	case arrowKindFloat:
+		return arrow.PrimitiveTypes.Float64
+	case arrowKindBool:
+		return arrow.FixedWidthTypes.Boolean
+	case arrowKindTime:
+		return &arrow.TimestampType{Unit: c.unit, TimeZone: "UTC"}
+	case arrowKindNull:
+		return arrow.Null
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+func schemaMetadata(schema *common.ColumnSchema) arrow.Metadata {
+	if schema == nil {
+		return arrow.Metadata{}
+	}
+	m := schema.ToMap()
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = fmt.Sprint(m[k])
+	}
+	return arrow.NewMetadata(keys, values)
+}
+
+// start builds the schema from the column schemas and creates the ipc writer, the columns without schema are
+// written as string
+func (w *arrowWriter) start() {
+	w.arrowCols = make([]arrowColumn, len(w.columns))
+	fields := make([]arrow.Field, len(w.columns))
+	for i, column := range w.columns {
+		var schema *common.ColumnSchema
+		w.arrowCols[i] = arrowColumn{kind: arrowKindString}
+		if i < len(w.schemas) && w.schemas[i] != nil {
+			schema = w.schemas[i]
+			if schema.ValueType != "" {
+				w.arrowCols[i] = valueTypeColumn(schema.ValueType)
+			}
+		}
+		fields[i] = arrow.Field{
+			Name:     fmt.Sprint(column),
+			Type:     w.arrowCols[i].dataType(),
+			Nullable: true,
+			Metadata: schemaMetadata(schema),
+		}
+	}
+	w.schema = arrow.NewSchema(fields, nil)
+	w.writer = ipc.NewWriter(w.out, ipc.WithSchema(w.schema), ipc.WithAllocator(w.mem))
+}
+
+func appendValue(builder array.Builder, column arrowColumn, value interface{}) bool {
+	v := indirect(value)
+	if v == nil {
+		builder.AppendNull()
+		return true
+	}
+	switch column.kind {
+	case arrowKindInt:
+		if i, ok := toInt64(v); ok {
+			builder.(*array.Int64Builder).Append(i)
+			return true
+		}
+	case arrowKindUint:
+		if u, ok := toUint64(v); ok {
+			builder.(*array.Uint64Builder).Append(u)
+			return true
+		}
+	case arrowKindFloat:
+		if f, ok := toFloat64(v); ok {
+			builder.(*array.Float64Builder).Append(f)
+			return true
+		}
+	case arrowKindBool:
+		if b, ok := v.(bool); ok {
+			builder.(*array.BooleanBuilder).Append(b)
+			return true
+		}
+	case arrowKindTime:
+		if t, ok := v.(time.Time); ok {
+			builder.(*array.TimestampBuilder).Append(toTimestamp(t, column.unit))
+			return true
+		}
+	case arrowKindString:
+		builder.(*array.StringBuilder).Append(formatValue(v))
+		return true
+	}
+	return false
+}
+
+func (w *arrowWriter) WriteRows(values []interface{}) error {
+	if w.writer == nil {
+		w.start()
+	}
+	builder := array.NewRecordBuilder(w.mem, w.schema)
+	defer builder.Release()
+	for _, value := range values {
+		row, _ := value.([]interface{})
+		for i, column := range w.arrowCols {
+			var v interface{}
+			if i < len(row) {
+				v = row[i]
+			}
+			if !appendValue(builder.Field(i), column, v) {
+				field := w.schema.Field(i)
+				return fmt.Errorf("can not write value %v (%T) of column %s as %s", v, v, field.Name, field.Type)
+			}
+		}
+	}
+	record := builder.NewRecord()
+	defer record.Release()
+	if err := w.writer.Write(record); err != nil {
+		return err
+	}
+	return w.out.Flush()
+}
+
+func (w *arrowWriter) Close() error {
+	if w.writer == nil {
+		w.start()
+	}
+	if err := w.writer.Close(); err != nil {
+		return err
+	}
+	return w.out.Flush()
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint:
+		if n <= math.MaxInt64 {
+			return int64(n), true
+		}
+	case uint64:
+		if n <= math.MaxInt64 {
+			return int64(n), true
+		}
+	case string:
+		// e.g. the values filled by the time fill callback
+		if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func toUint64(v interface{}) (uint64, bool) {
+	switch n := v.(type) {
+	case uint:
+		return uint64(n), true
+	case uint8:
+		return uint64(n), true
+	case uint16:
+		return uint64(n), true
+	case uint32:
+		return uint64(n), true
+	case uint64:
+		return n, true
+	case string:
+		if u, err := strconv.ParseUint(n, 10, 64); err == nil {
+			return u, true
+		}
+		return 0, false
+	}
+	if i, ok := toInt64(v); ok && i >= 0 {
+		return uint64(i), true
+	}
+	return 0, false
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		if f, err := strconv.ParseFloat(n, 64); err == nil {
+			return f, true
+		}
+		return 0, false
+	case interface{ Float64() (float64, bool) }:
+		// decimals
+		f, _ := n.Float64()
+		return f, true
+	}
+	if i, ok := toInt64(v); ok {
+		return float64(i), true
+	}
+	if u, ok := toUint64(v); ok {
+		return float64(u), true
+	}
+	return 0, false
+}
+
+func toTimestamp(t time.Time, unit arrow.TimeUnit) arrow.Timestamp {
+	switch unit {
+	case arrow.Second:
+		return arrow.Timestamp(t.Unix())
+	case arrow.Microsecond:
+		return arrow.Timestamp(t.UnixNano() / int64(time.Microsecond))
+	case arrow.Nanosecond:
+		return arrow.Timestamp(t.UnixNano())
+	default:
+		return arrow.Timestamp(t.UnixNano() / int64(time.Millisecond))
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+)
+
+type csvWriter struct {
+	out    *flushWriter
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	out := newFlushWriter(w)
+	return &csvWriter{out: out, writer: csv.NewWriter(out)}
+}
+
+func (w *csvWriter) WriteHeader(columns []interface{}, schemas common.ColumnSchemas) error {
+	w.record = make([]string, len(columns))
+	for i, column := range columns {
+		w.record[i] = fmt.Sprint(column)
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) WriteRows(values []interface{}) error {
+	for _, value := range values {
+		row, _ := value.([]interface{})
+		for i := range w.record {
+			w.record[i] = ""
+			if i < len(row) {
+				w.record[i] = formatValue(row[i])
+			}
+		}
+		if err := w.writer.Write(w.record); err != nil {
+			return err
+		}
+	}
+	return w.flush()
+}
+
+func (w *csvWriter) flush() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return err
+	}
+	return w.out.Flush()
+}
+
+func (w *csvWriter) Close() error {
+	return w.flush()
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+)
+
+const (
+	FORMAT_JSON   = "json"
+	FORMAT_CSV    = "csv"
+	FORMAT_NDJSON = "ndjson"
+	FORMAT_ARROW  = "arrow"
+)
+
+var contentTypes = map[string]string{
+	FORMAT_CSV:    "text/csv; charset=utf-8",
+	FORMAT_NDJSON: "application/x-ndjson",
+	FORMAT_ARROW:  "application/vnd.apache.arrow.stream",
+}
+
+// IsStreamFormat returns whether the format is written by NewResultWriter, json is not a stream format
+func IsStreamFormat(format string) bool {
+	_, ok := contentTypes[format]
+	return ok
+}
+
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// NewResultWriter returns the writer of the format, the written data is flushed to w after each batch
+func NewResultWriter(format string, w io.Writer) (common.ResultWriter, error) {
+	switch format {
+	case FORMAT_CSV:
+		return newCSVWriter(w), nil
+	case FORMAT_NDJSON:
+		return newNDJSONWriter(w), nil
+	case FORMAT_ARROW:
+		return newArrowWriter(w), nil
+	default:
+		return nil, common.NewError(common.INVALID_POST_DATA, fmt.Sprintf("unsupported format (%s)", format))
+	}
+}
+
+// flushWriter buffers the output and flushes it to the http response after each batch
+type flushWriter struct {
+	*bufio.Writer
+	out io.Writer
+}
+
+func newFlushWriter(out io.Writer) *flushWriter {
+	return &flushWriter{Writer: bufio.NewWriter(out), out: out}
+}
+
+func (w *flushWriter) Flush() error {
+	if err := w.Writer.Flush(); err != nil {
+		return err
+	}
+	if f, ok := w.out.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// indirect dereferences the pointers of nullable values, returns nil for null
+func indirect(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// formatValue formats the value as text, null is formatted as empty string
+func formatValue(value interface{}) string {
+	switch v := indirect(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case net.IP:
+		return v.String()
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	default:
+		// arrays, maps and tuples
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
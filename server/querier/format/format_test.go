@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+)
+
+func testWriteResult(t *testing.T, outputFormat string, laterByte interface{}) []byte {
+	var buf bytes.Buffer
+	writer, err := NewResultWriter(outputFormat, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nullFloat *float64
+	value := 1.5
+	columns := []interface{}{"time", "ip", "byte", "rtt", "tags"}
+	schemas := common.ColumnSchemas{
+		{Name: "time", Type: common.COLUMN_SCHEMA_TYPE_TAG, ValueType: "DateTime"},
+		{Name: "ip", Type: common.COLUMN_SCHEMA_TYPE_TAG, ValueType: "String"},
+		{Name: "byte", Type: common.COLUMN_SCHEMA_TYPE_METRICS, Unit: "byte", ValueType: "UInt64"},
+		{Name: "rtt", Type: common.COLUMN_SCHEMA_TYPE_METRICS, Unit: "us", ValueType: "Nullable(Float64)"},
+		{Name: "tags", Type: common.COLUMN_SCHEMA_TYPE_TAG, ValueType: "Array(String)"},
+	}
+	if err := writer.WriteHeader(columns, schemas); err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := writer.WriteRows([]interface{}{
+		[]interface{}{ts, "1.1.1.1", uint64(100), &value, []string{"a", "b"}},
+		[]interface{}{ts, "a,\"b\"", uint64(200), nullFloat, []string{}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// the value type of the later batch is different from the first batch
+	if err := writer.WriteRows([]interface{}{
+		[]interface{}{ts, "2.2.2.2", laterByte, 2.5, nil},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	expected := "time,ip,byte,rtt,tags\n" +
+		"2024-01-02T03:04:05Z,1.1.1.1,100,1.5,\"[\"\"a\"\",\"\"b\"\"]\"\n" +
+		"2024-01-02T03:04:05Z,\"a,\"\"b\"\"\",200,,[]\n" +
+		"2024-01-02T03:04:05Z,2.2.2.2,unknown,2.5,\n"
+	if result := string(testWriteResult(t, FORMAT_CSV, "unknown")); result != expected {
+		t.Errorf("unexpected csv:\n%s\nexpected:\n%s", result, expected)
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	expected := `{"time":"2024-01-02T03:04:05Z","ip":"1.1.1.1","byte":100,"rtt":1.5,"tags":["a","b"]}` + "\n" +
+		`{"time":"2024-01-02T03:04:05Z","ip":"a,\"b\"","byte":200,"rtt":null,"tags":[]}` + "\n" +
+		`{"time":"2024-01-02T03:04:05Z","ip":"2.2.2.2","byte":"unknown","rtt":2.5,"tags":null}` + "\n"
+	if result := string(testWriteResult(t, FORMAT_NDJSON, "unknown")); result != expected {
+		t.Errorf("unexpected ndjson:\n%s\nexpected:\n%s", result, expected)
+	}
+}
+
+func TestArrowWriter(t *testing.T) {
+	reader, err := ipc.NewReader(bytes.NewReader(testWriteResult(t, FORMAT_ARROW, "300")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Release()
+
+	schema := reader.Schema()
+	expectedTypes := []arrow.DataType{
+		&arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"},
+		arrow.BinaryTypes.String,
+		arrow.PrimitiveTypes.Uint64,
+		arrow.PrimitiveTypes.Float64,
+		arrow.BinaryTypes.String,
+	}
+	for i, field := range schema.Fields() {
+		if !arrow.TypeEqual(field.Type, expectedTypes[i]) {
+			t.Errorf("field %s type is %s, expected %s", field.Name, field.Type, expectedTypes[i])
+		}
+	}
+	byteField := schema.Field(2)
+	if i := byteField.Metadata.FindKey("unit"); i < 0 || byteField.Metadata.Values()[i] != "byte" {
+		t.Errorf("unexpected metadata of byte: %v", byteField.Metadata)
+	}
+	if i := byteField.Metadata.FindKey("value_type"); i < 0 || byteField.Metadata.Values()[i] != "UInt64" {
+		t.Errorf("unexpected metadata of byte: %v", byteField.Metadata)
+	}
+
+	var rows int
+	var byteValues []interface{}
+	for reader.Next() {
+		record := reader.Record()
+		rows += int(record.NumRows())
+		column := record.Column(2).(*array.Uint64)
+		for i := 0; i < column.Len(); i++ {
+			if column.IsNull(i) {
+				byteValues = append(byteValues, nil)
+			} else {
+				byteValues = append(byteValues, column.Value(i))
+			}
+		}
+		if rows == 2 {
+			rtt := record.Column(3).(*array.Float64)
+			if rtt.Value(0) != 1.5 || !rtt.IsNull(1) {
+				t.Errorf("unexpected rtt: %v", rtt)
+			}
+			tags := record.Column(4).(*array.String)
+			if tags.Value(0) != `["a","b"]` {
+				t.Errorf("unexpected tags: %s", tags.Value(0))
+			}
+		}
+	}
+	if rows != 3 {
+		t.Errorf("read %d rows, expected 3", rows)
+	}
+	// the field type is decided by the schema instead of the first batch
+	if len(byteValues) != 3 || byteValues[0] != uint64(100) || byteValues[1] != uint64(200) || byteValues[2] != uint64(300) {
+		t.Errorf("unexpected bytes: %v", byteValues)
+	}
+}
+
+func TestArrowWriterMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	writer, _ := NewResultWriter(FORMAT_ARROW, &buf)
+	writer.WriteHeader([]interface{}{"byte"}, common.ColumnSchemas{{Name: "byte", ValueType: "UInt64"}})
+	if err := writer.WriteRows([]interface{}{[]interface{}{uint64(1)}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteRows([]interface{}{[]interface{}{"unknown"}}); err == nil {
+		t.Error("expected error when the value can not be converted")
+	}
+}
+
+func TestValueTypeColumn(t *testing.T) {
+	cases := []struct {
+		valueType string
+		expected  arrow.DataType
+	}{
+		{"Int32", arrow.PrimitiveTypes.Int64},
+		{"LowCardinality(Nullable(UInt16))", arrow.PrimitiveTypes.Uint64},
+		{"Nullable(Float32)", arrow.PrimitiveTypes.Float64},
+		{"Decimal(18, 4)", arrow.PrimitiveTypes.Float64},
+		{"Bool", arrow.FixedWidthTypes.Boolean},
+		{"DateTime('Asia/Shanghai')", &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}},
+		{"DateTime64(6)", &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}},
+		{"Nullable(DateTime64(9, 'UTC'))", &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}},
+		{"LowCardinality(String)", arrow.BinaryTypes.String},
+		{"IPv4", arrow.BinaryTypes.String},
+		{"UInt128", arrow.BinaryTypes.String},
+		{"Array(UInt64)", arrow.BinaryTypes.String},
+	}
+	for _, c := range cases {
+		if dataType := valueTypeColumn(c.valueType).dataType(); !arrow.TypeEqual(dataType, c.expected) {
+			t.Errorf("%s is written as %s, expected %s", c.valueType, dataType, c.expected)
+		}
+	}
+}
+
+func TestArrowWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	writer, _ := NewResultWriter(FORMAT_ARROW, &buf)
+	writer.WriteHeader([]interface{}{"a"}, common.ColumnSchemas{{Name: "a"}})
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := ipc.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Release()
+	if len(reader.Schema().Fields()) != 1 || reader.Next() {
+		t.Error("expected a schema with 1 field and no record")
+	}
+}
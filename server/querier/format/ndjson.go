@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+)
+
+// ndjsonWriter writes each row as a json object in one line, the keys keep the order of the columns
+type ndjsonWriter struct {
+	out  *flushWriter
+	keys [][]byte
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{out: newFlushWriter(w)}
+}
+
+func (w *ndjsonWriter) WriteHeader(columns []interface{}, schemas common.ColumnSchemas) error {
+	w.keys = make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(fmt.Sprint(column))
+		if err != nil {
+			return err
+		}
+		w.keys[i] = key
+	}
+	return nil
+}
+
+func (w *ndjsonWriter) WriteRows(values []interface{}) error {
+	for _, value := range values {
+		row, _ := value.([]interface{})
+		w.out.WriteByte('{')
+		for i, key := range w.keys {
+			if i > 0 {
+				w.out.WriteByte(',')
+			}
+			w.out.Write(key)
+			w.out.WriteByte(':')
+			var v interface{}
+			if i < len(row) {
+				v = indirect(row[i])
+			}
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			w.out.Write(data)
+		}
+		if _, err := w.out.WriteString("}\n"); err != nil {
+			return err
+		}
+	}
+	return w.out.Flush()
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.out.Flush()
+}
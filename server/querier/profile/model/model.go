@@ -75,3 +75,42 @@ type GrafanaProfileValue struct {
 	Columns []string        `json:"columns"`
 	Values  [][]interface{} `json:"values"`
 }
+
+// ProfileDiff compares the profile of compare time range or tag filter with the base profile,
+// the compare time range and tag filter are the same as the base if not set.
+type ProfileDiff struct {
+	Profile
+	CompareTimeStart int    `json:"compare_time_start"`
+	CompareTimeEnd   int    `json:"compare_time_end"`
+	CompareTagFilter string `json:"compare_tag_filter"`
+}
+
+type ProfileDiffResult struct {
+	BaseTotalValue    int                   `json:"base_total_value"`
+	CompareTotalValue int                   `json:"compare_total_value"`
+	Functions         []ProfileFunctionDiff `json:"functions"`
+}
+
+type ProfileFunctionDiff struct {
+	Function          string `json:"function"`
+	FunctionType      string `json:"function_type"`
+	BaseSelfValue     int    `json:"base_self_value"`
+	BaseTotalValue    int    `json:"base_total_value"`
+	CompareSelfValue  int    `json:"compare_self_value"`
+	CompareTotalValue int    `json:"compare_total_value"`
+	SelfValueDelta    int    `json:"self_value_delta"`
+	TotalValueDelta   int    `json:"total_value_delta"`
+}
+
+// PyroscopeQuery is the query of Pyroscope HTTP API, such as `app.on-cpu{pod="foo",profile_language_type="eBPF"}`
+type PyroscopeQuery struct {
+	AppService       string
+	ProfileEventType string
+	Matchers         []PyroscopeMatcher
+}
+
+type PyroscopeMatcher struct {
+	Name     string
+	Operator string // =, !=, =~ or !~
+	Value    string
+}
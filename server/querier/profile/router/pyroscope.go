@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	querier_common "github.com/khulnasoft/deepflow/server/querier/common"
+	"github.com/khulnasoft/deepflow/server/querier/config"
+	"github.com/khulnasoft/deepflow/server/querier/profile/common"
+	"github.com/khulnasoft/deepflow/server/querier/profile/model"
+	"github.com/khulnasoft/deepflow/server/querier/profile/service"
+)
+
+const PYROSCOPE_FORMAT_PPROF = "pprof"
+
+// the responses of Pyroscope compatible API are not wrapped, so they can be consumed by Pyroscope clients directly
+func pyroscopeErrorResponse(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	var serviceError *querier_common.ServiceError
+	if errors.As(err, &serviceError) && serviceError.Status == querier_common.INVALID_POST_DATA {
+		code = http.StatusBadRequest
+	}
+	c.JSON(code, gin.H{"code": code, "message": err.Error()})
+}
+
+func pyroscopeBadRequestResponse(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": err.Error()})
+}
+
+func getPyroscopeMaxNodes(c *gin.Context) int {
+	maxNodes, _ := strconv.Atoi(c.Query("max-nodes"))
+	return maxNodes
+}
+
+// parsePyroscopeArgs parses the query and time range from the query params with prefix, such as leftQuery, leftFrom, leftUntil,
+// the params without prefix are used if they are not set.
+func parsePyroscopeArgs(c *gin.Context, prefix string) (*model.PyroscopeQuery, model.Profile, error) {
+	param := func(name string) string {
+		if prefix != "" {
+			if v := c.Query(prefix + strings.ToUpper(name[:1]) + name[1:]); v != "" {
+				return v
+			}
+		}
+		return c.Query(name)
+	}
+	q, err := service.ParsePyroscopeQuery(param("query"))
+	if err != nil {
+		return nil, model.Profile{}, err
+	}
+	timeStart, timeEnd, err := service.ParsePyroscopeTimeRange(param("from"), param("until"))
+	if err != nil {
+		return nil, model.Profile{}, err
+	}
+	debug, _ := strconv.ParseBool(c.DefaultQuery("debug", "false"))
+	args := service.NewPyroscopeProfileArgs(c.Request.Context(), c.Request.Header.Get(common.HEADER_KEY_X_ORG_ID), q, timeStart, timeEnd, debug)
+	return q, args, nil
+}
+
+func pyroscopeRender(cfg *config.QuerierConfig) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		q, args, err := parsePyroscopeArgs(c, "")
+		if err != nil {
+			pyroscopeBadRequestResponse(c, err)
+			return
+		}
+		if c.Query("format") == PYROSCOPE_FORMAT_PPROF {
+			data, _, err := service.PyroscopePprof(args, q, cfg)
+			if err != nil {
+				pyroscopeErrorResponse(c, err)
+				return
+			}
+			c.Header("Content-Disposition", "attachment; filename=profile.pb.gz")
+			c.Data(http.StatusOK, "application/octet-stream", data)
+			return
+		}
+		result, _, err := service.PyroscopeRender(args, q, getPyroscopeMaxNodes(c), cfg)
+		if err != nil {
+			pyroscopeErrorResponse(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	})
+}
+
+func pyroscopeRenderDiff(cfg *config.QuerierConfig) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		leftQuery, leftArgs, err := parsePyroscopeArgs(c, "left")
+		if err != nil {
+			pyroscopeBadRequestResponse(c, err)
+			return
+		}
+		rightQuery, rightArgs, err := parsePyroscopeArgs(c, "right")
+		if err != nil {
+			pyroscopeBadRequestResponse(c, err)
+			return
+		}
+		result, _, err := service.PyroscopeRenderDiff(leftArgs, rightArgs, leftQuery, rightQuery, getPyroscopeMaxNodes(c), cfg)
+		if err != nil {
+			pyroscopeErrorResponse(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	})
+}
+
+func pyroscopeLabels() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		debug, _ := strconv.ParseBool(c.DefaultQuery("debug", "false"))
+		result, _, err := service.PyroscopeLabels(c.Request.Context(), c.Request.Header.Get(common.HEADER_KEY_X_ORG_ID), debug)
+		if err != nil {
+			pyroscopeErrorResponse(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	})
+}
+
+func pyroscopeLabelValues() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		label := c.Query("label")
+		if label == "" {
+			pyroscopeBadRequestResponse(c, errors.New("label is required"))
+			return
+		}
+		var q *model.PyroscopeQuery
+		var err error
+		if query := c.Query("query"); query != "" {
+			if q, err = service.ParsePyroscopeQuery(query); err != nil {
+				pyroscopeBadRequestResponse(c, err)
+				return
+			}
+		}
+		timeStart, timeEnd, err := service.ParsePyroscopeTimeRange(c.Query("from"), c.Query("until"))
+		if err != nil {
+			pyroscopeBadRequestResponse(c, err)
+			return
+		}
+		debug, _ := strconv.ParseBool(c.DefaultQuery("debug", "false"))
+		result, _, err := service.PyroscopeLabelValues(c.Request.Context(), c.Request.Header.Get(common.HEADER_KEY_X_ORG_ID), label, q, timeStart, timeEnd, debug)
+		if err != nil {
+			pyroscopeErrorResponse(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	})
+}
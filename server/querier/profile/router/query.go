@@ -32,6 +32,13 @@ import (
 func ProfileRouter(e *gin.Engine, cfg *config.QuerierConfig) {
 	e.POST("/v1/profile/ProfileTracing", profile(cfg))
 	e.POST("/v1/profile/ProfileGrafana", profileGrafana(cfg))
+	e.POST("/v1/profile/ProfileDiff", profileDiff(cfg))
+
+	// Pyroscope compatible API, the base url of Pyroscope datasource is http://<querier>/v1/profile/pyroscope
+	e.GET("/v1/profile/pyroscope/render", pyroscopeRender(cfg))
+	e.GET("/v1/profile/pyroscope/render-diff", pyroscopeRenderDiff(cfg))
+	e.GET("/v1/profile/pyroscope/labels", pyroscopeLabels())
+	e.GET("/v1/profile/pyroscope/label-values", pyroscopeLabelValues())
 }
 
 func profile(cfg *config.QuerierConfig) gin.HandlerFunc {
@@ -79,3 +86,26 @@ func profileGrafana(cfg *config.QuerierConfig) gin.HandlerFunc {
 		router.JsonResponse(c, result, debug, err)
 	})
 }
+
+func profileDiff(cfg *config.QuerierConfig) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		var args model.ProfileDiff
+
+		err := c.ShouldBindBodyWith(&args, binding.JSON)
+		if err != nil {
+			router.BadRequestResponse(c, common.INVALID_POST_DATA, err.Error())
+			return
+		}
+		args.Context = c.Request.Context()
+		args.OrgID = c.Request.Header.Get(common.HEADER_KEY_X_ORG_ID)
+		if args.MaxKernelStackDepth == nil {
+			var maxKernelStackDepth = common.MAX_KERNEL_STACK_DEPTH_DEFAULT
+			args.MaxKernelStackDepth = &maxKernelStackDepth
+		}
+		result, debug, err := service.DiffProfile(args, cfg)
+		if err == nil && !args.Debug {
+			debug = nil
+		}
+		router.JsonResponse(c, result, debug, err)
+	})
+}
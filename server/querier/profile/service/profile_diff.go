@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"sort"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+	"github.com/khulnasoft/deepflow/server/querier/config"
+	"github.com/khulnasoft/deepflow/server/querier/profile/model"
+)
+
+// DiffProfile returns the self/total value deltas of each function between the base and the compare profile,
+// sorted by the absolute self value delta in descending order, so the regressions can be found at the top.
+func DiffProfile(args model.ProfileDiff, cfg *config.QuerierConfig) (result *model.ProfileDiffResult, debug interface{}, err error) {
+	compareArgs := args.Profile
+	if args.CompareTimeStart != 0 {
+		compareArgs.TimeStart = args.CompareTimeStart
+	}
+	if args.CompareTimeEnd != 0 {
+		compareArgs.TimeEnd = args.CompareTimeEnd
+	}
+	if args.CompareTagFilter != "" {
+		compareArgs.TagFilter = args.CompareTagFilter
+	}
+	if compareArgs.TimeStart == args.TimeStart && compareArgs.TimeEnd == args.TimeEnd && compareArgs.TagFilter == args.TagFilter {
+		err = common.NewError(common.INVALID_POST_DATA, "compare time range or tag filter should be different from the base")
+		return
+	}
+
+	debugs := []interface{}{}
+	baseTree, baseDebug, err := Profile(args.Profile, cfg)
+	debugs = append(debugs, baseDebug)
+	if err != nil {
+		return nil, debugs, err
+	}
+	compareTree, compareDebug, err := Profile(compareArgs, cfg)
+	debugs = append(debugs, compareDebug)
+	if err != nil {
+		return nil, debugs, err
+	}
+	return diffProfileTree(baseTree, compareTree), debugs, nil
+}
+
+func diffProfileTree(base, compare model.ProfileTree) *model.ProfileDiffResult {
+	result := &model.ProfileDiffResult{}
+	functionToIndex := make(map[string]int)
+	// the first function is the root, whose total value is the total value of the profile
+	addFunctions := func(tree model.ProfileTree, isBase bool) {
+		for i, function := range tree.Functions {
+			values := tree.FunctionValues.Values[i]
+			if i == 0 {
+				if isBase {
+					result.BaseTotalValue = values[1]
+				} else {
+					result.CompareTotalValue = values[1]
+				}
+				continue
+			}
+			index, ok := functionToIndex[function]
+			if !ok {
+				index = len(result.Functions)
+				functionToIndex[function] = index
+				result.Functions = append(result.Functions, model.ProfileFunctionDiff{Function: function, FunctionType: tree.FunctionTypes[i]})
+			}
+			f := &result.Functions[index]
+			if isBase {
+				f.BaseSelfValue += values[0]
+				f.BaseTotalValue += values[1]
+			} else {
+				f.CompareSelfValue += values[0]
+				f.CompareTotalValue += values[1]
+			}
+		}
+	}
+	addFunctions(base, true)
+	addFunctions(compare, false)
+
+	for i := range result.Functions {
+		f := &result.Functions[i]
+		f.SelfValueDelta = f.CompareSelfValue - f.BaseSelfValue
+		f.TotalValueDelta = f.CompareTotalValue - f.BaseTotalValue
+	}
+	sort.SliceStable(result.Functions, func(i, j int) bool {
+		di, dj := abs(result.Functions[i].SelfValueDelta), abs(result.Functions[j].SelfValueDelta)
+		if di != dj {
+			return di > dj
+		}
+		return abs(result.Functions[i].TotalValueDelta) > abs(result.Functions[j].TotalValueDelta)
+	})
+	return result
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
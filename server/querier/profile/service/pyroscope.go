@@ -0,0 +1,422 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pyroscope-io/pyroscope/pkg/storage/metadata"
+	"github.com/pyroscope-io/pyroscope/pkg/storage/tree"
+	"github.com/pyroscope-io/pyroscope/pkg/structs/flamebearer"
+	"github.com/pyroscope-io/pyroscope/pkg/util/attime"
+
+	querier_common "github.com/khulnasoft/deepflow/server/querier/common"
+	"github.com/khulnasoft/deepflow/server/querier/config"
+	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse"
+	"github.com/khulnasoft/deepflow/server/querier/profile/common"
+	"github.com/khulnasoft/deepflow/server/querier/profile/model"
+)
+
+const (
+	PYROSCOPE_LABEL_NAME       = "__name__"
+	PYROSCOPE_MAX_NODES        = 1024
+	PYROSCOPE_LABEL_VALUES_MAX = 10000
+)
+
+var pyroscopeLabelRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+// units of profile_event_type, see the relation in ingester/profile/dbwriter/profile.go
+var pyroscopeUnits = map[string]metadata.Units{
+	"on-cpu":         "microseconds",
+	"off-cpu":        "microseconds",
+	"mem-alloc":      metadata.BytesUnits,
+	"mem-inuse":      metadata.BytesUnits,
+	"cpu":            metadata.SamplesUnits,
+	"itimer":         metadata.SamplesUnits,
+	"wall":           metadata.SamplesUnits,
+	"inuse_objects":  metadata.ObjectsUnits,
+	"alloc_objects":  metadata.ObjectsUnits,
+	"inuse_space":    metadata.BytesUnits,
+	"alloc_space":    metadata.BytesUnits,
+	"goroutines":     metadata.GoroutinesUnits,
+	"mutex_duration": metadata.LockNanosecondsUnits,
+	"mutex_count":    metadata.LockSamplesUnits,
+	"block_duration": metadata.LockNanosecondsUnits,
+	"block_count":    metadata.LockSamplesUnits,
+	"lock_count":     metadata.LockSamplesUnits,
+	"lock_duration":  metadata.LockNanosecondsUnits,
+}
+
+func getPyroscopeUnits(profileEventType string) metadata.Units {
+	if units, ok := pyroscopeUnits[profileEventType]; ok {
+		return units
+	}
+	if strings.HasSuffix(profileEventType, "_bytes") {
+		return metadata.BytesUnits
+	}
+	if strings.HasSuffix(profileEventType, "_objects") {
+		return metadata.ObjectsUnits
+	}
+	return metadata.SamplesUnits
+}
+
+// ParsePyroscopeQuery parses the query of Pyroscope HTTP API, the format is `<app_service>.<profile_event_type>{<matchers>}`,
+// such as `app.on-cpu{pod="foo",profile_language_type="eBPF"}`, the operators of matchers can be =, !=, =~ and !~.
+func ParsePyroscopeQuery(query string) (*model.PyroscopeQuery, error) {
+	query = strings.TrimSpace(query)
+	name, selector := query, ""
+	if i := strings.IndexByte(query, '{'); i >= 0 {
+		if !strings.HasSuffix(query, "}") {
+			return nil, fmt.Errorf("query (%s) is invalid, missing '}'", query)
+		}
+		name, selector = strings.TrimSpace(query[:i]), query[i+1:len(query)-1]
+	}
+	dot := strings.LastIndexByte(name, '.')
+	if dot <= 0 || dot == len(name)-1 {
+		return nil, fmt.Errorf("name (%s) of query should be <app_service>.<profile_event_type>", name)
+	}
+	q := &model.PyroscopeQuery{
+		AppService:       name[:dot],
+		ProfileEventType: name[dot+1:],
+	}
+
+	for i := 0; ; {
+		for i < len(selector) && (selector[i] == ' ' || selector[i] == ',') {
+			i++
+		}
+		if i >= len(selector) {
+			break
+		}
+		// label name
+		start := i
+		for i < len(selector) && (isPyroscopeLabelChar(selector[i])) {
+			i++
+		}
+		matcher := model.PyroscopeMatcher{Name: selector[start:i]}
+		if !pyroscopeLabelRegexp.MatchString(matcher.Name) {
+			return nil, fmt.Errorf("label name (%s) of query is invalid", matcher.Name)
+		}
+		for i < len(selector) && selector[i] == ' ' {
+			i++
+		}
+		// operator
+		for _, op := range []string{"=~", "!~", "!=", "="} {
+			if strings.HasPrefix(selector[i:], op) {
+				matcher.Operator = op
+				i += len(op)
+				break
+			}
+		}
+		if matcher.Operator == "" {
+			return nil, fmt.Errorf("operator of label (%s) should be one of =, !=, =~ and !~", matcher.Name)
+		}
+		for i < len(selector) && selector[i] == ' ' {
+			i++
+		}
+		// quoted value
+		if i >= len(selector) || selector[i] != '"' {
+			return nil, fmt.Errorf("value of label (%s) should be quoted by '\"'", matcher.Name)
+		}
+		end := i + 1
+		for end < len(selector) && selector[end] != '"' {
+			if selector[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(selector) {
+			return nil, fmt.Errorf("value of label (%s) is not closed", matcher.Name)
+		}
+		value, err := strconv.Unquote(selector[i : end+1])
+		if err != nil {
+			return nil, fmt.Errorf("value of label (%s) is invalid: %s", matcher.Name, err)
+		}
+		matcher.Value = value
+		q.Matchers = append(q.Matchers, matcher)
+		i = end + 1
+	}
+	return q, nil
+}
+
+func isPyroscopeLabelChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func quotePyroscopeValue(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// pyroscopeWhere converts the query and time range to the where clause of querier sql
+func pyroscopeWhere(q *model.PyroscopeQuery, timeStart, timeEnd int) string {
+	whereSlice := []string{
+		fmt.Sprintf("time>=%d", timeStart),
+		fmt.Sprintf("time<=%d", timeEnd),
+	}
+	if q.AppService != "" {
+		whereSlice = append(whereSlice, "app_service="+quotePyroscopeValue(q.AppService))
+	}
+	if q.ProfileEventType != "" {
+		whereSlice = append(whereSlice, "profile_event_type="+quotePyroscopeValue(q.ProfileEventType))
+	}
+	for _, m := range q.Matchers {
+		switch m.Operator {
+		case "=~":
+			whereSlice = append(whereSlice, fmt.Sprintf("%s REGEXP %s", m.Name, quotePyroscopeValue(m.Value)))
+		case "!~":
+			whereSlice = append(whereSlice, fmt.Sprintf("%s NOT REGEXP %s", m.Name, quotePyroscopeValue(m.Value)))
+		default:
+			whereSlice = append(whereSlice, m.Name+m.Operator+quotePyroscopeValue(m.Value))
+		}
+	}
+	return strings.Join(whereSlice, " AND ")
+}
+
+// ParsePyroscopeTimeRange parses from and until of Pyroscope HTTP API, such as `now-1h` or unix timestamp,
+// the default time range is the last hour.
+func ParsePyroscopeTimeRange(from, until string) (int, int, error) {
+	if from == "" {
+		from = "now-1h"
+	}
+	if until == "" {
+		until = "now"
+	}
+	timeStart, timeEnd := int(attime.Parse(from).Unix()), int(attime.Parse(until).Unix())
+	if timeStart >= timeEnd {
+		return 0, 0, fmt.Errorf("from (%s) should be earlier than until (%s)", from, until)
+	}
+	return timeStart, timeEnd, nil
+}
+
+// NewPyroscopeProfileArgs returns the arguments of GenerateProfile,
+// profile_language_type is set only if it is in the matchers, which is used to cut kernel functions.
+func NewPyroscopeProfileArgs(ctx context.Context, orgID string, q *model.PyroscopeQuery, timeStart, timeEnd int, debug bool) model.Profile {
+	maxKernelStackDepth := common.MAX_KERNEL_STACK_DEPTH_DEFAULT
+	args := model.Profile{
+		AppService:          q.AppService,
+		ProfileEventType:    q.ProfileEventType,
+		TimeStart:           timeStart,
+		TimeEnd:             timeEnd,
+		Debug:               debug,
+		Context:             ctx,
+		OrgID:               orgID,
+		MaxKernelStackDepth: &maxKernelStackDepth,
+	}
+	for _, m := range q.Matchers {
+		if m.Name == "profile_language_type" && m.Operator == "=" {
+			args.ProfileLanguageType = m.Value
+		}
+	}
+	return args
+}
+
+func generatePyroscopeTree(args model.Profile, q *model.PyroscopeQuery, cfg *config.QuerierConfig) (*tree.Tree, interface{}, error) {
+	profileTree, debug, err := GenerateProfile(args, cfg, pyroscopeWhere(q, args.TimeStart, args.TimeEnd))
+	if err != nil {
+		return nil, debug, err
+	}
+	return profileTreeToPyroscopeTree(profileTree), debug, nil
+}
+
+// profileTreeToPyroscopeTree inserts the stack of each node which has self value into pyroscope tree,
+// the root node (app_service) is skipped because pyroscope tree has its own root.
+func profileTreeToPyroscopeTree(profileTree model.ProfileTree) *tree.Tree {
+	t := tree.New()
+	nodes := profileTree.NodeValues.Values
+	if len(nodes) <= 1 {
+		return t
+	}
+	stack := []string{}
+	for _, node := range nodes[1:] {
+		// columns: ["function_id", "parent_node_id", "self_value", "total_value"]
+		if node[2] <= 0 {
+			continue
+		}
+		stack = stack[:0]
+		for n := node; n[1] >= 0; n = nodes[n[1]] {
+			stack = append(stack, profileTree.Functions[n[0]])
+		}
+		// leaf to root => root to leaf
+		for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+			stack[i], stack[j] = stack[j], stack[i]
+		}
+		t.InsertStackString(stack, uint64(node[2]))
+	}
+	return t
+}
+
+func newPyroscopeProfileConfig(q *model.PyroscopeQuery, t *tree.Tree, maxNodes int) flamebearer.ProfileConfig {
+	if maxNodes <= 0 {
+		maxNodes = PYROSCOPE_MAX_NODES
+	}
+	return flamebearer.ProfileConfig{
+		Name:     q.AppService + "." + q.ProfileEventType,
+		MaxNodes: maxNodes,
+		Tree:     t,
+		Metadata: metadata.Metadata{
+			SpyName:         "deepflow",
+			Units:           getPyroscopeUnits(q.ProfileEventType),
+			AggregationType: metadata.SumAggregationType,
+		},
+	}
+}
+
+// PyroscopeRender returns the flamegraph in the format of Pyroscope `/render` API
+func PyroscopeRender(args model.Profile, q *model.PyroscopeQuery, maxNodes int, cfg *config.QuerierConfig) (*flamebearer.FlamebearerProfile, interface{}, error) {
+	t, debug, err := generatePyroscopeTree(args, q, cfg)
+	if err != nil {
+		return nil, debug, err
+	}
+	profile := flamebearer.NewProfile(newPyroscopeProfileConfig(q, t, maxNodes))
+	return &profile, debug, nil
+}
+
+// PyroscopeRenderDiff returns the differential flamegraph in the format of Pyroscope `/render-diff` API,
+// left is the baseline and right is the comparison.
+func PyroscopeRenderDiff(leftArgs, rightArgs model.Profile, leftQuery, rightQuery *model.PyroscopeQuery, maxNodes int, cfg *config.QuerierConfig) (*flamebearer.FlamebearerProfile, interface{}, error) {
+	debugs := []interface{}{}
+	leftTree, leftDebug, err := generatePyroscopeTree(leftArgs, leftQuery, cfg)
+	debugs = append(debugs, leftDebug)
+	if err != nil {
+		return nil, debugs, err
+	}
+	rightTree, rightDebug, err := generatePyroscopeTree(rightArgs, rightQuery, cfg)
+	debugs = append(debugs, rightDebug)
+	if err != nil {
+		return nil, debugs, err
+	}
+	profile, err := flamebearer.NewCombinedProfile(
+		newPyroscopeProfileConfig(leftQuery, leftTree, maxNodes),
+		newPyroscopeProfileConfig(rightQuery, rightTree, maxNodes),
+	)
+	if err != nil {
+		return nil, debugs, querier_common.NewError(querier_common.INVALID_POST_DATA, err.Error())
+	}
+	return &profile, debugs, nil
+}
+
+// PyroscopePprof returns the gzipped pprof protobuf of the profile
+func PyroscopePprof(args model.Profile, q *model.PyroscopeQuery, cfg *config.QuerierConfig) ([]byte, interface{}, error) {
+	t, debug, err := generatePyroscopeTree(args, q, cfg)
+	if err != nil {
+		return nil, debug, err
+	}
+	profile := t.Pprof(&tree.PprofMetadata{
+		Type:      q.ProfileEventType,
+		Unit:      getPyroscopeUnits(q.ProfileEventType).String(),
+		StartTime: time.Unix(int64(args.TimeStart), 0),
+		Duration:  time.Duration(args.TimeEnd-args.TimeStart) * time.Second,
+	})
+	data, err := profile.MarshalVT()
+	if err != nil {
+		return nil, debug, err
+	}
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err = gw.Write(data); err != nil {
+		return nil, debug, err
+	}
+	if err = gw.Close(); err != nil {
+		return nil, debug, err
+	}
+	return buf.Bytes(), debug, nil
+}
+
+func executeProfileQuery(ctx context.Context, orgID, sql string, debug bool) ([]interface{}, map[string]interface{}, error) {
+	ckEngine := &clickhouse.CHEngine{DB: common.DATABASE_PROFILE}
+	ckEngine.Init()
+	result, querierDebug, err := ckEngine.ExecuteQuery(&querier_common.QuerierParams{
+		DB:      common.DATABASE_PROFILE,
+		Sql:     sql,
+		Debug:   strconv.FormatBool(debug),
+		Context: ctx,
+		ORGID:   orgID,
+	})
+	if err != nil {
+		log.Errorf("ExecuteQuery failed: %v", querierDebug, err)
+		return nil, querierDebug, err
+	}
+	if result == nil {
+		return nil, querierDebug, nil
+	}
+	return result.Values, querierDebug, nil
+}
+
+// PyroscopeLabels returns the tag names of profile, including `__name__`
+func PyroscopeLabels(ctx context.Context, orgID string, debug bool) ([]string, interface{}, error) {
+	values, querierDebug, err := executeProfileQuery(ctx, orgID, fmt.Sprintf("show tags from %s", common.TABLE_PROFILE), debug)
+	if err != nil {
+		return nil, querierDebug, err
+	}
+	labels := []string{PYROSCOPE_LABEL_NAME}
+	for _, value := range values {
+		row, ok := value.([]interface{})
+		if !ok || len(row) == 0 {
+			continue
+		}
+		if name, ok := row[0].(string); ok && pyroscopeLabelRegexp.MatchString(name) {
+			labels = append(labels, name)
+		}
+	}
+	return labels, querierDebug, nil
+}
+
+// PyroscopeLabelValues returns the values of label in the time range,
+// the values of `__name__` are `<app_service>.<profile_event_type>`.
+func PyroscopeLabelValues(ctx context.Context, orgID, label string, q *model.PyroscopeQuery, timeStart, timeEnd int, debug bool) ([]string, interface{}, error) {
+	if q == nil {
+		q = &model.PyroscopeQuery{}
+	}
+	where := pyroscopeWhere(q, timeStart, timeEnd)
+	var sql string
+	if label == PYROSCOPE_LABEL_NAME {
+		sql = fmt.Sprintf("SELECT app_service, profile_event_type FROM %s WHERE %s GROUP BY app_service, profile_event_type LIMIT %d",
+			common.TABLE_PROFILE, where, PYROSCOPE_LABEL_VALUES_MAX)
+	} else {
+		if !pyroscopeLabelRegexp.MatchString(label) {
+			return nil, nil, querier_common.NewError(querier_common.INVALID_POST_DATA, fmt.Sprintf("label (%s) is invalid", label))
+		}
+		sql = fmt.Sprintf("SELECT %s FROM %s WHERE %s GROUP BY %s LIMIT %d", label, common.TABLE_PROFILE, where, label, PYROSCOPE_LABEL_VALUES_MAX)
+	}
+	values, querierDebug, err := executeProfileQuery(ctx, orgID, sql, debug)
+	if err != nil {
+		return nil, querierDebug, err
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		row, ok := value.([]interface{})
+		if !ok || len(row) == 0 {
+			continue
+		}
+		if label == PYROSCOPE_LABEL_NAME {
+			if len(row) < 2 {
+				continue
+			}
+			result = append(result, fmt.Sprintf("%v.%v", row[0], row[1]))
+		} else {
+			result = append(result, fmt.Sprintf("%v", row[0]))
+		}
+	}
+	return result, querierDebug, nil
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pyroscope-io/pyroscope/pkg/structs/flamebearer"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/khulnasoft/deepflow/server/querier/profile/model"
+)
+
+func TestParsePyroscopeQuery(t *testing.T) {
+	q, err := ParsePyroscopeQuery(`my.app.on-cpu{pod="foo", pod_ns!="kube-system",app_instance=~"a\"b.*" , host!~"it's"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "my.app", q.AppService)
+	assert.Equal(t, "on-cpu", q.ProfileEventType)
+	assert.Equal(t, []model.PyroscopeMatcher{
+		{Name: "pod", Operator: "=", Value: "foo"},
+		{Name: "pod_ns", Operator: "!=", Value: "kube-system"},
+		{Name: "app_instance", Operator: "=~", Value: `a"b.*`},
+		{Name: "host", Operator: "!~", Value: "it's"},
+	}, q.Matchers)
+	assert.Equal(t,
+		`time>=1 AND time<=2 AND app_service='my.app' AND profile_event_type='on-cpu' AND pod='foo' AND pod_ns!='kube-system' AND app_instance REGEXP 'a"b.*' AND host NOT REGEXP 'it\'s'`,
+		pyroscopeWhere(q, 1, 2))
+
+	q, err = ParsePyroscopeQuery("app.cpu")
+	assert.Nil(t, err)
+	assert.Equal(t, "app", q.AppService)
+	assert.Empty(t, q.Matchers)
+
+	for _, query := range []string{
+		"app",
+		"app.",
+		`app.cpu{pod="foo"`,
+		`app.cpu{pod}`,
+		`app.cpu{pod=foo}`,
+		`app.cpu{pod="foo}`,
+		`app.cpu{p'od="foo"}`,
+	} {
+		_, err = ParsePyroscopeQuery(query)
+		assert.NotNil(t, err, query)
+	}
+}
+
+func TestParsePyroscopeTimeRange(t *testing.T) {
+	timeStart, timeEnd, err := ParsePyroscopeTimeRange("1700000000", "1700003600000")
+	assert.Nil(t, err)
+	assert.Equal(t, 1700000000, timeStart)
+	assert.Equal(t, 1700003600, timeEnd)
+
+	timeStart, timeEnd, err = ParsePyroscopeTimeRange("", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 3600, timeEnd-timeStart)
+
+	_, _, err = ParsePyroscopeTimeRange("now", "now-1h")
+	assert.NotNil(t, err)
+}
+
+// app -> main -> foo (self 3)
+//
+//	-> bar (self 1) -> foo (self 2)
+func newTestProfileTree(fooSelf int) model.ProfileTree {
+	return model.ProfileTree{
+		Functions:     []string{"app", "main", "foo", "bar"},
+		FunctionTypes: []string{"P", "A", "A", "A"},
+		FunctionValues: model.Value{Values: [][]int{
+			{0, fooSelf + 3}, {0, fooSelf + 3}, {fooSelf + 2, fooSelf + 2}, {1, 3},
+		}},
+		NodeValues: model.Value{Values: [][]int{
+			{0, -1, 0, fooSelf + 3},
+			{1, 0, 0, fooSelf + 3},
+			{2, 1, fooSelf, fooSelf},
+			{3, 1, 1, 3},
+			{2, 3, 2, 2},
+		}},
+	}
+}
+
+func TestProfileTreeToPyroscopeTree(t *testing.T) {
+	tree := profileTreeToPyroscopeTree(newTestProfileTree(3))
+	assert.Equal(t, uint64(6), tree.Samples())
+	stacks := map[string]uint64{}
+	// stack of IterateStacks is from leaf to root
+	tree.IterateStacks(func(name string, self uint64, stack []string) {
+		for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+			stack[i], stack[j] = stack[j], stack[i]
+		}
+		stacks[strings.Join(stack, ";")] = self
+	})
+	assert.Equal(t, map[string]uint64{"main;foo": 3, "main;bar": 1, "main;bar;foo": 2}, stacks)
+
+	assert.Equal(t, uint64(0), profileTreeToPyroscopeTree(model.ProfileTree{}).Samples())
+
+	q := &model.PyroscopeQuery{AppService: "app", ProfileEventType: "on-cpu"}
+	profile := flamebearer.NewProfile(newPyroscopeProfileConfig(q, tree, 0))
+	assert.Equal(t, 6, profile.Flamebearer.NumTicks)
+	assert.Equal(t, "microseconds", string(profile.Metadata.Units))
+	combined, err := flamebearer.NewCombinedProfile(newPyroscopeProfileConfig(q, tree, 0),
+		newPyroscopeProfileConfig(q, profileTreeToPyroscopeTree(newTestProfileTree(10)), 0))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6), combined.LeftTicks)
+	assert.Equal(t, uint64(13), combined.RightTicks)
+}
+
+func TestDiffProfileTree(t *testing.T) {
+	result := diffProfileTree(newTestProfileTree(3), newTestProfileTree(10))
+	assert.Equal(t, 6, result.BaseTotalValue)
+	assert.Equal(t, 13, result.CompareTotalValue)
+	assert.Len(t, result.Functions, 3)
+	assert.Equal(t, model.ProfileFunctionDiff{
+		Function:          "foo",
+		FunctionType:      "A",
+		BaseSelfValue:     5,
+		BaseTotalValue:    5,
+		CompareSelfValue:  12,
+		CompareTotalValue: 12,
+		SelfValueDelta:    7,
+		TotalValueDelta:   7,
+	}, result.Functions[0])
+	assert.Equal(t, "main", result.Functions[1].Function)
+	assert.Equal(t, 7, result.Functions[1].TotalValueDelta)
+	assert.Equal(t, "bar", result.Functions[2].Function)
+	assert.Equal(t, 0, result.Functions[2].SelfValueDelta)
+
+	// functions only in one of the profiles
+	result = diffProfileTree(model.ProfileTree{}, newTestProfileTree(1))
+	assert.Equal(t, 0, result.BaseTotalValue)
+	assert.Equal(t, 3, result.Functions[0].SelfValueDelta)
+}
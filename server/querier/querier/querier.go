@@ -17,6 +17,7 @@
 package querier
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -28,24 +29,28 @@ import (
 	yaml "gopkg.in/yaml.v2"
 
 	servercommon "github.com/khulnasoft/deepflow/server/common"
+	"github.com/khulnasoft/deepflow/server/controller/trisolaris/utils"
 	"github.com/khulnasoft/deepflow/server/libs/logger"
 	"github.com/khulnasoft/deepflow/server/libs/stats"
 	distributed_tracing "github.com/khulnasoft/deepflow/server/querier/app/distributed_tracing/router"
 	"github.com/khulnasoft/deepflow/server/querier/app/distributed_tracing/service/tracemap"
 	prometheus_router "github.com/khulnasoft/deepflow/server/querier/app/prometheus/router"
+	"github.com/khulnasoft/deepflow/server/querier/app/prometheus/rules"
 	tracing_adapter "github.com/khulnasoft/deepflow/server/querier/app/tracing-adapter/router"
 	"github.com/khulnasoft/deepflow/server/querier/common"
 	"github.com/khulnasoft/deepflow/server/querier/config"
 	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/trans_prometheus"
 	profile_router "github.com/khulnasoft/deepflow/server/querier/profile/router"
 	"github.com/khulnasoft/deepflow/server/querier/router"
+	"github.com/khulnasoft/deepflow/server/querier/service"
 	"github.com/khulnasoft/deepflow/server/querier/statsd"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 var log = logging.MustGetLogger("querier")
 
-func Start(configPath, serverLogFile string, shared *servercommon.ControllerIngesterShared) {
+// Start runs the querier, leaderChecker is nil if the controller election is not running in the same process
+func Start(ctx context.Context, configPath, serverLogFile string, shared *servercommon.ControllerIngesterShared, leaderChecker rules.LeaderChecker) {
 	ServerCfg := config.DefaultConfig()
 	ServerCfg.Load(configPath)
 	config.Cfg = &ServerCfg.QuerierConfig
@@ -70,6 +75,9 @@ func Start(configPath, serverLogFile string, shared *servercommon.ControllerInge
 	// prometheus dict cache
 	go trans_prometheus.GeneratePrometheusMap()
 
+	// async query
+	service.InitAsyncQueryManager(&config.Cfg.AsyncQuery)
+
 	// init opentelemetry
 	if cfg.OtelEndpoint != "" {
 		log.Infof("init opentelemetry: otel-endpoint(%s)", cfg.OtelEndpoint)
@@ -90,10 +98,17 @@ func Start(configPath, serverLogFile string, shared *servercommon.ControllerInge
 	r.Use(ErrHandle())
 	router.QueryRouter(r)
 	profile_router.ProfileRouter(r, &cfg)
-	prometheus_router.PrometheusRouter(r)
+	prometheusService := prometheus_router.PrometheusRouter(r)
 	tracing_adapter.TracingAdapterRouter(r)
 	distributed_tracing.TraceMapRouter(r, &cfg, tracemap_generator)
 	registerRouterCounter(r.Routes())
+
+	// the rules are evaluated by the same prometheus service, but not limited by qps-limit
+	if cfg.Prometheus.Rules.Enabled {
+		ruleEvaluator := rules.NewRuleEvaluator(&config.Cfg.Prometheus, prometheusService, leaderChecker)
+		ruleEvaluator.Start()
+		go closeOnDone(ctx, ruleEvaluator)
+	}
 	// TODO: 增加router
 	if err := r.Run(fmt.Sprintf(":%d", cfg.ListenPort)); err != nil {
 		log.Errorf("startup service failed, err:%v\n", err)
@@ -102,6 +117,17 @@ func Start(configPath, serverLogFile string, shared *servercommon.ControllerInge
 	}
 }
 
+// closeOnDone closes c when the server shuts down, the server waits for it if ctx has a wait group
+func closeOnDone(ctx context.Context, c io.Closer) {
+	wg := utils.GetWaitGroupInCtx(ctx)
+	if wg != nil {
+		wg.Add(1)
+		defer wg.Done()
+	}
+	<-ctx.Done()
+	c.Close()
+}
+
 func registerRouterCounter(routers gin.RoutesInfo) {
 	statsd.ApiCounters = make(map[string]*statsd.ApiCounter, len(routers))
 	for _, v := range routers {
@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+	"github.com/khulnasoft/deepflow/server/querier/config"
+	"github.com/khulnasoft/deepflow/server/querier/service"
+)
+
+const ASYNC_QUERY_DEFAULT_PAGE_SIZE = 1000
+
+func getOrgID(c *gin.Context) string {
+	orgID := c.Request.Header.Get(common.HEADER_KEY_X_ORG_ID)
+	// if no org_id in header, set default org id
+	if orgID == "" {
+		orgID = common.DEFAULT_ORG_ID
+	}
+	return orgID
+}
+
+// forwardAsyncQuery forwards the request to the querier running the query, it returns the query_uuid and
+// whether the request is forwarded
+func forwardAsyncQuery(c *gin.Context) (string, bool) {
+	queryUUID, ip := service.GetAsyncQueryManager().ParseQueryID(c.Param("query_id"))
+	// never forward a forwarded request again, in case the querier has restarted with another ip
+	if ip == "" || c.GetHeader(common.HEADER_KEY_X_FORWARDED_QUERIER) != "" {
+		return queryUUID, false
+	}
+	// the queriers listen on the same port, only the ip is carried by the query_id
+	address := net.JoinHostPort(ip, strconv.Itoa(config.Cfg.ListenPort))
+	proxy := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: address})
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		JsonResponse(c, nil, nil, common.NewError(common.SERVER_ERROR, fmt.Sprintf("forward to querier (%s) failed: %s", address, err)))
+	}
+	c.Request.Header.Set(common.HEADER_KEY_X_FORWARDED_QUERIER, address)
+	proxy.ServeHTTP(c.Writer, c.Request)
+	return queryUUID, true
+}
+
+func submitAsyncQuery() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		args := parseQuerierParams(c)
+		// the running queries are killed by the prefix of query_uuid, so it must be an uuid
+		if _, err := uuid.Parse(args.QueryUUID); err != nil {
+			JsonResponse(c, nil, nil, common.NewError(common.INVALID_POST_DATA, fmt.Sprintf("invalid query_uuid (%s)", args.QueryUUID)))
+			return
+		}
+		if args.Sql == "" {
+			JsonResponse(c, nil, nil, common.NewError(common.INVALID_POST_DATA, "sql is empty"))
+			return
+		}
+		result, err := service.GetAsyncQueryManager().Submit(&args)
+		JsonResponse(c, result, nil, err)
+	})
+}
+
+func listAsyncQueries() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		result := service.GetAsyncQueryManager().List(getOrgID(c))
+		JsonResponse(c, result, nil, nil)
+	})
+}
+
+func getAsyncQuery() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		queryUUID, forwarded := forwardAsyncQuery(c)
+		if forwarded {
+			return
+		}
+		result, err := service.GetAsyncQueryManager().Get(getOrgID(c), queryUUID)
+		JsonResponse(c, result, nil, err)
+	})
+}
+
+func getAsyncQueryResult() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		queryUUID, forwarded := forwardAsyncQuery(c)
+		if forwarded {
+			return
+		}
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil {
+			JsonResponse(c, nil, nil, common.NewError(common.INVALID_POST_DATA, fmt.Sprintf("invalid page (%s)", c.Query("page"))))
+			return
+		}
+		pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(ASYNC_QUERY_DEFAULT_PAGE_SIZE)))
+		if err != nil {
+			JsonResponse(c, nil, nil, common.NewError(common.INVALID_POST_DATA, fmt.Sprintf("invalid page_size (%s)", c.Query("page_size"))))
+			return
+		}
+		result, debug, err := service.GetAsyncQueryManager().GetResult(getOrgID(c), queryUUID, page, pageSize)
+		if err == nil && c.Query("debug") != "true" {
+			debug = nil
+		}
+		JsonResponse(c, result, debug, err)
+	})
+}
+
+func cancelAsyncQuery() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		queryUUID, forwarded := forwardAsyncQuery(c)
+		if forwarded {
+			return
+		}
+		result, err := service.GetAsyncQueryManager().Cancel(getOrgID(c), queryUUID)
+		JsonResponse(c, result, nil, err)
+	})
+}
@@ -21,13 +21,22 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	logging "github.com/op/go-logging"
 
 	"github.com/khulnasoft/deepflow/server/querier/common"
+	"github.com/khulnasoft/deepflow/server/querier/format"
 	"github.com/khulnasoft/deepflow/server/querier/service"
 )
 
+var log = logging.MustGetLogger("router")
+
 func QueryRouter(e *gin.Engine) {
 	e.POST("/v1/query/", executeQuery())
+	e.POST("/v1/query/async/", submitAsyncQuery())
+	e.GET("/v1/query/async/", listAsyncQueries())
+	e.GET("/v1/query/async/:query_id/", getAsyncQuery())
+	e.GET("/v1/query/async/:query_id/result/", getAsyncQueryResult())
+	e.POST("/v1/query/async/:query_id/cancel/", cancelAsyncQuery())
 
 	// api router for tempo
 	e.GET("/api/traces/:traceId", tempoTraceReader())
@@ -37,33 +46,38 @@ func QueryRouter(e *gin.Engine) {
 	e.GET("/api/search", tempoSearchReader())
 }
 
+func parseQuerierParams(c *gin.Context) common.QuerierParams {
+	args := common.QuerierParams{}
+	args.Context = c.Request.Context()
+	args.Debug = c.Query("debug")
+	args.UseQueryCache, _ = strconv.ParseBool(c.DefaultQuery("use_query_cache", "false"))
+	args.SimpleSql, _ = strconv.ParseBool(c.DefaultQuery("simple_sql", "false"))
+	args.QueryCacheTTL = c.Query("query_cache_ttl")
+	args.QueryUUID = c.Query("query_uuid")
+	args.NoPreWhere, _ = strconv.ParseBool(c.DefaultQuery("no_prewhere", "false"))
+	args.ORGID = getOrgID(c)
+	if args.QueryUUID == "" {
+		query_uuid := uuid.New()
+		args.QueryUUID = query_uuid.String()
+	}
+	args.DB = c.PostForm("db")
+	args.Sql = c.PostForm("sql")
+	args.DataSource = c.PostForm("data_precision")
+	if args.Sql == "" && args.DB == "" {
+		json := make(map[string]interface{})
+		c.BindJSON(&json)
+		args.DB, _ = json["db"].(string)
+		args.Sql, _ = json["sql"].(string)
+	}
+	return args
+}
+
 func executeQuery() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		args := common.QuerierParams{}
-		args.Context = c.Request.Context()
-		args.Debug = c.Query("debug")
-		args.UseQueryCache, _ = strconv.ParseBool(c.DefaultQuery("use_query_cache", "false"))
-		args.SimpleSql, _ = strconv.ParseBool(c.DefaultQuery("simple_sql", "false"))
-		args.QueryCacheTTL = c.Query("query_cache_ttl")
-		args.QueryUUID = c.Query("query_uuid")
-		args.NoPreWhere, _ = strconv.ParseBool(c.DefaultQuery("no_prewhere", "false"))
-		args.ORGID = c.Request.Header.Get(common.HEADER_KEY_X_ORG_ID)
-		// if no org_id in header, set default org id
-		if args.ORGID == "" {
-			args.ORGID = common.DEFAULT_ORG_ID
-		}
-		if args.QueryUUID == "" {
-			query_uuid := uuid.New()
-			args.QueryUUID = query_uuid.String()
-		}
-		args.DB = c.PostForm("db")
-		args.Sql = c.PostForm("sql")
-		args.DataSource = c.PostForm("data_precision")
-		if args.Sql == "" && args.DB == "" {
-			json := make(map[string]interface{})
-			c.BindJSON(&json)
-			args.DB, _ = json["db"].(string)
-			args.Sql, _ = json["sql"].(string)
+		args := parseQuerierParams(c)
+		if outputFormat := c.DefaultQuery("format", format.FORMAT_JSON); outputFormat != format.FORMAT_JSON {
+			executeStreamQuery(c, &args, outputFormat)
+			return
 		}
 
 		result := map[string]interface{}{}
@@ -81,3 +95,24 @@ func executeQuery() gin.HandlerFunc {
 		JsonResponse(c, result, debug, err)
 	})
 }
+
+// executeStreamQuery writes the rows to the response while reading them from ClickHouse,
+// the error can only be responded before any data is written
+func executeStreamQuery(c *gin.Context, args *common.QuerierParams, outputFormat string) {
+	writer, err := format.NewResultWriter(outputFormat, c.Writer)
+	if err != nil {
+		JsonResponse(c, nil, nil, err)
+		return
+	}
+	c.Header("Content-Type", format.ContentType(outputFormat))
+	debug, err := service.ExecuteStream(args, writer)
+	if err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			JsonResponse(c, nil, debug, err)
+			return
+		}
+		log.Errorf("query_uuid: %s. stream %s result failed: %s", args.QueryUUID, outputFormat, err)
+		c.Abort()
+	}
+}
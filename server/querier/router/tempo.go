@@ -37,6 +37,7 @@ func tempoTagValuesReader() gin.HandlerFunc {
 		args := common.TempoParams{
 			TagName: c.Param("tagName"),
 			Context: c.Request.Context(),
+			ORGID:   getOrgID(c),
 		}
 		result, _, err := tempo.ShowTagValues(&args)
 		if err != nil {
@@ -51,6 +52,7 @@ func tempoTagsReader() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		args := common.TempoParams{
 			Context: c.Request.Context(),
+			ORGID:   getOrgID(c),
 		}
 		result, _, err := tempo.ShowTags(&args)
 		if err != nil {
@@ -70,11 +72,18 @@ func tempoSearchReader() gin.HandlerFunc {
 			StartTime:   c.Query("start"),
 			EndTime:     c.Query("end"),
 			Debug:       c.Query("debug"),
+			Query:       c.Query("q"),
 			Context:     c.Request.Context(),
+			ORGID:       getOrgID(c),
 		}
 		args.SetFilters(c.Query("tags"))
 		result, _, err := tempo.TraceSearch(&args)
 		if err != nil {
+			// invalid or unsupported TraceQL, shown to the user by grafana
+			if serviceErr, ok := err.(*common.ServiceError); ok && serviceErr.Status == common.INVALID_PARAMETERS {
+				c.String(400, serviceErr.Message)
+				return
+			}
 			c.JSON(500, err)
 			return
 		}
@@ -89,6 +98,7 @@ func tempoTraceReader() gin.HandlerFunc {
 			StartTime: c.Query("start"),
 			EndTime:   c.Query("end"),
 			Context:   c.Request.Context(),
+			ORGID:     getOrgID(c),
 		}
 		resp, err := tempo.FindTraceByTraceID(&args)
 		if err != nil {
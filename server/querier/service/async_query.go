@@ -0,0 +1,410 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	logging "github.com/op/go-logging"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+	"github.com/khulnasoft/deepflow/server/querier/config"
+	"github.com/khulnasoft/deepflow/server/querier/engine/clickhouse/client"
+)
+
+var log = logging.MustGetLogger("service")
+
+const (
+	ASYNC_QUERY_PENDING   = "pending"
+	ASYNC_QUERY_RUNNING   = "running"
+	ASYNC_QUERY_FINISHED  = "finished"
+	ASYNC_QUERY_FAILED    = "failed"
+	ASYNC_QUERY_CANCELLED = "cancelled"
+)
+
+const ASYNC_QUERY_CLEAN_INTERVAL = 10 * time.Second
+
+const (
+	POD_IP_KEY                  = "K8S_POD_IP_FOR_DEEPFLOW"
+	ASYNC_QUERY_OWNER_SEPARATOR = "."
+)
+
+type asyncQueryExecutor func(args *common.QuerierParams) (map[string]interface{}, map[string]interface{}, error)
+type asyncQueryKiller func(queryUUID string) error
+
+type asyncQuery struct {
+	args     *common.QuerierParams
+	progress *common.QueryProgress
+	cancel   context.CancelFunc
+
+	status     string
+	err        error
+	submitTime time.Time
+	startTime  time.Time
+	finishTime time.Time
+	result     map[string]interface{}
+	debug      map[string]interface{}
+}
+
+// AsyncQueryManager runs the queries in the background, the running queries of each org are limited by
+// MaxConcurrencyPerOrg and the others wait in the queue, the results are kept for ResultTTL after finished.
+// The queries are kept in the memory of the querier running them, so the query_id returned to the client carries
+// the ip of this querier, and the other queriers forward the requests of the query to it
+type AsyncQueryManager struct {
+	sync.Mutex
+	cfg     *config.AsyncQuery
+	ip      string
+	queries map[string]*asyncQuery   // key: QueryUUID
+	running map[string]int           // key: ORGID
+	pending map[string][]*asyncQuery // key: ORGID
+	execute asyncQueryExecutor
+	kill    asyncQueryKiller
+}
+
+var asyncQueryManager *AsyncQueryManager
+
+func InitAsyncQueryManager(cfg *config.AsyncQuery) {
+	ip := cfg.AdvertiseIP
+	if ip == "" {
+		ip = os.Getenv(POD_IP_KEY)
+	}
+	if ip == "" {
+		log.Warning("neither async-query advertise-ip nor pod ip is set, async queries can only be got from the querier running them")
+	}
+	asyncQueryManager = newAsyncQueryManager(cfg, ip, executeAsyncQuery, killAsyncQuery)
+	go asyncQueryManager.run()
+}
+
+func GetAsyncQueryManager() *AsyncQueryManager {
+	return asyncQueryManager
+}
+
+func newAsyncQueryManager(cfg *config.AsyncQuery, ip string, execute asyncQueryExecutor, kill asyncQueryKiller) *AsyncQueryManager {
+	return &AsyncQueryManager{
+		cfg:     cfg,
+		ip:      ip,
+		queries: make(map[string]*asyncQuery),
+		running: make(map[string]int),
+		pending: make(map[string][]*asyncQuery),
+		execute: execute,
+		kill:    kill,
+	}
+}
+
+func executeAsyncQuery(args *common.QuerierParams) (map[string]interface{}, map[string]interface{}, error) {
+	if args.SimpleSql {
+		return SimpleExecute(args)
+	}
+	return Execute(args)
+}
+
+func killAsyncQuery(queryUUID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Cfg.Clickhouse.Timeout)*time.Second)
+	defer cancel()
+	chClient := client.Client{
+		Host:     config.Cfg.Clickhouse.Host,
+		Port:     config.Cfg.Clickhouse.Port,
+		UserName: config.Cfg.Clickhouse.User,
+		Password: config.Cfg.Clickhouse.Password,
+		DB:       "default",
+		Context:  ctx,
+	}
+	return chClient.KillQuery(queryUUID, config.Cfg.Clickhouse.ClusterName)
+}
+
+// queryID returns the query_uuid followed by the encoded ip of this querier
+func (m *AsyncQueryManager) queryID(queryUUID string) string {
+	if m.ip == "" {
+		return queryUUID
+	}
+	return queryUUID + ASYNC_QUERY_OWNER_SEPARATOR + base64.RawURLEncoding.EncodeToString([]byte(m.ip))
+}
+
+// ParseQueryID returns the query_uuid of the query_id, and the ip of the querier running the query if it is not
+// this querier. A bare query_uuid is treated as a query of this querier.
+func (m *AsyncQueryManager) ParseQueryID(queryID string) (string, string) {
+	pos := strings.LastIndex(queryID, ASYNC_QUERY_OWNER_SEPARATOR)
+	if pos < 0 {
+		return queryID, ""
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(queryID[pos+1:])
+	if err != nil {
+		return queryID, ""
+	}
+	ip := net.ParseIP(string(decoded))
+	if ip == nil {
+		return queryID, ""
+	}
+	if ip.Equal(net.ParseIP(m.ip)) {
+		return queryID[:pos], ""
+	}
+	return queryID[:pos], ip.String()
+}
+
+func (m *AsyncQueryManager) run() {
+	ticker := time.NewTicker(ASYNC_QUERY_CLEAN_INTERVAL)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.clean(time.Now())
+	}
+}
+
+// clean removes the queries whose results are expired
+func (m *AsyncQueryManager) clean(now time.Time) {
+	m.Lock()
+	defer m.Unlock()
+	ttl := time.Duration(m.cfg.ResultTTL) * time.Second
+	for queryUUID, q := range m.queries {
+		if !q.finishTime.IsZero() && now.Sub(q.finishTime) >= ttl {
+			delete(m.queries, queryUUID)
+			log.Debugf("query_uuid: %s. async query result expired", queryUUID)
+		}
+	}
+}
+
+// Submit queues the query, the context of args is replaced because the query outlives the request
+func (m *AsyncQueryManager) Submit(args *common.QuerierParams) (map[string]interface{}, error) {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.queries[args.QueryUUID]; ok {
+		return nil, common.NewError(common.INVALID_POST_DATA, fmt.Sprintf("query (%s) already exists", args.QueryUUID))
+	}
+	if len(m.queries) >= m.cfg.MaxQueries {
+		return nil, common.NewError(common.RESOURCE_NUM_EXCEEDED, fmt.Sprintf("the number of async queries exceeds %d", m.cfg.MaxQueries))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &asyncQuery{
+		args:       args,
+		progress:   common.NewQueryProgress(args.QueryUUID),
+		cancel:     cancel,
+		status:     ASYNC_QUERY_PENDING,
+		submitTime: time.Now(),
+	}
+	args.Context = common.ContextWithQueryProgress(ctx, q.progress)
+	m.queries[args.QueryUUID] = q
+	m.pending[args.ORGID] = append(m.pending[args.ORGID], q)
+	m.schedule(args.ORGID)
+	log.Infof("query_uuid: %s. async query submitted, org_id: %s, sql: %s", args.QueryUUID, args.ORGID, args.Sql)
+	return m.queryToMap(q), nil
+}
+
+// schedule starts the pending queries of the org until the concurrency limit, it should be called with the lock
+func (m *AsyncQueryManager) schedule(orgID string) {
+	for m.running[orgID] < m.cfg.MaxConcurrencyPerOrg && len(m.pending[orgID]) > 0 {
+		q := m.pending[orgID][0]
+		m.pending[orgID] = m.pending[orgID][1:]
+		q.status = ASYNC_QUERY_RUNNING
+		q.startTime = time.Now()
+		m.running[orgID]++
+		go m.runQuery(q)
+	}
+	if len(m.pending[orgID]) == 0 {
+		delete(m.pending, orgID)
+	}
+}
+
+func (m *AsyncQueryManager) runQuery(q *asyncQuery) {
+	result, debug, err := m.execute(q.args)
+	q.cancel()
+
+	m.Lock()
+	defer m.Unlock()
+	orgID := q.args.ORGID
+	m.running[orgID]--
+	if m.running[orgID] <= 0 {
+		delete(m.running, orgID)
+	}
+	if q.status == ASYNC_QUERY_RUNNING {
+		q.finishTime = time.Now()
+		q.debug = debug
+		if err != nil {
+			q.status = ASYNC_QUERY_FAILED
+			q.err = err
+		} else {
+			q.status = ASYNC_QUERY_FINISHED
+			q.result = result
+		}
+		log.Infof("query_uuid: %s. async query %s, cost %v", q.args.QueryUUID, q.status, q.finishTime.Sub(q.startTime))
+	}
+	m.schedule(orgID)
+}
+
+func (m *AsyncQueryManager) getQuery(orgID, queryUUID string) (*asyncQuery, error) {
+	q, ok := m.queries[queryUUID]
+	if !ok || q.args.ORGID != orgID {
+		return nil, common.NewError(common.RESOURCE_NOT_FOUND, fmt.Sprintf("query (%s) not found", queryUUID))
+	}
+	return q, nil
+}
+
+func (m *AsyncQueryManager) Get(orgID, queryUUID string) (map[string]interface{}, error) {
+	m.Lock()
+	defer m.Unlock()
+	q, err := m.getQuery(orgID, queryUUID)
+	if err != nil {
+		return nil, err
+	}
+	return m.queryToMap(q), nil
+}
+
+// List returns the queries of the org run by this querier, the latest submitted is the first
+func (m *AsyncQueryManager) List(orgID string) []map[string]interface{} {
+	m.Lock()
+	defer m.Unlock()
+	queries := make([]*asyncQuery, 0)
+	for _, q := range m.queries {
+		if q.args.ORGID == orgID {
+			queries = append(queries, q)
+		}
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].submitTime.After(queries[j].submitTime)
+	})
+	data := make([]map[string]interface{}, 0, len(queries))
+	for _, q := range queries {
+		data = append(data, m.queryToMap(q))
+	}
+	return data
+}
+
+// Cancel removes the pending query from the queue, or kills the running query in ClickHouse
+func (m *AsyncQueryManager) Cancel(orgID, queryUUID string) (map[string]interface{}, error) {
+	m.Lock()
+	q, err := m.getQuery(orgID, queryUUID)
+	if err != nil {
+		m.Unlock()
+		return nil, err
+	}
+	status := q.status
+	switch status {
+	case ASYNC_QUERY_PENDING:
+		pending := m.pending[orgID]
+		for i := range pending {
+			if pending[i] == q {
+				m.pending[orgID] = append(pending[:i:i], pending[i+1:]...)
+				break
+			}
+		}
+		if len(m.pending[orgID]) == 0 {
+			delete(m.pending, orgID)
+		}
+	case ASYNC_QUERY_RUNNING:
+	default:
+		m.Unlock()
+		return nil, common.NewError(common.INVALID_POST_DATA, fmt.Sprintf("query (%s) is already %s", queryUUID, status))
+	}
+	q.status = ASYNC_QUERY_CANCELLED
+	q.finishTime = time.Now()
+	q.cancel()
+	data := m.queryToMap(q)
+	m.Unlock()
+
+	if status == ASYNC_QUERY_RUNNING {
+		// cancelling the context only closes the connection, the query may keep running in ClickHouse
+		if err := m.kill(queryUUID); err != nil {
+			log.Warningf("query_uuid: %s. kill async query failed: %s", queryUUID, err)
+		}
+	}
+	log.Infof("query_uuid: %s. async query cancelled", queryUUID)
+	return data, nil
+}
+
+// GetResult returns one page of the result values, page starts from 1
+func (m *AsyncQueryManager) GetResult(orgID, queryUUID string, page, pageSize int) (map[string]interface{}, map[string]interface{}, error) {
+	if page < 1 {
+		return nil, nil, common.NewError(common.INVALID_POST_DATA, fmt.Sprintf("invalid page (%d)", page))
+	}
+	if pageSize < 1 || pageSize > m.cfg.MaxPageSize {
+		return nil, nil, common.NewError(common.INVALID_POST_DATA, fmt.Sprintf("page_size should be in [1, %d]", m.cfg.MaxPageSize))
+	}
+	m.Lock()
+	defer m.Unlock()
+	q, err := m.getQuery(orgID, queryUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch q.status {
+	case ASYNC_QUERY_FINISHED:
+	case ASYNC_QUERY_FAILED:
+		return nil, q.debug, q.err
+	default:
+		return nil, nil, common.NewError(common.INVALID_POST_DATA, fmt.Sprintf("query (%s) is %s", queryUUID, q.status))
+	}
+
+	values, _ := q.result["values"].([]interface{})
+	total := len(values)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return map[string]interface{}{
+		"columns":     q.result["columns"],
+		"schemas":     q.result["schemas"],
+		"values":      values[start:end],
+		"page":        page,
+		"page_size":   pageSize,
+		"total_rows":  total,
+		"total_pages": (total + pageSize - 1) / pageSize,
+	}, q.debug, nil
+}
+
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func (m *AsyncQueryManager) queryToMap(q *asyncQuery) map[string]interface{} {
+	data := map[string]interface{}{
+		"query_id":    m.queryID(q.args.QueryUUID),
+		"query_uuid":  q.args.QueryUUID,
+		"status":      q.status,
+		"db":          q.args.DB,
+		"sql":         q.args.Sql,
+		"submit_time": unixTime(q.submitTime),
+		"start_time":  unixTime(q.startTime),
+		"finish_time": unixTime(q.finishTime),
+		"expire_time": 0,
+		"progress":    q.progress.ToMap(),
+		"result_rows": 0,
+		"error":       "",
+	}
+	if !q.finishTime.IsZero() {
+		data["expire_time"] = q.finishTime.Unix() + int64(m.cfg.ResultTTL)
+	}
+	if values, ok := q.result["values"].([]interface{}); ok {
+		data["result_rows"] = len(values)
+	}
+	if q.err != nil {
+		data["error"] = q.err.Error()
+	}
+	return data
+}
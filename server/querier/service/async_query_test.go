@@ -0,0 +1,238 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+	"github.com/khulnasoft/deepflow/server/querier/config"
+)
+
+type fakeAsyncQueryBackend struct {
+	sync.Mutex
+	releases map[string]chan struct{}
+	started  chan string
+	killed   chan string
+}
+
+func newFakeAsyncQueryBackend() *fakeAsyncQueryBackend {
+	return &fakeAsyncQueryBackend{
+		releases: make(map[string]chan struct{}),
+		started:  make(chan string, 10),
+		killed:   make(chan string, 10),
+	}
+}
+
+func (b *fakeAsyncQueryBackend) release(queryUUID string) chan struct{} {
+	b.Lock()
+	defer b.Unlock()
+	if _, ok := b.releases[queryUUID]; !ok {
+		b.releases[queryUUID] = make(chan struct{}, 1)
+	}
+	return b.releases[queryUUID]
+}
+
+func (b *fakeAsyncQueryBackend) execute(args *common.QuerierParams) (map[string]interface{}, map[string]interface{}, error) {
+	b.started <- args.QueryUUID
+	select {
+	case <-b.release(args.QueryUUID):
+	case <-args.Context.Done():
+		return nil, nil, args.Context.Err()
+	}
+	if args.Sql == "error" {
+		return nil, nil, errors.New("query failed")
+	}
+	values := make([]interface{}, 0, 5)
+	for i := 0; i < 5; i++ {
+		values = append(values, []interface{}{i})
+	}
+	return map[string]interface{}{"columns": []interface{}{"id"}, "values": values, "schemas": nil}, nil, nil
+}
+
+func (b *fakeAsyncQueryBackend) kill(queryUUID string) error {
+	b.killed <- queryUUID
+	return nil
+}
+
+func waitAsyncQueryStatus(t *testing.T, m *AsyncQueryManager, orgID, queryUUID, status string) {
+	for i := 0; i < 100; i++ {
+		data, err := m.Get(orgID, queryUUID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data["status"] == status {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("query %s is not %s", queryUUID, status)
+}
+
+func newTestAsyncQueryManager(b *fakeAsyncQueryBackend) *AsyncQueryManager {
+	return newAsyncQueryManager(&config.AsyncQuery{MaxConcurrencyPerOrg: 1, MaxQueries: 3, ResultTTL: 60, MaxPageSize: 100}, "", b.execute, b.kill)
+}
+
+func TestAsyncQueryConcurrencyAndPaging(t *testing.T) {
+	b := newFakeAsyncQueryBackend()
+	m := newTestAsyncQueryManager(b)
+
+	if _, err := m.Submit(&common.QuerierParams{QueryUUID: "q1", ORGID: "1", Sql: "select"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Submit(&common.QuerierParams{QueryUUID: "q2", ORGID: "1", Sql: "error"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Submit(&common.QuerierParams{QueryUUID: "q3", ORGID: "2", Sql: "select"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Submit(&common.QuerierParams{QueryUUID: "q4", ORGID: "2", Sql: "select"}); err == nil {
+		t.Error("expected error when the number of queries exceeds max-queries")
+	}
+	<-b.started
+	<-b.started
+	// org 1 only runs one query at a time
+	if data, _ := m.Get("1", "q2"); data["status"] != ASYNC_QUERY_PENDING {
+		t.Errorf("q2 status is %v, expected pending", data["status"])
+	}
+	if _, err := m.Get("2", "q1"); err == nil {
+		t.Error("expected error when getting the query of another org")
+	}
+
+	b.release("q1") <- struct{}{}
+	waitAsyncQueryStatus(t, m, "1", "q1", ASYNC_QUERY_FINISHED)
+	<-b.started
+	b.release("q2") <- struct{}{}
+	waitAsyncQueryStatus(t, m, "1", "q2", ASYNC_QUERY_FAILED)
+	if _, _, err := m.GetResult("1", "q2", 1, 10); err == nil {
+		t.Error("expected error when getting the result of the failed query")
+	}
+
+	result, _, err := m.GetResult("1", "q1", 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := result["values"].([]interface{})
+	if len(values) != 2 || values[0].([]interface{})[0] != 2 || result["total_pages"] != 3 || result["total_rows"] != 5 {
+		t.Errorf("unexpected page: %v", result)
+	}
+	if result, _, _ = m.GetResult("1", "q1", 4, 2); len(result["values"].([]interface{})) != 0 {
+		t.Errorf("expected empty page, got %v", result["values"])
+	}
+	if _, _, err := m.GetResult("1", "q1", 1, 101); err == nil {
+		t.Error("expected error when page_size exceeds max-page-size")
+	}
+
+	// the finished results are removed after the ttl
+	m.clean(time.Now().Add(61 * time.Second))
+	if _, err := m.Get("1", "q1"); err == nil {
+		t.Error("expected q1 to be removed")
+	}
+	if _, err := m.Get("2", "q3"); err != nil {
+		t.Error("the running query q3 should not be removed")
+	}
+	b.release("q3") <- struct{}{}
+}
+
+func TestAsyncQueryCancel(t *testing.T) {
+	b := newFakeAsyncQueryBackend()
+	m := newTestAsyncQueryManager(b)
+
+	m.Submit(&common.QuerierParams{QueryUUID: "q1", ORGID: "1", Sql: "select"})
+	m.Submit(&common.QuerierParams{QueryUUID: "q2", ORGID: "1", Sql: "select"})
+	<-b.started
+
+	// the pending query is removed from the queue without killing
+	if _, err := m.Cancel("1", "q2"); err != nil {
+		t.Fatal(err)
+	}
+	// the running query is killed
+	data, err := m.Cancel("1", "q1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["status"] != ASYNC_QUERY_CANCELLED {
+		t.Errorf("q1 status is %v, expected cancelled", data["status"])
+	}
+	if killed := <-b.killed; killed != "q1" {
+		t.Errorf("killed %s, expected q1", killed)
+	}
+	if len(b.killed) != 0 {
+		t.Error("the pending query should not be killed")
+	}
+	if _, err := m.Cancel("1", "q1"); err == nil {
+		t.Error("expected error when cancelling the cancelled query")
+	}
+
+	// the slot is released after the cancelled query returns
+	m.Submit(&common.QuerierParams{QueryUUID: "q3", ORGID: "1", Sql: "select"})
+	select {
+	case queryUUID := <-b.started:
+		if queryUUID != "q3" {
+			t.Errorf("started %s, expected q3", queryUUID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("q3 is not started")
+	}
+	b.release("q3") <- struct{}{}
+	waitAsyncQueryStatus(t, m, "1", "q3", ASYNC_QUERY_FINISHED)
+	if data, _ := m.Get("1", "q1"); data["status"] != ASYNC_QUERY_CANCELLED {
+		t.Errorf("q1 status is %v, expected cancelled", data["status"])
+	}
+}
+
+func TestAsyncQueryID(t *testing.T) {
+	b := newFakeAsyncQueryBackend()
+	m := newAsyncQueryManager(&config.AsyncQuery{MaxConcurrencyPerOrg: 1, MaxQueries: 3, ResultTTL: 60, MaxPageSize: 100}, "10.1.1.1", b.execute, b.kill)
+	other := newAsyncQueryManager(&config.AsyncQuery{MaxConcurrencyPerOrg: 1, MaxQueries: 3, ResultTTL: 60, MaxPageSize: 100}, "10.1.1.2", b.execute, b.kill)
+
+	data, err := m.Submit(&common.QuerierParams{QueryUUID: "q1", ORGID: "1", Sql: "select"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queryID := data["query_id"].(string)
+	if !strings.HasPrefix(queryID, "q1.") {
+		t.Fatalf("query_id is %s, expected q1 with the encoded ip", queryID)
+	}
+	// the querier running the query handles it locally
+	if queryUUID, ip := m.ParseQueryID(queryID); queryUUID != "q1" || ip != "" {
+		t.Errorf("parsed (%s, %s), expected (q1, )", queryUUID, ip)
+	}
+	// the other queriers forward it to the querier running it
+	if queryUUID, ip := other.ParseQueryID(queryID); queryUUID != "q1" || ip != "10.1.1.1" {
+		t.Errorf("parsed (%s, %s), expected (q1, 10.1.1.1)", queryUUID, ip)
+	}
+	// a bare query_uuid or an invalid owner is handled locally
+	for _, queryID := range []string{"q1", "q1.!!", "q1.bm90LWFuLWlw"} {
+		if queryUUID, ip := other.ParseQueryID(queryID); queryUUID != queryID || ip != "" {
+			t.Errorf("parsed %s to (%s, %s), expected (%s, )", queryID, queryUUID, ip, queryID)
+		}
+	}
+
+	// without ip, the query_id is the query_uuid
+	local := newTestAsyncQueryManager(b)
+	data, _ = local.Submit(&common.QuerierParams{QueryUUID: "q2", ORGID: "1", Sql: "select"})
+	if data["query_id"] != "q2" {
+		t.Errorf("query_id is %v, expected q2", data["query_id"])
+	}
+	b.release("q1") <- struct{}{}
+	b.release("q2") <- struct{}{}
+}
@@ -48,3 +48,46 @@ func SimpleExecute(args *common.QuerierParams) (jsonData map[string]interface{},
 	}
 	return jsonData, debug, err
 }
+
+// headerTrackedWriter records whether the engine has streamed the result into the writer
+type headerTrackedWriter struct {
+	common.ResultWriter
+	headerWritten bool
+}
+
+func (w *headerTrackedWriter) WriteHeader(columns []interface{}, schemas common.ColumnSchemas) error {
+	w.headerWritten = true
+	return w.ResultWriter.WriteHeader(columns, schemas)
+}
+
+// ExecuteStream writes the result to the writer, the rows are streamed from ClickHouse if the query supports,
+// otherwise the whole result is written after the query finished
+func ExecuteStream(args *common.QuerierParams, writer common.ResultWriter) (debug map[string]interface{}, err error) {
+	w := &headerTrackedWriter{ResultWriter: writer}
+	args.ResultWriter = w
+	var result *common.Result
+	if args.SimpleSql {
+		result, debug, err = clickhouse.SimpleExecute(args)
+	} else {
+		engine := &clickhouse.CHEngine{DB: args.DB, DataSource: args.DataSource, Context: args.Context}
+		engine.Init()
+		result, debug, err = engine.ExecuteQuery(args)
+	}
+	if err != nil {
+		return debug, err
+	}
+	if !w.headerWritten {
+		if result == nil {
+			result = &common.Result{}
+		}
+		if err = w.WriteHeader(result.Columns, result.Schemas); err != nil {
+			return debug, err
+		}
+		if len(result.Values) > 0 {
+			if err = w.WriteRows(result.Values); err != nil {
+				return debug, err
+			}
+		}
+	}
+	return debug, w.Close()
+}
@@ -55,6 +55,7 @@ var L7_TRACING_ENDPOINT = "endpoint"
 var L7_TRACING_OTEL_SDK_NAME = "telemetry.sdk.name"
 var L7_TRACING_OTEL_SDK_VERSION = "telemetry.sdk.version"
 var TABLE_NAME_L7_FLOW_LOG = "l7_flow_log"
+var TRACEQL_PARENT_SPAN_LIMIT = 10000
 
 var SEARCH_FIELDS = []string{
 	"trace_id as traceID", "app_service as rootServiceName", "endpoint as rootTraceName", "toUnixTimestamp64Micro(start_time) as startTimeUnixNano", "response_duration/1000 as durationMs",
@@ -94,6 +95,9 @@ func L7TracingRequest(args *common.TempoParams) (map[string]interface{}, error)
 		return nil, err
 	}
 	reqest.Header.Add("Content-Type", "application/json")
+	if args.ORGID != "" {
+		reqest.Header.Add(common.HEADER_KEY_X_ORG_ID, args.ORGID)
+	}
 	response, err := client.Do(reqest)
 	if err != nil {
 		return nil, err
@@ -292,6 +296,7 @@ func ShowTags(args *common.TempoParams) (resp map[string][]interface{}, debug ma
 		Debug:      args.Debug,
 		QueryUUID:  query_uuid.String(),
 		Context:    args.Context,
+		ORGID:      args.ORGID,
 	}
 	ckEngine := &clickhouse.CHEngine{DB: querierArgs.DB, DataSource: querierArgs.DataSource}
 	ckEngine.Init()
@@ -329,6 +334,7 @@ func ShowTagValues(args *common.TempoParams) (resp map[string][]interface{}, deb
 		Debug:      args.Debug,
 		QueryUUID:  query_uuid.String(),
 		Context:    args.Context,
+		ORGID:      args.ORGID,
 	}
 	ckEngine := &clickhouse.CHEngine{DB: querierArgs.DB, DataSource: querierArgs.DataSource}
 	ckEngine.Init()
@@ -367,6 +373,23 @@ func TraceSearch(args *common.TempoParams) (resp map[string]interface{}, debug m
 	if args.EndTime != "" {
 		filters = append(filters, fmt.Sprintf("time<=%s", args.EndTime))
 	}
+	if args.Query != "" {
+		spansetFilters, err := parseTraceQL(args.Query)
+		if err != nil {
+			return nil, nil, err
+		}
+		traceQLFilter, debug, err := traceQLToFilter(spansetFilters, filters, args)
+		if err != nil {
+			return nil, debug, err
+		}
+		if traceQLFilter == "" && len(spansetFilters) > 1 {
+			// no span matches the parent spansets
+			return resp, debug, nil
+		}
+		if traceQLFilter != "" {
+			filters = append(filters, traceQLFilter)
+		}
+	}
 	for _, kv := range args.Filters {
 		key := kv.Key
 		if k, ok := SPAN_ATTRS_MAP[kv.Key]; ok {
@@ -405,6 +428,7 @@ func TraceSearch(args *common.TempoParams) (resp map[string]interface{}, debug m
 		Debug:      "false",
 		QueryUUID:  query_uuid.String(),
 		Context:    args.Context,
+		ORGID:      args.ORGID,
 	}
 	ckEngine := &clickhouse.CHEngine{DB: querierArgs.DB, DataSource: querierArgs.DataSource}
 	ckEngine.Init()
@@ -430,6 +454,61 @@ func TraceSearch(args *common.TempoParams) (resp map[string]interface{}, debug m
 	return resp, debug, err
 }
 
+// traceQLToFilter combines the filters of spansets connected by the structural '>' operator. The span ids
+// matching a parent spanset are queried first, and the children are filtered by their parent span ids.
+// An empty filter is returned if no span matches a parent spanset, and an error is returned if more than
+// TRACEQL_PARENT_SPAN_LIMIT spans match, since the search result would be incomplete.
+func traceQLToFilter(spansetFilters []string, baseFilters []string, args *common.TempoParams) (string, map[string]interface{}, error) {
+	parentFilter := ""
+	for i, spansetFilter := range spansetFilters {
+		filters := append([]string{}, baseFilters...)
+		if spansetFilter != "" {
+			filters = append(filters, spansetFilter)
+		}
+		if parentFilter != "" {
+			filters = append(filters, parentFilter)
+		}
+		if i == len(spansetFilters)-1 {
+			return strings.Join(filters[len(baseFilters):], " AND "), nil, nil
+		}
+		filters = append(filters, "span_id != ''")
+		sql := fmt.Sprintf("select span_id from %s WHERE %s LIMIT %d", TABLE_NAME_L7_FLOW_LOG, strings.Join(filters, " AND "), TRACEQL_PARENT_SPAN_LIMIT+1)
+		querierArgs := common.QuerierParams{
+			DB:         "flow_log",
+			Sql:        sql,
+			DataSource: "",
+			Debug:      "false",
+			QueryUUID:  uuid.New().String(),
+			Context:    args.Context,
+			ORGID:      args.ORGID,
+		}
+		ckEngine := &clickhouse.CHEngine{DB: querierArgs.DB, DataSource: querierArgs.DataSource}
+		ckEngine.Init()
+		result, debug, err := ckEngine.ExecuteQuery(&querierArgs)
+		if err != nil {
+			return "", debug, err
+		}
+		if len(result.Values) > TRACEQL_PARENT_SPAN_LIMIT {
+			return "", debug, traceqlError("more than %d spans match the parent spanset, narrow the time range or the query", TRACEQL_PARENT_SPAN_LIMIT)
+		}
+		spanIDs := map[string]bool{}
+		quotedSpanIDs := []string{}
+		for _, d := range result.Values {
+			spanID, ok := d.([]interface{})[0].(string)
+			if !ok || spanID == "" || spanIDs[spanID] {
+				continue
+			}
+			spanIDs[spanID] = true
+			quotedSpanIDs = append(quotedSpanIDs, quoteTraceQLString(spanID))
+		}
+		if len(quotedSpanIDs) == 0 {
+			return "", debug, nil
+		}
+		parentFilter = fmt.Sprintf("parent_span_id IN (%s)", strings.Join(quotedSpanIDs, ","))
+	}
+	return "", nil, nil
+}
+
 func decodeIdBytes(id string, length int, idMap map[string][]byte) []byte {
 	idBytes := []byte{}
 	if len(id) == length*2 {
@@ -0,0 +1,503 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tempo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/khulnasoft/deepflow/server/libs/datatype"
+	"github.com/khulnasoft/deepflow/server/querier/common"
+)
+
+// TraceQL subset supported by the search api:
+//
+//	query    := spanset ( '>' spanset )*
+//	spanset  := '{' [ expr ] '}'
+//	expr     := and ( '||' and )*
+//	and      := primary ( '&&' primary )*
+//	primary  := '(' expr ')' | field op value
+//	field    := span.<key> | resource.<key> | .<key> | duration | status | name | kind
+//	op       := = | != | > | >= | < | <= | =~ | !~
+//
+// ref: https://grafana.com/docs/tempo/latest/traceql/
+
+const (
+	TRACEQL_SCOPE_SPAN     = "span."
+	TRACEQL_SCOPE_RESOURCE = "resource."
+	TRACEQL_SCOPE_NONE     = "."
+
+	TRACEQL_INTRINSIC_DURATION = "duration"
+	TRACEQL_INTRINSIC_STATUS   = "status"
+	TRACEQL_INTRINSIC_NAME     = "name"
+	TRACEQL_INTRINSIC_KIND     = "kind"
+)
+
+// span kinds are stored with the values of opentelemetry
+var TRACEQL_SPAN_KIND_MAP = map[string]int{
+	"unspecified": 0,
+	"internal":    1,
+	"server":      2,
+	"client":      3,
+	"producer":    4,
+	"consumer":    5,
+}
+
+var TRACEQL_STATUS_MAP = map[string][]int{
+	"ok":    {int(datatype.STATUS_OK)},
+	"error": {int(datatype.STATUS_ERROR), int(datatype.STATUS_SERVER_ERROR), int(datatype.STATUS_CLIENT_ERROR)},
+	"unset": {int(datatype.STATUS_NOT_EXIST)},
+}
+
+// attributes stored in native columns of l7_flow_log, others are read from `attribute.<key>`
+var TRACEQL_ATTRIBUTE_MAP = map[string]string{
+	"service.name":              L7_FLOW_LOG_SERVICE_NAME,
+	"http.status_code":          "response_code",
+	"http.response.status_code": "response_code",
+}
+
+// native columns which could be compared as numbers
+var TRACEQL_NUMERIC_COLUMNS = map[string]bool{
+	"response_code": true,
+}
+
+var TRACEQL_INTRINSIC_MAP = map[string]string{
+	TRACEQL_INTRINSIC_DURATION: "response_duration",
+	TRACEQL_INTRINSIC_STATUS:   "response_status",
+	TRACEQL_INTRINSIC_NAME:     L7_TRACING_ENDPOINT,
+	TRACEQL_INTRINSIC_KIND:     "span_kind",
+}
+
+type traceqlTokenType int
+
+const (
+	tokenEOF traceqlTokenType = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenDuration
+	tokenOp
+	tokenAnd
+	tokenOr
+	tokenLBrace
+	tokenRBrace
+	tokenLParen
+	tokenRParen
+	tokenUnsupported
+)
+
+type traceqlToken struct {
+	Type  traceqlTokenType
+	Value string
+	Pos   int
+}
+
+var traceqlDurationRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h)`)
+
+func traceqlError(format string, a ...interface{}) error {
+	return common.NewError(common.INVALID_PARAMETERS, "traceql: "+fmt.Sprintf(format, a...))
+}
+
+func lexTraceQL(q string) ([]traceqlToken, error) {
+	tokens := []traceqlToken{}
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '{':
+			tokens = append(tokens, traceqlToken{tokenLBrace, "{", i})
+			i++
+		case c == '}':
+			tokens = append(tokens, traceqlToken{tokenRBrace, "}", i})
+			i++
+		case c == '(':
+			tokens = append(tokens, traceqlToken{tokenLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, traceqlToken{tokenRParen, ")", i})
+			i++
+		case strings.HasPrefix(q[i:], "&&"):
+			tokens = append(tokens, traceqlToken{tokenAnd, "&&", i})
+			i += 2
+		case strings.HasPrefix(q[i:], "||"):
+			tokens = append(tokens, traceqlToken{tokenOr, "||", i})
+			i += 2
+		case strings.HasPrefix(q[i:], "=~"), strings.HasPrefix(q[i:], "!~"), strings.HasPrefix(q[i:], "!="),
+			strings.HasPrefix(q[i:], ">="), strings.HasPrefix(q[i:], "<="):
+			tokens = append(tokens, traceqlToken{tokenOp, q[i : i+2], i})
+			i += 2
+		case strings.HasPrefix(q[i:], ">>"), strings.HasPrefix(q[i:], "<<"), strings.HasPrefix(q[i:], "!>"), strings.HasPrefix(q[i:], "!<"):
+			tokens = append(tokens, traceqlToken{tokenUnsupported, q[i : i+2], i})
+			i += 2
+		case c == '=' || c == '<':
+			tokens = append(tokens, traceqlToken{tokenOp, string(c), i})
+			i++
+		case c == '>':
+			// the meaning of '>' depends on the position, a comparison inside braces and structural outside
+			tokens = append(tokens, traceqlToken{tokenOp, ">", i})
+			i++
+		case c == '"' || c == '`':
+			value, n, err := lexTraceQLString(q[i:])
+			if err != nil {
+				return nil, traceqlError("%s at position %d", err, i)
+			}
+			tokens = append(tokens, traceqlToken{tokenString, value, i})
+			i += n
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+			if c == '-' {
+				i++
+			}
+			if d := traceqlDurationRegexp.FindString(q[i:]); d != "" {
+				i += len(d)
+				tokens = append(tokens, traceqlToken{tokenDuration, q[start:i], start})
+				break
+			}
+			for i < len(q) && (q[i] == '.' || (q[i] >= '0' && q[i] <= '9')) {
+				i++
+			}
+			if i == start+1 && c == '-' {
+				return nil, traceqlError("unexpected '-' at position %d", start)
+			}
+			tokens = append(tokens, traceqlToken{tokenNumber, q[start:i], start})
+		case c == '.' || c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(q) && isTraceQLIdentChar(q[i]) {
+				i++
+			}
+			tokens = append(tokens, traceqlToken{tokenIdent, q[start:i], start})
+		default:
+			tokens = append(tokens, traceqlToken{tokenUnsupported, string(c), i})
+			i++
+		}
+	}
+	tokens = append(tokens, traceqlToken{tokenEOF, "", len(q)})
+	return tokens, nil
+}
+
+func isTraceQLIdentChar(c byte) bool {
+	return c == '.' || c == '_' || c == '-' || c == '/' || c == ':' || (c >= '0' && c <= '9') || unicode.IsLetter(rune(c))
+}
+
+func lexTraceQLString(q string) (string, int, error) {
+	quote := q[0]
+	if quote == '`' {
+		end := strings.IndexByte(q[1:], '`')
+		if end < 0 {
+			return "", 0, fmt.Errorf("unterminated string")
+		}
+		return q[1 : end+1], end + 2, nil
+	}
+	for i := 1; i < len(q); i++ {
+		if q[i] == '\\' {
+			i++
+			continue
+		}
+		if q[i] == quote {
+			value, err := strconv.Unquote(q[:i+1])
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid string %s", q[:i+1])
+			}
+			return value, i + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+type traceqlParser struct {
+	tokens []traceqlToken
+	pos    int
+}
+
+func (p *traceqlParser) peek() traceqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *traceqlParser) next() traceqlToken {
+	t := p.tokens[p.pos]
+	if t.Type != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *traceqlParser) unexpected(t traceqlToken) error {
+	switch {
+	case t.Type == tokenEOF:
+		return traceqlError("unexpected end of query")
+	case t.Value == "|":
+		return traceqlError("pipelines and aggregates at position %d are not supported", t.Pos)
+	case t.Type == tokenUnsupported:
+		return traceqlError("unsupported operator '%s' at position %d", t.Value, t.Pos)
+	default:
+		return traceqlError("unexpected '%s' at position %d", t.Value, t.Pos)
+	}
+}
+
+// parseTraceQL translates the query to l7_flow_log filters, one filter for each spanset.
+// Every spanset is the parent of the next one, connected by the structural '>' operator.
+// An empty spanset '{}' matches all spans and gets an empty filter.
+func parseTraceQL(q string) ([]string, error) {
+	tokens, err := lexTraceQL(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &traceqlParser{tokens: tokens}
+	filters := []string{}
+	for {
+		filter, err := p.parseSpanset()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+		t := p.next()
+		if t.Type == tokenEOF {
+			return filters, nil
+		}
+		if t.Type == tokenOp && t.Value == ">" {
+			continue
+		}
+		if t.Type == tokenAnd || t.Type == tokenOr {
+			return nil, traceqlError("spanset operator '%s' at position %d is not supported, only '>' is supported between spansets", t.Value, t.Pos)
+		}
+		return nil, p.unexpected(t)
+	}
+}
+
+func (p *traceqlParser) parseSpanset() (string, error) {
+	t := p.next()
+	if t.Type != tokenLBrace {
+		if t.Type == tokenIdent {
+			return "", traceqlError("'%s' at position %d is not supported, a query should start with a spanset like '{ ... }'", t.Value, t.Pos)
+		}
+		return "", p.unexpected(t)
+	}
+	if p.peek().Type == tokenRBrace {
+		p.next()
+		return "", nil
+	}
+	filter, err := p.parseOr()
+	if err != nil {
+		return "", err
+	}
+	if t := p.next(); t.Type != tokenRBrace {
+		return "", p.unexpected(t)
+	}
+	return filter, nil
+}
+
+func (p *traceqlParser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	conditions := []string{left}
+	for p.peek().Type == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, right)
+	}
+	if len(conditions) == 1 {
+		return left, nil
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", nil
+}
+
+func (p *traceqlParser) parseAnd() (string, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return "", err
+	}
+	conditions := []string{left}
+	for p.peek().Type == tokenAnd {
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, right)
+	}
+	if len(conditions) == 1 {
+		return left, nil
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")", nil
+}
+
+func (p *traceqlParser) parsePrimary() (string, error) {
+	t := p.next()
+	if t.Type == tokenLParen {
+		filter, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if t := p.next(); t.Type != tokenRParen {
+			return "", p.unexpected(t)
+		}
+		return filter, nil
+	}
+	if t.Type != tokenIdent {
+		return "", p.unexpected(t)
+	}
+	op := p.next()
+	if op.Type != tokenOp {
+		if op.Type == tokenLParen {
+			return "", traceqlError("function '%s' at position %d is not supported", t.Value, t.Pos)
+		}
+		if op.Type == tokenRBrace || op.Type == tokenAnd || op.Type == tokenOr {
+			return "", traceqlError("existence check of '%s' at position %d is not supported, use a comparison", t.Value, t.Pos)
+		}
+		return "", p.unexpected(op)
+	}
+	value := p.next()
+	switch value.Type {
+	case tokenString, tokenNumber, tokenDuration, tokenIdent:
+	default:
+		return "", p.unexpected(value)
+	}
+	return translateTraceQLCondition(t, op.Value, value)
+}
+
+func translateTraceQLCondition(field traceqlToken, op string, value traceqlToken) (string, error) {
+	name := field.Value
+	switch name {
+	case TRACEQL_INTRINSIC_DURATION:
+		if value.Type != tokenDuration {
+			return "", traceqlError("duration at position %d should be compared with a duration like '100ms'", field.Pos)
+		}
+		d, err := time.ParseDuration(value.Value)
+		if err != nil {
+			return "", traceqlError("invalid duration '%s' at position %d", value.Value, value.Pos)
+		}
+		if err := checkTraceQLOp(op, false, field); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s%s%d", TRACEQL_INTRINSIC_MAP[name], op, d.Microseconds()), nil
+	case TRACEQL_INTRINSIC_STATUS:
+		status, ok := TRACEQL_STATUS_MAP[value.Value]
+		if !ok || value.Type != tokenIdent {
+			return "", traceqlError("unknown status '%s' at position %d, expected ok, error or unset", value.Value, value.Pos)
+		}
+		return translateTraceQLEnum(TRACEQL_INTRINSIC_MAP[name], op, status, field)
+	case TRACEQL_INTRINSIC_KIND:
+		kind, ok := TRACEQL_SPAN_KIND_MAP[value.Value]
+		if !ok || value.Type != tokenIdent {
+			return "", traceqlError("unknown kind '%s' at position %d", value.Value, value.Pos)
+		}
+		return translateTraceQLEnum(TRACEQL_INTRINSIC_MAP[name], op, []int{kind}, field)
+	case TRACEQL_INTRINSIC_NAME:
+		return translateTraceQLValue(TRACEQL_INTRINSIC_MAP[name], op, value, field)
+	}
+
+	var key string
+	switch {
+	case strings.HasPrefix(name, TRACEQL_SCOPE_SPAN):
+		key = strings.TrimPrefix(name, TRACEQL_SCOPE_SPAN)
+	case strings.HasPrefix(name, TRACEQL_SCOPE_RESOURCE):
+		key = strings.TrimPrefix(name, TRACEQL_SCOPE_RESOURCE)
+	case strings.HasPrefix(name, TRACEQL_SCOPE_NONE):
+		key = strings.TrimPrefix(name, TRACEQL_SCOPE_NONE)
+	default:
+		return "", traceqlError("unsupported field '%s' at position %d", name, field.Pos)
+	}
+	if key == "" {
+		return "", traceqlError("empty attribute name at position %d", field.Pos)
+	}
+	// span and resource attributes of otel are both saved in attribute.<key>
+	column, ok := TRACEQL_ATTRIBUTE_MAP[key]
+	if !ok {
+		column = fmt.Sprintf("`attribute.%s`", key)
+	}
+	return translateTraceQLValue(column, op, value, field)
+}
+
+func translateTraceQLValue(column, op string, value traceqlToken, field traceqlToken) (string, error) {
+	switch op {
+	case "=~", "!~":
+		if value.Type != tokenString {
+			return "", traceqlError("regex of '%s' at position %d should be a string", field.Value, field.Pos)
+		}
+		if _, err := regexp.Compile(value.Value); err != nil {
+			return "", traceqlError("invalid regex '%s' at position %d", value.Value, value.Pos)
+		}
+		sqlOp := "REGEXP"
+		if op == "!~" {
+			sqlOp = "NOT REGEXP"
+		}
+		return fmt.Sprintf("%s %s %s", column, sqlOp, quoteTraceQLString(value.Value)), nil
+	}
+	switch value.Type {
+	case tokenString:
+		return fmt.Sprintf("%s%s%s", column, op, quoteTraceQLString(value.Value)), nil
+	case tokenNumber:
+		if TRACEQL_NUMERIC_COLUMNS[column] {
+			return fmt.Sprintf("%s%s%s", column, op, value.Value), nil
+		}
+		// attributes are saved as strings, numbers are compared as strings for equality only
+		if err := checkTraceQLOp(op, true, field); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s%s%s", column, op, quoteTraceQLString(value.Value)), nil
+	case tokenIdent:
+		if value.Value == "true" || value.Value == "false" {
+			return fmt.Sprintf("%s%s%s", column, op, quoteTraceQLString(value.Value)), nil
+		}
+	}
+	return "", traceqlError("unsupported value '%s' at position %d", value.Value, value.Pos)
+}
+
+func translateTraceQLEnum(column, op string, values []int, field traceqlToken) (string, error) {
+	if err := checkTraceQLOp(op, true, field); err != nil {
+		return "", err
+	}
+	if len(values) == 1 {
+		return fmt.Sprintf("%s%s%d", column, op, values[0]), nil
+	}
+	valueStrs := make([]string, 0, len(values))
+	for _, v := range values {
+		valueStrs = append(valueStrs, strconv.Itoa(v))
+	}
+	sqlOp := "IN"
+	if op == "!=" {
+		sqlOp = "NOT IN"
+	}
+	return fmt.Sprintf("%s %s (%s)", column, sqlOp, strings.Join(valueStrs, ",")), nil
+}
+
+func checkTraceQLOp(op string, equalOnly bool, field traceqlToken) error {
+	switch op {
+	case "=", "!=":
+		return nil
+	case ">", ">=", "<", "<=":
+		if !equalOnly {
+			return nil
+		}
+	}
+	return traceqlError("operator '%s' is not supported by '%s' at position %d", op, field.Value, field.Pos)
+}
+
+func quoteTraceQLString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2024 KhulnaSoft, Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tempo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/khulnasoft/deepflow/server/querier/common"
+)
+
+func TestParseTraceQL(t *testing.T) {
+	testCases := []struct {
+		query  string
+		output []string
+	}{
+		{
+			query:  `{}`,
+			output: []string{""},
+		},
+		{
+			query:  `{ resource.service.name = "frontend" }`,
+			output: []string{"app_service='frontend'"},
+		},
+		{
+			query:  `{ span.http.method = "GET" && .http.url =~ "/api/.*" }`,
+			output: []string{"(`attribute.http.method`='GET' AND `attribute.http.url` REGEXP '/api/.*')"},
+		},
+		{
+			query:  `{ duration > 100ms || (status = error && span.http.status_code >= 500) }`,
+			output: []string{"(response_duration>100000 OR (response_status IN (1,3,4) AND response_code>=500))"},
+		},
+		{
+			query:  `{ name != "health" && kind = server && status != ok }`,
+			output: []string{"(endpoint!='health' AND span_kind=2 AND response_status!=0)"},
+		},
+		{
+			query:  `{ .db.statement !~ "^SELECT" && .peer = "it's" }`,
+			output: []string{"(`attribute.db.statement` NOT REGEXP '^SELECT' AND `attribute.peer`='it\\'s')"},
+		},
+		{
+			query:  `{ resource.service.name = "frontend" } > { kind = client } > {}`,
+			output: []string{"app_service='frontend'", "span_kind=3", ""},
+		},
+	}
+	for _, tc := range testCases {
+		output, err := parseTraceQL(tc.query)
+		if err != nil {
+			t.Errorf("parse %s failed: %s", tc.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(output, tc.output) {
+			t.Errorf("parse %s, expected %v, actual %v", tc.query, tc.output, output)
+		}
+	}
+}
+
+func TestParseTraceQLUnsupported(t *testing.T) {
+	testCases := []struct {
+		query string
+		err   string
+	}{
+		{query: `{ .foo = "bar" } | count() > 2`, err: "pipelines"},
+		{query: `{ .foo = "bar" } >> { .a = "b" }`, err: "unsupported operator '>>'"},
+		{query: `{ .foo = "bar" } && { .a = "b" }`, err: "only '>' is supported"},
+		{query: `{ .foo }`, err: "existence check"},
+		{query: `{ duration > 100 }`, err: "duration"},
+		{query: `{ status = failed }`, err: "unknown status"},
+		{query: `{ kind > server }`, err: "operator '>' is not supported"},
+		{query: `{ .attempts > 3 }`, err: "operator '>' is not supported"},
+		{query: `{ .foo =~ "(" }`, err: "invalid regex"},
+		{query: `{ .foo = "bar"`, err: "unexpected end"},
+		{query: `{ .foo = "bar }`, err: "unterminated string"},
+		{query: `.foo = "bar"`, err: "should start with a spanset"},
+	}
+	for _, tc := range testCases {
+		_, err := parseTraceQL(tc.query)
+		if err == nil {
+			t.Errorf("parse %s should fail", tc.query)
+			continue
+		}
+		serviceErr, ok := err.(*common.ServiceError)
+		if !ok || serviceErr.Status != common.INVALID_PARAMETERS {
+			t.Errorf("parse %s, unexpected error type %v", tc.query, err)
+			continue
+		}
+		if !strings.Contains(serviceErr.Message, tc.err) {
+			t.Errorf("parse %s, expected error containing '%s', actual '%s'", tc.query, tc.err, serviceErr.Message)
+		}
+	}
+}